@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"syscall"
 	"time"
+	_ "time/tzdata" // time zone names must resolve on hosts without a zoneinfo database
 
 	"github.com/go-chi/chi/v5/middleware"
 
@@ -33,13 +34,47 @@ func main() {
 	var port int
 	var host string
 	var webDir string
+	var basePath string
+	var maxJSONBodyBytes int64
+	var maxBackupBytes int64
 	var debug bool
+	var logLevelFlag string
+	var logFormat string
+	var noCompress bool
+	var corsOrigins string
+	var timeZone string
+	var aiAPIKeyFile string
+	var aiMaxConcurrent int
+	var failedAnalysisRetention time.Duration
+	var slowQueryThreshold time.Duration
+	var requestIDHeader string
+	var goldUnit string
+	var goldCurrency string
+	var priceUserAgent string
+	var importMaxRows int
 
 	flag.StringVar(&dataDir, "data-dir", "", "Directory for storing database and application data")
 	flag.IntVar(&port, "port", 8000, "Port to run the server on")
 	flag.StringVar(&host, "host", "127.0.0.1", "Host to bind the server to")
 	flag.StringVar(&webDir, "web-dir", "", "Directory for SPA static files (optional)")
+	flag.Int64Var(&maxJSONBodyBytes, "max-json-body-bytes", 0, "Largest JSON request body accepted, in bytes; larger ones get 413 (default 1MB, negative disables)")
+	flag.Int64Var(&maxBackupBytes, "max-backup-bytes", 0, "Largest backup upload accepted by /api/restore, in bytes; larger ones get 413 (default 256MB, negative disables)")
+	flag.StringVar(&basePath, "base-path", "", "Path prefix all routes are served under, e.g. /investlog behind a reverse proxy (default: the root)")
 	flag.BoolVar(&debug, "debug", false, "Enable debug logging (overrides build mode)")
+	flag.StringVar(&logLevelFlag, "log-level", "", "Log level: debug, info, warn or error (default: debug in dev builds, info in release)")
+	flag.StringVar(&logFormat, "log-format", "", "Log format: text or json (default: text in dev builds, json in release)")
+	flag.BoolVar(&noCompress, "no-compress", false, "Disable gzip response compression (useful for curl debugging and streaming)")
+	flag.StringVar(&corsOrigins, "cors-origins", "", "Comma-separated origins allowed to call the API cross-origin, e.g. http://localhost:5173 (default: same-origin only)")
+	flag.StringVar(&timeZone, "timezone", "", "IANA time zone for generated dates and timestamps, e.g. America/New_York (default: config time_zone, then Asia/Shanghai)")
+	flag.StringVar(&requestIDHeader, "request-id-header", api.DefaultRequestIDHeader, "Header read for a caller-supplied request ID and echoed on every response")
+	flag.StringVar(&aiAPIKeyFile, "ai-api-key-file", "", "File holding the AI API key used when a request omits api_key (default: the AI_API_KEY environment variable)")
+	flag.IntVar(&aiMaxConcurrent, "ai-max-concurrent", 0, "Maximum upstream AI calls in flight across all analyses; extra calls queue for up to 30s (default 4, negative disables)")
+	flag.DurationVar(&failedAnalysisRetention, "failed-analysis-retention", 0, "Delete failed symbol analyses older than this, e.g. 720h (default: keep them)")
+	flag.DurationVar(&slowQueryThreshold, "slow-query-threshold", 0, "Log key database queries taking at least this long, e.g. 200ms (default: off)")
+	flag.StringVar(&goldUnit, "gold-unit", investlog.GoldUnitGram, "Unit gold prices are stored in: gram or ounce (troy ounce)")
+	flag.StringVar(&goldCurrency, "gold-currency", "CNY", "Currency gold prices are converted to: CNY, USD or HKD")
+	flag.StringVar(&priceUserAgent, "price-user-agent", "", "User-Agent header sent to price sources (default: a desktop browser string)")
+	flag.IntVar(&importMaxRows, "import-max-rows", 0, "Largest CSV transaction import accepted, in rows (default 10000)")
 	flag.Parse()
 
 	if dataDir != "" {
@@ -54,10 +89,25 @@ func main() {
 	}
 	logDir := filepath.Join(resolvedDataDir, "logs")
 	logLevel := slog.LevelInfo
-	if debug || buildMode == "dev" {
+	if buildMode == "dev" {
 		logLevel = slog.LevelDebug
 	}
-	logger, writer, err := logging.NewLogger(logDir, logLevel)
+	if logLevelFlag != "" {
+		if logLevel, err = logging.ParseLevel(logLevelFlag); err != nil {
+			slog.Error("invalid log level", "err", err)
+			os.Exit(1)
+		}
+	}
+	if debug {
+		logLevel = slog.LevelDebug
+	}
+	if logFormat == "" {
+		logFormat = logging.FormatText
+		if buildMode != "dev" {
+			logFormat = logging.FormatJSON
+		}
+	}
+	logger, writer, err := logging.NewLoggerWithFormat(logDir, logLevel, logFormat)
 	if err != nil {
 		slog.Error("failed to initialize logger", "err", err)
 		os.Exit(1)
@@ -65,6 +115,7 @@ func main() {
 	logger.Info("logger initialized",
 		"build_mode", buildMode,
 		"log_level", logLevel.String(),
+		"log_format", logFormat,
 		"log_dir", logDir,
 	)
 	defer func() {
@@ -79,7 +130,23 @@ func main() {
 		os.Exit(1)
 	}
 
-	core, err := investlog.OpenWithOptions(investlog.Options{DBPath: dbPath, Logger: logger})
+	if timeZone == "" {
+		timeZone = config.LoadUserConfig().TimeZone
+	}
+	coreOpts := investlog.Options{
+		DBPath:                  dbPath,
+		Logger:                  logger,
+		TimeZone:                timeZone,
+		AIAPIKeyFile:            aiAPIKeyFile,
+		AIMaxConcurrent:         aiMaxConcurrent,
+		FailedAnalysisRetention: failedAnalysisRetention,
+		SlowQueryThreshold:      slowQueryThreshold,
+		GoldPriceUnit:           goldUnit,
+		GoldPriceCurrency:       goldCurrency,
+		PriceUserAgent:          priceUserAgent,
+		ImportMaxRows:           importMaxRows,
+	}
+	core, err := investlog.OpenWithOptions(coreOpts)
 	if err != nil {
 		logger.Error("failed to initialize core", "err", err)
 		os.Exit(1)
@@ -90,18 +157,43 @@ func main() {
 		}
 	}()
 
+	// The router fails symbol analyses left pending by an earlier crash, then
+	// keeps sweeping for ones abandoned while running, following the active
+	// core across storage switches and restores.
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+
 	if os.Getenv("INVEST_LOG_PARENT_WATCH") == "1" {
 		logger.Info("parent watcher enabled")
 		go watchParent(logger)
 	}
 
 	addr := fmt.Sprintf("%s:%d", host, port)
-	handler := api.NewRouter(core)
+	allowedOrigins := api.ParseCORSOrigins(corsOrigins)
+	if len(allowedOrigins) > 0 {
+		logger.Info("cross-origin requests enabled", "origins", allowedOrigins)
+	}
+	basePath = api.NormalizeBasePath(basePath)
+	if basePath != "" {
+		logger.Info("serving under base path", "base_path", basePath)
+	}
+	handler := api.NewRouterWithOptions(core, api.RouterOptions{
+		CORS:             api.CORSConfig{AllowedOrigins: allowedOrigins, AllowCredentials: true},
+		RequestIDHeader:  requestIDHeader,
+		BasePath:         basePath,
+		MaxJSONBodyBytes: maxJSONBodyBytes,
+		MaxBackupBytes:   maxBackupBytes,
+		CoreOptions:      coreOpts,
+		CleanupContext:   cleanupCtx,
+	})
 	if resolvedWebDir := resolveWebDir(webDir); resolvedWebDir != "" {
 		logger.Info("serving SPA", "web_dir", resolvedWebDir)
-		handler = api.WithSPA(handler, resolvedWebDir)
+		handler = api.WithSPABasePath(handler, resolvedWebDir, basePath)
+	}
+	if noCompress {
+		logger.Info("response compression disabled")
 	}
-	handler = middleware.Compress(5)(handler)
+	handler = wrapCompression(handler, !noCompress)
 
 	server := &http.Server{
 		Addr:              addr,
@@ -140,6 +232,14 @@ func main() {
 	logger.Info("server shutdown completed")
 }
 
+// wrapCompression applies gzip compression unless disabled.
+func wrapCompression(handler http.Handler, enabled bool) http.Handler {
+	if !enabled {
+		return handler
+	}
+	return middleware.Compress(5)(handler)
+}
+
 func watchParent(logger *slog.Logger) {
 	for {
 		sleep(1 * time.Second)
@@ -4,9 +4,12 @@ import (
 	"flag"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -128,3 +131,34 @@ func TestMainLifecycle(t *testing.T) {
 		t.Fatalf("main did not exit")
 	}
 }
+
+func TestWrapCompression(t *testing.T) {
+	body := strings.Repeat(`{"status":"ok"}`, 100)
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	})
+
+	for _, tt := range []struct {
+		name     string
+		enabled  bool
+		encoding string
+	}{
+		{name: "enabled", enabled: true, encoding: "gzip"},
+		{name: "disabled", enabled: false, encoding: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rr := httptest.NewRecorder()
+			wrapCompression(inner, tt.enabled).ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("expected Content-Encoding %q, got %q", tt.encoding, got)
+			}
+			if !tt.enabled && rr.Body.String() != body {
+				t.Fatalf("expected uncompressed body")
+			}
+		})
+	}
+}
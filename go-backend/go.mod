@@ -6,6 +6,7 @@ require (
 	github.com/go-chi/chi/v5 v5.0.12
 	github.com/go-chi/cors v1.2.1
 	github.com/shopspring/decimal v1.4.0
+	golang.org/x/text v0.27.0
 	modernc.org/sqlite v1.28.0
 )
 
@@ -1,21 +1,53 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
 	"sync"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 
 	"investlog/pkg/investlog"
 )
 
-// NewRouter builds the HTTP API router.
+// RouterOptions configures NewRouterWithOptions.
+type RouterOptions struct {
+	CORS CORSConfig
+	// RequestIDHeader is read for a caller-supplied request ID and echoed on
+	// every response; it defaults to X-Request-ID. A custom header must be
+	// added to CORS.AllowedHeaders for cross-origin callers.
+	RequestIDHeader string
+	// BasePath mounts every route under a prefix such as "/investlog" for
+	// deployments behind a reverse proxy at a subpath. Default: the root.
+	BasePath string
+	// MaxJSONBodyBytes caps JSON request bodies; larger ones are rejected
+	// with 413. Default: 1MB. A negative value disables the cap.
+	MaxJSONBodyBytes int64
+	// MaxBackupBytes caps backup uploads to /api/restore; larger ones are
+	// rejected with 413. Default: 256MB. A negative value disables the cap.
+	MaxBackupBytes int64
+	// CoreOptions are the options core was opened with. Switching storage
+	// and restoring a backup reopen the database with them, replacing only
+	// DBPath.
+	CoreOptions investlog.Options
+	// CleanupContext, when set, runs the stale symbol analysis cleanup
+	// against the active core until it is done, restarting it whenever the
+	// core is replaced.
+	CleanupContext context.Context
+}
+
+// NewRouter builds the HTTP API router with default options, which only
+// serve same-origin browser requests.
 func NewRouter(core *investlog.Core) http.Handler {
+	return NewRouterWithOptions(core, RouterOptions{})
+}
+
+// NewRouterWithOptions builds the HTTP API router.
+func NewRouterWithOptions(core *investlog.Core, opts RouterOptions) http.Handler {
 	r := chi.NewRouter()
 
 	logger := slog.Default()
@@ -23,58 +55,54 @@ func NewRouter(core *investlog.Core) http.Handler {
 		logger = core.Logger()
 	}
 	h := &handler{
-		core:   core,
-		logger: logger,
+		core:             core,
+		logger:           logger,
+		maxJSONBodyBytes: opts.MaxJSONBodyBytes,
+		maxBackupBytes:   opts.MaxBackupBytes,
+		coreOpts:         opts.CoreOptions,
+		cleanupCtx:       opts.CleanupContext,
+	}
+	if core != nil {
+		h.startCleanup(core)
 	}
 
-	r.Use(middleware.RequestID)
+	r.Use(requestIDMiddleware(opts.RequestIDHeader))
 	r.Use(middleware.RealIP)
 	r.Use(requestLoggingMiddleware(logger))
 	r.Use(recoveryLoggingMiddleware(logger))
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins: []string{
-			"http://localhost:*",
-			"http://127.0.0.1:*",
-			"capacitor://localhost",
-			"ionic://localhost",
-			"file://*",
-		},
-		AllowOriginFunc: func(r *http.Request, origin string) bool {
-			// Allow requests with no origin (same-origin, curl, mobile apps)
-			if origin == "" {
-				return true
-			}
-			// Allow localhost and 127.0.0.1 on any port
-			if strings.HasPrefix(origin, "http://localhost:") ||
-				strings.HasPrefix(origin, "http://127.0.0.1:") ||
-				strings.HasPrefix(origin, "https://localhost:") ||
-				strings.HasPrefix(origin, "https://127.0.0.1:") ||
-				origin == "capacitor://localhost" ||
-				origin == "ionic://localhost" ||
-				strings.HasPrefix(origin, "file://") {
-				return true
-			}
-			return false
-		},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		AllowCredentials: true,
-	}))
+	r.Use(requestTimeoutMiddleware(defaultRequestTimeout, defaultRouteTimeouts))
+	r.Use(corsMiddleware(opts.CORS))
 
 	r.Use(h.coreLockMiddleware)
+	aiLimit := aiRateLimitMiddleware(core.AIRateLimit())
 
 	r.Get("/api/health", h.health)
+	r.Get("/api/livez", h.livez)
+	r.Get("/api/openapi.json", h.getOpenAPI)
 	// Holdings
 	r.Get("/api/holdings", h.getHoldings)
 	r.Get("/api/holdings-by-currency", h.getHoldingsByCurrency)
 	r.Get("/api/holdings-by-symbol", h.getHoldingsBySymbol)
 	r.Get("/api/holdings-by-currency-account", h.getHoldingsByCurrencyAndAccount)
+	r.Get("/api/holdings/by-exchange", h.getHoldingsByExchange)
+	r.Get("/api/holdings/top-movers", h.getTopMovers)
 	r.Post("/api/holdings/modify", h.modifyHolding)
+	r.Get("/api/networth", h.getNetWorth)
+	r.Get("/api/performance/annual", h.getAnnualPerformance)
+	r.Post("/api/performance/snapshots", h.recordPortfolioSnapshots)
+	r.Get("/api/performance/twr", h.getTimeWeightedReturn)
+	r.Get("/api/report", h.getAnalysisReport)
+	r.Post("/api/simulate", h.simulatePosition)
 
 	// Transactions
 	r.Get("/api/transactions", h.getTransactions)
 	r.Post("/api/transactions", h.addTransaction)
+	r.Post("/api/transactions/import", h.importTransactions)
+	r.Get("/api/transactions/deleted", h.getDeletedTransactions)
+	r.Get("/api/transactions/summary", h.getTransactionSummary)
 	r.Delete("/api/transactions/{id}", h.deleteTransaction)
+	r.Post("/api/transactions/{id}/restore", h.restoreTransaction)
+	r.Get("/api/tags", h.getTags)
 
 	// Transfers
 	r.Post("/api/transfers", h.addTransfer)
@@ -85,31 +113,49 @@ func NewRouter(core *investlog.Core) http.Handler {
 	// Prices
 	r.Post("/api/prices/update", h.updatePrice)
 	r.Post("/api/prices/manual", h.manualUpdatePrice)
+	r.Post("/api/prices/manual-bulk", h.manualUpdatePrices)
 	r.Post("/api/prices/update-all", h.updateAllPrices)
+	r.Get("/api/price/diagnostics", h.getPriceDiagnostics)
+	r.Get("/api/price/historical", h.getHistoricalPrice)
+	r.Get("/api/price-circuit-settings", h.getPriceCircuitSettings)
+	r.Put("/api/price-circuit-settings", h.setPriceCircuitSettings)
 	r.Get("/api/ai-settings", h.getAISettings)
 	r.Put("/api/ai-settings", h.setAISettings)
 	r.Get("/api/ai-analysis-methods", h.getAIAnalysisMethods)
 	r.Post("/api/ai-analysis-methods", h.createAIAnalysisMethod)
 	r.Put("/api/ai-analysis-methods/{id}", h.updateAIAnalysisMethod)
 	r.Delete("/api/ai-analysis-methods/{id}", h.deleteAIAnalysisMethod)
-	r.Post("/api/ai-analysis/stream", h.runAIAnalysisStream)
+	r.Get("/api/ai-analysis-profiles", h.getAIAnalysisProfiles)
+	r.Put("/api/ai-analysis-profiles", h.saveAIAnalysisProfile)
+	r.Delete("/api/ai-analysis-profiles/{name}", h.deleteAIAnalysisProfile)
+	r.With(aiLimit).Post("/api/ai-analysis/stream", h.runAIAnalysisStream)
 	r.Get("/api/ai-analysis/history", h.getAIAnalysisHistory)
 	r.Get("/api/ai-analysis/runs/{id}", h.getAIAnalysisRun)
-	r.Post("/api/ai/holdings-analysis", h.analyzeHoldingsWithAI)
-	r.Post("/api/ai/holdings-analysis/stream", h.analyzeHoldingsWithAIStream)
+	r.Get("/api/ai/scopes", h.getAIAnalyzableScopes)
+	r.Get("/api/ai/schemas", h.getAIResponseSchemas)
+	r.Get("/api/ai/options", h.getAIOptions)
+	r.With(aiLimit).Post("/api/ai/holdings-analysis", h.analyzeHoldingsWithAI)
+	r.With(aiLimit).Post("/api/ai/holdings-analysis/stream", h.analyzeHoldingsWithAIStream)
 	r.Get("/api/ai/holdings-analysis", h.getHoldingsAnalysis)
 	r.Get("/api/ai/holdings-analysis/history", h.getHoldingsAnalysisHistory)
-	r.Post("/api/ai/allocation-advice", h.getAIAllocationAdvice)
-	r.Post("/api/ai/allocation-advice/stream", h.getAIAllocationAdviceStream)
-	r.Post("/api/ai/symbol-analysis", h.analyzeSymbolWithAI)
-	r.Post("/api/ai/symbol-analysis/stream", h.analyzeSymbolWithAIStream)
+	r.Delete("/api/holdings-analysis/{id}", h.deleteHoldingsAnalysis)
+	r.With(aiLimit).Post("/api/ai/allocation-advice", h.getAIAllocationAdvice)
+	r.With(aiLimit).Post("/api/ai/allocation-advice/stream", h.getAIAllocationAdviceStream)
+	r.With(aiLimit).Post("/api/ai/symbol-analysis", h.analyzeSymbolWithAI)
+	r.With(aiLimit).Post("/api/ai/symbol-analysis/stream", h.analyzeSymbolWithAIStream)
 	r.Get("/api/ai/symbol-analysis", h.getSymbolAnalysis)
 	r.Get("/api/ai/symbol-analysis/history", h.getSymbolAnalysisHistory)
+	r.Get("/api/ai/symbol-analysis/position", h.getSymbolPositionWeight)
+	r.Get("/api/symbol-analysis/status", h.getSymbolAnalysisStatus)
+	r.Delete("/api/symbol-analysis/{id}", h.deleteSymbolAnalysis)
+	r.With(aiLimit).Post("/api/ai/symbol-analysis/{id}/resynthesize", h.resynthesizeSymbolAnalysis)
+	r.With(aiLimit).Post("/api/symbol-analysis/{id}/retry", h.retrySymbolAnalysis)
 
 	// Accounts
 	r.Get("/api/accounts", h.getAccounts)
 	r.Post("/api/accounts", h.addAccount)
 	r.Delete("/api/accounts/{id}", h.deleteAccount)
+	r.Put("/api/accounts/{id}/default-commission", h.setAccountDefaultCommission)
 
 	// Asset types
 	r.Get("/api/asset-types", h.getAssetTypes)
@@ -120,15 +166,34 @@ func NewRouter(core *investlog.Core) http.Handler {
 	r.Get("/api/allocation-settings", h.getAllocationSettings)
 	r.Put("/api/allocation-settings", h.setAllocationSetting)
 	r.Delete("/api/allocation-settings", h.deleteAllocationSetting)
+	r.Get("/api/reporting-settings", h.getReportingSettings)
+	r.Put("/api/reporting-settings", h.setReportingSettings)
 	r.Get("/api/exchange-rates", h.getExchangeRates)
 	r.Put("/api/exchange-rates", h.setExchangeRate)
 	r.Post("/api/exchange-rates/refresh", h.refreshExchangeRates)
+	r.Get("/api/convert", h.convertCurrency)
 
 	// Symbols
 	r.Get("/api/symbols", h.getSymbols)
+	r.Get("/api/symbols/classify", h.classifySymbol)
+	r.Post("/api/symbols/analysis/statuses", h.getSymbolAnalysisStatuses)
+	r.With(aiLimit).Post("/api/symbols/compare", h.compareSymbols)
 	r.Put("/api/symbols/{symbol}", h.updateSymbol)
 	r.Post("/api/symbols/{symbol}/asset-type", h.updateSymbolAssetType)
 	r.Post("/api/symbols/{symbol}/auto-update", h.updateSymbolAutoUpdate)
+	r.Post("/api/symbols/{symbol}/inactive", h.updateSymbolInactive)
+	r.Post("/api/symbols/{symbol}/type-override", h.setSymbolTypeOverride)
+	r.Post("/api/symbols/{symbol}/fetch-metadata", h.fetchSymbolMetadata)
+	r.Post("/api/symbols/{symbol}/refresh", h.refreshSymbolData)
+
+	// Watchlist
+	r.Get("/api/watchlist", h.getWatchlist)
+	r.Post("/api/watchlist", h.addToWatchlist)
+	r.Delete("/api/watchlist/{id}", h.removeFromWatchlist)
+	r.Get("/api/alerts", h.getPriceAlerts)
+	r.Post("/api/alerts", h.setPriceAlert)
+	r.Get("/api/alerts/triggered", h.getTriggeredPriceAlerts)
+	r.Delete("/api/alerts/{id}", h.deletePriceAlert)
 
 	// Operation logs
 	r.Get("/api/operation-logs", h.getOperationLogs)
@@ -136,14 +201,20 @@ func NewRouter(core *investlog.Core) http.Handler {
 	// Storage
 	r.Get("/api/storage", h.getStorageInfo)
 	r.Post("/api/storage/switch", h.switchStorage)
+	r.Post("/api/restore", h.restoreBackup)
 
-	return r
+	return withBasePath(NormalizeBasePath(opts.BasePath), r)
 }
 
 type handler struct {
-	core   *investlog.Core
-	logger *slog.Logger
-	coreMu sync.RWMutex
+	core             *investlog.Core
+	logger           *slog.Logger
+	coreMu           sync.RWMutex
+	maxJSONBodyBytes int64
+	maxBackupBytes   int64
+	coreOpts         investlog.Options
+	cleanupCtx       context.Context
+	stopCleanup      context.CancelFunc
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
@@ -153,8 +224,39 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 }
 
 func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorWithFields(w, status, message, nil)
+}
+
+// writeErrorWithFields writes the standard error body with extra fields, such
+// as an error_code and the id of the conflicting record.
+func writeErrorWithFields(w http.ResponseWriter, status int, message string, fields map[string]any) {
 	if setter, ok := w.(interface{ SetErrorMessage(string) }); ok {
 		setter.SetErrorMessage(message)
 	}
-	writeJSON(w, status, map[string]string{"error": message})
+	body := make(map[string]any, len(fields)+2)
+	for key, value := range fields {
+		body[key] = value
+	}
+	body["error"] = message
+	if requestID := responseRequestID(w); requestID != "" {
+		body["request_id"] = requestID
+	}
+	writeJSON(w, status, body)
+}
+
+// writeRequestError writes err with status, except validation errors, which
+// become 422 with the per-field messages under "errors", and busy errors,
+// which become 503 with a Retry-After hint.
+func writeRequestError(w http.ResponseWriter, status int, err error) {
+	var invalid *investlog.ValidationError
+	if !errors.As(err, &invalid) {
+		var coded *investlog.Error
+		if errors.As(err, &coded) && coded.Code == investlog.ErrCodeBusy {
+			w.Header().Set("Retry-After", "5")
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeErrorWithFields(w, http.StatusUnprocessableEntity, invalid.Error(), map[string]any{"errors": invalid.Fields})
 }
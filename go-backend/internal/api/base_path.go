@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NormalizeBasePath cleans a router prefix such as "investlog/" into
+// "/investlog". An empty value or "/" means the root and returns "".
+func NormalizeBasePath(value string) string {
+	value = strings.Trim(strings.TrimSpace(value), "/")
+	if value == "" {
+		return ""
+	}
+	return "/" + value
+}
+
+// withBasePath serves next under basePath, so "/investlog/api/health" reaches
+// the "/api/health" route. Paths outside the prefix get a 404.
+func withBasePath(basePath string, next http.Handler) http.Handler {
+	if basePath == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stripped, ok := stripBasePath(r, basePath)
+		if !ok {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		next.ServeHTTP(w, stripped)
+	})
+}
+
+// stripBasePath returns a copy of r with basePath removed from its URL path.
+// It reports false when the path is not under basePath.
+func stripBasePath(r *http.Request, basePath string) (*http.Request, bool) {
+	rest, ok := trimBasePath(r.URL.Path, basePath)
+	if !ok {
+		return nil, false
+	}
+	rawRest := ""
+	if r.URL.RawPath != "" {
+		if rawRest, ok = trimBasePath(r.URL.RawPath, basePath); !ok {
+			return nil, false
+		}
+	}
+	stripped := new(http.Request)
+	*stripped = *r
+	stripped.URL = new(url.URL)
+	*stripped.URL = *r.URL
+	stripped.URL.Path = rest
+	stripped.URL.RawPath = rawRest
+	return stripped, true
+}
+
+// trimBasePath removes basePath from the start of p, matching whole path
+// segments only ("/investlog2" is not under "/investlog").
+func trimBasePath(p, basePath string) (string, bool) {
+	if p == basePath {
+		return "/", true
+	}
+	if !strings.HasPrefix(p, basePath+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(p, basePath), true
+}
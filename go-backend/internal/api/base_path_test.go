@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"investlog/pkg/investlog"
+)
+
+func TestNormalizeBasePath(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"/":            "",
+		" investlog ":  "/investlog",
+		"/investlog/":  "/investlog",
+		"apps/invest/": "/apps/invest",
+	}
+	for input, want := range cases {
+		if got := NormalizeBasePath(input); got != want {
+			t.Errorf("NormalizeBasePath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestRouterBasePath(t *testing.T) {
+	router := NewRouterWithOptions(nil, RouterOptions{BasePath: "investlog/"})
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/investlog/api/livez", http.StatusOK},
+		{"/investlog/api/openapi.json", http.StatusOK},
+		{"/api/livez", http.StatusNotFound},
+		{"/investlog2/api/livez", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rr.Code != tc.want {
+			t.Errorf("GET %s: expected %d, got %d", tc.path, tc.want, rr.Code)
+		}
+	}
+}
+
+func TestRouterBasePathHealth(t *testing.T) {
+	core, err := investlog.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	defer core.Close()
+
+	router := NewRouterWithOptions(core, RouterOptions{BasePath: "/investlog"})
+	rr := doRequest(router, http.MethodGet, "/investlog/api/health", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /investlog/api/health: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestWithSPABasePath(t *testing.T) {
+	webDir := t.TempDir()
+	index := `<!DOCTYPE html><html><head><link rel="stylesheet" href="style.css"></head><body></body></html>`
+	if err := os.WriteFile(filepath.Join(webDir, "index.html"), []byte(index), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(webDir, "app.js"), []byte("APP"), 0o644); err != nil {
+		t.Fatalf("write asset: %v", err)
+	}
+
+	h := WithSPABasePath(NewRouterWithOptions(nil, RouterOptions{BasePath: "/investlog"}), webDir, "/investlog")
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	rr := get("/investlog?api=x")
+	if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/investlog/?api=x" {
+		t.Fatalf("expected redirect to /investlog/?api=x, got %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	rr = get("/investlog/")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for index, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `<head><meta name="investlog-base-path" content="/investlog">`) {
+		t.Fatalf("expected base path meta in index, got %q", body)
+	}
+
+	rr = get("/investlog/app.js")
+	if rr.Code != http.StatusOK || rr.Body.String() != "APP" {
+		t.Fatalf("expected asset under base path, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = get("/investlog/api/livez")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"ok"`) {
+		t.Fatalf("expected API under base path, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = get("/investlog/holdings")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "investlog-base-path") {
+		t.Fatalf("expected SPA fallback to index, got %d", rr.Code)
+	}
+
+	for _, path := range []string{"/", "/app.js", "/api/livez"} {
+		if rr = get(path); rr.Code != http.StatusNotFound {
+			t.Errorf("GET %s outside base path: expected 404, got %d", path, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/cors"
+)
+
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	// EventSource sends Cache-Control and Last-Event-ID on reconnect, so SSE
+	// routes need them allowed alongside the JSON API headers.
+	defaultCORSHeaders        = []string{"Accept", "Authorization", "Content-Type", "Cache-Control", "Last-Event-ID", DefaultRequestIDHeader}
+	defaultCORSExposedHeaders = []string{"ETag", "Retry-After", DefaultRequestIDHeader}
+)
+
+// CORSConfig controls cross-origin access to the API. With no allowed
+// origins only same-origin requests succeed.
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins or patterns with one wildcard,
+	// e.g. "http://localhost:*".
+	AllowedOrigins   []string
+	AllowedMethods   []string // Default: GET, POST, PUT, DELETE, OPTIONS
+	AllowedHeaders   []string // Default: Accept, Authorization, Content-Type, Cache-Control, Last-Event-ID, X-Request-ID
+	AllowCredentials bool
+}
+
+// ParseCORSOrigins splits a comma-separated origin list, dropping blanks.
+func ParseCORSOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+func corsMiddleware(cfg CORSConfig) func(http.Handler) http.Handler {
+	opts := cors.Options{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   defaultCORSExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+	}
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = defaultCORSMethods
+	}
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = defaultCORSHeaders
+	}
+	if len(opts.AllowedOrigins) == 0 {
+		// An empty list means "*" to the cors package; deny instead.
+		opts.AllowOriginFunc = func(*http.Request, string) bool { return false }
+	}
+	return cors.Handler(opts)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func preflight(handler http.Handler, origin, method string, headers string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/api/ai/symbol-analysis/stream", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	handler := corsMiddleware(CORSConfig{
+		AllowedOrigins:   []string{"http://localhost:5173"},
+		AllowCredentials: true,
+	})(http.NotFoundHandler())
+
+	rr := preflight(handler, "http://localhost:5173", http.MethodPost, "Content-Type, Last-Event-ID")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Fatalf("expected origin echoed, got %q", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Methods"); got != http.MethodPost {
+		t.Fatalf("expected POST allowed, got %q", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Fatalf("expected credentials allowed, got %q", got)
+	}
+}
+
+func TestCORSMiddlewareRejectsDisallowedOrigin(t *testing.T) {
+	handler := corsMiddleware(CORSConfig{AllowedOrigins: []string{"http://localhost:5173"}})(http.NotFoundHandler())
+
+	rr := preflight(handler, "http://evil.example", http.MethodPost, "")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no allow-origin header, got %q", got)
+	}
+	rr = preflight(handler, "http://localhost:5173", http.MethodPatch, "")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected disallowed method to be rejected, got %q", got)
+	}
+}
+
+func TestCORSMiddlewareDefaultsToSameOrigin(t *testing.T) {
+	handler := corsMiddleware(CORSConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := preflight(handler, "http://localhost:3000", http.MethodGet, "")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected cross-origin preflight to be refused, got %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/holdings", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no allow-origin header, got %q", got)
+	}
+}
+
+func TestCORSMiddlewareExposesHeadersOnStream(t *testing.T) {
+	handler := corsMiddleware(CORSConfig{AllowedOrigins: []string{"http://localhost:*"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ai/symbol-analysis/stream", nil)
+	req.Header.Set("Origin", "http://localhost:8080")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:8080" {
+		t.Fatalf("expected stream response to carry allow-origin, got %q", got)
+	}
+	if got := rr.Header().Get("Access-Control-Expose-Headers"); got == "" {
+		t.Fatalf("expected exposed headers on stream response")
+	}
+}
+
+func TestParseCORSOrigins(t *testing.T) {
+	got := ParseCORSOrigins(" http://a.example , ,capacitor://localhost")
+	want := []string{"http://a.example", "capacitor://localhost"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseCORSOrigins = %v, want %v", got, want)
+	}
+	if got := ParseCORSOrigins(""); got != nil {
+		t.Fatalf("expected nil for empty input, got %v", got)
+	}
+}
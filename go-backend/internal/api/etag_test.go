@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHoldingsEndpointsETag(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-etag",
+		"account_name": "ETag Account",
+	})
+	buy := func(quantity int) {
+		rr := doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+			"symbol":           "AAPL",
+			"transaction_type": "BUY",
+			"quantity":         quantity,
+			"price":            100,
+			"currency":         "USD",
+			"account_id":       "acc-etag",
+			"asset_type":       "stock",
+		})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("add transaction: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+		}
+	}
+	buy(10)
+
+	for _, path := range []string{"/api/holdings", "/api/holdings-by-currency", "/api/holdings-by-symbol"} {
+		t.Run(path, func(t *testing.T) {
+			first := doRequest(router, http.MethodGet, path, nil)
+			second := doRequest(router, http.MethodGet, path, nil)
+			etag := first.Header().Get("ETag")
+			if first.Code != http.StatusOK || etag == "" {
+				t.Fatalf("expected 200 with ETag, got %d %q", first.Code, etag)
+			}
+			if second.Header().Get("ETag") != etag {
+				t.Fatalf("expected identical ETag for identical data, got %q and %q", etag, second.Header().Get("ETag"))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("If-None-Match", etag)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != http.StatusNotModified {
+				t.Fatalf("conditional GET: expected 304, got %d", rr.Code)
+			}
+			if rr.Body.Len() != 0 {
+				t.Fatalf("expected empty 304 body, got %q", rr.Body.String())
+			}
+		})
+	}
+
+	etag := doRequest(router, http.MethodGet, "/api/holdings-by-symbol", nil).Header().Get("ETag")
+	buy(5)
+	req := httptest.NewRequest(http.MethodGet, "/api/holdings-by-symbol", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Fatalf("expected fresh 200 with new ETag after a write, got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: `"abc"`, want: true},
+		{header: `W/"abc"`, want: true},
+		{header: `"xyz", "abc"`, want: true},
+		{header: "*", want: true},
+		{header: `"xyz"`, want: false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `"abc"`); got != tt.want {
+			t.Fatalf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
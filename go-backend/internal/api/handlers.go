@@ -2,17 +2,31 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"investlog/pkg/investlog"
 )
 
+// health is the readiness probe: it returns 503 with per-check details when
+// the database or data directory is unusable.
 func (h *handler) health(w http.ResponseWriter, r *http.Request) {
+	report := h.core.CheckHealth(r.Context())
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// livez only reports that the process is serving requests.
+func (h *handler) livez(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -23,25 +37,135 @@ func (h *handler) getHoldings(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, result)
+	writeJSONWithETag(w, r, result)
 }
 
 func (h *handler) getHoldingsByCurrency(w http.ResponseWriter, r *http.Request) {
+	includeCash, err := parseIncludeCash(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	result, err := h.core.GetHoldingsByCurrency()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if !includeCash {
+		result = investlog.ExcludeCashFromAllocationPercents(result)
+	}
+	writeJSONWithETag(w, r, result)
+}
+
+func (h *handler) getHoldingsByExchange(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetHoldingsByExchange(r.URL.Query().Get("currency"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getTopMovers(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetTopMovers(r.URL.Query().Get("currency"), parseIntDefault(r.URL.Query().Get("n"), 0))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getNetWorth(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetNetWorth(r.URL.Query().Get("base"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getAnnualPerformance(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetAnnualPerformance(r.URL.Query().Get("currency"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *handler) recordPortfolioSnapshots(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.RecordPortfolioSnapshots()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getTimeWeightedReturn(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetTimeWeightedReturn(r.URL.Query().Get("currency"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) simulatePosition(w http.ResponseWriter, r *http.Request) {
+	var payload simulatePositionPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	result, err := h.core.SimulatePosition(payload.Symbol, payload.Currency, payload.Quantity, payload.Price)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getAnalysisReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	report, err := h.core.ExportAnalysisReport(r.URL.Query().Get("currency"), format)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	contentType := "text/markdown; charset=utf-8"
+	if strings.EqualFold(strings.TrimSpace(format), investlog.ReportFormatHTML) {
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(report))
+}
+
 func (h *handler) getHoldingsBySymbol(w http.ResponseWriter, r *http.Request) {
+	includeCash, err := parseIncludeCash(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	result, err := h.core.GetHoldingsBySymbol()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, result)
+	if !includeCash {
+		result = investlog.ExcludeCashFromSymbolPercents(result)
+	}
+	base := r.URL.Query().Get("base")
+	if base == "" {
+		writeJSONWithETag(w, r, result)
+		return
+	}
+	rollup, err := h.core.RollupHoldingsBySymbol(result, base)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSONWithETag(w, r, holdingsBySymbolRollupResponse{Currencies: result, Rollup: rollup})
 }
 
 func (h *handler) getHoldingsByCurrencyAndAccount(w http.ResponseWriter, r *http.Request) {
@@ -55,8 +179,8 @@ func (h *handler) getHoldingsByCurrencyAndAccount(w http.ResponseWriter, r *http
 
 func (h *handler) modifyHolding(w http.ResponseWriter, r *http.Request) {
 	var payload modifyHoldingPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	id, err := h.core.ModifyHolding(investlog.ModifyHoldingRequest{
@@ -117,36 +241,88 @@ func (h *handler) getTransactions(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *handler) getTransactionSummary(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	summary, err := h.core.GetTransactionSummary(investlog.TransactionFilter{
+		Symbol:          query.Get("symbol"),
+		AccountID:       query.Get("account_id"),
+		TransactionType: query.Get("transaction_type"),
+		Currency:        query.Get("currency"),
+		Year:            parseInt(query.Get("year")),
+		StartDate:       query.Get("start_date"),
+		EndDate:         query.Get("end_date"),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
 func (h *handler) addTransaction(w http.ResponseWriter, r *http.Request) {
 	var payload addTransactionPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
+	var commission investlog.Amount
+	if payload.Commission != nil {
+		commission = *payload.Commission
+	}
 	id, err := h.core.AddTransaction(investlog.AddTransactionRequest{
-		TransactionDate: payload.TransactionDate,
-		TransactionTime: payload.TransactionTime,
-		Symbol:          payload.Symbol,
-		TransactionType: payload.TransactionType,
-		Quantity:        payload.Quantity,
-		Price:           payload.Price,
-		AccountID:       payload.AccountID,
-		AssetType:       payload.AssetType,
-		Commission:      payload.Commission,
-		Currency:        payload.Currency,
-		AccountName:     payload.AccountName,
-		Notes:           payload.Notes,
-		Tags:            payload.Tags,
-		TotalAmount:     payload.TotalAmount,
-		LinkCash:        payload.LinkCash,
+		TransactionDate:      payload.TransactionDate,
+		TransactionTime:      payload.TransactionTime,
+		Symbol:               payload.Symbol,
+		TransactionType:      payload.TransactionType,
+		Quantity:             payload.Quantity,
+		Price:                payload.Price,
+		AccountID:            payload.AccountID,
+		AssetType:            payload.AssetType,
+		Commission:           commission,
+		UseDefaultCommission: payload.Commission == nil,
+		Currency:             payload.Currency,
+		AccountName:          payload.AccountName,
+		Notes:                payload.Notes,
+		Tags:                 payload.Tags,
+		TotalAmount:          payload.TotalAmount,
+		LinkCash:             payload.LinkCash,
+		CheckDuplicate:       !payload.Force,
+		CheckTotalAmount:     !payload.Force,
 	})
+	var duplicate *investlog.DuplicateTransactionError
+	if errors.As(err, &duplicate) {
+		writeErrorWithFields(w, http.StatusConflict, err.Error(), map[string]any{
+			"error_code":  investlog.ErrCodeDuplicate,
+			"existing_id": duplicate.ExistingID,
+		})
+		return
+	}
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"id": id})
 }
 
+func (h *handler) importTransactions(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.ImportTransactionsCSV(r.Body)
+	if err != nil && result != nil {
+		// Earlier batches are already committed; report them with the error.
+		status := http.StatusInternalServerError
+		var invalid *investlog.ValidationError
+		if errors.As(err, &invalid) {
+			status = http.StatusUnprocessableEntity
+		}
+		writeErrorWithFields(w, status, err.Error(), map[string]any{"result": result})
+		return
+	}
+	if err != nil {
+		writeRequestError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 func (h *handler) deleteTransaction(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -166,10 +342,38 @@ func (h *handler) deleteTransaction(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
+func (h *handler) getDeletedTransactions(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetDeletedTransactions(parseIntDefault(r.URL.Query().Get("limit"), 100))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) restoreTransaction(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	restored, err := h.core.RestoreTransaction(id)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !restored {
+		writeError(w, http.StatusNotFound, "deleted transaction not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "restored"})
+}
+
 func (h *handler) addTransfer(w http.ResponseWriter, r *http.Request) {
 	var payload transferPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	result, err := h.core.Transfer(investlog.TransferRequest{
@@ -185,7 +389,7 @@ func (h *handler) addTransfer(w http.ResponseWriter, r *http.Request) {
 		Notes:           payload.Notes,
 	})
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, result)
@@ -201,10 +405,94 @@ func (h *handler) getPortfolioHistory(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *handler) getPriceDiagnostics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	result, err := h.core.FetchPriceDiagnostics(query.Get("symbol"), query.Get("currency"), query.Get("asset_type"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) classifySymbol(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	result, err := h.core.ClassifySymbol(query.Get("symbol"), query.Get("currency"), query.Get("asset_type"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getHistoricalPrice(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	result, err := h.core.GetHistoricalPrice(query.Get("symbol"), query.Get("currency"), query.Get("asset_type"), query.Get("date"))
+	if err != nil {
+		switch {
+		case errors.Is(err, investlog.ErrNoData):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, investlog.ErrBondNotSupported), errors.Is(err, investlog.ErrUnknownSymbol):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusBadGateway, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getPriceCircuitSettings(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.core.GetPriceCircuitSettings())
+}
+
+func (h *handler) setPriceCircuitSettings(w http.ResponseWriter, r *http.Request) {
+	var payload priceCircuitSettingsPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	settings, err := h.core.SetPriceCircuitSettings(investlog.PriceCircuitSettings{
+		FailThreshold:     payload.FailThreshold,
+		FailWindowSeconds: payload.FailWindowSeconds,
+		CooldownSeconds:   payload.CooldownSeconds,
+	})
+	if err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (h *handler) getReportingSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.core.GetReportingSettings()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (h *handler) setReportingSettings(w http.ResponseWriter, r *http.Request) {
+	var payload reportingSettingsPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	settings, err := h.core.SetReportingSettings(investlog.ReportingSettings{
+		DefaultBaseCurrency: payload.DefaultBaseCurrency,
+	})
+	if err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
 func (h *handler) updatePrice(w http.ResponseWriter, r *http.Request) {
 	var payload pricePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	result, err := h.core.UpdatePrice(payload.Symbol, payload.Currency, payload.AssetType)
@@ -217,8 +505,8 @@ func (h *handler) updatePrice(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) manualUpdatePrice(w http.ResponseWriter, r *http.Request) {
 	var payload manualPricePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	if err := h.core.ManualUpdatePrice(payload.Symbol, payload.Currency, payload.Price); err != nil {
@@ -228,10 +516,28 @@ func (h *handler) manualUpdatePrice(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
 }
 
+func (h *handler) manualUpdatePrices(w http.ResponseWriter, r *http.Request) {
+	var payload []manualPricePayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	prices := make([]investlog.ManualPrice, 0, len(payload))
+	for _, p := range payload {
+		prices = append(prices, investlog.ManualPrice{Symbol: p.Symbol, Currency: p.Currency, Price: p.Price})
+	}
+	count, errors, err := h.core.ManualUpdatePrices(prices)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": count, "errors": errors})
+}
+
 func (h *handler) updateAllPrices(w http.ResponseWriter, r *http.Request) {
 	var payload updateAllPricesPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	count, errors, err := h.core.UpdateAllPrices(payload.Currency)
@@ -242,10 +548,27 @@ func (h *handler) updateAllPrices(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"updated": count, "errors": errors})
 }
 
+func (h *handler) getAIAnalyzableScopes(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetAnalyzableScopes()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getAIResponseSchemas(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, investlog.AIResponseSchemas())
+}
+
+func (h *handler) getAIOptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.core.GetAIOptions())
+}
+
 func (h *handler) analyzeHoldingsWithAI(w http.ResponseWriter, r *http.Request) {
 	var payload aiHoldingsAnalysisPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -255,42 +578,62 @@ func (h *handler) analyzeHoldingsWithAI(w http.ResponseWriter, r *http.Request)
 	}
 
 	result, err := h.core.AnalyzeHoldings(investlog.HoldingsAnalysisRequest{
-		BaseURL:         payload.BaseURL,
-		APIKey:          payload.APIKey,
-		Model:           payload.Model,
-		Currency:        payload.Currency,
-		RiskProfile:     payload.RiskProfile,
-		Horizon:         payload.Horizon,
-		AdviceStyle:     payload.AdviceStyle,
-		AllowNewSymbols: allowNewSymbols,
-		StrategyPrompt:  payload.StrategyPrompt,
-		AnalysisType:    payload.AnalysisType,
+		BaseURL:            payload.BaseURL,
+		APIKey:             payload.APIKey,
+		Model:              payload.Model,
+		Currency:           payload.Currency,
+		RiskProfile:        payload.RiskProfile,
+		Horizon:            payload.Horizon,
+		AdviceStyle:        payload.AdviceStyle,
+		AllowNewSymbols:    allowNewSymbols,
+		StrategyPrompt:     payload.StrategyPrompt,
+		AnalysisType:       payload.AnalysisType,
+		Profile:            payload.Profile,
+		FallbackModels:     payload.FallbackModels,
+		PromptTopSymbols:   payload.PromptTopSymbols,
+		MinWeightPct:       payload.MinWeightPct,
+		MaxRecommendations: payload.MaxRecommendations,
+		MaxKeyFindings:     payload.MaxKeyFindings,
+		StructuredOutput:   payload.StructuredOutput,
+		Temperature:        payload.Temperature,
+		ThinkingBudget:     payload.ThinkingBudget,
+		Context:            r.Context(),
 	})
 	if err != nil {
-		h.logger.Error("ai holdings analysis failed",
+		h.requestLogger(r).Error("ai holdings analysis failed",
 			"currency", payload.Currency,
 			"model", payload.Model,
 			"base_url", payload.BaseURL,
 			"err", err,
 		)
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, result)
 }
 
+// validateAICredentials rejects a streaming request before the SSE response
+// starts, since errors after that point can only be reported as events. A
+// blank api_key is accepted when the server has its own key configured.
+func (h *handler) validateAICredentials(apiKey, model string) error {
+	invalid := &investlog.ValidationError{}
+	if strings.TrimSpace(apiKey) == "" && !h.core.HasServerAIAPIKey() {
+		invalid.Add("api_key", "api_key is required")
+	}
+	if strings.TrimSpace(model) == "" {
+		invalid.Add("model", "model is required")
+	}
+	return invalid.Err()
+}
+
 func (h *handler) analyzeHoldingsWithAIStream(w http.ResponseWriter, r *http.Request) {
 	var payload aiHoldingsAnalysisPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
-	if strings.TrimSpace(payload.APIKey) == "" {
-		writeError(w, http.StatusBadRequest, "api_key is required")
-		return
-	}
-	if strings.TrimSpace(payload.Model) == "" {
-		writeError(w, http.StatusBadRequest, "model is required")
+	if err := h.validateAICredentials(payload.APIKey, payload.Model); err != nil {
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -319,21 +662,33 @@ func (h *handler) analyzeHoldingsWithAIStream(w http.ResponseWriter, r *http.Req
 		"stage":   "start",
 		"message": "开始执行持仓分析",
 	}); err != nil {
-		h.logger.Warn("ai holdings stream write failed", "stage", "start", "err", err)
+		h.requestLogger(r).Warn("ai holdings stream write failed", "stage", "start", "err", err)
 		return
 	}
 
+	stopHeartbeat := startSSEHeartbeat(w, flusher, &streamMu)
+	defer stopHeartbeat()
 	result, err := h.core.AnalyzeHoldingsStream(investlog.HoldingsAnalysisRequest{
-		BaseURL:         payload.BaseURL,
-		APIKey:          payload.APIKey,
-		Model:           payload.Model,
-		Currency:        payload.Currency,
-		RiskProfile:     payload.RiskProfile,
-		Horizon:         payload.Horizon,
-		AdviceStyle:     payload.AdviceStyle,
-		AllowNewSymbols: allowNewSymbols,
-		StrategyPrompt:  payload.StrategyPrompt,
-		AnalysisType:    payload.AnalysisType,
+		BaseURL:            payload.BaseURL,
+		APIKey:             payload.APIKey,
+		Model:              payload.Model,
+		Currency:           payload.Currency,
+		RiskProfile:        payload.RiskProfile,
+		Horizon:            payload.Horizon,
+		AdviceStyle:        payload.AdviceStyle,
+		AllowNewSymbols:    allowNewSymbols,
+		StrategyPrompt:     payload.StrategyPrompt,
+		AnalysisType:       payload.AnalysisType,
+		Profile:            payload.Profile,
+		FallbackModels:     payload.FallbackModels,
+		PromptTopSymbols:   payload.PromptTopSymbols,
+		MinWeightPct:       payload.MinWeightPct,
+		MaxRecommendations: payload.MaxRecommendations,
+		MaxKeyFindings:     payload.MaxKeyFindings,
+		StructuredOutput:   payload.StructuredOutput,
+		Temperature:        payload.Temperature,
+		ThinkingBudget:     payload.ThinkingBudget,
+		Context:            r.Context(),
 	}, func(delta string) error {
 		if delta == "" {
 			return nil
@@ -346,8 +701,9 @@ func (h *handler) analyzeHoldingsWithAIStream(w http.ResponseWriter, r *http.Req
 		}
 		return nil
 	})
+	stopHeartbeat()
 	if err != nil {
-		h.logger.Error("ai holdings analysis stream failed",
+		h.requestLogger(r).Error("ai holdings analysis stream failed",
 			"currency", payload.Currency,
 			"model", payload.Model,
 			"base_url", payload.BaseURL,
@@ -373,14 +729,54 @@ func (h *handler) getHoldingsAnalysis(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) getHoldingsAnalysisHistory(w http.ResponseWriter, r *http.Request) {
-	currency := r.URL.Query().Get("currency")
-	limit := parseIntDefault(r.URL.Query().Get("limit"), 10)
-	results, err := h.core.GetHoldingsAnalysisHistory(currency, limit)
+	query := r.URL.Query()
+	currency := query.Get("currency")
+	limit := parseIntDefault(query.Get("limit"), 10)
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := parseIntDefault(query.Get("offset"), 0)
+	if offset < 0 {
+		offset = 0
+	}
+	results, err := h.core.GetHoldingsAnalysisHistory(currency, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if query.Get("paged") != "1" {
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+	total, err := h.core.GetHoldingsAnalysisHistoryCount(currency)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, results)
+	writeJSON(w, http.StatusOK, holdingsAnalysisHistoryResponse{
+		Items:  results,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *handler) deleteHoldingsAnalysis(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	deleted, err := h.core.DeleteHoldingsAnalysis(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "holdings analysis not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted"})
 }
 
 func (h *handler) getAISettings(w http.ResponseWriter, r *http.Request) {
@@ -394,8 +790,8 @@ func (h *handler) getAISettings(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) setAISettings(w http.ResponseWriter, r *http.Request) {
 	var payload aiSettingsPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -403,6 +799,19 @@ func (h *handler) setAISettings(w http.ResponseWriter, r *http.Request) {
 	if payload.AllowNewSymbols != nil {
 		allowNewSymbols = *payload.AllowNewSymbols
 	}
+	// Omitting theory_tags keeps the stored vocabulary; an empty list resets
+	// it to the defaults.
+	var theoryTags []string
+	if payload.TheoryTags != nil {
+		theoryTags = *payload.TheoryTags
+	} else {
+		current, err := h.core.GetAISettings()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		theoryTags = current.TheoryTags
+	}
 
 	settings, err := h.core.SetAISettings(investlog.AISettings{
 		BaseURL:         payload.BaseURL,
@@ -413,6 +822,7 @@ func (h *handler) setAISettings(w http.ResponseWriter, r *http.Request) {
 		AllowNewSymbols: allowNewSymbols,
 		StrategyPrompt:  payload.StrategyPrompt,
 		APIKey:          payload.APIKey,
+		TheoryTags:      theoryTags,
 	})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
@@ -423,8 +833,8 @@ func (h *handler) setAISettings(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) getAIAllocationAdvice(w http.ResponseWriter, r *http.Request) {
 	var payload aiAllocationAdvicePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -439,14 +849,15 @@ func (h *handler) getAIAllocationAdvice(w http.ResponseWriter, r *http.Request)
 		ExperienceLevel: payload.ExperienceLevel,
 		Currencies:      payload.Currencies,
 		CustomPrompt:    payload.CustomPrompt,
+		Context:         r.Context(),
 	})
 	if err != nil {
-		h.logger.Error("ai allocation advice failed",
+		h.requestLogger(r).Error("ai allocation advice failed",
 			"model", payload.Model,
 			"base_url", payload.BaseURL,
 			"err", err,
 		)
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, result)
@@ -454,16 +865,12 @@ func (h *handler) getAIAllocationAdvice(w http.ResponseWriter, r *http.Request)
 
 func (h *handler) getAIAllocationAdviceStream(w http.ResponseWriter, r *http.Request) {
 	var payload aiAllocationAdvicePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-	if strings.TrimSpace(payload.APIKey) == "" {
-		writeError(w, http.StatusBadRequest, "api_key is required")
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
-	if strings.TrimSpace(payload.Model) == "" {
-		writeError(w, http.StatusBadRequest, "model is required")
+	if err := h.validateAICredentials(payload.APIKey, payload.Model); err != nil {
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -473,25 +880,33 @@ func (h *handler) getAIAllocationAdviceStream(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	initSSEHeaders(w)
-	w.WriteHeader(http.StatusOK)
-
-	if err := writeSSEEvent(w, flusher, "progress", map[string]any{
+	initSSEHeaders(w)
+	w.WriteHeader(http.StatusOK)
+	var streamMu sync.Mutex
+	writeStreamEvent := func(event string, payload any) error {
+		streamMu.Lock()
+		defer streamMu.Unlock()
+		return writeSSEEvent(w, flusher, event, payload)
+	}
+
+	if err := writeStreamEvent("progress", map[string]any{
 		"stage":   "start",
 		"message": "开始生成资产配置建议",
 	}); err != nil {
-		h.logger.Warn("ai allocation stream write failed", "stage", "start", "err", err)
+		h.requestLogger(r).Warn("ai allocation stream write failed", "stage", "start", "err", err)
 		return
 	}
 
-	if err := writeSSEEvent(w, flusher, "progress", map[string]any{
+	if err := writeStreamEvent("progress", map[string]any{
 		"stage":   "running",
 		"message": "正在调用 AI 生成配置区间",
 	}); err != nil {
-		h.logger.Warn("ai allocation stream write failed", "stage", "running", "err", err)
+		h.requestLogger(r).Warn("ai allocation stream write failed", "stage", "running", "err", err)
 		return
 	}
 
+	stopHeartbeat := startSSEHeartbeat(w, flusher, &streamMu)
+	defer stopHeartbeat()
 	result, err := h.core.GetAllocationAdviceWithStream(investlog.AllocationAdviceRequest{
 		BaseURL:         payload.BaseURL,
 		APIKey:          payload.APIKey,
@@ -503,33 +918,35 @@ func (h *handler) getAIAllocationAdviceStream(w http.ResponseWriter, r *http.Req
 		ExperienceLevel: payload.ExperienceLevel,
 		Currencies:      payload.Currencies,
 		CustomPrompt:    payload.CustomPrompt,
+		Context:         r.Context(),
 	}, func(delta string) {
 		if delta == "" {
 			return
 		}
-		if err := writeSSEEvent(w, flusher, "delta", map[string]string{"text": delta}); err != nil {
-			h.logger.Warn("ai allocation stream delta write failed", "err", err)
+		if err := writeStreamEvent("delta", map[string]string{"text": delta}); err != nil {
+			h.requestLogger(r).Warn("ai allocation stream delta write failed", "err", err)
 		}
 	})
+	stopHeartbeat()
 	if err != nil {
-		h.logger.Error("ai allocation advice stream failed",
+		h.requestLogger(r).Error("ai allocation advice stream failed",
 			"model", payload.Model,
 			"base_url", payload.BaseURL,
 			"err", err,
 		)
-		_ = writeSSEEvent(w, flusher, "error", map[string]string{"error": err.Error()})
-		_ = writeSSEEvent(w, flusher, "done", map[string]any{"ok": false})
+		_ = writeStreamEvent("error", map[string]string{"error": err.Error()})
+		_ = writeStreamEvent("done", map[string]any{"ok": false})
 		return
 	}
 
-	_ = writeSSEEvent(w, flusher, "result", result)
-	_ = writeSSEEvent(w, flusher, "done", map[string]any{"ok": true})
+	_ = writeStreamEvent("result", result)
+	_ = writeStreamEvent("done", map[string]any{"ok": true})
 }
 
 func (h *handler) analyzeSymbolWithAI(w http.ResponseWriter, r *http.Request) {
 	var payload aiSymbolAnalysisPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -543,32 +960,75 @@ func (h *handler) analyzeSymbolWithAI(w http.ResponseWriter, r *http.Request) {
 		Horizon:        payload.Horizon,
 		AdviceStyle:    payload.AdviceStyle,
 		StrategyPrompt: payload.StrategyPrompt,
+		PositionBasis:  payload.PositionBasis,
+		Force:          payload.Force,
+		Context:        r.Context(),
 	})
 	if err != nil {
-		h.logger.Error("ai symbol analysis failed",
+		h.requestLogger(r).Error("ai symbol analysis failed",
 			"symbol", payload.Symbol,
 			"currency", payload.Currency,
 			"model", payload.Model,
 			"err", err,
 		)
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, result)
 }
 
-func (h *handler) analyzeSymbolWithAIStream(w http.ResponseWriter, r *http.Request) {
-	var payload aiSymbolAnalysisPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+func (h *handler) compareSymbols(w http.ResponseWriter, r *http.Request) {
+	var payload aiSymbolComparePayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	result, err := h.core.CompareSymbols(investlog.SymbolAnalysisRequest{
+		BaseURL:        payload.BaseURL,
+		APIKey:         payload.APIKey,
+		Model:          payload.Model,
+		Currency:       payload.Currency,
+		RiskProfile:    payload.RiskProfile,
+		Horizon:        payload.Horizon,
+		AdviceStyle:    payload.AdviceStyle,
+		StrategyPrompt: payload.StrategyPrompt,
+		PositionBasis:  payload.PositionBasis,
+		Force:          payload.Force,
+		Context:        r.Context(),
+	}, payload.Symbols)
+	if err != nil {
+		h.requestLogger(r).Error("ai symbol comparison failed",
+			"symbols", payload.Symbols,
+			"currency", payload.Currency,
+			"model", payload.Model,
+			"err", err,
+		)
+		if result == nil {
+			writeRequestError(w, http.StatusBadRequest, err)
+			return
+		}
+		// Every analysis failed; the verdicts carry each symbol's error.
+		status := http.StatusBadRequest
+		var coded *investlog.Error
+		if errors.As(err, &coded) && coded.Code == investlog.ErrCodeBusy {
+			w.Header().Set("Retry-After", "5")
+			status = http.StatusServiceUnavailable
+		}
+		writeErrorWithFields(w, status, err.Error(), map[string]any{"result": result})
 		return
 	}
-	if strings.TrimSpace(payload.APIKey) == "" {
-		writeError(w, http.StatusBadRequest, "api_key is required")
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) analyzeSymbolWithAIStream(w http.ResponseWriter, r *http.Request) {
+	var payload aiSymbolAnalysisPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
-	if strings.TrimSpace(payload.Model) == "" {
-		writeError(w, http.StatusBadRequest, "model is required")
+	if err := h.validateAICredentials(payload.APIKey, payload.Model); err != nil {
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -591,7 +1051,7 @@ func (h *handler) analyzeSymbolWithAIStream(w http.ResponseWriter, r *http.Reque
 		"stage":   "start",
 		"message": "开始执行个股分析",
 	}); err != nil {
-		h.logger.Warn("ai symbol stream write failed", "stage", "start", "err", err)
+		h.requestLogger(r).Warn("ai symbol stream write failed", "stage", "start", "err", err)
 		return
 	}
 
@@ -599,10 +1059,12 @@ func (h *handler) analyzeSymbolWithAIStream(w http.ResponseWriter, r *http.Reque
 		"stage":   "running",
 		"message": "正在调用 AI 多维分析",
 	}); err != nil {
-		h.logger.Warn("ai symbol stream write failed", "stage", "running", "err", err)
+		h.requestLogger(r).Warn("ai symbol stream write failed", "stage", "running", "err", err)
 		return
 	}
 
+	stopHeartbeat := startSSEHeartbeat(w, flusher, &streamMu)
+	defer stopHeartbeat()
 	result, err := h.core.AnalyzeSymbolWithStream(investlog.SymbolAnalysisRequest{
 		BaseURL:        payload.BaseURL,
 		APIKey:         payload.APIKey,
@@ -613,16 +1075,24 @@ func (h *handler) analyzeSymbolWithAIStream(w http.ResponseWriter, r *http.Reque
 		Horizon:        payload.Horizon,
 		AdviceStyle:    payload.AdviceStyle,
 		StrategyPrompt: payload.StrategyPrompt,
+		PositionBasis:  payload.PositionBasis,
+		Force:          payload.Force,
+		Context:        r.Context(),
 	}, func(delta string) {
 		if delta == "" {
 			return
 		}
 		if err := writeStreamEvent("delta", map[string]string{"text": delta}); err != nil {
-			h.logger.Warn("ai symbol stream delta write failed", "err", err)
+			h.requestLogger(r).Warn("ai symbol stream delta write failed", "err", err)
+		}
+	}, func(progress investlog.SymbolAnalysisProgress) {
+		if err := writeStreamEvent("progress", progress); err != nil {
+			h.requestLogger(r).Warn("ai symbol stream write failed", "stage", progress.Stage, "err", err)
 		}
 	})
+	stopHeartbeat()
 	if err != nil {
-		h.logger.Error("ai symbol analysis stream failed",
+		h.requestLogger(r).Error("ai symbol analysis stream failed",
 			"symbol", payload.Symbol,
 			"currency", payload.Currency,
 			"model", payload.Model,
@@ -637,6 +1107,78 @@ func (h *handler) analyzeSymbolWithAIStream(w http.ResponseWriter, r *http.Reque
 	_ = writeStreamEvent("done", map[string]any{"ok": true})
 }
 
+func (h *handler) getSymbolPositionWeight(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	result, err := h.core.GetSymbolPositionWeight(query.Get("symbol"), query.Get("currency"), query.Get("basis"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) resynthesizeSymbolAnalysis(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var payload aiSymbolResynthesizePayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	result, err := h.core.ResynthesizeSymbol(id, investlog.SymbolAnalysisRequest{
+		BaseURL:        payload.BaseURL,
+		APIKey:         payload.APIKey,
+		Model:          payload.Model,
+		RiskProfile:    payload.RiskProfile,
+		Horizon:        payload.Horizon,
+		AdviceStyle:    payload.AdviceStyle,
+		StrategyPrompt: payload.StrategyPrompt,
+		PositionBasis:  payload.PositionBasis,
+		Context:        r.Context(),
+	})
+	if err != nil {
+		h.requestLogger(r).Error("ai symbol resynthesis failed", "id", id, "model", payload.Model, "err", err)
+		status := http.StatusBadRequest
+		var invErr *investlog.Error
+		if errors.As(err, &invErr) && invErr.Code == investlog.ErrCodeNotFound {
+			status = http.StatusNotFound
+		}
+		writeRequestError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) retrySymbolAnalysis(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var payload aiSymbolRetryPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	result, err := h.core.RetrySymbolAnalysis(r.Context(), id, payload.APIKey)
+	if err != nil {
+		h.requestLogger(r).Error("ai symbol analysis retry failed", "id", id, "err", err)
+		status := http.StatusBadRequest
+		var invErr *investlog.Error
+		if errors.As(err, &invErr) && invErr.Code == investlog.ErrCodeNotFound {
+			status = http.StatusNotFound
+		}
+		writeRequestError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 func initSSEHeaders(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -644,6 +1186,46 @@ func initSSEHeaders(w http.ResponseWriter) {
 	w.Header().Set("X-Accel-Buffering", "no")
 }
 
+// sseHeartbeatInterval is how often streaming handlers send a comment frame
+// while waiting for the model, so proxies don't drop an idle connection.
+var sseHeartbeatInterval = 15 * time.Second
+
+// startSSEHeartbeat writes ": ping" comments under mu every
+// sseHeartbeatInterval. The returned stop func is idempotent and waits for the
+// ticker goroutine, so nothing is written after it returns.
+func startSSEHeartbeat(w http.ResponseWriter, flusher http.Flusher, mu *sync.Mutex) func() {
+	ticker := time.NewTicker(sseHeartbeatInterval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				_, err := w.Write([]byte(": ping\n\n"))
+				if err == nil {
+					flusher.Flush()
+				}
+				mu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
 func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, event string, payload any) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
@@ -674,6 +1256,39 @@ func (h *handler) getSymbolAnalysis(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *handler) getSymbolAnalysisStatuses(w http.ResponseWriter, r *http.Request) {
+	var payload symbolAnalysisStatusesPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if payload.Currency == "" {
+		writeError(w, http.StatusBadRequest, "currency is required")
+		return
+	}
+	result, err := h.core.GetSymbolAnalysisStatuses(payload.Symbols, payload.Currency)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) getSymbolAnalysisStatus(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	currency := r.URL.Query().Get("currency")
+	if symbol == "" || currency == "" {
+		writeError(w, http.StatusBadRequest, "symbol and currency are required")
+		return
+	}
+	result, err := h.core.GetSymbolAnalysisStatus(symbol, currency)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 func (h *handler) getSymbolAnalysisHistory(w http.ResponseWriter, r *http.Request) {
 	symbol := r.URL.Query().Get("symbol")
 	currency := r.URL.Query().Get("currency")
@@ -682,12 +1297,60 @@ func (h *handler) getSymbolAnalysisHistory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	limit := parseIntDefault(r.URL.Query().Get("limit"), 10)
-	results, err := h.core.GetSymbolAnalysisHistory(symbol, currency, limit)
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
+	if offset < 0 {
+		offset = 0
+	}
+	results, err := h.core.GetSymbolAnalysisHistory(symbol, currency, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if r.URL.Query().Get("paged") != "1" {
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+	total, err := h.core.GetSymbolAnalysisHistoryCount(symbol, currency)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, symbolAnalysisHistoryResponse{
+		Items:  results,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *handler) deleteSymbolAnalysis(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	deleted, err := h.core.DeleteSymbolAnalysis(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "symbol analysis not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted"})
+}
+
+func (h *handler) getTags(w http.ResponseWriter, r *http.Request) {
+	result, err := h.core.GetAllTags()
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, results)
+	writeJSON(w, http.StatusOK, result)
 }
 
 func (h *handler) getAccounts(w http.ResponseWriter, r *http.Request) {
@@ -701,16 +1364,22 @@ func (h *handler) getAccounts(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) addAccount(w http.ResponseWriter, r *http.Request) {
 	var payload addAccountPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	success, err := h.core.AddAccount(investlog.Account{
-		AccountID:   payload.AccountID,
-		AccountName: payload.AccountName,
-		Broker:      payload.Broker,
-		AccountType: payload.AccountType,
+		AccountID:         payload.AccountID,
+		AccountName:       payload.AccountName,
+		Broker:            payload.Broker,
+		AccountType:       payload.AccountType,
+		DefaultCommission: payload.DefaultCommission,
 	})
+	var invalid *investlog.ValidationError
+	if errors.As(err, &invalid) {
+		writeRequestError(w, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil || !success {
 		writeError(w, http.StatusBadRequest, "add account failed")
 		return
@@ -724,7 +1393,7 @@ func (h *handler) addAccount(w http.ResponseWriter, r *http.Request) {
 	for currency, amount := range balances {
 		if amount.IsPositive() {
 			_, _ = h.core.AddTransaction(investlog.AddTransactionRequest{
-				TransactionDate: investlog.TodayISOInShanghai(),
+				TransactionDate: h.core.TodayISO(),
 				Symbol:          "CASH",
 				TransactionType: "TRANSFER_IN",
 				AssetType:       "cash",
@@ -740,6 +1409,24 @@ func (h *handler) addAccount(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "created"})
 }
 
+func (h *handler) setAccountDefaultCommission(w http.ResponseWriter, r *http.Request) {
+	var payload accountDefaultCommissionPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	updated, err := h.core.SetAccountDefaultCommission(chi.URLParam(r, "id"), payload.DefaultCommission)
+	if err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !updated {
+		writeError(w, http.StatusNotFound, "account not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"default_commission": payload.DefaultCommission})
+}
+
 func (h *handler) deleteAccount(w http.ResponseWriter, r *http.Request) {
 	accountID := chi.URLParam(r, "id")
 	deleted, message, err := h.core.DeleteAccount(accountID)
@@ -765,8 +1452,8 @@ func (h *handler) getAssetTypes(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) addAssetType(w http.ResponseWriter, r *http.Request) {
 	var payload assetTypePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	_, err := h.core.AddAssetType(payload.Code, payload.Label)
@@ -803,13 +1490,13 @@ func (h *handler) getAllocationSettings(w http.ResponseWriter, r *http.Request)
 
 func (h *handler) setAllocationSetting(w http.ResponseWriter, r *http.Request) {
 	var payload allocationPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	_, err := h.core.SetAllocationSetting(payload.Currency, payload.AssetType, payload.MinPercent, payload.MaxPercent)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, http.StatusBadRequest, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
@@ -817,8 +1504,8 @@ func (h *handler) setAllocationSetting(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) deleteAllocationSetting(w http.ResponseWriter, r *http.Request) {
 	var payload allocationPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	deleted, err := h.core.DeleteAllocationSetting(payload.Currency, payload.AssetType)
@@ -844,8 +1531,8 @@ func (h *handler) getExchangeRates(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) setExchangeRate(w http.ResponseWriter, r *http.Request) {
 	var payload exchangeRatePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	_, err := h.core.SetExchangeRate(payload.FromCurrency, payload.ToCurrency, payload.Rate.InexactFloat64(), "manual")
@@ -868,6 +1555,28 @@ func (h *handler) refreshExchangeRates(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *handler) convertCurrency(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	amount, err := strconv.ParseFloat(strings.TrimSpace(query.Get("amount")), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid amount")
+		return
+	}
+	from := strings.ToUpper(strings.TrimSpace(query.Get("from")))
+	to := strings.ToUpper(strings.TrimSpace(query.Get("to")))
+	converted, err := h.core.Convert(amount, from, to)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"amount": amount,
+		"from":   from,
+		"to":     to,
+		"result": converted,
+	})
+}
+
 func (h *handler) getSymbols(w http.ResponseWriter, r *http.Request) {
 	result, err := h.core.GetSymbols()
 	if err != nil {
@@ -880,8 +1589,8 @@ func (h *handler) getSymbols(w http.ResponseWriter, r *http.Request) {
 func (h *handler) updateSymbol(w http.ResponseWriter, r *http.Request) {
 	symbol := chi.URLParam(r, "symbol")
 	var payload symbolUpdatePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	updated, err := h.core.UpdateSymbolMetadata(symbol, payload.Name, payload.AssetType, payload.AutoUpdate, payload.Sector, payload.Exchange)
@@ -899,8 +1608,8 @@ func (h *handler) updateSymbol(w http.ResponseWriter, r *http.Request) {
 func (h *handler) updateSymbolAssetType(w http.ResponseWriter, r *http.Request) {
 	symbol := chi.URLParam(r, "symbol")
 	var payload updateSymbolAssetTypePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	updated, oldType, newType, err := h.core.UpdateSymbolAssetType(symbol, payload.AssetType)
@@ -915,11 +1624,25 @@ func (h *handler) updateSymbolAssetType(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, map[string]string{"old_type": oldType, "new_type": newType})
 }
 
+func (h *handler) setSymbolTypeOverride(w http.ResponseWriter, r *http.Request) {
+	symbol := chi.URLParam(r, "symbol")
+	var payload symbolTypeOverridePayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := h.core.SetSymbolTypeOverride(symbol, payload.Currency, payload.SymbolType); err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
 func (h *handler) updateSymbolAutoUpdate(w http.ResponseWriter, r *http.Request) {
 	symbol := chi.URLParam(r, "symbol")
 	var payload updateSymbolAutoUpdatePayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	_, err := h.core.UpdateSymbolAutoUpdate(symbol, payload.AutoUpdate)
@@ -930,25 +1653,136 @@ func (h *handler) updateSymbolAutoUpdate(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
 }
 
-func (h *handler) getOperationLogs(w http.ResponseWriter, r *http.Request) {
-	limit := parseIntDefault(r.URL.Query().Get("limit"), 50)
-	offset := parseIntDefault(r.URL.Query().Get("offset"), 0)
-	result, err := h.core.GetOperationLogs(limit, offset)
+func (h *handler) updateSymbolInactive(w http.ResponseWriter, r *http.Request) {
+	symbol := chi.URLParam(r, "symbol")
+	var payload updateSymbolInactivePayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	found, err := h.core.SetSymbolInactive(symbol, payload.Currency, payload.Inactive)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "symbol not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *handler) fetchSymbolMetadata(w http.ResponseWriter, r *http.Request) {
+	symbol := chi.URLParam(r, "symbol")
+	var payload fetchSymbolMetadataPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	result, err := h.core.FetchSymbolMetadata(symbol, payload.Currency)
+	if err != nil {
+		if errors.Is(err, investlog.ErrMetadataUnavailable) {
+			writeErrorResponse(w, http.StatusBadGateway, err)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// refreshSymbolData refreshes a symbol's price, metadata and external data.
+// Failed steps are reported in the result, so the response is 200 unless the
+// input is invalid.
+func (h *handler) refreshSymbolData(w http.ResponseWriter, r *http.Request) {
+	symbol := chi.URLParam(r, "symbol")
+	var payload refreshSymbolPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	result, err := h.core.RefreshSymbolData(r.Context(), symbol, payload.Currency)
+	if err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, result)
 }
 
+func (h *handler) getOperationLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := investlog.OperationLogFilter{
+		OperationType: query.Get("operation_type"),
+		Symbol:        query.Get("symbol"),
+		StartDate:     query.Get("start_date"),
+		EndDate:       query.Get("end_date"),
+		Limit:         parseIntDefault(query.Get("limit"), 50),
+		Offset:        parseIntDefault(query.Get("offset"), 0),
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	result, err := h.core.GetOperationLogs(filter)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if query.Get("paged") != "1" {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	total, err := h.core.GetOperationLogCount(filter)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if result == nil {
+		result = []investlog.OperationLog{}
+	}
+	writeJSON(w, http.StatusOK, operationLogsResponse{
+		Items:  result,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
+}
+
 // Helpers.
 
-func decodeJSON(r *http.Request, dst any) error {
-	decoder := json.NewDecoder(r.Body)
+// defaultMaxJSONBodyBytes caps JSON request bodies unless
+// RouterOptions.MaxJSONBodyBytes says otherwise.
+const defaultMaxJSONBodyBytes int64 = 1 << 20
+
+// decodeJSON decodes a JSON request body into dst, rejecting unknown fields
+// and bodies over the router's size limit.
+func (h *handler) decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	limit := h.maxJSONBodyBytes
+	if limit == 0 {
+		limit = defaultMaxJSONBodyBytes
+	}
+	body := r.Body
+	if limit > 0 {
+		body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	decoder := json.NewDecoder(body)
 	decoder.DisallowUnknownFields()
 	return decoder.Decode(dst)
 }
 
+// writeDecodeError reports a decodeJSON failure: 413 for an oversized body,
+// 400 otherwise.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large (limit "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes)")
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
+
 func parseInt(value string) int {
 	if value == "" {
 		return 0
@@ -968,6 +1802,20 @@ func parseIntDefault(value string, fallback int) int {
 	return i
 }
 
+// parseIncludeCash reads the includeCash query parameter, which defaults to
+// true; false computes holdings percentages over non-cash assets only.
+func parseIncludeCash(r *http.Request) (bool, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("includeCash"))
+	if value == "" {
+		return true, nil
+	}
+	includeCash, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("includeCash must be true or false")
+	}
+	return includeCash, nil
+}
+
 func normalizeLimitOffset(limit, offset int) (int, int) {
 	if limit <= 0 {
 		limit = 100
@@ -978,6 +1826,11 @@ func normalizeLimitOffset(limit, offset int) (int, int) {
 	return limit, offset
 }
 
+type holdingsBySymbolRollupResponse struct {
+	Currencies investlog.HoldingsBySymbolResult `json:"currencies"`
+	Rollup     *investlog.HoldingsRollup        `json:"rollup"`
+}
+
 type transactionsResponse struct {
 	Items  []investlog.Transaction `json:"items"`
 	Total  int                     `json:"total"`
@@ -985,6 +1838,27 @@ type transactionsResponse struct {
 	Offset int                     `json:"offset"`
 }
 
+type operationLogsResponse struct {
+	Items  []investlog.OperationLog `json:"items"`
+	Total  int                      `json:"total"`
+	Limit  int                      `json:"limit"`
+	Offset int                      `json:"offset"`
+}
+
+type holdingsAnalysisHistoryResponse struct {
+	Items  []investlog.HoldingsAnalysisResult `json:"items"`
+	Total  int                                `json:"total"`
+	Limit  int                                `json:"limit"`
+	Offset int                                `json:"offset"`
+}
+
+type symbolAnalysisHistoryResponse struct {
+	Items  []investlog.SymbolAnalysisResult `json:"items"`
+	Total  int                              `json:"total"`
+	Limit  int                              `json:"limit"`
+	Offset int                              `json:"offset"`
+}
+
 func ptrString(value string) *string {
 	return &value
 }
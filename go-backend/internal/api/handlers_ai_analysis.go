@@ -21,8 +21,8 @@ func (h *handler) getAIAnalysisMethods(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) createAIAnalysisMethod(w http.ResponseWriter, r *http.Request) {
 	var payload aiAnalysisMethodPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -46,8 +46,8 @@ func (h *handler) updateAIAnalysisMethod(w http.ResponseWriter, r *http.Request)
 	}
 
 	var payload aiAnalysisMethodPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -86,10 +86,54 @@ func (h *handler) deleteAIAnalysisMethod(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted"})
 }
 
+func (h *handler) getAIAnalysisProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.core.ListAIAnalysisProfiles()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+func (h *handler) saveAIAnalysisProfile(w http.ResponseWriter, r *http.Request) {
+	var payload aiAnalysisProfilePayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	profile, err := h.core.SaveAIAnalysisProfile(investlog.AIAnalysisProfile{
+		Name:         payload.Name,
+		Language:     payload.Language,
+		Verbosity:    payload.Verbosity,
+		PrivacyMode:  payload.PrivacyMode,
+		TheoryTags:   payload.TheoryTags,
+		FallbackText: payload.FallbackText,
+	})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func (h *handler) deleteAIAnalysisProfile(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.core.DeleteAIAnalysisProfile(chi.URLParam(r, "name"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "ai analysis profile not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted"})
+}
+
 func (h *handler) runAIAnalysisStream(w http.ResponseWriter, r *http.Request) {
 	var payload aiAnalysisStreamPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 	if payload.MethodID <= 0 {
@@ -106,6 +150,11 @@ func (h *handler) runAIAnalysisStream(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "ai analysis method not found")
 		return
 	}
+	// Missing settings must surface as 412 before the stream commits to 200.
+	if err := h.core.CheckAISetup(); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -127,21 +176,25 @@ func (h *handler) runAIAnalysisStream(w http.ResponseWriter, r *http.Request) {
 		"stage":   "start",
 		"message": "开始执行 AI Analysis",
 	}); err != nil {
-		h.logger.Warn("ai analysis stream write failed", "stage", "start", "err", err)
+		h.requestLogger(r).Warn("ai analysis stream write failed", "stage", "start", "err", err)
 		return
 	}
 
+	stopHeartbeat := startSSEHeartbeat(w, flusher, &streamMu)
+	defer stopHeartbeat()
 	result, err := h.core.RunAIAnalysisStream(investlog.RunAIAnalysisRequest{
 		MethodID:  payload.MethodID,
 		Variables: payload.Variables,
+		Context:   r.Context(),
 	}, func(delta string) error {
 		if delta == "" {
 			return nil
 		}
 		return writeStreamEvent("delta", map[string]string{"text": delta})
 	})
+	stopHeartbeat()
 	if err != nil {
-		h.logger.Error("ai analysis stream failed", "method_id", payload.MethodID, "err", err)
+		h.requestLogger(r).Error("ai analysis stream failed", "method_id", payload.MethodID, "err", err)
 		_ = writeStreamEvent("error", map[string]string{"error": err.Error()})
 		_ = writeStreamEvent("done", map[string]any{"ok": false})
 		return
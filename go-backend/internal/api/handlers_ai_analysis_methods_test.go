@@ -107,6 +107,28 @@ func TestAIAnalysisStreamEndpointSuccess(t *testing.T) {
 	}
 }
 
+func TestAIAnalysisStreamEndpointSetupRequired(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/ai-analysis-methods", map[string]any{
+		"name":          "股票速览",
+		"system_prompt": "Analyze",
+		"user_prompt":   "Question",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/ai-analysis-methods: expected 200, got %d, body=%s", rr.Code, rr.Body.String())
+	}
+
+	streamResp := doStreamRequest(t, router, http.MethodPost, "/api/ai-analysis/stream", map[string]any{"method_id": 1})
+	if streamResp.status != http.StatusPreconditionFailed {
+		t.Fatalf("POST /api/ai-analysis/stream: expected 412, got %d, body=%s", streamResp.status, streamResp.body)
+	}
+	if !strings.Contains(streamResp.body, `"error_code":"SETUP_REQUIRED"`) || strings.Contains(streamResp.body, "event:") {
+		t.Fatalf("expected a plain SETUP_REQUIRED error body, got %s", streamResp.body)
+	}
+}
+
 func TestAIAnalysisRunDetailEndpointNotFound(t *testing.T) {
 	router, cleanup := setupTestRouter(t)
 	defer cleanup()
@@ -124,3 +146,26 @@ func setupAIStubServer(t *testing.T, body string) *httptest.Server {
 		_, _ = w.Write([]byte(body))
 	}))
 }
+
+func TestAIAnalysisProfileEndpointValidation(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPut, "/api/ai-analysis-profiles", map[string]any{
+		"name":      "weekly",
+		"verbosity": "chatty",
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for invalid verbosity, got %d: %s", rr.Code, rr.Body.String())
+	}
+	payload := parseJSON(rr)
+	fields, _ := payload["errors"].(map[string]any)
+	if fields["verbosity"] == nil {
+		t.Fatalf("expected a verbosity field error, got %v", payload)
+	}
+
+	rr = doRequest(router, http.MethodPut, "/api/ai-analysis-profiles", map[string]any{"name": "weekly"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a valid profile, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -103,8 +105,8 @@ func TestAISettingsEndpoints_MigratesLegacyProviderConfig(t *testing.T) {
 	if got := saved["base_url"]; got != "https://api.aicodemirror.com/api/gemini" {
 		t.Fatalf("unexpected migrated base_url: %v", got)
 	}
-	if got := saved["model"]; got != "gemini-2.5-flash" {
-		t.Fatalf("unexpected migrated model: %v", got)
+	if got := saved["model"]; got != "gpt-4o-mini" {
+		t.Fatalf("expected model kept as given, got %v", got)
 	}
 }
 
@@ -145,6 +147,51 @@ func TestAISettingsEndpointDefaultAllowNewSymbolsWhenOmitted(t *testing.T) {
 	}
 }
 
+func TestAISettingsEndpointKeepsTheoryTagsWhenOmitted(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	theoryTags := func(rr *httptest.ResponseRecorder) string {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+		}
+		var settings struct {
+			TheoryTags []string `json:"theory_tags"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&settings); err != nil {
+			t.Fatalf("decode settings: %v", err)
+		}
+		return strings.Join(settings.TheoryTags, ",")
+	}
+
+	rr := doRequest(router, http.MethodPut, "/api/ai-settings", map[string]any{
+		"model":       "gemini-2.5-flash",
+		"theory_tags": []string{"Graham", "Lynch"},
+	})
+	if got := theoryTags(rr); got != "Graham,Lynch" {
+		t.Fatalf("expected custom theory tags, got %q", got)
+	}
+
+	rr = doRequest(router, http.MethodPut, "/api/ai-settings", map[string]any{
+		"model": "gemini-2.5-flash",
+	})
+	if got := theoryTags(rr); got != "Graham,Lynch" {
+		t.Fatalf("expected omitted theory_tags to keep custom tags, got %q", got)
+	}
+	if got := theoryTags(doRequest(router, http.MethodGet, "/api/ai-settings", nil)); got != "Graham,Lynch" {
+		t.Fatalf("expected stored custom tags, got %q", got)
+	}
+
+	rr = doRequest(router, http.MethodPut, "/api/ai-settings", map[string]any{
+		"model":       "gemini-2.5-flash",
+		"theory_tags": []string{},
+	})
+	if got := theoryTags(rr); got != "Malkiel,Dalio,Buffett" {
+		t.Fatalf("expected empty theory_tags to reset to defaults, got %q", got)
+	}
+}
+
 func TestAISettingsEndpointsDBClosed(t *testing.T) {
 	router, cleanup := setupClosedRouter(t)
 	defer cleanup()
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type streamHTTPResponse struct {
@@ -64,8 +66,8 @@ func TestAIHoldingsAnalysisStreamEndpoint_MissingKey(t *testing.T) {
 		"model":    "gpt-4o-mini",
 		"currency": "USD",
 	})
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("missing api_key: expected 400, got %d, body: %s", rr.Code, rr.Body.String())
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing api_key: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
 	}
 }
 
@@ -79,8 +81,8 @@ func TestAISymbolAnalysisStreamEndpoint_MissingKey(t *testing.T) {
 		"symbol":   "AAPL",
 		"currency": "USD",
 	})
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("missing api_key: expected 400, got %d, body: %s", rr.Code, rr.Body.String())
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing api_key: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
 	}
 }
 
@@ -92,8 +94,8 @@ func TestAIAllocationAdviceStreamEndpoint_MissingKey(t *testing.T) {
 		"base_url": "https://example.com/v1",
 		"model":    "gpt-4o-mini",
 	})
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("missing api_key: expected 400, got %d, body: %s", rr.Code, rr.Body.String())
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing api_key: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
 	}
 }
 
@@ -421,3 +423,114 @@ func TestAIAllocationAdviceStreamEndpoint_InvalidCurrenciesEmitsErrorEvent(t *te
 		t.Fatalf("expected done=false marker, got body: %s", body)
 	}
 }
+
+func TestAIHoldingsAnalysisStreamEndpoint_HeartbeatWhileWaiting(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	previous := sseHeartbeatInterval
+	sseHeartbeatInterval = 20 * time.Millisecond
+	defer func() { sseHeartbeatInterval = previous }()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-heartbeat",
+		"account_name": "Heartbeat Account",
+	})
+	doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         10,
+		"price":            100,
+		"currency":         "USD",
+		"account_id":       "acc-heartbeat",
+		"asset_type":       "stock",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"mock-model","choices":[{"message":{"content":"{\"overall_summary\":\"ok\",\"risk_level\":\"balanced\",\"key_findings\":[],\"recommendations\":[],\"disclaimer\":\"仅供参考\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	rr := doStreamRequest(t, router, http.MethodPost, "/api/ai/holdings-analysis/stream", map[string]any{
+		"base_url": server.URL,
+		"api_key":  "key",
+		"model":    "mock-model",
+		"currency": "USD",
+	})
+	if rr.status != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body: %s", rr.status, rr.body)
+	}
+	ping := strings.Index(rr.body, ": ping\n\n")
+	if ping < 0 {
+		t.Fatalf("expected heartbeat comment while waiting, got body: %s", rr.body)
+	}
+	result := strings.Index(rr.body, "event: result")
+	if result < 0 {
+		t.Fatalf("expected result event, got body: %s", rr.body)
+	}
+	if ping > result || strings.Contains(rr.body[result:], ": ping") {
+		t.Fatalf("expected heartbeats only before the result, got body: %s", rr.body)
+	}
+}
+
+func TestAIAllocationAdviceStreamEndpoint_HeartbeatWhileWaiting(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	previous := sseHeartbeatInterval
+	sseHeartbeatInterval = 20 * time.Millisecond
+	defer func() { sseHeartbeatInterval = previous }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"{\\\"summary\\\":\\\"ok\\\",\\\"rationale\\\":\\\"r\\\",\\\"allocations\\\":[{\\\"currency\\\":\\\"USD\\\",\\\"asset_type\\\":\\\"stock\\\",\\\"label\\\":\\\"股票\\\",\\\"min_percent\\\":10,\\\"max_percent\\\":30,\\\"rationale\\\":\\\"x\\\"}],\\\"disclaimer\\\":\\\"仅供参考\\\"}\"}]}}],\"modelVersion\":\"gemini-2.5-flash\"}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	rr := doStreamRequest(t, router, http.MethodPost, "/api/ai/allocation-advice/stream", map[string]any{
+		"base_url":   server.URL,
+		"api_key":    "key",
+		"model":      "gemini-2.5-flash",
+		"currencies": []string{"USD"},
+	})
+	if rr.status != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body: %s", rr.status, rr.body)
+	}
+	ping := strings.Index(rr.body, ": ping\n\n")
+	if ping < 0 {
+		t.Fatalf("expected heartbeat comment while waiting, got body: %s", rr.body)
+	}
+	result := strings.Index(rr.body, "event: result")
+	if result < 0 {
+		t.Fatalf("expected result event, got body: %s", rr.body)
+	}
+	if ping > result || strings.Contains(rr.body[result:], ": ping") {
+		t.Fatalf("expected heartbeats only before the result, got body: %s", rr.body)
+	}
+}
+
+func TestStartSSEHeartbeat_StopsWriting(t *testing.T) {
+	previous := sseHeartbeatInterval
+	sseHeartbeatInterval = 5 * time.Millisecond
+	defer func() { sseHeartbeatInterval = previous }()
+
+	rec := httptest.NewRecorder()
+	var mu sync.Mutex
+	stop := startSSEHeartbeat(rec, rec, &mu)
+	time.Sleep(30 * time.Millisecond)
+	stop()
+	stop()
+
+	written := rec.Body.String()
+	if !strings.HasPrefix(written, ": ping\n\n") {
+		t.Fatalf("expected heartbeat frames, got %q", written)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if rec.Body.String() != written {
+		t.Fatalf("heartbeat kept writing after stop")
+	}
+}
@@ -73,8 +73,8 @@ func TestAIHoldingsAnalysisEndpoint(t *testing.T) {
 		"base_url": server.URL,
 		"model":    "mock-model",
 	})
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("missing api_key: expected 400, got %d", rr.Code)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing api_key: expected 422, got %d", rr.Code)
 	}
 }
 
@@ -208,3 +208,193 @@ func TestAIHoldingsAnalysisHandlerDecodesUnknownField(t *testing.T) {
 }
 
 var _ = investlog.HoldingsAnalysisResult{}
+
+func TestAIScopesEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodGet, "/api/ai/scopes", nil)
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Fatalf("GET /api/ai/scopes: expected empty list, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-scope",
+		"account_name": "Scope Account",
+	})
+	for _, txn := range []map[string]any{
+		{"symbol": "AAPL", "currency": "USD"},
+		{"symbol": "00700", "currency": "HKD"},
+	} {
+		txn["transaction_type"] = "BUY"
+		txn["quantity"] = 10
+		txn["price"] = 100
+		txn["account_id"] = "acc-scope"
+		txn["asset_type"] = "stock"
+		if rr := doRequest(router, http.MethodPost, "/api/transactions", txn); rr.Code != http.StatusOK {
+			t.Fatalf("seed transaction: %d %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/ai/scopes", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/ai/scopes: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var scopes []investlog.AnalyzableScope
+	if err := json.Unmarshal(rr.Body.Bytes(), &scopes); err != nil {
+		t.Fatalf("decode scopes: %v", err)
+	}
+	if len(scopes) != 2 || scopes[0].Currency != "HKD" || scopes[1].Currency != "USD" {
+		t.Fatalf("unexpected scopes: %+v", scopes)
+	}
+	if scopes[0].SymbolCount != 1 || scopes[1].SymbolCount != 1 {
+		t.Fatalf("unexpected symbol counts: %+v", scopes)
+	}
+}
+
+func TestAIResponseSchemasEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodGet, "/api/ai/schemas", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/ai/schemas: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var payload map[string]struct {
+		Type     string   `json:"type"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode schemas: %v", err)
+	}
+	if !strings.Contains(strings.Join(payload["symbol_synthesis"].Required, ","), "overall_rating") {
+		t.Fatalf("expected overall_rating required, got %v", payload["symbol_synthesis"].Required)
+	}
+	if !strings.Contains(strings.Join(payload["holdings_analysis"].Required, ","), "recommendations") {
+		t.Fatalf("expected recommendations required, got %v", payload["holdings_analysis"].Required)
+	}
+}
+
+func TestCompareSymbolsEndpointValidation(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/symbols/compare", map[string]any{
+		"api_key":  "test-key",
+		"model":    "gemini-2.5-flash",
+		"symbols":  []string{"AAPL"},
+		"currency": "USD",
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /api/symbols/compare: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "symbols") {
+		t.Fatalf("expected symbols field error, got %s", rr.Body.String())
+	}
+}
+
+func TestAIOptionsEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodGet, "/api/ai/options", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/ai/options: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var options investlog.AIOptions
+	if err := json.Unmarshal(rr.Body.Bytes(), &options); err != nil {
+		t.Fatalf("decode options: %v", err)
+	}
+	checks := []struct {
+		name   string
+		got    investlog.AIEnumOption
+		values string
+		def    string
+	}{
+		{"risk_profiles", options.RiskProfiles, "aggressive,balanced,conservative", "balanced"},
+		{"horizons", options.Horizons, "long,medium,short", "medium"},
+		{"advice_styles", options.AdviceStyles, "aggressive,balanced,conservative", "balanced"},
+		{"analysis_types", options.AnalysisTypes, "adhoc,monthly,weekly", "adhoc"},
+		{"theory_tags", options.TheoryTags, "Malkiel,Dalio,Buffett", "Malkiel"},
+	}
+	for _, check := range checks {
+		if got := strings.Join(check.got.Values, ","); got != check.values || check.got.Default != check.def {
+			t.Errorf("%s: expected %s (default %s), got %s (default %s)", check.name, check.values, check.def, got, check.got.Default)
+		}
+	}
+	if strings.Join(options.Currencies, ",") != strings.Join(investlog.Currencies, ",") {
+		t.Fatalf("expected currencies %v, got %v", investlog.Currencies, options.Currencies)
+	}
+}
+
+func TestAnalysisReportEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-report",
+		"account_name": "Report",
+	})
+	doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"symbol":           "600519",
+		"transaction_type": "BUY",
+		"quantity":         1,
+		"price":            1500,
+		"currency":         "CNY",
+		"account_id":       "acc-report",
+		"asset_type":       "stock",
+	})
+
+	rr := doRequest(router, http.MethodGet, "/api/report?currency=CNY&format=md", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/report: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/markdown") {
+		t.Fatalf("expected markdown content type, got %q", got)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "### 600519 (CNY)") || !strings.Contains(body, "Not yet analyzed.") {
+		t.Fatalf("unexpected report body: %s", body)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/report?currency=CNY&format=html", nil)
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Fatalf("expected html content type, got %q", got)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/report?format=pdf", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown format, got %d", rr.Code)
+	}
+}
+
+func TestCompareSymbolsEndpointReportsFailedSymbols(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"invalid api key"}}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	rr := doRequest(router, http.MethodPost, "/api/symbols/compare", map[string]any{
+		"base_url": server.URL,
+		"api_key":  "test-key",
+		"model":    "gemini-2.5-flash",
+		"symbols":  []string{"AAPL", "MSFT"},
+		"currency": "USD",
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("POST /api/symbols/compare: expected 400, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	body := parseJSON(rr)
+	result, _ := body["result"].(map[string]any)
+	verdicts, _ := result["verdicts"].([]any)
+	if len(verdicts) != 2 {
+		t.Fatalf("expected both verdicts in the error response, got %v", body)
+	}
+	for _, v := range verdicts {
+		if verdict, _ := v.(map[string]any); verdict["error"] == nil || verdict["error"] == "" {
+			t.Fatalf("expected each verdict to carry its error, got %v", verdicts)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *handler) getPriceAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.core.GetPriceAlerts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+func (h *handler) getTriggeredPriceAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.core.GetTriggeredPriceAlerts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+func (h *handler) setPriceAlert(w http.ResponseWriter, r *http.Request) {
+	var payload priceAlertPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	alert, err := h.core.SetPriceAlert(payload.Symbol, payload.Currency, payload.TargetPrice, payload.Direction)
+	if err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, alert)
+}
+
+func (h *handler) deletePriceAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	deleted, err := h.core.DeletePriceAlert(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "price alert not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted"})
+}
@@ -65,3 +65,22 @@ func TestHandlersInternalErrorsWhenDBClosed(t *testing.T) {
 		t.Fatalf("updateSymbol: expected 400, got %d", rr.Code)
 	}
 }
+
+func TestHealthReportsUnavailableWhenDBClosed(t *testing.T) {
+	router, cleanup := setupClosedRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodGet, "/api/health", nil)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GET /api/health: expected 503, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	payload := parseJSON(rr)
+	if payload["status"] != investlog.HealthStatusDegraded {
+		t.Fatalf("expected degraded status, got %v", payload["status"])
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/livez", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/livez: expected 200, got %d", rr.Code)
+	}
+}
@@ -69,8 +69,8 @@ func TestAllocationSettingErrors(t *testing.T) {
 		"min_percent": 10,
 		"max_percent": 20,
 	})
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400 for invalid asset type, got %d", rr.Code)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for invalid asset type, got %d", rr.Code)
 	}
 
 	// Deleting non-existent setting should 404.
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -55,3 +56,137 @@ func TestExchangeRatesEndpoints(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPut, "/api/exchange-rates", map[string]any{
+		"from_currency": "USD",
+		"to_currency":   "CNY",
+		"rate":          7,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT /api/exchange-rates: expected 200, got %d", rr.Code)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/convert?amount=70&from=CNY&to=usd", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/convert: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	payload := parseJSON(rr)
+	if result, _ := payload["result"].(float64); result < 9.9999 || result > 10.0001 {
+		t.Fatalf("expected 10 USD, got %v", payload["result"])
+	}
+	if payload["to"] != "USD" {
+		t.Fatalf("expected normalized to currency USD, got %v", payload["to"])
+	}
+
+	for _, path := range []string{
+		"/api/convert?amount=abc&from=CNY&to=USD",
+		"/api/convert?amount=1&from=EUR&to=USD",
+	} {
+		rr = doRequest(router, http.MethodGet, path, nil)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("GET %s: expected 400, got %d", path, rr.Code)
+		}
+	}
+
+	for _, amount := range []string{"NaN", "Inf", "-Infinity"} {
+		rr = doRequest(router, http.MethodGet, "/api/convert?amount="+amount+"&from=CNY&to=USD", nil)
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "finite") {
+			t.Fatalf("amount %s: expected 422, got %d: %s", amount, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestNetWorthEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-nw",
+		"account_name": "Net Worth",
+	})
+	doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         10,
+		"price":            100,
+		"currency":         "USD",
+		"account_id":       "acc-nw",
+		"asset_type":       "stock",
+	})
+	doRequest(router, http.MethodPut, "/api/exchange-rates", map[string]any{
+		"from_currency": "USD",
+		"to_currency":   "CNY",
+		"rate":          7,
+	})
+
+	rr := doRequest(router, http.MethodGet, "/api/networth?base=CNY", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/networth: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	payload := parseJSON(rr)
+	if payload["base_currency"] != "CNY" {
+		t.Fatalf("expected base CNY, got %v", payload["base_currency"])
+	}
+	if total, _ := payload["total"].(float64); total < 6999.99 || total > 7000.01 {
+		t.Fatalf("expected total 7000, got %v", payload["total"])
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/networth?base=EUR", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("GET /api/networth?base=EUR: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestHoldingsBySymbolRollupEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-rollup",
+		"account_name": "Rollup",
+	})
+	doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         10,
+		"price":            100,
+		"currency":         "USD",
+		"account_id":       "acc-rollup",
+		"asset_type":       "stock",
+	})
+	doRequest(router, http.MethodPut, "/api/exchange-rates", map[string]any{
+		"from_currency": "USD",
+		"to_currency":   "CNY",
+		"rate":          7,
+	})
+
+	rr := doRequest(router, http.MethodGet, "/api/holdings-by-symbol", nil)
+	if payload := parseJSON(rr); payload["USD"] == nil || payload["rollup"] != nil {
+		t.Fatalf("expected plain per-currency result without base, got %v", payload)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/holdings-by-symbol?base=CNY", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/holdings-by-symbol?base=CNY: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	payload := parseJSON(rr)
+	rollup, _ := payload["rollup"].(map[string]any)
+	if rollup == nil || rollup["base_currency"] != "CNY" {
+		t.Fatalf("expected CNY rollup, got %v", payload)
+	}
+	if total, _ := rollup["total_cost"].(float64); total < 6999.99 || total > 7000.01 {
+		t.Fatalf("expected rolled-up cost 7000, got %v", rollup["total_cost"])
+	}
+	if currencies, _ := payload["currencies"].(map[string]any); currencies["USD"] == nil {
+		t.Fatalf("expected per-currency holdings alongside rollup, got %v", payload["currencies"])
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/holdings-by-symbol?base=EUR", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("GET /api/holdings-by-symbol?base=EUR: expected 400, got %d", rr.Code)
+	}
+}
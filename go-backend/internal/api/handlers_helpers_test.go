@@ -1,6 +1,11 @@
 package api
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
 
 func TestNormalizeLimitOffset(t *testing.T) {
 	tests := []struct {
@@ -25,3 +30,33 @@ func TestNormalizeLimitOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeJSONBodyLimit(t *testing.T) {
+	router := NewRouterWithOptions(nil, RouterOptions{MaxJSONBodyBytes: 64})
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/simulate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(`{"symbol":"` + strings.Repeat("A", 100) + `","currency":"USD"}`)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for oversized body, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "request body too large (limit 64 bytes)") {
+		t.Fatalf("expected size error, got %s", rr.Body.String())
+	}
+
+	rr = post(`{"symbol":"AAPL","unknown":1}`)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "unknown field") {
+		t.Fatalf("expected 400 for unknown field, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	router = NewRouter(nil)
+	rr = post(`{"symbol":"` + strings.Repeat("A", int(defaultMaxJSONBodyBytes)) + `"}`)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 above the default limit, got %d", rr.Code)
+	}
+}
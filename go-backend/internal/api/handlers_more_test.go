@@ -3,6 +3,8 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -60,6 +62,545 @@ func TestPricesEndpoints_UpdateAndUpdateAll(t *testing.T) {
 	}
 }
 
+func TestAccountDefaultCommission(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":         "acc-1",
+		"account_name":       "Main",
+		"default_commission": 5,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/accounts: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	buy := func(extra map[string]any) float64 {
+		t.Helper()
+		payload := map[string]any{
+			"symbol":           "AAPL",
+			"transaction_type": "BUY",
+			"quantity":         1,
+			"price":            100,
+			"currency":         "USD",
+			"account_id":       "acc-1",
+			"asset_type":       "stock",
+		}
+		for k, v := range extra {
+			payload[k] = v
+		}
+		rr := doRequest(router, http.MethodPost, "/api/transactions", payload)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("POST /api/transactions: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+		}
+		var created struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+			t.Fatalf("decode created transaction: %v", err)
+		}
+		rr = doRequest(router, http.MethodGet, "/api/transactions", nil)
+		var txns []struct {
+			ID         int64   `json:"id"`
+			Commission float64 `json:"commission"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&txns); err != nil {
+			t.Fatalf("decode transactions: %v", err)
+		}
+		for _, txn := range txns {
+			if txn.ID == created.ID {
+				return txn.Commission
+			}
+		}
+		t.Fatalf("transaction %d not found", created.ID)
+		return 0
+	}
+
+	if got := buy(nil); got != 5 {
+		t.Fatalf("omitted commission: expected default 5, got %v", got)
+	}
+	if got := buy(map[string]any{"commission": 0, "force": true}); got != 0 {
+		t.Fatalf("explicit zero commission: expected 0, got %v", got)
+	}
+
+	rr = doRequest(router, http.MethodPut, "/api/accounts/acc-1/default-commission", map[string]any{"default_commission": -1})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("negative default: expected 422, got %d", rr.Code)
+	}
+	rr = doRequest(router, http.MethodPut, "/api/accounts/missing/default-commission", map[string]any{"default_commission": 1})
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("missing account: expected 404, got %d", rr.Code)
+	}
+}
+
+func TestDeletedTransactionsEndpoints(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acc-1", "account_name": "Main"})
+	rr := doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         10,
+		"price":            100,
+		"currency":         "USD",
+		"account_id":       "acc-1",
+		"asset_type":       "stock",
+	})
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode created transaction: %v", err)
+	}
+	id := strconv.FormatInt(created.ID, 10)
+
+	if rr = doRequest(router, http.MethodDelete, "/api/transactions/"+id, nil); rr.Code != http.StatusOK {
+		t.Fatalf("DELETE transaction: expected 200, got %d", rr.Code)
+	}
+	rr = doRequest(router, http.MethodGet, "/api/transactions/deleted", nil)
+	var deleted []map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&deleted); err != nil {
+		t.Fatalf("decode deleted transactions: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0]["symbol"] != "AAPL" || deleted[0]["deleted_at"] == "" {
+		t.Fatalf("unexpected deleted transactions: %v", deleted)
+	}
+
+	if rr = doRequest(router, http.MethodPost, "/api/transactions/"+id+"/restore", nil); rr.Code != http.StatusOK {
+		t.Fatalf("restore transaction: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if rr = doRequest(router, http.MethodPost, "/api/transactions/"+id+"/restore", nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("restore twice: expected 404, got %d", rr.Code)
+	}
+}
+
+func TestHoldingsByExchangeEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acc-1", "account_name": "Main"})
+	rr := doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         10,
+		"price":            100,
+		"currency":         "USD",
+		"account_id":       "acc-1",
+		"asset_type":       "stock",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/transactions: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/holdings/by-exchange?currency=USD", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/holdings/by-exchange: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]struct {
+		Markets []struct {
+			Market  string  `json:"market"`
+			Percent float64 `json:"percent"`
+		} `json:"markets"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	markets := resp["USD"].Markets
+	if len(markets) != 1 || markets[0].Market != "US" || markets[0].Percent != 100 {
+		t.Fatalf("unexpected markets: %+v", markets)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/holdings/by-exchange?currency=XYZ", nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid currency: expected 422, got %d", rr.Code)
+	}
+}
+
+func TestSimulatePositionEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/simulate", map[string]any{
+		"symbol":   "AAPL",
+		"currency": "USD",
+		"quantity": 10,
+		"price":    150,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/simulate: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		PositionPercent float64 `json:"position_percent"`
+		Cost            float64 `json:"cost"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.PositionPercent != 100 || resp.Cost != 1500 {
+		t.Fatalf("expected sole position at 100%% costing 1500, got %+v", resp)
+	}
+
+	rr = doRequest(router, http.MethodPost, "/api/simulate", map[string]any{
+		"symbol":   "AAPL",
+		"currency": "USD",
+		"quantity": 0,
+		"price":    150,
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /api/simulate invalid quantity: expected 422, got %d", rr.Code)
+	}
+}
+
+func TestPriceDiagnosticsEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	// CASH has no external sources, so no network is touched.
+	rr := doRequest(router, http.MethodGet, "/api/price/diagnostics?symbol=CASH&currency=USD&asset_type=cash", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/price/diagnostics: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		SymbolType string `json:"symbol_type"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.SymbolType != "cash" {
+		t.Fatalf("expected cash symbol type, got %q", resp.SymbolType)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/price/diagnostics", nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing symbol: expected 422, got %d", rr.Code)
+	}
+}
+
+func TestClassifySymbolEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodGet, "/api/symbols/classify?symbol=aapl&currency=USD", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/symbols/classify: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Symbol     string   `json:"symbol"`
+		SymbolType string   `json:"symbol_type"`
+		Sources    []string `json:"sources"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Symbol != "AAPL" || resp.SymbolType != "us_stock" || len(resp.Sources) == 0 || resp.Sources[0] != "Yahoo Finance" {
+		t.Fatalf("unexpected classification: %+v", resp)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/symbols/classify", nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing symbol: expected 422, got %d", rr.Code)
+	}
+}
+
+func TestAddTransactionDuplicateRequiresForce(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acc-1", "account_name": "Main"})
+	txn := map[string]any{
+		"transaction_date": "2024-05-06",
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         5,
+		"price":            180,
+		"currency":         "USD",
+		"account_id":       "acc-1",
+		"asset_type":       "stock",
+	}
+	rr := doRequest(router, http.MethodPost, "/api/transactions", txn)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("first POST: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode created: %v", err)
+	}
+
+	rr = doRequest(router, http.MethodPost, "/api/transactions", txn)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("duplicate POST: expected 409, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var conflict struct {
+		ErrorCode  string `json:"error_code"`
+		ExistingID int64  `json:"existing_id"`
+		RequestID  string `json:"request_id"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&conflict); err != nil {
+		t.Fatalf("decode conflict: %v", err)
+	}
+	if conflict.ErrorCode != "DUPLICATE" || conflict.ExistingID != created.ID ||
+		conflict.RequestID == "" || conflict.RequestID != rr.Header().Get("X-Request-ID") {
+		t.Fatalf("unexpected conflict body: %+v", conflict)
+	}
+
+	txn["force"] = true
+	rr = doRequest(router, http.MethodPost, "/api/transactions", txn)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("forced POST: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAddTransactionTotalAmountMismatchRequiresForce(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acc-1", "account_name": "Main"})
+	txn := map[string]any{
+		"transaction_date": "2024-05-06",
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         5,
+		"price":            180,
+		"total_amount":     950,
+		"currency":         "USD",
+		"account_id":       "acc-1",
+		"asset_type":       "stock",
+	}
+	rr := doRequest(router, http.MethodPost, "/api/transactions", txn)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("mismatched POST: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	txn["force"] = true
+	rr = doRequest(router, http.MethodPost, "/api/transactions", txn)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("forced POST: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAnnualPerformanceEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acc-1", "account_name": "Main"})
+	for _, txn := range []map[string]any{
+		{"transaction_date": "2023-03-01", "transaction_type": "BUY", "quantity": 10, "price": 10},
+		{"transaction_date": "2024-03-01", "transaction_type": "SELL", "quantity": 10, "price": 12},
+	} {
+		txn["symbol"] = "600000"
+		txn["currency"] = "CNY"
+		txn["account_id"] = "acc-1"
+		txn["asset_type"] = "stock"
+		if rr := doRequest(router, http.MethodPost, "/api/transactions", txn); rr.Code != http.StatusOK {
+			t.Fatalf("POST /api/transactions: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := doRequest(router, http.MethodGet, "/api/performance/annual?currency=CNY", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/performance/annual: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var result struct {
+		Years []struct {
+			Year        int     `json:"year"`
+			RealizedPnL float64 `json:"realized_pnl"`
+		} `json:"years"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("decode performance: %v", err)
+	}
+	if len(result.Years) != 2 || result.Years[1].Year != 2024 || result.Years[1].RealizedPnL != 20 {
+		t.Fatalf("unexpected performance: %+v", result)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/performance/annual?currency=EUR", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("GET /api/performance/annual?currency=EUR: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestReportingSettingsEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPut, "/api/reporting-settings", map[string]any{"default_base_currency": "EUR"})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("PUT /api/reporting-settings EUR: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	rr = doRequest(router, http.MethodPut, "/api/reporting-settings", map[string]any{"default_base_currency": "hkd"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT /api/reporting-settings: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	rr = doRequest(router, http.MethodGet, "/api/reporting-settings", nil)
+	var settings struct {
+		DefaultBaseCurrency string `json:"default_base_currency"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&settings); err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if settings.DefaultBaseCurrency != "HKD" {
+		t.Fatalf("expected HKD, got %q", settings.DefaultBaseCurrency)
+	}
+
+	for path, want := range map[string]string{
+		"/api/networth":                        "HKD",
+		"/api/networth?base=USD":               "USD",
+		"/api/performance/annual":              "HKD",
+		"/api/performance/annual?currency=CNY": "CNY",
+	} {
+		rr := doRequest(router, http.MethodGet, path, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d, body: %s", path, rr.Code, rr.Body.String())
+		}
+		var result struct {
+			BaseCurrency string `json:"base_currency"`
+			Currency     string `json:"currency"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		if got := result.BaseCurrency + result.Currency; got != want {
+			t.Errorf("GET %s: expected %s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestWatchlistEndpoints(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/watchlist", map[string]any{"symbol": "nvda", "currency": "USD"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/watchlist: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var item struct {
+		ID     int64  `json:"id"`
+		Symbol string `json:"symbol"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&item); err != nil {
+		t.Fatalf("decode watchlist item: %v", err)
+	}
+	if item.Symbol != "NVDA" {
+		t.Fatalf("expected NVDA, got %q", item.Symbol)
+	}
+
+	rr = doRequest(router, http.MethodPost, "/api/watchlist", map[string]any{"symbol": "", "currency": "USD"})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /api/watchlist without symbol: expected 422, got %d", rr.Code)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/watchlist", nil)
+	var items []map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatalf("decode watchlist: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 watchlist item, got %d", len(items))
+	}
+
+	path := "/api/watchlist/" + strconv.FormatInt(item.ID, 10)
+	if rr := doRequest(router, http.MethodDelete, path, nil); rr.Code != http.StatusOK {
+		t.Fatalf("DELETE %s: expected 200, got %d", path, rr.Code)
+	}
+	if rr := doRequest(router, http.MethodDelete, path, nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("DELETE %s again: expected 404, got %d", path, rr.Code)
+	}
+}
+
+func TestTagsEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acc-1", "account_name": "Main"})
+	for i, tags := range []string{"long,tech", `["tech"]`} {
+		rr := doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+			"symbol":           "AAPL",
+			"transaction_type": "BUY",
+			"quantity":         i + 1,
+			"price":            100,
+			"currency":         "USD",
+			"account_id":       "acc-1",
+			"asset_type":       "stock",
+			"tags":             tags,
+		})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("POST /api/transactions: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := doRequest(router, http.MethodGet, "/api/tags", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/tags: expected 200, got %d", rr.Code)
+	}
+	var tags []struct {
+		Tag   string `json:"tag"`
+		Count int    `json:"count"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&tags); err != nil {
+		t.Fatalf("decode tags: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Tag != "long" || tags[0].Count != 1 || tags[1].Tag != "tech" || tags[1].Count != 2 {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+}
+
+func TestHistoricalPriceEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	// CASH has no external sources, so no network is touched.
+	rr := doRequest(router, http.MethodGet, "/api/price/historical?symbol=CASH&currency=USD&asset_type=cash&date=2024-01-02", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/price/historical: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Date  string  `json:"date"`
+		Price float64 `json:"price"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Date != "2024-01-02" || resp.Price != 1 {
+		t.Fatalf("unexpected historical price: %+v", resp)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/price/historical?symbol=CASH&currency=USD&date=01/02/2024", nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("bad date: expected 422, got %d", rr.Code)
+	}
+}
+
+func TestPriceCircuitSettingsEndpoints(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPut, "/api/price-circuit-settings", map[string]any{
+		"fail_threshold":      2,
+		"fail_window_seconds": 30,
+		"cooldown_seconds":    300,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT /api/price-circuit-settings: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/price-circuit-settings", nil)
+	var resp map[string]int
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["fail_threshold"] != 2 || resp["fail_window_seconds"] != 30 || resp["cooldown_seconds"] != 300 {
+		t.Fatalf("unexpected settings: %v", resp)
+	}
+
+	rr = doRequest(router, http.MethodPut, "/api/price-circuit-settings", map[string]any{"fail_threshold": 0})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid settings: expected 422, got %d", rr.Code)
+	}
+}
+
 func TestParseHelpers(t *testing.T) {
 	if got := parseInt(""); got != 0 {
 		t.Fatalf("parseInt empty: got %d", got)
@@ -87,3 +628,147 @@ func TestParseHelpers(t *testing.T) {
 		t.Fatalf("ptrString: expected %q", value)
 	}
 }
+
+func TestTopMoversEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodGet, "/api/holdings/top-movers?currency=USD&n=3", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/holdings/top-movers: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Currency    string            `json:"currency"`
+		Gainers     []json.RawMessage `json:"gainers"`
+		Losers      []json.RawMessage `json:"losers"`
+		NoDayChange []string          `json:"no_day_change"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Currency != "USD" || resp.Gainers == nil || resp.Losers == nil || resp.NoDayChange == nil {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/holdings/top-movers?currency=XYZ", nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid currency: expected 422, got %d", rr.Code)
+	}
+}
+
+func TestPriceAlertEndpoints(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/alerts", map[string]any{
+		"symbol":       "aapl",
+		"currency":     "USD",
+		"target_price": 200,
+		"direction":    "above",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/alerts: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var alert struct {
+		ID     int64  `json:"id"`
+		Symbol string `json:"symbol"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&alert); err != nil {
+		t.Fatalf("decode alert: %v", err)
+	}
+	if alert.Symbol != "AAPL" {
+		t.Fatalf("expected AAPL, got %q", alert.Symbol)
+	}
+
+	rr = doRequest(router, http.MethodPost, "/api/alerts", map[string]any{
+		"symbol": "AAPL", "currency": "USD", "target_price": 200, "direction": "sideways",
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /api/alerts with bad direction: expected 422, got %d", rr.Code)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/alerts", nil)
+	var alerts []map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&alerts); err != nil {
+		t.Fatalf("decode alerts: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/alerts/triggered", nil)
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Fatalf("GET /api/alerts/triggered: expected empty list, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	path := "/api/alerts/" + strconv.FormatInt(alert.ID, 10)
+	if rr := doRequest(router, http.MethodDelete, path, nil); rr.Code != http.StatusOK {
+		t.Fatalf("DELETE %s: expected 200, got %d", path, rr.Code)
+	}
+	if rr := doRequest(router, http.MethodDelete, path, nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("DELETE %s again: expected 404, got %d", path, rr.Code)
+	}
+}
+
+func TestTransactionSummaryEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{"account_id": "acct", "account_name": "Account"})
+	rr := doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+		"transaction_date": "2024-03-05",
+		"symbol":           "AAPL",
+		"transaction_type": "BUY",
+		"quantity":         10,
+		"price":            100,
+		"currency":         "USD",
+		"account_id":       "acct",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/transactions: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/transactions/summary?currency=USD", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/transactions/summary: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Count   int `json:"count"`
+		ByMonth []struct {
+			Month string `json:"month"`
+		} `json:"by_month"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode summary: %v", err)
+	}
+	if resp.Count != 1 || len(resp.ByMonth) != 1 || resp.ByMonth[0].Month != "2024-03" {
+		t.Fatalf("unexpected summary: %+v", resp)
+	}
+}
+
+func TestHoldingsIncludeCashParam(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	for _, path := range []string{"/api/holdings-by-symbol", "/api/holdings-by-currency"} {
+		if rr := doRequest(router, http.MethodGet, path+"?includeCash=false", nil); rr.Code != http.StatusOK {
+			t.Fatalf("GET %s?includeCash=false: expected 200, got %d, body: %s", path, rr.Code, rr.Body.String())
+		}
+		if rr := doRequest(router, http.MethodGet, path+"?includeCash=maybe", nil); rr.Code != http.StatusBadRequest {
+			t.Fatalf("GET %s?includeCash=maybe: expected 400, got %d", path, rr.Code)
+		}
+	}
+}
+
+func TestRefreshSymbolEndpoint_Validation(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/symbols/AAPL/refresh", map[string]any{"currency": "XYZ"})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /api/symbols/AAPL/refresh: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "currency") {
+		t.Fatalf("expected currency field error, got %s", rr.Body.String())
+	}
+}
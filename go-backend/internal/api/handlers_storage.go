@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,7 +17,7 @@ import (
 
 func (h *handler) coreLockMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/storage/switch" {
+		if r.URL.Path == "/api/storage/switch" || r.URL.Path == "/api/restore" || r.URL.Path == "/api/livez" {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -93,8 +95,8 @@ func (h *handler) switchStorage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var payload storageSwitchPayload
-	if err := decodeJSON(r, &payload); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -116,6 +118,7 @@ func (h *handler) switchStorage(w http.ResponseWriter, r *http.Request) {
 	if h.core != nil {
 		currentPath = h.core.DBPath()
 	}
+	timeZone := h.core.TimeZone()
 	h.coreMu.RUnlock()
 	if currentPath != "" && filepath.Clean(currentPath) == filepath.Clean(targetPath) {
 		writeJSON(w, http.StatusOK, map[string]string{"status": "active", "db_name": dbName})
@@ -138,10 +141,7 @@ func (h *handler) switchStorage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger := h.logger
-	newCore, err := investlog.OpenWithOptions(investlog.Options{
-		DBPath: targetPath,
-		Logger: logger,
-	})
+	newCore, err := h.openCore(targetPath, timeZone)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Errorf("open storage file: %w", err).Error())
 		return
@@ -161,6 +161,7 @@ func (h *handler) switchStorage(w http.ResponseWriter, r *http.Request) {
 	h.coreMu.Lock()
 	oldCore := h.core
 	h.core = newCore
+	h.startCleanup(newCore)
 	h.coreMu.Unlock()
 
 	if oldCore != nil {
@@ -172,6 +173,116 @@ func (h *handler) switchStorage(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "switched", "db_name": dbName})
 }
 
+// defaultMaxBackupBytes caps backup uploads unless RouterOptions.MaxBackupBytes
+// says otherwise.
+const defaultMaxBackupBytes int64 = 256 << 20
+
+// restoreBackup replaces the active database with an uploaded SQLite backup and
+// reopens the core on the restored file. The upload may be sent as the raw
+// request body or as a multipart "file" field. It is received and validated
+// before coreMu is taken, so only the file swap blocks other requests.
+func (h *handler) restoreBackup(w http.ResponseWriter, r *http.Request) {
+	limit := h.maxBackupBytes
+	if limit == 0 {
+		limit = defaultMaxBackupBytes
+	}
+	if limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	body := io.Reader(r.Body)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeDecodeError(w, fmt.Errorf("read backup file: %w", err))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	h.coreMu.RLock()
+	dbPath := ""
+	if h.core != nil {
+		dbPath = h.core.DBPath()
+	}
+	h.coreMu.RUnlock()
+	if dbPath == "" {
+		writeError(w, http.StatusServiceUnavailable, "storage is not initialized")
+		return
+	}
+	staged, err := investlog.StageBackup(dbPath, body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeDecodeError(w, err)
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer func() {
+		_ = os.Remove(staged)
+	}()
+
+	h.coreMu.Lock()
+	defer h.coreMu.Unlock()
+	if h.core == nil {
+		writeError(w, http.StatusServiceUnavailable, "storage is not initialized")
+		return
+	}
+
+	if err := h.core.RestoreFromStagedBackup(staged); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// RestoreFromStagedBackup closes the old core, so it must be replaced
+	// before any other request is allowed through.
+	dbPath = h.core.DBPath()
+	newCore, err := h.openCore(dbPath, h.core.TimeZone())
+	if err != nil {
+		h.requestLogger(r).Error("failed to reopen core after restore", "db_path", dbPath, "err", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error":            fmt.Errorf("reopen restored database: %w", err).Error(),
+			"restart_required": true,
+		})
+		return
+	}
+	h.core = newCore
+	h.startCleanup(newCore)
+
+	writeJSON(w, http.StatusOK, map[string]any{"status": "restored", "restart_required": false})
+}
+
+// openCore opens dbPath with the options the router was built with, so a
+// switched or restored database keeps the server's configuration. timeZone
+// carries over the active core's zone when the options leave it unset.
+func (h *handler) openCore(dbPath, timeZone string) (*investlog.Core, error) {
+	opts := h.coreOpts
+	opts.DBPath = dbPath
+	if opts.Logger == nil {
+		opts.Logger = h.logger
+	}
+	if opts.TimeZone == "" {
+		opts.TimeZone = timeZone
+	}
+	return investlog.OpenWithOptions(opts)
+}
+
+// startCleanup moves the stale analysis cleanup onto core. Callers replacing
+// h.core hold coreMu.
+func (h *handler) startCleanup(core *investlog.Core) {
+	if h.cleanupCtx == nil {
+		return
+	}
+	if h.stopCleanup != nil {
+		h.stopCleanup()
+	}
+	ctx, cancel := context.WithCancel(h.cleanupCtx)
+	h.stopCleanup = cancel
+	core.StartStaleAnalysisCleanup(ctx, 0, 0)
+}
+
 func sanitizeDBName(raw string) (string, error) {
 	name := strings.TrimSpace(raw)
 	if name == "" {
@@ -1,11 +1,14 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"investlog/internal/config"
@@ -230,6 +233,141 @@ func TestSwitchStorageDisabledByEnv(t *testing.T) {
 	}
 }
 
+func TestRestoreBackup(t *testing.T) {
+	router, cleanup, dataDir, _ := setupStorageRouter(t)
+	defer cleanup()
+
+	post := func(body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/restore", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("invalid file", func(t *testing.T) {
+		rr := post([]byte("definitely not sqlite"))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("valid backup", func(t *testing.T) {
+		snapshotPath := filepath.Join(dataDir, "snapshot.db")
+		snapshotCore, err := investlog.Open(snapshotPath)
+		if err != nil {
+			t.Fatalf("open snapshot db: %v", err)
+		}
+		if _, err := snapshotCore.AddAccount(investlog.Account{AccountID: "restored", AccountName: "Restored"}); err != nil {
+			t.Fatalf("add account: %v", err)
+		}
+		if err := snapshotCore.Close(); err != nil {
+			t.Fatalf("close snapshot db: %v", err)
+		}
+		snapshot, err := os.ReadFile(snapshotPath)
+		if err != nil {
+			t.Fatalf("read snapshot: %v", err)
+		}
+
+		rr := post(snapshot)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		payload := parseJSON(rr)
+		if payload["status"] != "restored" || payload["restart_required"] != false {
+			t.Fatalf("unexpected payload: %v", payload)
+		}
+
+		rr = doRequest(router, http.MethodGet, "/api/accounts", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200 after restore, got %d", rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), `"restored"`) {
+			t.Fatalf("expected restored account, got %s", rr.Body.String())
+		}
+	})
+}
+
+func TestRestoreBackupRejectsOversizedUpload(t *testing.T) {
+	_, cleanup, dataDir, dbName := setupStorageRouter(t)
+	cleanup()
+	core, err := investlog.Open(filepath.Join(dataDir, dbName))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	defer core.Close()
+	router := NewRouterWithOptions(core, RouterOptions{MaxBackupBytes: 64})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/restore", strings.NewReader(strings.Repeat("x", 65)))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if matches, _ := filepath.Glob(core.DBPath() + ".*"); len(matches) != 0 {
+		t.Fatalf("expected the staged upload to be removed, got %v", matches)
+	}
+	if _, err := core.GetAccounts(); err != nil {
+		t.Fatalf("core unusable after rejected restore: %v", err)
+	}
+}
+
+func TestStorageReopenKeepsCoreOptions(t *testing.T) {
+	_, cleanup, dataDir, dbName := setupStorageRouter(t)
+	cleanup()
+	coreOpts := investlog.Options{DBPath: filepath.Join(dataDir, dbName), ImportMaxRows: 1}
+	core, err := investlog.OpenWithOptions(coreOpts)
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	defer core.Close()
+	router := NewRouterWithOptions(core, RouterOptions{CoreOptions: coreOpts})
+
+	assertImportLimited := func(stage string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/transactions/import", strings.NewReader(
+			"symbol,transaction_type,quantity,price,account_id\nAAPL,BUY,1,1,acc\nMSFT,BUY,1,1,acc\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "limit of 1 rows") {
+			t.Fatalf("%s: expected the configured row limit, got %d: %s", stage, rr.Code, rr.Body.String())
+		}
+	}
+	assertImportLimited("initial")
+
+	snapshotPath := filepath.Join(dataDir, "snapshot.db")
+	snapshotCore, err := investlog.Open(snapshotPath)
+	if err != nil {
+		t.Fatalf("open snapshot db: %v", err)
+	}
+	if err := snapshotCore.Close(); err != nil {
+		t.Fatalf("close snapshot db: %v", err)
+	}
+	snapshot, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/restore", bytes.NewReader(snapshot))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected restore 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	assertImportLimited("after restore")
+
+	rr = doRequest(router, http.MethodPost, "/api/storage/switch", map[string]interface{}{
+		"db_name": "beta.db",
+		"create":  true,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected switch 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	assertImportLimited("after switch")
+}
+
 func setupStorageRouter(t *testing.T) (http.Handler, func(), string, string) {
 	t.Helper()
 
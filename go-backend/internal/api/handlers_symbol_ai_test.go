@@ -118,8 +118,8 @@ func TestSymbolAnalysisEndpoint_MissingKey(t *testing.T) {
 		"symbol":   "AAPL",
 		"currency": "USD",
 	})
-	if rr.Code != http.StatusBadRequest {
-		t.Fatalf("missing api_key: expected 400, got %d, body: %s", rr.Code, rr.Body.String())
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("missing api_key: expected 422, got %d, body: %s", rr.Code, rr.Body.String())
 	}
 }
 
@@ -215,6 +215,26 @@ func TestGetSymbolAnalysisHistory_Limit(t *testing.T) {
 	if len(results) < 1 {
 		t.Fatalf("expected at least 1 history entry, got %d", len(results))
 	}
+
+	rr = doRequest(router, http.MethodGet, "/api/ai/symbol-analysis/history?symbol=AAPL&currency=USD&limit=5&offset=1&paged=1", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET paged history: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var paged struct {
+		Items  []map[string]any `json:"items"`
+		Total  int              `json:"total"`
+		Limit  int              `json:"limit"`
+		Offset int              `json:"offset"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&paged); err != nil {
+		t.Fatalf("decode paged history response: %v", err)
+	}
+	if paged.Total != len(results) || paged.Limit != 5 || paged.Offset != 1 {
+		t.Fatalf("unexpected paged envelope: total=%d limit=%d offset=%d", paged.Total, paged.Limit, paged.Offset)
+	}
+	if len(paged.Items) != len(results)-1 {
+		t.Fatalf("expected %d items after offset, got %d", len(results)-1, len(paged.Items))
+	}
 }
 
 // Ensure the types compile.
@@ -229,3 +249,115 @@ var (
 	_ = io.ReadAll
 	_ = httptest.NewServer
 )
+
+func TestSymbolAnalysisResynthesizeEndpoint_Errors(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	payload := map[string]any{"base_url": "https://example.com/v1", "api_key": "k", "model": "m"}
+	rr := doRequest(router, http.MethodPost, "/api/ai/symbol-analysis/abc/resynthesize", payload)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid id, got %d", rr.Code)
+	}
+	rr = doRequest(router, http.MethodPost, "/api/ai/symbol-analysis/42/resynthesize", payload)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing analysis, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSymbolAnalysisRetryEndpoint_Errors(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	payload := map[string]any{"api_key": "k"}
+	rr := doRequest(router, http.MethodPost, "/api/symbol-analysis/abc/retry", payload)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid id, got %d", rr.Code)
+	}
+	rr = doRequest(router, http.MethodPost, "/api/symbol-analysis/42/retry", payload)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing analysis, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSymbolPositionWeightEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	doRequest(router, http.MethodPost, "/api/accounts", map[string]any{
+		"account_id":   "acc-pos",
+		"account_name": "Position Account",
+	})
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		doRequest(router, http.MethodPost, "/api/transactions", map[string]any{
+			"symbol":           symbol,
+			"transaction_type": "BUY",
+			"quantity":         10,
+			"price":            100,
+			"currency":         "USD",
+			"account_id":       "acc-pos",
+			"asset_type":       "stock",
+		})
+	}
+
+	rr := doRequest(router, http.MethodGet, "/api/ai/symbol-analysis/position?symbol=AAPL&currency=USD", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var weight investlog.SymbolPositionWeight
+	if err := json.NewDecoder(rr.Body).Decode(&weight); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if weight.Basis != "currency" || weight.PositionPercent != 50 {
+		t.Fatalf("unexpected weight: %+v", weight)
+	}
+
+	rr = doRequest(router, http.MethodGet, "/api/ai/symbol-analysis/position?symbol=AAPL&currency=USD&basis=bogus", nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for invalid basis, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"position_basis"`) {
+		t.Fatalf("expected basis field error, got %s", rr.Body.String())
+	}
+}
+
+func TestSymbolAnalysisStatusesEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, http.MethodPost, "/api/symbols/analysis/statuses", map[string]any{
+		"symbols":  []string{"AAPL", "MSFT"},
+		"currency": "USD",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/symbols/analysis/statuses: expected 200, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var statuses []investlog.SymbolAnalysisStatus
+	if err := json.Unmarshal(rr.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("decode statuses: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Symbol != "AAPL" || statuses[0].Status != "none" {
+		t.Fatalf("unexpected statuses: %+v", statuses)
+	}
+
+	rr = doRequest(router, http.MethodPost, "/api/symbols/analysis/statuses", map[string]any{
+		"symbols": []string{"AAPL"},
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("missing currency: expected 400, got %d", rr.Code)
+	}
+}
+
+func TestDeleteAnalysisEndpoints(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	for _, path := range []string{"/api/symbol-analysis", "/api/holdings-analysis"} {
+		if rr := doRequest(router, http.MethodDelete, path+"/abc", nil); rr.Code != http.StatusBadRequest {
+			t.Fatalf("DELETE %s/abc: expected 400, got %d", path, rr.Code)
+		}
+		if rr := doRequest(router, http.MethodDelete, path+"/999", nil); rr.Code != http.StatusNotFound {
+			t.Fatalf("DELETE %s/999: expected 404, got %d, body: %s", path, rr.Code, rr.Body.String())
+		}
+	}
+}
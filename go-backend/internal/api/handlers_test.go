@@ -77,6 +77,10 @@ func TestHealthEndpoint(t *testing.T) {
 	if result["status"] != "ok" {
 		t.Errorf("expected status 'ok', got %v", result["status"])
 	}
+	checks, _ := result["checks"].([]any)
+	if len(checks) != 2 {
+		t.Errorf("expected database and data_dir checks, got %v", result["checks"])
+	}
 }
 
 func TestAccountsEndpoints(t *testing.T) {
@@ -286,7 +290,7 @@ func TestTransactionsEndpoints_ValidationErrors(t *testing.T) {
 				"currency":         "USD",
 				"account_id":       "test-account",
 			},
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusUnprocessableEntity,
 		},
 		{
 			name: "invalid transaction type",
@@ -298,7 +302,7 @@ func TestTransactionsEndpoints_ValidationErrors(t *testing.T) {
 				"currency":         "USD",
 				"account_id":       "test-account",
 			},
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusUnprocessableEntity,
 		},
 		{
 			name: "negative quantity",
@@ -310,7 +314,7 @@ func TestTransactionsEndpoints_ValidationErrors(t *testing.T) {
 				"currency":         "USD",
 				"account_id":       "test-account",
 			},
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusUnprocessableEntity,
 		},
 	}
 
@@ -324,6 +328,79 @@ func TestTransactionsEndpoints_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestTransactionValidationFieldErrors(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	rr := doRequest(router, "POST", "/api/transactions", map[string]interface{}{
+		"quantity": 100,
+		"price":    150,
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Errors map[string]string `json:"errors"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := map[string]string{
+		"transaction_type": "transaction_type required",
+		"account_id":       "account_id required",
+		"symbol":           "symbol required",
+	}
+	if len(resp.Errors) != len(want) {
+		t.Fatalf("expected %d field errors, got %v", len(want), resp.Errors)
+	}
+	for field, msg := range want {
+		if resp.Errors[field] != msg {
+			t.Errorf("errors[%q] = %q, want %q", field, resp.Errors[field], msg)
+		}
+	}
+}
+
+func TestImportTransactionsEndpoint(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/transactions/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("symbol,transaction_type,quantity,price,currency,account_id\nAAPL,BUY,10,150,USD,acc-csv\nMSFT,BUY,5,300,USD,acc-csv\n")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	result := parseJSON(rr)
+	if result["imported"] != float64(2) || result["batches"] != float64(1) {
+		t.Fatalf("unexpected import result: %v", result)
+	}
+
+	rr = post("symbol,transaction_type,quantity,price,currency,account_id\nAAPL,SELL,100,150,USD,acc-csv\nGOOG,BUY,1,100,USD,acc-csv\n")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 with per-row results, got %d: %s", rr.Code, rr.Body.String())
+	}
+	result = parseJSON(rr)
+	rows, _ := result["rows"].([]any)
+	if result["imported"] != float64(1) || result["failed"] != float64(1) || len(rows) != 2 {
+		t.Fatalf("unexpected import result: %v", result)
+	}
+	if failed, _ := rows[0].(map[string]any); failed["line"] != float64(2) || !strings.Contains(failed["error"].(string), "insufficient shares") {
+		t.Fatalf("expected line 2 to be rejected, got %v", rows[0])
+	}
+
+	rr = post("symbol,quantity\nAAPL,1\n")
+	if rr.Code != http.StatusUnprocessableEntity || !strings.Contains(rr.Body.String(), "missing column") {
+		t.Fatalf("expected 422 for missing columns, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
 func TestHoldingsEndpoints(t *testing.T) {
 	router, cleanup := setupTestRouter(t)
 	defer cleanup()
@@ -487,6 +564,45 @@ func TestSymbolsEndpoints(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Errorf("POST /api/symbols/auto-update: expected 200, got %d", rr.Code)
 	}
+
+	// Mark symbol inactive
+	rr = doRequest(router, "POST", "/api/symbols/AAPL/inactive", map[string]interface{}{
+		"currency": "USD",
+		"inactive": true,
+	})
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST /api/symbols/inactive: expected 200, got %d", rr.Code)
+	}
+	rr = doRequest(router, "POST", "/api/symbols/NOPE/inactive", map[string]interface{}{
+		"currency": "USD",
+		"inactive": true,
+	})
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("POST /api/symbols/inactive for unknown symbol: expected 404, got %d", rr.Code)
+	}
+	rr = doRequest(router, "POST", "/api/symbols/AAPL/inactive", map[string]interface{}{
+		"currency": "EUR",
+		"inactive": true,
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST /api/symbols/inactive with invalid currency: expected 422, got %d", rr.Code)
+	}
+
+	// Override symbol type
+	rr = doRequest(router, "POST", "/api/symbols/AAPL/type-override", map[string]interface{}{
+		"currency":    "USD",
+		"symbol_type": "us_stock",
+	})
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST /api/symbols/type-override: expected 200, got %d", rr.Code)
+	}
+	rr = doRequest(router, "POST", "/api/symbols/AAPL/type-override", map[string]interface{}{
+		"currency":    "USD",
+		"symbol_type": "crypto",
+	})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST /api/symbols/type-override invalid type: expected 422, got %d", rr.Code)
+	}
 }
 
 func TestPricesEndpoints(t *testing.T) {
@@ -518,6 +634,18 @@ func TestPricesEndpoints(t *testing.T) {
 		t.Errorf("POST /api/prices/manual: expected 200, got %d", rr.Code)
 	}
 
+	rr = doRequest(router, "POST", "/api/prices/manual-bulk", []map[string]interface{}{
+		{"symbol": "AAPL", "currency": "USD", "price": 161},
+		{"symbol": "MSFT", "currency": "EUR", "price": 300},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /api/prices/manual-bulk: expected 200, got %d", rr.Code)
+	}
+	bulk := parseJSON(rr)
+	if errs, _ := bulk["errors"].([]interface{}); bulk["updated"] != float64(1) || len(errs) != 1 {
+		t.Errorf("POST /api/prices/manual-bulk: expected 1 updated and 1 error, got %+v", bulk)
+	}
+
 	// Note: We don't test /api/prices/update or /api/prices/update-all
 	// as they depend on external API calls
 }
@@ -531,6 +659,23 @@ func TestOperationLogsEndpoint(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Errorf("GET /api/operation-logs: expected 200, got %d", rr.Code)
 	}
+
+	rr = doRequest(router, "GET", "/api/operation-logs?paged=1&operation_type=PRICE_UPDATE&limit=10", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/operation-logs paged: expected 200, got %d", rr.Code)
+	}
+	payload := parseJSON(rr)
+	if _, ok := payload["items"].([]any); !ok {
+		t.Fatalf("expected items array, got %v", payload["items"])
+	}
+	if payload["total"] != float64(0) || payload["limit"] != float64(10) {
+		t.Fatalf("unexpected paging metadata: %v", payload)
+	}
+
+	rr = doRequest(router, "GET", "/api/operation-logs?start_date=bad", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("GET /api/operation-logs invalid date: expected 400, got %d", rr.Code)
+	}
 }
 
 func TestPortfolioHistoryEndpoint(t *testing.T) {
@@ -560,8 +705,7 @@ func TestPortfolioHistoryEndpoint(t *testing.T) {
 }
 
 func TestCORS(t *testing.T) {
-	router, cleanup := setupTestRouter(t)
-	defer cleanup()
+	router := NewRouterWithOptions(nil, RouterOptions{CORS: CORSConfig{AllowedOrigins: []string{"http://localhost:*"}}})
 
 	// Test preflight request
 	req := httptest.NewRequest("OPTIONS", "/api/health", nil)
@@ -570,10 +714,13 @@ func TestCORS(t *testing.T) {
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	// Should allow localhost origin
+	// Should allow configured localhost origin
 	if rr.Code != http.StatusOK && rr.Code != http.StatusNoContent {
 		t.Errorf("OPTIONS /api/health: expected 200 or 204, got %d", rr.Code)
 	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" {
+		t.Errorf("expected allowed origin header, got %q", got)
+	}
 }
 
 func TestAIHoldingsAnalysisStreamEndpoint_ReturnsSSEErrorEvent(t *testing.T) {
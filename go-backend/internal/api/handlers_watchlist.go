@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"investlog/pkg/investlog"
+)
+
+func (h *handler) getWatchlist(w http.ResponseWriter, r *http.Request) {
+	items, err := h.core.GetWatchlist()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *handler) addToWatchlist(w http.ResponseWriter, r *http.Request) {
+	var payload watchlistPayload
+	if err := h.decodeJSON(w, r, &payload); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	item, err := h.core.AddToWatchlist(investlog.WatchlistItem{
+		Symbol:    payload.Symbol,
+		Currency:  payload.Currency,
+		AssetType: payload.AssetType,
+		Notes:     payload.Notes,
+	})
+	if err != nil {
+		writeRequestError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (h *handler) removeFromWatchlist(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	deleted, err := h.core.RemoveFromWatchlist(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "watchlist item not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "deleted"})
+}
@@ -14,10 +14,19 @@ import (
 type loggingResponseWriter struct {
 	middleware.WrapResponseWriter
 	errorMessage string
+	requestID    string
 }
 
 func newLoggingResponseWriter(w http.ResponseWriter, r *http.Request) *loggingResponseWriter {
-	return &loggingResponseWriter{WrapResponseWriter: middleware.NewWrapResponseWriter(w, r.ProtoMajor)}
+	return &loggingResponseWriter{
+		WrapResponseWriter: middleware.NewWrapResponseWriter(w, r.ProtoMajor),
+		requestID:          middleware.GetReqID(r.Context()),
+	}
+}
+
+// RequestID lets error writers echo the request ID in response bodies.
+func (w *loggingResponseWriter) RequestID() string {
+	return w.requestID
 }
 
 func (w *loggingResponseWriter) SetErrorMessage(message string) {
@@ -98,7 +98,7 @@ func TestNewRouterRecoversPanicWithStructuredLog(t *testing.T) {
 	}
 
 	body := rr.Body.String()
-	if !strings.Contains(body, `{"error":"internal server error"}`) {
+	if !strings.Contains(body, `"error":"internal server error"`) {
 		t.Fatalf("expected structured error response, got %q", body)
 	}
 
@@ -197,3 +197,52 @@ func TestNewRouterRequestLogContainsDuration(t *testing.T) {
 		t.Fatalf("expected duration field in request logs, got %q", logs)
 	}
 }
+
+func TestRequestIDEchoedInHeaderBodyAndLogs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	oldDefault := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() {
+		slog.SetDefault(oldDefault)
+	})
+
+	// The id is rejected before the core is touched, so no database is needed.
+	router := NewRouter(nil)
+	req := httptest.NewRequest(http.MethodDelete, "/api/transactions/invalid", nil)
+	req.Header.Set("X-Request-ID", "trace-abc-123")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("X-Request-ID"); got != "trace-abc-123" {
+		t.Fatalf("expected request ID echoed in header, got %q", got)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"request_id":"trace-abc-123"`) {
+		t.Fatalf("expected request ID in error body, got %q", body)
+	}
+	if logs := buf.String(); !strings.Contains(logs, "request_id=trace-abc-123") {
+		t.Fatalf("expected request ID in logs, got %q", logs)
+	}
+
+	// Missing or unusable IDs are replaced with a generated one.
+	req = httptest.NewRequest(http.MethodGet, "/api/livez", nil)
+	req.Header.Set("X-Request-ID", "has space")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if got := rr.Header().Get("X-Request-ID"); got == "" || got == "has space" {
+		t.Fatalf("expected a generated request ID, got %q", got)
+	}
+}
+
+func TestRequestIDCustomHeader(t *testing.T) {
+	router := NewRouterWithOptions(nil, RouterOptions{RequestIDHeader: "X-Correlation-ID"})
+	req := httptest.NewRequest(http.MethodGet, "/api/livez", nil)
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if got := rr.Header().Get("X-Correlation-ID"); got != "corr-1" {
+		t.Fatalf("expected custom header echoed, got %q", got)
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"investlog/pkg/investlog"
+)
+
+// openAPIDocument is the subset of OpenAPI 3.0 the API description uses.
+type openAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// openAPIPathItem maps a lower-case HTTP method to its operation.
+type openAPIPathItem map[string]*openAPIOperation
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+// openAPIRoute documents one registered route. Request and Response are
+// zero values of the payload types; their schemas are derived from the Go
+// types so field changes show up in the document without extra edits.
+type openAPIRoute struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Query    []string
+	Request  any
+	Response any
+	Stream   bool
+}
+
+// errorMessage is the body written by writeError.
+type errorMessage struct {
+	Error     string            `json:"error"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+var (
+	openAPIPathParam = regexp.MustCompile(`\{(\w+)\}`)
+	amountType       = reflect.TypeOf(investlog.Amount{})
+	timeType         = reflect.TypeOf(time.Time{})
+	rawMessageType   = reflect.TypeOf(json.RawMessage{})
+)
+
+// buildOpenAPIDocument assembles the API description from openAPIRoutes.
+func buildOpenAPIDocument() *openAPIDocument {
+	schemas := map[string]*openAPISchema{}
+	doc := &openAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       openAPIInfo{Title: "Invest Log API", Version: "1.0.0"},
+		Paths:      map[string]openAPIPathItem{},
+		Components: openAPIComponents{Schemas: schemas},
+	}
+	errorSchema := &openAPISchema{OneOf: []*openAPISchema{
+		schemaForType(reflect.TypeOf(errorMessage{}), schemas),
+		schemaForType(reflect.TypeOf(ErrorResponse{}), schemas),
+	}}
+
+	for _, route := range openAPIRoutes {
+		op := &openAPIOperation{
+			Summary:   route.Summary,
+			Tags:      []string{route.Tag},
+			Responses: map[string]openAPIResponse{},
+		}
+		for _, match := range openAPIPathParam.FindAllStringSubmatch(route.Path, -1) {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: match[1], In: "path", Required: true, Schema: &openAPISchema{Type: "string"},
+			})
+		}
+		for _, name := range route.Query {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: name, In: "query", Schema: &openAPISchema{Type: "string"},
+			})
+		}
+		if route.Request != nil {
+			op.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content: map[string]openAPIMediaType{
+					"application/json": {Schema: schemaForType(reflect.TypeOf(route.Request), schemas)},
+				},
+			}
+		}
+		success := openAPIResponse{Description: "OK"}
+		switch {
+		case route.Stream:
+			success.Description = "Server-sent events: progress, delta, result, error and done"
+			success.Content = map[string]openAPIMediaType{"text/event-stream": {Schema: &openAPISchema{Type: "string"}}}
+		case route.Response != nil:
+			success.Content = map[string]openAPIMediaType{
+				"application/json": {Schema: schemaForType(reflect.TypeOf(route.Response), schemas)},
+			}
+		default:
+			success.Content = map[string]openAPIMediaType{"application/json": {Schema: &openAPISchema{Type: "object"}}}
+		}
+		op.Responses["200"] = success
+		op.Responses["default"] = openAPIResponse{
+			Description: "Error",
+			Content:     map[string]openAPIMediaType{"application/json": {Schema: errorSchema}},
+		}
+
+		item := doc.Paths[route.Path]
+		if item == nil {
+			item = openAPIPathItem{}
+			doc.Paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
+
+// schemaForType describes t, registering named structs under
+// components/schemas and referencing them by name.
+func schemaForType(t reflect.Type, schemas map[string]*openAPISchema) *openAPISchema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	schema := inlineSchemaForType(t, schemas)
+	if nullable && schema.Ref == "" {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+func inlineSchemaForType(t reflect.Type, schemas map[string]*openAPISchema) *openAPISchema {
+	switch t {
+	case amountType:
+		return &openAPISchema{Type: "number"}
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &openAPISchema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: schemaForType(t.Elem(), schemas)}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[name]; !ok {
+			// Register before walking fields so recursive types terminate.
+			schemas[name] = &openAPISchema{}
+			*schemas[name] = *structSchema(t, schemas)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &openAPISchema{}
+	}
+}
+
+// structSchema lists the JSON properties of t, flattening embedded structs.
+func structSchema(t reflect.Type, schemas map[string]*openAPISchema) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, value := range structSchema(embedded, schemas).Properties {
+					schema.Properties[key] = value
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaForType(field.Type, schemas)
+	}
+	return schema
+}
+
+func (h *handler) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildOpenAPIDocument())
+}
@@ -0,0 +1,120 @@
+package api
+
+import "investlog/pkg/investlog"
+
+// openAPIRoutes lists every route registered by NewRouterWithOptions. Keep it
+// in step with the router; TestOpenAPIDocumentsEveryRoute fails otherwise.
+var openAPIRoutes = []openAPIRoute{
+	{Method: "GET", Path: "/api/health", Tag: "system", Summary: "Report database and price source health", Response: investlog.HealthReport{}},
+	{Method: "GET", Path: "/api/livez", Tag: "system", Summary: "Liveness probe"},
+	{Method: "GET", Path: "/api/openapi.json", Tag: "system", Summary: "This OpenAPI document"},
+
+	{Method: "GET", Path: "/api/holdings", Tag: "holdings", Summary: "List holdings by symbol, account and currency", Query: []string{"account_id"}, Response: []investlog.Holding{}},
+	{Method: "GET", Path: "/api/holdings-by-currency", Tag: "holdings", Summary: "Allocation per currency; includeCash=false computes percentages over non-cash assets", Query: []string{"includeCash"}, Response: investlog.HoldingsByCurrencyResult{}},
+	{Method: "GET", Path: "/api/holdings-by-symbol", Tag: "holdings", Summary: "Holdings with market value and P&L per currency; base adds a converted rollup; includeCash=false computes percentages over non-cash assets", Query: []string{"base", "includeCash"}, Response: investlog.HoldingsBySymbolResult{}},
+	{Method: "GET", Path: "/api/holdings-by-currency-account", Tag: "holdings", Summary: "Holdings per currency and account", Response: investlog.HoldingsByCurrencyAccountResult{}},
+	{Method: "GET", Path: "/api/holdings/by-exchange", Tag: "holdings", Summary: "Holdings grouped by exchange", Query: []string{"currency"}, Response: investlog.HoldingsByExchangeResult{}},
+	{Method: "GET", Path: "/api/holdings/top-movers", Tag: "holdings", Summary: "Biggest daily gainers and losers", Query: []string{"currency", "n"}, Response: investlog.TopMovers{}},
+	{Method: "POST", Path: "/api/holdings/modify", Tag: "holdings", Summary: "Set a holding to target shares and average cost", Request: modifyHoldingPayload{}},
+	{Method: "GET", Path: "/api/networth", Tag: "holdings", Summary: "Net worth converted to base, or the default base currency", Query: []string{"base"}, Response: investlog.NetWorth{}},
+	{Method: "GET", Path: "/api/performance/annual", Tag: "holdings", Summary: "Realized P&L and dividends per calendar year", Query: []string{"currency"}, Response: investlog.AnnualPerformance{}},
+	{Method: "POST", Path: "/api/performance/snapshots", Tag: "holdings", Summary: "Record today's market value of each currency for time-weighted return", Response: []investlog.PortfolioSnapshot{}},
+	{Method: "GET", Path: "/api/performance/twr", Tag: "holdings", Summary: "Time-weighted return linked across portfolio snapshots", Query: []string{"currency"}, Response: investlog.TimeWeightedReturn{}},
+	{Method: "GET", Path: "/api/report", Tag: "holdings", Summary: "Export the analysis report", Query: []string{"currency", "format"}},
+	{Method: "POST", Path: "/api/simulate", Tag: "holdings", Summary: "Simulate buying a position", Request: simulatePositionPayload{}, Response: investlog.PositionSimulation{}},
+
+	{Method: "GET", Path: "/api/transactions", Tag: "transactions", Summary: "List transactions; paged=1 wraps them with a total", Query: []string{"symbol", "account_id", "transaction_type", "currency", "year", "start_date", "end_date", "limit", "offset", "paged"}, Response: []investlog.Transaction{}},
+	{Method: "POST", Path: "/api/transactions", Tag: "transactions", Summary: "Add a transaction; an identical one within 3 days returns 409 unless force is set", Request: addTransactionPayload{}},
+	{Method: "POST", Path: "/api/transactions/import", Tag: "transactions", Summary: "Import transactions from a CSV request body, rejected above the row limit", Response: investlog.TransactionImportResult{}},
+	{Method: "GET", Path: "/api/transactions/summary", Tag: "transactions", Summary: "Transaction counts and totals by type and month", Query: []string{"symbol", "account_id", "transaction_type", "currency", "year", "start_date", "end_date"}, Response: investlog.TransactionSummary{}},
+	{Method: "GET", Path: "/api/transactions/deleted", Tag: "transactions", Summary: "List deleted transactions", Query: []string{"limit"}, Response: []investlog.DeletedTransaction{}},
+	{Method: "DELETE", Path: "/api/transactions/{id}", Tag: "transactions", Summary: "Delete a transaction and its linked records"},
+	{Method: "POST", Path: "/api/transactions/{id}/restore", Tag: "transactions", Summary: "Restore a deleted transaction"},
+	{Method: "GET", Path: "/api/tags", Tag: "transactions", Summary: "List transaction tags with counts", Response: []investlog.TagCount{}},
+	{Method: "POST", Path: "/api/transfers", Tag: "transactions", Summary: "Transfer a position between accounts", Request: transferPayload{}, Response: investlog.TransferResult{}},
+	{Method: "GET", Path: "/api/portfolio-history", Tag: "holdings", Summary: "Portfolio value history", Query: []string{"limit"}, Response: []investlog.PortfolioPoint{}},
+
+	{Method: "POST", Path: "/api/prices/update", Tag: "prices", Summary: "Fetch the latest price of a symbol", Request: pricePayload{}},
+	{Method: "POST", Path: "/api/prices/manual", Tag: "prices", Summary: "Set a price manually", Request: manualPricePayload{}},
+	{Method: "POST", Path: "/api/prices/manual-bulk", Tag: "prices", Summary: "Set many prices manually; invalid entries are reported in errors", Request: []manualPricePayload{}},
+	{Method: "POST", Path: "/api/prices/update-all", Tag: "prices", Summary: "Fetch latest prices for every holding in a currency", Request: updateAllPricesPayload{}},
+	{Method: "GET", Path: "/api/price/diagnostics", Tag: "prices", Summary: "Try every price source for a symbol", Query: []string{"symbol", "currency", "asset_type"}, Response: investlog.PriceDiagnostics{}},
+	{Method: "GET", Path: "/api/price/historical", Tag: "prices", Summary: "Closing price on a past date", Query: []string{"symbol", "currency", "asset_type", "date"}, Response: investlog.HistoricalPrice{}},
+	{Method: "GET", Path: "/api/price-circuit-settings", Tag: "prices", Summary: "Price source circuit breaker settings", Response: investlog.PriceCircuitSettings{}},
+	{Method: "PUT", Path: "/api/price-circuit-settings", Tag: "prices", Summary: "Update price source circuit breaker settings", Request: priceCircuitSettingsPayload{}, Response: investlog.PriceCircuitSettings{}},
+
+	{Method: "GET", Path: "/api/ai-settings", Tag: "ai", Summary: "AI provider settings", Response: investlog.AISettings{}},
+	{Method: "PUT", Path: "/api/ai-settings", Tag: "ai", Summary: "Save AI provider settings", Request: aiSettingsPayload{}, Response: investlog.AISettings{}},
+	{Method: "GET", Path: "/api/ai-analysis-methods", Tag: "ai", Summary: "List AI analysis methods", Response: []investlog.AIAnalysisMethod{}},
+	{Method: "POST", Path: "/api/ai-analysis-methods", Tag: "ai", Summary: "Create an AI analysis method", Request: aiAnalysisMethodPayload{}, Response: investlog.AIAnalysisMethod{}},
+	{Method: "PUT", Path: "/api/ai-analysis-methods/{id}", Tag: "ai", Summary: "Update an AI analysis method", Request: aiAnalysisMethodPayload{}, Response: investlog.AIAnalysisMethod{}},
+	{Method: "DELETE", Path: "/api/ai-analysis-methods/{id}", Tag: "ai", Summary: "Delete an AI analysis method"},
+	{Method: "GET", Path: "/api/ai-analysis-profiles", Tag: "ai", Summary: "List saved AI analysis profiles", Response: []investlog.AIAnalysisProfile{}},
+	{Method: "PUT", Path: "/api/ai-analysis-profiles", Tag: "ai", Summary: "Save an AI analysis profile", Request: aiAnalysisProfilePayload{}},
+	{Method: "DELETE", Path: "/api/ai-analysis-profiles/{name}", Tag: "ai", Summary: "Delete an AI analysis profile"},
+	{Method: "POST", Path: "/api/ai-analysis/stream", Tag: "ai", Summary: "Run an AI analysis method", Request: aiAnalysisStreamPayload{}, Stream: true},
+	{Method: "GET", Path: "/api/ai-analysis/history", Tag: "ai", Summary: "List AI analysis runs", Query: []string{"method_id", "limit"}, Response: []investlog.AIAnalysisRun{}},
+	{Method: "GET", Path: "/api/ai-analysis/runs/{id}", Tag: "ai", Summary: "Get one AI analysis run", Response: investlog.AIAnalysisRun{}},
+	{Method: "GET", Path: "/api/ai/scopes", Tag: "ai", Summary: "Currencies and accounts that can be analyzed", Response: []investlog.AnalyzableScope{}},
+	{Method: "GET", Path: "/api/ai/schemas", Tag: "ai", Summary: "JSON schemas the AI responses must follow"},
+	{Method: "GET", Path: "/api/ai/options", Tag: "ai", Summary: "Accepted AI request enum values and defaults", Response: investlog.AIOptions{}},
+	{Method: "POST", Path: "/api/ai/holdings-analysis", Tag: "ai", Summary: "Analyze holdings with AI", Request: aiHoldingsAnalysisPayload{}, Response: investlog.HoldingsAnalysisResult{}},
+	{Method: "POST", Path: "/api/ai/holdings-analysis/stream", Tag: "ai", Summary: "Analyze holdings with AI, streamed", Request: aiHoldingsAnalysisPayload{}, Stream: true},
+	{Method: "GET", Path: "/api/ai/holdings-analysis", Tag: "ai", Summary: "Latest holdings analysis", Query: []string{"currency"}, Response: investlog.HoldingsAnalysisResult{}},
+	{Method: "GET", Path: "/api/ai/holdings-analysis/history", Tag: "ai", Summary: "Holdings analysis history; paged=1 wraps it with a total", Query: []string{"currency", "limit", "offset", "paged"}, Response: []investlog.HoldingsAnalysisResult{}},
+	{Method: "DELETE", Path: "/api/holdings-analysis/{id}", Tag: "ai", Summary: "Delete a saved holdings analysis"},
+	{Method: "POST", Path: "/api/ai/allocation-advice", Tag: "ai", Summary: "AI allocation advice", Request: aiAllocationAdvicePayload{}, Response: investlog.AllocationAdviceResult{}},
+	{Method: "POST", Path: "/api/ai/allocation-advice/stream", Tag: "ai", Summary: "AI allocation advice, streamed", Request: aiAllocationAdvicePayload{}, Stream: true},
+	{Method: "POST", Path: "/api/ai/symbol-analysis", Tag: "ai", Summary: "Analyze one symbol with AI", Request: aiSymbolAnalysisPayload{}, Response: investlog.SymbolAnalysisResult{}},
+	{Method: "POST", Path: "/api/ai/symbol-analysis/stream", Tag: "ai", Summary: "Analyze one symbol with AI, streamed with per-dimension progress", Request: aiSymbolAnalysisPayload{}, Stream: true},
+	{Method: "GET", Path: "/api/ai/symbol-analysis", Tag: "ai", Summary: "Latest analysis of a symbol", Query: []string{"symbol", "currency"}, Response: investlog.SymbolAnalysisResult{}},
+	{Method: "GET", Path: "/api/ai/symbol-analysis/history", Tag: "ai", Summary: "Analysis history of a symbol; paged=1 wraps it with a total", Query: []string{"symbol", "currency", "limit", "offset", "paged"}, Response: []investlog.SymbolAnalysisResult{}},
+	{Method: "GET", Path: "/api/ai/symbol-analysis/position", Tag: "ai", Summary: "Position weight used by symbol analysis", Query: []string{"symbol", "currency", "basis"}, Response: investlog.SymbolPositionWeight{}},
+	{Method: "GET", Path: "/api/symbol-analysis/status", Tag: "ai", Summary: "Status of the latest analysis run of a symbol", Query: []string{"symbol", "currency"}, Response: investlog.SymbolAnalysisStatus{}},
+	{Method: "DELETE", Path: "/api/symbol-analysis/{id}", Tag: "ai", Summary: "Delete a saved symbol analysis"},
+	{Method: "POST", Path: "/api/ai/symbol-analysis/{id}/resynthesize", Tag: "ai", Summary: "Rerun synthesis of a stored analysis", Request: aiSymbolResynthesizePayload{}, Response: investlog.SymbolAnalysisResult{}},
+	{Method: "POST", Path: "/api/symbol-analysis/{id}/retry", Tag: "ai", Summary: "Rerun a failed symbol analysis with its stored parameters", Request: aiSymbolRetryPayload{}, Response: investlog.SymbolAnalysisResult{}},
+
+	{Method: "GET", Path: "/api/accounts", Tag: "accounts", Summary: "List accounts", Response: []investlog.Account{}},
+	{Method: "POST", Path: "/api/accounts", Tag: "accounts", Summary: "Add an account with optional opening cash", Request: addAccountPayload{}},
+	{Method: "DELETE", Path: "/api/accounts/{id}", Tag: "accounts", Summary: "Delete an account"},
+	{Method: "PUT", Path: "/api/accounts/{id}/default-commission", Tag: "accounts", Summary: "Set an account's default commission", Request: accountDefaultCommissionPayload{}},
+
+	{Method: "GET", Path: "/api/asset-types", Tag: "settings", Summary: "List asset types", Response: []investlog.AssetType{}},
+	{Method: "POST", Path: "/api/asset-types", Tag: "settings", Summary: "Add an asset type", Request: assetTypePayload{}},
+	{Method: "DELETE", Path: "/api/asset-types/{code}", Tag: "settings", Summary: "Delete an asset type"},
+	{Method: "GET", Path: "/api/allocation-settings", Tag: "settings", Summary: "Allocation bands", Query: []string{"currency"}, Response: []investlog.AllocationSetting{}},
+	{Method: "PUT", Path: "/api/allocation-settings", Tag: "settings", Summary: "Set an allocation band", Request: allocationPayload{}},
+	{Method: "DELETE", Path: "/api/allocation-settings", Tag: "settings", Summary: "Delete an allocation band", Request: allocationPayload{}},
+	{Method: "GET", Path: "/api/reporting-settings", Tag: "settings", Summary: "Default base currency for reports", Response: investlog.ReportingSettings{}},
+	{Method: "PUT", Path: "/api/reporting-settings", Tag: "settings", Summary: "Set the default base currency for reports", Request: reportingSettingsPayload{}, Response: investlog.ReportingSettings{}},
+	{Method: "GET", Path: "/api/exchange-rates", Tag: "settings", Summary: "List exchange rates", Response: []investlog.ExchangeRateSetting{}},
+	{Method: "PUT", Path: "/api/exchange-rates", Tag: "settings", Summary: "Set an exchange rate manually", Request: exchangeRatePayload{}},
+	{Method: "POST", Path: "/api/exchange-rates/refresh", Tag: "settings", Summary: "Refresh exchange rates from the rate source"},
+	{Method: "GET", Path: "/api/convert", Tag: "settings", Summary: "Convert an amount between currencies", Query: []string{"amount", "from", "to"}},
+
+	{Method: "GET", Path: "/api/symbols", Tag: "symbols", Summary: "List symbols", Response: []investlog.Symbol{}},
+	{Method: "GET", Path: "/api/symbols/classify", Tag: "symbols", Summary: "Preview a symbol's detected type and price sources", Query: []string{"symbol", "currency", "asset_type"}, Response: investlog.SymbolClassification{}},
+	{Method: "POST", Path: "/api/symbols/analysis/statuses", Tag: "symbols", Summary: "Latest analysis status of several symbols", Request: symbolAnalysisStatusesPayload{}, Response: []investlog.SymbolAnalysisStatus{}},
+	{Method: "POST", Path: "/api/symbols/compare", Tag: "symbols", Summary: "Analyze several symbols and compare them side by side", Request: aiSymbolComparePayload{}, Response: investlog.SymbolComparison{}},
+	{Method: "PUT", Path: "/api/symbols/{symbol}", Tag: "symbols", Summary: "Update symbol metadata", Request: symbolUpdatePayload{}},
+	{Method: "POST", Path: "/api/symbols/{symbol}/asset-type", Tag: "symbols", Summary: "Change a symbol's asset type", Request: updateSymbolAssetTypePayload{}},
+	{Method: "POST", Path: "/api/symbols/{symbol}/auto-update", Tag: "symbols", Summary: "Toggle automatic price updates", Request: updateSymbolAutoUpdatePayload{}},
+	{Method: "POST", Path: "/api/symbols/{symbol}/inactive", Tag: "symbols", Summary: "Mark a symbol delisted or active again", Request: updateSymbolInactivePayload{}},
+	{Method: "POST", Path: "/api/symbols/{symbol}/type-override", Tag: "symbols", Summary: "Override detected symbol type", Request: symbolTypeOverridePayload{}},
+	{Method: "POST", Path: "/api/symbols/{symbol}/fetch-metadata", Tag: "symbols", Summary: "Fetch symbol name and metadata", Request: fetchSymbolMetadataPayload{}},
+	{Method: "POST", Path: "/api/symbols/{symbol}/refresh", Tag: "symbols", Summary: "Refresh price, metadata and external data, reporting each step", Request: refreshSymbolPayload{}, Response: investlog.SymbolRefreshResult{}},
+
+	{Method: "GET", Path: "/api/watchlist", Tag: "symbols", Summary: "List watched symbols with their latest prices", Response: []investlog.WatchlistItem{}},
+	{Method: "POST", Path: "/api/watchlist", Tag: "symbols", Summary: "Watch a symbol, or update its asset type and notes", Request: watchlistPayload{}, Response: investlog.WatchlistItem{}},
+	{Method: "DELETE", Path: "/api/watchlist/{id}", Tag: "symbols", Summary: "Stop watching a symbol"},
+	{Method: "GET", Path: "/api/alerts", Tag: "prices", Summary: "List price alerts", Response: []investlog.PriceAlert{}},
+	{Method: "POST", Path: "/api/alerts", Tag: "prices", Summary: "Set a target price alert, re-arming an existing one", Request: priceAlertPayload{}, Response: investlog.PriceAlert{}},
+	{Method: "GET", Path: "/api/alerts/triggered", Tag: "prices", Summary: "Price alerts that have fired, most recent first", Response: []investlog.PriceAlert{}},
+	{Method: "DELETE", Path: "/api/alerts/{id}", Tag: "prices", Summary: "Delete a price alert"},
+
+	{Method: "GET", Path: "/api/operation-logs", Tag: "system", Summary: "List operation logs", Query: []string{"symbol", "operation_type", "start_date", "end_date", "limit", "offset", "paged"}, Response: []investlog.OperationLog{}},
+	{Method: "GET", Path: "/api/storage", Tag: "system", Summary: "Storage location and available databases", Response: storageInfoResponse{}},
+	{Method: "POST", Path: "/api/storage/switch", Tag: "system", Summary: "Switch to another database file", Request: storageSwitchPayload{}},
+	{Method: "POST", Path: "/api/restore", Tag: "system", Summary: "Restore the database from an uploaded SQLite backup"},
+}
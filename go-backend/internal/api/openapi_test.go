@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	router := NewRouter(nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("expected OpenAPI 3 document, got %q", doc.OpenAPI)
+	}
+	for path, methods := range map[string][]string{
+		"/api/transactions":      {"get", "post"},
+		"/api/transactions/{id}": {"delete"},
+		"/api/holdings":          {"get"},
+	} {
+		for _, method := range methods {
+			if _, ok := doc.Paths[path][method]; !ok {
+				t.Errorf("missing %s %s", method, path)
+			}
+		}
+	}
+	for _, name := range []string{"Transaction", "Holding", "addTransactionPayload", "ErrorResponse"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("missing schema %s", name)
+		}
+	}
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	routes, ok := NewRouter(nil).(chi.Routes)
+	if !ok {
+		t.Fatal("router does not expose its routes")
+	}
+	registered := map[string]bool{}
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk routes: %v", err)
+	}
+
+	documented := map[string]bool{}
+	for _, route := range openAPIRoutes {
+		key := route.Method + " " + route.Path
+		if documented[key] {
+			t.Errorf("%s documented twice", key)
+		}
+		documented[key] = true
+	}
+
+	var missing, stale []string
+	for key := range registered {
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("routes missing from openAPIRoutes: %v", missing)
+	}
+	if len(stale) > 0 {
+		t.Errorf("openAPIRoutes entries without a route: %v", stale)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitIdleTTL is how long an untouched client bucket is kept.
+const rateLimitIdleTTL = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ipRateLimiter is a token-bucket limiter keyed by client IP.
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newIPRateLimiter(perMinute, burst int) *ipRateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &ipRateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token for key. When none is left it reports how long the
+// client should wait before retrying.
+func (l *ipRateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+func (l *ipRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitIdleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= rateLimitIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// aiRateLimitMiddleware rejects requests over the per-IP allowance with 429
+// and a Retry-After header. RemoteAddr already reflects X-Forwarded-For via
+// middleware.RealIP. A non-positive perMinute disables limiting.
+func aiRateLimitMiddleware(perMinute, burst int) func(http.Handler) http.Handler {
+	if perMinute <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	limiter := newIPRateLimiter(perMinute, burst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := limiter.allow(clientIP(r))
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeError(w, http.StatusTooManyRequests, "too many AI requests, please retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"investlog/pkg/investlog"
+)
+
+func TestAIRateLimitMiddlewareRejectsBurstOverflow(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := middleware.RealIP(aiRateLimitMiddleware(6, 2)(ok))
+
+	send := func(forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/ai/holdings-analysis", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := send("203.0.113.1"); rr.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected 204, got %d", i, rr.Code)
+		}
+	}
+	rr := send("203.0.113.1")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "10" {
+		t.Fatalf("expected Retry-After 10, got %q", got)
+	}
+	if rr := send("203.0.113.2"); rr.Code != http.StatusNoContent {
+		t.Fatalf("other client should pass, got %d", rr.Code)
+	}
+}
+
+func TestIPRateLimiterRefills(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newIPRateLimiter(60, 1)
+	limiter.now = func() time.Time { return now }
+
+	if ok, _ := limiter.allow("a"); !ok {
+		t.Fatalf("first request should pass")
+	}
+	ok, wait := limiter.allow("a")
+	if ok || wait != time.Second {
+		t.Fatalf("expected rejection with 1s wait, got ok=%v wait=%s", ok, wait)
+	}
+	now = now.Add(time.Second)
+	if ok, _ := limiter.allow("a"); !ok {
+		t.Fatalf("request after refill should pass")
+	}
+}
+
+func TestAIRateLimitMiddlewareDisabled(t *testing.T) {
+	handler := aiRateLimitMiddleware(-1, 1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for i := 0; i < 5; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/ai/holdings-analysis", nil))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected 204, got %d", i, rr.Code)
+		}
+	}
+}
+
+func TestRouterRateLimitsAIEndpointsOnly(t *testing.T) {
+	core, err := investlog.OpenWithOptions(investlog.Options{
+		DBPath:      filepath.Join(t.TempDir(), "test.db"),
+		AIRateLimit: 1,
+		AIRateBurst: 1,
+	})
+	if err != nil {
+		t.Fatalf("open core: %v", err)
+	}
+	defer core.Close()
+	router := NewRouter(core)
+
+	if rr := doRequest(router, http.MethodPost, "/api/ai/holdings-analysis", map[string]any{}); rr.Code == http.StatusTooManyRequests {
+		t.Fatalf("first AI request should not be limited")
+	}
+	rr := doRequest(router, http.MethodPost, "/api/ai/holdings-analysis", map[string]any{})
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+	for i := 0; i < 3; i++ {
+		if rr := doRequest(router, http.MethodGet, "/api/holdings", nil); rr.Code != http.StatusOK {
+			t.Fatalf("non-AI request %d: expected 200, got %d", i, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// DefaultRequestIDHeader carries the request ID unless
+// RouterOptions.RequestIDHeader names another header.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they stay log friendly.
+const maxRequestIDLength = 128
+
+// requestIDMiddleware adopts the caller's request ID from header, or
+// generates one, stores it where middleware.GetReqID finds it and echoes it
+// in the response header.
+func requestIDMiddleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(header, id)
+			ctx := context.WithValue(r.Context(), middleware.RequestIDKey, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validRequestID accepts short printable ASCII IDs; anything else is
+// replaced rather than copied into logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return strconv.FormatUint(middleware.NextRequestID(), 10)
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// responseRequestID returns the request ID recorded on w by the logging
+// middleware, or "" outside the router.
+func responseRequestID(w http.ResponseWriter) string {
+	if getter, ok := w.(interface{ RequestID() string }); ok {
+		return getter.RequestID()
+	}
+	return ""
+}
+
+// requestLogger returns the handler logger tagged with r's request ID.
+func (h *handler) requestLogger(r *http.Request) *slog.Logger {
+	return h.logger.With("request_id", middleware.GetReqID(r.Context()))
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"investlog/pkg/investlog"
@@ -15,10 +16,11 @@ type Response struct {
 
 // ErrorResponse represents an error API response with structured information.
 type ErrorResponse struct {
-	Code      int    `json:"code"`
-	Message   string `json:"message"`
-	ErrorCode string `json:"error_code,omitempty"`
-	RequestID string `json:"request_id,omitempty"`
+	Code      int               `json:"code"`
+	Message   string            `json:"message"`
+	ErrorCode string            `json:"error_code,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
 }
 
 // writeSuccess writes a successful response with data.
@@ -52,9 +54,15 @@ func writeErrorResponse(w http.ResponseWriter, httpStatus int, err error) {
 		httpStatus = mapErrorCodeToHTTPStatus(invErr.Code)
 		response.Code = httpStatus
 	}
+	var invalid *investlog.ValidationError
+	if errors.As(err, &invalid) {
+		response.ErrorCode = string(investlog.ErrCodeValidation)
+		response.Errors = invalid.Fields
+		httpStatus = http.StatusUnprocessableEntity
+		response.Code = httpStatus
+	}
 
-	// TODO: Add request ID from context when middleware is implemented
-	// requestID := r.Context().Value("request_id")
+	response.RequestID = responseRequestID(w)
 
 	writeJSON(w, httpStatus, response)
 }
@@ -74,6 +82,10 @@ func mapErrorCodeToHTTPStatus(code investlog.ErrorCode) int {
 		return http.StatusInternalServerError
 	case investlog.ErrCodeUnsupported:
 		return http.StatusNotImplemented
+	case investlog.ErrCodeSetupRequired:
+		return http.StatusPreconditionFailed
+	case investlog.ErrCodeBusy:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
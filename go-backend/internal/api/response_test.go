@@ -72,6 +72,21 @@ func TestWriteErrorResponse(t *testing.T) {
 			t.Fatalf("expected status 400, got %d", rr.Code)
 		}
 	})
+
+	t.Run("validation error", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		writeErrorResponse(rr, http.StatusBadRequest, investlog.NewValidationError("symbol", "symbol required"))
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status 422, got %d", rr.Code)
+		}
+		var resp ErrorResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Errors["symbol"] != "symbol required" {
+			t.Fatalf("expected symbol field error, got %v", resp.Errors)
+		}
+	})
 }
 
 func TestMapErrorCodeToHTTPStatus(t *testing.T) {
@@ -88,6 +103,7 @@ func TestMapErrorCodeToHTTPStatus(t *testing.T) {
 		{name: "database", code: investlog.ErrCodeDatabase, want: http.StatusInternalServerError},
 		{name: "internal", code: investlog.ErrCodeInternal, want: http.StatusInternalServerError},
 		{name: "unsupported", code: investlog.ErrCodeUnsupported, want: http.StatusNotImplemented},
+		{name: "setup required", code: investlog.ErrCodeSetupRequired, want: http.StatusPreconditionFailed},
 		{name: "default", code: investlog.ErrorCode("UNKNOWN"), want: http.StatusInternalServerError},
 	}
 
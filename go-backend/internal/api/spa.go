@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"html"
 	"net/http"
 	"os"
 	"path"
@@ -8,8 +10,21 @@ import (
 	"strings"
 )
 
+// basePathMetaName names the index.html meta tag that tells the frontend
+// which prefix to put in front of API calls.
+const basePathMetaName = "investlog-base-path"
+
 // WithSPA wraps API handler with SPA static serving.
 func WithSPA(apiHandler http.Handler, webDir string) http.Handler {
+	return WithSPABasePath(apiHandler, webDir, "")
+}
+
+// WithSPABasePath is WithSPA for an app served under basePath. API requests
+// are passed on unchanged, so apiHandler must be built with the same
+// RouterOptions.BasePath. The bare prefix redirects to its trailing-slash form
+// so the index's relative asset paths resolve under it.
+func WithSPABasePath(apiHandler http.Handler, webDir, basePath string) http.Handler {
+	basePath = NormalizeBasePath(basePath)
 	fileServer := http.FileServer(http.Dir(webDir))
 	indexPath := filepath.Join(webDir, "index.html")
 	nonCacheFileServer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -18,15 +33,32 @@ func WithSPA(apiHandler http.Handler, webDir string) http.Handler {
 	})
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if basePath != "" && r.URL.Path == basePath {
+			target := basePath + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		original := r
+		if basePath != "" {
+			stripped, ok := stripBasePath(r, basePath)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			r = stripped
+		}
 		if strings.HasPrefix(r.URL.Path, "/api/") {
-			apiHandler.ServeHTTP(w, r)
+			apiHandler.ServeHTTP(w, original)
 			return
 		}
 
 		cleanPath := path.Clean("/" + r.URL.Path)
 		cleanPath = strings.TrimPrefix(cleanPath, "/")
 		if cleanPath == "." || cleanPath == "" {
-			serveIndex(w, r, indexPath)
+			serveIndex(w, r, indexPath, basePath)
 			return
 		}
 
@@ -36,18 +68,49 @@ func WithSPA(apiHandler http.Handler, webDir string) http.Handler {
 			return
 		}
 
-		serveIndex(w, r, indexPath)
+		serveIndex(w, r, indexPath, basePath)
 	})
 }
 
-func serveIndex(w http.ResponseWriter, r *http.Request, indexPath string) {
-	if _, err := os.Stat(indexPath); err == nil {
-		setSPACacheControl(w)
+func serveIndex(w http.ResponseWriter, r *http.Request, indexPath, basePath string) {
+	info, err := os.Stat(indexPath)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("index.html not found"))
+		return
+	}
+	setSPACacheControl(w)
+	if basePath == "" {
 		http.ServeFile(w, r, indexPath)
 		return
 	}
-	w.WriteHeader(http.StatusNotFound)
-	_, _ = w.Write([]byte("index.html not found"))
+	content, err := os.ReadFile(indexPath)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("index.html not found"))
+		return
+	}
+	http.ServeContent(w, r, "index.html", info.ModTime(), bytes.NewReader(injectBasePathMeta(content, basePath)))
+}
+
+// injectBasePathMeta adds the base path meta tag right after <head>, or at
+// the start of documents without one.
+func injectBasePathMeta(content []byte, basePath string) []byte {
+	meta := []byte(`<meta name="` + basePathMetaName + `" content="` + html.EscapeString(basePath) + `">`)
+	lower := bytes.ToLower(content)
+	start := bytes.Index(lower, []byte("<head"))
+	if start < 0 {
+		return append(meta, content...)
+	}
+	end := bytes.IndexByte(content[start:], '>')
+	if end < 0 {
+		return append(meta, content...)
+	}
+	at := start + end + 1
+	out := make([]byte, 0, len(content)+len(meta))
+	out = append(out, content[:at]...)
+	out = append(out, meta...)
+	return append(out, content[at:]...)
 }
 
 func setSPACacheControl(w http.ResponseWriter) {
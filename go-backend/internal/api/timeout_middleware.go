@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"investlog/pkg/investlog"
+)
+
+const (
+	defaultRequestTimeout = 30 * time.Second
+	// aiRequestTimeout leaves headroom over the core's own AI deadline so the
+	// handler can still report the model timeout itself.
+	aiRequestTimeout = 16 * time.Minute
+	// compareRequestTimeout covers a comparison of the most symbols, whose
+	// analyses run one after another.
+	compareRequestTimeout = investlog.MaxCompareSymbols*(aiRequestTimeout-time.Minute) + time.Minute
+	// symbolRefreshRequestTimeout covers a symbol refresh: the price sources
+	// and metadata lookup, each on the 10s HTTP timeout with a transient
+	// retry, then up to 90s of external data. It stays under the server's
+	// 120s write timeout so the result can still be written.
+	symbolRefreshRequestTimeout = 115 * time.Second
+)
+
+// routeTimeout overrides the default deadline for paths under Prefix that
+// also end with Suffix, when set.
+type routeTimeout struct {
+	Prefix  string
+	Suffix  string
+	Timeout time.Duration
+}
+
+var defaultRouteTimeouts = []routeTimeout{
+	{Prefix: "/api/ai/", Timeout: aiRequestTimeout},
+	{Prefix: "/api/ai-analysis/", Timeout: aiRequestTimeout},
+	{Prefix: "/api/symbol-analysis/", Suffix: "/retry", Timeout: aiRequestTimeout},
+	{Prefix: "/api/symbols/compare", Timeout: compareRequestTimeout},
+	{Prefix: "/api/symbols/", Suffix: "/refresh", Timeout: symbolRefreshRequestTimeout},
+	{Prefix: "/api/prices/update-all", Timeout: 5 * time.Minute},
+	{Prefix: "/api/restore", Timeout: 5 * time.Minute},
+}
+
+// requestTimeoutMiddleware bounds each request's context with a deadline. When
+// the handler returns after the deadline without writing a response, it
+// replies 504 on its behalf.
+func requestTimeoutMiddleware(defaultTimeout time.Duration, overrides []routeTimeout) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeoutForPath(r.URL.Path, defaultTimeout, overrides))
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return
+			}
+			if statusWriter, ok := w.(interface{ Status() int }); ok && statusWriter.Status() != 0 {
+				return
+			}
+			writeError(w, http.StatusGatewayTimeout, "request timed out")
+		})
+	}
+}
+
+// timeoutForPath returns the most specific override for path, the one with
+// the longest prefix and suffix, or fallback.
+func timeoutForPath(path string, fallback time.Duration, overrides []routeTimeout) time.Duration {
+	timeout, matched := fallback, 0
+	for _, override := range overrides {
+		if !strings.HasPrefix(path, override.Prefix) || !strings.HasSuffix(path, override.Suffix) {
+			continue
+		}
+		if length := len(override.Prefix) + len(override.Suffix); length > matched {
+			timeout, matched = override.Timeout, length
+		}
+	}
+	return timeout
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeoutMiddlewareCutsOffSlowHandler(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			writeJSON(w, http.StatusOK, map[string]string{"status": "late"})
+		}
+	})
+	handler := requestTimeoutMiddleware(50*time.Millisecond, nil)(slow)
+
+	start := time.Now()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/holdings", nil))
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("handler was not cut off at the deadline, took %s", elapsed)
+	}
+}
+
+func TestRequestTimeoutMiddlewareKeepsWrittenResponse(t *testing.T) {
+	handler := requestTimeoutMiddleware(50*time.Millisecond, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/holdings", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestTimeoutForPathUsesLongestPrefix(t *testing.T) {
+	overrides := []routeTimeout{
+		{Prefix: "/api/ai/", Timeout: time.Minute},
+		{Prefix: "/api/ai/symbol-analysis/stream", Timeout: time.Hour},
+	}
+	cases := map[string]time.Duration{
+		"/api/holdings":                  time.Second,
+		"/api/ai/holdings-analysis":      time.Minute,
+		"/api/ai/symbol-analysis/stream": time.Hour,
+	}
+	for path, want := range cases {
+		if got := timeoutForPath(path, time.Second, overrides); got != want {
+			t.Fatalf("timeoutForPath(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestTimeoutForPathMatchesSymbolRefresh(t *testing.T) {
+	cases := map[string]time.Duration{
+		"/api/symbols/AAPL/refresh":  symbolRefreshRequestTimeout,
+		"/api/symbols/AAPL/metadata": defaultRequestTimeout,
+		"/api/symbols/compare":       compareRequestTimeout,
+	}
+	for path, want := range cases {
+		if got := timeoutForPath(path, defaultRequestTimeout, defaultRouteTimeouts); got != want {
+			t.Fatalf("timeoutForPath(%q) = %s, want %s", path, got, want)
+		}
+	}
+	if symbolRefreshRequestTimeout >= 120*time.Second {
+		t.Fatalf("symbol refresh deadline %s must stay under the server write timeout", symbolRefreshRequestTimeout)
+	}
+}
+
+func TestRequestTimeoutMiddlewareAppliesOverride(t *testing.T) {
+	var deadline time.Time
+	handler := requestTimeoutMiddleware(time.Second, defaultRouteTimeouts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ = r.Context().Deadline()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for path, want := range map[string]time.Duration{
+		"/api/ai/symbol-analysis/stream": aiRequestTimeout,
+		"/api/symbol-analysis/42/retry":  aiRequestTimeout,
+		"/api/symbols/compare":           compareRequestTimeout,
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+		if remaining := time.Until(deadline); remaining < want-time.Minute || remaining > want {
+			t.Fatalf("expected route deadline near %s for %s, got %s", want, path, remaining)
+		}
+	}
+	if compareRequestTimeout < 4*15*time.Minute {
+		t.Fatalf("expected compare deadline to cover four sequential analyses, got %s", compareRequestTimeout)
+	}
+}
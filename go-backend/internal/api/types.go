@@ -11,13 +11,14 @@ type addTransactionPayload struct {
 	Price           investlog.Amount  `json:"price"`
 	AccountID       string            `json:"account_id"`
 	AssetType       string            `json:"asset_type"`
-	Commission      investlog.Amount  `json:"commission"`
+	Commission      *investlog.Amount `json:"commission"`
 	Currency        string            `json:"currency"`
 	AccountName     *string           `json:"account_name"`
 	Notes           *string           `json:"notes"`
 	Tags            *string           `json:"tags"`
 	TotalAmount     *investlog.Amount `json:"total_amount"`
 	LinkCash        bool              `json:"link_cash"`
+	Force           bool              `json:"force"`
 }
 
 type modifyHoldingPayload struct {
@@ -51,27 +52,37 @@ type updateAllPricesPayload struct {
 }
 
 type aiHoldingsAnalysisPayload struct {
-	BaseURL         string `json:"base_url"`
-	APIKey          string `json:"api_key"`
-	Model           string `json:"model"`
-	Currency        string `json:"currency"`
-	RiskProfile     string `json:"risk_profile"`
-	Horizon         string `json:"horizon"`
-	AdviceStyle     string `json:"advice_style"`
-	AllowNewSymbols *bool  `json:"allow_new_symbols"`
-	StrategyPrompt  string `json:"strategy_prompt"`
-	AnalysisType    string `json:"analysis_type"`
+	BaseURL            string   `json:"base_url"`
+	APIKey             string   `json:"api_key"`
+	Model              string   `json:"model"`
+	Currency           string   `json:"currency"`
+	RiskProfile        string   `json:"risk_profile"`
+	Horizon            string   `json:"horizon"`
+	AdviceStyle        string   `json:"advice_style"`
+	AllowNewSymbols    *bool    `json:"allow_new_symbols"`
+	StrategyPrompt     string   `json:"strategy_prompt"`
+	AnalysisType       string   `json:"analysis_type"`
+	Profile            string   `json:"profile"`
+	FallbackModels     []string `json:"fallback_models"`
+	PromptTopSymbols   int      `json:"prompt_top_symbols"`
+	MinWeightPct       float64  `json:"min_weight_pct"`
+	MaxRecommendations int      `json:"max_recommendations"`
+	MaxKeyFindings     int      `json:"max_key_findings"`
+	StructuredOutput   bool     `json:"structured_output"`
+	Temperature        *float64 `json:"temperature"`
+	ThinkingBudget     *int     `json:"thinking_budget"`
 }
 
 type aiSettingsPayload struct {
-	BaseURL         string `json:"base_url"`
-	Model           string `json:"model"`
-	RiskProfile     string `json:"risk_profile"`
-	Horizon         string `json:"horizon"`
-	AdviceStyle     string `json:"advice_style"`
-	AllowNewSymbols *bool  `json:"allow_new_symbols"`
-	StrategyPrompt  string `json:"strategy_prompt"`
-	APIKey          string `json:"api_key"`
+	BaseURL         string    `json:"base_url"`
+	Model           string    `json:"model"`
+	RiskProfile     string    `json:"risk_profile"`
+	Horizon         string    `json:"horizon"`
+	AdviceStyle     string    `json:"advice_style"`
+	AllowNewSymbols *bool     `json:"allow_new_symbols"`
+	StrategyPrompt  string    `json:"strategy_prompt"`
+	APIKey          string    `json:"api_key"`
+	TheoryTags      *[]string `json:"theory_tags"`
 }
 
 type aiAnalysisMethodPayload struct {
@@ -80,6 +91,15 @@ type aiAnalysisMethodPayload struct {
 	UserPrompt   string `json:"user_prompt"`
 }
 
+type aiAnalysisProfilePayload struct {
+	Name         string   `json:"name"`
+	Language     string   `json:"language"`
+	Verbosity    string   `json:"verbosity"`
+	PrivacyMode  bool     `json:"privacy_mode"`
+	TheoryTags   []string `json:"theory_tags"`
+	FallbackText string   `json:"fallback_text"`
+}
+
 type aiAnalysisStreamPayload struct {
 	MethodID  int64             `json:"method_id"`
 	Variables map[string]string `json:"variables"`
@@ -95,16 +115,52 @@ type aiSymbolAnalysisPayload struct {
 	Horizon        string `json:"horizon"`
 	AdviceStyle    string `json:"advice_style"`
 	StrategyPrompt string `json:"strategy_prompt"`
+	PositionBasis  string `json:"position_basis"`
+	Force          bool   `json:"force"`
+}
+
+type aiSymbolComparePayload struct {
+	BaseURL        string   `json:"base_url"`
+	APIKey         string   `json:"api_key"`
+	Model          string   `json:"model"`
+	Symbols        []string `json:"symbols"`
+	Currency       string   `json:"currency"`
+	RiskProfile    string   `json:"risk_profile"`
+	Horizon        string   `json:"horizon"`
+	AdviceStyle    string   `json:"advice_style"`
+	StrategyPrompt string   `json:"strategy_prompt"`
+	PositionBasis  string   `json:"position_basis"`
+	Force          bool     `json:"force"`
+}
+
+type aiSymbolResynthesizePayload struct {
+	BaseURL        string `json:"base_url"`
+	APIKey         string `json:"api_key"`
+	Model          string `json:"model"`
+	RiskProfile    string `json:"risk_profile"`
+	Horizon        string `json:"horizon"`
+	AdviceStyle    string `json:"advice_style"`
+	StrategyPrompt string `json:"strategy_prompt"`
+	PositionBasis  string `json:"position_basis"`
+}
+
+type aiSymbolRetryPayload struct {
+	APIKey string `json:"api_key"`
 }
 
 type addAccountPayload struct {
-	AccountID         string           `json:"account_id"`
-	AccountName       string           `json:"account_name"`
-	Broker            *string          `json:"broker"`
-	AccountType       *string          `json:"account_type"`
-	InitialBalanceCNY investlog.Amount `json:"initial_balance_cny"`
-	InitialBalanceUSD investlog.Amount `json:"initial_balance_usd"`
-	InitialBalanceHKD investlog.Amount `json:"initial_balance_hkd"`
+	AccountID         string            `json:"account_id"`
+	AccountName       string            `json:"account_name"`
+	Broker            *string           `json:"broker"`
+	AccountType       *string           `json:"account_type"`
+	InitialBalanceCNY investlog.Amount  `json:"initial_balance_cny"`
+	InitialBalanceUSD investlog.Amount  `json:"initial_balance_usd"`
+	InitialBalanceHKD investlog.Amount  `json:"initial_balance_hkd"`
+	DefaultCommission *investlog.Amount `json:"default_commission"`
+}
+
+type accountDefaultCommissionPayload struct {
+	DefaultCommission *investlog.Amount `json:"default_commission"`
 }
 
 type assetTypePayload struct {
@@ -125,6 +181,37 @@ type aiAllocationAdvicePayload struct {
 	CustomPrompt    string   `json:"custom_prompt"`
 }
 
+type priceCircuitSettingsPayload struct {
+	FailThreshold     int `json:"fail_threshold"`
+	FailWindowSeconds int `json:"fail_window_seconds"`
+	CooldownSeconds   int `json:"cooldown_seconds"`
+}
+
+type watchlistPayload struct {
+	Symbol    string  `json:"symbol"`
+	Currency  string  `json:"currency"`
+	AssetType string  `json:"asset_type"`
+	Notes     *string `json:"notes"`
+}
+
+type priceAlertPayload struct {
+	Symbol      string  `json:"symbol"`
+	Currency    string  `json:"currency"`
+	TargetPrice float64 `json:"target_price"`
+	Direction   string  `json:"direction"`
+}
+
+type reportingSettingsPayload struct {
+	DefaultBaseCurrency string `json:"default_base_currency"`
+}
+
+type simulatePositionPayload struct {
+	Symbol   string  `json:"symbol"`
+	Currency string  `json:"currency"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
 type allocationPayload struct {
 	Currency   string  `json:"currency"`
 	AssetType  string  `json:"asset_type"`
@@ -154,6 +241,24 @@ type updateSymbolAutoUpdatePayload struct {
 	AutoUpdate int `json:"auto_update"`
 }
 
+type updateSymbolInactivePayload struct {
+	Currency string `json:"currency"`
+	Inactive bool   `json:"inactive"`
+}
+
+type symbolTypeOverridePayload struct {
+	Currency   string `json:"currency"`
+	SymbolType string `json:"symbol_type"`
+}
+
+type fetchSymbolMetadataPayload struct {
+	Currency string `json:"currency"`
+}
+
+type refreshSymbolPayload struct {
+	Currency string `json:"currency"`
+}
+
 type transferPayload struct {
 	TransactionDate string           `json:"transaction_date"`
 	Symbol          string           `json:"symbol"`
@@ -167,6 +272,11 @@ type transferPayload struct {
 	Notes           *string          `json:"notes"`
 }
 
+type symbolAnalysisStatusesPayload struct {
+	Symbols  []string `json:"symbols"`
+	Currency string   `json:"currency"`
+}
+
 type storageSwitchPayload struct {
 	DBName string `json:"db_name"`
 	Create bool   `json:"create"`
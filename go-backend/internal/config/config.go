@@ -16,6 +16,8 @@ type UserConfig struct {
 	UseICloud     bool   `json:"use_icloud"`
 	DataDir       string `json:"data_dir"`
 	SetupComplete bool   `json:"setup_complete"`
+	// TimeZone is the IANA zone name for generated dates; empty keeps Asia/Shanghai.
+	TimeZone string `json:"time_zone,omitempty"`
 }
 
 var runtimeDataDir string
@@ -124,47 +124,95 @@ func (w *DailyWriter) cleanup(now time.Time) {
 	}
 }
 
-// NewLogger creates a slog.Logger writing to stdout and a daily file.
+// Log output formats accepted by NewLoggerWithFormat.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// NewLogger creates a slog.Logger writing text to stdout and a daily file.
 func NewLogger(logDir string, level slog.Level) (*slog.Logger, *DailyWriter, error) {
+	return NewLoggerWithFormat(logDir, level, FormatText)
+}
+
+// NewLoggerWithFormat creates a slog.Logger writing to stdout and a daily
+// file in the given format. As with the level, INVEST_LOG_LOG_FORMAT takes
+// precedence when set.
+func NewLoggerWithFormat(logDir string, level slog.Level, format string) (*slog.Logger, *DailyWriter, error) {
+	format, err := ParseFormat(format)
+	if err != nil {
+		return nil, nil, err
+	}
 	writer, err := NewDailyWriter(logDir, 7)
 	if err != nil {
 		return nil, nil, err
 	}
 	multi := io.MultiWriter(os.Stdout, writer)
 	effectiveLevel := resolveLevel(level)
-	handler := newHandler(multi, effectiveLevel)
+	handler := newHandler(multi, effectiveLevel, resolveFormat(format))
 	logger := slog.New(handler).With("service", defaultPrefix)
 	slog.SetDefault(logger)
 	return logger, writer, nil
 }
 
-func resolveLevel(fallback slog.Level) slog.Level {
-	value := strings.TrimSpace(os.Getenv(envLogLevel))
-	if value == "" {
-		return fallback
+// ParseFormat normalizes a log format name; empty means FormatText.
+func ParseFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported log format %q (want text or json)", value)
 	}
+}
 
-	switch strings.ToLower(value) {
+// ParseLevel parses a level name (debug/info/warn/error) or a numeric level.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
 	case "debug":
-		return slog.LevelDebug
+		return slog.LevelDebug, nil
 	case "info":
-		return slog.LevelInfo
+		return slog.LevelInfo, nil
 	case "warn", "warning":
-		return slog.LevelWarn
+		return slog.LevelWarn, nil
 	case "error":
-		return slog.LevelError
+		return slog.LevelError, nil
 	default:
-		if i, err := strconv.Atoi(value); err == nil {
-			return slog.Level(i)
+		if i, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return slog.Level(i), nil
 		}
+		return 0, fmt.Errorf("unsupported log level %q", value)
+	}
+}
+
+func resolveLevel(fallback slog.Level) slog.Level {
+	value := strings.TrimSpace(os.Getenv(envLogLevel))
+	if value == "" {
+		return fallback
+	}
+	level, err := ParseLevel(value)
+	if err != nil {
+		return fallback
+	}
+	return level
+}
+
+func resolveFormat(fallback string) string {
+	value := strings.TrimSpace(os.Getenv(envLogFormat))
+	if value == "" {
+		return fallback
+	}
+	format, err := ParseFormat(value)
+	if err != nil {
 		return fallback
 	}
+	return format
 }
 
-func newHandler(w io.Writer, level slog.Level) slog.Handler {
+func newHandler(w io.Writer, level slog.Level, format string) slog.Handler {
 	options := &slog.HandlerOptions{Level: level}
-	format := strings.ToLower(strings.TrimSpace(os.Getenv(envLogFormat)))
-	if format == "json" {
+	if format == FormatJSON {
 		return slog.NewJSONHandler(w, options)
 	}
 	return slog.NewTextHandler(w, options)
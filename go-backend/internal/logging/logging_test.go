@@ -162,3 +162,73 @@ func TestNewLoggerSetsSlogDefault(t *testing.T) {
 		t.Fatalf("expected slog.Default to be updated")
 	}
 }
+
+func TestNewLoggerWithFormatHandlerType(t *testing.T) {
+	oldDefault := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(oldDefault)
+	})
+
+	for _, tt := range []struct {
+		format string
+		isJSON bool
+	}{
+		{format: "", isJSON: false},
+		{format: FormatText, isJSON: false},
+		{format: "JSON", isJSON: true},
+	} {
+		logger, writer, err := NewLoggerWithFormat(t.TempDir(), slog.LevelInfo, tt.format)
+		if err != nil {
+			t.Fatalf("NewLoggerWithFormat(%q): %v", tt.format, err)
+		}
+		_ = writer.Close()
+		_, isJSON := logger.Handler().(*slog.JSONHandler)
+		_, isText := logger.Handler().(*slog.TextHandler)
+		if isJSON != tt.isJSON || isText == tt.isJSON {
+			t.Fatalf("format %q: unexpected handler %T", tt.format, logger.Handler())
+		}
+	}
+
+	if _, _, err := NewLoggerWithFormat(t.TempDir(), slog.LevelInfo, "xml"); err == nil {
+		t.Fatal("expected unsupported format error")
+	}
+}
+
+func TestNewLoggerWithFormatFiltersLevel(t *testing.T) {
+	oldDefault := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(oldDefault)
+	})
+
+	dir := t.TempDir()
+	logger, writer, err := NewLoggerWithFormat(dir, slog.LevelWarn, FormatJSON)
+	if err != nil {
+		t.Fatalf("NewLoggerWithFormat: %v", err)
+	}
+	logger.Info("info filtered out")
+	logger.Warn("warn kept")
+	_ = writer.Close()
+
+	date := time.Now().Format("20060102")
+	data, err := os.ReadFile(filepath.Join(dir, defaultPrefix+"-"+date+".log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if strings.Contains(string(data), "info filtered out") || !strings.Contains(string(data), `"msg":"warn kept"`) {
+		t.Fatalf("expected only the warn line, got %q", string(data))
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for value, want := range map[string]slog.Level{
+		"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warning": slog.LevelWarn, "error": slog.LevelError, "8": slog.Level(8),
+	} {
+		got, err := ParseLevel(value)
+		if err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Fatal("expected unsupported level error")
+	}
+}
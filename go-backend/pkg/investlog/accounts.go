@@ -11,13 +11,17 @@ func (c *Core) AddAccount(account Account) (bool, error) {
 	if account.AccountID == "" || account.AccountName == "" {
 		return false, fmt.Errorf("account_id and account_name are required")
 	}
+	if err := validateDefaultCommission(account.DefaultCommission); err != nil {
+		return false, err
+	}
 	_, err := c.db.Exec(`
-		INSERT INTO accounts (account_id, account_name, broker, account_type)
-		VALUES (?, ?, ?, ?)
-	`, account.AccountID, account.AccountName, account.Broker, account.AccountType)
+		INSERT INTO accounts (account_id, account_name, broker, account_type, default_commission)
+		VALUES (?, ?, ?, ?, ?)
+	`, account.AccountID, account.AccountName, account.Broker, account.AccountType, account.DefaultCommission)
 	if err != nil {
 		return false, err
 	}
+	c.invalidateHoldingsCache()
 	return true, nil
 }
 
@@ -38,7 +42,7 @@ func ensureAccountTx(tx *sql.Tx, accountID string, accountName *string) error {
 
 // GetAccounts returns all accounts.
 func (c *Core) GetAccounts() ([]Account, error) {
-	rows, err := c.db.Query("SELECT account_id, account_name, broker, account_type, created_at FROM accounts ORDER BY account_id")
+	rows, err := c.db.Query("SELECT account_id, account_name, broker, account_type, default_commission, created_at FROM accounts ORDER BY account_id")
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +52,13 @@ func (c *Core) GetAccounts() ([]Account, error) {
 	for rows.Next() {
 		var acc Account
 		var broker, accType, createdAt sql.NullString
-		if err := rows.Scan(&acc.AccountID, &acc.AccountName, &broker, &accType, &createdAt); err != nil {
+		var defaultCommission sql.NullFloat64
+		if err := rows.Scan(&acc.AccountID, &acc.AccountName, &broker, &accType, &defaultCommission, &createdAt); err != nil {
 			return nil, err
 		}
+		if defaultCommission.Valid {
+			acc.DefaultCommission = amountPtr(NewAmount(defaultCommission.Float64))
+		}
 		if broker.Valid {
 			acc.Broker = &broker.String
 		}
@@ -65,6 +73,44 @@ func (c *Core) GetAccounts() ([]Account, error) {
 	return accounts, rows.Err()
 }
 
+// SetAccountDefaultCommission sets the commission applied to trades that
+// opt into the account default. A nil commission clears the default.
+func (c *Core) SetAccountDefaultCommission(accountID string, commission *Amount) (bool, error) {
+	if err := validateDefaultCommission(commission); err != nil {
+		return false, err
+	}
+	result, err := c.db.Exec("UPDATE accounts SET default_commission = ? WHERE account_id = ?", commission, accountID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func validateDefaultCommission(commission *Amount) error {
+	if commission != nil && commission.IsNegative() {
+		return NewValidationError("default_commission", "default_commission cannot be negative")
+	}
+	return nil
+}
+
+// accountDefaultCommissionTx returns the account's default commission, or
+// nil when none is set.
+func accountDefaultCommissionTx(tx *sql.Tx, accountID string) (*Amount, error) {
+	var commission sql.NullFloat64
+	err := tx.QueryRow("SELECT default_commission FROM accounts WHERE account_id = ?", accountID).Scan(&commission)
+	if err == sql.ErrNoRows || (err == nil && !commission.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return amountPtr(NewAmount(commission.Float64)), nil
+}
+
 // CheckAccountInUse returns true if the account has transactions.
 func (c *Core) CheckAccountInUse(accountID string) (bool, error) {
 	var count int
@@ -92,6 +138,7 @@ func (c *Core) DeleteAccount(accountID string) (bool, string, error) {
 		return false, "", err
 	}
 	if rows > 0 {
+		c.invalidateHoldingsCache()
 		return true, "Account deleted", nil
 	}
 	return false, "Account not found", nil
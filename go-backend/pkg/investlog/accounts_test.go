@@ -193,3 +193,65 @@ func TestDeleteAccount_NonExistent(t *testing.T) {
 		t.Error("should not report deleted for non-existent account")
 	}
 }
+
+func TestAddTransaction_DefaultCommission(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := core.AddAccount(Account{
+		AccountID:         "broker",
+		AccountName:       "Broker",
+		DefaultCommission: amountPtr(NewAmount(5)),
+	})
+	assertNoError(t, err, "add account")
+
+	buy := func(commission Amount, useDefault bool) Amount {
+		t.Helper()
+		id, err := core.AddTransaction(AddTransactionRequest{
+			Symbol:               "AAPL",
+			TransactionType:      "BUY",
+			Quantity:             NewAmountFromInt(1),
+			Price:                NewAmountFromInt(100),
+			Currency:             "USD",
+			AccountID:            "broker",
+			Commission:           commission,
+			UseDefaultCommission: useDefault,
+		})
+		assertNoError(t, err, "add transaction")
+		txn, err := core.GetTransaction(id)
+		assertNoError(t, err, "get transaction")
+		return txn.Commission
+	}
+
+	if got := buy(Amount{}, true); !got.Equal(NewAmount(5).Decimal) {
+		t.Fatalf("expected default commission 5 when unset, got %s", got)
+	}
+	if got := buy(Amount{}, false); !got.IsZero() {
+		t.Fatalf("expected explicit zero commission, got %s", got)
+	}
+	if got := buy(NewAmount(2), true); !got.Equal(NewAmount(2).Decimal) {
+		t.Fatalf("expected explicit commission 2 to win, got %s", got)
+	}
+
+	accounts, err := core.GetAccounts()
+	assertNoError(t, err, "get accounts")
+	if accounts[0].DefaultCommission == nil || !accounts[0].DefaultCommission.Equal(NewAmount(5).Decimal) {
+		t.Fatalf("expected default commission on account, got %v", accounts[0].DefaultCommission)
+	}
+
+	updated, err := core.SetAccountDefaultCommission("broker", nil)
+	assertNoError(t, err, "clear default commission")
+	if !updated {
+		t.Fatal("expected account to be updated")
+	}
+	if got := buy(Amount{}, true); !got.IsZero() {
+		t.Fatalf("expected no commission after clearing default, got %s", got)
+	}
+
+	if _, err := core.SetAccountDefaultCommission("broker", amountPtr(NewAmount(-1))); err == nil {
+		t.Fatal("expected negative default commission to be rejected")
+	}
+	if updated, err := core.SetAccountDefaultCommission("missing", nil); err != nil || updated {
+		t.Fatalf("expected missing account not updated, got %v, %v", updated, err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 )
 
 const allocationAdviceSystemPrompt = `你是一个专业的资产配置顾问，精通现代投资组合理论，擅长将学术理论转化为实际可执行的配置建议。
@@ -44,6 +43,8 @@ type AllocationAdviceRequest struct {
 	ExperienceLevel string // "beginner", "intermediate", "experienced"
 	Currencies      []string
 	CustomPrompt    string
+	// Context bounds the AI calls; it defaults to context.Background().
+	Context context.Context
 }
 
 // AllocationAdviceEntry is one recommended allocation band for a currency+asset_type pair.
@@ -107,6 +108,7 @@ func (c *Core) GetAllocationAdviceWithStream(req AllocationAdviceRequest, onDelt
 }
 
 func (c *Core) getAllocationAdvice(req AllocationAdviceRequest, onDelta func(string)) (*AllocationAdviceResult, error) {
+	req.APIKey = c.resolveAIAPIKey(req.APIKey)
 	if err := normalizeAllocationAdviceRequest(&req); err != nil {
 		return nil, err
 	}
@@ -129,10 +131,10 @@ func (c *Core) getAllocationAdvice(req AllocationAdviceRequest, onDelta func(str
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), aiTotalRequestTimeout)
+	ctx, cancel := context.WithTimeout(requestContext(req.Context), aiTotalRequestTimeout)
 	defer cancel()
 
-	chatResult, err := aiChatCompletion(ctx, aiChatCompletionRequest{
+	chatResult, err := c.chatCompletion(ctx, aiChatCompletionRequest{
 		EndpointURL:  endpointURL,
 		APIKey:       req.APIKey,
 		Model:        req.Model,
@@ -167,7 +169,7 @@ func (c *Core) getAllocationAdvice(req AllocationAdviceRequest, onDelta func(str
 	}
 
 	return &AllocationAdviceResult{
-		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		GeneratedAt: c.NowRFC3339(),
 		Model:       chatResult.Model,
 		Summary:     parsed.Summary,
 		Rationale:   parsed.Rationale,
@@ -182,10 +184,13 @@ func normalizeAllocationAdviceRequest(req *AllocationAdviceRequest) error {
 	req.Model = strings.TrimSpace(req.Model)
 
 	if req.APIKey == "" {
-		return fmt.Errorf("API key is required")
+		return NewValidationError("api_key", "API key is required")
+	}
+	if err := validateAIAPIKey(req.APIKey); err != nil {
+		return err
 	}
 	if req.Model == "" {
-		return fmt.Errorf("model is required")
+		return NewValidationError("model", "model is required")
 	}
 	req.Model = normalizeAIModel(req.Model)
 
@@ -200,7 +205,7 @@ func normalizeAllocationAdviceRequest(req *AllocationAdviceRequest) error {
 		}
 	}
 	if len(currencies) == 0 {
-		return fmt.Errorf("at least one valid currency (CNY, USD, HKD) is required")
+		return NewValidationError("currencies", "at least one valid currency (CNY, USD, HKD) is required")
 	}
 	req.Currencies = currencies
 
@@ -0,0 +1,43 @@
+package investlog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+type analysisLoggerKey struct{}
+
+var analysisIDFallback atomic.Uint64
+
+// newAnalysisID returns a short random ID that tags every log line of one
+// analysis run.
+func newAnalysisID() string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "a" + strconv.FormatUint(analysisIDFallback.Add(1), 10)
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// withAnalysisLogger starts an analysis run: it returns ctx carrying a logger
+// tagged with a fresh analysis_id, and that logger.
+func (c *Core) withAnalysisLogger(ctx context.Context) (context.Context, *slog.Logger) {
+	logger := c.Logger().With("analysis_id", newAnalysisID())
+	return context.WithValue(ctx, analysisLoggerKey{}, logger), logger
+}
+
+// analysisLogger returns the analysis run's logger from ctx, or the core
+// logger outside one. The concurrent agents of a run log through it so
+// their lines can be correlated.
+func (c *Core) analysisLogger(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(analysisLoggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return c.Logger()
+}
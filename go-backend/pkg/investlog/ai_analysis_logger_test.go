@@ -0,0 +1,125 @@
+package investlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// lockedBuffer collects log output written by concurrent agents.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) lines(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		if entry["msg"] == msg {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func TestAnalyzeSymbol_AgentLogsShareAnalysisID(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	logs := &lockedBuffer{}
+	core.logger = slog.New(slog.NewJSONHandler(logs, nil))
+
+	testAccount(t, core, "acc-logs", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-logs")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		req.Logger.Info("agent call")
+		return dimensionStubRouter(ctx, req)
+	}
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	req := SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+		Force:    true,
+	}
+	if _, err := core.AnalyzeSymbol(req); err != nil {
+		t.Fatalf("AnalyzeSymbol failed: %v", err)
+	}
+	first := logs.lines(t, "agent call")
+	// Three framework agents plus synthesis, and the retrieval call.
+	if len(first) < 4 {
+		t.Fatalf("expected at least 4 agent log lines, got %d", len(first))
+	}
+	analysisID, _ := first[0]["analysis_id"].(string)
+	if analysisID == "" {
+		t.Fatalf("expected analysis_id on agent logs, got %v", first[0])
+	}
+	frameworks := 0
+	for _, entry := range first {
+		if entry["analysis_id"] != analysisID {
+			t.Fatalf("expected analysis_id %s on every agent line, got %v", analysisID, entry)
+		}
+		if entry["framework"] != nil {
+			frameworks++
+		}
+	}
+	if frameworks != 3 {
+		t.Fatalf("expected 3 framework agent lines, got %d", frameworks)
+	}
+
+	if _, err := core.AnalyzeSymbol(req); err != nil {
+		t.Fatalf("second AnalyzeSymbol failed: %v", err)
+	}
+	all := logs.lines(t, "agent call")
+	if second := all[len(all)-1]["analysis_id"]; second == analysisID {
+		t.Fatalf("expected a new analysis_id for the second run, got %v", second)
+	}
+}
+
+func TestAnalysisLogger_FallsBackToCoreLogger(t *testing.T) {
+	t.Parallel()
+
+	logs := &lockedBuffer{}
+	core := &Core{logger: slog.New(slog.NewJSONHandler(logs, nil))}
+	core.analysisLogger(context.Background()).Info("plain")
+	ctx, logger := core.withAnalysisLogger(context.Background())
+	if core.analysisLogger(ctx) != logger {
+		t.Fatal("expected the context logger")
+	}
+	logger.Info("tagged")
+
+	if plain := logs.lines(t, "plain"); len(plain) != 1 || plain[0]["analysis_id"] != nil {
+		t.Fatalf("expected untagged line, got %v", plain)
+	}
+	if tagged := logs.lines(t, "tagged"); len(tagged) != 1 || tagged[0]["analysis_id"] == nil {
+		t.Fatalf("expected tagged line, got %v", tagged)
+	}
+}
@@ -1,6 +1,7 @@
 package investlog
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
@@ -24,6 +25,8 @@ type AIAnalysisMethod struct {
 type RunAIAnalysisRequest struct {
 	MethodID  int64
 	Variables map[string]string
+	// Context bounds the AI calls; it defaults to context.Background().
+	Context context.Context
 }
 
 func extractAIAnalysisVariables(systemPrompt, userPrompt string) []string {
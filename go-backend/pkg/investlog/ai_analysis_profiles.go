@@ -0,0 +1,253 @@
+package investlog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultAnalysisProfileLanguage  = "zh"
+	defaultAnalysisProfileVerbosity = "standard"
+)
+
+var validAnalysisProfileLanguages = map[string]struct{}{
+	"zh": {},
+	"en": {},
+}
+
+var validAnalysisProfileVerbosities = map[string]struct{}{
+	"brief":    {},
+	"standard": {},
+	"detailed": {},
+}
+
+// AIAnalysisProfile bundles output preferences that analysis requests can
+// select by name instead of passing each option individually.
+type AIAnalysisProfile struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Language  string `json:"language"`  // "zh" or "en"
+	Verbosity string `json:"verbosity"` // "brief", "standard" or "detailed"
+	// PrivacyMode withholds average cost and PnL from the prompt so only
+	// symbols and weights leave the device.
+	PrivacyMode bool `json:"privacy_mode"`
+	// TheoryTags restricts recommendation theory_tag values; the first tag
+	// replaces any value outside the list. Empty keeps the default behaviour.
+	TheoryTags []string `json:"theory_tags"`
+	// FallbackText replaces the built-in placeholder when the model omits a
+	// summary or recommendation rationale.
+	FallbackText string `json:"fallback_text,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// normalizeAIAnalysisProfile fills profile defaults. Invalid fields are
+// reported together as a ValidationError.
+func normalizeAIAnalysisProfile(profile AIAnalysisProfile) (AIAnalysisProfile, error) {
+	invalid := &ValidationError{}
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		invalid.Add("name", "name is required")
+	}
+	language, err := normalizeEnum(strings.TrimSpace(profile.Language), defaultAnalysisProfileLanguage, validAnalysisProfileLanguages)
+	if err != nil {
+		invalid.Add("language", fmt.Sprintf("invalid language: %v", err))
+	}
+	profile.Language = language
+	verbosity, err := normalizeEnum(strings.TrimSpace(profile.Verbosity), defaultAnalysisProfileVerbosity, validAnalysisProfileVerbosities)
+	if err != nil {
+		invalid.Add("verbosity", fmt.Sprintf("invalid verbosity: %v", err))
+	}
+	profile.Verbosity = verbosity
+	if err := invalid.Err(); err != nil {
+		return AIAnalysisProfile{}, err
+	}
+
+	profile.TheoryTags = normalizeTheoryTags(profile.TheoryTags)
+	profile.FallbackText = strings.TrimSpace(profile.FallbackText)
+	return profile, nil
+}
+
+// ListAIAnalysisProfiles returns all saved analysis profiles ordered by name.
+func (c *Core) ListAIAnalysisProfiles() ([]AIAnalysisProfile, error) {
+	rows, err := c.db.Query(`
+		SELECT id, name, language, verbosity, privacy_mode, theory_tags, fallback_text, created_at, updated_at
+		FROM ai_analysis_profiles
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query ai analysis profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []AIAnalysisProfile{}
+	for rows.Next() {
+		profile, err := scanAIAnalysisProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate ai analysis profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// GetAIAnalysisProfileByName returns the profile with the given name, or nil if none exists.
+func (c *Core) GetAIAnalysisProfileByName(name string) (*AIAnalysisProfile, error) {
+	row := c.db.QueryRow(`
+		SELECT id, name, language, verbosity, privacy_mode, theory_tags, fallback_text, created_at, updated_at
+		FROM ai_analysis_profiles
+		WHERE name = ?
+	`, strings.TrimSpace(name))
+	profile, err := scanAIAnalysisProfile(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// SaveAIAnalysisProfile creates a profile or replaces the one with the same name.
+func (c *Core) SaveAIAnalysisProfile(profile AIAnalysisProfile) (AIAnalysisProfile, error) {
+	normalized, err := normalizeAIAnalysisProfile(profile)
+	if err != nil {
+		return AIAnalysisProfile{}, err
+	}
+	tagsJSON, err := json.Marshal(normalized.TheoryTags)
+	if err != nil {
+		return AIAnalysisProfile{}, fmt.Errorf("marshal theory tags: %w", err)
+	}
+	privacy := 0
+	if normalized.PrivacyMode {
+		privacy = 1
+	}
+
+	_, err = c.db.Exec(`
+		INSERT INTO ai_analysis_profiles (name, language, verbosity, privacy_mode, theory_tags, fallback_text)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			language = excluded.language,
+			verbosity = excluded.verbosity,
+			privacy_mode = excluded.privacy_mode,
+			theory_tags = excluded.theory_tags,
+			fallback_text = excluded.fallback_text,
+			updated_at = CURRENT_TIMESTAMP
+	`, normalized.Name, normalized.Language, normalized.Verbosity, privacy, string(tagsJSON), normalized.FallbackText)
+	if err != nil {
+		return AIAnalysisProfile{}, fmt.Errorf("save ai analysis profile: %w", err)
+	}
+
+	saved, err := c.GetAIAnalysisProfileByName(normalized.Name)
+	if err != nil {
+		return AIAnalysisProfile{}, err
+	}
+	if saved == nil {
+		return AIAnalysisProfile{}, fmt.Errorf("ai analysis profile not found after save")
+	}
+	return *saved, nil
+}
+
+// DeleteAIAnalysisProfile deletes a profile by name.
+func (c *Core) DeleteAIAnalysisProfile(name string) (bool, error) {
+	res, err := c.db.Exec(`DELETE FROM ai_analysis_profiles WHERE name = ?`, strings.TrimSpace(name))
+	if err != nil {
+		return false, fmt.Errorf("delete ai analysis profile: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get deleted ai analysis profile rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// resolveAIAnalysisProfile loads the profile named on a request. An empty name
+// yields nil; an unknown name is an error rather than silently ignored.
+func (c *Core) resolveAIAnalysisProfile(name string) (*AIAnalysisProfile, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+	profile, err := c.GetAIAnalysisProfileByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, NewError(ErrCodeNotFound, fmt.Sprintf("ai analysis profile not found: %s", strings.TrimSpace(name)))
+	}
+	return profile, nil
+}
+
+func scanAIAnalysisProfile(scanner interface{ Scan(dest ...any) error }) (AIAnalysisProfile, error) {
+	var (
+		profile  AIAnalysisProfile
+		privacy  int
+		tagsJSON string
+	)
+	if err := scanner.Scan(
+		&profile.ID,
+		&profile.Name,
+		&profile.Language,
+		&profile.Verbosity,
+		&privacy,
+		&tagsJSON,
+		&profile.FallbackText,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	); err != nil {
+		return AIAnalysisProfile{}, err
+	}
+	profile.PrivacyMode = privacy != 0
+	profile.TheoryTags = []string{}
+	if strings.TrimSpace(tagsJSON) != "" {
+		if err := json.Unmarshal([]byte(tagsJSON), &profile.TheoryTags); err != nil {
+			return AIAnalysisProfile{}, fmt.Errorf("decode theory tags: %w", err)
+		}
+	}
+	return profile, nil
+}
+
+// analysisProfilePromptRules returns extra output instructions for a profile.
+func analysisProfilePromptRules(profile *AIAnalysisProfile) []string {
+	if profile == nil {
+		return nil
+	}
+	var rules []string
+	if profile.Language == "en" {
+		rules = append(rules, "所有文本字段必须使用英文（English）输出。")
+	}
+	switch profile.Verbosity {
+	case "brief":
+		rules = append(rules, "输出保持简洁：overall_summary 不超过 80 字，每条 rationale 一句话。")
+	case "detailed":
+		rules = append(rules, "输出尽量详尽：overall_summary 需覆盖组合结构、主要风险与应对，rationale 需给出数据依据。")
+	}
+	if len(profile.TheoryTags) > 0 {
+		rules = append(rules, fmt.Sprintf("theory_tag 只能取以下值之一：%s。", strings.Join(profile.TheoryTags, "/")))
+	}
+	if profile.PrivacyMode {
+		rules = append(rules, "用户开启了隐私模式，仅提供标的代码与持仓占比，不得推测或输出用户的成本与盈亏。")
+	}
+	return rules
+}
+
+// theoryTags returns the profile's vocabulary, or fallback when the profile
+// does not restrict theory tags.
+func (p *AIAnalysisProfile) theoryTags(fallback []string) []string {
+	if p == nil || len(p.TheoryTags) == 0 {
+		return fallback
+	}
+	return p.TheoryTags
+}
+
+// fallbackText returns the profile fallback text or def when unset.
+func (p *AIAnalysisProfile) fallbackText(def string) string {
+	if p == nil || p.FallbackText == "" {
+		return def
+	}
+	return p.FallbackText
+}
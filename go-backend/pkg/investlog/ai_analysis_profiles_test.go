@@ -0,0 +1,131 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAIAnalysisProfileCRUD(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	saved, err := core.SaveAIAnalysisProfile(AIAnalysisProfile{
+		Name:       " weekly-en ",
+		Language:   "EN",
+		TheoryTags: []string{" Buffett ", "buffett", "Dalio", ""},
+	})
+	assertNoError(t, err, "save profile")
+	if saved.Name != "weekly-en" || saved.Language != "en" || saved.Verbosity != "standard" {
+		t.Fatalf("unexpected normalized profile: %+v", saved)
+	}
+	if strings.Join(saved.TheoryTags, ",") != "Buffett,Dalio" {
+		t.Fatalf("expected deduplicated theory tags, got %v", saved.TheoryTags)
+	}
+
+	updated, err := core.SaveAIAnalysisProfile(AIAnalysisProfile{Name: "weekly-en", Verbosity: "brief", PrivacyMode: true})
+	assertNoError(t, err, "update profile")
+	if updated.ID != saved.ID || updated.Verbosity != "brief" || !updated.PrivacyMode || len(updated.TheoryTags) != 0 {
+		t.Fatalf("expected profile replaced in place, got %+v", updated)
+	}
+
+	_, err = core.SaveAIAnalysisProfile(AIAnalysisProfile{Name: "bad", Verbosity: "chatty"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Fields["verbosity"] == "" {
+		t.Fatalf("expected invalid verbosity validation error, got %v", err)
+	}
+
+	profiles, err := core.ListAIAnalysisProfiles()
+	assertNoError(t, err, "list profiles")
+	if len(profiles) != 1 {
+		t.Fatalf("expected 1 profile, got %d", len(profiles))
+	}
+
+	deleted, err := core.DeleteAIAnalysisProfile("weekly-en")
+	assertNoError(t, err, "delete profile")
+	if !deleted {
+		t.Fatal("expected profile deleted")
+	}
+	missing, err := core.GetAIAnalysisProfileByName("weekly-en")
+	assertNoError(t, err, "get deleted profile")
+	if missing != nil {
+		t.Fatalf("expected nil after delete, got %+v", missing)
+	}
+}
+
+func TestAnalyzeHoldingsAppliesProfile(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 123.45, "USD", "acc-1")
+
+	_, err := core.SaveAIAnalysisProfile(AIAnalysisProfile{
+		Name:         "private-brief",
+		Language:     "en",
+		Verbosity:    "brief",
+		PrivacyMode:  true,
+		TheoryTags:   []string{"Buffett", "Dalio"},
+		FallbackText: "No rationale provided.",
+	})
+	assertNoError(t, err, "save profile")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+
+	var userPrompt string
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		userPrompt = req.UserPrompt
+		return aiChatCompletionResult{
+			Model: "mock-model",
+			Content: `{
+				"overall_summary":"",
+				"risk_level":"balanced",
+				"key_findings":["concentrated"],
+				"recommendations":[
+					{"symbol":"AAPL","action":"hold","theory_tag":"Graham","rationale":""},
+					{"symbol":"AAPL","action":"reduce","theory_tag":"dalio","rationale":"trim"}
+				],
+				"disclaimer":"for reference only"
+			}`,
+		}, nil
+	}
+
+	result, err := core.AnalyzeHoldings(HoldingsAnalysisRequest{
+		APIKey:   "key",
+		Model:    "mock-model",
+		Currency: "USD",
+		Profile:  "private-brief",
+	})
+	assertNoError(t, err, "analyze holdings")
+
+	for _, want := range []string{"English", "简洁", "Buffett/Dalio", "隐私模式"} {
+		if !strings.Contains(userPrompt, want) {
+			t.Fatalf("expected prompt to contain %q, got: %s", want, userPrompt)
+		}
+	}
+	for _, forbidden := range []string{`"avg_cost"`, `"pnl_pct"`, "123.45"} {
+		if strings.Contains(userPrompt, forbidden) {
+			t.Fatalf("privacy mode prompt must not contain %s, got: %s", forbidden, userPrompt)
+		}
+	}
+
+	if result.OverallSummary != "No rationale provided." {
+		t.Fatalf("expected fallback summary, got %q", result.OverallSummary)
+	}
+	if len(result.Recommendations) != 2 {
+		t.Fatalf("expected 2 recommendations, got %d", len(result.Recommendations))
+	}
+	if got := result.Recommendations[0]; got.TheoryTag != "Buffett" || got.Rationale != "No rationale provided." {
+		t.Fatalf("expected constrained tag and fallback rationale, got %+v", got)
+	}
+	if got := result.Recommendations[1].TheoryTag; got != "Dalio" {
+		t.Fatalf("expected allowed tag canonicalized to Dalio, got %q", got)
+	}
+
+	_, err = core.AnalyzeHoldings(HoldingsAnalysisRequest{APIKey: "key", Model: "mock-model", Profile: "missing"})
+	if !IsErrorCode(err, ErrCodeNotFound) {
+		t.Fatalf("expected not found error for unknown profile, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package investlog
+
+import "sort"
+
+// AnalyzableScope describes a currency that has holdings and can therefore be analyzed.
+type AnalyzableScope struct {
+	Currency    string   `json:"currency"`
+	SymbolCount int      `json:"symbol_count"`
+	Symbols     []string `json:"symbols"`
+}
+
+// GetAnalyzableScopes returns currencies with non-empty holdings, ordered by currency.
+func (c *Core) GetAnalyzableScopes() ([]AnalyzableScope, error) {
+	bySymbol, err := c.GetHoldingsBySymbol()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]AnalyzableScope, 0, len(bySymbol))
+	for curr, data := range bySymbol {
+		seen := make(map[string]struct{}, len(data.Symbols))
+		symbols := make([]string, 0, len(data.Symbols))
+		for _, item := range data.Symbols {
+			if !item.TotalShares.IsPositive() {
+				continue
+			}
+			if _, ok := seen[item.Symbol]; ok {
+				continue
+			}
+			seen[item.Symbol] = struct{}{}
+			symbols = append(symbols, item.Symbol)
+		}
+		if len(symbols) == 0 {
+			continue
+		}
+		sort.Strings(symbols)
+		scopes = append(scopes, AnalyzableScope{
+			Currency:    curr,
+			SymbolCount: len(symbols),
+			Symbols:     symbols,
+		})
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		return scopes[i].Currency < scopes[j].Currency
+	})
+	return scopes, nil
+}
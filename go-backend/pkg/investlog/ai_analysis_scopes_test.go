@@ -0,0 +1,44 @@
+package investlog
+
+import "testing"
+
+func TestGetAnalyzableScopes_MultiCurrency(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc1", "Account 1")
+	testAccount(t, core, "acc2", "Account 2")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc1")
+	testBuyTransaction(t, core, "AAPL", 5, 110, "USD", "acc2")
+	testBuyTransaction(t, core, "MSFT", 3, 300, "USD", "acc1")
+	testBuyTransaction(t, core, "600000", 100, 10, "CNY", "acc1")
+	// Fully sold HKD position should not make HKD analyzable.
+	testBuyTransaction(t, core, "00700", 100, 300, "HKD", "acc1")
+	testSellTransaction(t, core, "00700", 100, 320, "HKD", "acc1")
+
+	scopes, err := core.GetAnalyzableScopes()
+	assertNoError(t, err, "get analyzable scopes")
+	if len(scopes) != 2 {
+		t.Fatalf("expected 2 scopes, got %d: %+v", len(scopes), scopes)
+	}
+	if scopes[0].Currency != "CNY" || scopes[0].SymbolCount != 1 {
+		t.Fatalf("unexpected CNY scope: %+v", scopes[0])
+	}
+	if scopes[1].Currency != "USD" || scopes[1].SymbolCount != 2 {
+		t.Fatalf("unexpected USD scope: %+v", scopes[1])
+	}
+	if scopes[1].Symbols[0] != "AAPL" || scopes[1].Symbols[1] != "MSFT" {
+		t.Fatalf("expected sorted USD symbols, got %v", scopes[1].Symbols)
+	}
+}
+
+func TestGetAnalyzableScopes_Empty(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	scopes, err := core.GetAnalyzableScopes()
+	assertNoError(t, err, "get analyzable scopes")
+	if scopes == nil || len(scopes) != 0 {
+		t.Fatalf("expected empty non-nil scopes, got %#v", scopes)
+	}
+}
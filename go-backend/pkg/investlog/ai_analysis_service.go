@@ -45,7 +45,7 @@ func (c *Core) runAIAnalysis(req RunAIAnalysisRequest, onDelta func(string) erro
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), aiTotalRequestTimeout)
+	ctx, cancel := context.WithTimeout(requestContext(req.Context), aiTotalRequestTimeout)
 	defer cancel()
 
 	chatReq := aiChatCompletionRequest{
@@ -60,9 +60,9 @@ func (c *Core) runAIAnalysis(req RunAIAnalysisRequest, onDelta func(string) erro
 
 	var result aiChatCompletionResult
 	if onDelta != nil {
-		result, err = aiChatCompletionStream(ctx, chatReq, onDelta)
+		result, err = c.chatCompletionStream(ctx, chatReq, onDelta)
 	} else {
-		result, err = aiChatCompletion(ctx, chatReq)
+		result, err = c.chatCompletion(ctx, chatReq)
 	}
 	if err != nil {
 		_ = c.completeAIAnalysisRun(runID, "failed", "", err.Error())
@@ -108,11 +108,9 @@ func (c *Core) prepareAIAnalysis(req RunAIAnalysisRequest) (*AIAnalysisMethod, A
 	if err != nil {
 		return nil, AISettings{}, "", "", nil, err
 	}
-	if strings.TrimSpace(settings.APIKey) == "" {
-		return nil, AISettings{}, "", "", nil, fmt.Errorf("AI API key is required")
-	}
-	if strings.TrimSpace(settings.Model) == "" {
-		return nil, AISettings{}, "", "", nil, fmt.Errorf("AI model is required")
+	settings.APIKey = c.resolveAIAPIKey(settings.APIKey)
+	if err := requireAISettingsConfigured(settings); err != nil {
+		return nil, AISettings{}, "", "", nil, err
 	}
 
 	renderedSystemPrompt, normalizedVars, err := renderAIAnalysisPrompt(method.SystemPrompt, method.Variables, req.Variables)
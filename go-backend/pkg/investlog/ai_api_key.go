@@ -0,0 +1,50 @@
+package investlog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AIAPIKeyEnv names the environment variable holding the server-side AI API
+// key used when Options.AIAPIKeyFile is not set.
+const AIAPIKeyEnv = "AI_API_KEY"
+
+// loadServerAIAPIKey reads the server-side AI API key from path, or from
+// AIAPIKeyEnv when path is empty. An empty result means requests must
+// supply their own key.
+func loadServerAIAPIKey(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return strings.TrimSpace(os.Getenv(AIAPIKeyEnv)), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read ai api key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("ai api key file %s is empty", path)
+	}
+	if err := validateAIAPIKey(key); err != nil {
+		return "", fmt.Errorf("ai api key file %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// HasServerAIAPIKey reports whether AI requests may omit their API key.
+func (c *Core) HasServerAIAPIKey() bool {
+	return c != nil && c.aiAPIKey != ""
+}
+
+// resolveAIAPIKey returns the request's key, falling back to the
+// server-side key when the request leaves it blank.
+func (c *Core) resolveAIAPIKey(requestKey string) string {
+	if key := strings.TrimSpace(requestKey); key != "" {
+		return key
+	}
+	if c == nil {
+		return ""
+	}
+	return c.aiAPIKey
+}
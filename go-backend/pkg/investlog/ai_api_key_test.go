@@ -0,0 +1,85 @@
+package investlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadServerAIAPIKey(t *testing.T) {
+	t.Setenv(AIAPIKeyEnv, " env-key ")
+
+	key, err := loadServerAIAPIKey("")
+	if err != nil || key != "env-key" {
+		t.Fatalf("expected env key, got %q, %v", key, err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ai_key")
+	if err := os.WriteFile(path, []byte("file-key\n"), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+	key, err = loadServerAIAPIKey(path)
+	if err != nil || key != "file-key" {
+		t.Fatalf("expected file key to win over env, got %q, %v", key, err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("  \n"), 0o600); err != nil {
+		t.Fatalf("write empty key file: %v", err)
+	}
+	if _, err := loadServerAIAPIKey(empty); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Fatalf("expected empty file error, got %v", err)
+	}
+	if _, err := loadServerAIAPIKey(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected missing file error")
+	}
+}
+
+func TestAnalyzeHoldings_ServerAIAPIKeyFallback(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "ai_key")
+	if err := os.WriteFile(keyPath, []byte("server-key"), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+	core, err := OpenWithOptions(Options{DBPath: filepath.Join(dir, "test.db"), AIAPIKeyFile: keyPath})
+	if err != nil {
+		t.Fatalf("open core: %v", err)
+	}
+	defer core.Close()
+	if !core.HasServerAIAPIKey() {
+		t.Fatal("expected server key to be configured")
+	}
+
+	testAccount(t, core, "acc-key", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-key")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	var usedKey string
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		usedKey = req.APIKey
+		return aiChatCompletionResult{
+			Model:   "mock-model",
+			Content: `{"overall_summary":"ok","risk_level":"balanced","key_findings":[],"recommendations":[],"disclaimer":"仅供参考"}`,
+		}, nil
+	}
+
+	req := HoldingsAnalysisRequest{BaseURL: "https://example.com/v1", Model: "mock-model", Currency: "USD"}
+	if _, err := core.AnalyzeHoldings(req); err != nil {
+		t.Fatalf("AnalyzeHoldings without request key failed: %v", err)
+	}
+	if usedKey != "server-key" {
+		t.Fatalf("expected server key, got %q", usedKey)
+	}
+
+	req.APIKey = "request-key"
+	if _, err := core.AnalyzeHoldings(req); err != nil {
+		t.Fatalf("AnalyzeHoldings with request key failed: %v", err)
+	}
+	if usedKey != "request-key" {
+		t.Fatalf("expected request key to take precedence, got %q", usedKey)
+	}
+}
@@ -1,8 +1,11 @@
 package investlog
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 )
@@ -26,6 +29,21 @@ type aiChatCompletionRequest struct {
 	Logger              *slog.Logger
 	OnDelta             func(string)
 	UseGoogleSearchTool bool
+	// OmitMaxTokens sends only max_completion_tokens for providers that
+	// reject requests carrying the legacy max_tokens field.
+	OmitMaxTokens bool
+	// MaxResponseBytes caps one-shot response bodies. Zero uses maxAIResponseBodySize.
+	MaxResponseBytes int64
+	// HTTPClient sends the request. Nil uses the shared defaultAIHTTPClient.
+	HTTPClient *http.Client
+	// ResponseSchema, when set, asks chat completions and Gemini providers to
+	// constrain output to it. A 400/422 reply retries without the schema.
+	ResponseSchema *aiResponseSchema
+	// Temperature overrides defaultAITemperature; reasoning models never send one.
+	Temperature *float64
+	// ThinkingBudget caps Gemini thinking tokens (-1 lets the model decide, 0
+	// turns thinking off). Models without thinking support ignore it.
+	ThinkingBudget *int
 }
 
 type aiChatCompletionResult struct {
@@ -33,6 +51,41 @@ type aiChatCompletionResult struct {
 	Content string
 }
 
+func (r aiChatCompletionRequest) temperature() float64 {
+	if r.Temperature == nil {
+		return defaultAITemperature
+	}
+	return *r.Temperature
+}
+
+func (r aiChatCompletionRequest) responseLimit() int64 {
+	if r.MaxResponseBytes <= 0 {
+		return maxAIResponseBodySize
+	}
+	return r.MaxResponseBytes
+}
+
+// readAIResponseBody reads at most limit bytes and fails explicitly when the
+// body is longer, instead of handing truncated JSON to the decoder.
+func readAIResponseBody(r io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read ai response: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("ai response truncated at %d bytes; increase limit", limit)
+	}
+	return body, nil
+}
+
+// requestContext returns ctx, or context.Background() when the caller set none.
+func requestContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
 var aiChatCompletion = requestAIChatCompletion
 var aiChatCompletionStream = requestAIChatCompletionStream
 
@@ -48,25 +101,3 @@ func normalizeEnum(raw, fallback string, allowed map[string]struct{}) (string, e
 	}
 	return normalized, nil
 }
-
-// cleanupModelJSON strips markdown code fences and extracts the outermost JSON object
-// from model-generated content that may contain surrounding noise.
-func cleanupModelJSON(content string) string {
-	trimmed := strings.TrimSpace(content)
-	if strings.HasPrefix(trimmed, "```") {
-		lines := strings.Split(trimmed, "\n")
-		if len(lines) >= 2 {
-			lines = lines[1:]
-			if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "```" {
-				lines = lines[:len(lines)-1]
-			}
-			trimmed = strings.Join(lines, "\n")
-		}
-	}
-	start := strings.Index(trimmed, "{")
-	end := strings.LastIndex(trimmed, "}")
-	if start >= 0 && end > start {
-		trimmed = trimmed[start : end+1]
-	}
-	return strings.TrimSpace(trimmed)
-}
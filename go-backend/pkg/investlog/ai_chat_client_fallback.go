@@ -7,20 +7,26 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"strings"
 )
 
 func requestAIByResponsesCandidates(ctx context.Context, req aiChatCompletionRequest, endpoint string) (aiChatCompletionResult, error) {
 	responseCandidates := collectResponsesCandidates(endpoint)
 	errs := make([]string, 0, len(responseCandidates))
+	attempts := make([]error, 0, len(responseCandidates))
 	for _, candidate := range responseCandidates {
 		result, err := requestAIByResponses(ctx, req, candidate)
 		if err == nil {
 			return result, nil
 		}
 		errs = append(errs, fmt.Sprintf("%s -> %v", candidate, err))
+		attempts = append(attempts, err)
+	}
+	return aiChatCompletionResult{}, &aiAttemptsError{
+		message:  fmt.Sprintf("responses attempts failed: %s", strings.Join(errs, " | ")),
+		attempts: attempts,
 	}
-	return aiChatCompletionResult{}, fmt.Errorf("responses attempts failed: %s", strings.Join(errs, " | "))
 }
 
 func collectChatCandidates(endpoint string) []string {
@@ -88,7 +94,6 @@ func toAltResponsesEndpoint(endpoint string) string {
 	return ""
 }
 
-
 func shouldFallbackToResponses(err error) bool {
 	if err == nil {
 		return false
@@ -97,6 +102,21 @@ func shouldFallbackToResponses(err error) bool {
 	return strings.Contains(message, "input is required") || strings.Contains(message, "missing required parameter: input")
 }
 
+// shouldRetryWithoutMaxTokens reports whether the provider rejected the
+// legacy max_tokens field (newer OpenAI models accept only max_completion_tokens).
+func shouldRetryWithoutMaxTokens(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	if !strings.Contains(message, "max_tokens") {
+		return false
+	}
+	return strings.Contains(message, "unsupported") ||
+		strings.Contains(message, "not supported") ||
+		strings.Contains(message, "max_completion_tokens")
+}
+
 func shouldFallbackToAltEndpoint(err error) bool {
 	if err == nil {
 		return false
@@ -167,6 +187,65 @@ func toResponsesEndpoint(endpoint string) string {
 	return ""
 }
 
+// aiStatusError is a non-2xx provider response.
+type aiStatusError struct {
+	Status  int
+	message string
+}
+
+func (e *aiStatusError) Error() string {
+	return e.message
+}
+
+// errAIOverloaded matches, via errors.Is, any 429 or 503 aiStatusError.
+var errAIOverloaded = errors.New("ai upstream overloaded")
+
+func (e *aiStatusError) Is(target error) bool {
+	return target == errAIOverloaded &&
+		(e.Status == http.StatusTooManyRequests || e.Status == http.StatusServiceUnavailable)
+}
+
+// aiAttemptsError is the flattened error of the endpoint fallback chain. It
+// keeps the per-attempt errors so callers can still inspect them.
+type aiAttemptsError struct {
+	message  string
+	attempts []error
+}
+
+func (e *aiAttemptsError) Error() string {
+	return e.message
+}
+
+func (e *aiAttemptsError) Unwrap() []error {
+	return e.attempts
+}
+
+// aiUpstreamError builds the error for a non-2xx provider response. Auth
+// failures get an explicit hint because provider messages for them vary widely.
+func aiUpstreamError(status int, body []byte) error {
+	message := parseAIErrorMessage(body)
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+	}
+	if message == "" {
+		message = fmt.Sprintf("status %d", status)
+	}
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		message = fmt.Sprintf("invalid api_key or insufficient permissions (status %d): %s", status, message)
+	} else if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		message = fmt.Sprintf("%s (status %d)", message, status)
+	}
+	return &aiStatusError{Status: status, message: "ai upstream error: " + message}
+}
+
+// isAIOverloadedError reports whether any attempt behind err got a 429 or 503
+// response, in which case another model on the same endpoint may still
+// succeed. errors.Is walks every attempt of an aiAttemptsError, not only the
+// first status error.
+func isAIOverloadedError(err error) bool {
+	return errors.Is(err, errAIOverloaded)
+}
+
 func parseAIErrorMessage(body []byte) string {
 	var payload struct {
 		Error struct {
@@ -0,0 +1,36 @@
+package investlog
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultAIMaxIdleConnsPerHost = 8
+	aiIdleConnTimeout            = 90 * time.Second
+)
+
+// defaultAIHTTPClient serves AI requests that were not given a client.
+var defaultAIHTTPClient = newAIHTTPClient(defaultAIMaxIdleConnsPerHost)
+
+// newAIHTTPClient returns a client whose transport keeps enough idle
+// connections per host for the concurrent dimension agents to reuse them
+// instead of repeating TLS handshakes against the same provider.
+func newAIHTTPClient(maxIdleConnsPerHost int) *http.Client {
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = defaultAIMaxIdleConnsPerHost
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.MaxIdleConns = max(transport.MaxIdleConns, maxIdleConnsPerHost)
+	transport.IdleConnTimeout = aiIdleConnTimeout
+	transport.DisableKeepAlives = false
+	return &http.Client{Transport: transport, Timeout: aiRequestTimeout}
+}
+
+func (r aiChatCompletionRequest) httpClient() *http.Client {
+	if r.HTTPClient == nil {
+		return defaultAIHTTPClient
+	}
+	return r.HTTPClient
+}
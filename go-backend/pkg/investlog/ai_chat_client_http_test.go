@@ -0,0 +1,83 @@
+package investlog
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAIHTTPClientReusesConnectionsAcrossConcurrentAgents(t *testing.T) {
+	const agents = 4
+
+	var newConns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := newAIHTTPClient(defaultAIMaxIdleConnsPerHost)
+	defer client.CloseIdleConnections()
+	endpoint := server.URL + "/v1/chat/completions"
+
+	runRound := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < agents; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := requestAIByChatCompletions(context.Background(), aiChatCompletionRequest{
+					EndpointURL:  endpoint,
+					APIKey:       "key",
+					Model:        "m",
+					SystemPrompt: "sys",
+					UserPrompt:   "user",
+					HTTPClient:   client,
+				}, endpoint)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	runRound()
+	firstRound := newConns.Load()
+	if firstRound == 0 || firstRound > agents {
+		t.Fatalf("expected 1-%d connections in first round, got %d", agents, firstRound)
+	}
+	runRound()
+	if got := newConns.Load(); got != firstRound {
+		t.Fatalf("expected second round to reuse %d connections, opened %d in total", firstRound, got)
+	}
+}
+
+func TestNewAIHTTPClientDefaults(t *testing.T) {
+	client := newAIHTTPClient(0)
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != defaultAIMaxIdleConnsPerHost {
+		t.Fatalf("expected %d idle conns per host, got %d", defaultAIMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	if transport.DisableKeepAlives {
+		t.Fatal("expected keep-alives enabled")
+	}
+	if client.Timeout != aiRequestTimeout {
+		t.Fatalf("expected per-request timeout %v, got %v", aiRequestTimeout, client.Timeout)
+	}
+}
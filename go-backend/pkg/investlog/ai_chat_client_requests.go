@@ -70,6 +70,7 @@ func requestAIChatCompletion(ctx context.Context, req aiChatCompletionRequest) (
 	chatCandidates := collectChatCandidates(endpoint)
 	chatErrors := make([]string, 0, len(chatCandidates))
 	sameEndpointErrors := []string{}
+	var attempts []error
 	allowResponsesFallback := false
 
 	for _, candidate := range chatCandidates {
@@ -81,6 +82,7 @@ func requestAIChatCompletion(ctx context.Context, req aiChatCompletionRequest) (
 		}
 		logger.Warn("ai analyze: chat endpoint failed", "endpoint", candidate, "err", err)
 		chatErrors = append(chatErrors, fmt.Sprintf("%s -> %v", candidate, err))
+		attempts = append(attempts, err)
 		if shouldFallbackToResponses(err) || shouldFallbackToAltEndpoint(err) {
 			allowResponsesFallback = true
 		}
@@ -94,6 +96,7 @@ func requestAIChatCompletion(ctx context.Context, req aiChatCompletionRequest) (
 			}
 			logger.Warn("ai analyze: same endpoint with responses payload failed", "endpoint", candidate, "err", sameErr)
 			sameEndpointErrors = append(sameEndpointErrors, fmt.Sprintf("%s -> %v", candidate, sameErr))
+			attempts = append(attempts, sameErr)
 
 			logger.Info("ai analyze: try hybrid payload on same endpoint", "endpoint", candidate)
 			hybridResult, hybridErr := requestAIByHybridPayload(ctx, req, candidate)
@@ -103,9 +106,13 @@ func requestAIChatCompletion(ctx context.Context, req aiChatCompletionRequest) (
 			}
 			logger.Warn("ai analyze: same endpoint with hybrid payload failed", "endpoint", candidate, "err", hybridErr)
 			sameEndpointErrors = append(sameEndpointErrors, fmt.Sprintf("%s(hybrid) -> %v", candidate, hybridErr))
+			attempts = append(attempts, hybridErr)
 
 			if isTimeoutError(sameErr) || isTimeoutError(hybridErr) {
-				return aiChatCompletionResult{}, fmt.Errorf("ai upstream timeout on %s; try a faster model or retry later", candidate)
+				return aiChatCompletionResult{}, &aiAttemptsError{
+					message:  fmt.Sprintf("ai upstream timeout on %s; try a faster model or retry later", candidate),
+					attempts: attempts,
+				}
 			}
 		}
 	}
@@ -116,10 +123,11 @@ func requestAIChatCompletion(ctx context.Context, req aiChatCompletionRequest) (
 	}
 
 	if !allowResponsesFallback {
+		message := fmt.Sprintf("chat completion failed: %s", strings.Join(chatErrors, " | "))
 		if len(sameEndpointErrors) > 0 {
-			return aiChatCompletionResult{}, fmt.Errorf("chat completion failed: %s; same-endpoint responses attempts failed: %s", strings.Join(chatErrors, " | "), strings.Join(sameEndpointErrors, " | "))
+			message += fmt.Sprintf("; same-endpoint responses attempts failed: %s", strings.Join(sameEndpointErrors, " | "))
 		}
-		return aiChatCompletionResult{}, fmt.Errorf("chat completion failed: %s", strings.Join(chatErrors, " | "))
+		return aiChatCompletionResult{}, &aiAttemptsError{message: message, attempts: attempts}
 	}
 
 	responsesResult, err := requestAIByResponsesCandidates(ctx, req, endpoint)
@@ -129,9 +137,13 @@ func requestAIChatCompletion(ctx context.Context, req aiChatCompletionRequest) (
 	}
 	logger.Error("ai analyze: responses fallback failed", "err", err)
 
+	message := fmt.Sprintf("chat completion failed (%s)", strings.Join(chatErrors, " | "))
 	if len(sameEndpointErrors) > 0 {
-		return aiChatCompletionResult{}, fmt.Errorf("chat completion failed (%s); same-endpoint responses attempts failed (%s); responses fallback failed: %w", strings.Join(chatErrors, " | "), strings.Join(sameEndpointErrors, " | "), err)
+		message += fmt.Sprintf("; same-endpoint responses attempts failed (%s)", strings.Join(sameEndpointErrors, " | "))
+	}
+	return aiChatCompletionResult{}, &aiAttemptsError{
+		message:  fmt.Sprintf("%s; responses fallback failed: %v", message, err),
+		attempts: append(attempts, err),
 	}
-	return aiChatCompletionResult{}, fmt.Errorf("chat completion failed (%s); responses fallback failed: %w", strings.Join(chatErrors, " | "), err)
 }
 
@@ -9,6 +9,11 @@ import (
 	"strings"
 )
 
+// geminiCumulativeMinPrefix is the minimum accumulated length before a Gemini
+// chunk that repeats the accumulated text is treated as cumulative. Short
+// prefixes are too likely to repeat by chance in incremental output.
+const geminiCumulativeMinPrefix = 32
+
 type sseChunk struct {
 	Model   string `json:"model"`
 	Choices []struct {
@@ -26,11 +31,34 @@ type geminiSSEChunk struct {
 				Text string `json:"text"`
 			} `json:"parts"`
 		} `json:"content"`
+		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// anthropicSSEEvent is one event of the Anthropic Messages streaming API.
+// Only text deltas carry content; thinking, signature and input_json deltas
+// and tool blocks are skipped.
+type anthropicSSEEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string `json:"model"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
 }
 
-// parseSSEStream reads an SSE stream in OpenAI/Gemini-compatible formats and
-// calls onChunk for each content delta. It returns the accumulated content and
+// parseSSEStream reads an SSE stream in OpenAI/Anthropic/Gemini-compatible
+// formats and calls onChunk for each content delta. It returns the accumulated content and
 // the last seen model identifier.
 func parseSSEStream(body io.Reader, onChunk func(model, delta string) error) (string, string, error) {
 	scanner := bufio.NewScanner(body)
@@ -63,7 +91,26 @@ func parseSSEStream(body io.Reader, onChunk func(model, delta string) error) (st
 
 		chunkModel, delta, handled := extractOpenAIStyleSSEChunk(data)
 		if !handled {
-			chunkModel, delta, handled = extractGeminiStyleSSEChunk(data)
+			if event, ok := parseAnthropicSSEEvent(data); ok {
+				if event.Type == "error" {
+					return builder.String(), model, fmt.Errorf("ai sse error: %s: %s", event.Error.Type, event.Error.Message)
+				}
+				if event.Type == "message_stop" {
+					break
+				}
+				if event.Type == "message_delta" && event.Delta.StopReason == "max_tokens" {
+					slog.Default().Warn("ai sse: response truncated", "stop_reason", event.Delta.StopReason)
+				}
+				chunkModel, delta, handled = strings.TrimSpace(event.Message.Model), event.textDelta(), true
+			}
+		}
+		if !handled {
+			var blockReason string
+			chunkModel, delta, blockReason, handled = extractGeminiStyleSSEChunk(data)
+			if blockReason != "" {
+				return builder.String(), model, geminiBlockedError(blockReason)
+			}
+			delta = geminiStreamDelta(builder.String(), delta)
 		}
 		if !handled {
 			slog.Default().Warn("ai sse: failed to parse chunk", "data", data)
@@ -102,15 +149,41 @@ func extractOpenAIStyleSSEChunk(data string) (string, string, bool) {
 	return model, chunk.Choices[0].Delta.Content, true
 }
 
-func extractGeminiStyleSSEChunk(data string) (string, string, bool) {
+// parseAnthropicSSEEvent decodes data as an Anthropic event. Any payload with
+// a type is accepted so newer event types are ignored rather than logged.
+func parseAnthropicSSEEvent(data string) (anthropicSSEEvent, bool) {
+	var event anthropicSSEEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil || event.Type == "" {
+		return anthropicSSEEvent{}, false
+	}
+	return event, true
+}
+
+func (e anthropicSSEEvent) textDelta() string {
+	if e.Type != "content_block_delta" || e.Delta.Type != "text_delta" {
+		return ""
+	}
+	return e.Delta.Text
+}
+
+// extractGeminiStyleSSEChunk returns the chunk's model, text and, when Gemini
+// withheld the output, the block reason.
+func extractGeminiStyleSSEChunk(data string) (string, string, string, bool) {
 	var chunk geminiSSEChunk
 	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-		return "", "", false
+		return "", "", "", false
 	}
 
 	model := strings.TrimSpace(chunk.ModelVersion)
+	finishReasons := make([]string, 0, len(chunk.Candidates))
+	for _, candidate := range chunk.Candidates {
+		finishReasons = append(finishReasons, candidate.FinishReason)
+	}
+	if reason := geminiBlockReason(chunk.PromptFeedback.BlockReason, finishReasons); reason != "" {
+		return model, "", reason, true
+	}
 	if len(chunk.Candidates) == 0 {
-		return model, "", model != ""
+		return model, "", "", model != ""
 	}
 
 	var builder strings.Builder
@@ -123,6 +196,16 @@ func extractGeminiStyleSSEChunk(data string) (string, string, bool) {
 		}
 	}
 
-	return model, builder.String(), true
+	return model, builder.String(), "", true
 }
 
+// geminiStreamDelta returns the new text carried by a Gemini chunk. Gemini
+// streams incremental deltas, so chunks are normally appended as-is; some
+// proxies resend the full text so far instead, in which case only the
+// unseen suffix is returned.
+func geminiStreamDelta(accumulated, chunk string) string {
+	if len(accumulated) < geminiCumulativeMinPrefix || !strings.HasPrefix(chunk, accumulated) {
+		return chunk
+	}
+	return chunk[len(accumulated):]
+}
@@ -0,0 +1,144 @@
+package investlog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func geminiSSELine(text string) string {
+	return fmt.Sprintf("data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":%q}]}}],\"modelVersion\":\"gemini-2.5-flash\"}\n\n", text)
+}
+
+func TestParseSSEStream_GeminiIncrementalChunks(t *testing.T) {
+	chunks := []string{
+		"{\"overall_summary\":\"组合集中度较高，",
+		"{\"overall_summary\":\"组合",
+		"建议分散\"}",
+	}
+	var body strings.Builder
+	for _, chunk := range chunks {
+		body.WriteString(geminiSSELine(chunk))
+	}
+
+	var deltas []string
+	content, model, err := parseSSEStream(strings.NewReader(body.String()), func(_, delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model != "gemini-2.5-flash" {
+		t.Fatalf("unexpected model: %q", model)
+	}
+	if content != strings.Join(chunks, "") {
+		t.Fatalf("expected concatenated content, got %q", content)
+	}
+	if len(deltas) != len(chunks) {
+		t.Fatalf("expected %d deltas, got %d: %q", len(chunks), len(deltas), deltas)
+	}
+	for i := range chunks {
+		if deltas[i] != chunks[i] {
+			t.Fatalf("delta %d: expected %q, got %q", i, chunks[i], deltas[i])
+		}
+	}
+}
+
+func TestParseSSEStream_GeminiCumulativeChunks(t *testing.T) {
+	full := "{\"overall_summary\":\"组合集中度较高，建议分散到宽基指数\",\"risk_level\":\"balanced\"}"
+	first := "{\"overall_summary\":\"组合集中度较高，"
+	body := geminiSSELine(first) + geminiSSELine(full) + geminiSSELine(full)
+
+	var deltas []string
+	content, _, err := parseSSEStream(strings.NewReader(body), func(_, delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != full {
+		t.Fatalf("expected deduplicated content %q, got %q", full, content)
+	}
+	if len(deltas) != 2 || deltas[0] != first || deltas[1] != full[len(first):] {
+		t.Fatalf("unexpected deltas: %q", deltas)
+	}
+}
+
+func TestGeminiStreamDelta(t *testing.T) {
+	long := strings.Repeat("a", geminiCumulativeMinPrefix)
+	cases := []struct {
+		name        string
+		accumulated string
+		chunk       string
+		want        string
+	}{
+		{"first chunk", "", "hello", "hello"},
+		{"incremental", long, "bcd", "bcd"},
+		{"short prefix repeated by chance", "好", "好的", "好的"},
+		{"cumulative", long, long + "tail", "tail"},
+		{"duplicate cumulative", long, long, ""},
+	}
+	for _, tc := range cases {
+		if got := geminiStreamDelta(tc.accumulated, tc.chunk); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestParseSSEStream_AnthropicSkipsThinkingAndToolBlocks(t *testing.T) {
+	body := strings.Join([]string{
+		"event: message_start",
+		`data: {"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4-5","content":[]}}`,
+		"event: content_block_start",
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+		"event: content_block_delta",
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"considering the portfolio"}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"abc"}}`,
+		`data: {"type":"content_block_stop","index":0}`,
+		`data: {"type":"ping"}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"{\"overall_summary\":"}}`,
+		`data: {"type":"content_block_stop","index":1}`,
+		`data: {"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"t1","name":"lookup","input":{}}}`,
+		`data: {"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"q\":"}}`,
+		`data: {"type":"content_block_stop","index":2}`,
+		`data: {"type":"content_block_delta","index":3,"delta":{"type":"text_delta","text":"\"ok\"}"}}`,
+		`data: {"type":"some_future_event","detail":{}}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":12}}`,
+		`data: {"type":"message_stop"}`,
+		`data: {"type":"content_block_delta","index":4,"delta":{"type":"text_delta","text":"after stop"}}`,
+	}, "\n\n")
+
+	var deltas []string
+	content, model, err := parseSSEStream(strings.NewReader(body), func(_, delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model != "claude-sonnet-4-5" {
+		t.Fatalf("unexpected model: %q", model)
+	}
+	if content != `{"overall_summary":"ok"}` {
+		t.Fatalf("expected only text deltas, got %q", content)
+	}
+	if len(deltas) != 2 {
+		t.Fatalf("expected 2 deltas, got %d: %q", len(deltas), deltas)
+	}
+}
+
+func TestParseSSEStream_AnthropicErrorEvent(t *testing.T) {
+	body := `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"partial"}}` + "\n\n" +
+		`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}` + "\n\n"
+
+	content, _, err := parseSSEStream(strings.NewReader(body), func(_, _ string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error") {
+		t.Fatalf("expected overloaded error, got %v", err)
+	}
+	if content != "partial" {
+		t.Fatalf("expected partial content, got %q", content)
+	}
+}
@@ -20,19 +20,7 @@ func requestAIByChatCompletions(ctx context.Context, req aiChatCompletionRequest
 		logger = slog.Default()
 	}
 
-	// Build streaming request payload.
-	payload := map[string]any{
-		"model": req.Model,
-		"messages": []map[string]string{
-			{"role": "system", "content": req.SystemPrompt},
-			{"role": "user", "content": req.UserPrompt},
-		},
-		"temperature":           0.2,
-		"stream":                true,
-		"max_completion_tokens": aiMaxOutputTokens,
-		"max_tokens":            aiMaxOutputTokens,
-	}
-	addAIRequestTools(payload, req)
+	payload := buildChatCompletionsPayload(req)
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return aiChatCompletionResult{}, fmt.Errorf("marshal ai request: %w", err)
@@ -49,8 +37,7 @@ func requestAIByChatCompletions(ctx context.Context, req aiChatCompletionRequest
 	setAIAuthHeader(httpReq, endpoint, req.Model, req.APIKey)
 	logAIRequestJSON(logger, httpReq, body)
 
-	client := &http.Client{Timeout: aiRequestTimeout}
-	resp, err := client.Do(httpReq)
+	resp, err := req.httpClient().Do(httpReq)
 	if err != nil {
 		return aiChatCompletionResult{}, fmt.Errorf("ai request failed: %w", err)
 	}
@@ -58,16 +45,20 @@ func requestAIByChatCompletions(ctx context.Context, req aiChatCompletionRequest
 
 	// Non-2xx: extract error message.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxAIResponseBodySize))
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, req.responseLimit()))
 		logAIRawResponseDebug(logger, endpoint, resp.StatusCode, respBody)
-		message := parseAIErrorMessage(respBody)
-		if message == "" {
-			message = strings.TrimSpace(string(respBody))
+		upstreamErr := aiUpstreamError(resp.StatusCode, respBody)
+		if !req.OmitMaxTokens && resp.StatusCode == http.StatusBadRequest && shouldRetryWithoutMaxTokens(upstreamErr) {
+			logger.Warn("ai analyze: provider rejected max_tokens, retry with max_completion_tokens only", "endpoint", endpoint)
+			req.OmitMaxTokens = true
+			return requestAIByChatCompletions(ctx, req, endpoint)
 		}
-		if message == "" {
-			message = fmt.Sprintf("status %d", resp.StatusCode)
+		if req.ResponseSchema != nil && shouldRetryWithoutResponseSchema(resp.StatusCode) {
+			logger.Warn("ai analyze: provider rejected response schema, retry with prompt-only json", "endpoint", endpoint, "err", upstreamErr)
+			req.ResponseSchema = nil
+			return requestAIByChatCompletions(ctx, req, endpoint)
 		}
-		return aiChatCompletionResult{}, fmt.Errorf("ai upstream error: %s", message)
+		return aiChatCompletionResult{}, upstreamErr
 	}
 
 	contentType := resp.Header.Get("Content-Type")
@@ -104,9 +95,9 @@ func requestAIByChatCompletions(ctx context.Context, req aiChatCompletionRequest
 		}
 	} else if strings.Contains(contentType, "application/json") {
 		// Non-streaming JSON response (some providers ignore stream:true).
-		respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxAIResponseBodySize))
+		respBody, err := readAIResponseBody(resp.Body, req.responseLimit())
 		if err != nil {
-			return aiChatCompletionResult{}, fmt.Errorf("read ai response: %w", err)
+			return aiChatCompletionResult{}, err
 		}
 		logAIRawResponseDebug(logger, endpoint, resp.StatusCode, respBody)
 		model, content, err := decodeAIModelAndContent(respBody)
@@ -127,7 +118,47 @@ func requestAIByChatCompletions(ctx context.Context, req aiChatCompletionRequest
 	return requestAIByPayload(ctx, req, endpoint, payload)
 }
 
+// buildChatCompletionsPayload builds the streaming chat completions body.
+// Reasoning models get a developer message, no temperature and only
+// max_completion_tokens.
+func buildChatCompletionsPayload(req aiChatCompletionRequest) map[string]any {
+	reasoning := isReasoningModel(req.Model)
+	instructionRole := "system"
+	if reasoning {
+		instructionRole = "developer"
+	}
+	payload := map[string]any{
+		"model": req.Model,
+		"messages": []map[string]string{
+			{"role": instructionRole, "content": req.SystemPrompt},
+			{"role": "user", "content": req.UserPrompt},
+		},
+		"stream":                true,
+		"max_completion_tokens": aiMaxOutputTokens,
+	}
+	if !reasoning {
+		payload["temperature"] = req.temperature()
+		if !req.OmitMaxTokens {
+			payload["max_tokens"] = aiMaxOutputTokens
+		}
+	}
+	if req.ResponseSchema != nil {
+		payload["response_format"] = req.ResponseSchema.chatResponseFormat()
+	}
+	addAIRequestTools(payload, req)
+	return payload
+}
+
 func buildGeminiStreamPayload(req aiChatCompletionRequest) map[string]any {
+	generationConfig := map[string]any{
+		"temperature":     req.temperature(),
+		"maxOutputTokens": geminiMaxOutputTokens,
+	}
+	if req.ThinkingBudget != nil && geminiSupportsThinking(req.Model) {
+		generationConfig["thinkingConfig"] = map[string]any{
+			"thinkingBudget": *req.ThinkingBudget,
+		}
+	}
 	payload := map[string]any{
 		"contents": []map[string]any{
 			{
@@ -136,10 +167,7 @@ func buildGeminiStreamPayload(req aiChatCompletionRequest) map[string]any {
 				},
 			},
 		},
-		"generationConfig": map[string]any{
-			"temperature":     0.2,
-			"maxOutputTokens": geminiMaxOutputTokens,
-		},
+		"generationConfig": generationConfig,
 	}
 
 	if strings.TrimSpace(req.SystemPrompt) != "" {
@@ -149,7 +177,14 @@ func buildGeminiStreamPayload(req aiChatCompletionRequest) map[string]any {
 			},
 		}
 	}
-	addAIRequestTools(payload, req)
+	if req.ResponseSchema != nil {
+		// Gemini rejects tools combined with a JSON response MIME type, so
+		// constrained output goes without search grounding.
+		generationConfig["responseMimeType"] = "application/json"
+		generationConfig["responseSchema"] = req.ResponseSchema.geminiSchema()
+	} else {
+		addAIRequestTools(payload, req)
+	}
 
 	return payload
 }
@@ -180,24 +215,21 @@ func requestAIByGeminiStream(ctx context.Context, req aiChatCompletionRequest, e
 	setAIAuthHeader(httpReq, endpoint, req.Model, req.APIKey)
 	logAIRequestJSON(logger, httpReq, body)
 
-	client := &http.Client{Timeout: aiRequestTimeout}
-	resp, err := client.Do(httpReq)
+	resp, err := req.httpClient().Do(httpReq)
 	if err != nil {
 		return aiChatCompletionResult{}, fmt.Errorf("ai request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxAIResponseBodySize))
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, req.responseLimit()))
 		logAIRawResponseDebug(logger, endpoint, resp.StatusCode, respBody)
-		message := parseAIErrorMessage(respBody)
-		if message == "" {
-			message = strings.TrimSpace(string(respBody))
+		if req.ResponseSchema != nil && shouldRetryWithoutResponseSchema(resp.StatusCode) {
+			logger.Warn("ai analyze: gemini rejected response schema, retry with prompt-only json", "endpoint", endpoint)
+			req.ResponseSchema = nil
+			return requestAIByGeminiStream(ctx, req, endpoint)
 		}
-		if message == "" {
-			message = fmt.Sprintf("status %d", resp.StatusCode)
-		}
-		return aiChatCompletionResult{}, fmt.Errorf("ai upstream error: %s", message)
+		return aiChatCompletionResult{}, aiUpstreamError(resp.StatusCode, respBody)
 	}
 
 	contentType := resp.Header.Get("Content-Type")
@@ -231,11 +263,14 @@ func requestAIByGeminiStream(ctx context.Context, req aiChatCompletionRequest, e
 		return aiChatCompletionResult{Model: model, Content: content}, nil
 	}
 
-	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxAIResponseBodySize))
+	respBody, err := readAIResponseBody(resp.Body, req.responseLimit())
 	if err != nil {
-		return aiChatCompletionResult{}, fmt.Errorf("read ai response: %w", err)
+		return aiChatCompletionResult{}, err
 	}
 	logAIRawResponseDebug(logger, endpoint, resp.StatusCode, respBody)
+	if reason := geminiResponseBlockReason(respBody); reason != "" {
+		return aiChatCompletionResult{}, geminiBlockedError(reason)
+	}
 
 	decodedModel, content, err := decodeAIModelAndContent(respBody)
 	if err != nil {
@@ -260,7 +295,7 @@ func requestAIByResponses(ctx context.Context, req aiChatCompletionRequest, endp
 		"model":             req.Model,
 		"instructions":      req.SystemPrompt,
 		"input":             req.UserPrompt,
-		"temperature":       0.2,
+		"temperature":       req.temperature(),
 		"stream":            false,
 		"max_output_tokens": aiMaxOutputTokens,
 		"messages": []map[string]string{
@@ -281,12 +316,14 @@ func requestAIByHybridPayload(ctx context.Context, req aiChatCompletionRequest,
 		},
 		"input":                 req.UserPrompt,
 		"instructions":          req.SystemPrompt,
-		"temperature":           0.2,
+		"temperature":           req.temperature(),
 		"stream":                false,
-		"max_tokens":            aiMaxOutputTokens,
 		"max_completion_tokens": aiMaxOutputTokens,
 		"max_output_tokens":     aiMaxOutputTokens,
 	}
+	if !req.OmitMaxTokens {
+		payload["max_tokens"] = aiMaxOutputTokens
+	}
 	addAIRequestTools(payload, req)
 	return requestAIByPayload(ctx, req, endpoint, payload)
 }
@@ -318,8 +355,12 @@ func requestAIByPayload(ctx context.Context, req aiChatCompletionRequest, endpoi
 	setAIAuthHeader(httpReq, endpoint, req.Model, req.APIKey)
 	logAIRequestJSON(req.Logger, httpReq, body)
 
-	respBody, err := executeAIRequest(httpReq, req.Logger)
+	respBody, err := executeAIRequest(req.httpClient(), httpReq, req.Logger, req.responseLimit())
 	if err != nil {
+		if _, ok := payload["max_tokens"]; ok && shouldRetryWithoutMaxTokens(err) {
+			delete(payload, "max_tokens")
+			return requestAIByPayload(ctx, req, endpoint, payload)
+		}
 		return aiChatCompletionResult{}, err
 	}
 
@@ -333,30 +374,22 @@ func requestAIByPayload(ctx context.Context, req aiChatCompletionRequest, endpoi
 	return aiChatCompletionResult{Model: model, Content: content}, nil
 }
 
-func executeAIRequest(httpReq *http.Request, logger *slog.Logger) ([]byte, error) {
-	client := &http.Client{Timeout: aiRequestTimeout}
+func executeAIRequest(client *http.Client, httpReq *http.Request, logger *slog.Logger, limit int64) ([]byte, error) {
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("ai request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxAIResponseBodySize))
+	respBody, err := readAIResponseBody(resp.Body, limit)
 	if err != nil {
-		return nil, fmt.Errorf("read ai response: %w", err)
+		return nil, err
 	}
 
 	logAIRawResponseDebug(logger, httpReq.URL.String(), resp.StatusCode, respBody)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		message := parseAIErrorMessage(respBody)
-		if message == "" {
-			message = strings.TrimSpace(string(respBody))
-		}
-		if message == "" {
-			message = fmt.Sprintf("status %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("ai upstream error: %s", message)
+		return nil, aiUpstreamError(resp.StatusCode, respBody)
 	}
 
 	return respBody, nil
@@ -0,0 +1,147 @@
+package investlog
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultAIMaxConcurrent = 4
+	defaultAIQueueTimeout  = 30 * time.Second
+)
+
+// aiCallLimiter is a semaphore bounding concurrent upstream AI calls across
+// holdings, symbol and allocation analyses. Each call holds one slot; calls
+// that run together, such as the framework agents of one symbol analysis,
+// reserve their slots up front with acquireAISlots.
+type aiCallLimiter struct {
+	slots   chan struct{}
+	timeout time.Duration
+	// reserving lets one multi-slot reservation fill at a time, so two of
+	// them cannot each hold part of the slots and wait on the other.
+	reserving chan struct{}
+}
+
+// aiSlotsHeldKey marks a context whose calls run on slots reserved by
+// acquireAISlots.
+type aiSlotsHeldKey struct{}
+
+// newAICallLimiter returns nil (no limit) when maxConcurrent is negative.
+func newAICallLimiter(maxConcurrent int, timeout time.Duration) *aiCallLimiter {
+	if maxConcurrent < 0 {
+		return nil
+	}
+	return &aiCallLimiter{
+		slots:     make(chan struct{}, defaultInt(maxConcurrent, defaultAIMaxConcurrent)),
+		timeout:   defaultDuration(timeout, defaultAIQueueTimeout),
+		reserving: make(chan struct{}, 1),
+	}
+}
+
+// acquireAISlot waits for a free upstream AI call slot and returns the
+// function that frees it. Waiting longer than the queue timeout fails with
+// ErrCodeBusy so callers can ask the client to retry.
+func (c *Core) acquireAISlot(ctx context.Context) (func(), error) {
+	if c == nil || c.aiLimiter == nil || ctx.Value(aiSlotsHeldKey{}) != nil {
+		return func() {}, nil
+	}
+	limiter := c.aiLimiter
+	select {
+	case limiter.slots <- struct{}{}:
+		return func() { <-limiter.slots }, nil
+	default:
+	}
+
+	timer := time.NewTimer(limiter.timeout)
+	defer timer.Stop()
+	select {
+	case limiter.slots <- struct{}{}:
+		return func() { <-limiter.slots }, nil
+	case <-timer.C:
+		return nil, errAIBusy()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// acquireAISlots reserves n slots at once, capped at the limiter size, for
+// calls that are only useful together. Calls made with the returned context
+// run on the reservation instead of taking slots of their own. It fails with
+// ErrCodeBusy, holding nothing, when the slots do not free up within the
+// queue timeout.
+func (c *Core) acquireAISlots(ctx context.Context, n int) (context.Context, func(), error) {
+	if c == nil || c.aiLimiter == nil || ctx.Value(aiSlotsHeldKey{}) != nil {
+		return ctx, func() {}, nil
+	}
+	limiter := c.aiLimiter
+	n = min(n, cap(limiter.slots))
+
+	timer := time.NewTimer(limiter.timeout)
+	defer timer.Stop()
+	select {
+	case limiter.reserving <- struct{}{}:
+	case <-timer.C:
+		return nil, nil, errAIBusy()
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	defer func() { <-limiter.reserving }()
+
+	held := 0
+	release := func() {
+		for ; held > 0; held-- {
+			<-limiter.slots
+		}
+	}
+	for held < n {
+		select {
+		case limiter.slots <- struct{}{}:
+			held++
+		case <-timer.C:
+			release()
+			return nil, nil, errAIBusy()
+		case <-ctx.Done():
+			release()
+			return nil, nil, ctx.Err()
+		}
+	}
+	return context.WithValue(ctx, aiSlotsHeldKey{}, true), release, nil
+}
+
+func errAIBusy() error {
+	return NewError(ErrCodeBusy, "server busy: too many AI analyses in progress, please retry later")
+}
+
+// chatCompletion runs aiChatCompletion with the Core's AI client settings while
+// holding an AI call slot.
+func (c *Core) chatCompletion(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+	release, err := c.acquireAISlot(ctx)
+	if err != nil {
+		return aiChatCompletionResult{}, err
+	}
+	defer release()
+	return aiChatCompletion(ctx, c.withAIClientSettings(req))
+}
+
+// chatCompletionStream runs aiChatCompletionStream with the Core's AI client
+// settings while holding an AI call slot.
+func (c *Core) chatCompletionStream(ctx context.Context, req aiChatCompletionRequest, onDelta func(string) error) (aiChatCompletionResult, error) {
+	release, err := c.acquireAISlot(ctx)
+	if err != nil {
+		return aiChatCompletionResult{}, err
+	}
+	defer release()
+	return aiChatCompletionStream(ctx, c.withAIClientSettings(req), onDelta)
+}
+
+// withAIClientSettings fills the response size cap and HTTP client of req
+// from Options, so every AI call honors them.
+func (c *Core) withAIClientSettings(req aiChatCompletionRequest) aiChatCompletionRequest {
+	if req.MaxResponseBytes == 0 {
+		req.MaxResponseBytes = c.aiMaxResponseBytes
+	}
+	if req.HTTPClient == nil {
+		req.HTTPClient = c.aiHTTPClient
+	}
+	return req
+}
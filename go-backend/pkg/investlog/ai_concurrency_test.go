@@ -0,0 +1,166 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChatCompletion_SerializesBeyondLimit(t *testing.T) {
+	core := &Core{aiLimiter: newAICallLimiter(1, time.Second)}
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	var inFlight, maxInFlight int32
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return aiChatCompletionResult{Content: "ok"}, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := core.chatCompletion(context.Background(), aiChatCompletionRequest{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("chatCompletion failed: %v", err)
+		}
+	}
+	if maxInFlight != 1 {
+		t.Fatalf("expected calls to run one at a time, saw %d in flight", maxInFlight)
+	}
+}
+
+func TestAcquireAISlot_BusyAfterQueueTimeout(t *testing.T) {
+	core := &Core{aiLimiter: newAICallLimiter(1, 10*time.Millisecond)}
+
+	release, err := core.acquireAISlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquire first slot: %v", err)
+	}
+	_, err = core.acquireAISlot(context.Background())
+	if e, ok := err.(*Error); !ok || e.Code != ErrCodeBusy {
+		t.Fatalf("expected busy error, got %v", err)
+	}
+
+	release()
+	release, err = core.acquireAISlot(context.Background())
+	if err != nil {
+		t.Fatalf("expected slot after release, got %v", err)
+	}
+	release()
+
+	if newAICallLimiter(-1, 0) != nil {
+		t.Fatal("expected negative limit to disable the limiter")
+	}
+}
+
+func TestAcquireAISlots_ReservesAllOrNothing(t *testing.T) {
+	core := &Core{aiLimiter: newAICallLimiter(4, 20*time.Millisecond)}
+
+	ctx, release, err := core.acquireAISlots(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("reserve slots: %v", err)
+	}
+	// Calls on the reserved context do not take slots of their own.
+	held, err := core.acquireAISlot(ctx)
+	if err != nil {
+		t.Fatalf("acquire on reserved context: %v", err)
+	}
+	held()
+	single, err := core.acquireAISlot(context.Background())
+	if err != nil {
+		t.Fatalf("expected the fourth slot to be free: %v", err)
+	}
+
+	_, _, err = core.acquireAISlots(context.Background(), 3)
+	if e, ok := err.(*Error); !ok || e.Code != ErrCodeBusy {
+		t.Fatalf("expected busy error, got %v", err)
+	}
+	single()
+	release()
+	if got := len(core.aiLimiter.slots); got != 0 {
+		t.Fatalf("expected every slot released, %d still held", got)
+	}
+
+	// A reservation larger than the limiter takes all of it.
+	_, release, err = core.acquireAISlots(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("reserve beyond limit: %v", err)
+	}
+	if got := len(core.aiLimiter.slots); got != 4 {
+		t.Fatalf("expected all 4 slots reserved, got %d", got)
+	}
+	release()
+}
+
+func TestRunDimensionAgents_KeepsBusyError(t *testing.T) {
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	frameworks := symbolFrameworkCatalog[:3]
+	failing := buildFrameworkSystemPrompt(frameworks[1])
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if req.SystemPrompt == failing {
+			return aiChatCompletionResult{}, errAIBusy()
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	_, err := (&Core{}).runDimensionAgents(context.Background(), "https://example.com/v1/chat/completions", "key", "model",
+		frameworks, "prompt", nil, nil)
+	var coded *Error
+	if !errors.As(err, &coded) || coded.Code != ErrCodeBusy {
+		t.Fatalf("expected busy error to survive, got %v", err)
+	}
+}
+
+func TestChatCompletion_AppliesAIClientSettings(t *testing.T) {
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+
+	var captured []aiChatCompletionRequest
+	aiChatCompletion = func(_ context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		captured = append(captured, req)
+		return aiChatCompletionResult{Content: "【最新动态】\n- ok"}, nil
+	}
+
+	client := newAIHTTPClient(0)
+	core := &Core{aiMaxResponseBytes: 1234, aiHTTPClient: client}
+	core.summarizeExternalData(context.Background(), &symbolExternalData{
+		Symbol:      "AAPL",
+		RawSections: []ExternalDataSection{{Source: "Yahoo", Type: "news", Content: "update"}},
+	}, "http://example.com", "k", "m", nil)
+	_, err := core.chatCompletion(context.Background(), aiChatCompletionRequest{MaxResponseBytes: 99})
+	if err != nil {
+		t.Fatalf("chat completion: %v", err)
+	}
+
+	if len(captured) != 2 {
+		t.Fatalf("expected 2 AI calls, got %d", len(captured))
+	}
+	if captured[0].MaxResponseBytes != 1234 || captured[0].HTTPClient != client {
+		t.Fatalf("expected the summarizer to use the configured client settings, got %+v", captured[0])
+	}
+	if captured[1].MaxResponseBytes != 99 {
+		t.Fatalf("expected an explicit cap to be kept, got %d", captured[1].MaxResponseBytes)
+	}
+}
@@ -0,0 +1,62 @@
+package investlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errGeminiBlocked marks a Gemini response withheld by its safety or policy
+// filters, as opposed to a model that simply returned nothing.
+var errGeminiBlocked = errors.New("gemini blocked response")
+
+// geminiBlockedFinishReasons are the candidate finish reasons that mean the
+// output was withheld rather than completed or truncated.
+var geminiBlockedFinishReasons = map[string]struct{}{
+	"SAFETY":             {},
+	"RECITATION":         {},
+	"BLOCKLIST":          {},
+	"PROHIBITED_CONTENT": {},
+	"SPII":               {},
+	"IMAGE_SAFETY":       {},
+}
+
+// geminiBlockReason returns why Gemini blocked the prompt or its answer, or
+// "" when neither the prompt feedback nor a candidate reports blocking.
+func geminiBlockReason(promptBlockReason string, finishReasons []string) string {
+	if reason := strings.TrimSpace(promptBlockReason); reason != "" {
+		return reason
+	}
+	for _, finishReason := range finishReasons {
+		reason := strings.ToUpper(strings.TrimSpace(finishReason))
+		if _, ok := geminiBlockedFinishReasons[reason]; ok {
+			return reason
+		}
+	}
+	return ""
+}
+
+// geminiResponseBlockReason inspects a non-streaming Gemini response body.
+func geminiResponseBlockReason(body []byte) string {
+	var fields struct {
+		PromptFeedback struct {
+			BlockReason string `json:"blockReason"`
+		} `json:"promptFeedback"`
+		Candidates []struct {
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	finishReasons := make([]string, 0, len(fields.Candidates))
+	for _, candidate := range fields.Candidates {
+		finishReasons = append(finishReasons, candidate.FinishReason)
+	}
+	return geminiBlockReason(fields.PromptFeedback.BlockReason, finishReasons)
+}
+
+func geminiBlockedError(reason string) error {
+	return fmt.Errorf("%w: %s", errGeminiBlocked, reason)
+}
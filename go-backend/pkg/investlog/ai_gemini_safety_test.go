@@ -0,0 +1,93 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func requestGeminiStub(t *testing.T, contentType, body string) error {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	endpoint, err := buildAICompletionsEndpoint(server.URL + "/api/gemini")
+	assertNoError(t, err, "build endpoint")
+	_, err = requestAIChatCompletion(context.Background(), aiChatCompletionRequest{
+		EndpointURL:  endpoint,
+		APIKey:       "gem-key",
+		Model:        "gemini-2.5-flash",
+		SystemPrompt: "sys prompt",
+		UserPrompt:   "user prompt",
+	})
+	return err
+}
+
+func TestRequestAIChatCompletion_GeminiSafetyBlocked(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		reason      string
+	}{
+		{
+			name:        "stream finish reason",
+			contentType: "text/event-stream",
+			body: "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"{\\\"overall\"}]}}],\"modelVersion\":\"gemini-2.5-flash\"}\n\n" +
+				"data: {\"candidates\":[{\"finishReason\":\"SAFETY\",\"safetyRatings\":[{\"category\":\"HARM_CATEGORY_DANGEROUS_CONTENT\",\"probability\":\"HIGH\"}]}],\"modelVersion\":\"gemini-2.5-flash\"}\n\n",
+			reason: "SAFETY",
+		},
+		{
+			name:        "stream prompt feedback",
+			contentType: "text/event-stream",
+			body:        "data: {\"promptFeedback\":{\"blockReason\":\"PROHIBITED_CONTENT\"},\"modelVersion\":\"gemini-2.5-flash\"}\n\n",
+			reason:      "PROHIBITED_CONTENT",
+		},
+		{
+			name:        "json finish reason",
+			contentType: "application/json",
+			body:        `{"candidates":[{"finishReason":"RECITATION"}],"modelVersion":"gemini-2.5-flash"}`,
+			reason:      "RECITATION",
+		},
+		{
+			name:        "json prompt feedback",
+			contentType: "application/json",
+			body:        `{"promptFeedback":{"blockReason":"SAFETY"}}`,
+			reason:      "SAFETY",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := requestGeminiStub(t, tc.contentType, tc.body)
+			if !errors.Is(err, errGeminiBlocked) {
+				t.Fatalf("expected blocked error, got %v", err)
+			}
+			if want := "gemini blocked response: " + tc.reason; err.Error() != want {
+				t.Fatalf("error = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestRequestAIChatCompletion_GeminiNormalFinishNotBlocked(t *testing.T) {
+	t.Parallel()
+
+	err := requestGeminiStub(t, "text/event-stream",
+		"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"{\\\"overall_summary\\\":\\\"ok\\\"}\"}]},\"finishReason\":\"STOP\"}],\"modelVersion\":\"gemini-2.5-flash\"}\n\n")
+	assertNoError(t, err, "normal finish")
+
+	err = requestGeminiStub(t, "application/json", `{"candidates":[{"finishReason":"STOP"}]}`)
+	if err == nil || errors.Is(err, errGeminiBlocked) || !strings.Contains(err.Error(), "empty") {
+		t.Fatalf("expected plain empty-content error, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuildGeminiStreamPayload_GenerationConfig(t *testing.T) {
+	t.Parallel()
+
+	config := buildGeminiStreamPayload(aiChatCompletionRequest{Model: "gemini-2.5-flash", UserPrompt: "user"})["generationConfig"].(map[string]any)
+	if config["temperature"] != defaultAITemperature {
+		t.Fatalf("expected default temperature, got %v", config["temperature"])
+	}
+	if _, ok := config["thinkingConfig"]; ok {
+		t.Fatalf("expected no thinkingConfig by default, got %#v", config)
+	}
+
+	temperature := 0.7
+	budget := 1024
+	config = buildGeminiStreamPayload(aiChatCompletionRequest{
+		Model:          "gemini-2.5-pro",
+		UserPrompt:     "user",
+		Temperature:    &temperature,
+		ThinkingBudget: &budget,
+	})["generationConfig"].(map[string]any)
+	if config["temperature"] != 0.7 {
+		t.Fatalf("expected temperature 0.7, got %v", config["temperature"])
+	}
+	thinking, ok := config["thinkingConfig"].(map[string]any)
+	if !ok || thinking["thinkingBudget"] != 1024 {
+		t.Fatalf("expected thinkingBudget 1024, got %#v", config["thinkingConfig"])
+	}
+
+	config = buildGeminiStreamPayload(aiChatCompletionRequest{
+		Model:          "gemini-2.0-flash",
+		UserPrompt:     "user",
+		ThinkingBudget: &budget,
+	})["generationConfig"].(map[string]any)
+	if _, ok := config["thinkingConfig"]; ok {
+		t.Fatalf("expected thinkingConfig omitted for gemini-2.0, got %#v", config)
+	}
+}
+
+func TestBuildChatCompletionsPayload_CustomTemperature(t *testing.T) {
+	t.Parallel()
+
+	temperature := 1.1
+	payload := buildChatCompletionsPayload(aiChatCompletionRequest{Model: "gpt-4o", Temperature: &temperature})
+	if payload["temperature"] != 1.1 {
+		t.Fatalf("expected temperature 1.1, got %v", payload["temperature"])
+	}
+	payload = buildChatCompletionsPayload(aiChatCompletionRequest{Model: "o3-mini", Temperature: &temperature})
+	if _, ok := payload["temperature"]; ok {
+		t.Fatalf("expected no temperature for reasoning model, got %v", payload["temperature"])
+	}
+}
+
+func TestGeminiSupportsThinking(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"gemini-2.5-flash":      true,
+		"models/gemini-2.5-pro": true,
+		"gemini-3-pro-preview":  true,
+		"gemini-2.0-flash":      false,
+		"gemini-1.5-pro":        false,
+		"gemini-exp-1206":       false,
+		"gpt-4o":                false,
+		"":                      false,
+	}
+	for model, want := range cases {
+		if got := geminiSupportsThinking(model); got != want {
+			t.Errorf("geminiSupportsThinking(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestValidateAIGenerationConfig(t *testing.T) {
+	t.Parallel()
+
+	zero, dynamic := 0.0, -1
+	if err := validateAIGenerationConfig(&zero, &dynamic); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	hot, tooLow := 2.1, -2
+	err := validateAIGenerationConfig(&hot, &tooLow)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if len(invalid.Fields) != 2 {
+		t.Fatalf("expected temperature and thinking_budget errors, got %v", invalid.Fields)
+	}
+}
@@ -2,6 +2,7 @@ package investlog
 
 import (
 	"context"
+	"sort"
 	"strings"
 )
 
@@ -26,10 +27,16 @@ JSON 字段必须包含：
 - 对于所持有的个股，需要联网抓取该个股近3年的财务数据，包括但不限于：营收、净利润、毛利率、净利率、资产负债率、现金流等，基于华尔街的估值逻辑进行分析。
 - recommendations 至少 3 条（如果持仓数量不足可少于 3 条，但必须说明原因）。
 - action 取值建议使用 increase/reduce/hold/add。
-- theory_tag 取值建议使用 Malkiel/Dalio/Buffett。
+- theory_tag 只能取以下值之一：{{theory_tags}}。
 - 禁止承诺收益，必须体现风险提示。
 - 用户仅提供标的代码、持仓占比、持仓盈亏和买入均价，你必须自行联网查找标的名称、最新价格、财务数据等信息来完成分析。`
 
+// holdingsAnalysisSystemPromptFor fills the theory_tag vocabulary into the
+// system prompt.
+func holdingsAnalysisSystemPromptFor(theoryTags []string) string {
+	return strings.Replace(holdingsAnalysisSystemPrompt, "{{theory_tags}}", strings.Join(theoryTags, "/"), 1)
+}
+
 // AnalyzeHoldings analyzes current holdings using an OpenAI-compatible model.
 func (c *Core) AnalyzeHoldings(req HoldingsAnalysisRequest) (*HoldingsAnalysisResult, error) {
 	return c.analyzeHoldings(req, nil, false)
@@ -56,20 +63,31 @@ func (c *Core) AnalyzeHoldingsStream(req HoldingsAnalysisRequest, onDelta func(s
 }
 
 func (c *Core) analyzeHoldings(req HoldingsAnalysisRequest, onDelta func(string) error, streamMode bool) (*HoldingsAnalysisResult, error) {
+	req.APIKey = c.resolveAIAPIKey(req.APIKey)
 	normalizedReq, err := normalizeHoldingsAnalysisRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
-	promptInput, err := c.buildHoldingsAnalysisPromptInput(normalizedReq.Currency)
+	profile, err := c.resolveAIAnalysisProfile(normalizedReq.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	theoryTags := profile.theoryTags(c.theoryTagVocabulary())
+
+	promptInput, err := c.buildHoldingsAnalysisPromptInput(normalizedReq.Currency, normalizedReq.MinWeightPct)
 	if err != nil {
 		return nil, err
 	}
+	if profile != nil && profile.PrivacyMode {
+		promptInput.applyPrivacyMode()
+	}
 
 	// Collect available symbol-level AI analysis for context.
 	symbolRefs := c.fetchSymbolAnalysisRefs(promptInput.Holdings)
 
-	userPrompt, err := buildHoldingsAnalysisUserPrompt(promptInput, normalizedReq, symbolRefs)
+	userPrompt, err := buildHoldingsAnalysisUserPrompt(promptInput, normalizedReq, symbolRefs, profile)
 	if err != nil {
 		return nil, err
 	}
@@ -79,16 +97,23 @@ func (c *Core) analyzeHoldings(req HoldingsAnalysisRequest, onDelta func(string)
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), aiTotalRequestTimeout)
+	ctx, cancel := context.WithTimeout(requestContext(normalizedReq.Context), aiTotalRequestTimeout)
 	defer cancel()
+	ctx, logger := c.withAnalysisLogger(ctx)
+	logger.Info("holdings analysis started", "currency", normalizedReq.Currency, "model", normalizedReq.Model)
 
 	chatReq := aiChatCompletionRequest{
-		EndpointURL:  endpointURL,
-		APIKey:       normalizedReq.APIKey,
-		Model:        normalizedReq.Model,
-		SystemPrompt: holdingsAnalysisSystemPrompt,
-		UserPrompt:   userPrompt,
-		Logger:       c.Logger(),
+		EndpointURL:    endpointURL,
+		APIKey:         normalizedReq.APIKey,
+		Model:          normalizedReq.Model,
+		SystemPrompt:   holdingsAnalysisSystemPromptFor(theoryTags),
+		UserPrompt:     userPrompt,
+		Logger:         logger,
+		Temperature:    normalizedReq.Temperature,
+		ThinkingBudget: normalizedReq.ThinkingBudget,
+	}
+	if normalizedReq.StructuredOutput {
+		chatReq.ResponseSchema = holdingsAnalysisResponseSchema()
 	}
 	if !streamMode && onDelta != nil {
 		chatReq.OnDelta = func(delta string) {
@@ -96,11 +121,19 @@ func (c *Core) analyzeHoldings(req HoldingsAnalysisRequest, onDelta func(string)
 		}
 	}
 
+	models := append([]string{normalizedReq.Model}, normalizedReq.FallbackModels...)
 	var chatResult aiChatCompletionResult
-	if streamMode {
-		chatResult, err = aiChatCompletionStream(ctx, chatReq, onDelta)
-	} else {
-		chatResult, err = aiChatCompletion(ctx, chatReq)
+	for i, model := range models {
+		chatReq.Model = model
+		if streamMode {
+			chatResult, err = c.chatCompletionStream(ctx, chatReq, onDelta)
+		} else {
+			chatResult, err = c.chatCompletion(ctx, chatReq)
+		}
+		if err == nil || i == len(models)-1 || !isAIOverloadedError(err) {
+			break
+		}
+		logger.Warn("ai holdings analysis: model overloaded, trying fallback", "model", model, "fallback", models[i+1], "err", err)
 	}
 	if err != nil {
 		return nil, err
@@ -113,7 +146,7 @@ func (c *Core) analyzeHoldings(req HoldingsAnalysisRequest, onDelta func(string)
 
 	model := strings.TrimSpace(chatResult.Model)
 	if model == "" {
-		model = normalizedReq.Model
+		model = chatReq.Model
 	}
 
 	riskLevel := strings.TrimSpace(parsed.RiskLevel)
@@ -122,7 +155,7 @@ func (c *Core) analyzeHoldings(req HoldingsAnalysisRequest, onDelta func(string)
 	}
 	overallSummary := strings.TrimSpace(parsed.OverallSummary)
 	if overallSummary == "" {
-		overallSummary = "模型未返回总结，请重试或更换模型。"
+		overallSummary = profile.fallbackText("模型未返回总结，请重试或更换模型。")
 	}
 	disclaimer := strings.TrimSpace(parsed.Disclaimer)
 	if disclaimer == "" {
@@ -130,22 +163,23 @@ func (c *Core) analyzeHoldings(req HoldingsAnalysisRequest, onDelta func(string)
 	}
 
 	result := &HoldingsAnalysisResult{
-		GeneratedAt:     NowRFC3339InShanghai(),
+		GeneratedAt:     c.NowRFC3339(),
 		Model:           model,
 		Currency:        normalizedReq.Currency,
 		AnalysisType:    normalizedReq.AnalysisType,
 		OverallSummary:  overallSummary,
 		RiskLevel:       riskLevel,
-		KeyFindings:     normalizeFindings(parsed.KeyFindings),
-		Recommendations: normalizeRecommendations(parsed.Recommendations),
+		KeyFindings:     normalizeFindings(parsed.KeyFindings, normalizedReq.MaxKeyFindings),
+		Recommendations: normalizeRecommendations(parsed.Recommendations, theoryTags, profile, normalizedReq.MaxRecommendations),
 		Disclaimer:      disclaimer,
 		SymbolRefs:      symbolRefs,
 	}
 
 	if id, err := c.saveHoldingsAnalysis(result); err != nil {
-		c.Logger().Warn("failed to save holdings analysis", "err", err)
+		logger.Warn("failed to save holdings analysis", "err", err)
 	} else {
 		result.ID = id
+		c.recordHoldingsAnalysisLog(result)
 	}
 
 	return result, nil
@@ -183,7 +217,9 @@ func (c *Core) fetchSymbolAnalysisRefs(holdings []holdingsAnalysisCurrencySnapsh
 	return refs
 }
 
-func normalizeFindings(findings []string) []string {
+// normalizeFindings drops blank findings and keeps at most maxItems of the
+// rest when maxItems is positive.
+func normalizeFindings(findings []string, maxItems int) []string {
 	result := make([]string, 0, len(findings))
 	for _, item := range findings {
 		trimmed := strings.TrimSpace(item)
@@ -192,23 +228,27 @@ func normalizeFindings(findings []string) []string {
 		}
 		result = append(result, trimmed)
 	}
+	if maxItems > 0 && len(result) > maxItems {
+		result = result[:maxItems]
+	}
 	return result
 }
 
-func normalizeRecommendations(items []HoldingsAnalysisRecommendation) []HoldingsAnalysisRecommendation {
+// normalizeRecommendations fills defaults for missing fields. Theory tags are
+// mapped onto theoryTags, with blank or unknown tags becoming its first entry;
+// a non-nil profile supplies the rationale fallback text. A positive maxItems keeps
+// only the top maxItems by priority, preserving model order within a priority.
+func normalizeRecommendations(items []HoldingsAnalysisRecommendation, theoryTags []string, profile *AIAnalysisProfile, maxItems int) []HoldingsAnalysisRecommendation {
 	result := make([]HoldingsAnalysisRecommendation, 0, len(items))
 	for _, item := range items {
 		action := strings.TrimSpace(strings.ToLower(item.Action))
 		if action == "" {
 			action = "hold"
 		}
-		theory := strings.TrimSpace(item.TheoryTag)
-		if theory == "" {
-			theory = "Malkiel"
-		}
+		theory := matchTheoryTag(item.TheoryTag, theoryTags)
 		rationale := strings.TrimSpace(item.Rationale)
 		if rationale == "" {
-			rationale = "模型未提供理由。"
+			rationale = profile.fallbackText("模型未提供理由。")
 		}
 		result = append(result, HoldingsAnalysisRecommendation{
 			Symbol:       strings.TrimSpace(item.Symbol),
@@ -219,5 +259,23 @@ func normalizeRecommendations(items []HoldingsAnalysisRecommendation) []Holdings
 			Priority:     strings.TrimSpace(item.Priority),
 		})
 	}
+	if maxItems > 0 && len(result) > maxItems {
+		sort.SliceStable(result, func(i, j int) bool {
+			return recommendationPriorityRank(result[i].Priority) < recommendationPriorityRank(result[j].Priority)
+		})
+		result = result[:maxItems]
+	}
 	return result
 }
+
+// recommendationPriorityRank orders priorities high < medium < anything else.
+func recommendationPriorityRank(priority string) int {
+	switch strings.ToLower(priority) {
+	case "high":
+		return 0
+	case "medium":
+		return 1
+	default:
+		return 2
+	}
+}
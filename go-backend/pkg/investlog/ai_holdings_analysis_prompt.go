@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -12,64 +13,84 @@ func normalizeHoldingsAnalysisRequest(req HoldingsAnalysisRequest) (HoldingsAnal
 	normalized.BaseURL = normalizeAIBaseURL(req.BaseURL)
 	normalized.APIKey = strings.TrimSpace(req.APIKey)
 	if normalized.APIKey == "" {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("api_key is required")
+		return HoldingsAnalysisRequest{}, NewValidationError("api_key", "api_key is required")
+	}
+	if err := validateAIAPIKey(normalized.APIKey); err != nil {
+		return HoldingsAnalysisRequest{}, err
 	}
 	normalized.Model = strings.TrimSpace(req.Model)
 	if normalized.Model == "" {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("model is required")
+		return HoldingsAnalysisRequest{}, NewValidationError("model", "model is required")
 	}
 	normalized.Model = normalizeAIModel(normalized.Model)
+	normalized.FallbackModels = normalizeFallbackModels(normalized.Model, req.FallbackModels)
 	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
 	if currency != "" && !contains(Currencies, currency) {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("invalid currency: %s", req.Currency)
+		return HoldingsAnalysisRequest{}, NewValidationError("currency", fmt.Sprintf("invalid currency: %s", req.Currency))
 	}
 	normalized.Currency = currency
 
-	riskProfile, err := normalizeEnum(strings.TrimSpace(req.RiskProfile), "balanced", map[string]struct{}{
-		"conservative": {},
-		"balanced":     {},
-		"aggressive":   {},
-	})
+	riskProfile, err := normalizeEnum(strings.TrimSpace(req.RiskProfile), defaultAISettingsRiskProfile, validAIRiskProfiles)
 	if err != nil {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("invalid risk_profile: %w", err)
+		return HoldingsAnalysisRequest{}, NewValidationError("risk_profile", fmt.Sprintf("invalid risk_profile: %v", err))
 	}
 	normalized.RiskProfile = riskProfile
 
-	horizon, err := normalizeEnum(strings.TrimSpace(req.Horizon), "medium", map[string]struct{}{
-		"short":  {},
-		"medium": {},
-		"long":   {},
-	})
+	horizon, err := normalizeEnum(strings.TrimSpace(req.Horizon), defaultAISettingsHorizon, validAIHorizons)
 	if err != nil {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("invalid horizon: %w", err)
+		return HoldingsAnalysisRequest{}, NewValidationError("horizon", fmt.Sprintf("invalid horizon: %v", err))
 	}
 	normalized.Horizon = horizon
 
-	adviceStyle, err := normalizeEnum(strings.TrimSpace(req.AdviceStyle), "balanced", map[string]struct{}{
-		"conservative": {},
-		"balanced":     {},
-		"aggressive":   {},
-	})
+	adviceStyle, err := normalizeEnum(strings.TrimSpace(req.AdviceStyle), defaultAISettingsAdviceStyle, validAIAdviceStyles)
 	if err != nil {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("invalid advice_style: %w", err)
+		return HoldingsAnalysisRequest{}, NewValidationError("advice_style", fmt.Sprintf("invalid advice_style: %v", err))
 	}
 	normalized.AdviceStyle = adviceStyle
 	normalized.StrategyPrompt = strings.TrimSpace(req.StrategyPrompt)
 
-	analysisType, err := normalizeEnum(strings.TrimSpace(req.AnalysisType), "adhoc", map[string]struct{}{
-		"adhoc":   {},
-		"weekly":  {},
-		"monthly": {},
-	})
+	analysisType, err := normalizeEnum(strings.TrimSpace(req.AnalysisType), defaultAIAnalysisType, validAIAnalysisTypes)
 	if err != nil {
-		return HoldingsAnalysisRequest{}, fmt.Errorf("invalid analysis_type: %w", err)
+		return HoldingsAnalysisRequest{}, NewValidationError("analysis_type", fmt.Sprintf("invalid analysis_type: %v", err))
 	}
 	normalized.AnalysisType = analysisType
+	normalized.Profile = strings.TrimSpace(req.Profile)
+	if req.MinWeightPct < 0 || req.MinWeightPct >= 100 {
+		return HoldingsAnalysisRequest{}, NewValidationError("min_weight_pct", "min_weight_pct must be between 0 and 100")
+	}
+	if req.MaxRecommendations < 0 {
+		return HoldingsAnalysisRequest{}, NewValidationError("max_recommendations", "max_recommendations must be non-negative")
+	}
+	if req.MaxKeyFindings < 0 {
+		return HoldingsAnalysisRequest{}, NewValidationError("max_key_findings", "max_key_findings must be non-negative")
+	}
+	if err := validateAIGenerationConfig(req.Temperature, req.ThinkingBudget); err != nil {
+		return HoldingsAnalysisRequest{}, err
+	}
 
 	return normalized, nil
 }
 
-func (c *Core) buildHoldingsAnalysisPromptInput(currency string) (*holdingsAnalysisPromptInput, error) {
+// normalizeFallbackModels drops blanks, duplicates and the primary model.
+// Fallbacks keep their provider like the primary model does.
+func normalizeFallbackModels(primary string, models []string) []string {
+	seen := map[string]struct{}{primary: {}}
+	var out []string
+	for _, model := range models {
+		model = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(model), "models/"))
+		if model == "" {
+			continue
+		}
+		if _, ok := seen[model]; ok {
+			continue
+		}
+		seen[model] = struct{}{}
+		out = append(out, model)
+	}
+	return out
+}
+
+func (c *Core) buildHoldingsAnalysisPromptInput(currency string, minWeightPct float64) (*holdingsAnalysisPromptInput, error) {
 	bySymbol, err := c.GetHoldingsBySymbol()
 	if err != nil {
 		return nil, fmt.Errorf("load holdings by symbol: %w", err)
@@ -94,7 +115,18 @@ func (c *Core) buildHoldingsAnalysisPromptInput(currency string) (*holdingsAnaly
 	for _, curr := range currencies {
 		currData := bySymbol[curr]
 		symbols := make([]holdingsAnalysisSymbolItem, 0, len(currData.Symbols))
+		excluded := 0
 		for _, item := range currData.Symbols {
+			// Delisted symbols no longer get prices or advice.
+			if item.Inactive {
+				continue
+			}
+			// Percent is relative to the whole currency, so skipping small
+			// positions leaves the other weights as they are.
+			if item.Percent < minWeightPct {
+				excluded++
+				continue
+			}
 			symbols = append(symbols, holdingsAnalysisSymbolItem{
 				Symbol:    item.Symbol,
 				WeightPct: item.Percent,
@@ -104,15 +136,26 @@ func (c *Core) buildHoldingsAnalysisPromptInput(currency string) (*holdingsAnaly
 		}
 
 		holdings = append(holdings, holdingsAnalysisCurrencySnapshot{
-			Currency: curr,
-			Symbols:  symbols,
+			Currency:      curr,
+			Symbols:       symbols,
+			ExcludedCount: excluded,
 		})
 	}
 
 	return &holdingsAnalysisPromptInput{Holdings: holdings}, nil
 }
 
-func buildHoldingsAnalysisUserPrompt(input *holdingsAnalysisPromptInput, req HoldingsAnalysisRequest, symbolRefs []HoldingsSymbolRef) (string, error) {
+// applyPrivacyMode strips cost and PnL from the snapshot, keeping only weights.
+func (input *holdingsAnalysisPromptInput) applyPrivacyMode() {
+	for i := range input.Holdings {
+		for j := range input.Holdings[i].Symbols {
+			input.Holdings[i].Symbols[j].AvgCost = 0
+			input.Holdings[i].Symbols[j].PnLPct = nil
+		}
+	}
+}
+
+func buildHoldingsAnalysisUserPrompt(input *holdingsAnalysisPromptInput, req HoldingsAnalysisRequest, symbolRefs []HoldingsSymbolRef, profile *AIAnalysisProfile) (string, error) {
 	promptInput := holdingsAnalysisPromptInput{
 		RiskProfile:     req.RiskProfile,
 		Horizon:         req.Horizon,
@@ -126,15 +169,51 @@ func buildHoldingsAnalysisUserPrompt(input *holdingsAnalysisPromptInput, req Hol
 		return "", fmt.Errorf("marshal holdings prompt input: %w", err)
 	}
 
+	// Oversized portfolios keep only the largest positions per currency,
+	// halving the count until the snapshot fits the token budget.
+	topN := req.PromptTopSymbols
+	if topN <= 0 {
+		topN = defaultHoldingsPromptTopSymbols
+	}
+	condensedTo := 0
+	for estimatePromptTokens(payload) > holdingsPromptTokenBudget && topN > 0 {
+		promptInput.Holdings = condenseHoldingsSnapshot(input.Holdings, topN)
+		payload, err = json.Marshal(promptInput)
+		if err != nil {
+			return "", fmt.Errorf("marshal holdings prompt input: %w", err)
+		}
+		condensedTo = topN
+		topN /= 2
+	}
+	if condensedTo > 0 {
+		symbolRefs = filterSymbolRefs(symbolRefs, promptInput.Holdings)
+	}
+
 	var sb strings.Builder
 	sb.WriteString("请基于以下输入完成分析并给出建议：\n")
 	sb.WriteString(string(payload))
+	if condensedTo > 0 {
+		fmt.Fprintf(&sb, "\n\n注意：持仓标的过多，每个币种仅逐一列出权重最高的 %d 个标的，其余标的已合并到该币种的 others（count 为标的数，weight_pct 为合计权重）。", condensedTo)
+	}
+	if hasExcludedHoldings(input.Holdings) {
+		fmt.Fprintf(&sb, "\n\n注意：已省略权重低于 %s%% 的零碎持仓（excluded_count 为各币种省略的标的数），列出标的的 weight_pct 仍按该币种全部持仓计算。", strconv.FormatFloat(req.MinWeightPct, 'f', -1, 64))
+	}
 	sb.WriteString("\n\n输出要求：\n")
 	sb.WriteString("1) 必须是 JSON 对象。\n")
 	sb.WriteString("2) recommendations 中建议尽量覆盖：仓位集中风险、资产分散、回撤防御、长期价值。\n")
 	sb.WriteString("3) 允许新增标的时，可给出 add 建议并点名标的。\n")
 	sb.WriteString("4) 每条建议必须给出 theory_tag 和 rationale。\n")
 	sb.WriteString("5) 若 strategy_prompt 非空，需优先吸收为策略偏好，但不得违反风险提示原则。")
+	next := 6
+	if req.MaxRecommendations > 0 {
+		fmt.Fprintf(&sb, "\n%d) recommendations 最多 %d 条，按重要性排序，优先保留 priority 为 high 的建议。", next, req.MaxRecommendations)
+		next++
+	}
+	fmt.Fprintf(&sb, "\n%d) %s", next, keyFindingsPromptRule(req.MaxKeyFindings))
+	next++
+	for i, rule := range analysisProfilePromptRules(profile) {
+		fmt.Fprintf(&sb, "\n%d) %s", i+next, rule)
+	}
 
 	// Append analysis-type-specific focus instructions.
 	switch req.AnalysisType {
@@ -156,6 +235,17 @@ func buildHoldingsAnalysisUserPrompt(input *holdingsAnalysisPromptInput, req Hol
 	return sb.String(), nil
 }
 
+// hasExcludedHoldings reports whether any snapshot dropped positions below
+// the minimum weight.
+func hasExcludedHoldings(holdings []holdingsAnalysisCurrencySnapshot) bool {
+	for _, snapshot := range holdings {
+		if snapshot.ExcludedCount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func parseHoldingsAnalysisResponse(content string) (*holdingsAnalysisModelResponse, error) {
 	cleaned := cleanupModelJSON(content)
 	var parsed holdingsAnalysisModelResponse
@@ -164,3 +254,19 @@ func parseHoldingsAnalysisResponse(content string) (*holdingsAnalysisModelRespon
 	}
 	return &parsed, nil
 }
+
+// minHoldingsKeyFindings is the fewest key_findings the prompt asks for.
+const minHoldingsKeyFindings = 2
+
+// keyFindingsPromptRule bounds the number of key_findings the model returns:
+// at least minHoldingsKeyFindings, and at most maxFindings when positive.
+func keyFindingsPromptRule(maxFindings int) string {
+	switch {
+	case maxFindings <= 0:
+		return fmt.Sprintf("key_findings 至少 %d 条。", minHoldingsKeyFindings)
+	case maxFindings <= minHoldingsKeyFindings:
+		return fmt.Sprintf("key_findings 给出 %d 条，按重要性排序。", maxFindings)
+	default:
+		return fmt.Sprintf("key_findings 给出 %d-%d 条，按重要性排序。", minHoldingsKeyFindings, maxFindings)
+	}
+}
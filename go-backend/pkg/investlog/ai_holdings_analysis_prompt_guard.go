@@ -0,0 +1,66 @@
+package investlog
+
+import "sort"
+
+const (
+	// defaultHoldingsPromptTopSymbols is how many positions per currency stay
+	// itemized when the holdings snapshot is condensed.
+	defaultHoldingsPromptTopSymbols = 50
+	// holdingsPromptTokenBudget is the share of aiMaxInputTokens the holdings
+	// snapshot may use; the rest is left for instructions and symbol refs.
+	holdingsPromptTokenBudget = aiMaxInputTokens / 4
+)
+
+// estimatePromptTokens is a deliberately pessimistic token estimate (about
+// three bytes per token) for JSON that is mostly ASCII.
+func estimatePromptTokens(text []byte) int {
+	return (len(text) + 2) / 3
+}
+
+// condenseHoldingsSnapshot keeps each currency's topN positions by weight and
+// merges the rest into an "others" bucket.
+func condenseHoldingsSnapshot(holdings []holdingsAnalysisCurrencySnapshot, topN int) []holdingsAnalysisCurrencySnapshot {
+	if topN <= 0 {
+		topN = defaultHoldingsPromptTopSymbols
+	}
+	condensed := make([]holdingsAnalysisCurrencySnapshot, 0, len(holdings))
+	for _, snapshot := range holdings {
+		if len(snapshot.Symbols) <= topN {
+			condensed = append(condensed, snapshot)
+			continue
+		}
+		symbols := append([]holdingsAnalysisSymbolItem(nil), snapshot.Symbols...)
+		sort.SliceStable(symbols, func(i, j int) bool {
+			return symbols[i].WeightPct > symbols[j].WeightPct
+		})
+		others := &holdingsAnalysisOthers{Count: len(symbols) - topN}
+		for _, item := range symbols[topN:] {
+			others.WeightPct += item.WeightPct
+		}
+		others.WeightPct = round2(others.WeightPct)
+		condensed = append(condensed, holdingsAnalysisCurrencySnapshot{
+			Currency:      snapshot.Currency,
+			Symbols:       symbols[:topN],
+			Others:        others,
+			ExcludedCount: snapshot.ExcludedCount,
+		})
+	}
+	return condensed
+}
+
+// filterSymbolRefs drops refs for symbols no longer itemized in holdings.
+func filterSymbolRefs(refs []HoldingsSymbolRef, holdings []holdingsAnalysisCurrencySnapshot) []HoldingsSymbolRef {
+	kept := make(map[string]struct{})
+	for _, snapshot := range holdings {
+		for _, item := range snapshot.Symbols {
+			kept[item.Symbol] = struct{}{}
+		}
+	}
+	filtered := make([]HoldingsSymbolRef, 0, len(refs))
+	for _, ref := range refs {
+		if _, ok := kept[ref.Symbol]; ok {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
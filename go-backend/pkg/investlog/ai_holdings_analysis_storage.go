@@ -45,6 +45,23 @@ func (c *Core) saveHoldingsAnalysis(result *HoldingsAnalysisResult) (int64, erro
 	return res.LastInsertId()
 }
 
+// recordHoldingsAnalysisLog adds the analysis to the operation log. Failures
+// are only logged so the analysis result is still returned.
+func (c *Core) recordHoldingsAnalysisLog(result *HoldingsAnalysisResult) {
+	details := fmt.Sprintf("model=%s risk_level=%s", result.Model, result.RiskLevel)
+	var currency *string
+	if result.Currency != "" {
+		currency = stringPtr(result.Currency)
+	}
+	if _, err := c.AddOperationLog(OperationLog{
+		Operation: OperationAIHoldingsAnalysis,
+		Currency:  currency,
+		Details:   &details,
+	}); err != nil {
+		c.Logger().Warn("failed to record holdings analysis operation log", "err", err)
+	}
+}
+
 func nullableString(s string) any {
 	if s == "" || s == "null" {
 		return nil
@@ -55,7 +72,7 @@ func nullableString(s string) any {
 // GetHoldingsAnalysis returns the latest saved analysis for the given currency.
 func (c *Core) GetHoldingsAnalysis(currency string) (*HoldingsAnalysisResult, error) {
 	currency = strings.ToUpper(strings.TrimSpace(currency))
-	results, err := c.GetHoldingsAnalysisHistory(currency, 1)
+	results, err := c.GetHoldingsAnalysisHistory(currency, 1, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -65,12 +82,16 @@ func (c *Core) GetHoldingsAnalysis(currency string) (*HoldingsAnalysisResult, er
 	return &results[0], nil
 }
 
-// GetHoldingsAnalysisHistory returns up to limit recent analyses for the given currency.
-func (c *Core) GetHoldingsAnalysisHistory(currency string, limit int) ([]HoldingsAnalysisResult, error) {
+// GetHoldingsAnalysisHistory returns up to limit recent analyses for the given
+// currency, newest first, skipping the first offset of them.
+func (c *Core) GetHoldingsAnalysisHistory(currency string, limit, offset int) ([]HoldingsAnalysisResult, error) {
 	currency = strings.ToUpper(strings.TrimSpace(currency))
 	if limit <= 0 {
 		limit = 10
 	}
+	if offset < 0 {
+		offset = 0
+	}
 
 	var (
 		query string
@@ -78,12 +99,12 @@ func (c *Core) GetHoldingsAnalysisHistory(currency string, limit int) ([]Holding
 	)
 	if currency != "" {
 		query = `SELECT id, currency, model, analysis_type, risk_level, overall_summary, key_findings, recommendations, disclaimer, symbol_refs, created_at
-		          FROM holdings_analyses WHERE currency = ? ORDER BY created_at DESC LIMIT ?`
-		args = []any{currency, limit}
+		          FROM holdings_analyses WHERE currency = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+		args = []any{currency, limit, offset}
 	} else {
 		query = `SELECT id, currency, model, analysis_type, risk_level, overall_summary, key_findings, recommendations, disclaimer, symbol_refs, created_at
-		          FROM holdings_analyses ORDER BY created_at DESC LIMIT ?`
-		args = []any{limit}
+		          FROM holdings_analyses ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+		args = []any{limit, offset}
 	}
 
 	rows, err := c.db.Query(query, args...)
@@ -155,3 +176,34 @@ func (c *Core) GetHoldingsAnalysisHistory(currency string, limit int) ([]Holding
 	}
 	return results, nil
 }
+
+// GetHoldingsAnalysisHistoryCount returns the number of saved analyses for the
+// given currency, or for all currencies when currency is empty.
+func (c *Core) GetHoldingsAnalysisHistoryCount(currency string) (int, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	query := `SELECT COUNT(*) FROM holdings_analyses`
+	var args []any
+	if currency != "" {
+		query += ` WHERE currency = ?`
+		args = append(args, currency)
+	}
+	var count int
+	if err := c.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count holdings_analyses: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteHoldingsAnalysis removes holdings analysis id. It reports false when
+// there is no such analysis.
+func (c *Core) DeleteHoldingsAnalysis(id int64) (bool, error) {
+	result, err := c.db.Exec("DELETE FROM holdings_analyses WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("delete holdings_analysis: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
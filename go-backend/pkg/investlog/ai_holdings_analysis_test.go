@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -180,11 +181,37 @@ func TestNormalizeHoldingsAnalysisRequest(t *testing.T) {
 		t.Fatalf("expected api_key validation error, got %v", err)
 	}
 
+	_, err = normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{APIKey: "sk-abc def", Model: "m"})
+	if err == nil || !strings.Contains(err.Error(), "whitespace or control characters") {
+		t.Fatalf("expected malformed api_key error, got %v", err)
+	}
+
 	_, err = normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{APIKey: "k", Model: "m", Currency: "EUR"})
 	if err == nil || !strings.Contains(err.Error(), "invalid currency") {
 		t.Fatalf("expected currency validation error, got %v", err)
 	}
 
+	_, err = normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{APIKey: "k", Model: "m", MinWeightPct: -1})
+	if err == nil || !strings.Contains(err.Error(), "min_weight_pct") {
+		t.Fatalf("expected min_weight_pct validation error, got %v", err)
+	}
+
+	_, err = normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{APIKey: "k", Model: "m", MaxRecommendations: -1})
+	if err == nil || !strings.Contains(err.Error(), "max_recommendations") {
+		t.Fatalf("expected max_recommendations validation error, got %v", err)
+	}
+
+	_, err = normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{APIKey: "k", Model: "m", MaxKeyFindings: -1})
+	if err == nil || !strings.Contains(err.Error(), "max_key_findings") {
+		t.Fatalf("expected max_key_findings validation error, got %v", err)
+	}
+
+	hot := 2.5
+	_, err = normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{APIKey: "k", Model: "m", Temperature: &hot})
+	if err == nil || !strings.Contains(err.Error(), "temperature") {
+		t.Fatalf("expected temperature validation error, got %v", err)
+	}
+
 	result, err := normalizeHoldingsAnalysisRequest(HoldingsAnalysisRequest{
 		APIKey:         " k ",
 		Model:          " m ",
@@ -217,7 +244,7 @@ func TestBuildHoldingsAnalysisUserPrompt_ContainsStrategyPrompt(t *testing.T) {
 		AdviceStyle:     "balanced",
 		AllowNewSymbols: true,
 		StrategyPrompt:  "优先控制回撤，不新增中概股",
-	}, nil)
+	}, nil, nil)
 	if err != nil {
 		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
 	}
@@ -229,6 +256,170 @@ func TestBuildHoldingsAnalysisUserPrompt_ContainsStrategyPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildHoldingsAnalysisUserPrompt_MaxRecommendations(t *testing.T) {
+	t.Parallel()
+
+	input := &holdingsAnalysisPromptInput{Holdings: []holdingsAnalysisCurrencySnapshot{{Currency: "USD"}}}
+	prompt, err := buildHoldingsAnalysisUserPrompt(input, HoldingsAnalysisRequest{MaxRecommendations: 3}, nil, nil)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "6) recommendations 最多 3 条") {
+		t.Fatalf("expected recommendation cap in prompt, got: %s", prompt)
+	}
+
+	unlimited, err := buildHoldingsAnalysisUserPrompt(input, HoldingsAnalysisRequest{}, nil, nil)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
+	}
+	if strings.Contains(unlimited, "recommendations 最多") {
+		t.Fatalf("expected no recommendation cap by default, got: %s", unlimited)
+	}
+}
+
+func TestBuildHoldingsAnalysisUserPrompt_KeyFindingsCount(t *testing.T) {
+	t.Parallel()
+
+	input := &holdingsAnalysisPromptInput{Holdings: []holdingsAnalysisCurrencySnapshot{{Currency: "USD"}}}
+	cases := map[int]string{
+		0: "key_findings 至少 2 条",
+		1: "key_findings 给出 1 条",
+		5: "key_findings 给出 2-5 条",
+	}
+	for maxFindings, want := range cases {
+		prompt, err := buildHoldingsAnalysisUserPrompt(input, HoldingsAnalysisRequest{MaxKeyFindings: maxFindings}, nil, nil)
+		if err != nil {
+			t.Fatalf("buildHoldingsAnalysisUserPrompt(%d) failed: %v", maxFindings, err)
+		}
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("max %d: expected %q in prompt, got: %s", maxFindings, want, prompt)
+		}
+	}
+}
+
+func TestNormalizeFindings_TruncatesToMax(t *testing.T) {
+	t.Parallel()
+
+	findings := []string{" a ", "", "b", "  ", "c", "d"}
+	if got := normalizeFindings(findings, 2); strings.Join(got, ",") != "a,b" {
+		t.Fatalf("expected first two non-blank findings, got %v", got)
+	}
+	if got := normalizeFindings(findings, 0); len(got) != 4 {
+		t.Fatalf("expected all non-blank findings without max, got %v", got)
+	}
+	if got := normalizeFindings(findings, 10); len(got) != 4 {
+		t.Fatalf("expected all non-blank findings under max, got %v", got)
+	}
+}
+
+func TestNormalizeRecommendations_MaxKeepsHighPriority(t *testing.T) {
+	t.Parallel()
+
+	items := []HoldingsAnalysisRecommendation{
+		{Symbol: "A", Priority: "low"},
+		{Symbol: "B", Priority: "high"},
+		{Symbol: "C", Priority: "medium"},
+		{Symbol: "D"},
+		{Symbol: "E", Priority: " HIGH "},
+	}
+
+	got := normalizeRecommendations(items, defaultTheoryTags, nil, 3)
+	var symbols []string
+	for _, item := range got {
+		symbols = append(symbols, item.Symbol)
+	}
+	if strings.Join(symbols, ",") != "B,E,C" {
+		t.Fatalf("expected high then medium priorities, got %v", symbols)
+	}
+
+	if all := normalizeRecommendations(items, defaultTheoryTags, nil, 0); len(all) != len(items) {
+		t.Fatalf("expected no truncation without max, got %d items", len(all))
+	}
+	if all := normalizeRecommendations(items, defaultTheoryTags, nil, 10); len(all) != len(items) {
+		t.Fatalf("expected no truncation under max, got %d items", len(all))
+	}
+	if all := normalizeRecommendations(items, defaultTheoryTags, nil, 10); all[0].Symbol != "A" {
+		t.Fatalf("expected model order kept under max, got %q first", all[0].Symbol)
+	}
+}
+
+func TestBuildHoldingsAnalysisUserPrompt_CondensesLargePortfolio(t *testing.T) {
+	t.Parallel()
+
+	const count = 6000
+	symbols := make([]holdingsAnalysisSymbolItem, 0, count)
+	pnl := 1.5
+	for i := 0; i < count; i++ {
+		symbols = append(symbols, holdingsAnalysisSymbolItem{
+			Symbol:    fmt.Sprintf("SYM%05d", i),
+			WeightPct: float64(i%100) / 100,
+			PnLPct:    &pnl,
+			AvgCost:   123.4567,
+		})
+	}
+	// One clearly largest position must survive condensing.
+	symbols[count-1].WeightPct = 9.5
+	input := &holdingsAnalysisPromptInput{Holdings: []holdingsAnalysisCurrencySnapshot{{Currency: "USD", Symbols: symbols}}}
+	refs := []HoldingsSymbolRef{{Symbol: "SYM05999", Rating: "buy"}, {Symbol: "SYM00000", Rating: "sell"}}
+
+	prompt, err := buildHoldingsAnalysisUserPrompt(input, HoldingsAnalysisRequest{PromptTopSymbols: 20}, refs, nil)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
+	}
+	if tokens := estimatePromptTokens([]byte(prompt)); tokens > holdingsPromptTokenBudget {
+		t.Fatalf("expected prompt within %d tokens, got %d", holdingsPromptTokenBudget, tokens)
+	}
+	if !strings.Contains(prompt, `"others":{"count":5980,`) {
+		t.Fatalf("expected others bucket for 5980 symbols, got: %.500s", prompt)
+	}
+	if !strings.Contains(prompt, "权重最高的 20 个标的") {
+		t.Fatal("expected truncation note in prompt")
+	}
+	if !strings.Contains(prompt, `"symbol":"SYM05999"`) {
+		t.Fatal("expected largest position to stay itemized")
+	}
+	if strings.Contains(prompt, `"rating":"sell"`) {
+		t.Fatal("expected refs for merged symbols to be dropped")
+	}
+	if len(input.Holdings[0].Symbols) != count {
+		t.Fatal("expected input snapshot to be left intact")
+	}
+
+	small, err := buildHoldingsAnalysisUserPrompt(&holdingsAnalysisPromptInput{
+		Holdings: []holdingsAnalysisCurrencySnapshot{{Currency: "USD", Symbols: symbols[:30]}},
+	}, HoldingsAnalysisRequest{PromptTopSymbols: 20}, nil, nil)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
+	}
+	if strings.Contains(small, "others") {
+		t.Fatal("expected small portfolio to stay itemized")
+	}
+}
+
+func TestCondenseHoldingsSnapshot(t *testing.T) {
+	t.Parallel()
+
+	condensed := condenseHoldingsSnapshot([]holdingsAnalysisCurrencySnapshot{
+		{Currency: "CNY", Symbols: []holdingsAnalysisSymbolItem{{Symbol: "A", WeightPct: 60}}},
+		{Currency: "USD", Symbols: []holdingsAnalysisSymbolItem{
+			{Symbol: "S", WeightPct: 5.5},
+			{Symbol: "L", WeightPct: 70},
+			{Symbol: "M", WeightPct: 20},
+			{Symbol: "T", WeightPct: 4.5},
+		}},
+	}, 2)
+	if condensed[0].Others != nil || len(condensed[0].Symbols) != 1 {
+		t.Fatalf("expected CNY untouched, got %+v", condensed[0])
+	}
+	usd := condensed[1]
+	if len(usd.Symbols) != 2 || usd.Symbols[0].Symbol != "L" || usd.Symbols[1].Symbol != "M" {
+		t.Fatalf("expected top 2 by weight, got %+v", usd.Symbols)
+	}
+	if usd.Others == nil || usd.Others.Count != 2 || !floatEquals(usd.Others.WeightPct, 10, 0.001) {
+		t.Fatalf("expected others count=2 weight=10, got %+v", usd.Others)
+	}
+}
+
 func TestParseHoldingsAnalysisResponse(t *testing.T) {
 	t.Parallel()
 
@@ -1001,6 +1192,129 @@ func TestAnalyzeHoldingsEndToEndWithStub(t *testing.T) {
 	if result.Recommendations[0].Action != "reduce" {
 		t.Fatalf("unexpected action: %s", result.Recommendations[0].Action)
 	}
+
+	logs, err := core.GetOperationLogs(OperationLogFilter{OperationType: OperationAIHoldingsAnalysis})
+	assertNoError(t, err, "GetOperationLogs")
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 holdings analysis log, got %d", len(logs))
+	}
+	if logs[0].Currency == nil || *logs[0].Currency != "USD" {
+		t.Fatalf("expected USD currency in log, got %v", logs[0].Currency)
+	}
+	if logs[0].Details == nil || !strings.Contains(*logs[0].Details, "mock-model") || !strings.Contains(*logs[0].Details, "balanced") {
+		t.Fatalf("expected model and risk level in log details, got %v", logs[0].Details)
+	}
+}
+
+func TestAnalyzeHoldings_FallsBackToNextModelWhenOverloaded(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+
+	var tried []string
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		tried = append(tried, req.Model)
+		if req.Model == "gemini-2.5-pro" {
+			return aiChatCompletionResult{}, aiUpstreamError(http.StatusTooManyRequests, []byte(`{"error":{"message":"quota exceeded"}}`))
+		}
+		return aiChatCompletionResult{
+			Model:   req.Model,
+			Content: `{"overall_summary":"ok","risk_level":"balanced","key_findings":[],"recommendations":[],"disclaimer":"仅供参考"}`,
+		}, nil
+	}
+
+	result, err := core.AnalyzeHoldings(HoldingsAnalysisRequest{
+		BaseURL:        "https://example.com/v1",
+		APIKey:         "key",
+		Model:          "gemini-2.5-pro",
+		Currency:       "USD",
+		FallbackModels: []string{"gemini-2.5-flash", "gemini-2.0-flash"},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeHoldings failed: %v", err)
+	}
+	if result.Model != "gemini-2.5-flash" {
+		t.Fatalf("expected fallback model in result, got %q", result.Model)
+	}
+	if strings.Join(tried, ",") != "gemini-2.5-pro,gemini-2.5-flash" {
+		t.Fatalf("unexpected models tried: %v", tried)
+	}
+}
+
+func TestAnalyzeHoldings_NoFallbackOnNonRetryableError(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+
+	calls := 0
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		calls++
+		return aiChatCompletionResult{}, aiUpstreamError(http.StatusBadRequest, []byte(`{"error":{"message":"bad request"}}`))
+	}
+
+	_, err := core.AnalyzeHoldings(HoldingsAnalysisRequest{
+		APIKey:         "key",
+		Model:          "gemini-2.5-pro",
+		FallbackModels: []string{"gemini-2.5-flash"},
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected no fallback attempt, got %d calls", calls)
+	}
+}
+
+func TestIsAIOverloadedError(t *testing.T) {
+	t.Parallel()
+
+	if !isAIOverloadedError(aiUpstreamError(http.StatusTooManyRequests, nil)) {
+		t.Fatal("429 should be overloaded")
+	}
+	flattened := &aiAttemptsError{
+		message:  "chat completion failed: x -> busy",
+		attempts: []error{errors.New("timeout"), aiUpstreamError(http.StatusServiceUnavailable, []byte("busy"))},
+	}
+	if !isAIOverloadedError(fmt.Errorf("analyze: %w", flattened)) {
+		t.Fatal("503 should stay detectable through the fallback chain")
+	}
+	laterAttempt := &aiAttemptsError{
+		message:  "chat completion failed: x -> not found | y -> busy",
+		attempts: []error{aiUpstreamError(http.StatusNotFound, []byte("no route")), aiUpstreamError(http.StatusTooManyRequests, []byte("busy"))},
+	}
+	if !isAIOverloadedError(laterAttempt) {
+		t.Fatal("a 429 on a later attempt should be detected past an earlier status error")
+	}
+	if isAIOverloadedError(errors.New("ai upstream error: model overloaded")) {
+		t.Fatal("overload must come from the status, not the message text")
+	}
+	if isAIOverloadedError(aiUpstreamError(http.StatusInternalServerError, []byte("boom"))) {
+		t.Fatal("500 should not be overloaded")
+	}
+}
+
+func TestNormalizeFallbackModels(t *testing.T) {
+	t.Parallel()
+
+	got := normalizeFallbackModels("gemini-2.5-pro", []string{" gemini-2.5-flash ", "", "gemini-2.5-pro", "gemini-2.5-flash"})
+	if strings.Join(got, ",") != "gemini-2.5-flash" {
+		t.Fatalf("unexpected fallback models: %v", got)
+	}
+
+	got = normalizeFallbackModels("gpt-4o", []string{"gpt-4o-mini", "models/gemini-2.5-flash"})
+	if strings.Join(got, ",") != "gpt-4o-mini,gemini-2.5-flash" {
+		t.Fatalf("expected non-Gemini fallbacks to be kept, got %v", got)
+	}
 }
 
 func TestAnalyzeHoldingsStreamEndToEndWithStub(t *testing.T) {
@@ -1117,6 +1431,86 @@ func TestAnalyzeHoldings_IncludesSymbolRefs(t *testing.T) {
 	}
 }
 
+func TestBuildHoldingsAnalysisPromptInput_MinWeightPct(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-min", "Main")
+	testBuyTransaction(t, core, "AAPL", 9, 100, "USD", "acc-min")
+	testBuyTransaction(t, core, "MSFT", 1, 60, "USD", "acc-min")
+	testBuyTransaction(t, core, "TSLA", 1, 40, "USD", "acc-min")
+
+	input, err := core.buildHoldingsAnalysisPromptInput("USD", 5)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisPromptInput failed: %v", err)
+	}
+	if len(input.Holdings) != 1 {
+		t.Fatalf("expected 1 currency snapshot, got %d", len(input.Holdings))
+	}
+	snapshot := input.Holdings[0]
+	if snapshot.ExcludedCount != 1 {
+		t.Fatalf("expected 1 excluded position, got %d", snapshot.ExcludedCount)
+	}
+	weights := map[string]float64{}
+	for _, item := range snapshot.Symbols {
+		weights[item.Symbol] = item.WeightPct
+	}
+	if _, ok := weights["TSLA"]; ok {
+		t.Fatalf("expected TSLA below threshold to be omitted, got %+v", snapshot.Symbols)
+	}
+	// Weights stay relative to the full 1000 USD, including TSLA.
+	assertFloatEquals(t, weights["AAPL"], 90, "AAPL weight")
+	assertFloatEquals(t, weights["MSFT"], 6, "MSFT weight")
+
+	prompt, err := buildHoldingsAnalysisUserPrompt(input, HoldingsAnalysisRequest{MinWeightPct: 5}, nil, nil)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
+	}
+	if strings.Contains(prompt, "TSLA") {
+		t.Fatalf("expected TSLA omitted from prompt, got: %s", prompt)
+	}
+	if !strings.Contains(prompt, `"excluded_count":1`) || !strings.Contains(prompt, "权重低于 5%") {
+		t.Fatalf("expected excluded note in prompt, got: %s", prompt)
+	}
+
+	all, err := core.buildHoldingsAnalysisPromptInput("USD", 0)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisPromptInput failed: %v", err)
+	}
+	if len(all.Holdings[0].Symbols) != 3 || all.Holdings[0].ExcludedCount != 0 {
+		t.Fatalf("expected every position without a threshold, got %+v", all.Holdings[0])
+	}
+}
+
+func TestBuildHoldingsAnalysisPromptInput_SkipsInactiveSymbols(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-inactive", "Main")
+	testBuyTransaction(t, core, "AAPL", 9, 100, "USD", "acc-inactive")
+	testBuyTransaction(t, core, "DLST", 10, 10, "USD", "acc-inactive")
+	found, err := core.SetSymbolInactive("DLST", "USD", true)
+	if err != nil || !found {
+		t.Fatalf("SetSymbolInactive failed: %v, %v", found, err)
+	}
+
+	input, err := core.buildHoldingsAnalysisPromptInput("USD", 0)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisPromptInput failed: %v", err)
+	}
+	symbols := input.Holdings[0].Symbols
+	if len(symbols) != 1 || symbols[0].Symbol != "AAPL" {
+		t.Fatalf("expected only AAPL in the snapshot, got %+v", symbols)
+	}
+	prompt, err := buildHoldingsAnalysisUserPrompt(input, HoldingsAnalysisRequest{}, nil, nil)
+	if err != nil {
+		t.Fatalf("buildHoldingsAnalysisUserPrompt failed: %v", err)
+	}
+	if strings.Contains(prompt, "DLST") {
+		t.Fatalf("expected inactive DLST omitted from prompt, got: %s", prompt)
+	}
+}
+
 func TestGetHoldingsAnalysisAndHistory_WithSeedData(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1161,7 +1555,7 @@ func TestGetHoldingsAnalysisAndHistory_WithSeedData(t *testing.T) {
 		t.Fatalf("expected latest USD analysis, got %+v", latest)
 	}
 
-	history, err := core.GetHoldingsAnalysisHistory("USD", 10)
+	history, err := core.GetHoldingsAnalysisHistory("USD", 10, 0)
 	if err != nil {
 		t.Fatalf("GetHoldingsAnalysisHistory failed: %v", err)
 	}
@@ -1169,7 +1563,7 @@ func TestGetHoldingsAnalysisAndHistory_WithSeedData(t *testing.T) {
 		t.Fatal("expected non-empty history")
 	}
 
-	allHistory, err := core.GetHoldingsAnalysisHistory("", 10)
+	allHistory, err := core.GetHoldingsAnalysisHistory("", 10, 0)
 	if err != nil {
 		t.Fatalf("GetHoldingsAnalysisHistory(all) failed: %v", err)
 	}
@@ -1394,7 +1788,7 @@ func TestAnyToStringAndNormalizeRecommendations(t *testing.T) {
 			Rationale: "",
 			Priority:  " high ",
 		},
-	})
+	}, defaultTheoryTags, nil, 0)
 	if len(normalized) != 1 {
 		t.Fatalf("unexpected normalized length: %d", len(normalized))
 	}
@@ -1416,7 +1810,7 @@ func TestGetHoldingsAnalysisHistory_EmptyResult(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	history, err := core.GetHoldingsAnalysisHistory("", 0)
+	history, err := core.GetHoldingsAnalysisHistory("", 0, 0)
 	if err != nil {
 		t.Fatalf("GetHoldingsAnalysisHistory failed: %v", err)
 	}
@@ -1436,6 +1830,85 @@ func TestGetHoldingsAnalysisHistory_EmptyResult(t *testing.T) {
 	}
 }
 
+func TestGetHoldingsAnalysisHistory_OffsetPaging(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := core.saveHoldingsAnalysis(&HoldingsAnalysisResult{Currency: "USD", Model: "m", AnalysisType: "adhoc"})
+		if err != nil {
+			t.Fatalf("saveHoldingsAnalysis %d failed: %v", i, err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := core.saveHoldingsAnalysis(&HoldingsAnalysisResult{Currency: "CNY", Model: "m", AnalysisType: "adhoc"}); err != nil {
+		t.Fatalf("saveHoldingsAnalysis CNY failed: %v", err)
+	}
+
+	var got []int64
+	for offset := 0; offset < 6; offset += 2 {
+		page, err := core.GetHoldingsAnalysisHistory("USD", 2, offset)
+		if err != nil {
+			t.Fatalf("GetHoldingsAnalysisHistory offset %d failed: %v", offset, err)
+		}
+		for _, item := range page {
+			got = append(got, item.ID)
+		}
+	}
+	// Rows saved within the same second tie on created_at, so id breaks the tie.
+	want := []int64{ids[4], ids[3], ids[2], ids[1], ids[0]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected pages %v, got %v", want, got)
+	}
+
+	total, err := core.GetHoldingsAnalysisHistoryCount("usd")
+	if err != nil {
+		t.Fatalf("GetHoldingsAnalysisHistoryCount failed: %v", err)
+	}
+	if total != 5 {
+		t.Fatalf("expected USD total 5, got %d", total)
+	}
+	all, err := core.GetHoldingsAnalysisHistoryCount("")
+	if err != nil {
+		t.Fatalf("GetHoldingsAnalysisHistoryCount all failed: %v", err)
+	}
+	if all != 6 {
+		t.Fatalf("expected total 6, got %d", all)
+	}
+}
+
+func TestDeleteHoldingsAnalysis(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	keep, err := core.saveHoldingsAnalysis(&HoldingsAnalysisResult{Currency: "USD", Model: "m", AnalysisType: "adhoc"})
+	if err != nil {
+		t.Fatalf("saveHoldingsAnalysis failed: %v", err)
+	}
+	drop, err := core.saveHoldingsAnalysis(&HoldingsAnalysisResult{Currency: "USD", Model: "m", AnalysisType: "adhoc"})
+	if err != nil {
+		t.Fatalf("saveHoldingsAnalysis failed: %v", err)
+	}
+
+	deleted, err := core.DeleteHoldingsAnalysis(drop)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteHoldingsAnalysis(%d) = %v, %v; want true", drop, deleted, err)
+	}
+	history, err := core.GetHoldingsAnalysisHistory("USD", 10, 0)
+	if err != nil {
+		t.Fatalf("GetHoldingsAnalysisHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != keep {
+		t.Fatalf("expected only analysis %d left, got %+v", keep, history)
+	}
+
+	deleted, err = core.DeleteHoldingsAnalysis(drop)
+	if err != nil || deleted {
+		t.Fatalf("second DeleteHoldingsAnalysis(%d) = %v, %v; want false", drop, deleted, err)
+	}
+}
+
 func TestAnalyzeHoldings_UsesFifteenMinuteOverallTimeout(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1602,7 +2075,7 @@ func TestGetHoldingsAnalysisAndHistory(t *testing.T) {
 		t.Fatalf("expected normalized currency USD, got %s", latest.Currency)
 	}
 
-	usdHistory, err := core.GetHoldingsAnalysisHistory("USD", 10)
+	usdHistory, err := core.GetHoldingsAnalysisHistory("USD", 10, 0)
 	if err != nil {
 		t.Fatalf("GetHoldingsAnalysisHistory USD failed: %v", err)
 	}
@@ -1636,7 +2109,7 @@ func TestGetHoldingsAnalysisAndHistory(t *testing.T) {
 		t.Fatal("expected to find malformed row in USD history")
 	}
 
-	allHistory, err := core.GetHoldingsAnalysisHistory("", 0)
+	allHistory, err := core.GetHoldingsAnalysisHistory("", 0, 0)
 	if err != nil {
 		t.Fatalf("GetHoldingsAnalysisHistory all failed: %v", err)
 	}
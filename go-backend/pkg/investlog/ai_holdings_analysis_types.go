@@ -1,6 +1,7 @@
 package investlog
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -17,6 +18,35 @@ type HoldingsAnalysisRequest struct {
 	AllowNewSymbols bool
 	StrategyPrompt  string
 	AnalysisType    string // "adhoc", "weekly", "monthly"
+	Profile         string // Optional AIAnalysisProfile name
+	// FallbackModels are tried in order on the same endpoint when Model is
+	// overloaded (429/503).
+	FallbackModels []string
+	// PromptTopSymbols is how many of each currency's largest positions stay
+	// itemized when the holdings snapshot is too large for the prompt; the
+	// rest are merged into "others". Default: 50.
+	PromptTopSymbols int
+	// MinWeightPct leaves positions whose weight in their currency is below
+	// this percentage out of the snapshot; they still count toward the
+	// currency total, so the remaining weights are unchanged. Default: 0.
+	MinWeightPct float64
+	// MaxRecommendations caps how many recommendations are kept, preferring
+	// high-priority ones. Default: 0 (unlimited).
+	MaxRecommendations int
+	// MaxKeyFindings caps how many key findings are kept; the prompt also asks
+	// for at least minHoldingsKeyFindings of them. Default: 0 (unlimited).
+	MaxKeyFindings int
+	// StructuredOutput sends a strict JSON schema of the expected response to
+	// providers that support one, falling back to prompt-only JSON when the
+	// provider rejects it.
+	StructuredOutput bool
+	// Temperature overrides the default sampling temperature (0-2).
+	Temperature *float64
+	// ThinkingBudget caps thinking tokens on Gemini models that support it;
+	// -1 lets the model decide and 0 disables thinking.
+	ThinkingBudget *int
+	// Context bounds the AI calls; it defaults to context.Background().
+	Context context.Context
 }
 
 // HoldingsSymbolRef is a brief summary of a symbol's latest AI analysis used as context.
@@ -93,13 +123,22 @@ type HoldingsAnalysisResult struct {
 type holdingsAnalysisCurrencySnapshot struct {
 	Currency string                       `json:"currency"`
 	Symbols  []holdingsAnalysisSymbolItem `json:"symbols"`
+	Others   *holdingsAnalysisOthers      `json:"others,omitempty"`
+	// ExcludedCount is how many positions fell below MinWeightPct.
+	ExcludedCount int `json:"excluded_count,omitempty"`
+}
+
+// holdingsAnalysisOthers summarizes positions left out of a condensed snapshot.
+type holdingsAnalysisOthers struct {
+	Count     int     `json:"count"`
+	WeightPct float64 `json:"weight_pct"`
 }
 
 type holdingsAnalysisSymbolItem struct {
 	Symbol    string   `json:"symbol"`
 	WeightPct float64  `json:"weight_pct"`
 	PnLPct    *float64 `json:"pnl_pct,omitempty"`
-	AvgCost   float64  `json:"avg_cost"`
+	AvgCost   float64  `json:"avg_cost,omitempty"`
 }
 
 type holdingsAnalysisPromptInput struct {
@@ -0,0 +1,102 @@
+package investlog
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// cleanupModelJSON extracts the JSON object from model-generated content that
+// may wrap it in prose or markdown code fences. The first fenced block holding
+// a valid object wins, then the first valid balanced {...} anywhere in the
+// text; otherwise it falls back to the span from the first '{' to the last
+// '}' so truncated output still reaches the JSON decoder's error.
+func cleanupModelJSON(content string) string {
+	trimmed := strings.TrimSpace(content)
+	for _, block := range fencedBlocks(trimmed) {
+		if object, ok := firstJSONObject(block); ok {
+			return object
+		}
+	}
+	if object, ok := firstJSONObject(trimmed); ok {
+		return object
+	}
+
+	if strings.HasPrefix(trimmed, "```") {
+		if _, rest, ok := strings.Cut(trimmed, "\n"); ok {
+			trimmed = strings.TrimSuffix(strings.TrimSpace(rest), "```")
+		}
+	}
+	start := strings.Index(trimmed, "{")
+	end := strings.LastIndex(trimmed, "}")
+	if start >= 0 && end > start {
+		trimmed = trimmed[start : end+1]
+	}
+	return strings.TrimSpace(trimmed)
+}
+
+// fencedBlocks returns the contents of the ``` code fences in text, language
+// tag included; an unterminated last fence runs to the end of text.
+func fencedBlocks(text string) []string {
+	parts := strings.Split(text, "```")
+	var blocks []string
+	for i := 1; i < len(parts); i += 2 {
+		blocks = append(blocks, parts[i])
+	}
+	return blocks
+}
+
+// firstJSONObject returns the first balanced top-level {...} in text that is
+// valid JSON. Objects nested in an invalid or unclosed one are not
+// considered, so truncated output is never mistaken for one of its fields.
+func firstJSONObject(text string) (string, bool) {
+	offset := 0
+	for {
+		start := strings.IndexByte(text[offset:], '{')
+		if start < 0 {
+			return "", false
+		}
+		start += offset
+		end := matchingBrace(text, start)
+		if end < 0 {
+			return "", false
+		}
+		if candidate := text[start : end+1]; json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+		offset = end + 1
+	}
+}
+
+// matchingBrace returns the index of the '}' closing the '{' at start,
+// skipping braces inside JSON strings, or -1 when it is never closed.
+func matchingBrace(text string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		ch := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,72 @@
+package investlog
+
+import "testing"
+
+func TestCleanupModelJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "plain object",
+			content: `  {"a":1}  `,
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "single fence",
+			content: "```json\n{\"a\":1}\n```",
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "leading prose",
+			content: "Here is the analysis you asked for:\n{\"a\":{\"b\":2}}",
+			want:    `{"a":{"b":2}}`,
+		},
+		{
+			name:    "trailing notes with braces",
+			content: "{\"a\":1}\n\nNote: values in {braces} are estimates.",
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "braces inside strings",
+			content: `Result: {"summary":"use } and { carefully","n":"a\"}"} done`,
+			want:    `{"summary":"use } and { carefully","n":"a\"}"}`,
+		},
+		{
+			name:    "double fenced takes first JSON block",
+			content: "Example schema:\n```text\nsee below\n```\n```json\n{\"a\":1}\n```\nAlternative:\n```json\n{\"a\":2}\n```",
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "prose with non-JSON braces before object",
+			content: "Use {symbol} placeholders. {\"a\":1}",
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "truncated falls back to outer braces",
+			content: "```json\n{\"a\":{\"b\":1}\n```",
+			want:    `{"a":{"b":1}`,
+		},
+	}
+	for _, tt := range tests {
+		if got := cleanupModelJSON(tt.content); got != tt.want {
+			t.Errorf("%s: cleanupModelJSON() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseHoldingsAnalysisResponse_ProseAndFences(t *testing.T) {
+	t.Parallel()
+
+	content := "好的，以下是分析结果：\n```json\n{\"overall_summary\":\"ok\",\"risk_level\":\"balanced\",\"key_findings\":[\"x\"],\"recommendations\":[],\"disclaimer\":\"d\"}\n```\n说明：以上 {仅供参考}。"
+	parsed, err := parseHoldingsAnalysisResponse(content)
+	if err != nil {
+		t.Fatalf("parseHoldingsAnalysisResponse failed: %v", err)
+	}
+	if parsed.OverallSummary != "ok" || len(parsed.KeyFindings) != 1 {
+		t.Fatalf("unexpected parsed response: %+v", parsed)
+	}
+}
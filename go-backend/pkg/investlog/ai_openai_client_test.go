@@ -2,6 +2,8 @@ package investlog
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -46,3 +48,273 @@ func TestRequestAIByChatCompletions_StreamingDelta(t *testing.T) {
 		t.Fatalf("unexpected streamed deltas: %q", streamed.String())
 	}
 }
+
+func TestRequestAIByChatCompletions_RetriesWithoutMaxTokens(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	var lastPayload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		lastPayload = map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&lastPayload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, ok := lastPayload["max_tokens"]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Unsupported parameter: 'max_tokens' is not supported with this model. Use 'max_completion_tokens' instead."}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"model":"o-model","choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	endpoint := server.URL + "/v1/chat/completions"
+	result, err := requestAIByChatCompletions(context.Background(), aiChatCompletionRequest{
+		EndpointURL:  endpoint,
+		APIKey:       "key",
+		Model:        "o-model",
+		SystemPrompt: "sys",
+		UserPrompt:   "user",
+	}, endpoint)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != "ok" {
+		t.Fatalf("unexpected content: %q", result.Content)
+	}
+	if calls != 2 {
+		t.Fatalf("expected one retry, got %d calls", calls)
+	}
+	if _, ok := lastPayload["max_tokens"]; ok {
+		t.Fatalf("retry should omit max_tokens: %v", lastPayload)
+	}
+	if _, ok := lastPayload["max_completion_tokens"]; !ok {
+		t.Fatalf("retry should keep max_completion_tokens: %v", lastPayload)
+	}
+}
+
+func TestShouldRetryWithoutMaxTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{message: "ai upstream error: Unsupported parameter: 'max_tokens' is not supported with this model. Use 'max_completion_tokens' instead.", want: true},
+		{message: "ai upstream error: max_tokens is not supported", want: true},
+		{message: "ai upstream error: max_completion_tokens is too large", want: false},
+		{message: "ai upstream error: invalid api key", want: false},
+	}
+	for _, tt := range tests {
+		if got := shouldRetryWithoutMaxTokens(errors.New(tt.message)); got != tt.want {
+			t.Fatalf("shouldRetryWithoutMaxTokens(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestRequestAIByChatCompletions_ReportsTruncatedResponse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		content := strings.Repeat("x", 256)
+		_, _ = w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"` + content + `"}}]}`))
+	}))
+	defer server.Close()
+
+	endpoint := server.URL + "/v1/chat/completions"
+	_, err := requestAIByChatCompletions(context.Background(), aiChatCompletionRequest{
+		EndpointURL:      endpoint,
+		APIKey:           "key",
+		Model:            "m",
+		SystemPrompt:     "sys",
+		UserPrompt:       "user",
+		MaxResponseBytes: 64,
+	}, endpoint)
+	if err == nil {
+		t.Fatalf("expected truncation error")
+	}
+	if !strings.Contains(err.Error(), "truncated at 64 bytes") {
+		t.Fatalf("expected explicit truncation error, got %v", err)
+	}
+}
+
+func TestExecuteAIRequest_MapsAuthFailures(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+	}))
+	defer server.Close()
+
+	httpReq, err := http.NewRequest(http.MethodPost, server.URL+"/v1/responses", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	_, err = executeAIRequest(defaultAIHTTPClient, httpReq, nil, maxAIResponseBodySize)
+	if err == nil {
+		t.Fatalf("expected auth error")
+	}
+	if !strings.Contains(err.Error(), "invalid api_key or insufficient permissions (status 401)") {
+		t.Fatalf("expected auth hint, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Incorrect API key provided") {
+		t.Fatalf("expected upstream message preserved, got %v", err)
+	}
+}
+
+func TestRequestAIByChatCompletions_ReasoningModelPayload(t *testing.T) {
+	t.Parallel()
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"o3-mini","choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	endpoint := server.URL + "/v1/chat/completions"
+	if _, err := requestAIByChatCompletions(context.Background(), aiChatCompletionRequest{
+		EndpointURL:  endpoint,
+		APIKey:       "key",
+		Model:        "o3-mini",
+		SystemPrompt: "sys",
+		UserPrompt:   "user",
+	}, endpoint); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := payload["temperature"]; ok {
+		t.Fatalf("reasoning payload should omit temperature: %v", payload)
+	}
+	if _, ok := payload["max_tokens"]; ok {
+		t.Fatalf("reasoning payload should omit max_tokens: %v", payload)
+	}
+	if _, ok := payload["max_completion_tokens"]; !ok {
+		t.Fatalf("reasoning payload should set max_completion_tokens: %v", payload)
+	}
+	messages, _ := payload["messages"].([]any)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %v", payload["messages"])
+	}
+	first, _ := messages[0].(map[string]any)
+	if first["role"] != "developer" || first["content"] != "sys" {
+		t.Fatalf("expected developer instruction message, got %v", first)
+	}
+}
+
+func TestBuildChatCompletionsPayload_DefaultModel(t *testing.T) {
+	t.Parallel()
+
+	payload := buildChatCompletionsPayload(aiChatCompletionRequest{Model: "gpt-4o-mini", SystemPrompt: "sys", UserPrompt: "user"})
+	if payload["temperature"] != 0.2 {
+		t.Fatalf("expected temperature 0.2, got %v", payload["temperature"])
+	}
+	if _, ok := payload["max_tokens"]; !ok {
+		t.Fatalf("expected max_tokens: %v", payload)
+	}
+	messages := payload["messages"].([]map[string]string)
+	if messages[0]["role"] != "system" {
+		t.Fatalf("expected system role, got %q", messages[0]["role"])
+	}
+}
+
+func TestIsReasoningModel(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"o1":            true,
+		"o3-mini":       true,
+		"O4-mini":       true,
+		"openai/o1-pro": true,
+		"gpt-4o":        false,
+		"o-model":       false,
+		"omni":          false,
+		"":              false,
+	}
+	for model, want := range cases {
+		if got := isReasoningModel(model); got != want {
+			t.Errorf("isReasoningModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestNormalizeAIModel_KeepsReasoningModelEverywhere(t *testing.T) {
+	t.Parallel()
+
+	if got := normalizeAIModel(" models/o3-mini "); got != "o3-mini" {
+		t.Fatalf("normalizeAIModel = %q, want o3-mini", got)
+	}
+	if got := normalizeAIModel("  "); got != defaultAIModel {
+		t.Fatalf("normalizeAIModel of empty = %q, want %q", got, defaultAIModel)
+	}
+
+	symbolReq, err := normalizeSymbolAnalysisRequest(SymbolAnalysisRequest{APIKey: "key", Model: "o3-mini", Symbol: "AAPL", Currency: "USD"})
+	if err != nil || symbolReq.Model != "o3-mini" {
+		t.Fatalf("symbol analysis model = %q, %v", symbolReq.Model, err)
+	}
+	allocationReq := AllocationAdviceRequest{APIKey: "key", Model: "o3-mini"}
+	if err := normalizeAllocationAdviceRequest(&allocationReq); err != nil || allocationReq.Model != "o3-mini" {
+		t.Fatalf("allocation advice model = %q, %v", allocationReq.Model, err)
+	}
+	if settings := normalizeAISettings(AISettings{Model: "o3-mini"}); settings.Model != "o3-mini" {
+		t.Fatalf("ai settings model = %q", settings.Model)
+	}
+}
+
+func TestAnalyzeHoldings_ReasoningModelPayload(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-o3", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-o3")
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"o3-mini","choices":[{"message":{"content":"{\"overall_summary\":\"ok\",\"risk_level\":\"balanced\",\"key_findings\":[],\"recommendations\":[],\"disclaimer\":\"d\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	result, err := core.AnalyzeHoldings(HoldingsAnalysisRequest{
+		BaseURL:  server.URL + "/v1",
+		APIKey:   "key",
+		Model:    "o3-mini",
+		Currency: "USD",
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeHoldings failed: %v", err)
+	}
+	if result.Model != "o3-mini" {
+		t.Fatalf("expected o3-mini to be kept, got %q", result.Model)
+	}
+	if payload["model"] != "o3-mini" {
+		t.Fatalf("expected o3-mini in payload, got %v", payload["model"])
+	}
+	if _, ok := payload["temperature"]; ok {
+		t.Fatalf("reasoning payload should omit temperature: %v", payload)
+	}
+	if _, ok := payload["max_completion_tokens"]; !ok {
+		t.Fatalf("reasoning payload should set max_completion_tokens: %v", payload)
+	}
+	messages, _ := payload["messages"].([]any)
+	if len(messages) == 0 {
+		t.Fatalf("expected messages, got %v", payload["messages"])
+	}
+	if first, _ := messages[0].(map[string]any); first["role"] != "developer" {
+		t.Fatalf("expected developer instruction message, got %v", first)
+	}
+}
@@ -0,0 +1,51 @@
+package investlog
+
+import "sort"
+
+// AIEnumOption lists the accepted values of one AI request field and the
+// value used when the field is omitted.
+type AIEnumOption struct {
+	Values  []string `json:"values"`
+	Default string   `json:"default"`
+}
+
+// AIOptions describes the choices AI analysis requests accept, so clients do
+// not have to mirror backend validation.
+type AIOptions struct {
+	RiskProfiles  AIEnumOption `json:"risk_profiles"`
+	Horizons      AIEnumOption `json:"horizons"`
+	AdviceStyles  AIEnumOption `json:"advice_styles"`
+	AnalysisTypes AIEnumOption `json:"analysis_types"`
+	// Currencies are the values accepted as currency; omitting it analyzes
+	// every currency.
+	Currencies []string `json:"currencies"`
+	// TheoryTags is the configured theory_tag vocabulary; the default is the
+	// fallback tag.
+	TheoryTags AIEnumOption `json:"theory_tags"`
+}
+
+// GetAIOptions returns the enum values and defaults used to validate AI
+// analysis requests.
+func (c *Core) GetAIOptions() AIOptions {
+	theoryTags := c.theoryTagVocabulary()
+	return AIOptions{
+		RiskProfiles:  newAIEnumOption(validAIRiskProfiles, defaultAISettingsRiskProfile),
+		Horizons:      newAIEnumOption(validAIHorizons, defaultAISettingsHorizon),
+		AdviceStyles:  newAIEnumOption(validAIAdviceStyles, defaultAISettingsAdviceStyle),
+		AnalysisTypes: newAIEnumOption(validAIAnalysisTypes, defaultAIAnalysisType),
+		Currencies:    append([]string(nil), Currencies...),
+		TheoryTags: AIEnumOption{
+			Values:  append([]string(nil), theoryTags...),
+			Default: theoryTags[0],
+		},
+	}
+}
+
+func newAIEnumOption(allowed map[string]struct{}, fallback string) AIEnumOption {
+	values := make([]string, 0, len(allowed))
+	for value := range allowed {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return AIEnumOption{Values: values, Default: fallback}
+}
@@ -0,0 +1,46 @@
+package investlog
+
+import "testing"
+
+func TestAIOptions_ValuesPassNormalization(t *testing.T) {
+	t.Parallel()
+
+	base := HoldingsAnalysisRequest{APIKey: "k", Model: "gemini-2.5-flash"}
+	fields := []struct {
+		name   string
+		option AIEnumOption
+		set    func(*HoldingsAnalysisRequest, string)
+	}{
+		{"risk_profile", newAIEnumOption(validAIRiskProfiles, defaultAISettingsRiskProfile), func(r *HoldingsAnalysisRequest, v string) { r.RiskProfile = v }},
+		{"horizon", newAIEnumOption(validAIHorizons, defaultAISettingsHorizon), func(r *HoldingsAnalysisRequest, v string) { r.Horizon = v }},
+		{"advice_style", newAIEnumOption(validAIAdviceStyles, defaultAISettingsAdviceStyle), func(r *HoldingsAnalysisRequest, v string) { r.AdviceStyle = v }},
+		{"analysis_type", newAIEnumOption(validAIAnalysisTypes, defaultAIAnalysisType), func(r *HoldingsAnalysisRequest, v string) { r.AnalysisType = v }},
+	}
+	defaults, err := normalizeHoldingsAnalysisRequest(base)
+	if err != nil {
+		t.Fatalf("normalize defaults: %v", err)
+	}
+	defaultOf := map[string]string{
+		"risk_profile":  defaults.RiskProfile,
+		"horizon":       defaults.Horizon,
+		"advice_style":  defaults.AdviceStyle,
+		"analysis_type": defaults.AnalysisType,
+	}
+	for _, field := range fields {
+		if field.option.Default != defaultOf[field.name] {
+			t.Errorf("%s: option default %q, normalization default %q", field.name, field.option.Default, defaultOf[field.name])
+		}
+		for _, value := range field.option.Values {
+			req := base
+			field.set(&req, value)
+			if _, err := normalizeHoldingsAnalysisRequest(req); err != nil {
+				t.Errorf("%s=%s rejected: %v", field.name, value, err)
+			}
+		}
+		req := base
+		field.set(&req, "bogus")
+		if _, err := normalizeHoldingsAnalysisRequest(req); err == nil {
+			t.Errorf("%s=bogus accepted", field.name)
+		}
+	}
+}
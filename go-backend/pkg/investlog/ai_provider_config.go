@@ -3,26 +3,92 @@ package investlog
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 const defaultAIModel = "gemini-2.5-flash"
 
+const (
+	defaultAITemperature = 0.2
+	maxAITemperature     = 2.0
+	// maxAIThinkingBudget is the largest thinking budget Gemini accepts
+	// (gemini-2.5-pro); -1 asks for a dynamic budget.
+	maxAIThinkingBudget = 32768
+)
+
 func isGeminiModel(model string) bool {
 	trimmed := strings.TrimSpace(strings.TrimPrefix(model, "models/"))
 	return strings.HasPrefix(strings.ToLower(trimmed), "gemini")
 }
 
+// isReasoningModel reports whether model belongs to the OpenAI o-series
+// (o1, o3, o4-mini, ...), which rejects temperature and max_tokens and expects
+// a developer message instead of a system message. A vendor prefix such as
+// "openai/" is ignored.
+func isReasoningModel(model string) bool {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return len(name) >= 2 && name[0] == 'o' && name[1] >= '0' && name[1] <= '9'
+}
+
+// geminiSupportsThinking reports whether model accepts a thinkingConfig,
+// i.e. Gemini 2.5 and later.
+func geminiSupportsThinking(model string) bool {
+	name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(model, "models/")))
+	if !strings.HasPrefix(name, "gemini-") {
+		return false
+	}
+	version := strings.TrimPrefix(name, "gemini-")
+	if idx := strings.IndexByte(version, '-'); idx >= 0 {
+		version = version[:idx]
+	}
+	majorText, minorText, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorText)
+	if err != nil {
+		return false
+	}
+	minor, _ := strconv.Atoi(minorText)
+	return major > 2 || (major == 2 && minor >= 5)
+}
+
+// validateAIGenerationConfig checks the optional sampling overrides shared by
+// every provider path.
+func validateAIGenerationConfig(temperature *float64, thinkingBudget *int) error {
+	invalid := &ValidationError{}
+	if temperature != nil && (*temperature < 0 || *temperature > maxAITemperature) {
+		invalid.Add("temperature", fmt.Sprintf("temperature must be between 0 and %g", maxAITemperature))
+	}
+	if thinkingBudget != nil && (*thinkingBudget < -1 || *thinkingBudget > maxAIThinkingBudget) {
+		invalid.Add("thinking_budget", fmt.Sprintf("thinking_budget must be -1 (dynamic) or between 0 and %d", maxAIThinkingBudget))
+	}
+	return invalid.Err()
+}
+
+// normalizeAIModel trims a model name and its "models/" prefix, defaulting
+// to defaultAIModel when empty. It does not force Gemini, so requests aimed
+// at an OpenAI compatible base URL keep their model (and reasoning models get
+// their dedicated payload). Every AI entry point normalizes through it.
 func normalizeAIModel(model string) string {
-	trimmed := strings.TrimSpace(strings.TrimPrefix(model, "models/"))
-	if !isGeminiModel(trimmed) {
+	trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(model), "models/"))
+	if trimmed == "" {
 		return defaultAIModel
 	}
 	return trimmed
 }
 
-func normalizeAIModelName(model string) string {
-	return normalizeAIModel(model)
+// validateAIAPIKey rejects keys that cannot be real credentials, usually a
+// bad copy-paste, before they are sent upstream and come back as an opaque 401.
+func validateAIAPIKey(key string) error {
+	for _, r := range key {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return NewValidationError("api_key", "api_key must not contain whitespace or control characters")
+		}
+	}
+	return nil
 }
 
 func normalizeAIBaseURL(baseURL string) string {
@@ -0,0 +1,98 @@
+package investlog
+
+import (
+	"net/http"
+	"sort"
+)
+
+// aiResponseSchema constrains model output to a JSON schema: sent as a
+// strict json_schema response_format on chat completions and as
+// responseSchema on Gemini.
+type aiResponseSchema struct {
+	Name   string
+	Schema map[string]any
+}
+
+// chatResponseFormat returns the OpenAI-compatible response_format value.
+func (s *aiResponseSchema) chatResponseFormat() map[string]any {
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   s.Name,
+			"strict": true,
+			"schema": s.Schema,
+		},
+	}
+}
+
+// geminiSchema returns the schema without additionalProperties, which
+// Gemini's OpenAPI-subset schema rejects.
+func (s *aiResponseSchema) geminiSchema() map[string]any {
+	return stripSchemaKey(s.Schema, "additionalProperties")
+}
+
+func stripSchemaKey(schema map[string]any, key string) map[string]any {
+	result := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if k == key {
+			continue
+		}
+		switch typed := v.(type) {
+		case map[string]any:
+			result[k] = stripSchemaKey(typed, key)
+		default:
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// shouldRetryWithoutResponseSchema reports whether a failed status may mean
+// the provider does not support the schema, so the prompt-only request is
+// worth a try.
+func shouldRetryWithoutResponseSchema(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusUnprocessableEntity
+}
+
+func schemaString() map[string]any {
+	return map[string]any{"type": "string"}
+}
+
+// schemaObject builds a strict object schema requiring every property.
+func schemaObject(properties map[string]any) map[string]any {
+	required := make([]string, 0, len(properties))
+	for name := range properties {
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// holdingsAnalysisResponseSchema describes holdingsAnalysisModelResponse.
+// Strict mode needs every field required, so optional recommendation
+// fields are returned as empty strings.
+func holdingsAnalysisResponseSchema() *aiResponseSchema {
+	recommendation := schemaObject(map[string]any{
+		"symbol":        schemaString(),
+		"action":        schemaString(),
+		"theory_tag":    schemaString(),
+		"rationale":     schemaString(),
+		"target_weight": schemaString(),
+		"priority":      schemaString(),
+	})
+	return &aiResponseSchema{
+		Name: "holdings_analysis",
+		Schema: schemaObject(map[string]any{
+			"overall_summary": schemaString(),
+			"risk_level":      schemaString(),
+			"key_findings":    map[string]any{"type": "array", "items": schemaString()},
+			"recommendations": map[string]any{"type": "array", "items": recommendation},
+			"disclaimer":      schemaString(),
+		}),
+	}
+}
@@ -0,0 +1,135 @@
+package investlog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBuildChatCompletionsPayload_ResponseSchema(t *testing.T) {
+	req := aiChatCompletionRequest{Model: "gpt-4o", SystemPrompt: "sys", UserPrompt: "user"}
+	if _, ok := buildChatCompletionsPayload(req)["response_format"]; ok {
+		t.Fatal("expected no response_format without a schema")
+	}
+
+	req.ResponseSchema = holdingsAnalysisResponseSchema()
+	format, ok := buildChatCompletionsPayload(req)["response_format"].(map[string]any)
+	if !ok || format["type"] != "json_schema" {
+		t.Fatalf("expected json_schema response_format, got %#v", format)
+	}
+	jsonSchema := format["json_schema"].(map[string]any)
+	if jsonSchema["name"] != "holdings_analysis" || jsonSchema["strict"] != true {
+		t.Fatalf("unexpected json_schema: %#v", jsonSchema)
+	}
+	schema := jsonSchema["schema"].(map[string]any)
+	required := schema["required"].([]string)
+	if strings.Join(required, ",") != "disclaimer,key_findings,overall_summary,recommendations,risk_level" {
+		t.Fatalf("unexpected required fields: %v", required)
+	}
+	if schema["additionalProperties"] != false {
+		t.Fatalf("expected strict object schema, got %#v", schema)
+	}
+}
+
+func TestBuildGeminiStreamPayload_ResponseSchema(t *testing.T) {
+	req := aiChatCompletionRequest{
+		Model:          "gemini-2.5-flash",
+		UserPrompt:     "user",
+		ResponseSchema: holdingsAnalysisResponseSchema(),
+	}
+	payload := buildGeminiStreamPayload(req)
+	config := payload["generationConfig"].(map[string]any)
+	if config["responseMimeType"] != "application/json" {
+		t.Fatalf("expected JSON MIME type, got %#v", config)
+	}
+	schema, ok := config["responseSchema"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected responseSchema, got %#v", config)
+	}
+	body, _ := json.Marshal(schema)
+	if strings.Contains(string(body), "additionalProperties") {
+		t.Fatalf("expected additionalProperties stripped for gemini: %s", body)
+	}
+	if !strings.Contains(string(body), "theory_tag") {
+		t.Fatalf("expected nested recommendation schema: %s", body)
+	}
+	if _, ok := payload["tools"]; ok {
+		t.Fatal("expected tools omitted alongside a response schema")
+	}
+}
+
+func TestRequestAIByChatCompletions_RetriesWithoutRejectedSchema(t *testing.T) {
+	var calls int32
+	var lastHadSchema bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, lastHadSchema = body["response_format"]
+		if lastHadSchema {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"response_format json_schema is not supported"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"{\"overall_summary\":\"ok\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	result, err := requestAIByChatCompletions(context.Background(), aiChatCompletionRequest{
+		EndpointURL:    server.URL,
+		APIKey:         "key",
+		Model:          "m",
+		SystemPrompt:   "sys",
+		UserPrompt:     "user",
+		ResponseSchema: holdingsAnalysisResponseSchema(),
+	}, server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || lastHadSchema {
+		t.Fatalf("expected one prompt-only retry, got %d calls (last with schema: %v)", calls, lastHadSchema)
+	}
+	if !strings.Contains(result.Content, "overall_summary") {
+		t.Fatalf("unexpected content: %q", result.Content)
+	}
+}
+
+func TestAnalyzeHoldings_StructuredOutputSendsSchema(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-schema", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-schema")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	var schema *aiResponseSchema
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		schema = req.ResponseSchema
+		return aiChatCompletionResult{
+			Model:   "mock-model",
+			Content: `{"overall_summary":"ok","risk_level":"balanced","key_findings":[],"recommendations":[],"disclaimer":"仅供参考"}`,
+		}, nil
+	}
+
+	req := HoldingsAnalysisRequest{BaseURL: "https://example.com/v1", APIKey: "k", Model: "mock-model", Currency: "USD"}
+	if _, err := core.AnalyzeHoldings(req); err != nil {
+		t.Fatalf("AnalyzeHoldings failed: %v", err)
+	}
+	if schema != nil {
+		t.Fatal("expected no schema unless structured output is requested")
+	}
+	req.StructuredOutput = true
+	if _, err := core.AnalyzeHoldings(req); err != nil {
+		t.Fatalf("AnalyzeHoldings with structured output failed: %v", err)
+	}
+	if schema == nil || schema.Name != "holdings_analysis" {
+		t.Fatalf("expected holdings analysis schema, got %+v", schema)
+	}
+}
@@ -0,0 +1,69 @@
+package investlog
+
+import (
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// AIResponseSchemas returns JSON schemas describing the objects the model is
+// expected to return, keyed by response kind. They are derived from the Go
+// structs used to decode model output, so they stay in sync with parsing:
+// fields without omitempty are required.
+func AIResponseSchemas() map[string]map[string]any {
+	schemas := map[string]map[string]any{
+		"holdings_analysis": jsonSchemaFor(reflect.TypeOf(holdingsAnalysisModelResponse{})),
+		"symbol_dimension":  jsonSchemaFor(reflect.TypeOf(SymbolDimensionResult{})),
+		"symbol_synthesis":  jsonSchemaFor(reflect.TypeOf(SymbolSynthesisResult{})),
+	}
+	for name, schema := range schemas {
+		schema["$schema"] = jsonSchemaDraft
+		schema["title"] = name
+	}
+	return schemas
+}
+
+func jsonSchemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaFor(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = jsonSchemaFor(field.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]any{"type": "object", "properties": properties, "required": required}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,49 @@
+package investlog
+
+import "testing"
+
+func TestAIResponseSchemas(t *testing.T) {
+	schemas := AIResponseSchemas()
+
+	tests := []struct {
+		name        string
+		required    []string
+		notRequired []string
+	}{
+		{name: "holdings_analysis", required: []string{"overall_summary", "risk_level", "recommendations"}},
+		{name: "symbol_dimension", required: []string{"dimension", "rating", "summary"}, notRequired: []string{"suggestion"}},
+		{name: "symbol_synthesis", required: []string{"overall_rating", "target_action", "action_items"}, notRequired: []string{"action_probability_percent"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, ok := schemas[tt.name]
+			if !ok {
+				t.Fatalf("missing schema %s", tt.name)
+			}
+			if schema["type"] != "object" {
+				t.Fatalf("expected object schema, got %v", schema["type"])
+			}
+			required, _ := schema["required"].([]string)
+			for _, field := range tt.required {
+				if !contains(required, field) {
+					t.Fatalf("expected %s to be required, got %v", field, required)
+				}
+			}
+			for _, field := range tt.notRequired {
+				if contains(required, field) {
+					t.Fatalf("expected %s to be optional", field)
+				}
+			}
+		})
+	}
+
+	recs := schemas["holdings_analysis"]["properties"].(map[string]any)["recommendations"].(map[string]any)
+	if recs["type"] != "array" {
+		t.Fatalf("expected recommendations array, got %v", recs["type"])
+	}
+	item := recs["items"].(map[string]any)
+	itemRequired, _ := item["required"].([]string)
+	if !contains(itemRequired, "action") || contains(itemRequired, "symbol") {
+		t.Fatalf("unexpected recommendation required fields: %v", itemRequired)
+	}
+}
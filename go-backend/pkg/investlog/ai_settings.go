@@ -2,6 +2,8 @@ package investlog
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -11,8 +13,13 @@ const (
 	defaultAISettingsRiskProfile = "balanced"
 	defaultAISettingsHorizon     = "medium"
 	defaultAISettingsAdviceStyle = "balanced"
+	defaultAIAnalysisType        = "adhoc"
 )
 
+// defaultTheoryTags is the theory_tag vocabulary the holdings prompt was
+// written around; the first entry is the fallback tag.
+var defaultTheoryTags = []string{"Malkiel", "Dalio", "Buffett"}
+
 var validAIRiskProfiles = map[string]struct{}{
 	"conservative": {},
 	"balanced":     {},
@@ -31,6 +38,12 @@ var validAIAdviceStyles = map[string]struct{}{
 	"aggressive":   {},
 }
 
+var validAIAnalysisTypes = map[string]struct{}{
+	"adhoc":   {},
+	"weekly":  {},
+	"monthly": {},
+}
+
 func defaultAISettings() AISettings {
 	return AISettings{
 		BaseURL:         defaultAISettingsBaseURL,
@@ -40,9 +53,45 @@ func defaultAISettings() AISettings {
 		AdviceStyle:     defaultAISettingsAdviceStyle,
 		AllowNewSymbols: true,
 		StrategyPrompt:  "",
+		TheoryTags:      append([]string(nil), defaultTheoryTags...),
 	}
 }
 
+// normalizeTheoryTags trims tags and drops blanks and case-insensitive
+// duplicates, keeping the first spelling.
+func normalizeTheoryTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		trimmed := strings.TrimSpace(tag)
+		key := strings.ToLower(trimmed)
+		if trimmed == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, trimmed)
+	}
+	return result
+}
+
+// matchTheoryTag maps a model-supplied tag onto vocabulary, returning the
+// first entry for blank or unknown tags.
+func matchTheoryTag(tag string, vocabulary []string) string {
+	tag = strings.TrimSpace(tag)
+	if len(vocabulary) == 0 {
+		return tag
+	}
+	for _, allowed := range vocabulary {
+		if strings.EqualFold(allowed, tag) {
+			return allowed
+		}
+	}
+	return vocabulary[0]
+}
+
 func trimTrailingSlash(value string) string {
 	trimmed := strings.TrimSpace(value)
 	return strings.TrimRight(trimmed, "/")
@@ -57,6 +106,10 @@ func normalizeAISettings(settings AISettings) AISettings {
 	normalized.AdviceStyle = strings.ToLower(strings.TrimSpace(normalized.AdviceStyle))
 	normalized.StrategyPrompt = strings.TrimSpace(normalized.StrategyPrompt)
 	normalized.APIKey = strings.TrimSpace(normalized.APIKey)
+	normalized.TheoryTags = normalizeTheoryTags(normalized.TheoryTags)
+	if len(normalized.TheoryTags) == 0 {
+		normalized.TheoryTags = append([]string(nil), defaultTheoryTags...)
+	}
 
 	if _, ok := validAIRiskProfiles[normalized.RiskProfile]; !ok {
 		normalized.RiskProfile = defaultAISettingsRiskProfile
@@ -70,13 +123,40 @@ func normalizeAISettings(settings AISettings) AISettings {
 	return normalized
 }
 
-// GetAISettings returns persisted AI settings.
+// requireAISettingsConfigured reports a SETUP_REQUIRED error when saved settings
+// cannot be used yet, so callers can tell "not configured" apart from upstream
+// or storage failures.
+func requireAISettingsConfigured(settings AISettings) error {
+	if strings.TrimSpace(settings.APIKey) == "" {
+		return NewError(ErrCodeSetupRequired, "AI API key is not configured yet; save one in AI settings")
+	}
+	if strings.TrimSpace(settings.Model) == "" {
+		return NewError(ErrCodeSetupRequired, "AI model is not configured yet; save one in AI settings")
+	}
+	return nil
+}
+
+// CheckAISetup reports a SETUP_REQUIRED error when the saved AI settings,
+// with the server key as a fallback, cannot run an analysis yet. Streaming
+// handlers call it before the response starts.
+func (c *Core) CheckAISetup() error {
+	settings, err := c.GetAISettings()
+	if err != nil {
+		return err
+	}
+	settings.APIKey = c.resolveAIAPIKey(settings.APIKey)
+	return requireAISettingsConfigured(settings)
+}
+
+// GetAISettings returns persisted AI settings. An empty stored model (the
+// column default for rows written by older versions) resolves to the default model.
 func (c *Core) GetAISettings() (AISettings, error) {
 	settings := defaultAISettings()
 	var allowNewSymbols int
+	var tagsJSON string
 
 	err := c.db.QueryRow(`
-		SELECT base_url, model, risk_profile, horizon, advice_style, allow_new_symbols, strategy_prompt, api_key
+		SELECT base_url, model, risk_profile, horizon, advice_style, allow_new_symbols, strategy_prompt, api_key, theory_tags
 		FROM ai_settings
 		WHERE id = 1
 	`).Scan(
@@ -88,6 +168,7 @@ func (c *Core) GetAISettings() (AISettings, error) {
 		&allowNewSymbols,
 		&settings.StrategyPrompt,
 		&settings.APIKey,
+		&tagsJSON,
 	)
 	if err == sql.ErrNoRows {
 		return settings, nil
@@ -96,6 +177,12 @@ func (c *Core) GetAISettings() (AISettings, error) {
 		return AISettings{}, err
 	}
 	settings.AllowNewSymbols = allowNewSymbols != 0
+	settings.TheoryTags = nil
+	if strings.TrimSpace(tagsJSON) != "" {
+		if err := json.Unmarshal([]byte(tagsJSON), &settings.TheoryTags); err != nil {
+			return AISettings{}, fmt.Errorf("decode theory tags: %w", err)
+		}
+	}
 	return normalizeAISettings(settings), nil
 }
 
@@ -106,12 +193,16 @@ func (c *Core) SetAISettings(settings AISettings) (AISettings, error) {
 	if normalized.AllowNewSymbols {
 		allowNewSymbols = 1
 	}
+	tagsJSON, err := json.Marshal(normalized.TheoryTags)
+	if err != nil {
+		return AISettings{}, fmt.Errorf("encode theory tags: %w", err)
+	}
 
-	_, err := c.db.Exec(`
+	_, err = c.db.Exec(`
 		INSERT INTO ai_settings (
-			id, base_url, model, risk_profile, horizon, advice_style, allow_new_symbols, strategy_prompt, api_key, updated_at
+			id, base_url, model, risk_profile, horizon, advice_style, allow_new_symbols, strategy_prompt, api_key, theory_tags, updated_at
 		)
-		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(id) DO UPDATE SET
 			base_url = excluded.base_url,
 			model = excluded.model,
@@ -121,10 +212,22 @@ func (c *Core) SetAISettings(settings AISettings) (AISettings, error) {
 			allow_new_symbols = excluded.allow_new_symbols,
 			strategy_prompt = excluded.strategy_prompt,
 			api_key = excluded.api_key,
+			theory_tags = excluded.theory_tags,
 			updated_at = CURRENT_TIMESTAMP
-	`, normalized.BaseURL, normalized.Model, normalized.RiskProfile, normalized.Horizon, normalized.AdviceStyle, allowNewSymbols, normalized.StrategyPrompt, normalized.APIKey)
+	`, normalized.BaseURL, normalized.Model, normalized.RiskProfile, normalized.Horizon, normalized.AdviceStyle, allowNewSymbols, normalized.StrategyPrompt, normalized.APIKey, string(tagsJSON))
 	if err != nil {
 		return AISettings{}, err
 	}
 	return normalized, nil
 }
+
+// theoryTagVocabulary returns the configured theory tags, falling back to the
+// defaults when settings cannot be read so an analysis never fails on them.
+func (c *Core) theoryTagVocabulary() []string {
+	settings, err := c.GetAISettings()
+	if err != nil {
+		c.Logger().Warn("load theory tags failed, using defaults", "err", err)
+		return defaultTheoryTags
+	}
+	return settings.TheoryTags
+}
@@ -1,6 +1,11 @@
 package investlog
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestGetAISettingsDefaults(t *testing.T) {
 	core, cleanup := setupTestDB(t)
@@ -72,7 +77,7 @@ func TestSetAISettingsPersistsAndNormalizes(t *testing.T) {
 	loaded, err := core.GetAISettings()
 	assertNoError(t, err, "get ai settings")
 
-	if loaded != saved {
+	if !reflect.DeepEqual(loaded, saved) {
 		t.Fatalf("loaded settings mismatch: got %+v, want %+v", loaded, saved)
 	}
 }
@@ -95,8 +100,8 @@ func TestSetAISettingsInvalidEnumsFallbackToDefault(t *testing.T) {
 	if saved.BaseURL != "https://api.aicodemirror.com/api/gemini" {
 		t.Fatalf("expected default base url, got %q", saved.BaseURL)
 	}
-	if saved.Model != "gemini-2.5-flash" {
-		t.Fatalf("expected migrated default model, got %q", saved.Model)
+	if saved.Model != "gpt-4o-mini" {
+		t.Fatalf("expected model kept as given, got %q", saved.Model)
 	}
 	if saved.RiskProfile != "balanced" {
 		t.Fatalf("expected default risk profile, got %q", saved.RiskProfile)
@@ -123,8 +128,8 @@ func TestSetAISettingsMigratesLegacyProviderConfig(t *testing.T) {
 	if saved.BaseURL != "https://api.aicodemirror.com/api/gemini" {
 		t.Fatalf("unexpected migrated base url: %q", saved.BaseURL)
 	}
-	if saved.Model != "gemini-2.5-flash" {
-		t.Fatalf("unexpected migrated model: %q", saved.Model)
+	if saved.Model != "sonar-pro" {
+		t.Fatalf("expected trimmed model kept, got %q", saved.Model)
 	}
 	if saved.APIKey != "key" {
 		t.Fatalf("unexpected trimmed api key: %q", saved.APIKey)
@@ -168,3 +173,134 @@ func TestAISettingsMethodsWhenDBClosed(t *testing.T) {
 		t.Fatal("expected SetAISettings error on closed db")
 	}
 }
+
+func TestGetAISettingsEmptyStoredModelUsesDefault(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := core.SetAISettings(AISettings{APIKey: "key"})
+	assertNoError(t, err, "set ai settings")
+	_, err = core.db.Exec(`UPDATE ai_settings SET model = '' WHERE id = 1`)
+	assertNoError(t, err, "clear stored model")
+
+	settings, err := core.GetAISettings()
+	assertNoError(t, err, "get ai settings")
+	if settings.Model != defaultAIModel {
+		t.Fatalf("expected default model for empty stored model, got %q", settings.Model)
+	}
+}
+
+func TestRequireAISettingsConfigured(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings AISettings
+		wantErr  bool
+	}{
+		{name: "configured", settings: AISettings{Model: defaultAIModel, APIKey: "key"}},
+		{name: "empty model", settings: AISettings{Model: " ", APIKey: "key"}, wantErr: true},
+		{name: "empty api key", settings: AISettings{Model: defaultAIModel}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireAISettingsConfigured(tt.settings)
+			if !tt.wantErr {
+				assertNoError(t, err, "require configured")
+				return
+			}
+			if !IsErrorCode(err, ErrCodeSetupRequired) {
+				t.Fatalf("expected SETUP_REQUIRED error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunAIAnalysisWithoutAPIKeyRequiresSetup(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	method, err := core.CreateAIAnalysisMethod(AIAnalysisMethod{
+		Name:         "速览",
+		SystemPrompt: "你是研究员",
+		UserPrompt:   "请分析",
+	})
+	assertNoError(t, err, "create method")
+
+	_, err = core.RunAIAnalysis(RunAIAnalysisRequest{MethodID: method.ID})
+	if !IsErrorCode(err, ErrCodeSetupRequired) {
+		t.Fatalf("expected SETUP_REQUIRED error, got %v", err)
+	}
+}
+
+func TestAISettingsTheoryTagsFlowIntoHoldingsAnalysis(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	defaults, err := core.GetAISettings()
+	assertNoError(t, err, "get defaults")
+	if !reflect.DeepEqual(defaults.TheoryTags, []string{"Malkiel", "Dalio", "Buffett"}) {
+		t.Fatalf("unexpected default theory tags: %v", defaults.TheoryTags)
+	}
+
+	saved, err := core.SetAISettings(AISettings{TheoryTags: []string{" Graham ", "Lynch", "graham", ""}})
+	assertNoError(t, err, "set theory tags")
+	if !reflect.DeepEqual(saved.TheoryTags, []string{"Graham", "Lynch"}) {
+		t.Fatalf("unexpected normalized theory tags: %v", saved.TheoryTags)
+	}
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+
+	var systemPrompt string
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		systemPrompt = req.SystemPrompt
+		return aiChatCompletionResult{
+			Model: "mock-model",
+			Content: `{
+				"overall_summary":"ok",
+				"risk_level":"balanced",
+				"key_findings":[],
+				"recommendations":[
+					{"symbol":"AAPL","action":"hold","theory_tag":"lynch","rationale":"r"},
+					{"symbol":"AAPL","action":"hold","theory_tag":"Buffett","rationale":"r"},
+					{"symbol":"AAPL","action":"hold","theory_tag":"","rationale":"r"}
+				],
+				"disclaimer":"d"
+			}`,
+		}, nil
+	}
+
+	result, err := core.AnalyzeHoldings(HoldingsAnalysisRequest{APIKey: "key", Model: "mock-model", Currency: "USD"})
+	assertNoError(t, err, "analyze holdings")
+
+	if !strings.Contains(systemPrompt, "theory_tag 只能取以下值之一：Graham/Lynch。") {
+		t.Fatalf("expected configured vocabulary in system prompt, got: %s", systemPrompt)
+	}
+	var got []string
+	for _, rec := range result.Recommendations {
+		got = append(got, rec.TheoryTag)
+	}
+	if !reflect.DeepEqual(got, []string{"Lynch", "Graham", "Graham"}) {
+		t.Fatalf("expected tags mapped onto Graham/Lynch, got %v", got)
+	}
+}
+
+func TestMatchTheoryTag(t *testing.T) {
+	vocabulary := []string{"Graham", "Lynch"}
+	tests := map[string]string{
+		"lynch":   "Lynch",
+		" Graham": "Graham",
+		"Malkiel": "Graham",
+		"":        "Graham",
+	}
+	for tag, want := range tests {
+		if got := matchTheoryTag(tag, vocabulary); got != want {
+			t.Fatalf("matchTheoryTag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+	if got := holdingsAnalysisSystemPromptFor(vocabulary); strings.Contains(got, "{{theory_tags}}") || !strings.Contains(got, "Graham/Lynch") {
+		t.Fatalf("expected vocabulary substituted into system prompt, got: %s", got)
+	}
+}
@@ -3,6 +3,7 @@ package investlog
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -25,6 +26,7 @@ func (c *Core) runDimensionAgents(
 	frameworks []symbolFrameworkSpec,
 	userPrompt string,
 	onDelta func(string),
+	onProgress func(SymbolAnalysisProgress),
 ) (map[string]string, error) {
 	if len(frameworks) < minFrameworkAnalyses {
 		return nil, fmt.Errorf("selected frameworks less than %d", minFrameworkAnalyses)
@@ -38,20 +40,29 @@ func (c *Core) runDimensionAgents(
 		})
 	}
 
+	// The agents are admitted together: running only some of them would
+	// still leave the analysis short of frameworks.
+	agentCtx, release, err := c.acquireAISlots(ctx, len(agents))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	ch := make(chan agentResult, len(agents))
 	var wg sync.WaitGroup
+	logger := c.analysisLogger(ctx)
 
 	for _, a := range agents {
 		wg.Add(1)
 		go func(frameworkID, sysPrompt string) {
 			defer wg.Done()
-			res, err := aiChatCompletion(ctx, aiChatCompletionRequest{
+			res, err := c.chatCompletion(agentCtx, aiChatCompletionRequest{
 				EndpointURL:  endpoint,
 				APIKey:       apiKey,
 				Model:        model,
 				SystemPrompt: sysPrompt,
 				UserPrompt:   userPrompt,
-				Logger:       c.Logger(),
+				Logger:       logger.With("framework", frameworkID),
 				OnDelta: func(delta string) {
 					delta = strings.TrimSpace(delta)
 					if delta == "" || onDelta == nil {
@@ -74,22 +85,42 @@ func (c *Core) runDimensionAgents(
 	}()
 
 	outputs := make(map[string]string, len(agents))
-	var errs []string
+	var errs []error
+	returned := 0
 	for r := range ch {
+		returned++
 		if r.Error != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", r.FrameworkID, r.Error))
+			errs = append(errs, fmt.Errorf("%s: %w", r.FrameworkID, r.Error))
+			if onProgress != nil {
+				onProgress(SymbolAnalysisProgress{
+					Stage:     SymbolAnalysisStageDimensionFailed,
+					Dimension: r.FrameworkID,
+					Completed: returned,
+					Total:     len(agents),
+					Message:   fmt.Sprintf("维度分析失败：%s", r.FrameworkID),
+				})
+			}
 			continue
 		}
 		outputs[r.FrameworkID] = r.Content
+		if onProgress != nil {
+			onProgress(SymbolAnalysisProgress{
+				Stage:     SymbolAnalysisStageDimensionDone,
+				Dimension: r.FrameworkID,
+				Completed: returned,
+				Total:     len(agents),
+				Message:   fmt.Sprintf("维度分析完成：%s", r.FrameworkID),
+			})
+		}
 	}
 
 	if len(outputs) < minFrameworkAnalyses {
-		return nil, fmt.Errorf("framework analyses insufficient (%d/%d): %s", len(outputs), len(agents), strings.Join(errs, "; "))
+		return nil, fmt.Errorf("framework analyses insufficient (%d/%d): %w", len(outputs), len(agents), errors.Join(errs...))
 	}
 	return outputs, nil
 }
 
-func runSynthesisAgent(
+func (c *Core) runSynthesisAgent(
 	ctx context.Context,
 	endpoint, apiKey, model, symbolContext string,
 	frameworkOutputs map[string]string,
@@ -130,12 +161,13 @@ func runSynthesisAgent(
 3) 必须明确给出当前仓位占比、目标配置区间、差值。
 4) 禁止“看情况/视情况/it depends”。`, symbolContext, string(frameworkIDsJSON), string(frameworkJSON), string(weightJSON))
 
-	result, err := aiChatCompletion(ctx, aiChatCompletionRequest{
+	result, err := c.chatCompletion(ctx, aiChatCompletionRequest{
 		EndpointURL:  endpoint,
 		APIKey:       apiKey,
 		Model:        model,
 		SystemPrompt: symbolSynthesisSystemPrompt,
 		UserPrompt:   userPrompt,
+		Logger:       c.analysisLogger(ctx),
 		OnDelta: func(delta string) {
 			delta = strings.TrimSpace(delta)
 			if delta == "" || onDelta == nil {
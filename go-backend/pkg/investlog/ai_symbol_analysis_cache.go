@@ -0,0 +1,88 @@
+package investlog
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const defaultSymbolAnalysisCacheTTL = 6 * time.Hour
+
+// symbolAnalysisInputHash fingerprints everything that feeds the framework and
+// synthesis agents, so an unchanged position, preference set and external
+// data can reuse the previous result. It covers the raw external sections
+// rather than the model-written summary, which differs between runs and is
+// only produced after the cache is checked.
+func symbolAnalysisInputHash(contextData *symbolContextData, req SymbolAnalysisRequest, externalSections []ExternalDataSection) (string, error) {
+	data, err := json.Marshal(struct {
+		Context     *symbolContextData      `json:"context"`
+		Model       string                  `json:"model"`
+		Preferences symbolPreferenceContext `json:"preferences"`
+		External    []ExternalDataSection   `json:"external"`
+	}{
+		Context: contextData,
+		Model:   req.Model,
+		Preferences: symbolPreferenceContext{
+			RiskProfile:    req.RiskProfile,
+			Horizon:        req.Horizon,
+			AdviceStyle:    req.AdviceStyle,
+			StrategyPrompt: req.StrategyPrompt,
+		},
+		External: externalSections,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal symbol analysis inputs: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// findCachedSymbolAnalysis returns the newest completed analysis with the same
+// input hash that finished within the cache TTL, or nil.
+func (c *Core) findCachedSymbolAnalysis(symbol, currency, inputHash string) (*SymbolAnalysisResult, error) {
+	var (
+		id               int64
+		model, status    string
+		macroRaw         sql.NullString
+		industryRaw      sql.NullString
+		companyRaw       sql.NullString
+		internationalRaw sql.NullString
+		synthesisRaw     sql.NullString
+		errorMessage     sql.NullString
+		createdAt        string
+		completedAtRaw   sql.NullString
+	)
+	err := c.db.QueryRow(
+		`SELECT id, model, status, macro_analysis, industry_analysis, company_analysis, international_analysis,
+		        synthesis, error_message, created_at, completed_at
+		 FROM symbol_analyses
+		 WHERE symbol = ? AND currency = ? AND status = 'completed' AND input_hash = ?
+		   AND completed_at >= datetime('now', ?)
+		 ORDER BY completed_at DESC, id DESC LIMIT 1`,
+		symbol, currency, inputHash, fmt.Sprintf("-%d seconds", int64(c.symbolAnalysisCacheTTL.Seconds())),
+	).Scan(&id, &model, &status, &macroRaw, &industryRaw, &companyRaw, &internationalRaw,
+		&synthesisRaw, &errorMessage, &createdAt, &completedAtRaw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query cached symbol analysis: %w", err)
+	}
+
+	result, err := buildSymbolAnalysisResult(id, symbol, currency, model, status,
+		macroRaw, industryRaw, companyRaw, internationalRaw,
+		synthesisRaw, errorMessage, createdAt, completedAtRaw)
+	if err != nil {
+		return nil, err
+	}
+	result.Cached = true
+	return result, nil
+}
+
+func (c *Core) setSymbolAnalysisInputHash(id int64, inputHash string) error {
+	_, err := c.db.Exec(`UPDATE symbol_analyses SET input_hash = ? WHERE id = ?`, inputHash, id)
+	return err
+}
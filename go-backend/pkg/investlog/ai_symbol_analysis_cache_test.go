@@ -0,0 +1,177 @@
+package investlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeSymbol_ReusesCachedResultForIdenticalInputs(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	var synthesisCalls atomic.Int32
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if strings.Contains(req.SystemPrompt, "综合投资分析师") {
+			synthesisCalls.Add(1)
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	req := SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+	}
+	countRows := func() int {
+		var n int
+		if err := core.db.QueryRow("SELECT COUNT(*) FROM symbol_analyses").Scan(&n); err != nil {
+			t.Fatalf("count symbol analyses: %v", err)
+		}
+		return n
+	}
+
+	first, err := core.AnalyzeSymbol(req)
+	assertNoError(t, err, "first AnalyzeSymbol")
+	if first.Cached || synthesisCalls.Load() != 1 {
+		t.Fatalf("expected fresh first run, cached=%v synthesis calls=%d", first.Cached, synthesisCalls.Load())
+	}
+
+	hit, err := core.AnalyzeSymbol(req)
+	assertNoError(t, err, "cached AnalyzeSymbol")
+	if !hit.Cached || hit.ID != first.ID {
+		t.Fatalf("expected cache hit on row %d, got cached=%v id=%d", first.ID, hit.Cached, hit.ID)
+	}
+	if synthesisCalls.Load() != 1 {
+		t.Fatalf("expected no synthesis call on cache hit, got %d", synthesisCalls.Load())
+	}
+	if hit.Synthesis == nil || hit.Synthesis.OverallRating != first.Synthesis.OverallRating {
+		t.Fatalf("expected cached synthesis, got %+v", hit.Synthesis)
+	}
+	if got := countRows(); got != 1 {
+		t.Fatalf("expected cache hit to leave no extra rows, got %d", got)
+	}
+
+	changed := req
+	changed.RiskProfile = "aggressive"
+	miss, err := core.AnalyzeSymbol(changed)
+	assertNoError(t, err, "AnalyzeSymbol with changed preferences")
+	if miss.Cached || synthesisCalls.Load() != 2 {
+		t.Fatalf("expected changed preferences to miss cache, cached=%v calls=%d", miss.Cached, synthesisCalls.Load())
+	}
+
+	testBuyTransaction(t, core, "AAPL", 5, 120, "USD", "acc-1")
+	miss, err = core.AnalyzeSymbol(req)
+	assertNoError(t, err, "AnalyzeSymbol with changed holdings")
+	if miss.Cached || synthesisCalls.Load() != 3 {
+		t.Fatalf("expected changed holdings to miss cache, cached=%v calls=%d", miss.Cached, synthesisCalls.Load())
+	}
+
+	forced := req
+	forced.Force = true
+	miss, err = core.AnalyzeSymbol(forced)
+	assertNoError(t, err, "forced AnalyzeSymbol")
+	if miss.Cached || synthesisCalls.Load() != 4 {
+		t.Fatalf("expected force to bypass cache, cached=%v calls=%d", miss.Cached, synthesisCalls.Load())
+	}
+}
+
+func TestAnalyzeSymbol_CacheKeyIgnoresModelSummary(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = dimensionStubRouter
+
+	core.externalCache = nil
+	news := "AAPL news"
+	core.externalData = ExternalDataProviderFunc(func(context.Context, ExternalDataRequest) (*ExternalData, error) {
+		return &ExternalData{Sections: []ExternalDataSection{{Source: "test", Type: "news", Content: news}}}, nil
+	})
+	var summaryCalls atomic.Int32
+	origSummarize := summarizeExternalDataFn
+	defer func() { summarizeExternalDataFn = origSummarize }()
+	summarizeExternalDataFn = func(*Core, context.Context, *symbolExternalData, string, string, string, *slog.Logger) string {
+		// A model summary differs between runs even for the same sources.
+		return fmt.Sprintf("summary %d", summaryCalls.Add(1))
+	}
+
+	req := SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+	}
+	first, err := core.AnalyzeSymbol(req)
+	assertNoError(t, err, "first AnalyzeSymbol")
+	hit, err := core.AnalyzeSymbol(req)
+	assertNoError(t, err, "cached AnalyzeSymbol")
+	if !hit.Cached || hit.ID != first.ID {
+		t.Fatalf("expected cache hit on row %d, got cached=%v id=%d", first.ID, hit.Cached, hit.ID)
+	}
+	if summaryCalls.Load() != 1 {
+		t.Fatalf("expected cache hit before summarizing, got %d summary calls", summaryCalls.Load())
+	}
+
+	news = "AAPL breaking news"
+	miss, err := core.AnalyzeSymbol(req)
+	assertNoError(t, err, "AnalyzeSymbol with changed external data")
+	if miss.Cached {
+		t.Fatal("expected changed external data to miss cache")
+	}
+}
+
+func TestFindCachedSymbolAnalysisRespectsTTL(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	core.symbolAnalysisCacheTTL = time.Hour
+
+	insert := func(completedAt string) {
+		if _, err := core.db.Exec(
+			`INSERT INTO symbol_analyses (symbol, currency, model, status, synthesis, input_hash, completed_at)
+			 VALUES ('AAPL', 'USD', 'mock', 'completed', ?, 'hash-1', `+completedAt+`)`,
+			stubSynthesisJSON,
+		); err != nil {
+			t.Fatalf("seed symbol analysis: %v", err)
+		}
+	}
+
+	insert("datetime('now', '-2 hours')")
+	cached, err := core.findCachedSymbolAnalysis("AAPL", "USD", "hash-1")
+	assertNoError(t, err, "findCachedSymbolAnalysis expired")
+	if cached != nil {
+		t.Fatalf("expected expired analysis to miss, got id %d", cached.ID)
+	}
+
+	insert("datetime('now', '-10 minutes')")
+	cached, err = core.findCachedSymbolAnalysis("AAPL", "USD", "hash-1")
+	assertNoError(t, err, "findCachedSymbolAnalysis fresh")
+	if cached == nil || !cached.Cached {
+		t.Fatalf("expected fresh analysis to hit, got %+v", cached)
+	}
+	if other, _ := core.findCachedSymbolAnalysis("AAPL", "USD", "hash-2"); other != nil {
+		t.Fatalf("expected different hash to miss")
+	}
+}
@@ -0,0 +1,98 @@
+package investlog
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultStaleAnalysisAge leaves a full symbolAnalysisTimeout of margin
+	// before a pending or running row is considered abandoned.
+	defaultStaleAnalysisAge      = 2 * symbolAnalysisTimeout
+	defaultStaleAnalysisInterval = 10 * time.Minute
+)
+
+// StaleAnalysisCleanup reports what CleanupStaleAnalyses changed.
+type StaleAnalysisCleanup struct {
+	// MarkedFailed counts pending or running rows moved to failed.
+	MarkedFailed int64 `json:"marked_failed"`
+	// Pruned counts failed rows deleted for exceeding the retention.
+	Pruned int64 `json:"pruned"`
+}
+
+// CleanupStaleAnalyses marks symbol analyses still pending or running after
+// olderThan as failed, since the process that owned them is gone (e.g. it
+// crashed mid-analysis). When Options.FailedAnalysisRetention is set, failed
+// rows created before it are also deleted. A non-positive olderThan uses
+// twice the symbol analysis timeout.
+func (c *Core) CleanupStaleAnalyses(olderThan time.Duration) (StaleAnalysisCleanup, error) {
+	olderThan = defaultDuration(olderThan, defaultStaleAnalysisAge)
+	var cleanup StaleAnalysisCleanup
+
+	result, err := c.db.Exec(
+		`UPDATE symbol_analyses
+		 SET status = 'failed',
+		     error_message = ?,
+		     completed_at = CURRENT_TIMESTAMP
+		 WHERE status IN ('pending', 'running')
+		   AND created_at < datetime('now', ?)`,
+		fmt.Sprintf("analysis timed out: no result after %s, the server may have restarted", olderThan),
+		sqliteAgeModifier(olderThan),
+	)
+	if err != nil {
+		return cleanup, fmt.Errorf("mark stale symbol analyses failed: %w", err)
+	}
+	if cleanup.MarkedFailed, err = result.RowsAffected(); err != nil {
+		return cleanup, err
+	}
+
+	if c.analysisRetention > 0 {
+		result, err := c.db.Exec(
+			`DELETE FROM symbol_analyses
+			 WHERE status = 'failed' AND created_at < datetime('now', ?)`,
+			sqliteAgeModifier(c.analysisRetention),
+		)
+		if err != nil {
+			return cleanup, fmt.Errorf("prune failed symbol analyses: %w", err)
+		}
+		if cleanup.Pruned, err = result.RowsAffected(); err != nil {
+			return cleanup, err
+		}
+	}
+	return cleanup, nil
+}
+
+// StartStaleAnalysisCleanup runs CleanupStaleAnalyses now and then every
+// interval until ctx is done. A non-positive interval uses 10 minutes.
+func (c *Core) StartStaleAnalysisCleanup(ctx context.Context, olderThan, interval time.Duration) {
+	interval = defaultDuration(interval, defaultStaleAnalysisInterval)
+	run := func() {
+		cleanup, err := c.CleanupStaleAnalyses(olderThan)
+		if err != nil {
+			c.Logger().Warn("stale symbol analysis cleanup failed", "err", err)
+			return
+		}
+		if cleanup.MarkedFailed > 0 || cleanup.Pruned > 0 {
+			c.Logger().Info("stale symbol analysis cleanup", "marked_failed", cleanup.MarkedFailed, "pruned", cleanup.Pruned)
+		}
+	}
+	run()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
+
+// sqliteAgeModifier formats age as a negative datetime() modifier.
+func sqliteAgeModifier(age time.Duration) string {
+	return fmt.Sprintf("-%d seconds", int64(age.Seconds()))
+}
@@ -0,0 +1,74 @@
+package investlog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCleanupStaleAnalyses_FailsOldPendingRows(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	stale := seedSymbolAnalysisRow(t, core, "pending")
+	running := seedSymbolAnalysisRow(t, core, "running")
+	fresh := seedSymbolAnalysisRow(t, core, "pending")
+	done := seedSymbolAnalysisRow(t, core, "completed")
+	if _, err := core.db.Exec(
+		`UPDATE symbol_analyses SET created_at = datetime('now', '-2 hours') WHERE id IN (?, ?, ?)`,
+		stale, running, done,
+	); err != nil {
+		t.Fatalf("age rows: %v", err)
+	}
+
+	result, err := core.CleanupStaleAnalyses(time.Hour)
+	if err != nil {
+		t.Fatalf("CleanupStaleAnalyses failed: %v", err)
+	}
+	if result.MarkedFailed != 2 || result.Pruned != 0 {
+		t.Fatalf("expected 2 rows marked failed, got %+v", result)
+	}
+
+	wantStatus := map[int64]string{stale: "failed", running: "failed", fresh: "pending", done: "completed"}
+	for id, want := range wantStatus {
+		var status string
+		var errMsg *string
+		if err := core.db.QueryRow(`SELECT status, error_message FROM symbol_analyses WHERE id = ?`, id).Scan(&status, &errMsg); err != nil {
+			t.Fatalf("query row %d: %v", id, err)
+		}
+		if status != want {
+			t.Fatalf("row %d: expected %s, got %s", id, want, status)
+		}
+		if want == "failed" && (errMsg == nil || !strings.Contains(*errMsg, "timed out")) {
+			t.Fatalf("row %d: expected timeout message, got %v", id, errMsg)
+		}
+	}
+}
+
+func TestCleanupStaleAnalyses_PrunesOldFailedRows(t *testing.T) {
+	dir := t.TempDir()
+	core, err := OpenWithOptions(Options{DBPath: filepath.Join(dir, "test.db"), FailedAnalysisRetention: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("open core: %v", err)
+	}
+	defer core.Close()
+
+	old := seedSymbolAnalysisRow(t, core, "failed")
+	recent := seedSymbolAnalysisRow(t, core, "failed")
+	if _, err := core.db.Exec(`UPDATE symbol_analyses SET created_at = datetime('now', '-48 hours') WHERE id = ?`, old); err != nil {
+		t.Fatalf("age row: %v", err)
+	}
+
+	result, err := core.CleanupStaleAnalyses(0)
+	if err != nil {
+		t.Fatalf("CleanupStaleAnalyses failed: %v", err)
+	}
+	if result.Pruned != 1 {
+		t.Fatalf("expected 1 pruned row, got %+v", result)
+	}
+	var count int
+	if err := core.db.QueryRow(`SELECT COUNT(*) FROM symbol_analyses WHERE id = ?`, recent).Scan(&count); err != nil || count != 1 {
+		t.Fatalf("expected recent failed row kept, count=%d err=%v", count, err)
+	}
+}
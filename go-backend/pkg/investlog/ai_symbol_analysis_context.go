@@ -5,10 +5,23 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
+// defaultQuantityPrecision is the decimal places symbol context keeps for
+// quantities and amounts unless Options.QuantityPrecision overrides it.
+const defaultQuantityPrecision = 2
+
+func (c *Core) quantityPlaces() int {
+	if c.quantityPrecision <= 0 {
+		return defaultQuantityPrecision
+	}
+	return c.quantityPrecision
+}
+
 // aiJSON returns a JSON string containing only the fields allowed for AI consumption:
-// symbol, name, avg_cost, pnl_percent, position_percent, allocation_max_percent, allocation_status.
+// symbol, name, avg_cost, pnl_percent, position_percent, position_basis, allocation_max_percent, allocation_status.
 func (ctx *symbolContextData) aiJSON() (string, error) {
 	slim := struct {
 		Symbol               string  `json:"symbol"`
@@ -16,6 +29,7 @@ func (ctx *symbolContextData) aiJSON() (string, error) {
 		AvgCost              float64 `json:"avg_cost,omitempty"`
 		PnLPercent           float64 `json:"pnl_percent,omitempty"`
 		PositionPercent      float64 `json:"position_percent,omitempty"`
+		PositionBasis        string  `json:"position_basis,omitempty"`
 		AllocationMaxPercent float64 `json:"allocation_max_percent,omitempty"`
 		AllocationStatus     string  `json:"allocation_status,omitempty"`
 	}{
@@ -24,6 +38,7 @@ func (ctx *symbolContextData) aiJSON() (string, error) {
 		AvgCost:              ctx.AvgCost,
 		PnLPercent:           ctx.PnLPercent,
 		PositionPercent:      ctx.PositionPercent,
+		PositionBasis:        ctx.PositionBasis,
 		AllocationMaxPercent: ctx.AllocationMaxPercent,
 		AllocationStatus:     ctx.AllocationStatus,
 	}
@@ -34,17 +49,68 @@ func (ctx *symbolContextData) aiJSON() (string, error) {
 	return string(data), nil
 }
 
-func (c *Core) buildSymbolContext(symbol, currency string) (*symbolContextData, error) {
-	bySymbol, err := c.GetHoldingsBySymbol()
+// GetSymbolPositionWeight returns the position_percent symbol analysis would use
+// for symbol, together with the denominator it was computed against. basis is
+// "currency" (default) or "portfolio"; the portfolio total converts every
+// currency bucket into currency using the maintained exchange rates.
+func (c *Core) GetSymbolPositionWeight(symbol, currency, basis string) (SymbolPositionWeight, error) {
+	symbol = strings.TrimSpace(strings.ToUpper(symbol))
+	if symbol == "" {
+		return SymbolPositionWeight{}, NewError(ErrCodeInvalidInput, "symbol is required")
+	}
+	currency = normalizeCurrency(currency)
+	if !contains(Currencies, currency) {
+		return SymbolPositionWeight{}, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid currency: %s", currency))
+	}
+	basis, err := normalizeSymbolPositionBasis(basis)
 	if err != nil {
-		return nil, fmt.Errorf("load holdings: %w", err)
+		return SymbolPositionWeight{}, WrapError(ErrCodeInvalidInput, "invalid basis", err)
 	}
 
-	currData, ok := bySymbol[currency]
-	if !ok {
-		return nil, fmt.Errorf("no holdings found for currency: %s", currency)
+	ctx, err := c.buildSymbolContext(symbol, currency, basis)
+	if err != nil {
+		return SymbolPositionWeight{}, err
 	}
+	return SymbolPositionWeight{
+		Symbol:          ctx.Symbol,
+		Currency:        ctx.Currency,
+		Basis:           basis,
+		MarketValue:     ctx.MarketValue,
+		BasisTotal:      ctx.PositionBasisTotal,
+		PositionPercent: ctx.PositionPercent,
+	}, nil
+}
 
+// symbolPositionBasisTotal returns the denominator for position_percent in currency.
+func (c *Core) symbolPositionBasisTotal(bySymbol HoldingsBySymbolResult, currency, basis string) (float64, error) {
+	if basis != SymbolPositionBasisPortfolio {
+		return bySymbol[currency].TotalMarketValue.InexactFloat64(), nil
+	}
+	total := 0.0
+	for cur, data := range bySymbol {
+		value := data.TotalMarketValue.InexactFloat64()
+		if value == 0 {
+			continue
+		}
+		rate, err := c.GetExchangeRate(cur, currency)
+		if err != nil {
+			return 0, fmt.Errorf("convert %s holdings to %s: %w", cur, currency, err)
+		}
+		total += value * rate
+	}
+	return total, nil
+}
+
+func (c *Core) buildSymbolContext(symbol, currency, basis string) (*symbolContextData, error) {
+	if basis == "" {
+		basis = SymbolPositionBasisCurrency
+	}
+	bySymbol, err := c.GetHoldingsBySymbol()
+	if err != nil {
+		return nil, fmt.Errorf("load holdings: %w", err)
+	}
+
+	currData := bySymbol[currency]
 	matched := make([]SymbolHolding, 0)
 	for _, s := range currData.Symbols {
 		if strings.EqualFold(s.Symbol, symbol) {
@@ -53,18 +119,27 @@ func (c *Core) buildSymbolContext(symbol, currency string) (*symbolContextData,
 	}
 
 	if len(matched) == 0 {
-		// Allow analysis even without holdings (just symbol + currency)
-		return &symbolContextData{
-			Symbol:   symbol,
-			Currency: currency,
-		}, nil
+		// Allow analysis even without holdings (just symbol + currency),
+		// e.g. for watchlist symbols.
+		ctx := &symbolContextData{
+			Symbol:        symbol,
+			Currency:      currency,
+			PositionBasis: basis,
+		}
+		if item, err := c.getWatchlistItem(normalizeSymbol(symbol), currency); err == nil {
+			ctx.AssetType = item.AssetType
+			if item.LatestPrice != nil {
+				ctx.LatestPrice = roundDecimal(item.LatestPrice.Decimal, c.quantityPlaces())
+			}
+		}
+		return ctx, nil
 	}
 
 	name := symbol
 	assetType := ""
-	var totalShares float64
-	var totalCostBasis float64
-	var totalMarketValue float64
+	var totalShares decimal.Decimal
+	var totalCostBasis decimal.Decimal
+	var totalMarketValue decimal.Decimal
 	accountNameSet := map[string]struct{}{}
 
 	for _, item := range matched {
@@ -75,9 +150,9 @@ func (c *Core) buildSymbolContext(symbol, currency string) (*symbolContextData,
 			assetType = strings.TrimSpace(item.AssetType)
 		}
 
-		totalShares += item.TotalShares.InexactFloat64()
-		totalCostBasis += item.CostBasis.InexactFloat64()
-		totalMarketValue += item.MarketValue.InexactFloat64()
+		totalShares = totalShares.Add(item.TotalShares.Decimal)
+		totalCostBasis = totalCostBasis.Add(item.CostBasis.Decimal)
+		totalMarketValue = totalMarketValue.Add(item.MarketValue.Decimal)
 
 		accountName := strings.TrimSpace(item.AccountName)
 		if accountName == "" {
@@ -98,19 +173,33 @@ func (c *Core) buildSymbolContext(symbol, currency string) (*symbolContextData,
 	}
 	sort.Strings(accountNames)
 
+	// Quantities and amounts are summed as decimals and rounded once, so
+	// small fractional positions survive at the configured precision.
+	places := c.quantityPlaces()
 	avgCost := 0.0
 	latestPrice := 0.0
-	if totalShares > 0 {
-		avgCost = round2(totalCostBasis / totalShares)
-		latestPrice = round2(totalMarketValue / totalShares)
+	if totalShares.IsPositive() {
+		avgCost = roundDecimal(totalCostBasis.Div(totalShares), places)
+		latestPrice = roundDecimal(totalMarketValue.Div(totalShares), places)
 	}
 	pnlPercent := 0.0
-	if totalCostBasis > 0 {
-		pnlPercent = round2((totalMarketValue - totalCostBasis) / totalCostBasis * 100)
+	if totalCostBasis.IsPositive() {
+		pnlPercent = round2(totalMarketValue.Sub(totalCostBasis).Div(totalCostBasis).Mul(decimal.NewFromInt(100)).InexactFloat64())
+	}
+	marketValue := totalMarketValue.InexactFloat64()
+	basisTotal, err := c.symbolPositionBasisTotal(bySymbol, currency, basis)
+	if err != nil {
+		return nil, err
 	}
 	positionPercent := 0.0
+	if basisTotal > 0 {
+		positionPercent = round2(marketValue / basisTotal * 100)
+	}
+	// Allocation targets are per currency, so status always compares the
+	// currency-relative weight regardless of the reported basis.
+	currencyPercent := 0.0
 	if currData.TotalMarketValue.IsPositive() {
-		positionPercent = round2(totalMarketValue / currData.TotalMarketValue.InexactFloat64() * 100)
+		currencyPercent = round2(totalMarketValue.Div(currData.TotalMarketValue.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64())
 	}
 
 	ctx := &symbolContextData{
@@ -118,13 +207,15 @@ func (c *Core) buildSymbolContext(symbol, currency string) (*symbolContextData,
 		Name:                     name,
 		Currency:                 currency,
 		AssetType:                assetType,
-		TotalShares:              round2(totalShares),
+		TotalShares:              roundDecimal(totalShares, places),
 		AvgCost:                  avgCost,
-		CostBasis:                round2(totalCostBasis),
+		CostBasis:                roundDecimal(totalCostBasis, places),
 		LatestPrice:              latestPrice,
-		MarketValue:              round2(totalMarketValue),
+		MarketValue:              roundDecimal(totalMarketValue, places),
 		PnLPercent:               pnlPercent,
 		PositionPercent:          positionPercent,
+		PositionBasis:            basis,
+		PositionBasisTotal:       round2(basisTotal),
 		CurrencyTotalMarketValue: round2(currData.TotalMarketValue.InexactFloat64()),
 		AccountNames:             accountNames,
 	}
@@ -142,9 +233,9 @@ func (c *Core) buildSymbolContext(symbol, currency string) (*symbolContextData,
 				ctx.AllocationMinPercent = round2(setting.MinPercent)
 				ctx.AllocationMaxPercent = round2(setting.MaxPercent)
 				switch {
-				case positionPercent < setting.MinPercent:
+				case currencyPercent < setting.MinPercent:
 					ctx.AllocationStatus = "below_target"
-				case positionPercent > setting.MaxPercent:
+				case currencyPercent > setting.MaxPercent:
 					ctx.AllocationStatus = "above_target"
 				default:
 					ctx.AllocationStatus = "within_target"
@@ -140,7 +140,8 @@ func buildSynthesisWeightContext(contextData *symbolContextData, preference symb
 		return weight
 	}
 
-	weight.HoldingsQuantity = round2(contextData.TotalShares)
+	// Already rounded to the configured quantity precision.
+	weight.HoldingsQuantity = contextData.TotalShares
 	weight.PositionPercent = round2(contextData.PositionPercent)
 	weight.AllocationMinPercent = round2(contextData.AllocationMinPercent)
 	weight.AllocationMaxPercent = round2(contextData.AllocationMaxPercent)
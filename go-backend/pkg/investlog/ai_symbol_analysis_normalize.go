@@ -56,6 +56,30 @@ func normalizeSynthesisResult(result *SymbolSynthesisResult, context *symbolCont
 	result.PositionSuggestion = normalizeSynthesisPositionSuggestion(*result, context)
 	result.OverallSummary = normalizeSynthesisSummary(*result, selectedFrameworkIDs)
 	result.Disclaimer = normalizeSynthesisDisclaimer(result.Disclaimer)
+	result.ActionItems = normalizeSynthesisActionItems(result.ActionItems)
+}
+
+// normalizeSynthesisActionItems trims action items, defaults a missing
+// priority to medium and drops items with no content at all.
+func normalizeSynthesisActionItems(items []SymbolAnalysisActionItem) []SymbolAnalysisActionItem {
+	result := make([]SymbolAnalysisActionItem, 0, len(items))
+	for _, item := range items {
+		action := strings.TrimSpace(item.Action)
+		rationale := strings.TrimSpace(item.Rationale)
+		priority := strings.ToLower(strings.TrimSpace(item.Priority))
+		if action == "" && rationale == "" && priority == "" {
+			continue
+		}
+		if priority == "" {
+			priority = "medium"
+		}
+		result = append(result, SymbolAnalysisActionItem{
+			Action:    action,
+			Rationale: rationale,
+			Priority:  priority,
+		})
+	}
+	return result
 }
 
 func normalizeSynthesisAction(action string) string {
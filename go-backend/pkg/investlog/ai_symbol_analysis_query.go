@@ -46,21 +46,116 @@ func (c *Core) GetSymbolAnalysis(symbol, currency string) (*SymbolAnalysisResult
 		synthesisRaw, errorMessage, createdAt, completedAtRaw)
 }
 
-// GetSymbolAnalysisHistory returns recent completed analyses for a symbol.
-func (c *Core) GetSymbolAnalysisHistory(symbol, currency string, limit int) ([]SymbolAnalysisResult, error) {
+const maxSymbolAnalysisStatusSymbols = 500
+
+// GetSymbolAnalysisStatuses returns the latest analysis status for each symbol
+// in currency, whatever its status, in the order the symbols were given.
+func (c *Core) GetSymbolAnalysisStatuses(symbols []string, currency string) ([]SymbolAnalysisStatus, error) {
+	currency = normalizeCurrency(currency)
+	if !contains(Currencies, currency) {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid currency: %s", currency))
+	}
+	normalized := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = normalizeSymbol(symbol)
+		if symbol != "" && !contains(normalized, symbol) {
+			normalized = append(normalized, symbol)
+		}
+	}
+	if len(normalized) == 0 {
+		return []SymbolAnalysisStatus{}, nil
+	}
+	if len(normalized) > maxSymbolAnalysisStatusSymbols {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("too many symbols: %d (max %d)", len(normalized), maxSymbolAnalysisStatusSymbols))
+	}
+
+	params := make([]any, 0, len(normalized)+1)
+	params = append(params, currency)
+	for _, symbol := range normalized {
+		params = append(params, symbol)
+	}
+	rows, err := c.db.Query(
+		`SELECT symbol, id, status, error_message, created_at, completed_at,
+		        CAST((julianday('now') - julianday(created_at)) * 86400 AS INTEGER)
+		 FROM (
+		     SELECT symbol, id, status, error_message, created_at, completed_at,
+		            ROW_NUMBER() OVER (PARTITION BY symbol ORDER BY created_at DESC, id DESC) AS rn
+		     FROM symbol_analyses
+		     WHERE currency = ? AND symbol IN (?`+strings.Repeat(", ?", len(normalized)-1)+`)
+		 )
+		 WHERE rn = 1`,
+		params...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query symbol analysis statuses: %w", err)
+	}
+	defer rows.Close()
+
+	latest := make(map[string]SymbolAnalysisStatus, len(normalized))
+	for rows.Next() {
+		var (
+			item         SymbolAnalysisStatus
+			errorMessage sql.NullString
+			completedAt  sql.NullString
+			age          sql.NullInt64
+		)
+		if err := rows.Scan(&item.Symbol, &item.ID, &item.Status, &errorMessage, &item.CreatedAt, &completedAt, &age); err != nil {
+			return nil, fmt.Errorf("scan symbol analysis status: %w", err)
+		}
+		item.Currency = currency
+		item.ErrorMessage = errorMessage.String
+		item.CompletedAt = completedAt.String
+		item.AgeSeconds = age.Int64
+		latest[item.Symbol] = item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate symbol analysis statuses: %w", err)
+	}
+
+	result := make([]SymbolAnalysisStatus, 0, len(normalized))
+	for _, symbol := range normalized {
+		item, ok := latest[symbol]
+		if !ok {
+			item = SymbolAnalysisStatus{Symbol: symbol, Currency: currency, Status: "none"}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GetSymbolAnalysisStatus returns the status of the latest analysis run for
+// symbol, so clients can poll a run in progress. Status is "pending" until the
+// framework agents start, then "running", and finally "completed" or "failed".
+func (c *Core) GetSymbolAnalysisStatus(symbol, currency string) (*SymbolAnalysisStatus, error) {
+	if normalizeSymbol(symbol) == "" {
+		return nil, NewError(ErrCodeInvalidInput, "symbol is required")
+	}
+	statuses, err := c.GetSymbolAnalysisStatuses([]string{symbol}, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &statuses[0], nil
+}
+
+// GetSymbolAnalysisHistory returns recent completed analyses for a symbol,
+// newest first, skipping the first offset of them.
+func (c *Core) GetSymbolAnalysisHistory(symbol, currency string, limit, offset int) ([]SymbolAnalysisResult, error) {
 	symbol = strings.TrimSpace(strings.ToUpper(symbol))
 	currency = strings.TrimSpace(strings.ToUpper(currency))
 	if limit <= 0 {
 		limit = 10
 	}
+	if offset < 0 {
+		offset = 0
+	}
 
 	rows, err := c.db.Query(
 		`SELECT id, model, status, macro_analysis, industry_analysis, company_analysis, international_analysis,
 		        synthesis, error_message, created_at, completed_at
 		 FROM symbol_analyses
 		 WHERE symbol = ? AND currency = ? AND status = 'completed'
-		 ORDER BY created_at DESC LIMIT ?`,
-		symbol, currency, limit,
+		 ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`,
+		symbol, currency, limit, offset,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query symbol analysis history: %w", err)
@@ -102,6 +197,21 @@ func (c *Core) GetSymbolAnalysisHistory(symbol, currency string, limit int) ([]S
 	return results, nil
 }
 
+// GetSymbolAnalysisHistoryCount returns the number of completed analyses for
+// a symbol, ignoring limit and offset.
+func (c *Core) GetSymbolAnalysisHistoryCount(symbol, currency string) (int, error) {
+	symbol = strings.TrimSpace(strings.ToUpper(symbol))
+	currency = strings.TrimSpace(strings.ToUpper(currency))
+	var count int
+	if err := c.db.QueryRow(
+		`SELECT COUNT(*) FROM symbol_analyses WHERE symbol = ? AND currency = ? AND status = 'completed'`,
+		symbol, currency,
+	).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count symbol analysis history: %w", err)
+	}
+	return count, nil
+}
+
 func buildSymbolAnalysisResult(
 	id int64, symbol, currency, model, status string,
 	macroRaw, industryRaw, companyRaw, internationalRaw, synthesisRaw, errorMessage sql.NullString,
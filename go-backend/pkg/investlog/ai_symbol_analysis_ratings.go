@@ -0,0 +1,207 @@
+package investlog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// overallRatingDimension is the symbol_analysis_ratings key of the synthesis
+// verdict; framework ratings are keyed by framework ID.
+const overallRatingDimension = "overall"
+
+// SymbolRatingMatch is a symbol whose latest completed analysis has the
+// requested rating.
+type SymbolRatingMatch struct {
+	Symbol     string `json:"symbol"`
+	Currency   string `json:"currency"`
+	AnalysisID int64  `json:"analysis_id"`
+	Rating     string `json:"rating"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// GetSymbolsByRating lists symbols whose latest completed analysis rated
+// dimension as rating. dimension is a framework ID such as "dcf" or
+// "porter_moat", a legacy dimension ("macro", "industry", "company",
+// "international") or "overall" for the synthesis; ratings compare
+// case-insensitively.
+func (c *Core) GetSymbolsByRating(dimension, rating string) ([]SymbolRatingMatch, error) {
+	dimension = strings.ToLower(strings.TrimSpace(dimension))
+	if !isRatingDimension(dimension) {
+		return nil, NewValidationError("dimension", fmt.Sprintf("invalid dimension: %s", dimension))
+	}
+	rating = normalizeAnalysisRating(rating)
+	if rating == "" {
+		return nil, NewValidationError("rating", "rating is required")
+	}
+
+	rows, err := c.db.Query(
+		`SELECT a.symbol, a.currency, a.id, r.rating, a.created_at
+		 FROM (
+		     SELECT symbol, currency, id, created_at,
+		            ROW_NUMBER() OVER (PARTITION BY symbol, currency ORDER BY created_at DESC, id DESC) AS rn
+		     FROM symbol_analyses
+		     WHERE status = 'completed'
+		 ) a
+		 JOIN symbol_analysis_ratings r ON r.analysis_id = a.id AND r.dimension = ?
+		 WHERE a.rn = 1 AND r.rating = ?
+		 ORDER BY a.symbol, a.currency`,
+		dimension, rating,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query symbols by rating: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]SymbolRatingMatch, 0)
+	for rows.Next() {
+		var match SymbolRatingMatch
+		if err := rows.Scan(&match.Symbol, &match.Currency, &match.AnalysisID, &match.Rating, &match.CreatedAt); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+func isRatingDimension(dimension string) bool {
+	if dimension == overallRatingDimension {
+		return true
+	}
+	for _, framework := range symbolFrameworkCatalog {
+		if framework.ID == dimension {
+			return true
+		}
+	}
+	for _, legacyKey := range legacyDimensionColumnOrder {
+		if legacyKey == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeAnalysisRating(rating string) string {
+	return strings.ToLower(strings.TrimSpace(rating))
+}
+
+// dimensionOutputRating parses the rating from a stored dimension output, or
+// returns "" when the output has none.
+func dimensionOutputRating(output string) string {
+	if strings.TrimSpace(output) == "" {
+		return ""
+	}
+	var result SymbolDimensionResult
+	if err := json.Unmarshal([]byte(cleanupModelJSON(output)), &result); err != nil {
+		return ""
+	}
+	return normalizeAnalysisRating(result.Rating)
+}
+
+// synthesisOutputRating parses the overall rating from a stored synthesis.
+func synthesisOutputRating(output string) string {
+	if strings.TrimSpace(output) == "" {
+		return ""
+	}
+	var result SymbolSynthesisResult
+	if err := json.Unmarshal([]byte(cleanupModelJSON(output)), &result); err != nil {
+		return ""
+	}
+	return normalizeAnalysisRating(result.OverallRating)
+}
+
+// replaceSymbolAnalysisRatings replaces the framework ratings of analysis id
+// with those parsed from dimensionOutputs, keyed by framework ID. The overall
+// rating is left alone.
+func replaceSymbolAnalysisRatings(tx *sql.Tx, id int64, dimensionOutputs map[string]string) error {
+	if _, err := tx.Exec(
+		`DELETE FROM symbol_analysis_ratings WHERE analysis_id = ? AND dimension != ?`,
+		id, overallRatingDimension,
+	); err != nil {
+		return err
+	}
+	for dimension, output := range dimensionOutputs {
+		if err := setSymbolAnalysisRating(tx, id, dimension, dimensionOutputRating(output)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSymbolAnalysisRating stores one rating of analysis id; an empty rating
+// removes it.
+func setSymbolAnalysisRating(tx *sql.Tx, id int64, dimension, rating string) error {
+	dimension = strings.ToLower(strings.TrimSpace(dimension))
+	if dimension == "" {
+		return nil
+	}
+	if rating == "" {
+		_, err := tx.Exec(`DELETE FROM symbol_analysis_ratings WHERE analysis_id = ? AND dimension = ?`, id, dimension)
+		return err
+	}
+	_, err := tx.Exec(
+		`INSERT INTO symbol_analysis_ratings (analysis_id, dimension, rating) VALUES (?, ?, ?)
+		 ON CONFLICT(analysis_id, dimension) DO UPDATE SET rating = excluded.rating`,
+		id, dimension, rating,
+	)
+	return err
+}
+
+// backfillSymbolAnalysisRatings fills symbol_analysis_ratings for analyses
+// saved before it existed. Stored outputs are keyed by the dimension they
+// name, falling back to their legacy column.
+func backfillSymbolAnalysisRatings(tx *sql.Tx) error {
+	rows, err := tx.Query(
+		`SELECT id, macro_analysis, industry_analysis, company_analysis, international_analysis, synthesis
+		 FROM symbol_analyses`,
+	)
+	if err != nil {
+		return err
+	}
+	type analysisOutputs struct {
+		id         int64
+		dimensions map[string]string
+		synthesis  string
+	}
+	var pending []analysisOutputs
+	for rows.Next() {
+		var id int64
+		var outputs [5]sql.NullString
+		if err := rows.Scan(&id, &outputs[0], &outputs[1], &outputs[2], &outputs[3], &outputs[4]); err != nil {
+			rows.Close()
+			return err
+		}
+		item := analysisOutputs{id: id, dimensions: make(map[string]string), synthesis: outputs[4].String}
+		for i, key := range legacyDimensionColumnOrder {
+			output := outputs[i].String
+			if strings.TrimSpace(output) == "" {
+				continue
+			}
+			var result SymbolDimensionResult
+			if err := json.Unmarshal([]byte(cleanupModelJSON(output)), &result); err == nil {
+				if named := strings.ToLower(strings.TrimSpace(result.Dimension)); named != "" {
+					key = named
+				}
+			}
+			item.dimensions[key] = output
+		}
+		pending = append(pending, item)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	for _, item := range pending {
+		if err := replaceSymbolAnalysisRatings(tx, item.id, item.dimensions); err != nil {
+			return err
+		}
+		if err := setSymbolAnalysisRating(tx, item.id, overallRatingDimension, synthesisOutputRating(item.synthesis)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package investlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSymbolAnalysisOutputRatings(t *testing.T) {
+	if got := dimensionOutputRating(stubInternationalJSON); got != "neutral" {
+		t.Fatalf("expected neutral, got %q", got)
+	}
+	if got := dimensionOutputRating("```json\n{\"rating\":\" Negative \"}\n```"); got != "negative" {
+		t.Fatalf("expected fenced rating to be parsed and lowercased, got %q", got)
+	}
+	if got := dimensionOutputRating("not json"); got != "" {
+		t.Fatalf("expected empty rating for invalid output, got %q", got)
+	}
+	if got := synthesisOutputRating(stubSynthesisJSON); got != "buy" {
+		t.Fatalf("expected buy, got %q", got)
+	}
+	if got := synthesisOutputRating(""); got != "" {
+		t.Fatalf("expected empty rating for empty synthesis, got %q", got)
+	}
+}
+
+func saveRatedSymbolAnalysis(t *testing.T, core *Core, symbol, companyRating, overallRating string) int64 {
+	t.Helper()
+	id, err := core.insertPendingSymbolAnalysis(SymbolAnalysisRequest{Symbol: symbol, Currency: "USD", Model: "test-model"})
+	assertNoError(t, err, "insert pending analysis")
+	outputs := map[string]string{
+		"macro":         stubMacroJSON,
+		"industry":      stubIndustryJSON,
+		"company":       strings.Replace(stubCompanyJSON, `"rating":"positive"`, `"rating":"`+companyRating+`"`, 1),
+		"international": stubInternationalJSON,
+	}
+	synthesis := strings.Replace(stubSynthesisJSON, `"overall_rating":"buy"`, `"overall_rating":"`+overallRating+`"`, 1)
+	assertNoError(t, core.saveCompletedSymbolAnalysis(id, outputs, synthesis, ""), "save completed analysis")
+	return id
+}
+
+func TestGetSymbolsByRating(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	saveRatedSymbolAnalysis(t, core, "AAPL", "negative", "hold")
+	latestAAPL := saveRatedSymbolAnalysis(t, core, "AAPL", "positive", "buy")
+	msft := saveRatedSymbolAnalysis(t, core, "MSFT", "Negative", "buy")
+	failed := saveRatedSymbolAnalysis(t, core, "TSLA", "negative", "reduce")
+	assertNoError(t, core.updateSymbolAnalysisStatus(failed, "failed", "boom"), "mark analysis failed")
+
+	stored := storedSymbolAnalysisRatings(t, core, msft)
+	if stored["macro"] != "positive" || stored["company"] != "negative" || stored[overallRatingDimension] != "buy" {
+		t.Fatalf("unexpected stored ratings: %v", stored)
+	}
+
+	negative, err := core.GetSymbolsByRating("company", "NEGATIVE")
+	assertNoError(t, err, "get symbols by company rating")
+	if len(negative) != 1 || negative[0].Symbol != "MSFT" || negative[0].AnalysisID != msft || negative[0].Rating != "negative" {
+		t.Fatalf("expected only MSFT's latest analysis, got %+v", negative)
+	}
+
+	buys, err := core.GetSymbolsByRating("overall", "buy")
+	assertNoError(t, err, "get symbols by overall rating")
+	if len(buys) != 2 || buys[0].Symbol != "AAPL" || buys[0].AnalysisID != latestAAPL || buys[1].Symbol != "MSFT" {
+		t.Fatalf("expected AAPL and MSFT, got %+v", buys)
+	}
+
+	if _, err := core.GetSymbolsByRating("valuation", "buy"); err == nil {
+		t.Fatal("expected error for unknown dimension")
+	}
+	if _, err := core.GetSymbolsByRating("overall", " "); err == nil {
+		t.Fatal("expected error for empty rating")
+	}
+}
+
+func TestBackfillSymbolAnalysisRatings(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	result, err := core.db.Exec(
+		`INSERT INTO symbol_analyses
+		 (symbol, currency, model, status, macro_analysis, industry_analysis, company_analysis, international_analysis, synthesis)
+		 VALUES ('AAPL', 'USD', 'old-model', 'completed', ?, ?, ?, ?, ?)`,
+		stubMacroJSON, stubIndustryJSON, stubCompanyJSON, stubInternationalJSON, stubSynthesisJSON,
+	)
+	assertNoError(t, err, "insert legacy analysis")
+	id, err := result.LastInsertId()
+	assertNoError(t, err, "legacy analysis id")
+
+	tx, err := core.db.Begin()
+	assertNoError(t, err, "begin")
+	assertNoError(t, backfillSymbolAnalysisRatings(tx), "backfill ratings")
+	assertNoError(t, tx.Commit(), "commit")
+
+	stored := storedSymbolAnalysisRatings(t, core, id)
+	if stored["international"] != "neutral" || stored[overallRatingDimension] != "buy" || len(stored) != 5 {
+		t.Fatalf("unexpected backfilled ratings: %v", stored)
+	}
+}
+
+func storedSymbolAnalysisRatings(t *testing.T, core *Core, id int64) map[string]string {
+	t.Helper()
+	rows, err := core.db.Query("SELECT dimension, rating FROM symbol_analysis_ratings WHERE analysis_id = ?", id)
+	assertNoError(t, err, "query stored ratings")
+	defer rows.Close()
+	ratings := make(map[string]string)
+	for rows.Next() {
+		var dimension, rating string
+		assertNoError(t, rows.Scan(&dimension, &rating), "scan stored rating")
+		ratings[dimension] = rating
+	}
+	assertNoError(t, rows.Err(), "iterate stored ratings")
+	return ratings
+}
+
+func frameworkRatingJSON(frameworkID, rating string) string {
+	return fmt.Sprintf(`{"dimension":%q,"rating":%q,"confidence":"medium","key_points":["k"],"risks":["r"],"opportunities":["o"],"summary":"s"}`, frameworkID, rating)
+}
+
+func TestSymbolAnalysisRatingsKeyedByFramework(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	id, err := core.insertPendingSymbolAnalysis(SymbolAnalysisRequest{Symbol: "AAPL", Currency: "USD", Model: "test-model"})
+	assertNoError(t, err, "insert pending analysis")
+	// Five frameworks: only four fit the legacy columns, so the last one in
+	// catalog order (porter_moat) has no column of its own.
+	outputs := map[string]string{
+		"dupont_roic":   frameworkRatingJSON("dupont_roic", "positive"),
+		"capital_cycle": frameworkRatingJSON("capital_cycle", "neutral"),
+		"reverse_dcf":   frameworkRatingJSON("reverse_dcf", "positive"),
+		"dcf":           frameworkRatingJSON("dcf", "positive"),
+		"porter_moat":   frameworkRatingJSON("porter_moat", "negative"),
+	}
+	assertNoError(t, core.saveCompletedSymbolAnalysis(id, outputs, stubSynthesisJSON, ""), "save completed analysis")
+
+	stored := storedSymbolAnalysisRatings(t, core, id)
+	if len(stored) != 6 || stored["porter_moat"] != "negative" || stored["capital_cycle"] != "neutral" || stored[overallRatingDimension] != "buy" {
+		t.Fatalf("unexpected stored ratings: %v", stored)
+	}
+	matches, err := core.GetSymbolsByRating("porter_moat", "negative")
+	assertNoError(t, err, "get symbols by porter_moat rating")
+	if len(matches) != 1 || matches[0].AnalysisID != id {
+		t.Fatalf("expected AAPL by porter_moat rating, got %+v", matches)
+	}
+	if matches, err := core.GetSymbolsByRating("dcf", "negative"); err != nil || len(matches) != 0 {
+		t.Fatalf("expected no dcf match, got %+v, %v", matches, err)
+	}
+
+	// Resaving the framework outputs drops ratings of frameworks no longer present.
+	delete(outputs, "porter_moat")
+	assertNoError(t, core.saveSymbolAnalysisDimensions(id, outputs), "save dimensions")
+	if stored := storedSymbolAnalysisRatings(t, core, id); len(stored) != 5 || stored["porter_moat"] != "" {
+		t.Fatalf("expected porter_moat rating removed, got %v", stored)
+	}
+}
+
+func TestAnalyzeSymbol_StoresFrameworkRatings(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-rating", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-rating")
+
+	frameworkRatings := make(map[string]string, len(symbolFrameworkCatalog))
+	for i, spec := range symbolFrameworkCatalog {
+		frameworkRatings[spec.ID] = []string{"positive", "neutral", "negative"}[i%3]
+	}
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		for _, spec := range symbolFrameworkCatalog {
+			if buildFrameworkSystemPrompt(spec) == req.SystemPrompt {
+				return aiChatCompletionResult{Model: "mock", Content: frameworkRatingJSON(spec.ID, frameworkRatings[spec.ID])}, nil
+			}
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	result, err := core.AnalyzeSymbol(SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+	})
+	assertNoError(t, err, "analyze symbol")
+	if len(result.Dimensions) == 0 {
+		t.Fatal("expected framework results")
+	}
+	for frameworkID := range result.Dimensions {
+		want := frameworkRatings[frameworkID]
+		matches, err := core.GetSymbolsByRating(frameworkID, want)
+		assertNoError(t, err, "get symbols by "+frameworkID)
+		if len(matches) != 1 || matches[0].AnalysisID != result.ID {
+			t.Fatalf("expected %s rated %s to match analysis %d, got %+v", frameworkID, want, result.ID, matches)
+		}
+		other := "positive"
+		if want == other {
+			other = "negative"
+		}
+		if matches, err := core.GetSymbolsByRating(frameworkID, other); err != nil || len(matches) != 0 {
+			t.Fatalf("expected no %s match for %s, got %+v, %v", frameworkID, other, matches, err)
+		}
+	}
+}
@@ -10,56 +10,64 @@ func normalizeSymbolAnalysisRequest(req SymbolAnalysisRequest) (SymbolAnalysisRe
 	normalized.BaseURL = normalizeAIBaseURL(req.BaseURL)
 	normalized.APIKey = strings.TrimSpace(req.APIKey)
 	if normalized.APIKey == "" {
-		return SymbolAnalysisRequest{}, fmt.Errorf("api_key is required")
+		return SymbolAnalysisRequest{}, NewValidationError("api_key", "api_key is required")
+	}
+	if err := validateAIAPIKey(normalized.APIKey); err != nil {
+		return SymbolAnalysisRequest{}, err
 	}
 	normalized.Model = strings.TrimSpace(req.Model)
 	if normalized.Model == "" {
-		return SymbolAnalysisRequest{}, fmt.Errorf("model is required")
+		return SymbolAnalysisRequest{}, NewValidationError("model", "model is required")
 	}
 	normalized.Model = normalizeAIModel(normalized.Model)
 	normalized.Symbol = strings.TrimSpace(strings.ToUpper(req.Symbol))
 	if normalized.Symbol == "" {
-		return SymbolAnalysisRequest{}, fmt.Errorf("symbol is required")
+		return SymbolAnalysisRequest{}, NewValidationError("symbol", "symbol is required")
 	}
 	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
 	if currency == "" {
-		return SymbolAnalysisRequest{}, fmt.Errorf("currency is required")
+		return SymbolAnalysisRequest{}, NewValidationError("currency", "currency is required")
 	}
 	if !contains(Currencies, currency) {
-		return SymbolAnalysisRequest{}, fmt.Errorf("invalid currency: %s", req.Currency)
+		return SymbolAnalysisRequest{}, NewValidationError("currency", fmt.Sprintf("invalid currency: %s", req.Currency))
 	}
 	normalized.Currency = currency
 
-	riskProfile, err := normalizeEnum(strings.TrimSpace(req.RiskProfile), "balanced", map[string]struct{}{
-		"conservative": {},
-		"balanced":     {},
-		"aggressive":   {},
-	})
+	riskProfile, err := normalizeEnum(strings.TrimSpace(req.RiskProfile), defaultAISettingsRiskProfile, validAIRiskProfiles)
 	if err != nil {
-		return SymbolAnalysisRequest{}, fmt.Errorf("invalid risk_profile: %w", err)
+		return SymbolAnalysisRequest{}, NewValidationError("risk_profile", fmt.Sprintf("invalid risk_profile: %v", err))
 	}
 	normalized.RiskProfile = riskProfile
 
-	horizon, err := normalizeEnum(strings.TrimSpace(req.Horizon), "medium", map[string]struct{}{
-		"short":  {},
-		"medium": {},
-		"long":   {},
-	})
+	horizon, err := normalizeEnum(strings.TrimSpace(req.Horizon), defaultAISettingsHorizon, validAIHorizons)
 	if err != nil {
-		return SymbolAnalysisRequest{}, fmt.Errorf("invalid horizon: %w", err)
+		return SymbolAnalysisRequest{}, NewValidationError("horizon", fmt.Sprintf("invalid horizon: %v", err))
 	}
 	normalized.Horizon = horizon
 
-	adviceStyle, err := normalizeEnum(strings.TrimSpace(req.AdviceStyle), "balanced", map[string]struct{}{
-		"conservative": {},
-		"balanced":     {},
-		"aggressive":   {},
-	})
+	adviceStyle, err := normalizeEnum(strings.TrimSpace(req.AdviceStyle), defaultAISettingsAdviceStyle, validAIAdviceStyles)
 	if err != nil {
-		return SymbolAnalysisRequest{}, fmt.Errorf("invalid advice_style: %w", err)
+		return SymbolAnalysisRequest{}, NewValidationError("advice_style", fmt.Sprintf("invalid advice_style: %v", err))
 	}
 	normalized.AdviceStyle = adviceStyle
 
+	positionBasis, err := normalizeSymbolPositionBasis(req.PositionBasis)
+	if err != nil {
+		return SymbolAnalysisRequest{}, err
+	}
+	normalized.PositionBasis = positionBasis
+
 	normalized.StrategyPrompt = strings.TrimSpace(req.StrategyPrompt)
 	return normalized, nil
 }
+
+func normalizeSymbolPositionBasis(raw string) (string, error) {
+	basis, err := normalizeEnum(strings.TrimSpace(raw), SymbolPositionBasisCurrency, map[string]struct{}{
+		SymbolPositionBasisCurrency:  {},
+		SymbolPositionBasisPortfolio: {},
+	})
+	if err != nil {
+		return "", NewValidationError("position_basis", fmt.Sprintf("invalid position_basis: %v", err))
+	}
+	return basis, nil
+}
@@ -0,0 +1,150 @@
+package investlog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ResynthesizeSymbol reruns only the synthesis agent for a stored symbol analysis,
+// reusing the framework outputs already saved on the row. Symbol and currency
+// come from the stored row; req supplies the provider settings and preferences.
+// A failed analysis qualifies as long as its framework outputs were stored;
+// a pending or running row is still being written and is rejected.
+func (c *Core) ResynthesizeSymbol(id int64, req SymbolAnalysisRequest) (*SymbolAnalysisResult, error) {
+	var (
+		symbol, currency string
+		status           string
+		storedStrategy   sql.NullString
+		createdAt        string
+		dimensionRaws    [4]sql.NullString
+	)
+	err := c.db.QueryRow(
+		`SELECT symbol, currency, status, strategy_prompt, created_at,
+		        macro_analysis, industry_analysis, company_analysis, international_analysis
+		 FROM symbol_analyses WHERE id = ?`,
+		id,
+	).Scan(&symbol, &currency, &status, &storedStrategy, &createdAt,
+		&dimensionRaws[0], &dimensionRaws[1], &dimensionRaws[2], &dimensionRaws[3])
+	if err == sql.ErrNoRows {
+		return nil, NewError(ErrCodeNotFound, fmt.Sprintf("symbol analysis not found: %d", id))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query symbol analysis: %w", err)
+	}
+	if status == "pending" || status == "running" {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("symbol analysis %d is still %s", id, status))
+	}
+	hasOutputs := false
+	for _, raw := range dimensionRaws {
+		if raw.Valid && strings.TrimSpace(raw.String) != "" {
+			hasOutputs = true
+			break
+		}
+	}
+	if !hasOutputs {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("symbol analysis %d has no stored framework outputs, rerun the full analysis", id))
+	}
+
+	req.Symbol = symbol
+	req.Currency = currency
+	if strings.TrimSpace(req.StrategyPrompt) == "" && storedStrategy.Valid {
+		req.StrategyPrompt = storedStrategy.String
+	}
+	req.APIKey = c.resolveAIAPIKey(req.APIKey)
+	normalizedReq, err := normalizeSymbolAnalysisRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	dimensions := make(map[string]*SymbolDimensionResult, len(dimensionRaws))
+	dimensionOutputs := make(map[string]string, len(dimensionRaws))
+	for idx, raw := range dimensionRaws {
+		if !raw.Valid || strings.TrimSpace(raw.String) == "" {
+			continue
+		}
+		parsed, parseErr := parseSymbolDimensionResult(raw.String)
+		if parseErr != nil {
+			c.Logger().Warn("skip unparsable stored framework result", "id", id, "column", legacyDimensionColumnOrder[idx], "err", parseErr)
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(parsed.Dimension))
+		if key == "" {
+			key = legacyDimensionColumnOrder[idx]
+		}
+		normalizeDimensionResult(parsed, key)
+		dimensions[key] = parsed
+		dimensionOutputs[key] = raw.String
+	}
+	if len(dimensions) < minFrameworkAnalyses {
+		return nil, NewError(ErrCodeValidation,
+			fmt.Sprintf("stored framework analyses less than %d, rerun the full analysis", minFrameworkAnalyses))
+	}
+	frameworkIDs := orderedDimensionOutputKeys(dimensionOutputs)
+
+	contextData, err := c.buildSymbolContext(normalizedReq.Symbol, normalizedReq.Currency, normalizedReq.PositionBasis)
+	if err != nil {
+		return nil, err
+	}
+	symbolContextJSON, err := contextData.aiJSON()
+	if err != nil {
+		return nil, err
+	}
+	endpointURL, err := buildAICompletionsEndpoint(normalizedReq.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(requestContext(normalizedReq.Context), symbolAnalysisTimeout)
+	defer cancel()
+
+	weightContext := buildSynthesisWeightContext(contextData, symbolPreferenceContext{
+		RiskProfile:    normalizedReq.RiskProfile,
+		Horizon:        normalizedReq.Horizon,
+		AdviceStyle:    normalizedReq.AdviceStyle,
+		StrategyPrompt: normalizedReq.StrategyPrompt,
+	})
+	synthesisOutput, err := c.runSynthesisAgent(
+		ctx,
+		endpointURL,
+		normalizedReq.APIKey,
+		normalizedReq.Model,
+		symbolContextJSON,
+		dimensionOutputs,
+		frameworkIDs,
+		weightContext,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("synthesis agent failed: %w", err)
+	}
+	synthesis, err := parseSynthesisResult(synthesisOutput)
+	if err != nil {
+		return nil, fmt.Errorf("parse synthesis result: %w", err)
+	}
+	normalizeSynthesisResult(synthesis, contextData, frameworkIDs)
+
+	synthesisToSave := synthesisOutput
+	if normalizedJSON, marshalErr := json.Marshal(synthesis); marshalErr == nil {
+		synthesisToSave = string(normalizedJSON)
+	} else {
+		c.Logger().Warn("failed to marshal normalized synthesis", "err", marshalErr)
+	}
+	if err := c.saveSymbolAnalysisSynthesis(id, synthesisToSave); err != nil {
+		return nil, fmt.Errorf("save synthesis result: %w", err)
+	}
+
+	return &SymbolAnalysisResult{
+		ID:          id,
+		Symbol:      normalizedReq.Symbol,
+		Currency:    normalizedReq.Currency,
+		Model:       normalizedReq.Model,
+		Status:      "completed",
+		Dimensions:  dimensions,
+		Synthesis:   synthesis,
+		CreatedAt:   createdAt,
+		CompletedAt: c.NowRFC3339(),
+	}, nil
+}
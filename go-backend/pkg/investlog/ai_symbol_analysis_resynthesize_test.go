@@ -0,0 +1,202 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func seedSymbolAnalysisRow(t *testing.T, core *Core, status string, outputs ...string) int64 {
+	t.Helper()
+	var cols [4]any
+	for i, output := range outputs {
+		cols[i] = output
+	}
+	result, err := core.db.Exec(
+		`INSERT INTO symbol_analyses (symbol, currency, model, status, strategy_prompt,
+		   macro_analysis, industry_analysis, company_analysis, international_analysis)
+		 VALUES ('AAPL', 'USD', 'gemini-2.5-pro', ?, '控制回撤', ?, ?, ?, ?)`,
+		status, cols[0], cols[1], cols[2], cols[3],
+	)
+	if err != nil {
+		t.Fatalf("seed symbol analysis: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("seed symbol analysis id: %v", err)
+	}
+	return id
+}
+
+func TestResynthesizeSymbol_OnlyRunsSynthesisAgent(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-resyn", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-resyn")
+	id := seedSymbolAnalysisRow(t, core, "failed",
+		buildGenericDimensionJSON(buildFrameworkSystemPrompt(symbolFrameworkCatalog[0])),
+		buildGenericDimensionJSON(buildFrameworkSystemPrompt(symbolFrameworkCatalog[1])),
+		buildGenericDimensionJSON(buildFrameworkSystemPrompt(symbolFrameworkCatalog[2])),
+	)
+
+	var synthesisCalls, otherCalls int32
+	var synthesisPrompt string
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if req.SystemPrompt != symbolSynthesisSystemPrompt {
+			atomic.AddInt32(&otherCalls, 1)
+			return aiChatCompletionResult{}, errors.New("unexpected agent call")
+		}
+		atomic.AddInt32(&synthesisCalls, 1)
+		synthesisPrompt = req.UserPrompt
+		return dimensionStubRouter(ctx, req)
+	}
+
+	result, err := core.ResynthesizeSymbol(id, SymbolAnalysisRequest{
+		BaseURL: "https://example.com/v1",
+		APIKey:  "test-key",
+		Model:   "new-model",
+	})
+	if err != nil {
+		t.Fatalf("ResynthesizeSymbol failed: %v", err)
+	}
+	if synthesisCalls != 1 || otherCalls != 0 {
+		t.Fatalf("expected exactly one synthesis call, got synthesis=%d other=%d", synthesisCalls, otherCalls)
+	}
+	for _, spec := range symbolFrameworkCatalog[:3] {
+		if !strings.Contains(synthesisPrompt, spec.ID) {
+			t.Fatalf("expected synthesis prompt to reference stored framework %s", spec.ID)
+		}
+	}
+	if !strings.Contains(synthesisPrompt, "控制回撤") {
+		t.Fatalf("expected stored strategy prompt to be reused")
+	}
+	if result.ID != id || result.Status != "completed" || len(result.Dimensions) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	assertSynthesisHardConstraints(t, result.Synthesis)
+
+	stored, err := core.GetSymbolAnalysis("AAPL", "USD")
+	if err != nil {
+		t.Fatalf("GetSymbolAnalysis failed: %v", err)
+	}
+	if stored == nil || stored.ID != id || stored.Synthesis == nil || stored.ErrorMessage != "" {
+		t.Fatalf("expected row to be completed with new synthesis, got %+v", stored)
+	}
+}
+
+func TestResynthesizeSymbol_AfterSynthesisFailure(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-resyn-fail", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-resyn-fail")
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if req.SystemPrompt == symbolSynthesisSystemPrompt {
+			return aiChatCompletionResult{}, errors.New("synthesis upstream down")
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	req := SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+	}
+	if _, err := core.AnalyzeSymbol(req); err == nil {
+		t.Fatal("expected synthesis failure")
+	}
+	var id int64
+	if err := core.db.QueryRow(`SELECT id FROM symbol_analyses WHERE status = 'failed'`).Scan(&id); err != nil {
+		t.Fatalf("expected failed row: %v", err)
+	}
+
+	var dimensionCalls int32
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if req.SystemPrompt != symbolSynthesisSystemPrompt {
+			atomic.AddInt32(&dimensionCalls, 1)
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+	result, err := core.ResynthesizeSymbol(id, req)
+	if err != nil {
+		t.Fatalf("ResynthesizeSymbol failed: %v", err)
+	}
+	if dimensionCalls != 0 {
+		t.Fatalf("expected no framework agent calls, got %d", dimensionCalls)
+	}
+	if len(result.Dimensions) != 3 || result.Synthesis == nil {
+		t.Fatalf("expected stored frameworks and new synthesis, got %+v", result)
+	}
+}
+
+func TestResynthesizeSymbol_RejectsUnusableRows(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var calls int32
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		atomic.AddInt32(&calls, 1)
+		return dimensionStubRouter(ctx, req)
+	}
+
+	req := SymbolAnalysisRequest{BaseURL: "https://example.com/v1", APIKey: "test-key", Model: "mock-model"}
+	for _, status := range []string{"pending", "running"} {
+		id := seedSymbolAnalysisRow(t, core, status, stubMacroJSON, stubIndustryJSON, stubCompanyJSON)
+		if _, err := core.ResynthesizeSymbol(id, req); !IsErrorCode(err, ErrCodeInvalidInput) {
+			t.Fatalf("%s row: expected INVALID_INPUT, got %v", status, err)
+		}
+		var stored string
+		if err := core.db.QueryRow(`SELECT status FROM symbol_analyses WHERE id = ?`, id).Scan(&stored); err != nil {
+			t.Fatalf("load seeded row: %v", err)
+		}
+		if stored != status {
+			t.Fatalf("expected %s row to be left alone, got status %q", status, stored)
+		}
+	}
+	id := seedSymbolAnalysisRow(t, core, "failed")
+	if _, err := core.ResynthesizeSymbol(id, req); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("failed row without outputs: expected INVALID_INPUT, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no model calls, got %d", calls)
+	}
+}
+
+func TestResynthesizeSymbol_Errors(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	req := SymbolAnalysisRequest{BaseURL: "https://example.com/v1", APIKey: "k", Model: "m"}
+	if _, err := core.ResynthesizeSymbol(999, req); !IsErrorCode(err, ErrCodeNotFound) {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+
+	id := seedSymbolAnalysisRow(t, core, "failed", stubMacroJSON)
+	if _, err := core.ResynthesizeSymbol(id, req); !IsErrorCode(err, ErrCodeValidation) {
+		t.Fatalf("expected VALIDATION_ERROR for insufficient frameworks, got %v", err)
+	}
+
+	id = seedSymbolAnalysisRow(t, core, "completed", stubMacroJSON, stubIndustryJSON, stubCompanyJSON)
+	if _, err := core.ResynthesizeSymbol(id, SymbolAnalysisRequest{Model: "m"}); err == nil ||
+		!strings.Contains(err.Error(), "api_key is required") {
+		t.Fatalf("expected api_key validation error, got %v", err)
+	}
+}
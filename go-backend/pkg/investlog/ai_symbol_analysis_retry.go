@@ -0,0 +1,50 @@
+package investlog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RetrySymbolAnalysis reruns a failed symbol analysis with the symbol,
+// currency, model and strategy prompt stored on its row. The base URL and
+// preferences come from the saved AI settings and apiKey may be empty when a
+// server-side key is configured. The rerun is recorded as a new run in the
+// symbol's history; the failed row is kept. ctx bounds the model calls.
+func (c *Core) RetrySymbolAnalysis(ctx context.Context, id int64, apiKey string) (*SymbolAnalysisResult, error) {
+	var (
+		symbol, currency, model, status string
+		storedStrategy                  sql.NullString
+	)
+	err := c.db.QueryRow(
+		`SELECT symbol, currency, model, status, strategy_prompt FROM symbol_analyses WHERE id = ?`,
+		id,
+	).Scan(&symbol, &currency, &model, &status, &storedStrategy)
+	if err == sql.ErrNoRows {
+		return nil, NewError(ErrCodeNotFound, fmt.Sprintf("symbol analysis not found: %d", id))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query symbol analysis: %w", err)
+	}
+	if status != "failed" {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("symbol analysis %d is %s; only failed analyses can be retried", id, status))
+	}
+
+	settings, err := c.GetAISettings()
+	if err != nil {
+		return nil, fmt.Errorf("load ai settings: %w", err)
+	}
+	return c.analyzeSymbol(SymbolAnalysisRequest{
+		Context:        ctx,
+		BaseURL:        settings.BaseURL,
+		APIKey:         apiKey,
+		Model:          model,
+		Symbol:         symbol,
+		Currency:       currency,
+		RiskProfile:    settings.RiskProfile,
+		Horizon:        settings.Horizon,
+		AdviceStyle:    settings.AdviceStyle,
+		StrategyPrompt: storedStrategy.String,
+		Force:          true,
+	}, nil, nil)
+}
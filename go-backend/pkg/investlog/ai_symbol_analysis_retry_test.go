@@ -0,0 +1,56 @@
+package investlog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestRetrySymbolAnalysis_CompletesFailedRun(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-retry", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-retry")
+	id := seedSymbolAnalysisRow(t, core, "failed")
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	var (
+		mu        sync.Mutex
+		usedModel string
+	)
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		mu.Lock()
+		usedModel = req.Model
+		mu.Unlock()
+		return dimensionStubRouter(ctx, req)
+	}
+
+	result, err := core.RetrySymbolAnalysis(context.Background(), id, "test-key")
+	if err != nil {
+		t.Fatalf("RetrySymbolAnalysis failed: %v", err)
+	}
+	if result.Status != "completed" || result.Symbol != "AAPL" {
+		t.Fatalf("expected completed AAPL analysis, got %+v", result)
+	}
+	if usedModel != "gemini-2.5-pro" {
+		t.Fatalf("expected stored model to be reused, got %q", usedModel)
+	}
+	if result.ID == id {
+		t.Fatal("expected retry to be recorded as a new run")
+	}
+
+	if _, err := core.RetrySymbolAnalysis(context.Background(), result.ID, "test-key"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected INVALID_INPUT for completed analysis, got %v", err)
+	}
+	if _, err := core.RetrySymbolAnalysis(context.Background(), 999, "test-key"); !IsErrorCode(err, ErrCodeNotFound) {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+}
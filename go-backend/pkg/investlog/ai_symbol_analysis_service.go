@@ -9,26 +9,29 @@ import (
 
 // AnalyzeSymbol runs a multi-agent deep analysis for a single symbol.
 func (c *Core) AnalyzeSymbol(req SymbolAnalysisRequest) (*SymbolAnalysisResult, error) {
-	return c.analyzeSymbol(req, nil)
+	return c.analyzeSymbol(req, nil, nil)
 }
 
 // AnalyzeSymbolWithStream runs symbol analysis with stream envelope events.
-// It suppresses intermediate model token deltas to avoid noisy UI output.
-func (c *Core) AnalyzeSymbolWithStream(req SymbolAnalysisRequest, onDelta func(string)) (*SymbolAnalysisResult, error) {
+// It suppresses intermediate model token deltas to avoid noisy UI output;
+// onProgress, when set, is called as each dimension agent returns and once
+// before synthesis starts.
+func (c *Core) AnalyzeSymbolWithStream(req SymbolAnalysisRequest, onDelta func(string), onProgress func(SymbolAnalysisProgress)) (*SymbolAnalysisResult, error) {
 	_ = onDelta
-	return c.analyzeSymbol(req, nil)
+	return c.analyzeSymbol(req, nil, onProgress)
 }
 
-func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*SymbolAnalysisResult, error) {
+func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string), onProgress func(SymbolAnalysisProgress)) (*SymbolAnalysisResult, error) {
 	// Suppress intermediate token output for symbol analysis stream.
 	onDelta = nil
 
+	req.APIKey = c.resolveAIAPIKey(req.APIKey)
 	normalizedReq, err := normalizeSymbolAnalysisRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
-	contextData, err := c.buildSymbolContext(normalizedReq.Symbol, normalizedReq.Currency)
+	contextData, err := c.buildSymbolContext(normalizedReq.Symbol, normalizedReq.Currency, normalizedReq.PositionBasis)
 	if err != nil {
 		return nil, err
 	}
@@ -43,20 +46,53 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), symbolAnalysisTimeout)
+	ctx, cancel := context.WithTimeout(requestContext(normalizedReq.Context), symbolAnalysisTimeout)
 	defer cancel()
+	ctx, logger := c.withAnalysisLogger(ctx)
+	logger.Info("symbol analysis started", "symbol", normalizedReq.Symbol, "currency", normalizedReq.Currency, "model", normalizedReq.Model)
+
+	// Fetch external data first: the cache key covers the raw sections, so
+	// a cache hit skips every model call, including the summary.
+	externalData := c.fetchExternalData(ctx, normalizedReq, symbolContextJSON)
+	var externalSections []ExternalDataSection
+	if externalData != nil {
+		externalSections = externalData.RawSections
+	}
+	inputHash, err := symbolAnalysisInputHash(contextData, normalizedReq, externalSections)
+	if err != nil {
+		return nil, err
+	}
+	if !normalizedReq.Force {
+		cached, err := c.findCachedSymbolAnalysis(normalizedReq.Symbol, normalizedReq.Currency, inputHash)
+		if err != nil {
+			logger.Warn("symbol analysis cache lookup failed", "symbol", normalizedReq.Symbol, "err", err)
+		} else if cached != nil {
+			logger.Info("symbol analysis served from cache", "symbol", normalizedReq.Symbol, "currency", normalizedReq.Currency, "id", cached.ID)
+			return cached, nil
+		}
+	}
 
 	// Insert pending row.
 	rowID, err := c.insertPendingSymbolAnalysis(normalizedReq)
 	if err != nil {
 		return nil, fmt.Errorf("save pending analysis: %w", err)
 	}
+	if err := c.setSymbolAnalysisInputHash(rowID, inputHash); err != nil {
+		logger.Warn("failed to store symbol analysis input hash", "id", rowID, "err", err)
+	}
 
-	// Fetch and summarize external data (graceful degradation on failure).
+	// Summarize external data (graceful degradation on failure).
 	var enrichedContext string
-	externalData := fetchExternalDataFn(ctx, normalizedReq.Symbol, normalizedReq.Currency, c.Logger())
 	if externalData != nil {
-		summary := summarizeExternalDataFn(ctx, externalData, endpointURL, normalizedReq.APIKey, normalizedReq.Model, c.Logger())
+		summary := externalData.Summary
+		if summary == "" {
+			// A busy AI slot skips the summary; the reserved slot carries
+			// the summarizer's call.
+			if slotCtx, release, err := c.acquireAISlots(ctx, 1); err == nil {
+				summary = summarizeExternalDataFn(c, slotCtx, externalData, endpointURL, normalizedReq.APIKey, normalizedReq.Model, logger)
+				release()
+			}
+		}
 		if summary != "" {
 			enrichedContext = summary
 			externalData.Summary = summary
@@ -88,6 +124,10 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 	// Build user prompt for framework agents.
 	userPrompt := buildDimensionUserPrompt(symbolContextJSON, enrichedContext, normalizedReq, selectedFrameworkIDs)
 
+	if err := c.markSymbolAnalysisRunning(rowID); err != nil {
+		logger.Warn("failed to mark symbol analysis running", "id", rowID, "err", err)
+	}
+
 	// Run 3 framework agents in parallel.
 	dimensionOutputs, err := c.runDimensionAgents(
 		ctx,
@@ -97,6 +137,7 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 		selectedFrameworks,
 		userPrompt,
 		onDelta,
+		onProgress,
 	)
 	if err != nil {
 		_ = c.updateSymbolAnalysisStatus(rowID, "failed", err.Error())
@@ -113,7 +154,7 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 
 		parsed, parseErr := parseSymbolDimensionResult(rawOutput)
 		if parseErr != nil {
-			c.Logger().Warn("failed to parse framework result", "framework", frameworkID, "err", parseErr)
+			logger.Warn("failed to parse framework result", "framework", frameworkID, "err", parseErr)
 			continue
 		}
 		normalizeDimensionResult(parsed, frameworkID)
@@ -121,7 +162,7 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 
 		normalizedJSON, marshalErr := json.Marshal(parsed)
 		if marshalErr != nil {
-			c.Logger().Warn("failed to marshal normalized framework result", "framework", frameworkID, "err", marshalErr)
+			logger.Warn("failed to marshal normalized framework result", "framework", frameworkID, "err", marshalErr)
 			normalizedDimensionOutputs[frameworkID] = rawOutput
 			continue
 		}
@@ -133,6 +174,10 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 		return nil, err
 	}
 
+	if err := c.saveSymbolAnalysisDimensions(rowID, normalizedDimensionOutputs); err != nil {
+		logger.Warn("failed to save framework results", "id", rowID, "err", err)
+	}
+
 	preferenceContext := symbolPreferenceContext{
 		RiskProfile:    normalizedReq.RiskProfile,
 		Horizon:        normalizedReq.Horizon,
@@ -141,8 +186,17 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 	}
 	weightContext := buildSynthesisWeightContext(contextData, preferenceContext)
 
+	if onProgress != nil {
+		onProgress(SymbolAnalysisProgress{
+			Stage:     SymbolAnalysisStageSynthesis,
+			Completed: len(dimensionOutputs),
+			Total:     len(selectedFrameworks),
+			Message:   "正在综合各维度结论",
+		})
+	}
+
 	// Run synthesis agent sequentially.
-	synthesisOutput, err := runSynthesisAgent(
+	synthesisOutput, err := c.runSynthesisAgent(
 		ctx,
 		endpointURL,
 		normalizedReq.APIKey,
@@ -170,7 +224,7 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 	if normalizedJSON, marshalErr := json.Marshal(synthesis); marshalErr == nil {
 		synthesisToSave = string(normalizedJSON)
 	} else {
-		c.Logger().Warn("failed to marshal normalized synthesis", "err", marshalErr)
+		logger.Warn("failed to marshal normalized synthesis", "err", marshalErr)
 	}
 
 	// Save completed result.
@@ -182,12 +236,13 @@ func (c *Core) analyzeSymbol(req SymbolAnalysisRequest, onDelta func(string)) (*
 		Status:     "completed",
 		Dimensions: dimensions,
 		Synthesis:  synthesis,
-		CreatedAt:  NowRFC3339InShanghai(),
+		CreatedAt:  c.NowRFC3339(),
 	}
 
 	if err := c.saveCompletedSymbolAnalysis(rowID, normalizedDimensionOutputs, synthesisToSave, enrichedContext); err != nil {
 		return nil, fmt.Errorf("save analysis result: %w", err)
 	}
+	c.recordSymbolAnalysisLog(result)
 
 	return result, nil
 }
@@ -222,16 +277,16 @@ currency: %s
 - 缺失信息必须写“缺口：...”。
 - 禁止编造来源。`, symbol, currency, symbolContext)
 
-	result, err := aiChatCompletion(ctx, aiChatCompletionRequest{
+	result, err := c.chatCompletion(ctx, aiChatCompletionRequest{
 		EndpointURL:  endpoint,
 		APIKey:       apiKey,
 		Model:        model,
 		SystemPrompt: systemPrompt,
 		UserPrompt:   userPrompt,
-		Logger:       c.Logger(),
+		Logger:       c.analysisLogger(ctx),
 	})
 	if err != nil {
-		c.Logger().Warn("symbol retrieval context failed",
+		c.analysisLogger(ctx).Warn("symbol retrieval context failed",
 			"symbol", symbol,
 			"currency", currency,
 			"model", model,
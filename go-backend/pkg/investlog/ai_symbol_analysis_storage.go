@@ -1,6 +1,7 @@
 package investlog
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 )
@@ -25,6 +26,30 @@ func (c *Core) updateSymbolAnalysisStatus(id int64, status, errMsg string) error
 	return err
 }
 
+// markSymbolAnalysisRunning moves a pending row to running once its agents
+// start, so pollers can tell queued work from in-flight work.
+func (c *Core) markSymbolAnalysisRunning(id int64) error {
+	_, err := c.db.Exec(
+		`UPDATE symbol_analyses SET status = 'running' WHERE id = ? AND status = 'pending'`,
+		id,
+	)
+	return err
+}
+
+// DeleteSymbolAnalysis removes symbol analysis id. It reports false when
+// there is no such analysis.
+func (c *Core) DeleteSymbolAnalysis(id int64) (bool, error) {
+	result, err := c.db.Exec("DELETE FROM symbol_analyses WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("delete symbol analysis: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
 func orderedDimensionOutputKeys(dimensionOutputs map[string]string) []string {
 	orderedKeys := make([]string, 0, len(dimensionOutputs))
 	seen := make(map[string]struct{}, len(dimensionOutputs))
@@ -117,7 +142,15 @@ func orderedDimensionIDs(dimensions map[string]*SymbolDimensionResult) []string
 func (c *Core) saveCompletedSymbolAnalysis(id int64, dimensionOutputs map[string]string, synthesisOutput string, externalDataSummary string) error {
 	macroOutput, industryOutput, companyOutput, internationalOutput := mapDimensionOutputsToLegacyColumns(dimensionOutputs)
 
-	_, err := c.db.Exec(
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(
 		`UPDATE symbol_analyses
 		 SET status = 'completed',
 		     macro_analysis = ?,
@@ -135,6 +168,93 @@ func (c *Core) saveCompletedSymbolAnalysis(id int64, dimensionOutputs map[string
 		synthesisOutput,
 		externalDataSummary,
 		id,
-	)
-	return err
+	); err != nil {
+		return err
+	}
+	if err := replaceSymbolAnalysisRatings(tx, id, dimensionOutputs); err != nil {
+		return err
+	}
+	if err := setSymbolAnalysisRating(tx, id, overallRatingDimension, synthesisOutputRating(synthesisOutput)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordSymbolAnalysisLog adds the analysis to the operation log. Failures
+// are only logged so the analysis result is still returned.
+func (c *Core) recordSymbolAnalysisLog(result *SymbolAnalysisResult) {
+	rating := ""
+	if result.Synthesis != nil {
+		rating = result.Synthesis.OverallRating
+	}
+	details := fmt.Sprintf("model=%s rating=%s", result.Model, rating)
+	if _, err := c.AddOperationLog(OperationLog{
+		Operation: OperationAISymbolAnalysis,
+		Symbol:    stringPtr(result.Symbol),
+		Currency:  stringPtr(result.Currency),
+		Details:   &details,
+	}); err != nil {
+		c.Logger().Warn("failed to record symbol analysis operation log", "symbol", result.Symbol, "err", err)
+	}
+}
+
+// saveSymbolAnalysisDimensions stores framework outputs before synthesis runs,
+// so a failed synthesis can later be retried without rerunning the agents.
+func (c *Core) saveSymbolAnalysisDimensions(id int64, dimensionOutputs map[string]string) error {
+	macroOutput, industryOutput, companyOutput, internationalOutput := mapDimensionOutputsToLegacyColumns(dimensionOutputs)
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(
+		`UPDATE symbol_analyses
+		 SET macro_analysis = ?,
+		     industry_analysis = ?,
+		     company_analysis = ?,
+		     international_analysis = ?
+		 WHERE id = ?`,
+		macroOutput,
+		industryOutput,
+		companyOutput,
+		internationalOutput,
+		id,
+	); err != nil {
+		return err
+	}
+	if err := replaceSymbolAnalysisRatings(tx, id, dimensionOutputs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *Core) saveSymbolAnalysisSynthesis(id int64, synthesisOutput string) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(
+		`UPDATE symbol_analyses
+		 SET status = 'completed',
+		     synthesis = ?,
+		     error_message = NULL,
+		     completed_at = CURRENT_TIMESTAMP
+		 WHERE id = ?`,
+		synthesisOutput,
+		id,
+	); err != nil {
+		return err
+	}
+	if err := setSymbolAnalysisRating(tx, id, overallRatingDimension, synthesisOutputRating(synthesisOutput)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
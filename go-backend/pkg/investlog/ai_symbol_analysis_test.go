@@ -185,6 +185,7 @@ func TestAnalyzeSymbol_SelectsStableThreeFrameworks(t *testing.T) {
 	if err != nil {
 		t.Fatalf("AnalyzeSymbol first run failed: %v", err)
 	}
+	req.Force = true
 	secondResult, err := core.AnalyzeSymbol(req)
 	if err != nil {
 		t.Fatalf("AnalyzeSymbol second run failed: %v", err)
@@ -270,6 +271,17 @@ func TestAnalyzeSymbol_EndToEnd(t *testing.T) {
 	if len(result.Synthesis.ActionItems) == 0 {
 		t.Fatal("expected at least one action item")
 	}
+
+	logs, err := core.GetOperationLogs(OperationLogFilter{OperationType: OperationAISymbolAnalysis, Symbol: "AAPL"})
+	if err != nil {
+		t.Fatalf("GetOperationLogs failed: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 symbol analysis log, got %d", len(logs))
+	}
+	if logs[0].Details == nil || !strings.Contains(*logs[0].Details, "rating=buy") {
+		t.Fatalf("expected rating in log details, got %v", logs[0].Details)
+	}
 }
 
 func TestAnalyzeSymbolWithStream_SuppressesIntermediateDelta(t *testing.T) {
@@ -305,7 +317,7 @@ func TestAnalyzeSymbolWithStream_SuppressesIntermediateDelta(t *testing.T) {
 	}, func(delta string) {
 		streamed.WriteString(delta)
 		streamed.WriteString("\n")
-	})
+	}, nil)
 	if err != nil {
 		t.Fatalf("AnalyzeSymbolWithStream failed: %v", err)
 	}
@@ -318,6 +330,103 @@ func TestAnalyzeSymbolWithStream_SuppressesIntermediateDelta(t *testing.T) {
 	}
 }
 
+func TestAnalyzeSymbolWithStream_ReportsDimensionProgress(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-progress", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-progress")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	var mu sync.Mutex
+	var events []SymbolAnalysisProgress
+	synthesisAnnounced := false
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if strings.Contains(req.SystemPrompt, "综合投资分析师") {
+			mu.Lock()
+			synthesisAnnounced = len(events) > 0 && events[len(events)-1].Stage == SymbolAnalysisStageSynthesis
+			mu.Unlock()
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	result, err := core.AnalyzeSymbolWithStream(SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+	}, nil, func(progress SymbolAnalysisProgress) {
+		mu.Lock()
+		events = append(events, progress)
+		mu.Unlock()
+	})
+	assertNoError(t, err, "AnalyzeSymbolWithStream")
+
+	if len(events) != len(result.Dimensions)+1 {
+		t.Fatalf("expected %d progress events, got %+v", len(result.Dimensions)+1, events)
+	}
+	for i, event := range events[:len(events)-1] {
+		if event.Stage != SymbolAnalysisStageDimensionDone || result.Dimensions[event.Dimension] == nil {
+			t.Fatalf("event %d: expected dimension_done for a result dimension, got %+v", i, event)
+		}
+		if event.Completed != i+1 || event.Total != len(result.Dimensions) {
+			t.Fatalf("event %d: unexpected counts %+v", i, event)
+		}
+	}
+	if last := events[len(events)-1]; last.Stage != SymbolAnalysisStageSynthesis {
+		t.Fatalf("expected synthesis as the last event, got %+v", last)
+	}
+	if !synthesisAnnounced {
+		t.Fatal("expected synthesis progress before the synthesis agent ran")
+	}
+}
+
+func TestRunDimensionAgents_ReportsFailedDimension(t *testing.T) {
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+
+	frameworks := symbolFrameworkCatalog[:3]
+	failing := buildFrameworkSystemPrompt(frameworks[0])
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if req.SystemPrompt == failing {
+			return aiChatCompletionResult{}, errors.New("upstream timeout")
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	var events []SymbolAnalysisProgress
+	_, err := (&Core{}).runDimensionAgents(context.Background(), "https://example.com/v1/chat/completions", "key", "model",
+		frameworks, "prompt", nil, func(progress SymbolAnalysisProgress) {
+			events = append(events, progress)
+		})
+	if err == nil {
+		t.Fatal("expected insufficient analyses error")
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected a progress event per agent, got %+v", events)
+	}
+	failed := 0
+	for _, event := range events {
+		if event.Stage == SymbolAnalysisStageDimensionFailed {
+			failed++
+			if event.Dimension != frameworks[0].ID {
+				t.Fatalf("unexpected failed dimension %+v", event)
+			}
+		}
+	}
+	if failed != 1 || events[2].Completed != 3 {
+		t.Fatalf("expected one failed event and final count 3, got %+v", events)
+	}
+}
+
 func TestAnalyzeSymbol_Validation(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -496,7 +605,7 @@ func TestAnalyzeSymbol_UsesPrimaryGeminiConfigForExternalSummary(t *testing.T) {
 			Symbol:      "AAPL",
 			Market:      "us",
 			FetchedAt:   time.Now(),
-			RawSections: []externalDataSection{{Source: "stub", Type: "news", Content: "headline"}},
+			RawSections: []ExternalDataSection{{Source: "stub", Type: "news", Content: "headline"}},
 		}
 	}
 
@@ -506,7 +615,7 @@ func TestAnalyzeSymbol_UsesPrimaryGeminiConfigForExternalSummary(t *testing.T) {
 	var summaryEndpoint string
 	var summaryAPIKey string
 	var summaryModel string
-	summarizeExternalDataFn = func(_ context.Context, _ *symbolExternalData, endpoint, apiKey, model string, _ *slog.Logger) string {
+	summarizeExternalDataFn = func(_ *Core, _ context.Context, _ *symbolExternalData, endpoint, apiKey, model string, _ *slog.Logger) string {
 		summaryEndpoint = endpoint
 		summaryAPIKey = apiKey
 		summaryModel = model
@@ -542,20 +651,20 @@ func TestAnalyzeSymbol_UsesPrimaryGeminiConfigForExternalSummary(t *testing.T) {
 	if summaryAPIKey != "main-key" {
 		t.Fatalf("expected primary api key main-key, got %q", summaryAPIKey)
 	}
-	if summaryModel != defaultAIModel {
-		t.Fatalf("expected primary gemini model %q, got %q", defaultAIModel, summaryModel)
+	if summaryModel != "gpt-4o" {
+		t.Fatalf("expected primary model gpt-4o, got %q", summaryModel)
 	}
 
-	if result.Model != defaultAIModel {
-		t.Fatalf("expected result model to normalize to %q, got %q", defaultAIModel, result.Model)
+	if result.Model != "gpt-4o" {
+		t.Fatalf("expected result model to stay gpt-4o, got %q", result.Model)
 	}
 
 	if len(seenModels) == 0 {
 		t.Fatal("expected framework/synthesis model calls")
 	}
 	for _, model := range seenModels {
-		if model != defaultAIModel {
-			t.Fatalf("expected framework/synthesis to use %q, got %q", defaultAIModel, model)
+		if model != "gpt-4o" {
+			t.Fatalf("expected framework/synthesis to use gpt-4o, got %q", model)
 		}
 	}
 }
@@ -578,7 +687,7 @@ func TestAnalyzeSymbol_UsesPrimaryGeminiContextRetrievalWhenExternalDataMissing(
 
 	origSummarize := summarizeExternalDataFn
 	defer func() { summarizeExternalDataFn = origSummarize }()
-	summarizeExternalDataFn = func(_ context.Context, _ *symbolExternalData, _, _, _ string, _ *slog.Logger) string {
+	summarizeExternalDataFn = func(_ *Core, _ context.Context, _ *symbolExternalData, _, _, _ string, _ *slog.Logger) string {
 		t.Fatal("summarizeExternalDataFn should not be called when external data is nil")
 		return ""
 	}
@@ -613,15 +722,15 @@ func TestAnalyzeSymbol_UsesPrimaryGeminiContextRetrievalWhenExternalDataMissing(
 	if got := atomic.LoadInt32(&retrievalCalls); got == 0 {
 		t.Fatal("expected Gemini context retrieval call when external data is missing")
 	}
-	if result.Model != defaultAIModel {
-		t.Fatalf("expected result model to normalize to %q, got %q", defaultAIModel, result.Model)
+	if result.Model != "gpt-4o" {
+		t.Fatalf("expected result model to stay gpt-4o, got %q", result.Model)
 	}
 	if len(mainModelCalls) == 0 {
 		t.Fatal("expected main model calls for framework/synthesis")
 	}
 	for _, model := range mainModelCalls {
-		if model != defaultAIModel {
-			t.Fatalf("expected framework/synthesis to use %q, got %q", defaultAIModel, model)
+		if model != "gpt-4o" {
+			t.Fatalf("expected framework/synthesis to use gpt-4o, got %q", model)
 		}
 	}
 }
@@ -754,7 +863,7 @@ func TestGetSymbolAnalysisHistory(t *testing.T) {
 	}
 
 	// Fetch all.
-	results, err := core.GetSymbolAnalysisHistory("AAPL", "USD", 10)
+	results, err := core.GetSymbolAnalysisHistory("AAPL", "USD", 10, 0)
 	if err != nil {
 		t.Fatalf("GetSymbolAnalysisHistory failed: %v", err)
 	}
@@ -780,7 +889,7 @@ func TestGetSymbolAnalysisHistory(t *testing.T) {
 	}
 
 	// Verify limit works.
-	limited, err := core.GetSymbolAnalysisHistory("AAPL", "USD", 2)
+	limited, err := core.GetSymbolAnalysisHistory("AAPL", "USD", 2, 0)
 	if err != nil {
 		t.Fatalf("GetSymbolAnalysisHistory with limit failed: %v", err)
 	}
@@ -789,7 +898,7 @@ func TestGetSymbolAnalysisHistory(t *testing.T) {
 	}
 
 	// Non-existent symbol returns empty slice.
-	empty, err := core.GetSymbolAnalysisHistory("ZZZZ", "USD", 10)
+	empty, err := core.GetSymbolAnalysisHistory("ZZZZ", "USD", 10, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -798,6 +907,89 @@ func TestGetSymbolAnalysisHistory(t *testing.T) {
 	}
 }
 
+func TestGetSymbolAnalysisHistory_OffsetPaging(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		res, err := core.db.Exec(
+			`INSERT INTO symbol_analyses (symbol, currency, model, status, synthesis, created_at, completed_at)
+			 VALUES ('AAPL', 'USD', 'm', 'completed', ?, '2026-01-02 03:04:05', '2026-01-02 03:04:05')`,
+			stubSynthesisJSON,
+		)
+		if err != nil {
+			t.Fatalf("insert row %d: %v", i, err)
+		}
+		id, _ := res.LastInsertId()
+		ids = append(ids, id)
+	}
+	if _, err := core.db.Exec(
+		`INSERT INTO symbol_analyses (symbol, currency, model, status, error_message) VALUES ('AAPL', 'USD', 'm', 'failed', 'boom')`,
+	); err != nil {
+		t.Fatalf("insert failed row: %v", err)
+	}
+
+	var got []int64
+	for offset := 0; offset < 6; offset += 2 {
+		page, err := core.GetSymbolAnalysisHistory("AAPL", "USD", 2, offset)
+		if err != nil {
+			t.Fatalf("GetSymbolAnalysisHistory offset %d failed: %v", offset, err)
+		}
+		for _, item := range page {
+			got = append(got, item.ID)
+		}
+	}
+	want := []int64{ids[4], ids[3], ids[2], ids[1], ids[0]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected pages %v, got %v", want, got)
+	}
+
+	total, err := core.GetSymbolAnalysisHistoryCount("aapl", "usd")
+	if err != nil {
+		t.Fatalf("GetSymbolAnalysisHistoryCount failed: %v", err)
+	}
+	if total != 5 {
+		t.Fatalf("expected total 5 completed analyses, got %d", total)
+	}
+}
+
+func TestDeleteSymbolAnalysis(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for i := 0; i < 2; i++ {
+		res, err := core.db.Exec(
+			`INSERT INTO symbol_analyses (symbol, currency, model, status, synthesis, completed_at)
+			 VALUES ('AAPL', 'USD', 'm', 'completed', ?, CURRENT_TIMESTAMP)`,
+			stubSynthesisJSON,
+		)
+		if err != nil {
+			t.Fatalf("insert row %d: %v", i, err)
+		}
+		id, _ := res.LastInsertId()
+		ids = append(ids, id)
+	}
+
+	deleted, err := core.DeleteSymbolAnalysis(ids[1])
+	if err != nil || !deleted {
+		t.Fatalf("DeleteSymbolAnalysis(%d) = %v, %v; want true", ids[1], deleted, err)
+	}
+	history, err := core.GetSymbolAnalysisHistory("AAPL", "USD", 10, 0)
+	if err != nil {
+		t.Fatalf("GetSymbolAnalysisHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != ids[0] {
+		t.Fatalf("expected only analysis %d left, got %d results", ids[0], len(history))
+	}
+
+	deleted, err = core.DeleteSymbolAnalysis(ids[1])
+	if err != nil || deleted {
+		t.Fatalf("second DeleteSymbolAnalysis(%d) = %v, %v; want false", ids[1], deleted, err)
+	}
+}
+
 func TestBuildSymbolContext(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -812,7 +1004,7 @@ func TestBuildSymbolContext(t *testing.T) {
 	testBuyTransaction(t, core, "AAPL", 5, 120, "USD", "acc-2")
 	testBuyTransaction(t, core, "MSFT", 20, 50, "USD", "acc-1")
 
-	ctx, err := core.buildSymbolContext("AAPL", "USD")
+	ctx, err := core.buildSymbolContext("AAPL", "USD", "")
 	if err != nil {
 		t.Fatalf("buildSymbolContext failed: %v", err)
 	}
@@ -874,7 +1066,7 @@ func TestBuildSymbolContext(t *testing.T) {
 	}
 
 	// Symbol not held: should still succeed with minimal data.
-	ctx2, err := core.buildSymbolContext("NVDA", "USD")
+	ctx2, err := core.buildSymbolContext("NVDA", "USD", "")
 	if err != nil {
 		t.Fatalf("buildSymbolContext for unheld symbol failed: %v", err)
 	}
@@ -886,6 +1078,95 @@ func TestBuildSymbolContext(t *testing.T) {
 	}
 }
 
+func TestBuildSymbolContext_FractionalSharePrecision(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 0.0001, 10, "USD", "acc-1")
+	testBuyTransaction(t, core, "MSFT", 1, 50, "USD", "acc-1")
+	if err := core.UpdateLatestPrice("AAPL", "USD", NewAmount(12)); err != nil {
+		t.Fatalf("UpdateLatestPrice failed: %v", err)
+	}
+
+	ctx, err := core.buildSymbolContext("AAPL", "USD", "")
+	if err != nil {
+		t.Fatalf("buildSymbolContext failed: %v", err)
+	}
+	if ctx.TotalShares != 0 || ctx.AvgCost != 10 {
+		t.Fatalf("expected default 2-place precision, got shares=%v avg=%v", ctx.TotalShares, ctx.AvgCost)
+	}
+
+	core.quantityPrecision = 6
+	ctx, err = core.buildSymbolContext("AAPL", "USD", "")
+	if err != nil {
+		t.Fatalf("buildSymbolContext failed: %v", err)
+	}
+	if ctx.TotalShares != 0.0001 {
+		t.Fatalf("expected 0.0001 shares, got %v", ctx.TotalShares)
+	}
+	if ctx.CostBasis != 0.001 || ctx.MarketValue != 0.0012 {
+		t.Fatalf("expected cost basis 0.001 and market value 0.0012, got %v and %v", ctx.CostBasis, ctx.MarketValue)
+	}
+	if ctx.AvgCost != 10 || ctx.LatestPrice != 12 || ctx.PnLPercent != 20 {
+		t.Fatalf("expected avg 10, price 12, pnl 20%%, got %+v", ctx)
+	}
+	if weight := buildSynthesisWeightContext(ctx, symbolPreferenceContext{}); weight.HoldingsQuantity != 0.0001 {
+		t.Fatalf("expected synthesis weight to keep 0.0001 shares, got %v", weight.HoldingsQuantity)
+	}
+}
+
+func TestGetSymbolPositionWeightBasis(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := core.SetExchangeRate("USD", "CNY", 7, "manual"); err != nil {
+		t.Fatalf("SetExchangeRate failed: %v", err)
+	}
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	testBuyTransaction(t, core, "MSFT", 20, 50, "USD", "acc-1")
+	testBuyTransaction(t, core, "600519", 1, 7000, "CNY", "acc-1")
+
+	currencyWeight, err := core.GetSymbolPositionWeight("aapl", "USD", "")
+	if err != nil {
+		t.Fatalf("GetSymbolPositionWeight currency basis failed: %v", err)
+	}
+	if currencyWeight.Basis != SymbolPositionBasisCurrency {
+		t.Fatalf("expected default basis currency, got %q", currencyWeight.Basis)
+	}
+	if !floatEquals(currencyWeight.BasisTotal, 2000, 0.01) || !floatEquals(currencyWeight.PositionPercent, 50, 0.01) {
+		t.Fatalf("unexpected currency basis weight: %+v", currencyWeight)
+	}
+
+	portfolioWeight, err := core.GetSymbolPositionWeight("AAPL", "USD", "portfolio")
+	if err != nil {
+		t.Fatalf("GetSymbolPositionWeight portfolio basis failed: %v", err)
+	}
+	if !floatEquals(portfolioWeight.BasisTotal, 3000, 0.01) || !floatEquals(portfolioWeight.PositionPercent, 33.33, 0.01) {
+		t.Fatalf("unexpected portfolio basis weight: %+v", portfolioWeight)
+	}
+	if portfolioWeight.MarketValue != currencyWeight.MarketValue {
+		t.Fatalf("market value should not depend on basis: %v vs %v", portfolioWeight.MarketValue, currencyWeight.MarketValue)
+	}
+
+	ctx, err := core.buildSymbolContext("AAPL", "USD", SymbolPositionBasisPortfolio)
+	if err != nil {
+		t.Fatalf("buildSymbolContext failed: %v", err)
+	}
+	aiJSON, err := ctx.aiJSON()
+	if err != nil {
+		t.Fatalf("aiJSON failed: %v", err)
+	}
+	if !strings.Contains(aiJSON, `"position_basis":"portfolio"`) {
+		t.Fatalf("expected aiJSON to report position basis, got: %s", aiJSON)
+	}
+
+	if _, err := core.GetSymbolPositionWeight("AAPL", "USD", "household"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid basis error, got %v", err)
+	}
+}
+
 func TestParseSymbolDimensionResult(t *testing.T) {
 	t.Parallel()
 
@@ -1106,6 +1387,32 @@ func TestNormalizeSynthesisResult_EnforcesShortDisclaimer(t *testing.T) {
 	}
 }
 
+func TestNormalizeSynthesisResult_NormalizesActionItems(t *testing.T) {
+	t.Parallel()
+
+	result := &SymbolSynthesisResult{
+		TargetAction: "increase",
+		ActionItems: []SymbolAnalysisActionItem{
+			{Action: "  分批加仓  ", Rationale: " 估值回落 "},
+			{Action: " ", Rationale: "", Priority: " "},
+			{Action: "设置止损", Priority: " HIGH "},
+		},
+	}
+
+	normalizeSynthesisResult(result, nil)
+
+	if len(result.ActionItems) != 2 {
+		t.Fatalf("expected empty action item dropped, got %+v", result.ActionItems)
+	}
+	first := result.ActionItems[0]
+	if first.Action != "分批加仓" || first.Rationale != "估值回落" || first.Priority != "medium" {
+		t.Fatalf("expected trimmed item with default priority, got %+v", first)
+	}
+	if result.ActionItems[1].Priority != "high" {
+		t.Fatalf("expected normalized priority, got %q", result.ActionItems[1].Priority)
+	}
+}
+
 func TestNormalizeSymbolAnalysisRequest(t *testing.T) {
 	t.Parallel()
 
@@ -1130,8 +1437,8 @@ func TestNormalizeSymbolAnalysisRequest(t *testing.T) {
 	if req.BaseURL != defaultAIBaseURL {
 		t.Fatalf("expected normalized base url %q, got %q", defaultAIBaseURL, req.BaseURL)
 	}
-	if req.Model != defaultAIModel {
-		t.Fatalf("expected normalized model %q, got %q", defaultAIModel, req.Model)
+	if req.Model != "gpt-4o" {
+		t.Fatalf("expected trimmed model gpt-4o, got %q", req.Model)
 	}
 	if req.Symbol != "AAPL" {
 		t.Fatalf("expected uppercased symbol AAPL, got %q", req.Symbol)
@@ -1173,8 +1480,8 @@ func TestNormalizeSymbolAnalysisRequest(t *testing.T) {
 	if defaults.BaseURL != defaultAIBaseURL {
 		t.Fatalf("expected default base url %q, got %q", defaultAIBaseURL, defaults.BaseURL)
 	}
-	if defaults.Model != defaultAIModel {
-		t.Fatalf("expected default model %q, got %q", defaultAIModel, defaults.Model)
+	if defaults.Model != "m" {
+		t.Fatalf("expected model m kept, got %q", defaults.Model)
 	}
 
 	// Missing api_key
@@ -1222,3 +1529,131 @@ func TestNormalizeSymbolAnalysisRequest(t *testing.T) {
 		t.Fatalf("expected invalid advice_style error, got %v", err)
 	}
 }
+
+func TestGetSymbolAnalysisStatuses(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	seed := []struct {
+		symbol, currency, status, createdAt string
+	}{
+		{"AAPL", "USD", "completed", "2024-01-01 10:00:00"},
+		{"AAPL", "USD", "failed", "2024-01-02 10:00:00"},
+		{"MSFT", "USD", "failed", "2024-01-01 10:00:00"},
+		{"MSFT", "USD", "running", "2024-01-03 10:00:00"},
+		{"TSLA", "USD", "pending", "2024-01-03 10:00:00"},
+		{"TSLA", "USD", "completed", "2024-01-03 10:00:00"},
+		{"NVDA", "HKD", "completed", "2024-01-05 10:00:00"},
+	}
+	for _, s := range seed {
+		if _, err := core.db.Exec(
+			`INSERT INTO symbol_analyses (symbol, currency, model, status, error_message, created_at)
+			 VALUES (?, ?, 'mock-model', ?, CASE WHEN ? = 'failed' THEN 'boom' END, ?)`,
+			s.symbol, s.currency, s.status, s.status, s.createdAt,
+		); err != nil {
+			t.Fatalf("seed symbol analysis: %v", err)
+		}
+	}
+
+	statuses, err := core.GetSymbolAnalysisStatuses([]string{"msft", "AAPL", "TSLA", "NVDA", "AAPL"}, "usd")
+	if err != nil {
+		t.Fatalf("GetSymbolAnalysisStatuses failed: %v", err)
+	}
+	want := []struct{ symbol, status string }{
+		{"MSFT", "running"},
+		{"AAPL", "failed"},
+		{"TSLA", "completed"}, // same created_at: higher id wins
+		{"NVDA", "none"},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("expected %d statuses, got %+v", len(want), statuses)
+	}
+	for i, w := range want {
+		if statuses[i].Symbol != w.symbol || statuses[i].Status != w.status {
+			t.Fatalf("status[%d]: expected %s/%s, got %+v", i, w.symbol, w.status, statuses[i])
+		}
+		if w.status != "none" && (statuses[i].ID == 0 || statuses[i].AgeSeconds <= 0) {
+			t.Fatalf("status[%d]: expected id and age, got %+v", i, statuses[i])
+		}
+	}
+	if statuses[1].ErrorMessage != "boom" {
+		t.Fatalf("expected failed status to carry error message, got %+v", statuses[1])
+	}
+
+	if _, err := core.GetSymbolAnalysisStatuses([]string{"AAPL"}, "EUR"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid currency error, got %v", err)
+	}
+}
+
+func TestGetSymbolAnalysisStatus_Progression(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	status, err := core.GetSymbolAnalysisStatus("aapl", "usd")
+	assertNoError(t, err, "GetSymbolAnalysisStatus before run")
+	if status.Status != "none" {
+		t.Fatalf("expected none before any run, got %+v", status)
+	}
+
+	// Hold synthesis until the test has observed the in-flight status.
+	synthesisStarted := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if strings.Contains(req.SystemPrompt, "综合投资分析师") {
+			once.Do(func() { close(synthesisStarted) })
+			<-release
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := core.AnalyzeSymbol(SymbolAnalysisRequest{
+			BaseURL:  "https://example.com/v1",
+			APIKey:   "test-key",
+			Model:    "mock-model",
+			Symbol:   "AAPL",
+			Currency: "USD",
+		})
+		done <- err
+	}()
+
+	select {
+	case <-synthesisStarted:
+	case err := <-done:
+		t.Fatalf("analysis finished before synthesis was held: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for synthesis to start")
+	}
+	status, err = core.GetSymbolAnalysisStatus("AAPL", "USD")
+	assertNoError(t, err, "GetSymbolAnalysisStatus while running")
+	if status.Status != "running" || status.ID == 0 || status.CreatedAt == "" {
+		t.Fatalf("expected running status with id and start time, got %+v", status)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("AnalyzeSymbol failed: %v", err)
+	}
+	status, err = core.GetSymbolAnalysisStatus("AAPL", "USD")
+	assertNoError(t, err, "GetSymbolAnalysisStatus after run")
+	if status.Status != "completed" || status.CompletedAt == "" {
+		t.Fatalf("expected completed status, got %+v", status)
+	}
+
+	if _, err := core.GetSymbolAnalysisStatus(" ", "USD"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected missing symbol error, got %v", err)
+	}
+}
@@ -1,5 +1,7 @@
 package investlog
 
+import "context"
+
 const (
 	symbolAnalysisTimeout       = aiTotalRequestTimeout
 	minFrameworkAnalyses        = 3
@@ -90,6 +92,29 @@ type SymbolAnalysisRequest struct {
 	Horizon        string
 	AdviceStyle    string
 	StrategyPrompt string
+	// PositionBasis selects the position_percent denominator: "currency"
+	// (default, the symbol's currency bucket) or "portfolio" (all currencies).
+	PositionBasis string
+	// Force skips the input-hash cache and always runs the agents.
+	Force bool
+	// Context bounds the AI calls; it defaults to context.Background().
+	Context context.Context
+}
+
+// Position percent denominators for symbol analysis.
+const (
+	SymbolPositionBasisCurrency  = "currency"
+	SymbolPositionBasisPortfolio = "portfolio"
+)
+
+// SymbolPositionWeight is the holdings weight fed to symbol analysis as position_percent.
+type SymbolPositionWeight struct {
+	Symbol          string  `json:"symbol"`
+	Currency        string  `json:"currency"`
+	Basis           string  `json:"basis"`
+	MarketValue     float64 `json:"market_value"`
+	BasisTotal      float64 `json:"basis_total"`
+	PositionPercent float64 `json:"position_percent"`
 }
 
 // SymbolDimensionResult is one dimension's analysis output.
@@ -127,6 +152,37 @@ type SymbolSynthesisResult struct {
 	Disclaimer         string                     `json:"disclaimer"`
 }
 
+// SymbolAnalysisStatus summarizes the latest analysis run for one symbol.
+// Status is "none" when the symbol has never been analyzed in the currency.
+type SymbolAnalysisStatus struct {
+	Symbol       string `json:"symbol"`
+	Currency     string `json:"currency"`
+	ID           int64  `json:"id,omitempty"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	CreatedAt    string `json:"created_at,omitempty"`
+	CompletedAt  string `json:"completed_at,omitempty"`
+	AgeSeconds   int64  `json:"age_seconds,omitempty"`
+}
+
+// Symbol analysis progress stages.
+const (
+	SymbolAnalysisStageDimensionDone   = "dimension_done"
+	SymbolAnalysisStageDimensionFailed = "dimension_failed"
+	SymbolAnalysisStageSynthesis       = "synthesis"
+)
+
+// SymbolAnalysisProgress reports one step of a running symbol analysis:
+// a dimension agent returning, or synthesis starting. Completed counts the
+// dimension agents that have returned so far out of Total.
+type SymbolAnalysisProgress struct {
+	Stage     string `json:"stage"`
+	Dimension string `json:"dimension,omitempty"`
+	Completed int    `json:"completed"`
+	Total     int    `json:"total"`
+	Message   string `json:"message"`
+}
+
 // SymbolAnalysisResult is the full result returned to clients.
 type SymbolAnalysisResult struct {
 	ID           int64                             `json:"id"`
@@ -139,6 +195,8 @@ type SymbolAnalysisResult struct {
 	ErrorMessage string                            `json:"error_message,omitempty"`
 	CreatedAt    string                            `json:"created_at"`
 	CompletedAt  string                            `json:"completed_at,omitempty"`
+	// Cached is true when an earlier analysis with identical inputs was reused.
+	Cached bool `json:"cached,omitempty"`
 }
 
 type symbolContextData struct {
@@ -153,6 +211,8 @@ type symbolContextData struct {
 	MarketValue              float64  `json:"market_value,omitempty"`
 	PnLPercent               float64  `json:"pnl_percent,omitempty"`
 	PositionPercent          float64  `json:"position_percent,omitempty"`
+	PositionBasis            string   `json:"position_basis,omitempty"`
+	PositionBasisTotal       float64  `json:"position_basis_total,omitempty"`
 	CurrencyTotalMarketValue float64  `json:"currency_total_market_value,omitempty"`
 	AccountName              string   `json:"account_name,omitempty"`
 	AccountNames             []string `json:"account_names,omitempty"`
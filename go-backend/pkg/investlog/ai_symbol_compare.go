@@ -0,0 +1,166 @@
+package investlog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxCompareSymbols is the most symbols one comparison analyzes. They run
+// one after another, so a comparison can take this many symbol analyses.
+const MaxCompareSymbols = 4
+
+// SymbolComparisonRating is one symbol's view of a dimension. Rating is
+// empty when that symbol's analysis did not cover the dimension.
+type SymbolComparisonRating struct {
+	Symbol     string `json:"symbol"`
+	Rating     string `json:"rating"`
+	Confidence string `json:"confidence,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+}
+
+// SymbolComparisonDimension lines up one dimension across the compared
+// symbols; Ratings follow SymbolComparison.Symbols.
+type SymbolComparisonDimension struct {
+	Dimension string                   `json:"dimension"`
+	Ratings   []SymbolComparisonRating `json:"ratings"`
+}
+
+// SymbolComparisonVerdict is one symbol's synthesis, or the error that
+// stopped its analysis.
+type SymbolComparisonVerdict struct {
+	Symbol            string  `json:"symbol"`
+	AnalysisID        int64   `json:"analysis_id,omitempty"`
+	OverallRating     string  `json:"overall_rating,omitempty"`
+	TargetAction      string  `json:"target_action,omitempty"`
+	Confidence        string  `json:"confidence,omitempty"`
+	ActionProbability float64 `json:"action_probability_percent,omitempty"`
+	OverallSummary    string  `json:"overall_summary,omitempty"`
+	Error             string  `json:"error,omitempty"`
+}
+
+// SymbolComparison is a side-by-side view of several symbol analyses.
+type SymbolComparison struct {
+	Currency   string                      `json:"currency"`
+	Symbols    []string                    `json:"symbols"`
+	Dimensions []SymbolComparisonDimension `json:"dimensions"`
+	Verdicts   []SymbolComparisonVerdict   `json:"verdicts"`
+}
+
+// CompareSymbols analyzes each symbol with req's settings and lines up their
+// dimension ratings and synthesis verdicts. req.Symbol is ignored. The
+// symbols are analyzed one at a time, since each analysis already fans out
+// to several framework agents. A symbol whose analysis fails is reported in
+// its verdict; when every analysis fails, the comparison is returned along
+// with the joined error.
+func (c *Core) CompareSymbols(req SymbolAnalysisRequest, symbols []string) (*SymbolComparison, error) {
+	symbols = normalizeCompareSymbols(symbols)
+	if len(symbols) < 2 || len(symbols) > MaxCompareSymbols {
+		return nil, NewValidationError("symbols", fmt.Sprintf("symbols must list 2 to %d distinct symbols", MaxCompareSymbols))
+	}
+	req.APIKey = c.resolveAIAPIKey(req.APIKey)
+	req.Symbol = symbols[0]
+	normalizedReq, err := normalizeSymbolAnalysisRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*SymbolAnalysisResult, len(symbols))
+	errs := make([]error, len(symbols))
+	failed := 0
+	for i, symbol := range symbols {
+		symbolReq := normalizedReq
+		symbolReq.Symbol = symbol
+		results[i], errs[i] = c.AnalyzeSymbol(symbolReq)
+		if errs[i] != nil {
+			failed++
+			errs[i] = fmt.Errorf("%s: %w", symbol, errs[i])
+			c.Logger().Warn("compare symbols: analysis failed", "symbol", symbol, "err", errs[i])
+		}
+	}
+	comparison := buildSymbolComparison(normalizedReq.Currency, symbols, results, errs)
+	if failed == len(symbols) {
+		return comparison, fmt.Errorf("analyze symbols: %w", errors.Join(errs...))
+	}
+	return comparison, nil
+}
+
+func normalizeCompareSymbols(symbols []string) []string {
+	seen := make(map[string]struct{}, len(symbols))
+	out := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" {
+			continue
+		}
+		if _, ok := seen[symbol]; ok {
+			continue
+		}
+		seen[symbol] = struct{}{}
+		out = append(out, symbol)
+	}
+	return out
+}
+
+// buildSymbolComparison aligns the results by dimension. Symbols may have
+// been analyzed under different frameworks, so every dimension any of them
+// covers gets a row, in name order.
+func buildSymbolComparison(currency string, symbols []string, results []*SymbolAnalysisResult, errs []error) *SymbolComparison {
+	comparison := &SymbolComparison{
+		Currency:   currency,
+		Symbols:    symbols,
+		Dimensions: []SymbolComparisonDimension{},
+		Verdicts:   make([]SymbolComparisonVerdict, 0, len(symbols)),
+	}
+
+	dimensionSet := map[string]struct{}{}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for dimension := range result.Dimensions {
+			dimensionSet[dimension] = struct{}{}
+		}
+	}
+	dimensions := make([]string, 0, len(dimensionSet))
+	for dimension := range dimensionSet {
+		dimensions = append(dimensions, dimension)
+	}
+	sort.Strings(dimensions)
+
+	for _, dimension := range dimensions {
+		row := SymbolComparisonDimension{Dimension: dimension, Ratings: make([]SymbolComparisonRating, 0, len(symbols))}
+		for i, symbol := range symbols {
+			rating := SymbolComparisonRating{Symbol: symbol}
+			if results[i] != nil {
+				if dim := results[i].Dimensions[dimension]; dim != nil {
+					rating.Rating = dim.Rating
+					rating.Confidence = dim.Confidence
+					rating.Summary = dim.Summary
+				}
+			}
+			row.Ratings = append(row.Ratings, rating)
+		}
+		comparison.Dimensions = append(comparison.Dimensions, row)
+	}
+
+	for i, symbol := range symbols {
+		verdict := SymbolComparisonVerdict{Symbol: symbol}
+		switch {
+		case errs[i] != nil:
+			verdict.Error = errs[i].Error()
+		case results[i] != nil:
+			verdict.AnalysisID = results[i].ID
+			if synthesis := results[i].Synthesis; synthesis != nil {
+				verdict.OverallRating = synthesis.OverallRating
+				verdict.TargetAction = synthesis.TargetAction
+				verdict.Confidence = synthesis.Confidence
+				verdict.ActionProbability = synthesis.ActionProbability
+				verdict.OverallSummary = synthesis.OverallSummary
+			}
+		}
+		comparison.Verdicts = append(comparison.Verdicts, verdict)
+	}
+	return comparison
+}
@@ -0,0 +1,178 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompareSymbols_AlignsDimensionsAndVerdicts(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-compare", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-compare")
+	testBuyTransaction(t, core, "MSFT", 5, 300, "USD", "acc-compare")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	aiChatCompletion = dimensionStubRouter
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	comparison, err := core.CompareSymbols(SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Currency: "USD",
+	}, []string{"aapl", "MSFT", "AAPL"})
+	if err != nil {
+		t.Fatalf("CompareSymbols failed: %v", err)
+	}
+	if strings.Join(comparison.Symbols, ",") != "AAPL,MSFT" {
+		t.Fatalf("expected deduplicated symbols, got %v", comparison.Symbols)
+	}
+	if len(comparison.Verdicts) != 2 {
+		t.Fatalf("expected 2 verdicts, got %+v", comparison.Verdicts)
+	}
+	for i, verdict := range comparison.Verdicts {
+		if verdict.Symbol != comparison.Symbols[i] || verdict.Error != "" || verdict.OverallRating == "" || verdict.AnalysisID == 0 {
+			t.Fatalf("expected synthesis for %s, got %+v", comparison.Symbols[i], verdict)
+		}
+	}
+	if len(comparison.Dimensions) == 0 {
+		t.Fatal("expected aligned dimensions")
+	}
+	for _, row := range comparison.Dimensions {
+		if len(row.Ratings) != 2 || row.Ratings[0].Symbol != "AAPL" || row.Ratings[1].Symbol != "MSFT" {
+			t.Fatalf("dimension %s: expected ratings in symbol order, got %+v", row.Dimension, row.Ratings)
+		}
+	}
+}
+
+func TestCompareSymbols_RunsSequentiallyAndReportsFailures(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-compare", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-compare")
+	testBuyTransaction(t, core, "MSFT", 5, 300, "USD", "acc-compare")
+
+	original := aiChatCompletion
+	defer func() { aiChatCompletion = original }()
+	var inFlight, maxInFlight int32
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if strings.Contains(req.UserPrompt, "MSFT") {
+			return aiChatCompletionResult{}, errors.New("upstream down")
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+
+	origFetch := fetchExternalDataFn
+	defer func() { fetchExternalDataFn = origFetch }()
+	fetchExternalDataFn = func(_ context.Context, _, _ string, _ *slog.Logger) *symbolExternalData {
+		return nil
+	}
+
+	req := SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Currency: "USD",
+	}
+	comparison, err := core.CompareSymbols(req, []string{"AAPL", "MSFT"})
+	if err != nil {
+		t.Fatalf("CompareSymbols failed: %v", err)
+	}
+	if comparison.Verdicts[0].Error != "" || !strings.Contains(comparison.Verdicts[1].Error, "MSFT") {
+		t.Fatalf("expected only MSFT to fail, got %+v", comparison.Verdicts)
+	}
+	if maxInFlight > int32(minFrameworkAnalyses) {
+		t.Fatalf("expected one analysis at a time, saw %d calls in flight", maxInFlight)
+	}
+
+	comparison, err = core.CompareSymbols(req, []string{"MSFT", "MSFT.US"})
+	if err == nil || comparison == nil {
+		t.Fatalf("expected the comparison with an error when every analysis fails, got %+v, %v", comparison, err)
+	}
+	for _, verdict := range comparison.Verdicts {
+		if verdict.Error == "" {
+			t.Fatalf("expected every verdict to carry its error, got %+v", comparison.Verdicts)
+		}
+	}
+}
+
+func TestCompareSymbols_Validation(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	req := SymbolAnalysisRequest{APIKey: "test-key", Model: "mock-model", Currency: "USD"}
+	var invalid *ValidationError
+	if _, err := core.CompareSymbols(req, []string{"AAPL", " aapl "}); !errors.As(err, &invalid) || invalid.Fields["symbols"] == "" {
+		t.Fatalf("expected symbols validation error, got %v", err)
+	}
+	if _, err := core.CompareSymbols(req, []string{"A", "B", "C", "D", "E"}); !errors.As(err, &invalid) {
+		t.Fatalf("expected too many symbols error, got %v", err)
+	}
+	req.Currency = "EUR"
+	if _, err := core.CompareSymbols(req, []string{"AAPL", "MSFT"}); !errors.As(err, &invalid) || invalid.Fields["currency"] == "" {
+		t.Fatalf("expected currency validation error, got %v", err)
+	}
+}
+
+func TestBuildSymbolComparison_MissingDimensionAndFailure(t *testing.T) {
+	t.Parallel()
+
+	results := []*SymbolAnalysisResult{
+		{
+			ID: 1,
+			Dimensions: map[string]*SymbolDimensionResult{
+				"macro":   {Dimension: "macro", Rating: "positive"},
+				"company": {Dimension: "company", Rating: "neutral"},
+			},
+			Synthesis: &SymbolSynthesisResult{OverallRating: "buy", TargetAction: "increase"},
+		},
+		{
+			ID: 2,
+			Dimensions: map[string]*SymbolDimensionResult{
+				"macro": {Dimension: "macro", Rating: "negative"},
+			},
+			Synthesis: &SymbolSynthesisResult{OverallRating: "hold", TargetAction: "hold"},
+		},
+		nil,
+	}
+	errs := []error{nil, nil, errors.New("no holdings")}
+	comparison := buildSymbolComparison("USD", []string{"AAPL", "MSFT", "TSLA"}, results, errs)
+
+	if len(comparison.Dimensions) != 2 || comparison.Dimensions[0].Dimension != "company" || comparison.Dimensions[1].Dimension != "macro" {
+		t.Fatalf("expected company and macro rows, got %+v", comparison.Dimensions)
+	}
+	company := comparison.Dimensions[0].Ratings
+	if company[0].Rating != "neutral" || company[1].Rating != "" || company[2].Rating != "" {
+		t.Fatalf("expected only AAPL rated on company, got %+v", company)
+	}
+	macro := comparison.Dimensions[1].Ratings
+	if macro[0].Rating != "positive" || macro[1].Rating != "negative" {
+		t.Fatalf("unexpected macro ratings: %+v", macro)
+	}
+	if comparison.Verdicts[1].OverallRating != "hold" || comparison.Verdicts[2].Error != "no holdings" {
+		t.Fatalf("unexpected verdicts: %+v", comparison.Verdicts)
+	}
+}
@@ -0,0 +1,54 @@
+package investlog
+
+import (
+	"sync"
+	"time"
+)
+
+// externalDataCacheTTL is how long fetched external data is reused by symbol
+// analyses, so a RefreshSymbolData call shortly before analyzing saves the
+// analysis its own round of scraping.
+const externalDataCacheTTL = 30 * time.Minute
+
+// externalDataCache holds the latest non-empty external data per symbol and
+// currency. A nil cache stores nothing.
+type externalDataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*symbolExternalData
+}
+
+func newExternalDataCache(ttl time.Duration) *externalDataCache {
+	return &externalDataCache{ttl: ttl, entries: map[string]*symbolExternalData{}}
+}
+
+// get returns a copy of the cached data, so callers may fill in the summary
+// without touching the cached entry.
+func (c *externalDataCache) get(symbol, currency string) (*symbolExternalData, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := symbol + "|" + currency
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.FetchedAt) > c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+	copied := *entry
+	return &copied, true
+}
+
+func (c *externalDataCache) put(symbol, currency string, data *symbolExternalData) {
+	if c == nil || data == nil {
+		return
+	}
+	copied := *data
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[symbol+"|"+currency] = &copied
+}
@@ -42,7 +42,7 @@ func fetchExternalDataImpl(ctx context.Context, symbol, currency string, logger
 	defer cancel()
 
 	type result struct {
-		section externalDataSection
+		section ExternalDataSection
 		err     error
 	}
 
@@ -67,7 +67,7 @@ func fetchExternalDataImpl(ctx context.Context, symbol, currency string, logger
 				ch <- result{err: fmt.Errorf("%s: empty content", s.Name)}
 				return
 			}
-			ch <- result{section: externalDataSection{
+			ch <- result{section: ExternalDataSection{
 				Source:  s.Name,
 				Type:    inferDataType(s.Name),
 				Content: content,
@@ -80,7 +80,7 @@ func fetchExternalDataImpl(ctx context.Context, symbol, currency string, logger
 		close(ch)
 	}()
 
-	var sections []externalDataSection
+	var sections []ExternalDataSection
 	for r := range ch {
 		if r.err != nil {
 			if logger != nil {
@@ -106,8 +106,8 @@ func fetchExternalDataImpl(ctx context.Context, symbol, currency string, logger
 	}
 }
 
-// summarizeExternalDataImpl uses AI to summarize the raw external data sections.
-func summarizeExternalDataImpl(ctx context.Context, data *symbolExternalData, endpoint, apiKey, model string, logger *slog.Logger) string {
+// summarizeExternalData uses AI to summarize the raw external data sections.
+func (c *Core) summarizeExternalData(ctx context.Context, data *symbolExternalData, endpoint, apiKey, model string, logger *slog.Logger) string {
 	if data == nil || len(data.RawSections) == 0 {
 		return ""
 	}
@@ -163,7 +163,7 @@ func summarizeExternalDataImpl(ctx context.Context, data *symbolExternalData, en
 	summarizeCtx, cancel := context.WithTimeout(ctx, externalDataSummarizeTimeout)
 	defer cancel()
 
-	result, err := aiChatCompletion(summarizeCtx, aiChatCompletionRequest{
+	result, err := c.chatCompletion(summarizeCtx, aiChatCompletionRequest{
 		EndpointURL:  endpoint,
 		APIKey:       apiKey,
 		Model:        model,
@@ -101,7 +101,7 @@ func buildAllGapSummary() string {
 	return builder.String()
 }
 
-func flattenExternalDataLines(sections []externalDataSection) []string {
+func flattenExternalDataLines(sections []ExternalDataSection) []string {
 	lines := make([]string, 0, len(sections)*3)
 	for _, section := range sections {
 		source := strings.TrimSpace(section.Source)
@@ -157,7 +157,7 @@ func containsAnyLowerKeyword(text string, keywords []string) bool {
 
 // buildDataSources returns the data sources for the given market.
 
-func buildRawSectionsText(sections []externalDataSection) string {
+func buildRawSectionsText(sections []ExternalDataSection) string {
 	if len(sections) == 0 {
 		return ""
 	}
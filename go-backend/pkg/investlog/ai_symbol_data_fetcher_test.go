@@ -324,7 +324,7 @@ func TestInferDataType(t *testing.T) {
 func TestBuildRawSectionsText(t *testing.T) {
 	t.Parallel()
 
-	sections := []externalDataSection{
+	sections := []ExternalDataSection{
 		{Source: "Source1", Type: "news", Content: "content1"},
 		{Source: "Source2", Type: "financials", Content: "content2"},
 	}
@@ -358,7 +358,7 @@ func TestFetchExternalData_NilForUnknownMarket(t *testing.T) {
 func TestSummarizeExternalData_NilData(t *testing.T) {
 	t.Parallel()
 
-	result := summarizeExternalDataImpl(context.Background(), nil, "http://example.com", "key", "model", slog.Default())
+	result := (&Core{}).summarizeExternalData(context.Background(), nil, "http://example.com", "key", "model", slog.Default())
 	if result != "" {
 		t.Fatalf("expected empty for nil data, got: %s", result)
 	}
@@ -370,9 +370,9 @@ func TestSummarizeExternalData_EmptySections(t *testing.T) {
 	data := &symbolExternalData{
 		Symbol:      "AAPL",
 		Market:      "us",
-		RawSections: []externalDataSection{},
+		RawSections: []ExternalDataSection{},
 	}
-	result := summarizeExternalDataImpl(context.Background(), data, "http://example.com", "key", "model", slog.Default())
+	result := (&Core{}).summarizeExternalData(context.Background(), data, "http://example.com", "key", "model", slog.Default())
 	if result != "" {
 		t.Fatalf("expected empty for empty sections, got: %s", result)
 	}
@@ -392,13 +392,13 @@ func TestSummarizeExternalData_PromptCoversFirstFetchStructureKeywords(t *testin
 		Symbol:    "AAPL",
 		Market:    "us",
 		FetchedAt: time.Date(2026, 2, 28, 15, 4, 0, 0, time.UTC),
-		RawSections: []externalDataSection{
+		RawSections: []ExternalDataSection{
 			{Source: "Yahoo Finance Summary", Type: "financials", Content: "Revenue Growth: 12%"},
 			{Source: "Yahoo Finance News", Type: "news", Content: "New product cycle update"},
 		},
 	}
 
-	got := summarizeExternalDataImpl(context.Background(), data, "http://example.com", "k", "m", slog.Default())
+	got := (&Core{}).summarizeExternalData(context.Background(), data, "http://example.com", "k", "m", slog.Default())
 	if strings.TrimSpace(got) == "" {
 		t.Fatal("expected non-empty summarized content")
 	}
@@ -421,7 +421,7 @@ func TestNormalizeStructuredExternalSummary_FillsMissingSections(t *testing.T) {
 		Symbol:    "AAPL",
 		Market:    "us",
 		FetchedAt: time.Date(2026, 2, 28, 15, 4, 0, 0, time.UTC),
-		RawSections: []externalDataSection{
+		RawSections: []ExternalDataSection{
 			{Source: "Yahoo Finance Summary", Type: "financials", Content: "Revenue Growth: 12%"},
 		},
 	}
@@ -441,7 +441,7 @@ func TestBuildFallbackStructuredExternalSummary_CoversSections(t *testing.T) {
 		Symbol:    "AAPL",
 		Market:    "us",
 		FetchedAt: time.Date(2026, 2, 28, 15, 4, 0, 0, time.UTC),
-		RawSections: []externalDataSection{
+		RawSections: []ExternalDataSection{
 			{
 				Source: "Yahoo Finance Summary",
 				Type:   "financials",
@@ -475,7 +475,7 @@ func TestBuildAllGapSummaryAndHelpers(t *testing.T) {
 		}
 	}
 
-	lines := flattenExternalDataLines([]externalDataSection{
+	lines := flattenExternalDataLines([]ExternalDataSection{
 		{Source: "S1", Type: "news", Content: "line1\nline2"},
 	})
 	if len(lines) < 2 {
@@ -4,7 +4,8 @@ import (
 	"time"
 )
 
-type externalDataSection struct {
+// ExternalDataSection is one block of raw external data about a symbol.
+type ExternalDataSection struct {
 	Source  string `json:"source"`
 	Type    string `json:"type"` // "news", "financials", "research"
 	Content string `json:"content"`
@@ -15,7 +16,7 @@ type symbolExternalData struct {
 	Symbol            string
 	Market            string
 	FetchedAt         time.Time
-	RawSections       []externalDataSection
+	RawSections       []ExternalDataSection
 	Summary           string
 	StructuredSummary string
 }
@@ -49,4 +50,4 @@ var externalSummarySectionSpecs = []externalSummarySectionSpec{
 
 // Function variables for testing/mocking.
 var fetchExternalDataFn = fetchExternalDataImpl
-var summarizeExternalDataFn = summarizeExternalDataImpl
+var summarizeExternalDataFn = (*Core).summarizeExternalData
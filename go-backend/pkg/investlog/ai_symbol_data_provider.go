@@ -0,0 +1,100 @@
+package investlog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ExternalDataRequest describes the symbol being analyzed.
+type ExternalDataRequest struct {
+	Symbol   string
+	Currency string
+	// Context is the symbol's position context as JSON, as shown to the
+	// analysis agents.
+	Context string
+}
+
+// ExternalData is real-time data or news about a symbol. Sections are
+// summarized by the analysis model unless Summary is set, in which case
+// Summary is passed to the agents as is.
+type ExternalData struct {
+	Sections []ExternalDataSection
+	Summary  string
+}
+
+// ExternalDataProvider supplies the external data that enriches symbol
+// analysis. Inject a custom implementation via Options.ExternalDataProvider.
+// Returning an error or nil data falls back to AI context retrieval.
+type ExternalDataProvider interface {
+	FetchExternalData(ctx context.Context, req ExternalDataRequest) (*ExternalData, error)
+}
+
+// ExternalDataProviderFunc adapts a function to ExternalDataProvider.
+type ExternalDataProviderFunc func(ctx context.Context, req ExternalDataRequest) (*ExternalData, error)
+
+// FetchExternalData calls f.
+func (f ExternalDataProviderFunc) FetchExternalData(ctx context.Context, req ExternalDataRequest) (*ExternalData, error) {
+	return f(ctx, req)
+}
+
+// NewExternalDataProvider returns the default provider, which scrapes
+// financials, news and research from public market data sites.
+func NewExternalDataProvider(logger *slog.Logger) ExternalDataProvider {
+	return ExternalDataProviderFunc(func(ctx context.Context, req ExternalDataRequest) (*ExternalData, error) {
+		data := fetchExternalDataFn(ctx, req.Symbol, req.Currency, logger)
+		if data == nil {
+			return nil, nil
+		}
+		return &ExternalData{Sections: data.RawSections}, nil
+	})
+}
+
+// fetchExternalData returns external data for an analysis, reusing data
+// fetched within externalDataCacheTTL. Provider errors are treated as missing
+// data.
+func (c *Core) fetchExternalData(ctx context.Context, req SymbolAnalysisRequest, symbolContext string) *symbolExternalData {
+	if cached, ok := c.externalCache.get(req.Symbol, req.Currency); ok {
+		return cached
+	}
+	data, err := c.loadExternalData(ctx, req.Symbol, req.Currency, symbolContext)
+	if err != nil {
+		c.analysisLogger(ctx).Warn("external data provider failed", "symbol", req.Symbol, "err", err)
+		return nil
+	}
+	return data
+}
+
+// loadExternalData asks the configured provider for external data and caches
+// a non-empty result. It returns nil data when the provider has none.
+func (c *Core) loadExternalData(ctx context.Context, symbol, currency, symbolContext string) (*symbolExternalData, error) {
+	provider := c.externalData
+	if provider == nil {
+		provider = NewExternalDataProvider(c.Logger())
+	}
+	data, err := provider.FetchExternalData(ctx, ExternalDataRequest{
+		Symbol:   symbol,
+		Currency: currency,
+		Context:  symbolContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+	summary := strings.TrimSpace(data.Summary)
+	if len(data.Sections) == 0 && summary == "" {
+		return nil, nil
+	}
+	result := &symbolExternalData{
+		Symbol:      symbol,
+		Market:      detectMarket(symbol, currency),
+		FetchedAt:   time.Now(),
+		RawSections: data.Sections,
+		Summary:     summary,
+	}
+	c.externalCache.put(symbol, currency, result)
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureDimensionPrompts stubs completions and records framework-agent user prompts.
+func captureDimensionPrompts(t *testing.T) func() []string {
+	t.Helper()
+	var mu sync.Mutex
+	var prompts []string
+	original := aiChatCompletion
+	t.Cleanup(func() { aiChatCompletion = original })
+	aiChatCompletion = func(ctx context.Context, req aiChatCompletionRequest) (aiChatCompletionResult, error) {
+		if strings.Contains(req.UserPrompt, "请分析以下投资标的") {
+			mu.Lock()
+			prompts = append(prompts, req.UserPrompt)
+			mu.Unlock()
+		}
+		return dimensionStubRouter(ctx, req)
+	}
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), prompts...)
+	}
+}
+
+func TestAnalyzeSymbol_UsesCustomExternalDataProvider(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	var got ExternalDataRequest
+	core.externalData = ExternalDataProviderFunc(func(_ context.Context, req ExternalDataRequest) (*ExternalData, error) {
+		got = req
+		return &ExternalData{Summary: "自定义资讯：新品发布会定于下周举行"}, nil
+	})
+	prompts := captureDimensionPrompts(t)
+
+	_, err := core.AnalyzeSymbol(SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "aapl",
+		Currency: "usd",
+	})
+	assertNoError(t, err, "AnalyzeSymbol")
+
+	if got.Symbol != "AAPL" || got.Currency != "USD" || !strings.Contains(got.Context, "AAPL") {
+		t.Fatalf("unexpected provider request: %+v", got)
+	}
+	seen := prompts()
+	if len(seen) == 0 {
+		t.Fatal("expected dimension prompts")
+	}
+	for _, prompt := range seen {
+		if !strings.Contains(prompt, "自定义资讯：新品发布会定于下周举行") {
+			t.Fatalf("expected provider summary in dimension prompt, got: %s", prompt)
+		}
+	}
+}
+
+func TestAnalyzeSymbol_ExternalDataProviderErrorDegrades(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	core.externalData = ExternalDataProviderFunc(func(context.Context, ExternalDataRequest) (*ExternalData, error) {
+		return nil, errors.New("provider down")
+	})
+	prompts := captureDimensionPrompts(t)
+
+	result, err := core.AnalyzeSymbol(SymbolAnalysisRequest{
+		BaseURL:  "https://example.com/v1",
+		APIKey:   "test-key",
+		Model:    "mock-model",
+		Symbol:   "AAPL",
+		Currency: "USD",
+	})
+	assertNoError(t, err, "AnalyzeSymbol with failing provider")
+	if result.Status != "completed" || len(prompts()) == 0 {
+		t.Fatalf("expected analysis to complete without external data, got %+v", result)
+	}
+}
@@ -35,15 +35,22 @@ func (c *Core) GetAllocationSettings(currency string) ([]AllocationSetting, erro
 // SetAllocationSetting updates or inserts a setting.
 func (c *Core) SetAllocationSetting(currency, assetType string, minPercent, maxPercent float64) (bool, error) {
 	currency = normalizeCurrency(currency)
+	invalid := &ValidationError{}
 	if !isValidCurrency(currency) {
-		return false, fmt.Errorf("invalid currency: %s", currency)
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", currency))
 	}
-	if minPercent < 0 || maxPercent > 100 || minPercent > maxPercent {
-		return false, fmt.Errorf("invalid percent range")
+	if minPercent < 0 || minPercent > maxPercent {
+		invalid.Add("min_percent", "invalid percent range")
+	}
+	if maxPercent > 100 {
+		invalid.Add("max_percent", "invalid percent range")
 	}
 	assetType = strings.ToLower(strings.TrimSpace(assetType))
 	if assetType == "" {
-		return false, fmt.Errorf("asset_type required")
+		invalid.Add("asset_type", "asset_type required")
+	}
+	if err := invalid.Err(); err != nil {
+		return false, err
 	}
 
 	tx, err := c.db.Begin()
@@ -58,7 +65,7 @@ func (c *Core) SetAllocationSetting(currency, assetType string, minPercent, maxP
 		return false, err
 	}
 	if !valid {
-		return false, fmt.Errorf("invalid asset_type: %s", assetType)
+		return false, NewValidationError("asset_type", fmt.Sprintf("invalid asset_type: %s", assetType))
 	}
 
 	_, err = tx.Exec(`
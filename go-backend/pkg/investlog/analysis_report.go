@@ -0,0 +1,260 @@
+package investlog
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report formats accepted by ExportAnalysisReport.
+const (
+	ReportFormatMarkdown = "md"
+	ReportFormatHTML     = "html"
+)
+
+const reportNotAnalyzed = "Not yet analyzed."
+
+type analysisReportSymbol struct {
+	Symbol   string
+	Name     string
+	Currency string
+	Analysis *SymbolAnalysisResult
+}
+
+type analysisReport struct {
+	Currency    string
+	GeneratedAt string
+	Holdings    *HoldingsAnalysisResult
+	Symbols     []analysisReportSymbol
+}
+
+// ExportAnalysisReport renders the latest holdings analysis together with the
+// latest synthesis of every held symbol as one shareable document. An empty
+// currency covers all currencies; format is "md" (default) or "html".
+func (c *Core) ExportAnalysisReport(currency, format string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" && !contains(Currencies, currency) {
+		return "", NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid currency: %s", currency))
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = ReportFormatMarkdown
+	}
+	if format != ReportFormatMarkdown && format != ReportFormatHTML {
+		return "", NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid format: %s", format))
+	}
+
+	report, err := c.buildAnalysisReport(currency)
+	if err != nil {
+		return "", err
+	}
+	if format == ReportFormatHTML {
+		return renderAnalysisReportHTML(report)
+	}
+	return renderAnalysisReportMarkdown(report), nil
+}
+
+func (c *Core) buildAnalysisReport(currency string) (*analysisReport, error) {
+	holdingsAnalysis, err := c.GetHoldingsAnalysis(currency)
+	if err != nil {
+		return nil, err
+	}
+	if holdingsAnalysis != nil {
+		holdingsAnalysis.GeneratedAt = c.reportTimestamp(holdingsAnalysis.GeneratedAt)
+	}
+	holdings, err := c.GetHoldings("")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var symbols []analysisReportSymbol
+	for _, h := range holdings {
+		if !h.TotalShares.IsPositive() || (currency != "" && h.Currency != currency) {
+			continue
+		}
+		key := h.Symbol + "|" + h.Currency
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		item := analysisReportSymbol{Symbol: h.Symbol, Currency: h.Currency}
+		if h.Name != nil {
+			item.Name = *h.Name
+		}
+		symbols = append(symbols, item)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Currency != symbols[j].Currency {
+			return symbols[i].Currency < symbols[j].Currency
+		}
+		return symbols[i].Symbol < symbols[j].Symbol
+	})
+	for i := range symbols {
+		analysis, err := c.GetSymbolAnalysis(symbols[i].Symbol, symbols[i].Currency)
+		if err != nil {
+			return nil, err
+		}
+		if analysis != nil {
+			analysis.CreatedAt = c.reportTimestamp(analysis.CreatedAt)
+		}
+		symbols[i].Analysis = analysis
+	}
+
+	return &analysisReport{
+		Currency:    currency,
+		GeneratedAt: c.NowRFC3339(),
+		Holdings:    holdingsAnalysis,
+		Symbols:     symbols,
+	}, nil
+}
+
+// reportTimestamp formats a stored timestamp in the configured time zone so
+// every time in a report shares one offset. Zone-less values are SQLite
+// CURRENT_TIMESTAMP output and therefore UTC; unparseable values are kept.
+func (c *Core) reportTimestamp(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.In(c.Location()).Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+func (r *analysisReport) scopeLabel() string {
+	if r.Currency == "" {
+		return "All currencies"
+	}
+	return r.Currency
+}
+
+func renderAnalysisReportMarkdown(r *analysisReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Investment Analysis Report\n\n")
+	fmt.Fprintf(&b, "- Scope: %s\n- Generated at: %s\n\n", r.scopeLabel(), r.GeneratedAt)
+
+	b.WriteString("## Holdings Analysis\n\n")
+	if h := r.Holdings; h == nil {
+		b.WriteString(reportNotAnalyzed + "\n\n")
+	} else {
+		fmt.Fprintf(&b, "- Generated at: %s\n- Model: %s\n- Risk level: %s\n\n", h.GeneratedAt, h.Model, h.RiskLevel)
+		fmt.Fprintf(&b, "### Summary\n\n%s\n\n", h.OverallSummary)
+		if len(h.KeyFindings) > 0 {
+			b.WriteString("### Key Findings\n\n")
+			for _, finding := range h.KeyFindings {
+				fmt.Fprintf(&b, "- %s\n", finding)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("### Recommendations\n\n")
+		if len(h.Recommendations) == 0 {
+			b.WriteString("No recommendations.\n\n")
+		}
+		for _, rec := range h.Recommendations {
+			target := rec.Symbol
+			if target == "" {
+				target = "Portfolio"
+			}
+			fmt.Fprintf(&b, "- **%s** — %s", target, rec.Action)
+			if rec.TargetWeight != "" {
+				fmt.Fprintf(&b, " (target %s)", rec.TargetWeight)
+			}
+			if rec.Priority != "" {
+				fmt.Fprintf(&b, " [%s]", rec.Priority)
+			}
+			fmt.Fprintf(&b, ": %s\n", rec.Rationale)
+		}
+		if len(h.Recommendations) > 0 {
+			b.WriteString("\n")
+		}
+		if h.Disclaimer != "" {
+			fmt.Fprintf(&b, "> %s\n\n", h.Disclaimer)
+		}
+	}
+
+	b.WriteString("## Symbol Analyses\n\n")
+	if len(r.Symbols) == 0 {
+		b.WriteString("No holdings.\n")
+	}
+	for _, s := range r.Symbols {
+		fmt.Fprintf(&b, "### %s (%s)", s.Symbol, s.Currency)
+		if s.Name != "" {
+			fmt.Fprintf(&b, " %s", s.Name)
+		}
+		b.WriteString("\n\n")
+		if s.Analysis == nil || s.Analysis.Synthesis == nil {
+			b.WriteString(reportNotAnalyzed + "\n\n")
+			continue
+		}
+		syn := s.Analysis.Synthesis
+		fmt.Fprintf(&b, "- Analyzed at: %s\n- Rating: %s (confidence %s)\n- Action: %s\n", s.Analysis.CreatedAt, syn.OverallRating, syn.Confidence, syn.TargetAction)
+		if syn.PositionSuggestion != "" {
+			fmt.Fprintf(&b, "- Position: %s\n", syn.PositionSuggestion)
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", syn.OverallSummary)
+		writeMarkdownList(&b, "Key factors", syn.KeyFactors)
+		writeMarkdownList(&b, "Risk warnings", syn.RiskWarnings)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeMarkdownList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+	b.WriteString("\n")
+}
+
+var analysisReportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Investment Analysis Report</title></head>
+<body>
+<h1>Investment Analysis Report</h1>
+<p>Scope: {{.Scope}}<br>Generated at: {{.Report.GeneratedAt}}</p>
+<h2>Holdings Analysis</h2>
+{{with .Report.Holdings}}
+<p>Generated at: {{.GeneratedAt}} · Model: {{.Model}} · Risk level: {{.RiskLevel}}</p>
+<h3>Summary</h3>
+<p>{{.OverallSummary}}</p>
+{{if .KeyFindings}}<h3>Key Findings</h3>
+<ul>{{range .KeyFindings}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<h3>Recommendations</h3>
+{{if .Recommendations}}<ul>{{range .Recommendations}}<li><strong>{{if .Symbol}}{{.Symbol}}{{else}}Portfolio{{end}}</strong> — {{.Action}}{{if .TargetWeight}} (target {{.TargetWeight}}){{end}}{{if .Priority}} [{{.Priority}}]{{end}}: {{.Rationale}}</li>{{end}}</ul>
+{{else}}<p>No recommendations.</p>{{end}}
+{{if .Disclaimer}}<blockquote>{{.Disclaimer}}</blockquote>{{end}}
+{{else}}<p>{{$.NotAnalyzed}}</p>{{end}}
+<h2>Symbol Analyses</h2>
+{{range .Report.Symbols}}
+<h3>{{.Symbol}} ({{.Currency}}){{if .Name}} {{.Name}}{{end}}</h3>
+{{if and .Analysis .Analysis.Synthesis}}{{with .Analysis}}
+<p>Analyzed at: {{.CreatedAt}}<br>Rating: {{.Synthesis.OverallRating}} (confidence {{.Synthesis.Confidence}})<br>Action: {{.Synthesis.TargetAction}}{{if .Synthesis.PositionSuggestion}}<br>Position: {{.Synthesis.PositionSuggestion}}{{end}}</p>
+<p>{{.Synthesis.OverallSummary}}</p>
+{{if .Synthesis.KeyFactors}}<p>Key factors:</p><ul>{{range .Synthesis.KeyFactors}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Synthesis.RiskWarnings}}<p>Risk warnings:</p><ul>{{range .Synthesis.RiskWarnings}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{end}}{{else}}<p>{{$.NotAnalyzed}}</p>{{end}}
+{{else}}<p>No holdings.</p>
+{{end}}
+</body>
+</html>
+`))
+
+func renderAnalysisReportHTML(r *analysisReport) (string, error) {
+	var buf bytes.Buffer
+	err := analysisReportHTMLTemplate.Execute(&buf, struct {
+		Report      *analysisReport
+		Scope       string
+		NotAnalyzed string
+	}{Report: r, Scope: r.scopeLabel(), NotAnalyzed: reportNotAnalyzed})
+	if err != nil {
+		return "", fmt.Errorf("render html report: %w", err)
+	}
+	return buf.String(), nil
+}
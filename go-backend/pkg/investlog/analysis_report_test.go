@@ -0,0 +1,100 @@
+package investlog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportAnalysisReport_CombinesHoldingsAndSymbolAnalyses(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	testBuyTransaction(t, core, "MSFT", 5, 300, "USD", "acc-1")
+
+	_, err := core.saveHoldingsAnalysis(&HoldingsAnalysisResult{
+		Currency:       "USD",
+		Model:          "mock-model",
+		AnalysisType:   "adhoc",
+		RiskLevel:      "balanced",
+		OverallSummary: "组合集中于科技股",
+		KeyFindings:    []string{"科技权重偏高"},
+		Recommendations: []HoldingsAnalysisRecommendation{
+			{Symbol: "AAPL", Action: "reduce", TheoryTag: "Rebalance", Rationale: "降低单一标的集中度", TargetWeight: "30%"},
+		},
+		Disclaimer: "仅供参考",
+	})
+	assertNoError(t, err, "save holdings analysis")
+
+	id, err := core.insertPendingSymbolAnalysis(SymbolAnalysisRequest{Symbol: "AAPL", Currency: "USD", Model: "mock-model"})
+	assertNoError(t, err, "insert symbol analysis")
+	synthesis, _ := json.Marshal(SymbolSynthesisResult{
+		OverallRating:  "hold",
+		Confidence:     "medium",
+		TargetAction:   "hold",
+		OverallSummary: "AAPL 估值合理，维持持有",
+		KeyFactors:     []string{"现金流稳健"},
+	})
+	assertNoError(t, core.saveSymbolAnalysisSynthesis(id, string(synthesis)), "save synthesis")
+
+	stored, err := core.GetSymbolAnalysis("AAPL", "USD")
+	assertNoError(t, err, "GetSymbolAnalysis")
+	summary := stored.Synthesis.OverallSummary
+
+	report, err := core.ExportAnalysisReport("usd", "")
+	assertNoError(t, err, "ExportAnalysisReport")
+	for _, want := range []string{
+		"## Holdings Analysis",
+		"### Recommendations",
+		"**AAPL** — reduce (target 30%): 降低单一标的集中度",
+		"### AAPL (USD)",
+		summary,
+		"- 现金流稳健",
+		"### MSFT (USD)",
+		"Not yet analyzed.",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "Z\n") || strings.Count(report, "+08:00") != 3 {
+		t.Fatalf("expected every timestamp in the configured zone:\n%s", report)
+	}
+
+	html, err := core.ExportAnalysisReport("USD", ReportFormatHTML)
+	assertNoError(t, err, "ExportAnalysisReport html")
+	if !strings.Contains(html, "<h3>Recommendations</h3>") || !strings.Contains(html, "<li>现金流稳健</li>") {
+		t.Fatalf("unexpected html report:\n%s", html)
+	}
+}
+
+func TestExportAnalysisReport_RejectsUnknownFormat(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := core.ExportAnalysisReport("USD", "pdf")
+	if !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid input error, got %v", err)
+	}
+}
+
+func TestRenderAnalysisReport_WithoutAnalyses(t *testing.T) {
+	report := &analysisReport{
+		Currency:    "CNY",
+		GeneratedAt: "2026-01-02T03:04:05+08:00",
+		Symbols:     []analysisReportSymbol{{Symbol: "600519", Currency: "CNY", Name: "<贵州茅台>"}},
+	}
+
+	md := renderAnalysisReportMarkdown(report)
+	if strings.Count(md, reportNotAnalyzed) != 2 {
+		t.Fatalf("expected holdings and symbol sections marked not analyzed:\n%s", md)
+	}
+
+	html, err := renderAnalysisReportHTML(report)
+	assertNoError(t, err, "render html")
+	if strings.Contains(html, "<贵州茅台>") || !strings.Contains(html, "&lt;贵州茅台&gt;") {
+		t.Fatalf("expected escaped symbol name in html:\n%s", html)
+	}
+}
@@ -5,25 +5,101 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+const (
+	defaultAIRateLimit = 20
+	defaultAIRateBurst = 10
+)
+
 // Options controls Core initialization.
 type Options struct {
-	DBPath             string
-	Logger             *slog.Logger
-	MaxOpenConns       int           // Maximum number of open connections to the database
-	MaxIdleConns       int           // Maximum number of idle connections in the pool
-	ConnMaxLifetime    time.Duration // Maximum lifetime of a connection
-	PriceCacheTTL      time.Duration
-	PriceFailThreshold int
-	PriceFailWindow    time.Duration
-	PriceCooldown      time.Duration
-	HTTPTimeout        time.Duration
+	DBPath          string
+	Logger          *slog.Logger
+	MaxOpenConns    int           // Maximum number of open connections to the database
+	MaxIdleConns    int           // Maximum number of idle connections in the pool
+	ConnMaxLifetime time.Duration // Maximum lifetime of a connection
+	PriceCacheTTL   time.Duration
+	// PriceCacheTTLByType overrides PriceCacheTTL per detected symbol type
+	// (e.g. "etf" for funds whose NAV updates daily, "us_stock" for intraday quotes).
+	PriceCacheTTLByType map[string]time.Duration
+	PriceFailThreshold  int
+	PriceFailWindow     time.Duration
+	PriceCooldown       time.Duration
+	HTTPTimeout         time.Duration
+	// PriceTransientRetries is how many times a price source is retried after
+	// a transient network error (connection reset, temporary DNS failure)
+	// before the failure counts toward its circuit breaker. Default: 1. A
+	// negative value disables retries.
+	PriceTransientRetries int
+	// PriceRetryDelay is the pause before such a retry. Default: 300ms.
+	PriceRetryDelay time.Duration
+	// GoldPriceUnit is the unit gold prices are stored in: "gram" (default)
+	// or "ounce" (troy ounce).
+	GoldPriceUnit string
+	// GoldPriceCurrency is the currency gold prices are converted to.
+	// Default: CNY.
+	GoldPriceCurrency string
+	// PriceUserAgent is the User-Agent header sent to price sources.
+	// Default: a desktop browser string.
+	PriceUserAgent string
+	// MissingPriceFetchLimit bounds how many holdings without any latest price are
+	// fetched in the background when holdings are valued by symbol. Such holdings
+	// are valued at cost and flagged price_missing until their quote is stored.
+	// Zero disables fetching.
+	MissingPriceFetchLimit int
+	// FXRateFetcher overrides the exchange-rate source used by RefreshExchangeRates.
+	FXRateFetcher FXRateFetcher
+	// SymbolAnalysisCacheTTL is how long a completed symbol analysis is reused
+	// when its inputs are unchanged. Default: 6h.
+	SymbolAnalysisCacheTTL time.Duration
+	// FailedAnalysisRetention is how long failed symbol analyses are kept
+	// before CleanupStaleAnalyses deletes them. Zero keeps them forever.
+	FailedAnalysisRetention time.Duration
+	// SlowQueryThreshold logs key database statements, such as the holdings
+	// aggregation, that take at least this long. Zero disables the timing.
+	SlowQueryThreshold time.Duration
+	// AIRateLimit caps AI analysis requests per client IP per minute.
+	// Default: 20. A negative value disables limiting.
+	AIRateLimit int
+	// AIRateBurst is how many AI requests a client may send back to back. Default: 10.
+	AIRateBurst int
+	// AIMaxResponseBytes caps non-streaming AI response bodies. Default: 2MB.
+	AIMaxResponseBytes int64
+	// AIMaxIdleConnsPerHost is how many keep-alive connections to one AI
+	// provider are kept for reuse. Default: 8.
+	AIMaxIdleConnsPerHost int
+	// AIAPIKeyFile is a file holding the server-side AI API key used when a
+	// request omits api_key. When empty, the AI_API_KEY environment variable
+	// is used instead. Keys supplied in requests always take precedence.
+	AIAPIKeyFile string
+	// AIMaxConcurrent caps upstream AI calls in flight across all analyses.
+	// Default: 4. A negative value disables the limit.
+	AIMaxConcurrent int
+	// AIQueueTimeout is how long an AI call waits for a free slot before
+	// failing with ErrCodeBusy. Default: 30s.
+	AIQueueTimeout time.Duration
+	// ExternalDataProvider overrides the real-time data and news source used
+	// to enrich symbol analysis.
+	ExternalDataProvider ExternalDataProvider
+	// QuantityPrecision is how many decimal places of share quantities,
+	// per-share prices, cost basis and market value symbol analysis keeps.
+	// Raise it for brokers with fine fractional shares. Default: 2.
+	QuantityPrecision int
+	// TimeZone is the IANA zone name used for generated dates and
+	// timestamps, such as default transaction dates. Default: Asia/Shanghai.
+	TimeZone string
+	// ImportMaxRows caps the data rows of one CSV transaction import; larger
+	// files are rejected before anything is written. Default: 10000.
+	ImportMaxRows int
 }
 
 // Core provides access to Invest Log business logic and storage.
@@ -33,6 +109,29 @@ type Core struct {
 	price  *priceFetcher
 	dbPath string
 	cache  *holdingsCache
+	fx     FXRateFetcher
+
+	missingPriceFetchLimit int
+	missingPriceRefreshing atomic.Bool
+	missingPriceRefresh    sync.WaitGroup
+	// closeMu orders Close against starting a missing-price refresh, so
+	// Close waits for every refresh that started before it.
+	closeMu                sync.Mutex
+	closed                 atomic.Bool
+	symbolAnalysisCacheTTL time.Duration
+	analysisRetention      time.Duration
+	slowQueryThreshold     time.Duration
+	aiRateLimit            int
+	aiRateBurst            int
+	aiMaxResponseBytes     int64
+	aiHTTPClient           *http.Client
+	aiAPIKey               string
+	aiLimiter              *aiCallLimiter
+	externalData           ExternalDataProvider
+	externalCache          *externalDataCache
+	quantityPrecision      int
+	importMaxRows          int
+	location               *time.Location
 }
 
 // Open initializes a Core using the provided database path.
@@ -46,6 +145,14 @@ func OpenWithOptions(opts Options) (*Core, error) {
 	if opts.DBPath == "" {
 		return nil, errors.New("db path is required")
 	}
+	location, err := loadTimeZone(opts.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	aiAPIKey, err := loadServerAIAPIKey(opts.AIAPIKeyFile)
+	if err != nil {
+		return nil, err
+	}
 	cleanPath := filepath.Clean(opts.DBPath)
 	if err := os.MkdirAll(filepath.Dir(cleanPath), 0o755); err != nil {
 		return nil, fmt.Errorf("create db dir: %w", err)
@@ -81,12 +188,18 @@ func OpenWithOptions(opts Options) (*Core, error) {
 	}
 
 	pf := newPriceFetcher(priceFetcherOptions{
-		Logger:        logger,
-		CacheTTL:      defaultDuration(opts.PriceCacheTTL, 30*time.Second),
-		FailThreshold: defaultInt(opts.PriceFailThreshold, 3),
-		FailWindow:    defaultDuration(opts.PriceFailWindow, 60*time.Second),
-		Cooldown:      defaultDuration(opts.PriceCooldown, 120*time.Second),
-		HTTPTimeout:   defaultDuration(opts.HTTPTimeout, 10*time.Second),
+		Logger:         logger,
+		CacheTTL:       defaultDuration(opts.PriceCacheTTL, 30*time.Second),
+		CacheTTLByType: opts.PriceCacheTTLByType,
+		FailThreshold:  defaultInt(opts.PriceFailThreshold, 3),
+		FailWindow:     defaultDuration(opts.PriceFailWindow, 60*time.Second),
+		Cooldown:       defaultDuration(opts.PriceCooldown, 120*time.Second),
+		HTTPTimeout:    defaultDuration(opts.HTTPTimeout, 10*time.Second),
+		RetryAttempts:  priceTransientRetries(opts.PriceTransientRetries),
+		RetryDelay:     defaultDuration(opts.PriceRetryDelay, 300*time.Millisecond),
+		GoldUnit:       opts.GoldPriceUnit,
+		GoldCurrency:   opts.GoldPriceCurrency,
+		UserAgent:      opts.PriceUserAgent,
 	})
 
 	c := &Core{
@@ -95,6 +208,29 @@ func OpenWithOptions(opts Options) (*Core, error) {
 		price:  pf,
 		dbPath: cleanPath,
 		cache:  newHoldingsCache(),
+		fx:     opts.FXRateFetcher,
+
+		missingPriceFetchLimit: opts.MissingPriceFetchLimit,
+		symbolAnalysisCacheTTL: defaultDuration(opts.SymbolAnalysisCacheTTL, defaultSymbolAnalysisCacheTTL),
+		analysisRetention:      opts.FailedAnalysisRetention,
+		slowQueryThreshold:     opts.SlowQueryThreshold,
+		aiRateLimit:            opts.AIRateLimit,
+		aiRateBurst:            defaultInt(opts.AIRateBurst, defaultAIRateBurst),
+		aiMaxResponseBytes:     opts.AIMaxResponseBytes,
+		aiHTTPClient:           newAIHTTPClient(opts.AIMaxIdleConnsPerHost),
+		aiAPIKey:               aiAPIKey,
+		aiLimiter:              newAICallLimiter(opts.AIMaxConcurrent, opts.AIQueueTimeout),
+		externalData:           opts.ExternalDataProvider,
+		externalCache:          newExternalDataCache(externalDataCacheTTL),
+		quantityPrecision:      defaultInt(opts.QuantityPrecision, defaultQuantityPrecision),
+		importMaxRows:          defaultInt(opts.ImportMaxRows, defaultImportMaxRows),
+		location:               location,
+	}
+	if c.aiRateLimit == 0 {
+		c.aiRateLimit = defaultAIRateLimit
+	}
+	if c.fx == nil {
+		c.fx = NewFXRateFetcher(nil)
 	}
 
 	// Inject rate resolver so priceFetcher can look up FX rates (e.g. HKD→CNY)
@@ -102,15 +238,24 @@ func OpenWithOptions(opts Options) (*Core, error) {
 	pf.rateResolver = func(fromCurrency string) (float64, error) {
 		return c.GetRateToCNY(fromCurrency)
 	}
+	pf.typeOverride = c.symbolTypeOverride
+	if err := c.loadPriceCircuitSettings(); err != nil {
+		logger.Warn("load price circuit settings failed", "err", err)
+	}
 
 	return c, nil
 }
 
-// Close releases database resources.
+// Close releases database resources. It stops the background missing-price
+// refresh and waits for it before closing the database.
 func (c *Core) Close() error {
 	if c == nil || c.db == nil {
 		return nil
 	}
+	c.closeMu.Lock()
+	c.closed.Store(true)
+	c.closeMu.Unlock()
+	c.missingPriceRefresh.Wait()
 	return c.db.Close()
 }
 
@@ -127,6 +272,15 @@ func (c *Core) Logger() *slog.Logger {
 	return c.logger
 }
 
+// AIRateLimit returns the per-client AI request allowance per minute and its
+// burst size. A non-positive perMinute means limiting is disabled.
+func (c *Core) AIRateLimit() (perMinute, burst int) {
+	if c == nil {
+		return defaultAIRateLimit, defaultAIRateBurst
+	}
+	return c.aiRateLimit, c.aiRateBurst
+}
+
 func (c *Core) invalidateHoldingsCache() {
 	if c == nil || c.cache == nil {
 		return
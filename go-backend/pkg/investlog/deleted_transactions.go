@@ -0,0 +1,177 @@
+package investlog
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// deletedTransactionRetention is how many deletions (a transaction together
+// with its linked transfer side) are kept for restore.
+const deletedTransactionRetention = 200
+
+// transactionColumns lists the columns shared by transactions and
+// deleted_transactions.
+const transactionColumns = `id, transaction_date, transaction_time, symbol_id, transaction_type,
+	quantity, price, total_amount, commission, currency,
+	account_id, account_name, notes, tags,
+	linked_transaction_id, created_at, updated_at`
+
+// DeletedTransaction is a deleted transaction that can still be restored.
+// DeleteGroup is the ID passed to DeleteTransaction; linked transactions
+// deleted with it share the group and are restored together.
+type DeletedTransaction struct {
+	Transaction
+	DeleteGroup int64  `json:"delete_group"`
+	DeletedAt   string `json:"deleted_at"`
+}
+
+// archiveDeletedTransactions copies ids into deleted_transactions under group
+// and prunes deletions beyond the retention limit.
+func archiveDeletedTransactions(tx *sql.Tx, group int64, ids []int64) error {
+	args := append([]any{group}, int64SliceToAny(ids)...)
+	if _, err := tx.Exec(
+		`INSERT INTO deleted_transactions (`+transactionColumns+`, delete_group)
+		 SELECT `+transactionColumns+`, ? FROM transactions WHERE id IN (`+placeholders(len(ids))+`)`,
+		args...,
+	); err != nil {
+		return fmt.Errorf("archive deleted transactions: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM deleted_transactions
+		WHERE delete_group NOT IN (
+			SELECT delete_group FROM deleted_transactions
+			GROUP BY delete_group
+			ORDER BY MAX(deleted_at) DESC, delete_group DESC
+			LIMIT ?
+		)
+	`, deletedTransactionRetention); err != nil {
+		return fmt.Errorf("prune deleted transactions: %w", err)
+	}
+	return nil
+}
+
+// GetDeletedTransactions returns restorable deleted transactions, most
+// recently deleted first.
+func (c *Core) GetDeletedTransactions(limit int) ([]DeletedTransaction, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := c.db.Query(`
+		SELECT
+			t.id, t.transaction_date, t.transaction_time, t.symbol_id, t.transaction_type,
+			t.quantity, t.price, t.total_amount, t.commission, t.currency,
+			t.account_id, t.account_name, t.notes, t.tags,
+			t.linked_transaction_id, t.created_at, t.updated_at,
+			s.symbol, s.name, s.asset_type,
+			t.delete_group, t.deleted_at
+		FROM deleted_transactions t
+		JOIN symbols s ON s.id = t.symbol_id
+		ORDER BY t.deleted_at DESC, t.delete_group DESC, t.id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []DeletedTransaction{}
+	for rows.Next() {
+		var d DeletedTransaction
+		t, err := scanTransaction(rows, &d.DeleteGroup, &d.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		d.Transaction = t
+		results = append(results, d)
+	}
+	return results, rows.Err()
+}
+
+// RestoreTransaction reinserts a deleted transaction with its original ID,
+// together with every transaction deleted alongside it (such as the other
+// side of a transfer). It returns false when id is not a restorable deletion.
+// A SELL or TRANSFER_OUT that would exceed the current holdings is rejected,
+// as AddTransaction does.
+func (c *Core) RestoreTransaction(id int64) (bool, error) {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var group int64
+	err = tx.QueryRow("SELECT delete_group FROM deleted_transactions WHERE id = ?", id).Scan(&group)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := checkRestoredSells(tx, group); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO transactions (`+transactionColumns+`)
+		 SELECT `+transactionColumns+` FROM deleted_transactions WHERE delete_group = ?`,
+		group,
+	); err != nil {
+		return false, fmt.Errorf("restore transaction %d: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM deleted_transactions WHERE delete_group = ?", group); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	c.invalidateHoldingsCache()
+	return true, nil
+}
+
+// checkRestoredSells applies AddTransaction's holdings check to the SELL and
+// TRANSFER_OUT rows of group. Linked rows, such as the cash leg of a BUY, were
+// not checked when recorded and are skipped.
+func checkRestoredSells(tx *sql.Tx, group int64) error {
+	rows, err := tx.Query(`
+		SELECT s.symbol, t.currency, t.account_id, t.transaction_type, t.quantity
+		FROM deleted_transactions t
+		JOIN symbols s ON s.id = t.symbol_id
+		WHERE t.delete_group = ?
+		  AND t.transaction_type IN ('SELL', 'TRANSFER_OUT')
+		  AND t.linked_transaction_id IS NULL
+	`, group)
+	if err != nil {
+		return err
+	}
+	type restoredSell struct {
+		symbol, currency, accountID, txType string
+		quantity                            Amount
+	}
+	var sells []restoredSell
+	for rows.Next() {
+		var s restoredSell
+		if err := rows.Scan(&s.symbol, &s.currency, &s.accountID, &s.txType, &s.quantity); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		sells = append(sells, s)
+	}
+	// Close before querying holdings: the transaction has a single connection.
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range sells {
+		shares, err := currentShares(tx, s.symbol, s.currency, s.accountID)
+		if err != nil {
+			return fmt.Errorf("failed to check current holdings: %w", err)
+		}
+		if s.quantity.GreaterThan(shares.Decimal) {
+			return NewError(ErrCodeInsufficientFund, fmt.Sprintf("insufficient shares: restoring %s %s %s but only have %s",
+				s.txType, s.quantity.Round(4).String(), s.symbol, shares.Round(4).String()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package investlog
+
+import "testing"
+
+func TestDeleteAndRestoreTransaction(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	id := testBuyTransaction(t, core, "AAPL", 5, 120, "USD", "acc-1")
+
+	before, err := core.GetHoldings("")
+	assertNoError(t, err, "GetHoldings before")
+
+	deleted, err := core.DeleteTransaction(id)
+	assertNoError(t, err, "DeleteTransaction")
+	if !deleted {
+		t.Fatal("expected transaction to be deleted")
+	}
+
+	tombstones, err := core.GetDeletedTransactions(0)
+	assertNoError(t, err, "GetDeletedTransactions")
+	if len(tombstones) != 1 || tombstones[0].ID != id || tombstones[0].Symbol != "AAPL" || tombstones[0].DeleteGroup != id {
+		t.Fatalf("unexpected tombstones: %+v", tombstones)
+	}
+
+	restored, err := core.RestoreTransaction(id)
+	assertNoError(t, err, "RestoreTransaction")
+	if !restored {
+		t.Fatal("expected transaction to be restored")
+	}
+
+	txn, err := core.GetTransaction(id)
+	assertNoError(t, err, "GetTransaction")
+	if txn == nil || !txn.Quantity.Equal(NewAmountFromInt(5).Decimal) {
+		t.Fatalf("expected original transaction back, got %+v", txn)
+	}
+	after, err := core.GetHoldings("")
+	assertNoError(t, err, "GetHoldings after")
+	if len(after) != len(before) || !after[0].TotalShares.Equal(before[0].TotalShares.Decimal) || !after[0].TotalCost.Equal(before[0].TotalCost.Decimal) {
+		t.Fatalf("expected holdings %+v after restore, got %+v", before, after)
+	}
+
+	tombstones, err = core.GetDeletedTransactions(0)
+	assertNoError(t, err, "GetDeletedTransactions after restore")
+	if len(tombstones) != 0 {
+		t.Fatalf("expected no tombstones after restore, got %+v", tombstones)
+	}
+
+	restored, err = core.RestoreTransaction(id)
+	assertNoError(t, err, "RestoreTransaction twice")
+	if restored {
+		t.Fatal("expected second restore to find nothing")
+	}
+}
+
+func TestRestoreTransaction_LinkedTransferPair(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct-a", "Account A")
+	testAccount(t, core, "acct-b", "Account B")
+	testBuyTransaction(t, core, "AAPL", 100, 150, "USD", "acct-a")
+	result, err := core.Transfer(TransferRequest{
+		Symbol:        "AAPL",
+		Quantity:      NewAmountFromInt(40),
+		FromAccountID: "acct-a",
+		ToAccountID:   "acct-b",
+		FromCurrency:  "USD",
+	})
+	assertNoError(t, err, "Transfer")
+
+	deleted, err := core.DeleteTransaction(result.TransferOutID)
+	assertNoError(t, err, "DeleteTransaction")
+	if !deleted {
+		t.Fatal("expected transfer to be deleted")
+	}
+	if in, _ := core.GetTransaction(result.TransferInID); in != nil {
+		t.Fatal("expected linked transfer-in to be deleted too")
+	}
+
+	// Restoring either side brings back the pair.
+	restored, err := core.RestoreTransaction(result.TransferInID)
+	assertNoError(t, err, "RestoreTransaction")
+	if !restored {
+		t.Fatal("expected transfer to be restored")
+	}
+	for _, id := range []int64{result.TransferOutID, result.TransferInID} {
+		txn, err := core.GetTransaction(id)
+		assertNoError(t, err, "GetTransaction")
+		if txn == nil || txn.LinkedTransactionID == nil {
+			t.Fatalf("expected linked transaction %d restored, got %+v", id, txn)
+		}
+	}
+	holdings, err := core.GetHoldings("acct-b")
+	assertNoError(t, err, "GetHoldings acct-b")
+	if len(holdings) != 1 || !holdings[0].TotalShares.Equal(NewAmountFromInt(40).Decimal) {
+		t.Fatalf("expected 40 shares in acct-b after restore, got %+v", holdings)
+	}
+}
+
+func TestDeletedTransactionRetention(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	var ids []int64
+	for i := 0; i < deletedTransactionRetention+2; i++ {
+		ids = append(ids, testBuyTransaction(t, core, "AAPL", 1, 100, "USD", "acc-1"))
+	}
+	for _, id := range ids {
+		_, err := core.DeleteTransaction(id)
+		assertNoError(t, err, "DeleteTransaction")
+	}
+
+	tombstones, err := core.GetDeletedTransactions(deletedTransactionRetention + 10)
+	assertNoError(t, err, "GetDeletedTransactions")
+	if len(tombstones) != deletedTransactionRetention {
+		t.Fatalf("expected %d tombstones, got %d", deletedTransactionRetention, len(tombstones))
+	}
+	restored, err := core.RestoreTransaction(ids[0])
+	assertNoError(t, err, "RestoreTransaction oldest")
+	if restored {
+		t.Fatal("expected oldest deletion to be pruned")
+	}
+}
+
+func TestRestoreTransaction_RejectsOversell(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	buyID := testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	sellID, err := core.AddTransaction(AddTransactionRequest{
+		Symbol:          "AAPL",
+		TransactionType: "SELL",
+		Quantity:        NewAmountFromInt(8),
+		Price:           NewAmountFromInt(120),
+		Currency:        "USD",
+		AccountID:       "acc-1",
+	})
+	assertNoError(t, err, "sell")
+
+	_, err = core.DeleteTransaction(sellID)
+	assertNoError(t, err, "delete sell")
+	_, err = core.DeleteTransaction(buyID)
+	assertNoError(t, err, "delete buy")
+	testBuyTransaction(t, core, "AAPL", 5, 100, "USD", "acc-1")
+
+	restored, err := core.RestoreTransaction(sellID)
+	if !IsErrorCode(err, ErrCodeInsufficientFund) || restored {
+		t.Fatalf("expected the SELL restore to be rejected as an oversell, got %v (restored=%v)", err, restored)
+	}
+	shares, err := core.getCurrentShares("AAPL", "USD", "acc-1")
+	assertNoError(t, err, "current shares")
+	assertFloatEquals(t, shares, 5, "shares after rejected restore")
+
+	_, err = core.RestoreTransaction(buyID)
+	assertNoError(t, err, "restore buy")
+	restored, err = core.RestoreTransaction(sellID)
+	assertNoError(t, err, "restore sell once covered")
+	if !restored {
+		t.Fatal("expected the SELL to be restored once the holdings cover it")
+	}
+}
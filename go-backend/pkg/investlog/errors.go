@@ -1,6 +1,10 @@
 package investlog
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // ErrorCode defines error classification codes for structured error handling.
 type ErrorCode string
@@ -15,6 +19,8 @@ const (
 	ErrCodeValidation       ErrorCode = "VALIDATION_ERROR"
 	ErrCodeInternal         ErrorCode = "INTERNAL_ERROR"
 	ErrCodeUnsupported      ErrorCode = "UNSUPPORTED"
+	ErrCodeSetupRequired    ErrorCode = "SETUP_REQUIRED"
+	ErrCodeBusy             ErrorCode = "SERVER_BUSY"
 )
 
 // Error represents a structured error with classification code.
@@ -56,3 +62,48 @@ func IsErrorCode(err error, code ErrorCode) bool {
 	}
 	return false
 }
+
+// ValidationError reports invalid request fields as a field→message map so
+// clients can highlight each offending input.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+// NewValidationError creates a ValidationError for a single field.
+func NewValidationError(field, message string) *ValidationError {
+	v := &ValidationError{}
+	v.Add(field, message)
+	return v
+}
+
+// Add records message for field, keeping the first message per field.
+func (v *ValidationError) Add(field, message string) {
+	if v.Fields == nil {
+		v.Fields = make(map[string]string)
+	}
+	if _, ok := v.Fields[field]; !ok {
+		v.Fields[field] = message
+	}
+}
+
+// Err returns v when any field failed, or nil.
+func (v *ValidationError) Err() error {
+	if v == nil || len(v.Fields) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Error joins the field messages in field order.
+func (v *ValidationError) Error() string {
+	fields := make([]string, 0, len(v.Fields))
+	for field := range v.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, v.Fields[field])
+	}
+	return strings.Join(messages, "; ")
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -22,7 +23,28 @@ const (
 	maxExchangeRateBodySize    = 1 << 20
 )
 
-var exchangeRateFetcher = fetchExchangeRateFromProviders
+// FXRateFetcher fetches the latest rate for one currency pair.
+// Inject a custom implementation via Options.FXRateFetcher.
+type FXRateFetcher interface {
+	FetchRate(ctx context.Context, fromCurrency, toCurrency string) (rate float64, source string, err error)
+}
+
+// FXRateFetcherFunc adapts a function to FXRateFetcher.
+type FXRateFetcherFunc func(ctx context.Context, fromCurrency, toCurrency string) (float64, string, error)
+
+// FetchRate calls f.
+func (f FXRateFetcherFunc) FetchRate(ctx context.Context, fromCurrency, toCurrency string) (float64, string, error) {
+	return f(ctx, fromCurrency, toCurrency)
+}
+
+// NewFXRateFetcher returns the default fetcher, which tries frankfurter.app and
+// then open.er-api.com. A nil client uses a plain http.Client.
+func NewFXRateFetcher(client HTTPDoer) FXRateFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: exchangeRateRequestTimeout}
+	}
+	return &providerFXRateFetcher{client: client}
+}
 
 // GetExchangeRates returns all maintained exchange rates.
 func (c *Core) GetExchangeRates() ([]ExchangeRateSetting, error) {
@@ -111,17 +133,24 @@ func (c *Core) GetRateToCNY(currency string) (float64, error) {
 	return rate, nil
 }
 
-// RefreshExchangeRates fetches USD/CNY and HKD/CNY from online providers.
+// RefreshExchangeRates fetches USD/CNY and HKD/CNY from the configured FX source.
+// A failed pair is reported in the returned messages and does not stop the others.
 func (c *Core) RefreshExchangeRates() (int, []string, error) {
 	pairs := [][2]string{
 		{"USD", "CNY"},
 		{"HKD", "CNY"},
 	}
+	fetcher := c.fx
+	if fetcher == nil {
+		fetcher = NewFXRateFetcher(nil)
+	}
 
 	updated := 0
 	errors := []string{}
 	for _, pair := range pairs {
-		rate, _, err := exchangeRateFetcher(pair[0], pair[1])
+		ctx, cancel := context.WithTimeout(context.Background(), exchangeRateRequestTimeout)
+		rate, _, err := fetcher.FetchRate(ctx, pair[0], pair[1])
+		cancel()
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("%s/%s: %v", pair[0], pair[1], err))
 			continue
@@ -154,6 +183,26 @@ func (c *Core) GetExchangeRate(fromCurrency, toCurrency string) (float64, error)
 	return fromRate / toRate, nil
 }
 
+// Convert converts amount between two supported currencies using the maintained
+// exchange rates, routing through CNY since rates are stored against CNY.
+func (c *Core) Convert(amount float64, fromCurrency, toCurrency string) (float64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, NewValidationError("amount", "amount must be a finite number")
+	}
+	fromCurrency = normalizeCurrency(fromCurrency)
+	toCurrency = normalizeCurrency(toCurrency)
+	for _, currency := range []string{fromCurrency, toCurrency} {
+		if !contains(Currencies, currency) {
+			return 0, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid currency: %s", currency))
+		}
+	}
+	rate, err := c.GetExchangeRate(fromCurrency, toCurrency)
+	if err != nil {
+		return 0, WrapError(ErrCodeNotFound, fmt.Sprintf("no exchange rate for %s/%s", fromCurrency, toCurrency), err)
+	}
+	return amount * rate, nil
+}
+
 func validateExchangeRatePair(fromCurrency, toCurrency string) error {
 	fromCurrency = normalizeCurrency(fromCurrency)
 	toCurrency = normalizeCurrency(toCurrency)
@@ -176,14 +225,14 @@ func normalizeExchangeRateSource(source string) string {
 	return strings.ToLower(trimmed)
 }
 
-func fetchExchangeRateFromProviders(fromCurrency, toCurrency string) (float64, string, error) {
-	client := &http.Client{Timeout: exchangeRateRequestTimeout}
-	ctx, cancel := context.WithTimeout(context.Background(), exchangeRateRequestTimeout)
-	defer cancel()
+type providerFXRateFetcher struct {
+	client HTTPDoer
+}
 
+func (f *providerFXRateFetcher) FetchRate(ctx context.Context, fromCurrency, toCurrency string) (float64, string, error) {
 	providers := []struct {
 		name string
-		fn   func(context.Context, *http.Client, string, string) (float64, error)
+		fn   func(context.Context, HTTPDoer, string, string) (float64, error)
 	}{
 		{name: "frankfurter", fn: fetchExchangeRateFromFrankfurter},
 		{name: "open_er_api", fn: fetchExchangeRateFromOpenERAPI},
@@ -191,7 +240,7 @@ func fetchExchangeRateFromProviders(fromCurrency, toCurrency string) (float64, s
 
 	errs := make([]string, 0, len(providers))
 	for _, provider := range providers {
-		rate, err := provider.fn(ctx, client, fromCurrency, toCurrency)
+		rate, err := provider.fn(ctx, f.client, fromCurrency, toCurrency)
 		if err == nil {
 			return rate, provider.name, nil
 		}
@@ -205,7 +254,7 @@ type frankfurterRateResponse struct {
 	Rates map[string]float64 `json:"rates"`
 }
 
-func fetchExchangeRateFromFrankfurter(ctx context.Context, client *http.Client, fromCurrency, toCurrency string) (float64, error) {
+func fetchExchangeRateFromFrankfurter(ctx context.Context, client HTTPDoer, fromCurrency, toCurrency string) (float64, error) {
 	url := fmt.Sprintf("https://api.frankfurter.app/latest?from=%s&to=%s", fromCurrency, toCurrency)
 	var payload frankfurterRateResponse
 	if err := fetchJSONWithClient(ctx, client, url, &payload); err != nil {
@@ -223,7 +272,7 @@ type openERAPIRateResponse struct {
 	Rates  map[string]float64 `json:"rates"`
 }
 
-func fetchExchangeRateFromOpenERAPI(ctx context.Context, client *http.Client, fromCurrency, toCurrency string) (float64, error) {
+func fetchExchangeRateFromOpenERAPI(ctx context.Context, client HTTPDoer, fromCurrency, toCurrency string) (float64, error) {
 	url := fmt.Sprintf("https://open.er-api.com/v6/latest/%s", fromCurrency)
 	var payload openERAPIRateResponse
 	if err := fetchJSONWithClient(ctx, client, url, &payload); err != nil {
@@ -239,7 +288,7 @@ func fetchExchangeRateFromOpenERAPI(ctx context.Context, client *http.Client, fr
 	return rate, nil
 }
 
-func fetchJSONWithClient(ctx context.Context, client *http.Client, url string, target any) error {
+func fetchJSONWithClient(ctx context.Context, client HTTPDoer, url string, target any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
@@ -1,7 +1,11 @@
 package investlog
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -98,12 +102,7 @@ func TestRefreshExchangeRates(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	originalFetcher := exchangeRateFetcher
-	defer func() {
-		exchangeRateFetcher = originalFetcher
-	}()
-
-	exchangeRateFetcher = func(fromCurrency, toCurrency string) (float64, string, error) {
+	core.fx = FXRateFetcherFunc(func(_ context.Context, fromCurrency, toCurrency string) (float64, string, error) {
 		switch fromCurrency + "/" + toCurrency {
 		case "USD/CNY":
 			return 7.25, "stub", nil
@@ -112,7 +111,7 @@ func TestRefreshExchangeRates(t *testing.T) {
 		default:
 			return 0, "", fmt.Errorf("unexpected pair: %s/%s", fromCurrency, toCurrency)
 		}
-	}
+	})
 
 	updated, errors, err := core.RefreshExchangeRates()
 	if err != nil {
@@ -141,3 +140,105 @@ func TestRefreshExchangeRates(t *testing.T) {
 		t.Fatalf("unexpected HKD/CNY rate, got %.6f", hkdRate)
 	}
 }
+
+func TestRefreshExchangeRatesReportsPerPairErrors(t *testing.T) {
+	fetcher := FXRateFetcherFunc(func(_ context.Context, fromCurrency, toCurrency string) (float64, string, error) {
+		if fromCurrency == "USD" {
+			return 7.31, "stub", nil
+		}
+		return 0, "", fmt.Errorf("upstream unavailable")
+	})
+	core, err := OpenWithOptions(Options{
+		DBPath:        filepath.Join(t.TempDir(), "fx.db"),
+		FXRateFetcher: fetcher,
+	})
+	if err != nil {
+		t.Fatalf("open core: %v", err)
+	}
+	defer core.Close()
+
+	hkdBefore, err := core.GetRateToCNY("HKD")
+	if err != nil {
+		t.Fatalf("GetRateToCNY HKD returned error: %v", err)
+	}
+
+	updated, errors, err := core.RefreshExchangeRates()
+	if err != nil {
+		t.Fatalf("RefreshExchangeRates returned error: %v", err)
+	}
+	if updated != 1 {
+		t.Fatalf("expected updated=1, got %d", updated)
+	}
+	if len(errors) != 1 || !strings.Contains(errors[0], "HKD/CNY") || !strings.Contains(errors[0], "upstream unavailable") {
+		t.Fatalf("expected HKD/CNY error, got %v", errors)
+	}
+
+	usdRate, err := core.GetRateToCNY("USD")
+	if err != nil {
+		t.Fatalf("GetRateToCNY USD returned error: %v", err)
+	}
+	if !floatEquals(usdRate, 7.31, 0.0001) {
+		t.Fatalf("unexpected USD/CNY rate, got %.6f", usdRate)
+	}
+	hkdAfter, err := core.GetRateToCNY("HKD")
+	if err != nil {
+		t.Fatalf("GetRateToCNY HKD returned error: %v", err)
+	}
+	if hkdAfter != hkdBefore {
+		t.Fatalf("expected HKD/CNY unchanged at %.6f, got %.6f", hkdBefore, hkdAfter)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := core.SetExchangeRate("USD", "CNY", 7.2, "manual"); err != nil {
+		t.Fatalf("SetExchangeRate USD returned error: %v", err)
+	}
+	if _, err := core.SetExchangeRate("HKD", "CNY", 0.9, "manual"); err != nil {
+		t.Fatalf("SetExchangeRate HKD returned error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		amount float64
+		from   string
+		to     string
+		want   float64
+	}{
+		{name: "identity", amount: 12.5, from: "usd", to: "USD", want: 12.5},
+		{name: "USD to CNY", amount: 100, from: "USD", to: "CNY", want: 720},
+		{name: "CNY to USD", amount: 720, from: "CNY", to: "USD", want: 100},
+		{name: "HKD to USD via CNY", amount: 800, from: "HKD", to: "USD", want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := core.Convert(tt.amount, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Convert returned error: %v", err)
+			}
+			if !floatEquals(got, tt.want, 0.0001) {
+				t.Fatalf("expected %.4f, got %.4f", tt.want, got)
+			}
+		})
+	}
+
+	for _, amount := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		var invalid *ValidationError
+		if _, err := core.Convert(amount, "USD", "CNY"); !errors.As(err, &invalid) {
+			t.Fatalf("expected validation error for amount %v, got %v", amount, err)
+		}
+	}
+
+	if _, err := core.Convert(1, "EUR", "CNY"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid currency error, got %v", err)
+	}
+
+	if _, err := core.db.Exec("DELETE FROM exchange_rates WHERE from_currency = 'HKD'"); err != nil {
+		t.Fatalf("delete HKD rate: %v", err)
+	}
+	if _, err := core.Convert(1, "HKD", "USD"); !IsErrorCode(err, ErrCodeNotFound) {
+		t.Fatalf("expected missing rate error, got %v", err)
+	}
+}
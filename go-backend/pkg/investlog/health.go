@@ -0,0 +1,76 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusFailed   = "failed"
+
+	healthCheckTimeout = 2 * time.Second
+)
+
+// HealthCheck is the outcome of one readiness probe.
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthReport aggregates readiness probes. Status is degraded when any check fails.
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Healthy reports whether every check passed.
+func (r HealthReport) Healthy() bool {
+	return r.Status == HealthStatusOK
+}
+
+// CheckHealth pings the database and verifies the data directory accepts writes.
+func (c *Core) CheckHealth(ctx context.Context) HealthReport {
+	report := HealthReport{Status: HealthStatusOK}
+	add := func(name string, err error) {
+		check := HealthCheck{Name: name, Status: HealthStatusOK}
+		if err != nil {
+			check.Status = HealthStatusFailed
+			check.Error = err.Error()
+			report.Status = HealthStatusDegraded
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	add("database", c.pingDB(ctx))
+	add("data_dir", c.checkDataDirWritable())
+	return report
+}
+
+func (c *Core) pingDB(ctx context.Context) error {
+	if c == nil || c.db == nil {
+		return errors.New("database not initialized")
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return c.db.PingContext(ctx)
+}
+
+func (c *Core) checkDataDirWritable() error {
+	if c == nil || c.dbPath == "" {
+		return errors.New("data directory unknown")
+	}
+	f, err := os.CreateTemp(filepath.Dir(c.dbPath), ".health-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	closeErr := f.Close()
+	removeErr := os.Remove(name)
+	return errors.Join(closeErr, removeErr)
+}
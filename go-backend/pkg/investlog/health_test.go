@@ -0,0 +1,46 @@
+package investlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckHealth(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	report := core.CheckHealth(context.Background())
+	if !report.Healthy() {
+		t.Fatalf("expected healthy report, got %+v", report)
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("expected 2 checks, got %+v", report.Checks)
+	}
+	entries, err := os.ReadDir(filepath.Dir(core.DBPath()))
+	assertNoError(t, err, "read data dir")
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".health-") {
+			t.Fatalf("health probe file left behind: %s", entry.Name())
+		}
+	}
+}
+
+func TestCheckHealthDegradedWhenDBClosed(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	assertNoError(t, core.Close(), "close core")
+	report := core.CheckHealth(context.Background())
+	if report.Healthy() || report.Status != HealthStatusDegraded {
+		t.Fatalf("expected degraded report, got %+v", report)
+	}
+	if report.Checks[0].Name != "database" || report.Checks[0].Status != HealthStatusFailed || report.Checks[0].Error == "" {
+		t.Fatalf("expected failed database check, got %+v", report.Checks[0])
+	}
+	if report.Checks[1].Status != HealthStatusOK {
+		t.Fatalf("expected data_dir check to pass, got %+v", report.Checks[1])
+	}
+}
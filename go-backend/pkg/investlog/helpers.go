@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
 func normalizeSymbol(symbol string) string {
@@ -99,6 +101,11 @@ func round2(value float64) float64 {
 	return math.Round(value*100) / 100
 }
 
+// roundDecimal rounds value to places decimals and returns it as a float64.
+func roundDecimal(value decimal.Decimal, places int) float64 {
+	return value.Round(int32(places)).InexactFloat64()
+}
+
 func stringPtr(value string) *string {
 	if value == "" {
 		return nil
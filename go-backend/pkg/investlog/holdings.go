@@ -11,10 +11,13 @@ import (
 
 // GetHoldings calculates holdings aggregated by symbol, currency, and account.
 func (c *Core) GetHoldings(accountID string) ([]Holding, error) {
-	if accountID == "" && c.cache != nil {
-		if cached, ok := c.cache.getHoldings(); ok {
+	var cacheVersion uint64
+	if c.cache != nil {
+		cached, version, ok := c.cache.getHoldings(accountID)
+		if ok {
 			return cached, nil
 		}
+		cacheVersion = version
 	}
 	query := `
 		SELECT
@@ -49,6 +52,7 @@ func (c *Core) GetHoldings(accountID string) ([]Holding, error) {
 	}
 	query += " GROUP BY t.symbol_id, s.symbol, s.name, s.asset_type, t.account_id, t.currency HAVING total_shares > 0 OR total_cost != 0"
 
+	defer c.trackQuery("holdings.aggregate")()
 	rows, err := c.db.Query(query, params...)
 	if err != nil {
 		return nil, err
@@ -78,18 +82,21 @@ func (c *Core) GetHoldings(accountID string) ([]Holding, error) {
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	if accountID == "" && c.cache != nil {
-		c.cache.setHoldings(holdings)
+	if c.cache != nil {
+		c.cache.setHoldings(accountID, cacheVersion, holdings)
 	}
 	return holdings, nil
 }
 
 // GetHoldingsBySymbol returns holdings grouped by currency with PnL data.
 func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
+	var cacheVersion uint64
 	if c.cache != nil {
-		if cached, ok := c.cache.getBySymbol(); ok {
+		cached, version, ok := c.cache.getBySymbol()
+		if ok {
 			return cached, nil
 		}
+		cacheVersion = version
 	}
 	holdings, err := c.GetHoldings("")
 	if err != nil {
@@ -119,6 +126,11 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	inactiveSymbols, err := c.getInactiveSymbols()
+	if err != nil {
+		return nil, err
+	}
+	c.refreshMissingPrices(holdings, latestPrices, autoUpdateMap, inactiveSymbols)
 
 	byCurrency := map[string]struct {
 		totalCost Amount
@@ -140,6 +152,7 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 		})
 		symbolsData := make([]SymbolHolding, 0, len(data.symbols))
 		var totalMarketValue Amount
+		priceMissingCount := 0
 
 		for _, h := range data.symbols {
 			name := ""
@@ -153,10 +166,12 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 			priceKey := [2]string{h.Symbol, currency}
 			var latestPrice *Amount
 			var priceUpdatedAt *string
+			var dayChange *float64
 			if p, ok := latestPrices[priceKey]; ok {
 				lp := p.Price
 				latestPrice = &lp
 				priceUpdatedAt = &p.UpdatedAt
+				dayChange = dayChangePct(p.Price, p.PreviousClose)
 			}
 
 			marketValue := h.TotalCost
@@ -177,6 +192,10 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 			if assetType == "" {
 				assetType = "stock"
 			}
+			priceMissing := latestPrice == nil && isPricedHolding(h)
+			if priceMissing {
+				priceMissingCount++
+			}
 			label := assetTypeLabels[assetType]
 			if label == "" {
 				label = assetType
@@ -197,6 +216,7 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 				AssetType:      assetType,
 				AssetTypeLabel: label,
 				AutoUpdate:     autoUpdate,
+				Inactive:       inactiveSymbols[h.Symbol],
 				AccountID:      h.AccountID,
 				AccountName:    accountName,
 				TotalShares:    h.TotalShares,
@@ -207,6 +227,8 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 				MarketValue:    marketValue,
 				UnrealizedPnL:  unrealizedPnL,
 				PnlPercent:     pnlPercent,
+				DayChangePct:   dayChange,
+				PriceMissing:   priceMissing,
 			})
 		}
 
@@ -225,25 +247,91 @@ func (c *Core) GetHoldingsBySymbol() (HoldingsBySymbolResult, error) {
 		}
 
 		result[currency] = SymbolHoldingsCurrency{
-			TotalCost:        data.totalCost,
-			TotalMarketValue: totalMarketValue,
-			TotalPnL:         Amount{totalMarketValue.Sub(data.totalCost.Decimal)},
-			Symbols:          symbolsData,
-			ByAccount:        byAccount,
+			TotalCost:         data.totalCost,
+			TotalMarketValue:  totalMarketValue,
+			TotalPnL:          Amount{totalMarketValue.Sub(data.totalCost.Decimal)},
+			Symbols:           symbolsData,
+			ByAccount:         byAccount,
+			PriceMissingCount: priceMissingCount,
 		}
 	}
 	if c.cache != nil {
-		c.cache.setBySymbol(result)
+		c.cache.setBySymbol(cacheVersion, result)
 	}
 	return result, nil
 }
 
+// isPricedHolding reports whether a holding is valued from latest_prices.
+// Cash and closed positions are carried at cost and never need a quote.
+func isPricedHolding(h Holding) bool {
+	return !strings.EqualFold(h.AssetType, "cash") && h.TotalShares.IsPositive()
+}
+
+// refreshMissingPrices fetches quotes in the background for up to
+// Options.MissingPriceFetchLimit holdings that have no latest_prices row yet,
+// skipping symbols with auto-update disabled or marked inactive. The caller
+// values those holdings at cost and flags them; each stored quote invalidates
+// the holdings cache, so a later read picks it up. Only one refresh runs at a
+// time, none starts once Close has begun, and a running one stops before its
+// next symbol.
+func (c *Core) refreshMissingPrices(holdings []Holding, latestPrices map[[2]string]LatestPrice, autoUpdateMap map[string]int, inactiveSymbols map[string]bool) {
+	if c.missingPriceFetchLimit <= 0 {
+		return
+	}
+	seen := map[[2]string]struct{}{}
+	var missing []Holding
+	for _, h := range holdings {
+		if len(missing) >= c.missingPriceFetchLimit {
+			break
+		}
+		key := [2]string{h.Symbol, h.Currency}
+		if _, ok := latestPrices[key]; ok || !isPricedHolding(h) {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if autoUpdate, ok := autoUpdateMap[h.Symbol]; (ok && autoUpdate == 0) || inactiveSymbols[h.Symbol] {
+			continue
+		}
+		missing = append(missing, h)
+	}
+	if len(missing) == 0 || !c.missingPriceRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+	c.closeMu.Lock()
+	if c.closed.Load() {
+		c.closeMu.Unlock()
+		c.missingPriceRefreshing.Store(false)
+		return
+	}
+	c.missingPriceRefresh.Add(1)
+	c.closeMu.Unlock()
+	go func() {
+		defer c.missingPriceRefresh.Done()
+		defer c.missingPriceRefreshing.Store(false)
+		for _, h := range missing {
+			if c.closed.Load() {
+				return
+			}
+			result, err := c.UpdatePrice(h.Symbol, h.Currency, h.AssetType)
+			if result.Price == nil {
+				c.Logger().Warn("fetch missing price failed", "symbol", h.Symbol, "currency", h.Currency, "message", result.Message, "err", err)
+			}
+		}
+	}()
+}
+
 // GetHoldingsByCurrency calculates allocation by asset type within currency.
 func (c *Core) GetHoldingsByCurrency() (HoldingsByCurrencyResult, error) {
+	var cacheVersion uint64
 	if c.cache != nil {
-		if cached, ok := c.cache.getByCurrency(); ok {
+		cached, version, ok := c.cache.getByCurrency()
+		if ok {
 			return cached, nil
 		}
+		cacheVersion = version
 	}
 	holdings, err := c.GetHoldings("")
 	if err != nil {
@@ -253,6 +341,19 @@ func (c *Core) GetHoldingsByCurrency() (HoldingsByCurrencyResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	result, err := c.buildHoldingsByCurrency(holdings, latestPrices)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		c.cache.setByCurrency(cacheVersion, result)
+	}
+	return result, nil
+}
+
+// buildHoldingsByCurrency aggregates holdings into per-currency asset-type
+// allocations and checks them against the configured bands.
+func (c *Core) buildHoldingsByCurrency(holdings []Holding, latestPrices map[[2]string]LatestPrice) (HoldingsByCurrencyResult, error) {
 	settings, err := c.GetAllocationSettings("")
 	if err != nil {
 		return nil, err
@@ -285,11 +386,7 @@ func (c *Core) GetHoldingsByCurrency() (HoldingsByCurrencyResult, error) {
 		if entry.byAssetType == nil {
 			entry.byAssetType = map[string]Amount{}
 		}
-		priceKey := [2]string{h.Symbol, curr}
-		marketValue := h.TotalCost
-		if p, ok := latestPrices[priceKey]; ok && h.TotalShares.IsPositive() {
-			marketValue = Amount{p.Price.Mul(h.TotalShares.Decimal)}
-		}
+		marketValue := holdingMarketValue(h, latestPrices)
 		entry.total = Amount{entry.total.Add(marketValue.Decimal)}
 		asset := strings.ToLower(h.AssetType)
 		if asset == "" {
@@ -349,20 +446,10 @@ func (c *Core) GetHoldingsByCurrency() (HoldingsByCurrencyResult, error) {
 			if !ok {
 				setting = struct{ min, max float64 }{0, 100}
 			}
-			warning := ""
-			if percent < setting.min {
-				warning = fmt.Sprintf("低于最小配置 %.0f%%", setting.min)
-			} else if percent > setting.max {
-				warning = fmt.Sprintf("超过最大配置 %.0f%%", setting.max)
-			}
 			label := labels[assetType]
 			if label == "" {
 				label = assetType
 			}
-			var warningPtr *string
-			if warning != "" {
-				warningPtr = &warning
-			}
 			allocations = append(allocations, AllocationEntry{
 				AssetType:  assetType,
 				Label:      label,
@@ -370,23 +457,46 @@ func (c *Core) GetHoldingsByCurrency() (HoldingsByCurrencyResult, error) {
 				Percent:    round2(percent),
 				MinPercent: setting.min,
 				MaxPercent: setting.max,
-				Warning:    warningPtr,
+				Warning:    allocationWarning(percent, setting.min, setting.max),
 			})
 		}
 		result[curr] = CurrencyAllocation{Total: data.total, Allocations: allocations}
 	}
-	if c.cache != nil {
-		c.cache.setByCurrency(result)
-	}
 	return result, nil
 }
 
+// allocationWarning describes how percent falls outside [min, max], or
+// returns nil when it is within the band.
+func allocationWarning(percent, min, max float64) *string {
+	var warning string
+	if percent < min {
+		warning = fmt.Sprintf("低于最小配置 %.0f%%", min)
+	} else if percent > max {
+		warning = fmt.Sprintf("超过最大配置 %.0f%%", max)
+	} else {
+		return nil
+	}
+	return &warning
+}
+
+// holdingMarketValue values h at its latest price, or at cost when no quote
+// exists or the position is closed.
+func holdingMarketValue(h Holding, latestPrices map[[2]string]LatestPrice) Amount {
+	if p, ok := latestPrices[[2]string{h.Symbol, h.Currency}]; ok && h.TotalShares.IsPositive() {
+		return Amount{p.Price.Mul(h.TotalShares.Decimal)}
+	}
+	return h.TotalCost
+}
+
 // GetHoldingsByCurrencyAndAccount returns holdings grouped by currency and account.
 func (c *Core) GetHoldingsByCurrencyAndAccount() (HoldingsByCurrencyAccountResult, error) {
+	var cacheVersion uint64
 	if c.cache != nil {
-		if cached, ok := c.cache.getByCurrencyAccount(); ok {
+		cached, version, ok := c.cache.getByCurrencyAccount()
+		if ok {
 			return cached, nil
 		}
+		cacheVersion = version
 	}
 	holdings, err := c.GetHoldings("")
 	if err != nil {
@@ -489,7 +599,7 @@ func (c *Core) GetHoldingsByCurrencyAndAccount() (HoldingsByCurrencyAccountResul
 		}
 	}
 	if c.cache != nil {
-		c.cache.setByCurrencyAccount(result)
+		c.cache.setByCurrencyAccount(cacheVersion, result)
 	}
 	return result, nil
 }
@@ -514,7 +624,7 @@ func (c *Core) AdjustAssetValue(symbol string, newValue Amount, currency string,
 		text = &msg
 	}
 	return c.AddTransaction(AddTransactionRequest{
-		TransactionDate: todayISO(),
+		TransactionDate: c.TodayISO(),
 		Symbol:          symbol,
 		TransactionType: "ADJUST",
 		Quantity:        NewAmountFromInt(0),
@@ -0,0 +1,157 @@
+package investlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Market buckets used by GetHoldingsByExchange.
+const (
+	MarketCN    = "CN"
+	MarketHK    = "HK"
+	MarketUS    = "US"
+	MarketOther = "OTHER"
+)
+
+// exchangeMarkets maps normalized exchange names (lowercase, no spaces) as
+// stored from Yahoo metadata or entered by hand to a market bucket.
+var exchangeMarkets = map[string]string{
+	"shanghai": MarketCN, "shenzhen": MarketCN, "sse": MarketCN, "szse": MarketCN,
+	"shh": MarketCN, "shz": MarketCN, "sh": MarketCN, "sz": MarketCN,
+	"hkse": MarketHK, "hkex": MarketHK, "hkg": MarketHK, "hongkong": MarketHK,
+	"nasdaq": MarketUS, "nasdaqgs": MarketUS, "nasdaqgm": MarketUS, "nasdaqcm": MarketUS,
+	"nms": MarketUS, "ngm": MarketUS, "ncm": MarketUS, "nyse": MarketUS, "nyq": MarketUS,
+	"nysearca": MarketUS, "pcx": MarketUS, "nyseamerican": MarketUS, "amex": MarketUS,
+	"ase": MarketUS, "bats": MarketUS, "cboe": MarketUS,
+}
+
+// MarketExposure is the value held on one market within a currency.
+type MarketExposure struct {
+	Market      string   `json:"market"`
+	MarketValue Amount   `json:"market_value"`
+	Percent     float64  `json:"percent"`
+	Symbols     []string `json:"symbols"`
+}
+
+// CurrencyMarketExposure lists a currency's holdings by market, largest first.
+type CurrencyMarketExposure struct {
+	Total   Amount           `json:"total"`
+	Markets []MarketExposure `json:"markets"`
+}
+
+// HoldingsByExchangeResult maps currency to market exposure.
+type HoldingsByExchangeResult map[string]CurrencyMarketExposure
+
+// GetHoldingsByExchange groups open holdings by the market of their
+// exchange. Symbols without an exchange are placed by their detected symbol
+// type; unrecognized exchanges fall into MarketOther. An empty currency
+// covers all currencies.
+func (c *Core) GetHoldingsByExchange(currency string) (HoldingsByExchangeResult, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" && !isValidCurrency(currency) {
+		return nil, NewValidationError("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+
+	holdings, err := c.GetHoldings("")
+	if err != nil {
+		return nil, err
+	}
+	latestPrices, err := c.GetAllLatestPrices()
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := c.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	exchanges := make(map[string]string, len(symbols))
+	for _, s := range symbols {
+		if s.Exchange != nil {
+			exchanges[s.Symbol] = *s.Exchange
+		}
+	}
+
+	type bucket struct {
+		value   Amount
+		symbols map[string]struct{}
+	}
+	byCurrency := map[string]map[string]*bucket{}
+	totals := map[string]Amount{}
+	for _, h := range holdings {
+		if !h.TotalShares.IsPositive() || (currency != "" && h.Currency != currency) {
+			continue
+		}
+		market := marketForExchange(exchanges[h.Symbol])
+		if market == "" {
+			market = marketForSymbolType(c.price.symbolType(h.Symbol, h.Currency, h.AssetType))
+		}
+		markets := byCurrency[h.Currency]
+		if markets == nil {
+			markets = map[string]*bucket{}
+			byCurrency[h.Currency] = markets
+		}
+		b := markets[market]
+		if b == nil {
+			b = &bucket{symbols: map[string]struct{}{}}
+			markets[market] = b
+		}
+		value := holdingMarketValue(h, latestPrices)
+		b.value = Amount{b.value.Add(value.Decimal)}
+		b.symbols[h.Symbol] = struct{}{}
+		totals[h.Currency] = Amount{totals[h.Currency].Add(value.Decimal)}
+	}
+
+	result := HoldingsByExchangeResult{}
+	for curr, markets := range byCurrency {
+		total := totals[curr]
+		exposures := make([]MarketExposure, 0, len(markets))
+		for market, b := range markets {
+			exposure := MarketExposure{Market: market, MarketValue: b.value, Symbols: make([]string, 0, len(b.symbols))}
+			if total.IsPositive() {
+				exposure.Percent = round2(b.value.Div(total.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64())
+			}
+			for symbol := range b.symbols {
+				exposure.Symbols = append(exposure.Symbols, symbol)
+			}
+			sort.Strings(exposure.Symbols)
+			exposures = append(exposures, exposure)
+		}
+		sort.Slice(exposures, func(i, j int) bool {
+			if !exposures[i].MarketValue.Equal(exposures[j].MarketValue.Decimal) {
+				return exposures[i].MarketValue.GreaterThan(exposures[j].MarketValue.Decimal)
+			}
+			return exposures[i].Market < exposures[j].Market
+		})
+		result[curr] = CurrencyMarketExposure{Total: total, Markets: exposures}
+	}
+	return result, nil
+}
+
+// marketForExchange returns the market of a known exchange, MarketOther for
+// an unknown one, and "" when exchange is blank.
+func marketForExchange(exchange string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(exchange), ""))
+	if key == "" {
+		return ""
+	}
+	if market, ok := exchangeMarkets[key]; ok {
+		return market
+	}
+	return MarketOther
+}
+
+func marketForSymbolType(symbolType string) string {
+	switch symbolType {
+	case "a_share", "etf", "fund":
+		return MarketCN
+	case "hk_stock", "hk_connect":
+		return MarketHK
+	case "us_stock":
+		return MarketUS
+	default:
+		return MarketOther
+	}
+}
@@ -0,0 +1,88 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func marketExposureFor(t *testing.T, exposure CurrencyMarketExposure, market string) MarketExposure {
+	t.Helper()
+	for _, m := range exposure.Markets {
+		if m.Market == market {
+			return m
+		}
+	}
+	t.Fatalf("market %s not found in %+v", market, exposure.Markets)
+	return MarketExposure{}
+}
+
+func TestGetHoldingsByExchange(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	testBuyTransaction(t, core, "BABA", 5, 100, "USD", "acc-1")
+	testBuyTransaction(t, core, "VOD", 10, 50, "USD", "acc-1")
+	testBuyTransaction(t, core, "600519", 1, 1000, "CNY", "acc-1")
+
+	nasdaq, lse := "NasdaqGS", "LSE"
+	_, err := core.UpdateSymbolMetadata("AAPL", nil, nil, nil, nil, &nasdaq)
+	assertNoError(t, err, "set AAPL exchange")
+	_, err = core.UpdateSymbolMetadata("VOD", nil, nil, nil, nil, &lse)
+	assertNoError(t, err, "set VOD exchange")
+
+	result, err := core.GetHoldingsByExchange("")
+	assertNoError(t, err, "GetHoldingsByExchange")
+
+	usd := result["USD"]
+	if !usd.Total.Equal(NewAmountFromInt(2000).Decimal) {
+		t.Fatalf("expected USD total 2000, got %s", usd.Total)
+	}
+	us := marketExposureFor(t, usd, MarketUS)
+	if us.Percent != 75 || len(us.Symbols) != 2 || us.Symbols[0] != "AAPL" || us.Symbols[1] != "BABA" {
+		t.Fatalf("unexpected US bucket: %+v", us)
+	}
+	other := marketExposureFor(t, usd, MarketOther)
+	if other.Percent != 25 || len(other.Symbols) != 1 || other.Symbols[0] != "VOD" {
+		t.Fatalf("expected unknown exchange in default bucket, got %+v", other)
+	}
+	if usd.Markets[0].Market != MarketUS {
+		t.Fatalf("expected largest market first, got %+v", usd.Markets)
+	}
+	if cn := marketExposureFor(t, result["CNY"], MarketCN); cn.Percent != 100 {
+		t.Fatalf("expected A-share inferred as CN, got %+v", cn)
+	}
+
+	cnyOnly, err := core.GetHoldingsByExchange("cny")
+	assertNoError(t, err, "GetHoldingsByExchange CNY")
+	if _, ok := cnyOnly["USD"]; ok || len(cnyOnly) != 1 {
+		t.Fatalf("expected only CNY, got %+v", cnyOnly)
+	}
+
+	_, err = core.GetHoldingsByExchange("XYZ")
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestMarketForExchange(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"NasdaqGS":   MarketUS,
+		"NYSE Arca":  MarketUS,
+		"HKSE":       MarketHK,
+		"Shanghai":   MarketCN,
+		"Shenzhen":   MarketCN,
+		"London":     MarketOther,
+		"  nyse  ":   MarketUS,
+		"Hong Kong":  MarketHK,
+		"Frankfurt ": MarketOther,
+	}
+	for exchange, want := range cases {
+		if got := marketForExchange(exchange); got != want {
+			t.Fatalf("marketForExchange(%q) = %q, want %q", exchange, got, want)
+		}
+	}
+}
@@ -2,96 +2,101 @@ package investlog
 
 import "sync"
 
+// Cache keys for each holdings query shape.
+const (
+	holdingsCacheKeyBySymbol          = "by_symbol"
+	holdingsCacheKeyByCurrency        = "by_currency"
+	holdingsCacheKeyByCurrencyAccount = "by_currency_account"
+)
+
+// holdingsCacheKeyHoldings keys GetHoldings per account ("" is all accounts).
+func holdingsCacheKeyHoldings(accountID string) string {
+	return "holdings:" + accountID
+}
+
+// holdingsCache memoizes holdings aggregations keyed by query shape. Every
+// write bumps version and drops all entries; a result computed before a write
+// carries the old version and is discarded by set, so a slow reader can never
+// store stale data.
 type holdingsCache struct {
-	mu                  sync.RWMutex
-	holdings            []Holding
-	bySymbol            HoldingsBySymbolResult
-	byCurrency          HoldingsByCurrencyResult
-	byCurrencyAccount   HoldingsByCurrencyAccountResult
-	holdingsValid       bool
-	bySymbolValid       bool
-	byCurrencyValid     bool
-	byCurrencyAcctValid bool
+	mu      sync.RWMutex
+	version uint64
+	entries map[string]any
 }
 
 func newHoldingsCache() *holdingsCache {
-	return &holdingsCache{}
+	return &holdingsCache{entries: map[string]any{}}
 }
 
-func (c *holdingsCache) getHoldings() ([]Holding, bool) {
+// get returns the entry for key, or the current version to pass to set on a miss.
+func (c *holdingsCache) get(key string) (any, uint64, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	if !c.holdingsValid {
-		return nil, false
-	}
-	copied := append([]Holding(nil), c.holdings...)
-	return copied, true
+	value, ok := c.entries[key]
+	return value, c.version, ok
 }
 
-func (c *holdingsCache) setHoldings(items []Holding) {
+// set stores value unless the cache was invalidated since version was read.
+func (c *holdingsCache) set(key string, version uint64, value any) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.holdings = append([]Holding(nil), items...)
-	c.holdingsValid = true
+	if version != c.version {
+		return
+	}
+	c.entries[key] = value
 }
 
-func (c *holdingsCache) getBySymbol() (HoldingsBySymbolResult, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if !c.bySymbolValid {
-		return nil, false
+func (c *holdingsCache) getHoldings(accountID string) ([]Holding, uint64, bool) {
+	value, version, ok := c.get(holdingsCacheKeyHoldings(accountID))
+	if !ok {
+		return nil, version, false
 	}
-	return c.bySymbol, true
+	return append([]Holding(nil), value.([]Holding)...), version, true
 }
 
-func (c *holdingsCache) setBySymbol(result HoldingsBySymbolResult) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.bySymbol = result
-	c.bySymbolValid = true
+func (c *holdingsCache) setHoldings(accountID string, version uint64, items []Holding) {
+	c.set(holdingsCacheKeyHoldings(accountID), version, append([]Holding(nil), items...))
 }
 
-func (c *holdingsCache) getByCurrency() (HoldingsByCurrencyResult, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if !c.byCurrencyValid {
-		return nil, false
+func (c *holdingsCache) getBySymbol() (HoldingsBySymbolResult, uint64, bool) {
+	value, version, ok := c.get(holdingsCacheKeyBySymbol)
+	if !ok {
+		return nil, version, false
 	}
-	return c.byCurrency, true
+	return value.(HoldingsBySymbolResult), version, true
 }
 
-func (c *holdingsCache) setByCurrency(result HoldingsByCurrencyResult) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.byCurrency = result
-	c.byCurrencyValid = true
+func (c *holdingsCache) setBySymbol(version uint64, result HoldingsBySymbolResult) {
+	c.set(holdingsCacheKeyBySymbol, version, result)
 }
 
-func (c *holdingsCache) getByCurrencyAccount() (HoldingsByCurrencyAccountResult, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if !c.byCurrencyAcctValid {
-		return nil, false
+func (c *holdingsCache) getByCurrency() (HoldingsByCurrencyResult, uint64, bool) {
+	value, version, ok := c.get(holdingsCacheKeyByCurrency)
+	if !ok {
+		return nil, version, false
 	}
-	return c.byCurrencyAccount, true
+	return value.(HoldingsByCurrencyResult), version, true
 }
 
-func (c *holdingsCache) setByCurrencyAccount(result HoldingsByCurrencyAccountResult) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.byCurrencyAccount = result
-	c.byCurrencyAcctValid = true
+func (c *holdingsCache) setByCurrency(version uint64, result HoldingsByCurrencyResult) {
+	c.set(holdingsCacheKeyByCurrency, version, result)
+}
+
+func (c *holdingsCache) getByCurrencyAccount() (HoldingsByCurrencyAccountResult, uint64, bool) {
+	value, version, ok := c.get(holdingsCacheKeyByCurrencyAccount)
+	if !ok {
+		return nil, version, false
+	}
+	return value.(HoldingsByCurrencyAccountResult), version, true
+}
+
+func (c *holdingsCache) setByCurrencyAccount(version uint64, result HoldingsByCurrencyAccountResult) {
+	c.set(holdingsCacheKeyByCurrencyAccount, version, result)
 }
 
 func (c *holdingsCache) invalidate() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.holdings = nil
-	c.bySymbol = nil
-	c.byCurrency = nil
-	c.byCurrencyAccount = nil
-	c.holdingsValid = false
-	c.bySymbolValid = false
-	c.byCurrencyValid = false
-	c.byCurrencyAcctValid = false
+	c.version++
+	c.entries = map[string]any{}
 }
@@ -0,0 +1,193 @@
+package investlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestHoldingsCacheDropsStaleSet(t *testing.T) {
+	cache := newHoldingsCache()
+
+	_, version, ok := cache.getHoldings("")
+	if ok {
+		t.Fatal("expected empty cache")
+	}
+	// A write lands while the reader is still computing.
+	cache.invalidate()
+	cache.setHoldings("", version, []Holding{{Symbol: "STALE"}})
+	if _, _, ok := cache.getHoldings(""); ok {
+		t.Fatal("expected result computed before invalidation to be dropped")
+	}
+
+	_, version, _ = cache.getHoldings("")
+	cache.setHoldings("", version, []Holding{{Symbol: "FRESH"}})
+	got, _, ok := cache.getHoldings("")
+	if !ok || len(got) != 1 || got[0].Symbol != "FRESH" {
+		t.Fatalf("expected fresh holdings cached, got %+v ok=%v", got, ok)
+	}
+	if _, _, ok := cache.getHoldings("acc-1"); ok {
+		t.Fatal("expected per-account holdings to be keyed separately")
+	}
+
+	got[0].Symbol = "MUTATED"
+	again, _, _ := cache.getHoldings("")
+	if again[0].Symbol != "FRESH" {
+		t.Fatal("expected cached holdings to be copied on read")
+	}
+}
+
+func TestHoldingsCacheConcurrentAccess(t *testing.T) {
+	cache := newHoldingsCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				account := fmt.Sprintf("acc-%d", j%3)
+				if _, version, ok := cache.getHoldings(account); !ok {
+					cache.setHoldings(account, version, []Holding{{Symbol: "AAPL", AccountID: account}})
+				}
+				if _, version, ok := cache.getBySymbol(); !ok {
+					cache.setBySymbol(version, HoldingsBySymbolResult{})
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				cache.invalidate()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGetHoldings_ServedFromCacheUntilWrite(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+
+	first, err := core.GetHoldings("acc-1")
+	assertNoError(t, err, "GetHoldings")
+
+	// Change the table behind the cache's back: a cached read must not see it.
+	if _, err := core.db.Exec("UPDATE transactions SET quantity = 99"); err != nil {
+		t.Fatalf("update transactions: %v", err)
+	}
+	cached, err := core.GetHoldings("acc-1")
+	assertNoError(t, err, "GetHoldings cached")
+	if !cached[0].TotalShares.Equal(first[0].TotalShares.Decimal) {
+		t.Fatalf("expected cached shares %s, got %s", first[0].TotalShares, cached[0].TotalShares)
+	}
+
+	testBuyTransaction(t, core, "MSFT", 1, 300, "USD", "acc-1")
+	fresh, err := core.GetHoldings("acc-1")
+	assertNoError(t, err, "GetHoldings after write")
+	if len(fresh) != 2 {
+		t.Fatalf("expected write to invalidate cache, got %+v", fresh)
+	}
+}
+
+func TestHoldingsCacheInterleavedReadsAndWrites(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	const writes = 20
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < writes; i++ {
+			_, err := core.AddTransaction(AddTransactionRequest{
+				Symbol:          "AAPL",
+				TransactionType: "BUY",
+				Quantity:        NewAmount(1),
+				Price:           NewAmount(100),
+				Currency:        "USD",
+				AccountID:       "acc-1",
+				AssetType:       "stock",
+			})
+			if err != nil {
+				t.Errorf("AddTransaction: %v", err)
+				return
+			}
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				if _, err := core.GetHoldings(""); err != nil {
+					t.Errorf("GetHoldings: %v", err)
+					return
+				}
+				if _, err := core.GetHoldingsByCurrency(); err != nil {
+					t.Errorf("GetHoldingsByCurrency: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	holdings, err := core.GetHoldings("")
+	assertNoError(t, err, "GetHoldings after writes")
+	if len(holdings) != 1 || holdings[0].TotalShares.InexactFloat64() != writes {
+		t.Fatalf("expected %d shares after all writes, got %+v", writes, holdings)
+	}
+}
+
+func BenchmarkGetHoldingsBySymbol(b *testing.B) {
+	core, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("open db: %v", err)
+	}
+	defer core.Close()
+	if _, err := core.AddAccount(Account{AccountID: "acc-1", AccountName: "Main"}); err != nil {
+		b.Fatalf("add account: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		_, err := core.AddTransaction(AddTransactionRequest{
+			Symbol:          fmt.Sprintf("SYM%03d", i%50),
+			TransactionType: "BUY",
+			Quantity:        NewAmount(10),
+			Price:           NewAmount(100),
+			Currency:        "USD",
+			AccountID:       "acc-1",
+			AssetType:       "stock",
+		})
+		if err != nil {
+			b.Fatalf("add transaction: %v", err)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		if err := core.UpdateLatestPrice(fmt.Sprintf("SYM%03d", i), "USD", NewAmount(110)); err != nil {
+			b.Fatalf("update price: %v", err)
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := core.GetHoldingsBySymbol(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		cache := core.cache
+		core.cache = nil
+		defer func() { core.cache = cache }()
+		for i := 0; i < b.N; i++ {
+			if _, err := core.GetHoldingsBySymbol(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package investlog
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// isCashAssetType reports whether assetType is the cash asset type.
+func isCashAssetType(assetType string) bool {
+	return strings.EqualFold(strings.TrimSpace(assetType), "cash")
+}
+
+// ExcludeCashFromSymbolPercents returns a copy of result whose symbol
+// percentages are computed over each currency's non-cash market value. Cash
+// positions stay listed with their market value and a zero percent; totals are
+// unchanged. result itself is not modified, so cached results are safe to pass.
+func ExcludeCashFromSymbolPercents(result HoldingsBySymbolResult) HoldingsBySymbolResult {
+	out := make(HoldingsBySymbolResult, len(result))
+	for currency, data := range result {
+		var nonCashTotal Amount
+		for _, s := range data.Symbols {
+			if !isCashAssetType(s.AssetType) {
+				nonCashTotal = Amount{nonCashTotal.Add(s.MarketValue.Decimal)}
+			}
+		}
+		data.Symbols = symbolPercentsOver(data.Symbols, nonCashTotal)
+		byAccount := make(map[string]SymbolHoldingsByAccount, len(data.ByAccount))
+		for accountID, entry := range data.ByAccount {
+			entry.Symbols = symbolPercentsOver(entry.Symbols, nonCashTotal)
+			byAccount[accountID] = entry
+		}
+		data.ByAccount = byAccount
+		out[currency] = data
+	}
+	return out
+}
+
+// symbolPercentsOver copies symbols with non-cash percentages relative to
+// total and cash percentages set to zero.
+func symbolPercentsOver(symbols []SymbolHolding, total Amount) []SymbolHolding {
+	out := make([]SymbolHolding, len(symbols))
+	for i, s := range symbols {
+		s.Percent = 0
+		if !isCashAssetType(s.AssetType) && total.IsPositive() {
+			s.Percent = round2(s.MarketValue.Div(total.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64())
+		}
+		out[i] = s
+	}
+	return out
+}
+
+// ExcludeCashFromAllocationPercents returns a copy of result whose asset-type
+// percentages and band warnings are computed over each currency's non-cash
+// total. The cash entry keeps its amount with a zero percent and no warning;
+// Total still includes cash. result itself is not modified.
+func ExcludeCashFromAllocationPercents(result HoldingsByCurrencyResult) HoldingsByCurrencyResult {
+	out := make(HoldingsByCurrencyResult, len(result))
+	for currency, data := range result {
+		nonCashTotal := data.Total
+		for _, entry := range data.Allocations {
+			if isCashAssetType(entry.AssetType) {
+				nonCashTotal = Amount{nonCashTotal.Sub(entry.Amount.Decimal)}
+			}
+		}
+		allocations := make([]AllocationEntry, len(data.Allocations))
+		for i, entry := range data.Allocations {
+			if isCashAssetType(entry.AssetType) {
+				entry.Percent = 0
+				entry.Warning = nil
+			} else {
+				percent := 0.0
+				if nonCashTotal.IsPositive() {
+					percent = entry.Amount.Div(nonCashTotal.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64()
+				}
+				entry.Percent = round2(percent)
+				entry.Warning = allocationWarning(percent, entry.MinPercent, entry.MaxPercent)
+			}
+			allocations[i] = entry
+		}
+		data.Allocations = allocations
+		out[currency] = data
+	}
+	return out
+}
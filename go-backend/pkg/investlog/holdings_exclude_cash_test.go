@@ -0,0 +1,78 @@
+package investlog
+
+import "testing"
+
+func TestExcludeCashFromSymbolPercents(t *testing.T) {
+	t.Parallel()
+
+	symbols := []SymbolHolding{
+		{Symbol: "AAPL", AssetType: "stock", AccountID: "a", MarketValue: NewAmountFromInt(300), Percent: 30},
+		{Symbol: "BND", AssetType: "bond", AccountID: "b", MarketValue: NewAmountFromInt(100), Percent: 10},
+		{Symbol: "CASH", AssetType: "CASH", AccountID: "a", MarketValue: NewAmountFromInt(600), Percent: 60},
+	}
+	result := HoldingsBySymbolResult{"USD": {
+		TotalMarketValue: NewAmountFromInt(1000),
+		Symbols:          symbols,
+		ByAccount: map[string]SymbolHoldingsByAccount{
+			"a": {AccountName: "A", Symbols: []SymbolHolding{symbols[0], symbols[2]}},
+			"b": {AccountName: "B", Symbols: []SymbolHolding{symbols[1]}},
+		},
+	}}
+
+	got := ExcludeCashFromSymbolPercents(result)["USD"]
+	want := map[string]float64{"AAPL": 75, "BND": 25, "CASH": 0}
+	for _, s := range got.Symbols {
+		if s.Percent != want[s.Symbol] {
+			t.Fatalf("%s: expected %.2f%%, got %.2f%%", s.Symbol, want[s.Symbol], s.Percent)
+		}
+	}
+	for _, s := range got.ByAccount["a"].Symbols {
+		if s.Percent != want[s.Symbol] {
+			t.Fatalf("by_account %s: expected %.2f%%, got %.2f%%", s.Symbol, want[s.Symbol], s.Percent)
+		}
+	}
+	if len(got.Symbols) != 3 || got.Symbols[2].MarketValue.InexactFloat64() != 600 {
+		t.Fatalf("expected cash still listed with its market value, got %+v", got.Symbols)
+	}
+	if got.TotalMarketValue.InexactFloat64() != 1000 {
+		t.Fatalf("expected total unchanged, got %s", got.TotalMarketValue)
+	}
+	if result["USD"].Symbols[0].Percent != 30 || result["USD"].ByAccount["a"].Symbols[0].Percent != 30 {
+		t.Fatal("expected input result left unmodified")
+	}
+}
+
+func TestExcludeCashFromAllocationPercents(t *testing.T) {
+	t.Parallel()
+
+	result := HoldingsByCurrencyResult{"CNY": {
+		Total: NewAmountFromInt(1000),
+		Allocations: []AllocationEntry{
+			{AssetType: "stock", Amount: NewAmountFromInt(400), Percent: 40, MinPercent: 0, MaxPercent: 70},
+			{AssetType: "bond", Amount: NewAmountFromInt(100), Percent: 10, MinPercent: 20, MaxPercent: 100},
+			{AssetType: "cash", Amount: NewAmountFromInt(500), Percent: 50, MinPercent: 0, MaxPercent: 30,
+				Warning: allocationWarning(50, 0, 30)},
+		},
+	}}
+
+	got := ExcludeCashFromAllocationPercents(result)["CNY"]
+	stock, bond, cash := got.Allocations[0], got.Allocations[1], got.Allocations[2]
+	if stock.Percent != 80 || bond.Percent != 20 {
+		t.Fatalf("expected 80/20 split over non-cash assets, got %.2f/%.2f", stock.Percent, bond.Percent)
+	}
+	if stock.Warning == nil || *stock.Warning != "超过最大配置 70%" {
+		t.Fatalf("expected stock band warning recomputed, got %v", stock.Warning)
+	}
+	if bond.Warning != nil {
+		t.Fatalf("expected bond back within band, got %q", *bond.Warning)
+	}
+	if cash.Percent != 0 || cash.Warning != nil || cash.Amount.InexactFloat64() != 500 {
+		t.Fatalf("expected cash reported separately at 0%%, got %+v", cash)
+	}
+	if got.Total.InexactFloat64() != 1000 {
+		t.Fatalf("expected total unchanged, got %s", got.Total)
+	}
+	if result["CNY"].Allocations[0].Percent != 40 {
+		t.Fatal("expected input result left unmodified")
+	}
+}
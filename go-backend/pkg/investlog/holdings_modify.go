@@ -28,7 +28,7 @@ func (c *Core) ModifyHolding(req ModifyHoldingRequest) (int64, error) {
 		return 0, errors.New("target_avg_cost cannot be negative")
 	}
 	if req.TransactionDate == "" {
-		req.TransactionDate = todayISO()
+		req.TransactionDate = c.TodayISO()
 	}
 
 	normalizedSymbol := normalizeSymbol(req.Symbol)
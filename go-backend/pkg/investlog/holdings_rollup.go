@@ -0,0 +1,96 @@
+package investlog
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// HoldingsRollupCurrency is one currency's symbol totals and their value in
+// the rollup base currency. Converted fields are nil when no rate exists.
+type HoldingsRollupCurrency struct {
+	Currency             string   `json:"currency"`
+	TotalCost            Amount   `json:"total_cost"`
+	TotalMarketValue     Amount   `json:"total_market_value"`
+	TotalPnL             Amount   `json:"total_pnl"`
+	Rate                 *float64 `json:"rate"`
+	ConvertedCost        *Amount  `json:"converted_cost"`
+	ConvertedMarketValue *Amount  `json:"converted_market_value"`
+	ConvertedPnL         *Amount  `json:"converted_pnl"`
+	Error                string   `json:"error,omitempty"`
+}
+
+// HoldingsRollup combines per-currency symbol holdings into one base
+// currency. Currencies without a rate are listed in Unconverted and
+// MissingRates, whose Amount is the unconverted market value, and left out
+// of the totals.
+type HoldingsRollup struct {
+	BaseCurrency     string                   `json:"base_currency"`
+	TotalCost        Amount                   `json:"total_cost"`
+	TotalMarketValue Amount                   `json:"total_market_value"`
+	TotalPnL         Amount                   `json:"total_pnl"`
+	PnlPercent       *float64                 `json:"pnl_percent"`
+	Currencies       []HoldingsRollupCurrency `json:"currencies"`
+	Unconverted      []string                 `json:"unconverted_currencies"`
+	MissingRates     []MissingRate            `json:"missing_rates"`
+}
+
+// RollupHoldingsBySymbol converts each currency bucket of result into
+// baseCurrency using the maintained exchange rates and sums cost, market
+// value and P&L.
+func (c *Core) RollupHoldingsBySymbol(result HoldingsBySymbolResult, baseCurrency string) (*HoldingsRollup, error) {
+	base := normalizeCurrency(baseCurrency)
+	if !contains(Currencies, base) {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid base currency: %s", baseCurrency))
+	}
+
+	rollup := &HoldingsRollup{
+		BaseCurrency: base,
+		Currencies:   []HoldingsRollupCurrency{},
+		Unconverted:  []string{},
+		MissingRates: []MissingRate{},
+	}
+	for _, currency := range Currencies {
+		data, ok := result[currency]
+		if !ok {
+			continue
+		}
+		entry := HoldingsRollupCurrency{
+			Currency:         currency,
+			TotalCost:        data.TotalCost,
+			TotalMarketValue: data.TotalMarketValue,
+			TotalPnL:         data.TotalPnL,
+		}
+		rate, err := c.GetExchangeRate(currency, base)
+		if err != nil {
+			c.Logger().Warn("holdings rollup conversion skipped", "currency", currency, "base", base, "err", err)
+			entry.Error = err.Error()
+			rollup.Unconverted = append(rollup.Unconverted, currency)
+			rollup.MissingRates = append(rollup.MissingRates, MissingRate{
+				Currency:     currency,
+				BaseCurrency: base,
+				Amount:       data.TotalMarketValue,
+				Error:        err.Error(),
+			})
+			rollup.Currencies = append(rollup.Currencies, entry)
+			continue
+		}
+		r := decimal.NewFromFloat(rate)
+		cost := Amount{data.TotalCost.Mul(r)}
+		marketValue := Amount{data.TotalMarketValue.Mul(r)}
+		pnl := Amount{data.TotalPnL.Mul(r)}
+		entry.Rate = &rate
+		entry.ConvertedCost = &cost
+		entry.ConvertedMarketValue = &marketValue
+		entry.ConvertedPnL = &pnl
+		rollup.TotalCost = Amount{rollup.TotalCost.Add(cost.Decimal)}
+		rollup.TotalMarketValue = Amount{rollup.TotalMarketValue.Add(marketValue.Decimal)}
+		rollup.TotalPnL = Amount{rollup.TotalPnL.Add(pnl.Decimal)}
+		rollup.Currencies = append(rollup.Currencies, entry)
+	}
+	if rollup.TotalCost.IsPositive() {
+		pct := round2(rollup.TotalPnL.Div(rollup.TotalCost.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64())
+		rollup.PnlPercent = &pct
+	}
+	return rollup, nil
+}
@@ -0,0 +1,69 @@
+package investlog
+
+import "testing"
+
+func TestRollupHoldingsBySymbol(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	setupNetWorthHoldings(t, core)
+	assertNoError(t, core.UpdateLatestPrice("AAPL", "USD", NewAmountFromInt(120)), "UpdateLatestPrice AAPL")
+	assertNoError(t, core.UpdateLatestPrice("00700", "HKD", NewAmountFromInt(40)), "UpdateLatestPrice 00700")
+
+	bySymbol, err := core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol")
+	rollup, err := core.RollupHoldingsBySymbol(bySymbol, "cny")
+	assertNoError(t, err, "RollupHoldingsBySymbol")
+
+	if rollup.BaseCurrency != "CNY" || len(rollup.Currencies) != 3 || len(rollup.Unconverted) != 0 {
+		t.Fatalf("unexpected rollup: %+v", rollup)
+	}
+	var wantValue, wantPnL float64
+	for _, entry := range rollup.Currencies {
+		local := bySymbol[entry.Currency]
+		wantValue += local.TotalMarketValue.InexactFloat64() * *entry.Rate
+		wantPnL += local.TotalPnL.InexactFloat64() * *entry.Rate
+	}
+	if !floatEquals(rollup.TotalMarketValue.InexactFloat64(), wantValue, 0.01) || !floatEquals(rollup.TotalPnL.InexactFloat64(), wantPnL, 0.01) {
+		t.Fatalf("expected value %.2f pnl %.2f, got %s %s", wantValue, wantPnL, rollup.TotalMarketValue, rollup.TotalPnL)
+	}
+	// 600519 at cost 10000, AAPL 1200 USD * 7, 00700 4000 HKD * 0.9.
+	if !floatEquals(wantValue, 10000+8400+3600, 0.01) || !floatEquals(wantPnL, 1400-900, 0.01) {
+		t.Fatalf("unexpected converted sums: value %.2f pnl %.2f", wantValue, wantPnL)
+	}
+	if rollup.PnlPercent == nil {
+		t.Fatal("expected pnl percent")
+	}
+
+	if _, err := core.RollupHoldingsBySymbol(bySymbol, "EUR"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid base currency error, got %v", err)
+	}
+}
+
+func TestRollupHoldingsBySymbolReportsMissingRates(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	setupNetWorthHoldings(t, core)
+	if _, err := core.db.Exec("DELETE FROM exchange_rates WHERE from_currency = 'HKD'"); err != nil {
+		t.Fatalf("delete HKD rate: %v", err)
+	}
+
+	bySymbol, err := core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol")
+	rollup, err := core.RollupHoldingsBySymbol(bySymbol, "CNY")
+	assertNoError(t, err, "RollupHoldingsBySymbol")
+
+	if len(rollup.Unconverted) != 1 || rollup.Unconverted[0] != "HKD" {
+		t.Fatalf("expected HKD unconverted, got %v", rollup.Unconverted)
+	}
+	if !floatEquals(rollup.TotalCost.InexactFloat64(), 17000, 0.01) {
+		t.Fatalf("expected cost without HKD 17000, got %s", rollup.TotalCost)
+	}
+	hkd := rollup.Currencies[2]
+	if hkd.Currency != "HKD" || hkd.Rate != nil || hkd.ConvertedMarketValue != nil || hkd.Error == "" {
+		t.Fatalf("expected HKD entry with error and no conversion, got %+v", hkd)
+	}
+	if len(rollup.MissingRates) != 1 || rollup.MissingRates[0].Currency != "HKD" ||
+		!floatEquals(rollup.MissingRates[0].Amount.InexactFloat64(), hkd.TotalMarketValue.InexactFloat64(), 0.01) {
+		t.Fatalf("expected HKD market value reported as missing rate, got %+v", rollup.MissingRates)
+	}
+}
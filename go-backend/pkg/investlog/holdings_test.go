@@ -1,8 +1,12 @@
 package investlog
 
 import (
+	"net/http"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestGetHoldings_Basic(t *testing.T) {
@@ -278,6 +282,141 @@ func TestGetHoldingsBySymbol(t *testing.T) {
 	}
 }
 
+func TestGetHoldingsBySymbol_FlagsMissingPrice(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "test-account", "Test Account")
+	testBuyTransaction(t, core, "AAPL", 10, 150, "USD", "test-account")
+
+	result, err := core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol")
+	usd := result["USD"]
+	if usd.PriceMissingCount != 1 || len(usd.Symbols) != 1 || !usd.Symbols[0].PriceMissing {
+		t.Fatalf("expected unpriced AAPL to be flagged, got %+v", usd)
+	}
+	if !usd.Symbols[0].MarketValue.Equal(usd.Symbols[0].CostBasis.Decimal) {
+		t.Fatalf("expected unpriced holding valued at cost, got %v", usd.Symbols[0].MarketValue)
+	}
+
+	assertNoError(t, core.ManualUpdatePrice("AAPL", "USD", NewAmount(160)), "ManualUpdatePrice")
+	result, err = core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol after price")
+	usd = result["USD"]
+	if usd.PriceMissingCount != 0 || usd.Symbols[0].PriceMissing {
+		t.Fatalf("expected priced AAPL not to be flagged, got %+v", usd)
+	}
+}
+
+func TestGetHoldingsBySymbol_RefreshesMissingPricesInBackground(t *testing.T) {
+	core, err := OpenWithOptions(Options{
+		DBPath:                 filepath.Join(t.TempDir(), "missing-prices.db"),
+		MissingPriceFetchLimit: 1,
+	})
+	assertNoError(t, err, "open core")
+	defer core.Close()
+
+	// Seed the fetcher cache so background fetches never hit the network.
+	now := time.Now()
+	core.price.cache[cacheKey("AAPL", "USD", "stock")] = cacheEntry{price: 200, source: "Test", ts: now}
+	core.price.cache[cacheKey("MSFT", "USD", "stock")] = cacheEntry{price: 400, source: "Test", ts: now}
+
+	testAccount(t, core, "test-account", "Test Account")
+	testBuyTransaction(t, core, "AAPL", 10, 150, "USD", "test-account")
+	testBuyTransaction(t, core, "MSFT", 5, 300, "USD", "test-account")
+
+	// The read serves what is stored and leaves the fetch to the background.
+	result, err := core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol")
+	if missing := result["USD"].PriceMissingCount; missing != 2 {
+		t.Fatalf("expected both holdings flagged on the first read, got %d missing", missing)
+	}
+	core.missingPriceRefresh.Wait()
+
+	result, err = core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol after refresh")
+	usd := result["USD"]
+	if usd.PriceMissingCount != 1 {
+		t.Fatalf("expected exactly one fetched price with limit 1, got %d missing", usd.PriceMissingCount)
+	}
+	for _, s := range usd.Symbols {
+		if !s.PriceMissing && s.LatestPrice == nil {
+			t.Fatalf("expected fetched latest price for %s", s.Symbol)
+		}
+	}
+	core.missingPriceRefresh.Wait()
+
+	prices, err := core.GetAllLatestPrices()
+	assertNoError(t, err, "GetAllLatestPrices")
+	if len(prices) != 2 {
+		t.Fatalf("expected the second read to fetch the remaining price, got %d stored", len(prices))
+	}
+}
+
+// blockingHTTPClient holds the first request until release is closed and
+// records every requested URL.
+type blockingHTTPClient struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	urls    []string
+}
+
+func (b *blockingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	b.urls = append(b.urls, req.URL.String())
+	b.mu.Unlock()
+	b.once.Do(func() {
+		close(b.started)
+		<-b.release
+	})
+	return (&mockHTTPClient{status: http.StatusServiceUnavailable}).Do(req)
+}
+
+func TestClose_WaitsForMissingPriceRefresh(t *testing.T) {
+	core, err := OpenWithOptions(Options{
+		DBPath:                 filepath.Join(t.TempDir(), "close-refresh.db"),
+		MissingPriceFetchLimit: 2,
+	})
+	assertNoError(t, err, "open core")
+
+	client := &blockingHTTPClient{started: make(chan struct{}), release: make(chan struct{})}
+	core.price = newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Second,
+		FailThreshold: 100,
+		FailWindow:    time.Second,
+		Cooldown:      time.Second,
+		HTTPTimeout:   time.Second,
+		HTTPClient:    client,
+	})
+	testAccount(t, core, "test-account", "Test Account")
+	testBuyTransaction(t, core, "AAPL", 10, 150, "USD", "test-account")
+	testBuyTransaction(t, core, "MSFT", 5, 300, "USD", "test-account")
+
+	_, err = core.GetHoldingsBySymbol()
+	assertNoError(t, err, "GetHoldingsBySymbol")
+	<-client.started
+
+	closed := make(chan error, 1)
+	go func() { closed <- core.Close() }()
+	select {
+	case <-closed:
+		t.Fatal("expected Close to wait for the running refresh")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(client.release)
+	assertNoError(t, <-closed, "close core")
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	for _, url := range client.urls {
+		if strings.Contains(url, "MSFT") {
+			t.Fatalf("expected the refresh to stop before the next symbol, fetched %s", url)
+		}
+	}
+}
+
 func TestGetHoldingsByCurrency(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -0,0 +1,114 @@
+package investlog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTopMovers = 5
+	maxTopMovers     = 50
+)
+
+// SymbolMover is one held symbol's move since the previous close.
+type SymbolMover struct {
+	Symbol       string  `json:"symbol"`
+	DisplayName  string  `json:"display_name"`
+	DayChangePct float64 `json:"day_change_pct"`
+	LatestPrice  *Amount `json:"latest_price"`
+	// MarketValue sums the symbol's holdings across accounts.
+	MarketValue Amount `json:"market_value"`
+}
+
+// TopMovers splits a currency's biggest daily movers into gainers and losers.
+type TopMovers struct {
+	Currency string        `json:"currency"`
+	Gainers  []SymbolMover `json:"gainers"`
+	Losers   []SymbolMover `json:"losers"`
+	// NoDayChange lists held symbols whose price source gave no previous
+	// close, so they could not be ranked.
+	NoDayChange []string `json:"no_day_change"`
+}
+
+// GetTopMovers returns the n held symbols in currency with the largest
+// absolute day change, gainers and losers each ordered from the biggest
+// move. Ties go to the larger position, then the symbol name. Unchanged
+// symbols are neither gainers nor losers. n defaults to 5 and is capped at 50.
+func (c *Core) GetTopMovers(currency string, n int) (TopMovers, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !isValidCurrency(currency) {
+		return TopMovers{}, NewValidationError("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+	if n <= 0 {
+		n = defaultTopMovers
+	}
+	if n > maxTopMovers {
+		n = maxTopMovers
+	}
+
+	bySymbol, err := c.GetHoldingsBySymbol()
+	if err != nil {
+		return TopMovers{}, err
+	}
+	result := TopMovers{Currency: currency, Gainers: []SymbolMover{}, Losers: []SymbolMover{}, NoDayChange: []string{}}
+
+	movers := map[string]*SymbolMover{}
+	missing := map[string]struct{}{}
+	for _, h := range bySymbol[currency].Symbols {
+		if strings.EqualFold(h.AssetType, "cash") || !h.TotalShares.IsPositive() {
+			continue
+		}
+		if h.DayChangePct == nil {
+			missing[h.Symbol] = struct{}{}
+			continue
+		}
+		if m, ok := movers[h.Symbol]; ok {
+			m.MarketValue = Amount{m.MarketValue.Add(h.MarketValue.Decimal)}
+			continue
+		}
+		movers[h.Symbol] = &SymbolMover{
+			Symbol:       h.Symbol,
+			DisplayName:  h.DisplayName,
+			DayChangePct: *h.DayChangePct,
+			LatestPrice:  h.LatestPrice,
+			MarketValue:  h.MarketValue,
+		}
+	}
+
+	ranked := make([]SymbolMover, 0, len(movers))
+	for _, m := range movers {
+		if m.DayChangePct != 0 {
+			ranked = append(ranked, *m)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := math.Abs(ranked[i].DayChangePct), math.Abs(ranked[j].DayChangePct)
+		if a != b {
+			return a > b
+		}
+		if !ranked[i].MarketValue.Equal(ranked[j].MarketValue.Decimal) {
+			return ranked[i].MarketValue.GreaterThan(ranked[j].MarketValue.Decimal)
+		}
+		return ranked[i].Symbol < ranked[j].Symbol
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	for _, m := range ranked {
+		if m.DayChangePct > 0 {
+			result.Gainers = append(result.Gainers, m)
+		} else {
+			result.Losers = append(result.Losers, m)
+		}
+	}
+
+	for symbol := range missing {
+		if _, ok := movers[symbol]; !ok {
+			result.NoDayChange = append(result.NoDayChange, symbol)
+		}
+	}
+	sort.Strings(result.NoDayChange)
+	return result, nil
+}
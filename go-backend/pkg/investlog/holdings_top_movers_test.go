@@ -0,0 +1,85 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func seedQuote(t *testing.T, core *Core, symbol string, price, previousClose int64) {
+	t.Helper()
+	prev := NewAmountFromInt(previousClose)
+	if err := core.updateLatestQuote(symbol, "USD", NewAmountFromInt(price), &prev); err != nil {
+		t.Fatalf("updateLatestQuote %s: %v", symbol, err)
+	}
+}
+
+func moverSymbols(movers []SymbolMover) []string {
+	out := make([]string, 0, len(movers))
+	for _, m := range movers {
+		out = append(out, m.Symbol)
+	}
+	return out
+}
+
+func TestGetTopMovers_OrdersAndSplits(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct1", "Account 1")
+	testAccount(t, core, "acct2", "Account 2")
+	testBuyTransaction(t, core, "AAA", 10, 100, "USD", "acct1") // +10%
+	testBuyTransaction(t, core, "BBB", 10, 100, "USD", "acct1") // -20%
+	testBuyTransaction(t, core, "CCC", 10, 100, "USD", "acct1") // +5%, tie with DDD
+	testBuyTransaction(t, core, "DDD", 20, 100, "USD", "acct1") // -5%, larger position
+	testBuyTransaction(t, core, "DDD", 5, 100, "USD", "acct2")
+	testBuyTransaction(t, core, "EEE", 10, 100, "USD", "acct1") // flat
+	testBuyTransaction(t, core, "FFF", 10, 100, "USD", "acct1") // no previous close
+
+	seedQuote(t, core, "AAA", 110, 100)
+	seedQuote(t, core, "BBB", 80, 100)
+	seedQuote(t, core, "CCC", 105, 100)
+	seedQuote(t, core, "DDD", 95, 100)
+	seedQuote(t, core, "EEE", 100, 100)
+	assertNoError(t, core.ManualUpdatePrice("FFF", "USD", NewAmountFromInt(101)), "manual price")
+
+	result, err := core.GetTopMovers("usd", 10)
+	assertNoError(t, err, "GetTopMovers")
+	if result.Currency != "USD" {
+		t.Fatalf("currency = %q, want USD", result.Currency)
+	}
+	if got := moverSymbols(result.Gainers); len(got) != 2 || got[0] != "AAA" || got[1] != "CCC" {
+		t.Fatalf("gainers = %v, want [AAA CCC]", got)
+	}
+	if got := moverSymbols(result.Losers); len(got) != 2 || got[0] != "BBB" || got[1] != "DDD" {
+		t.Fatalf("losers = %v, want [BBB DDD]", got)
+	}
+	if len(result.NoDayChange) != 1 || result.NoDayChange[0] != "FFF" {
+		t.Fatalf("no_day_change = %v, want [FFF]", result.NoDayChange)
+	}
+	ddd := result.Losers[1]
+	assertFloatEquals(t, ddd.DayChangePct, -5, "DDD day change")
+	if ddd.MarketValue.InexactFloat64() != 2375 {
+		t.Fatalf("DDD market value = %s, want 2375 across accounts", ddd.MarketValue.String())
+	}
+
+	// The 5% tie is broken by position size, so DDD outranks CCC.
+	result, err = core.GetTopMovers("USD", 3)
+	assertNoError(t, err, "GetTopMovers n=3")
+	if got := moverSymbols(result.Gainers); len(got) != 1 || got[0] != "AAA" {
+		t.Fatalf("top 3 gainers = %v, want [AAA]", got)
+	}
+	if got := moverSymbols(result.Losers); len(got) != 2 || got[0] != "BBB" || got[1] != "DDD" {
+		t.Fatalf("top 3 losers = %v, want [BBB DDD]", got)
+	}
+}
+
+func TestGetTopMovers_InvalidCurrency(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := core.GetTopMovers("XYZ", 5)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
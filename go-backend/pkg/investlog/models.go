@@ -63,7 +63,23 @@ type AddTransactionRequest struct {
 	Notes           *string
 	Tags            *string
 	TotalAmount     *Amount
-	LinkCash        bool
+	// LinkCash records the matching CASH movement in the same account and
+	// currency for a BUY, SELL or DIVIDEND. Deleting the transaction also
+	// deletes its cash entry.
+	LinkCash bool
+	// UseDefaultCommission applies the account's default commission to a
+	// BUY or SELL whose Commission is zero. Leave it false to record an
+	// explicit zero commission.
+	UseDefaultCommission bool
+	// CheckDuplicate rejects the transaction with a DuplicateTransactionError
+	// when an identical one already exists within a few days of it.
+	CheckDuplicate bool
+	// CheckTotalAmount rejects a BUY or SELL whose TotalAmount disagrees
+	// with Quantity*Price. TotalAmount may be the gross trade value or
+	// include the commission: Quantity*Price+Commission for a BUY (cost) and
+	// Quantity*Price-Commission for a SELL (net proceeds). Commission itself
+	// is always non-negative.
+	CheckTotalAmount bool
 }
 
 // TransferRequest defines inputs for a cross-account transfer.
@@ -80,11 +96,16 @@ type TransferRequest struct {
 	Notes           *string
 }
 
-// TransferResult returns the IDs of the paired transactions.
+// TransferResult returns the IDs of the paired transactions. OutTotalAmount
+// is in the source currency; InQuantity and InTotalAmount are in the
+// destination currency after conversion at ExchangeRate.
 type TransferResult struct {
-	TransferOutID int64  `json:"transfer_out_id"`
-	TransferInID  int64  `json:"transfer_in_id"`
-	ExchangeRate  Amount `json:"exchange_rate,omitempty"`
+	TransferOutID  int64  `json:"transfer_out_id"`
+	TransferInID   int64  `json:"transfer_in_id"`
+	ExchangeRate   Amount `json:"exchange_rate,omitempty"`
+	OutTotalAmount Amount `json:"out_total_amount"`
+	InQuantity     Amount `json:"in_quantity"`
+	InTotalAmount  Amount `json:"in_total_amount"`
 }
 
 // ModifyHoldingRequest defines inputs for modifying an existing holding.
@@ -134,6 +155,35 @@ type CurrencyAllocation struct {
 // HoldingsByCurrencyResult maps currency to allocation data.
 type HoldingsByCurrencyResult map[string]CurrencyAllocation
 
+// NetWorthCurrency is one currency's contribution to net worth.
+type NetWorthCurrency struct {
+	Currency  string   `json:"currency"`
+	Total     Amount   `json:"total"`
+	Rate      *float64 `json:"rate"`
+	Converted *Amount  `json:"converted"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// MissingRate is the part of a multi-currency rollup left out of its totals
+// because no exchange rate to the base currency exists. Amount is in
+// Currency, not the base currency.
+type MissingRate struct {
+	Currency     string `json:"currency"`
+	BaseCurrency string `json:"base_currency"`
+	Amount       Amount `json:"amount"`
+	Error        string `json:"error"`
+}
+
+// NetWorth is the total of all holdings converted to a base currency.
+// Currencies without a rate are reported in MissingRates.
+type NetWorth struct {
+	BaseCurrency string             `json:"base_currency"`
+	Total        Amount             `json:"total"`
+	Currencies   []NetWorthCurrency `json:"currencies"`
+	Unconverted  []string           `json:"unconverted_currencies"`
+	MissingRates []MissingRate      `json:"missing_rates"`
+}
+
 // SymbolHolding represents per-symbol holding details.
 type SymbolHolding struct {
 	Symbol         string   `json:"symbol"`
@@ -142,6 +192,7 @@ type SymbolHolding struct {
 	AssetType      string   `json:"asset_type"`
 	AssetTypeLabel string   `json:"asset_type_label"`
 	AutoUpdate     int      `json:"auto_update"`
+	Inactive       bool     `json:"inactive,omitempty"`
 	AccountID      string   `json:"account_id"`
 	AccountName    string   `json:"account_name"`
 	TotalShares    Amount   `json:"total_shares"`
@@ -152,7 +203,12 @@ type SymbolHolding struct {
 	MarketValue    Amount   `json:"market_value"`
 	UnrealizedPnL  *Amount  `json:"unrealized_pnl"`
 	PnlPercent     *float64 `json:"pnl_percent"`
-	Percent        float64  `json:"percent"`
+	// DayChangePct is the latest price's change from the previous close, in
+	// percent; omitted when the price source gave no previous close.
+	DayChangePct *float64 `json:"day_change_pct,omitempty"`
+	Percent      float64  `json:"percent"`
+	// PriceMissing is set when no latest price exists and MarketValue falls back to cost.
+	PriceMissing bool `json:"price_missing"`
 }
 
 // SymbolHoldingsByAccount groups symbols by account for chart legend.
@@ -168,6 +224,8 @@ type SymbolHoldingsCurrency struct {
 	TotalPnL         Amount                             `json:"total_pnl"`
 	Symbols          []SymbolHolding                    `json:"symbols"`
 	ByAccount        map[string]SymbolHoldingsByAccount `json:"by_account"`
+	// PriceMissingCount is the number of symbols valued at cost for lack of a price.
+	PriceMissingCount int `json:"price_missing_count"`
 }
 
 // HoldingsBySymbolResult maps currency to symbol holdings.
@@ -230,6 +288,9 @@ type AISettings struct {
 	AllowNewSymbols bool   `json:"allow_new_symbols"`
 	StrategyPrompt  string `json:"strategy_prompt"`
 	APIKey          string `json:"api_key"`
+	// TheoryTags is the theory_tag vocabulary for holdings recommendations;
+	// the first tag is the default. Empty falls back to Malkiel/Dalio/Buffett.
+	TheoryTags []string `json:"theory_tags"`
 }
 
 // AssetType represents a dynamic asset type.
@@ -246,7 +307,10 @@ type Account struct {
 	AccountName string  `json:"account_name"`
 	Broker      *string `json:"broker"`
 	AccountType *string `json:"account_type"`
-	CreatedAt   *string `json:"created_at"`
+	// DefaultCommission is applied to trades that opt in via
+	// AddTransactionRequest.UseDefaultCommission. Nil means no default.
+	DefaultCommission *Amount `json:"default_commission"`
+	CreatedAt         *string `json:"created_at"`
 }
 
 // Symbol represents symbol metadata.
@@ -258,6 +322,9 @@ type Symbol struct {
 	Sector     *string `json:"sector"`
 	Exchange   *string `json:"exchange"`
 	AutoUpdate int     `json:"auto_update"`
+	// Inactive marks a delisted symbol: its prices are no longer fetched,
+	// while its transactions and holdings are kept.
+	Inactive bool `json:"inactive"`
 }
 
 // LatestPrice represents the last fetched price for a symbol.
@@ -266,6 +333,9 @@ type LatestPrice struct {
 	Currency  string `json:"currency"`
 	Price     Amount `json:"price"`
 	UpdatedAt string `json:"updated_at"`
+	// PreviousClose is the prior session's close reported with Price; nil for
+	// manual prices and sources that do not provide it.
+	PreviousClose *Amount `json:"previous_close,omitempty"`
 }
 
 // OperationLog represents an audit log record.
@@ -289,11 +359,7 @@ type PortfolioPoint struct {
 
 // PriceResult represents a fetch price result.
 type PriceResult struct {
-	Price   *Amount `json:"price"`
-	Message string  `json:"message"`
-}
-
-// Time helpers.
-func todayISO() string {
-	return TodayISOInShanghai()
+	Price         *Amount `json:"price"`
+	PreviousClose *Amount `json:"previous_close,omitempty"`
+	Message       string  `json:"message"`
 }
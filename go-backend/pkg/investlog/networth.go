@@ -0,0 +1,62 @@
+package investlog
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// GetNetWorth sums market value plus cash across all currencies and converts
+// the result to baseCurrency (the configured default when empty) using the maintained exchange
+// rates. Currencies without a usable rate are listed in Unconverted and
+// MissingRates and left out of Total instead of failing the whole request.
+func (c *Core) GetNetWorth(baseCurrency string) (*NetWorth, error) {
+	base, err := c.resolveBaseCurrency(baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(Currencies, base) {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid base currency: %s", baseCurrency))
+	}
+
+	byCurrency, err := c.GetHoldingsByCurrency()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NetWorth{
+		BaseCurrency: base,
+		Currencies:   []NetWorthCurrency{},
+		Unconverted:  []string{},
+		MissingRates: []MissingRate{},
+	}
+	for _, currency := range Currencies {
+		data, ok := byCurrency[currency]
+		if !ok {
+			continue
+		}
+		entry := NetWorthCurrency{
+			Currency: currency,
+			Total:    data.Total,
+		}
+		rate, err := c.GetExchangeRate(currency, base)
+		if err != nil {
+			c.Logger().Warn("net worth conversion skipped", "currency", currency, "base", base, "err", err)
+			entry.Error = err.Error()
+			result.Unconverted = append(result.Unconverted, currency)
+			result.MissingRates = append(result.MissingRates, MissingRate{
+				Currency:     currency,
+				BaseCurrency: base,
+				Amount:       data.Total,
+				Error:        err.Error(),
+			})
+		} else {
+			converted := Amount{data.Total.Mul(decimal.NewFromFloat(rate))}
+			entry.Rate = &rate
+			entry.Converted = &converted
+			result.Total = Amount{result.Total.Add(converted.Decimal)}
+		}
+		result.Currencies = append(result.Currencies, entry)
+	}
+	return result, nil
+}
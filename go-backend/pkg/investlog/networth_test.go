@@ -0,0 +1,85 @@
+package investlog
+
+import "testing"
+
+func setupNetWorthHoldings(t *testing.T, core *Core) {
+	t.Helper()
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "600519", 1, 10000, "CNY", "acc-1")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	testBuyTransaction(t, core, "00700", 100, 50, "HKD", "acc-1")
+	if _, err := core.SetExchangeRate("USD", "CNY", 7, "manual"); err != nil {
+		t.Fatalf("SetExchangeRate USD failed: %v", err)
+	}
+	if _, err := core.SetExchangeRate("HKD", "CNY", 0.9, "manual"); err != nil {
+		t.Fatalf("SetExchangeRate HKD failed: %v", err)
+	}
+}
+
+func TestGetNetWorth(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	setupNetWorthHoldings(t, core)
+
+	tests := []struct {
+		base string
+		want float64
+	}{
+		{base: "", want: 21500},
+		{base: "CNY", want: 21500},
+		{base: "usd", want: 21500.0 / 7},
+		{base: "HKD", want: 21500.0 / 0.9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			result, err := core.GetNetWorth(tt.base)
+			assertNoError(t, err, "GetNetWorth")
+			if !floatEquals(result.Total.InexactFloat64(), tt.want, 0.01) {
+				t.Fatalf("expected total %.2f, got %s", tt.want, result.Total.String())
+			}
+			if len(result.Currencies) != 3 || len(result.Unconverted) != 0 {
+				t.Fatalf("expected 3 converted currencies, got %+v", result)
+			}
+			if result.Currencies[0].Currency != "CNY" || result.Currencies[1].Currency != "USD" || result.Currencies[2].Currency != "HKD" {
+				t.Fatalf("unexpected currency order: %+v", result.Currencies)
+			}
+		})
+	}
+
+	if _, err := core.GetNetWorth("EUR"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid base currency error, got %v", err)
+	}
+}
+
+func TestGetNetWorthReportsMissingRates(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	setupNetWorthHoldings(t, core)
+
+	if _, err := core.db.Exec("DELETE FROM exchange_rates WHERE from_currency = 'HKD'"); err != nil {
+		t.Fatalf("delete HKD rate: %v", err)
+	}
+
+	result, err := core.GetNetWorth("CNY")
+	assertNoError(t, err, "GetNetWorth")
+	if len(result.Unconverted) != 1 || result.Unconverted[0] != "HKD" {
+		t.Fatalf("expected HKD unconverted, got %v", result.Unconverted)
+	}
+	if !floatEquals(result.Total.InexactFloat64(), 17000, 0.01) {
+		t.Fatalf("expected total without HKD 17000, got %s", result.Total.String())
+	}
+	hkd := result.Currencies[2]
+	if hkd.Currency != "HKD" || hkd.Converted != nil || hkd.Rate != nil || hkd.Error == "" {
+		t.Fatalf("expected HKD entry with error and no conversion, got %+v", hkd)
+	}
+	if !floatEquals(hkd.Total.InexactFloat64(), 5000, 0.01) {
+		t.Fatalf("expected HKD local total 5000, got %s", hkd.Total.String())
+	}
+	if len(result.MissingRates) != 1 {
+		t.Fatalf("expected one missing rate, got %+v", result.MissingRates)
+	}
+	missing := result.MissingRates[0]
+	if missing.Currency != "HKD" || missing.BaseCurrency != "CNY" || missing.Error == "" || !floatEquals(missing.Amount.InexactFloat64(), 5000, 0.01) {
+		t.Fatalf("expected HKD 5000 reported as missing rate, got %+v", missing)
+	}
+}
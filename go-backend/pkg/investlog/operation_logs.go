@@ -1,6 +1,27 @@
 package investlog
 
-import "database/sql"
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Operation types recorded for completed AI analyses.
+const (
+	OperationAIHoldingsAnalysis = "ai_holdings_analysis"
+	OperationAISymbolAnalysis   = "ai_symbol_analysis"
+)
+
+// OperationLogFilter narrows GetOperationLogs and GetOperationLogCount.
+// StartDate and EndDate are inclusive YYYY-MM-DD bounds on created_at.
+type OperationLogFilter struct {
+	OperationType string
+	Symbol        string
+	StartDate     string
+	EndDate       string
+	Limit         int
+	Offset        int
+}
 
 // AddOperationLog adds a new operation log entry.
 func (c *Core) AddOperationLog(log OperationLog) (int64, error) {
@@ -14,17 +35,57 @@ func (c *Core) AddOperationLog(log OperationLog) (int64, error) {
 	return result.LastInsertId()
 }
 
-// GetOperationLogs returns recent operation logs.
-func (c *Core) GetOperationLogs(limit, offset int) ([]OperationLog, error) {
+// operationLogWhere builds the WHERE clause shared by list and count queries.
+func operationLogWhere(filter OperationLogFilter) (string, []any, error) {
+	query := strings.Builder{}
+	query.WriteString(" WHERE 1=1")
+	params := []any{}
+
+	if filter.OperationType != "" {
+		query.WriteString(" AND operation_type = ?")
+		params = append(params, strings.TrimSpace(filter.OperationType))
+	}
+	if filter.Symbol != "" {
+		query.WriteString(" AND symbol = ?")
+		params = append(params, normalizeSymbol(filter.Symbol))
+	}
+	if filter.StartDate != "" {
+		if _, err := time.Parse("2006-01-02", filter.StartDate); err != nil {
+			return "", nil, NewError(ErrCodeInvalidInput, "invalid start_date, expected YYYY-MM-DD")
+		}
+		query.WriteString(" AND created_at >= ?")
+		params = append(params, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", filter.EndDate); err != nil {
+			return "", nil, NewError(ErrCodeInvalidInput, "invalid end_date, expected YYYY-MM-DD")
+		}
+		// created_at carries a time component, so compare against the next day.
+		query.WriteString(" AND created_at < date(?, '+1 day')")
+		params = append(params, filter.EndDate)
+	}
+	return query.String(), params, nil
+}
+
+// GetOperationLogs returns operation logs matching filter, newest first.
+func (c *Core) GetOperationLogs(filter OperationLogFilter) ([]OperationLog, error) {
+	limit := filter.Limit
 	if limit <= 0 {
 		limit = 50
 	}
+	offset := filter.Offset
 	if offset < 0 {
 		offset = 0
 	}
+	where, params, err := operationLogWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	params = append(params, limit, offset)
 	rows, err := c.db.Query(
-		"SELECT id, operation_type, symbol, currency, details, old_value, new_value, price_fetched, created_at FROM operation_logs ORDER BY created_at DESC LIMIT ? OFFSET ?",
-		limit, offset,
+		"SELECT id, operation_type, symbol, currency, details, old_value, new_value, price_fetched, created_at FROM operation_logs"+
+			where+" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		params...,
 	)
 	if err != nil {
 		return nil, err
@@ -67,3 +128,17 @@ func (c *Core) GetOperationLogs(limit, offset int) ([]OperationLog, error) {
 	}
 	return logs, rows.Err()
 }
+
+// GetOperationLogCount returns the number of operation logs matching filter,
+// ignoring Limit and Offset.
+func (c *Core) GetOperationLogCount(filter OperationLogFilter) (int, error) {
+	where, params, err := operationLogWhere(filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := c.db.QueryRow("SELECT COUNT(*) FROM operation_logs"+where, params...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -28,7 +28,7 @@ func TestOperationLogs(t *testing.T) {
 		t.Fatalf("AddOperationLog (empty): %v", err)
 	}
 
-	logs, err := core.GetOperationLogs(0, -1)
+	logs, err := core.GetOperationLogs(OperationLogFilter{Offset: -1})
 	if err != nil {
 		t.Fatalf("GetOperationLogs: %v", err)
 	}
@@ -39,3 +39,86 @@ func TestOperationLogs(t *testing.T) {
 		t.Fatalf("expected operation in log")
 	}
 }
+
+func TestGetOperationLogsFilters(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	seed := []struct {
+		op, symbol, createdAt string
+	}{
+		{"PRICE_UPDATE", "AAPL", "2024-01-05 10:00:00"},
+		{"PRICE_UPDATE", "AAPL", "2024-01-10 09:00:00"},
+		{"PRICE_UPDATE", "MSFT", "2024-01-10 09:00:00"},
+		{"PRICE_UPDATE_FAILED", "AAPL", "2024-01-10 23:59:59"},
+		{"PRICE_UPDATE", "AAPL", "2024-02-01 08:00:00"},
+	}
+	for _, s := range seed {
+		if _, err := core.db.Exec(
+			"INSERT INTO operation_logs (operation_type, symbol, created_at) VALUES (?, ?, ?)",
+			s.op, s.symbol, s.createdAt,
+		); err != nil {
+			t.Fatalf("seed operation log: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter OperationLogFilter
+		want   int
+	}{
+		{name: "all", filter: OperationLogFilter{}, want: 5},
+		{name: "type", filter: OperationLogFilter{OperationType: "PRICE_UPDATE"}, want: 4},
+		{name: "symbol", filter: OperationLogFilter{Symbol: "aapl"}, want: 4},
+		{name: "inclusive end date", filter: OperationLogFilter{StartDate: "2024-01-10", EndDate: "2024-01-10"}, want: 3},
+		{name: "combined", filter: OperationLogFilter{OperationType: "PRICE_UPDATE", Symbol: "AAPL", StartDate: "2024-01-06"}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := core.GetOperationLogCount(tt.filter)
+			assertNoError(t, err, "GetOperationLogCount")
+			if count != tt.want {
+				t.Fatalf("expected count %d, got %d", tt.want, count)
+			}
+			logs, err := core.GetOperationLogs(tt.filter)
+			assertNoError(t, err, "GetOperationLogs")
+			if len(logs) != tt.want {
+				t.Fatalf("expected %d logs, got %d", tt.want, len(logs))
+			}
+		})
+	}
+
+	if _, err := core.GetOperationLogs(OperationLogFilter{StartDate: "2024/01/01"}); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid start_date error, got %v", err)
+	}
+}
+
+func TestGetOperationLogsStablePaging(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 5; i++ {
+		if _, err := core.db.Exec(
+			"INSERT INTO operation_logs (operation_type, created_at) VALUES ('PRICE_UPDATE', '2024-03-01 12:00:00')",
+		); err != nil {
+			t.Fatalf("seed operation log: %v", err)
+		}
+	}
+
+	var ids []int64
+	for offset := 0; offset < 5; offset += 2 {
+		page, err := core.GetOperationLogs(OperationLogFilter{Limit: 2, Offset: offset})
+		assertNoError(t, err, "GetOperationLogs page")
+		for _, log := range page {
+			ids = append(ids, log.ID)
+		}
+	}
+	if len(ids) != 5 {
+		t.Fatalf("expected 5 ids across pages, got %v", ids)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] >= ids[i-1] {
+			t.Fatalf("expected ids in descending order for equal timestamps, got %v", ids)
+		}
+	}
+}
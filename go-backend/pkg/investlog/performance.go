@@ -0,0 +1,225 @@
+package investlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AnnualPerformanceYear is one calendar year's realized results.
+type AnnualPerformanceYear struct {
+	Year            int     `json:"year"`
+	RealizedPnL     Amount  `json:"realized_pnl"`
+	Dividends       Amount  `json:"dividends"`
+	Total           Amount  `json:"total"`
+	CumulativeTotal Amount  `json:"cumulative_total"`
+	ChangeFromPrior *Amount `json:"change_from_prior"`
+}
+
+// AnnualPerformance lists realized P&L and dividends per calendar year for
+// one currency, oldest year first.
+type AnnualPerformance struct {
+	Currency string                  `json:"currency"`
+	Years    []AnnualPerformanceYear `json:"years"`
+}
+
+// performanceTransaction is the slice of a transaction the realized-gain
+// replay needs.
+type performanceTransaction struct {
+	id         int64
+	at         time.Time
+	symbol     string
+	accountID  string
+	txnType    string
+	quantity   decimal.Decimal
+	total      decimal.Decimal
+	commission decimal.Decimal
+	linked     bool
+}
+
+// realizedPosition tracks shares and cost basis of one symbol in one account.
+type realizedPosition struct {
+	shares decimal.Decimal
+	cost   decimal.Decimal
+}
+
+// apply updates the position the way GetHoldings aggregates cost and returns
+// the realized P&L of a SELL at average cost.
+func (p *realizedPosition) apply(t performanceTransaction) decimal.Decimal {
+	realized := decimal.Zero
+	switch t.txnType {
+	case "BUY", "INCOME":
+		p.shares = p.shares.Add(t.quantity)
+		p.cost = p.cost.Add(t.total).Add(t.commission)
+	case "SELL":
+		soldCost := decimal.Zero
+		if p.shares.IsPositive() {
+			soldCost = p.cost.Mul(t.quantity).Div(p.shares)
+		}
+		realized = t.total.Sub(t.commission).Sub(soldCost)
+		p.shares = p.shares.Sub(t.quantity)
+		p.cost = p.cost.Sub(soldCost)
+	case "ADJUST", "MODIFY":
+		p.shares = p.shares.Add(t.quantity)
+		p.cost = p.cost.Add(t.total)
+	case "SPLIT":
+		p.shares = p.shares.Add(t.quantity)
+	case "TRANSFER_IN":
+		p.shares = p.shares.Add(t.quantity)
+		if t.linked {
+			p.cost = p.cost.Add(t.total)
+		}
+	case "TRANSFER_OUT":
+		p.shares = p.shares.Sub(t.quantity)
+		if t.linked {
+			p.cost = p.cost.Sub(t.total)
+		}
+	}
+	if !p.shares.IsPositive() {
+		p.shares = decimal.Zero
+		p.cost = decimal.Zero
+	}
+	return realized
+}
+
+// GetAnnualPerformance buckets realized P&L (average cost) and dividends by
+// calendar year in the configured time zone for currency (the configured
+// default base currency when empty).
+// Cash positions are left out.
+func (c *Core) GetAnnualPerformance(currency string) (*AnnualPerformance, error) {
+	curr, err := c.resolveBaseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidCurrency(curr) {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid currency: %s", currency))
+	}
+
+	txns, err := c.performanceTransactions(curr)
+	if err != nil {
+		return nil, err
+	}
+
+	type yearTotals struct {
+		realized  decimal.Decimal
+		dividends decimal.Decimal
+	}
+	byYear := map[int]*yearTotals{}
+	positions := map[[2]string]*realizedPosition{}
+	for _, t := range txns {
+		year := t.at.Year()
+		totals, ok := byYear[year]
+		if !ok {
+			totals = &yearTotals{}
+			byYear[year] = totals
+		}
+		if t.txnType == "DIVIDEND" {
+			totals.dividends = totals.dividends.Add(t.total.Sub(t.commission))
+			continue
+		}
+		key := [2]string{t.symbol, t.accountID}
+		pos, ok := positions[key]
+		if !ok {
+			pos = &realizedPosition{}
+			positions[key] = pos
+		}
+		totals.realized = totals.realized.Add(pos.apply(t))
+	}
+
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	result := &AnnualPerformance{Currency: curr, Years: []AnnualPerformanceYear{}}
+	cumulative := decimal.Zero
+	var prior *decimal.Decimal
+	for _, year := range years {
+		totals := byYear[year]
+		total := totals.realized.Add(totals.dividends)
+		cumulative = cumulative.Add(total)
+		entry := AnnualPerformanceYear{
+			Year:            year,
+			RealizedPnL:     Amount{totals.realized},
+			Dividends:       Amount{totals.dividends},
+			Total:           Amount{total},
+			CumulativeTotal: Amount{cumulative},
+		}
+		if prior != nil {
+			entry.ChangeFromPrior = amountPtr(Amount{total.Sub(*prior)})
+		}
+		result.Years = append(result.Years, entry)
+		prior = &total
+	}
+	return result, nil
+}
+
+// performanceTransactions loads non-cash transactions in currency ordered by
+// their local time, then ID.
+func (c *Core) performanceTransactions(currency string) ([]performanceTransaction, error) {
+	defer c.trackQuery("performance.transactions")()
+	// CAST keeps the driver from turning DATE values into UTC timestamps.
+	rows, err := c.db.Query(`
+		SELECT t.id, CAST(t.transaction_date AS TEXT), s.symbol, t.account_id,
+			t.transaction_type, t.quantity, t.total_amount, COALESCE(t.commission, 0),
+			t.linked_transaction_id IS NOT NULL
+		FROM transactions t
+		JOIN symbols s ON s.id = t.symbol_id
+		WHERE t.currency = ? AND LOWER(s.asset_type) != 'cash'
+	`, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txns []performanceTransaction
+	for rows.Next() {
+		var t performanceTransaction
+		var date string
+		var quantity, total, commission Amount
+		if err := rows.Scan(&t.id, &date, &t.symbol, &t.accountID, &t.txnType, &quantity, &total, &commission, &t.linked); err != nil {
+			return nil, err
+		}
+		at, err := parseTransactionDate(date, c.Location())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", t.id, err)
+		}
+		t.at = at
+		t.quantity = quantity.Decimal
+		t.total = total.Decimal
+		t.commission = commission.Decimal
+		txns = append(txns, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(txns, func(i, j int) bool {
+		if !txns[i].at.Equal(txns[j].at) {
+			return txns[i].at.Before(txns[j].at)
+		}
+		return txns[i].id < txns[j].id
+	})
+	return txns, nil
+}
+
+// parseTransactionDate reads a stored transaction date. Plain dates and
+// zone-less timestamps are already local to loc; timestamps carrying an
+// offset are converted to loc so they land in the right calendar year.
+func parseTransactionDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05Z07:00"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid transaction date: %q", value)
+}
@@ -0,0 +1,93 @@
+package investlog
+
+import "testing"
+
+func TestParseTransactionDate(t *testing.T) {
+	cases := map[string]int{
+		"2023-12-31":                2023,
+		"2023-12-31 23:59:59":       2023,
+		"2023-12-31T16:30:00Z":      2024,
+		"2024-12-31T15:59:59Z":      2024,
+		"2024-12-31T16:00:00Z":      2025,
+		"2024-12-31T23:59:59+08:00": 2024,
+	}
+	for value, want := range cases {
+		got, err := parseTransactionDate(value, shanghaiLocation)
+		if err != nil {
+			t.Fatalf("parse %q: %v", value, err)
+		}
+		if got.Year() != want {
+			t.Fatalf("parse %q: expected year %d, got %d", value, want, got.Year())
+		}
+	}
+	if _, err := parseTransactionDate("31/12/2023", shanghaiLocation); err == nil {
+		t.Fatal("expected error for unsupported date format")
+	}
+}
+
+func TestGetAnnualPerformance_BucketsByShanghaiYear(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	add := func(date, txnType string, quantity, price float64) {
+		t.Helper()
+		req := AddTransactionRequest{
+			TransactionDate: date,
+			Symbol:          "AAPL",
+			TransactionType: txnType,
+			Quantity:        NewAmount(quantity),
+			Price:           NewAmount(price),
+			Currency:        "USD",
+			AccountID:       "acc-1",
+			AssetType:       "stock",
+		}
+		if txnType == "DIVIDEND" {
+			total := NewAmount(price)
+			req.TotalAmount = &total
+		}
+		_, err := core.AddTransaction(req)
+		assertNoError(t, err, "AddTransaction "+date)
+	}
+	add("2023-06-01", "BUY", 10, 100)
+	add("2023-12-31", "SELL", 5, 120)
+	// 2023-12-31 16:30 UTC is already 2024-01-01 in Shanghai.
+	add("2023-12-31T16:30:00Z", "SELL", 2, 130)
+	add("2024-12-31T15:59:59Z", "DIVIDEND", 0, 20)
+	add("2024-12-31T16:00:00Z", "DIVIDEND", 0, 5)
+	testBuyTransaction(t, core, "600519", 1, 1000, "CNY", "acc-1")
+
+	result, err := core.GetAnnualPerformance("usd")
+	assertNoError(t, err, "GetAnnualPerformance")
+	if result.Currency != "USD" || len(result.Years) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	want := []struct {
+		year                                 int
+		realized, dividends, total, cumTotal float64
+	}{
+		{2023, 100, 0, 100, 100},
+		{2024, 60, 20, 80, 180},
+		{2025, 0, 5, 5, 185},
+	}
+	for i, w := range want {
+		got := result.Years[i]
+		if got.Year != w.year ||
+			!floatEquals(got.RealizedPnL.InexactFloat64(), w.realized, 1e-9) ||
+			!floatEquals(got.Dividends.InexactFloat64(), w.dividends, 1e-9) ||
+			!floatEquals(got.Total.InexactFloat64(), w.total, 1e-9) ||
+			!floatEquals(got.CumulativeTotal.InexactFloat64(), w.cumTotal, 1e-9) {
+			t.Fatalf("year %d: unexpected entry %+v", w.year, got)
+		}
+	}
+	if result.Years[0].ChangeFromPrior != nil {
+		t.Fatal("expected no change for the first year")
+	}
+	if change := result.Years[1].ChangeFromPrior; change == nil || !floatEquals(change.InexactFloat64(), -20, 1e-9) {
+		t.Fatalf("expected 2024 change of -20, got %v", change)
+	}
+
+	if _, err := core.GetAnnualPerformance("EUR"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid input for EUR, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package investlog
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// TWRPeriod is one sub-period between consecutive portfolio snapshots.
+// CashFlow is the net external flow dated after StartDate and on or before
+// EndDate.
+type TWRPeriod struct {
+	StartDate     string  `json:"start_date"`
+	EndDate       string  `json:"end_date"`
+	StartValue    Amount  `json:"start_value"`
+	EndValue      Amount  `json:"end_value"`
+	CashFlow      Amount  `json:"cash_flow"`
+	ReturnPercent float64 `json:"return_percent"`
+}
+
+// TimeWeightedReturn links the sub-period returns of one currency's
+// portfolio. ReturnPercent is nil until at least two snapshots exist.
+type TimeWeightedReturn struct {
+	Currency      string      `json:"currency"`
+	StartDate     string      `json:"start_date,omitempty"`
+	EndDate       string      `json:"end_date,omitempty"`
+	ReturnPercent *float64    `json:"return_percent"`
+	Periods       []TWRPeriod `json:"periods"`
+}
+
+// GetTimeWeightedReturn computes the time-weighted return of currency (the
+// configured default base currency when empty) from its portfolio snapshots.
+//
+// Each sub-period runs from one snapshot to the next and returns
+// end / (start + flows) - 1: external cash flows dated inside it are assumed
+// to arrive at its start, so they earn the whole sub-period's return. External
+// flows are TRANSFER_IN (positive) and TRANSFER_OUT (negative) at their total
+// amount, except linked transfers whose other side is in the same currency,
+// which only move value between accounts. BUY, SELL and DIVIDEND stay inside
+// the portfolio, so cash must be tracked for the return to be accurate.
+// Sub-periods whose starting capital is not positive are skipped.
+func (c *Core) GetTimeWeightedReturn(currency string) (*TimeWeightedReturn, error) {
+	curr, err := c.resolveBaseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidCurrency(curr) {
+		return nil, NewError(ErrCodeInvalidInput, fmt.Sprintf("invalid currency: %s", currency))
+	}
+
+	snapshots, err := c.GetPortfolioSnapshots(curr)
+	if err != nil {
+		return nil, err
+	}
+	result := &TimeWeightedReturn{Currency: curr, Periods: []TWRPeriod{}}
+	if len(snapshots) < 2 {
+		return result, nil
+	}
+	flows, err := c.externalCashFlows(curr)
+	if err != nil {
+		return nil, err
+	}
+
+	growth := decimal.NewFromInt(1)
+	hundred := decimal.NewFromInt(100)
+	for i := 1; i < len(snapshots); i++ {
+		start, end := snapshots[i-1], snapshots[i]
+		flow := decimal.Zero
+		for _, f := range flows {
+			if f.date > start.Date && f.date <= end.Date {
+				flow = flow.Add(f.amount)
+			}
+		}
+		capital := start.MarketValue.Add(flow)
+		if !capital.IsPositive() {
+			continue
+		}
+		periodGrowth := end.MarketValue.Div(capital)
+		growth = growth.Mul(periodGrowth)
+		result.Periods = append(result.Periods, TWRPeriod{
+			StartDate:     start.Date,
+			EndDate:       end.Date,
+			StartValue:    start.MarketValue,
+			EndValue:      end.MarketValue,
+			CashFlow:      Amount{flow},
+			ReturnPercent: round2(periodGrowth.Sub(decimal.NewFromInt(1)).Mul(hundred).InexactFloat64()),
+		})
+	}
+	result.StartDate = snapshots[0].Date
+	result.EndDate = snapshots[len(snapshots)-1].Date
+	if len(result.Periods) > 0 {
+		total := round2(growth.Sub(decimal.NewFromInt(1)).Mul(hundred).InexactFloat64())
+		result.ReturnPercent = &total
+	}
+	return result, nil
+}
+
+// externalCashFlow is a signed flow into the portfolio on a local date.
+type externalCashFlow struct {
+	date   string
+	amount decimal.Decimal
+}
+
+// externalCashFlows loads the transfers in currency that cross the portfolio
+// boundary, as described on GetTimeWeightedReturn.
+func (c *Core) externalCashFlows(currency string) ([]externalCashFlow, error) {
+	// CAST keeps the driver from turning DATE values into UTC timestamps.
+	rows, err := c.db.Query(`
+		SELECT t.id, CAST(t.transaction_date AS TEXT), t.transaction_type, t.total_amount
+		FROM transactions t
+		LEFT JOIN transactions linked ON linked.id = t.linked_transaction_id
+		WHERE t.currency = ?
+			AND t.transaction_type IN ('TRANSFER_IN', 'TRANSFER_OUT')
+			AND (linked.id IS NULL OR linked.currency != t.currency)
+	`, currency)
+	if err != nil {
+		return nil, fmt.Errorf("query external cash flows: %w", err)
+	}
+	defer rows.Close()
+
+	var flows []externalCashFlow
+	for rows.Next() {
+		var (
+			id      int64
+			date    string
+			txnType string
+			total   Amount
+		)
+		if err := rows.Scan(&id, &date, &txnType, &total); err != nil {
+			return nil, err
+		}
+		at, err := parseTransactionDate(date, c.Location())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", id, err)
+		}
+		amount := total.Decimal
+		if txnType == "TRANSFER_OUT" {
+			amount = amount.Neg()
+		}
+		flows = append(flows, externalCashFlow{date: at.Format("2006-01-02"), amount: amount})
+	}
+	return flows, rows.Err()
+}
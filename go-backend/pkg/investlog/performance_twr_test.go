@@ -0,0 +1,105 @@
+package investlog
+
+import "testing"
+
+func TestGetTimeWeightedReturn_TwoPeriods(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct-a", "Account A")
+	testAccount(t, core, "acct-b", "Account B")
+	addCash := func(date string, amount int64) {
+		t.Helper()
+		_, err := core.AddTransaction(AddTransactionRequest{
+			TransactionDate: date,
+			Symbol:          "CASH",
+			TransactionType: "TRANSFER_IN",
+			Quantity:        NewAmountFromInt(amount),
+			Price:           NewAmountFromInt(1),
+			AccountID:       "acct-a",
+			AssetType:       "cash",
+			Currency:        "CNY",
+		})
+		assertNoError(t, err, "deposit cash")
+	}
+	addCash("2024-01-01", 1000)
+	// Deposit inside the first sub-period.
+	addCash("2024-01-15", 100)
+	// A same-currency transfer between accounts is not an external flow.
+	_, err := core.Transfer(TransferRequest{
+		TransactionDate: "2024-02-10",
+		Symbol:          "CASH",
+		Quantity:        NewAmountFromInt(50),
+		FromAccountID:   "acct-a",
+		ToAccountID:     "acct-b",
+		FromCurrency:    "CNY",
+	})
+	assertNoError(t, err, "Transfer")
+
+	for _, snapshot := range []PortfolioSnapshot{
+		{Currency: "CNY", Date: "2024-01-01", MarketValue: NewAmountFromInt(1000)},
+		{Currency: "CNY", Date: "2024-02-01", MarketValue: NewAmountFromInt(1200)},
+		{Currency: "CNY", Date: "2024-03-01", MarketValue: NewAmountFromInt(1320)},
+	} {
+		assertNoError(t, core.savePortfolioSnapshot(snapshot), "savePortfolioSnapshot")
+	}
+
+	result, err := core.GetTimeWeightedReturn("CNY")
+	assertNoError(t, err, "GetTimeWeightedReturn")
+
+	// Period 1: 1200 / (1000 + 100) - 1 = 9.09%; period 2: 1320 / 1200 - 1 = 10%.
+	// Linked: 1.090909 * 1.1 - 1 = 20%.
+	if len(result.Periods) != 2 {
+		t.Fatalf("expected 2 periods, got %+v", result.Periods)
+	}
+	assertFloatEquals(t, result.Periods[0].CashFlow, 100, "period 1 cash flow")
+	assertFloatEquals(t, result.Periods[0].ReturnPercent, 9.09, "period 1 return")
+	assertFloatEquals(t, result.Periods[1].CashFlow, 0, "period 2 cash flow")
+	assertFloatEquals(t, result.Periods[1].ReturnPercent, 10, "period 2 return")
+	if result.ReturnPercent == nil {
+		t.Fatal("expected linked return")
+	}
+	assertFloatEquals(t, *result.ReturnPercent, 20, "linked return")
+	if result.StartDate != "2024-01-01" || result.EndDate != "2024-03-01" {
+		t.Fatalf("unexpected range %s..%s", result.StartDate, result.EndDate)
+	}
+}
+
+func TestGetTimeWeightedReturn_NeedsTwoSnapshots(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	assertNoError(t, core.savePortfolioSnapshot(PortfolioSnapshot{Currency: "USD", Date: "2024-01-01", MarketValue: NewAmountFromInt(10)}), "savePortfolioSnapshot")
+	result, err := core.GetTimeWeightedReturn("USD")
+	assertNoError(t, err, "GetTimeWeightedReturn")
+	if result.ReturnPercent != nil || len(result.Periods) != 0 {
+		t.Fatalf("expected no return from a single snapshot, got %+v", result)
+	}
+
+	if _, err := core.GetTimeWeightedReturn("EUR"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid currency error, got %v", err)
+	}
+}
+
+func TestRecordPortfolioSnapshots(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct-a", "Account A")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acct-a")
+
+	snapshots, err := core.RecordPortfolioSnapshots()
+	assertNoError(t, err, "RecordPortfolioSnapshots")
+	if len(snapshots) != 1 || snapshots[0].Currency != "USD" || snapshots[0].Date != core.TodayISO() {
+		t.Fatalf("unexpected snapshots: %+v", snapshots)
+	}
+	// Recording again the same day replaces the snapshot.
+	_, err = core.RecordPortfolioSnapshots()
+	assertNoError(t, err, "RecordPortfolioSnapshots again")
+	stored, err := core.GetPortfolioSnapshots("usd")
+	assertNoError(t, err, "GetPortfolioSnapshots")
+	if len(stored) != 1 {
+		t.Fatalf("expected one snapshot per day, got %+v", stored)
+	}
+	assertFloatEquals(t, stored[0].MarketValue, snapshots[0].MarketValue.InexactFloat64(), "stored market value")
+}
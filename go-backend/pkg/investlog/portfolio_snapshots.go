@@ -0,0 +1,82 @@
+package investlog
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PortfolioSnapshot is one currency's holdings market value, cash included,
+// as of the end of Date (YYYY-MM-DD in the configured time zone).
+type PortfolioSnapshot struct {
+	Currency    string `json:"currency"`
+	Date        string `json:"date"`
+	MarketValue Amount `json:"market_value"`
+}
+
+// RecordPortfolioSnapshots stores today's market value for every currency
+// with holdings, replacing any snapshot already taken today.
+func (c *Core) RecordPortfolioSnapshots() ([]PortfolioSnapshot, error) {
+	bySymbol, err := c.GetHoldingsBySymbol()
+	if err != nil {
+		return nil, err
+	}
+	currencies := make([]string, 0, len(bySymbol))
+	for currency := range bySymbol {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	today := c.TodayISO()
+	snapshots := make([]PortfolioSnapshot, 0, len(currencies))
+	for _, currency := range currencies {
+		snapshot := PortfolioSnapshot{
+			Currency:    currency,
+			Date:        today,
+			MarketValue: bySymbol[currency].TotalMarketValue,
+		}
+		if err := c.savePortfolioSnapshot(snapshot); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (c *Core) savePortfolioSnapshot(snapshot PortfolioSnapshot) error {
+	_, err := c.db.Exec(`
+		INSERT INTO portfolio_snapshots (currency, snapshot_date, market_value)
+		VALUES (?, ?, ?)
+		ON CONFLICT(currency, snapshot_date) DO UPDATE SET
+			market_value = excluded.market_value,
+			created_at = CURRENT_TIMESTAMP
+	`, snapshot.Currency, snapshot.Date, snapshot.MarketValue)
+	if err != nil {
+		return fmt.Errorf("save portfolio snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetPortfolioSnapshots returns the stored snapshots of currency, oldest first.
+func (c *Core) GetPortfolioSnapshots(currency string) ([]PortfolioSnapshot, error) {
+	currency = normalizeCurrency(currency)
+	rows, err := c.db.Query(`
+		SELECT currency, snapshot_date, market_value
+		FROM portfolio_snapshots
+		WHERE currency = ?
+		ORDER BY snapshot_date
+	`, currency)
+	if err != nil {
+		return nil, fmt.Errorf("query portfolio snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []PortfolioSnapshot{}
+	for rows.Next() {
+		var snapshot PortfolioSnapshot
+		if err := rows.Scan(&snapshot.Currency, &snapshot.Date, &snapshot.MarketValue); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
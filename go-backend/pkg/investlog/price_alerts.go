@@ -0,0 +1,174 @@
+package investlog
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const (
+	PriceAlertAbove = "above"
+	PriceAlertBelow = "below"
+)
+
+// PriceAlert fires once when a bulk price update fetches a price at or past
+// TargetPrice in Direction. TriggeredAt and TriggeredPrice stay nil until then.
+type PriceAlert struct {
+	ID             int64   `json:"id"`
+	Symbol         string  `json:"symbol"`
+	Currency       string  `json:"currency"`
+	TargetPrice    Amount  `json:"target_price"`
+	Direction      string  `json:"direction"`
+	TriggeredAt    *string `json:"triggered_at"`
+	TriggeredPrice *Amount `json:"triggered_price"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// SetPriceAlert sets the alert for symbol in currency and direction. Setting
+// an existing alert replaces its target and re-arms it.
+func (c *Core) SetPriceAlert(symbol, currency string, targetPrice float64, direction string) (*PriceAlert, error) {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	direction = strings.ToLower(strings.TrimSpace(direction))
+	invalid := &ValidationError{}
+	if symbol == "" {
+		invalid.Add("symbol", "symbol is required")
+	}
+	if !isValidCurrency(currency) {
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+	if targetPrice <= 0 {
+		invalid.Add("target_price", "target_price must be positive")
+	}
+	if direction != PriceAlertAbove && direction != PriceAlertBelow {
+		invalid.Add("direction", "direction must be above or below")
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err := c.db.Exec(`
+		INSERT INTO price_alerts (symbol, currency, target_price, direction)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(symbol, currency, direction) DO UPDATE SET
+			target_price = excluded.target_price,
+			triggered_at = NULL,
+			triggered_price = NULL
+	`, symbol, currency, targetPrice, direction)
+	if err != nil {
+		return nil, fmt.Errorf("set price alert: %w", err)
+	}
+	alerts, err := c.queryPriceAlerts("WHERE symbol = ? AND currency = ? AND direction = ?", []any{symbol, currency, direction}, priceAlertOrder)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, fmt.Errorf("price alert for %s (%s) not found after save", symbol, currency)
+	}
+	return &alerts[0], nil
+}
+
+// DeletePriceAlert removes alert id. It reports false when there is no such alert.
+func (c *Core) DeletePriceAlert(id int64) (bool, error) {
+	result, err := c.db.Exec("DELETE FROM price_alerts WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetPriceAlerts returns every alert, triggered or not, ordered by currency
+// and symbol.
+func (c *Core) GetPriceAlerts() ([]PriceAlert, error) {
+	return c.queryPriceAlerts("", nil, priceAlertOrder)
+}
+
+// GetTriggeredPriceAlerts returns the alerts that have fired, most recent first.
+func (c *Core) GetTriggeredPriceAlerts() ([]PriceAlert, error) {
+	return c.queryPriceAlerts("WHERE triggered_at IS NOT NULL", nil, "triggered_at DESC, id DESC")
+}
+
+const priceAlertOrder = "currency, symbol, direction"
+
+func (c *Core) queryPriceAlerts(where string, params []any, order string) ([]PriceAlert, error) {
+	rows, err := c.db.Query(`
+		SELECT id, symbol, currency, target_price, direction,
+			CAST(triggered_at AS TEXT), triggered_price, CAST(created_at AS TEXT)
+		FROM price_alerts
+		`+where+`
+		ORDER BY `+order, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []PriceAlert{}
+	for rows.Next() {
+		var alert PriceAlert
+		var target float64
+		var triggeredAt, createdAt sql.NullString
+		var triggeredPrice sql.NullFloat64
+		if err := rows.Scan(&alert.ID, &alert.Symbol, &alert.Currency, &target, &alert.Direction,
+			&triggeredAt, &triggeredPrice, &createdAt); err != nil {
+			return nil, err
+		}
+		alert.TargetPrice = NewAmount(target)
+		if triggeredAt.Valid {
+			alert.TriggeredAt = &triggeredAt.String
+		}
+		if triggeredPrice.Valid {
+			alert.TriggeredPrice = amountPtr(NewAmount(triggeredPrice.Float64))
+		}
+		alert.CreatedAt = createdAt.String
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// evaluatePriceAlerts fires the armed alerts in currency whose symbol has a
+// freshly fetched price in prices. Failures are logged rather than returned
+// so alerting never fails a price update.
+func (c *Core) evaluatePriceAlerts(currency string, prices map[string]Amount) {
+	if len(prices) == 0 {
+		return
+	}
+	alerts, err := c.queryPriceAlerts("WHERE currency = ? AND triggered_at IS NULL", []any{currency}, priceAlertOrder)
+	if err != nil {
+		c.Logger().Warn("load price alerts failed", "currency", currency, "err", err)
+		return
+	}
+	for _, alert := range alerts {
+		price, ok := prices[alert.Symbol]
+		if !ok || !priceAlertHit(alert, price) {
+			continue
+		}
+		_, err := c.db.Exec(`
+			UPDATE price_alerts
+			SET triggered_at = CURRENT_TIMESTAMP, triggered_price = ?
+			WHERE id = ? AND triggered_at IS NULL
+		`, price.InexactFloat64(), alert.ID)
+		if err != nil {
+			c.Logger().Warn("record price alert failed", "id", alert.ID, "symbol", alert.Symbol, "err", err)
+			continue
+		}
+		c.Logger().Info("price alert triggered",
+			"id", alert.ID,
+			"symbol", alert.Symbol,
+			"currency", alert.Currency,
+			"direction", alert.Direction,
+			"target_price", alert.TargetPrice.String(),
+			"price", price.String(),
+		)
+	}
+}
+
+func priceAlertHit(alert PriceAlert, price Amount) bool {
+	if alert.Direction == PriceAlertAbove {
+		return price.GreaterThanOrEqual(alert.TargetPrice.Decimal)
+	}
+	return price.LessThanOrEqual(alert.TargetPrice.Decimal)
+}
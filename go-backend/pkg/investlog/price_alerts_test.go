@@ -0,0 +1,98 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPriceAlerts_TriggerOnBulkUpdate(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct", "Account")
+	testBuyTransaction(t, core, "AAPL", 10, 150, "USD", "acct")
+	testBuyTransaction(t, core, "MSFT", 10, 300, "USD", "acct")
+	core.price.setCached("AAPL", "USD", "stock", 180, "test")
+	core.price.setCached("MSFT", "USD", "stock", 310, "test")
+
+	above, err := core.SetPriceAlert("aapl", "usd", 175, "ABOVE")
+	assertNoError(t, err, "set AAPL above")
+	if above.Symbol != "AAPL" || above.Currency != "USD" || above.Direction != PriceAlertAbove || above.TriggeredAt != nil {
+		t.Fatalf("unexpected alert: %+v", above)
+	}
+	_, err = core.SetPriceAlert("AAPL", "USD", 140, PriceAlertBelow)
+	assertNoError(t, err, "set AAPL below")
+	_, err = core.SetPriceAlert("MSFT", "USD", 305, PriceAlertBelow)
+	assertNoError(t, err, "set MSFT below")
+
+	_, _, err = core.UpdateAllPrices("USD")
+	assertNoError(t, err, "update all prices")
+
+	triggered, err := core.GetTriggeredPriceAlerts()
+	assertNoError(t, err, "triggered alerts")
+	if len(triggered) != 1 || triggered[0].ID != above.ID {
+		t.Fatalf("expected only AAPL above to trigger, got %+v", triggered)
+	}
+	if triggered[0].TriggeredPrice == nil || triggered[0].TriggeredPrice.InexactFloat64() != 180 {
+		t.Fatalf("expected triggered price 180, got %v", triggered[0].TriggeredPrice)
+	}
+
+	// Re-setting the alert re-arms it with the new target.
+	rearmed, err := core.SetPriceAlert("AAPL", "USD", 200, PriceAlertAbove)
+	assertNoError(t, err, "re-arm AAPL above")
+	if rearmed.ID != above.ID || rearmed.TriggeredAt != nil || rearmed.TargetPrice.InexactFloat64() != 200 {
+		t.Fatalf("expected re-armed alert, got %+v", rearmed)
+	}
+	alerts, err := core.GetPriceAlerts()
+	assertNoError(t, err, "list alerts")
+	if len(alerts) != 3 {
+		t.Fatalf("expected 3 alerts, got %d", len(alerts))
+	}
+
+	deleted, err := core.DeletePriceAlert(above.ID)
+	assertNoError(t, err, "delete alert")
+	if !deleted {
+		t.Fatal("expected alert deleted")
+	}
+	if deleted, err := core.DeletePriceAlert(above.ID); err != nil || deleted {
+		t.Fatalf("expected second delete to report missing, got %v, %v", deleted, err)
+	}
+}
+
+func TestSetPriceAlert_Validation(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := core.SetPriceAlert("", "XYZ", 0, "sideways")
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	for _, field := range []string{"symbol", "currency", "target_price", "direction"} {
+		if _, ok := invalid.Fields[field]; !ok {
+			t.Fatalf("expected %s to be invalid, got %+v", field, invalid.Fields)
+		}
+	}
+}
+
+func TestPriceAlertHit(t *testing.T) {
+	above := PriceAlert{Direction: PriceAlertAbove, TargetPrice: NewAmountFromInt(100)}
+	below := PriceAlert{Direction: PriceAlertBelow, TargetPrice: NewAmountFromInt(100)}
+	cases := []struct {
+		alert PriceAlert
+		price int64
+		want  bool
+	}{
+		{above, 99, false},
+		{above, 100, true},
+		{above, 101, true},
+		{below, 101, false},
+		{below, 100, true},
+		{below, 99, true},
+	}
+	for _, tc := range cases {
+		if got := priceAlertHit(tc.alert, NewAmountFromInt(tc.price)); got != tc.want {
+			t.Fatalf("%s %s at %d: got %v, want %v", tc.alert.Direction, tc.alert.TargetPrice.String(), tc.price, got, tc.want)
+		}
+	}
+}
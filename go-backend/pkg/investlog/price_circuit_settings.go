@@ -0,0 +1,87 @@
+package investlog
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PriceCircuitSettings tunes the per-source price circuit breaker: a source
+// that fails FailThreshold times within FailWindowSeconds is skipped for
+// CooldownSeconds.
+type PriceCircuitSettings struct {
+	FailThreshold     int `json:"fail_threshold"`
+	FailWindowSeconds int `json:"fail_window_seconds"`
+	CooldownSeconds   int `json:"cooldown_seconds"`
+}
+
+// GetPriceCircuitSettings returns the circuit-breaker parameters in effect.
+func (c *Core) GetPriceCircuitSettings() PriceCircuitSettings {
+	threshold, window, cooldown := c.price.circuitConfig()
+	return PriceCircuitSettings{
+		FailThreshold:     threshold,
+		FailWindowSeconds: int(window / time.Second),
+		CooldownSeconds:   int(cooldown / time.Second),
+	}
+}
+
+// SetPriceCircuitSettings applies new circuit-breaker parameters immediately
+// and persists them so they survive restarts.
+func (c *Core) SetPriceCircuitSettings(settings PriceCircuitSettings) (PriceCircuitSettings, error) {
+	invalid := &ValidationError{}
+	if settings.FailThreshold <= 0 {
+		invalid.Add("fail_threshold", "fail_threshold must be positive")
+	}
+	if settings.FailWindowSeconds <= 0 {
+		invalid.Add("fail_window_seconds", "fail_window_seconds must be positive")
+	}
+	if settings.CooldownSeconds <= 0 {
+		invalid.Add("cooldown_seconds", "cooldown_seconds must be positive")
+	}
+	if err := invalid.Err(); err != nil {
+		return PriceCircuitSettings{}, err
+	}
+
+	_, err := c.db.Exec(`
+		INSERT INTO price_circuit_settings (id, fail_threshold, fail_window_seconds, cooldown_seconds, updated_at)
+		VALUES (1, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			fail_threshold = excluded.fail_threshold,
+			fail_window_seconds = excluded.fail_window_seconds,
+			cooldown_seconds = excluded.cooldown_seconds,
+			updated_at = CURRENT_TIMESTAMP
+	`, settings.FailThreshold, settings.FailWindowSeconds, settings.CooldownSeconds)
+	if err != nil {
+		return PriceCircuitSettings{}, fmt.Errorf("save price circuit settings: %w", err)
+	}
+	if err := c.applyPriceCircuitSettings(settings); err != nil {
+		return PriceCircuitSettings{}, err
+	}
+	return c.GetPriceCircuitSettings(), nil
+}
+
+// loadPriceCircuitSettings applies persisted settings over the construction
+// options. Without a saved row the options stay in effect.
+func (c *Core) loadPriceCircuitSettings() error {
+	var settings PriceCircuitSettings
+	err := c.db.QueryRow(`
+		SELECT fail_threshold, fail_window_seconds, cooldown_seconds
+		FROM price_circuit_settings
+		WHERE id = 1
+	`).Scan(&settings.FailThreshold, &settings.FailWindowSeconds, &settings.CooldownSeconds)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.applyPriceCircuitSettings(settings)
+}
+
+func (c *Core) applyPriceCircuitSettings(settings PriceCircuitSettings) error {
+	return c.price.configureCircuit(
+		settings.FailThreshold,
+		time.Duration(settings.FailWindowSeconds)*time.Second,
+		time.Duration(settings.CooldownSeconds)*time.Second,
+	)
+}
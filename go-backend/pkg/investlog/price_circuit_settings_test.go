@@ -0,0 +1,36 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetPriceCircuitSettings(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	saved, err := core.SetPriceCircuitSettings(PriceCircuitSettings{FailThreshold: 1, FailWindowSeconds: 30, CooldownSeconds: 600})
+	assertNoError(t, err, "SetPriceCircuitSettings")
+	if saved != (PriceCircuitSettings{FailThreshold: 1, FailWindowSeconds: 30, CooldownSeconds: 600}) {
+		t.Fatalf("unexpected saved settings: %+v", saved)
+	}
+
+	core.price.recordServiceFailure("Eastmoney")
+	if core.price.serviceAvailable("Eastmoney") {
+		t.Fatal("threshold of 1 should trip after one failure")
+	}
+
+	// Persisted values are reapplied on load.
+	assertNoError(t, core.price.configureCircuit(9, time.Second, time.Second), "reset circuit")
+	assertNoError(t, core.loadPriceCircuitSettings(), "loadPriceCircuitSettings")
+	if got := core.GetPriceCircuitSettings(); got != saved {
+		t.Fatalf("expected persisted settings %+v, got %+v", saved, got)
+	}
+
+	_, err = core.SetPriceCircuitSettings(PriceCircuitSettings{})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != 3 {
+		t.Fatalf("expected 3 field errors, got %v", err)
+	}
+}
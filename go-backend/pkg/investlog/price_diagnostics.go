@@ -0,0 +1,141 @@
+package investlog
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit-breaker states reported by price diagnostics.
+const (
+	CircuitClosed = "closed"
+	CircuitOpen   = "open"
+)
+
+// PriceSourceRequest is one HTTP call a price source made.
+type PriceSourceRequest struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PriceSourceDiagnostic is the outcome of querying one price source.
+type PriceSourceDiagnostic struct {
+	Source    string               `json:"source"`
+	Circuit   string               `json:"circuit"`
+	Requests  []PriceSourceRequest `json:"requests"`
+	Price     *float64             `json:"price,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	LatencyMS int64                `json:"latency_ms"`
+}
+
+// PriceDiagnostics reports every source tried for a symbol.
+type PriceDiagnostics struct {
+	Symbol     string                  `json:"symbol"`
+	Currency   string                  `json:"currency"`
+	AssetType  string                  `json:"asset_type"`
+	SymbolType string                  `json:"symbol_type"`
+	Message    string                  `json:"message,omitempty"`
+	Sources    []PriceSourceDiagnostic `json:"sources"`
+}
+
+// FetchPriceDiagnostics queries every price source for symbol and reports
+// each one's requests, HTTP status or error, latency and circuit state. It
+// bypasses the cache, tries sources whose circuit is open, and neither
+// stores prices nor updates the circuit breaker.
+func (c *Core) FetchPriceDiagnostics(symbol, currency, assetType string) (*PriceDiagnostics, error) {
+	if normalizeSymbol(symbol) == "" {
+		return nil, NewValidationError("symbol", "symbol is required")
+	}
+	return c.price.diagnose(symbol, currency, assetType), nil
+}
+
+func (pf *priceFetcher) diagnose(symbol, currency, assetType string) *PriceDiagnostics {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	assetType = strings.ToLower(strings.TrimSpace(assetType))
+	if assetType == "" {
+		assetType = "stock"
+	}
+	symbolType := pf.symbolType(symbol, currency, assetType)
+	result := &PriceDiagnostics{
+		Symbol:     symbol,
+		Currency:   currency,
+		AssetType:  assetType,
+		SymbolType: symbolType,
+		Sources:    []PriceSourceDiagnostic{},
+	}
+
+	recorder := &recordingHTTPDoer{next: pf.client}
+	probe := newPriceFetcher(priceFetcherOptions{
+		Logger:       pf.logger,
+		HTTPClient:   recorder,
+		USDToCNYRate: pf.usdToCNYRate,
+		RateResolver: pf.rateResolver,
+		GoldUnit:     pf.goldUnit,
+		GoldCurrency: pf.goldCurrency,
+		UserAgent:    pf.userAgent,
+	})
+	attempts := probe.buildAttempts(symbolType, symbol, currency, assetType)
+	if len(attempts) == 0 {
+		result.Message = "no price sources for symbol type " + symbolType
+		return result
+	}
+
+	for _, attempt := range attempts {
+		diag := PriceSourceDiagnostic{Source: attempt.name, Circuit: CircuitClosed}
+		if !pf.serviceAvailable(attempt.name) {
+			diag.Circuit = CircuitOpen
+		}
+		start := time.Now()
+		quote, err := attempt.fn()
+		diag.LatencyMS = time.Since(start).Milliseconds()
+		diag.Requests = recorder.drain()
+		switch {
+		case err != nil:
+			diag.Error = err.Error()
+		case quote == nil:
+			diag.Error = ErrNoData.Error()
+		default:
+			price := quote.price
+			diag.Price = &price
+		}
+		result.Sources = append(result.Sources, diag)
+	}
+	return result
+}
+
+// recordingHTTPDoer forwards requests and remembers their URL and outcome.
+type recordingHTTPDoer struct {
+	next HTTPDoer
+
+	mu    sync.Mutex
+	calls []PriceSourceRequest
+}
+
+func (r *recordingHTTPDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.Do(req)
+	call := PriceSourceRequest{URL: req.URL.String()}
+	if err != nil {
+		call.Error = err.Error()
+	} else {
+		call.StatusCode = resp.StatusCode
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+	return resp, err
+}
+
+// drain returns the calls recorded since the previous drain.
+func (r *recordingHTTPDoer) drain() []PriceSourceRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	if calls == nil {
+		calls = []PriceSourceRequest{}
+	}
+	return calls
+}
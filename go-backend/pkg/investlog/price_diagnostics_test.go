@@ -0,0 +1,92 @@
+package investlog
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPriceFetcherDiagnoseCapturesEachSource(t *testing.T) {
+	pf := newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Minute,
+		FailThreshold: 1,
+		FailWindow:    time.Minute,
+		Cooldown:      time.Minute,
+		HTTPClient: &routeHTTPClient{routes: map[string]mockHTTPClient{
+			"https://query1.finance.yahoo.com/v8/finance/chart/AAPL?interval=1d&range=1d": {status: http.StatusInternalServerError},
+			"http://hq.sinajs.cn/list=gb_aapl":                                            {status: http.StatusOK, body: `var hq_str_gb_aapl="0,9.87,1"`},
+		}},
+	})
+	pf.recordServiceFailure("Tencent Finance")
+
+	diag := pf.diagnose("aapl", "usd", "")
+	if diag.Symbol != "AAPL" || diag.SymbolType != "us_stock" {
+		t.Fatalf("unexpected header: %+v", diag)
+	}
+	if len(diag.Sources) != 3 {
+		t.Fatalf("expected 3 sources, got %+v", diag.Sources)
+	}
+
+	yahoo := diag.Sources[0]
+	if yahoo.Source != "Yahoo Finance" || len(yahoo.Requests) == 0 {
+		t.Fatalf("unexpected yahoo diagnostic: %+v", yahoo)
+	}
+	if yahoo.Requests[0].StatusCode != http.StatusInternalServerError || !strings.Contains(yahoo.Requests[0].URL, "/chart/AAPL") {
+		t.Fatalf("expected yahoo 500 on chart URL, got %+v", yahoo.Requests[0])
+	}
+	if yahoo.Error == "" || yahoo.Price != nil {
+		t.Fatalf("expected yahoo failure, got %+v", yahoo)
+	}
+
+	sina := diag.Sources[1]
+	if sina.Price == nil || *sina.Price != 9.87 || sina.Error != "" {
+		t.Fatalf("expected sina price, got %+v", sina)
+	}
+	if len(sina.Requests) != 1 || sina.Requests[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected one sina 200 request, got %+v", sina.Requests)
+	}
+
+	tencent := diag.Sources[2]
+	if tencent.Circuit != CircuitOpen {
+		t.Fatalf("expected tencent circuit open, got %+v", tencent)
+	}
+	if len(tencent.Requests) != 1 || tencent.Requests[0].StatusCode != http.StatusNotFound || tencent.Error == "" {
+		t.Fatalf("expected tencent 404 failure, got %+v", tencent)
+	}
+
+	// Diagnostics must not touch the cache or the breaker.
+	if _, _, ok := pf.getCached("AAPL", "USD", "stock", "us_stock"); ok {
+		t.Fatal("diagnostics should not cache prices")
+	}
+	if !pf.serviceAvailable("Yahoo Finance") {
+		t.Fatal("diagnostics should not record failures")
+	}
+}
+
+func TestPriceFetcherDiagnoseSendsConfiguredUserAgent(t *testing.T) {
+	client := &headerCaptureClient{}
+	pf := newPriceFetcher(priceFetcherOptions{
+		HTTPTimeout: time.Second,
+		HTTPClient:  client,
+		UserAgent:   "InvestLogTest/2.0",
+	})
+
+	pf.diagnose("AAPL", "USD", "stock")
+	if len(client.userAgents) == 0 {
+		t.Fatal("expected diagnostics to probe price sources")
+	}
+	for i, ua := range client.userAgents {
+		if ua != "InvestLogTest/2.0" {
+			t.Errorf("request %d: expected configured User-Agent, got %q", i, ua)
+		}
+	}
+}
+
+func TestPriceFetcherDiagnoseWithoutSources(t *testing.T) {
+	pf := newPriceFetcher(priceFetcherOptions{})
+	diag := pf.diagnose("CASH", "USD", "cash")
+	if diag.SymbolType != "cash" || len(diag.Sources) != 0 || diag.Message == "" {
+		t.Fatalf("unexpected cash diagnostics: %+v", diag)
+	}
+}
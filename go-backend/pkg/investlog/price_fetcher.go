@@ -42,6 +42,9 @@ var (
 	ErrBondNotSupported = errors.New("bond price not supported")
 	// ErrUnknownSymbol indicates the symbol type could not be determined.
 	ErrUnknownSymbol = errors.New("unknown symbol type")
+	// ErrHTMLResponse indicates a data source answered with an HTML page
+	// (typically a block or captcha page) instead of quote data.
+	ErrHTMLResponse = errors.New("source returned HTML (possible block/captcha)")
 )
 
 // Symbol classification prefixes for Chinese markets.
@@ -78,27 +81,45 @@ type HTTPDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// defaultPriceUserAgent is sent to price sources unless overridden. Several
+// of them reject bare "Mozilla/5.0" as bot traffic, so it mimics a desktop browser.
+const defaultPriceUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+
 type priceFetcherOptions struct {
-	Logger        *slog.Logger
-	CacheTTL      time.Duration
-	FailThreshold int
-	FailWindow    time.Duration
-	Cooldown      time.Duration
-	HTTPTimeout   time.Duration
-	HTTPClient    HTTPDoer                                   // Optional: inject custom client for testing
-	USDToCNYRate  float64                                    // Optional: USD/CNY exchange rate for gold price conversion
-	RateResolver  func(fromCurrency string) (float64, error) // Optional: resolve FX rates at runtime (e.g. HKD→CNY)
+	Logger         *slog.Logger
+	CacheTTL       time.Duration
+	CacheTTLByType map[string]time.Duration // Optional: TTL per detected symbol type (e.g. "etf", "us_stock"); falls back to CacheTTL
+	FailThreshold  int
+	FailWindow     time.Duration
+	Cooldown       time.Duration
+	HTTPTimeout    time.Duration
+	HTTPClient     HTTPDoer                                   // Optional: inject custom client for testing
+	USDToCNYRate   float64                                    // Optional: fallback USD/CNY rate for gold price conversion
+	RateResolver   func(fromCurrency string) (float64, error) // Optional: resolve FX rates at runtime (e.g. HKD→CNY)
+	TypeOverride   func(symbol, currency string) string       // Optional: user-forced symbol type; "" means detect
+	RetryAttempts  int                                        // Optional: retries after a transient network error; 0 disables
+	RetryDelay     time.Duration                              // Optional: pause before each retry
+	GoldUnit       string                                     // Optional: "gram" (default) or "ounce"
+	GoldCurrency   string                                     // Optional: currency gold is quoted in; default CNY
+	UserAgent      string                                     // Optional: User-Agent sent to price sources; default defaultPriceUserAgent
 }
 
 type priceFetcher struct {
-	logger        *slog.Logger
-	cacheTTL      time.Duration
-	failThreshold int
-	failWindow    time.Duration
-	cooldown      time.Duration
-	client        HTTPDoer
-	usdToCNYRate  float64
-	rateResolver  func(fromCurrency string) (float64, error)
+	logger         *slog.Logger
+	cacheTTL       time.Duration
+	cacheTTLByType map[string]time.Duration
+	failThreshold  int
+	failWindow     time.Duration
+	cooldown       time.Duration
+	client         HTTPDoer
+	usdToCNYRate   float64
+	rateResolver   func(fromCurrency string) (float64, error)
+	typeOverride   func(symbol, currency string) string
+	retryAttempts  int
+	retryDelay     time.Duration
+	goldUnit       string
+	goldCurrency   string
+	userAgent      string
 
 	// Separate locks for cache and circuit breaker to reduce contention.
 	// Cache operations are frequent reads; circuit breaker updates are less frequent.
@@ -109,9 +130,44 @@ type priceFetcher struct {
 }
 
 type cacheEntry struct {
-	price  float64
-	source string
-	ts     time.Time
+	price         float64
+	previousClose *float64
+	source        string
+	ts            time.Time
+}
+
+// priceQuote is a fetched price plus the previous session's close when the
+// source reports one.
+type priceQuote struct {
+	price         float64
+	previousClose *float64
+}
+
+// priceOnly adapts a provider that reports no previous close.
+func priceOnly(price *float64, err error) (*priceQuote, error) {
+	if err != nil || price == nil {
+		return nil, err
+	}
+	return &priceQuote{price: *price}, nil
+}
+
+// quotePrice drops the previous close from a quote provider's result.
+func quotePrice(quote *priceQuote, err error) (*float64, error) {
+	if err != nil || quote == nil {
+		return nil, err
+	}
+	price := quote.price
+	return &price, nil
+}
+
+// parsePreviousClose returns a positive previous close parsed from raw, or
+// nil when the field is missing or malformed.
+func parsePreviousClose(raw any) *float64 {
+	value, err := parseFloat(raw)
+	if err != nil || value <= 0 {
+		return nil
+	}
+	return &value
 }
 
 type serviceState struct {
@@ -135,34 +191,62 @@ func newPriceFetcher(opts priceFetcherOptions) *priceFetcher {
 	if usdToCNYRate <= 0 {
 		usdToCNYRate = defaultUSDToCNYRate
 	}
+	cacheTTLByType := make(map[string]time.Duration, len(opts.CacheTTLByType))
+	for symbolType, ttl := range opts.CacheTTLByType {
+		if ttl > 0 {
+			cacheTTLByType[symbolType] = ttl
+		}
+	}
+	userAgent := strings.TrimSpace(opts.UserAgent)
+	if userAgent == "" {
+		userAgent = defaultPriceUserAgent
+	}
 	return &priceFetcher{
-		logger:        logger,
-		cacheTTL:      opts.CacheTTL,
-		failThreshold: opts.FailThreshold,
-		failWindow:    opts.FailWindow,
-		cooldown:      opts.Cooldown,
-		client:        client,
-		usdToCNYRate:  usdToCNYRate,
-		rateResolver:  opts.RateResolver,
-		cache:         map[string]cacheEntry{},
-		serviceState:  map[string]*serviceState{},
+		logger:         logger,
+		cacheTTL:       opts.CacheTTL,
+		cacheTTLByType: cacheTTLByType,
+		failThreshold:  opts.FailThreshold,
+		failWindow:     opts.FailWindow,
+		cooldown:       opts.Cooldown,
+		client:         client,
+		usdToCNYRate:   usdToCNYRate,
+		rateResolver:   opts.RateResolver,
+		typeOverride:   opts.TypeOverride,
+		retryAttempts:  opts.RetryAttempts,
+		retryDelay:     opts.RetryDelay,
+		goldUnit:       normalizeGoldUnit(opts.GoldUnit),
+		goldCurrency:   normalizeGoldCurrency(opts.GoldCurrency),
+		userAgent:      userAgent,
+		cache:          map[string]cacheEntry{},
+		serviceState:   map[string]*serviceState{},
 	}
 }
 
 // FetchPrice fetches latest price with fallback.
 func (c *Core) FetchPrice(symbol, currency, assetType string) (PriceResult, error) {
-	priceF, message, err := c.price.fetch(symbol, currency, assetType)
+	quote, message, err := c.price.fetchQuote(symbol, currency, assetType)
 	if err != nil {
 		return PriceResult{Price: nil, Message: message}, err
 	}
-	if priceF != nil {
-		a := NewAmount(*priceF)
-		return PriceResult{Price: &a, Message: message}, nil
+	if quote != nil {
+		a := NewAmount(quote.price)
+		result := PriceResult{Price: &a, Message: message}
+		if quote.previousClose != nil {
+			prev := NewAmount(*quote.previousClose)
+			result.PreviousClose = &prev
+		}
+		return result, nil
 	}
 	return PriceResult{Price: nil, Message: message}, nil
 }
 
 func (pf *priceFetcher) fetch(symbol, currency, assetType string) (*float64, string, error) {
+	quote, message, err := pf.fetchQuote(symbol, currency, assetType)
+	price, _ := quotePrice(quote, nil)
+	return price, message, err
+}
+
+func (pf *priceFetcher) fetchQuote(symbol, currency, assetType string) (*priceQuote, string, error) {
 	symbol = normalizeSymbol(symbol)
 	currency = normalizeCurrency(currency)
 	assetType = strings.ToLower(strings.TrimSpace(assetType))
@@ -170,20 +254,19 @@ func (pf *priceFetcher) fetch(symbol, currency, assetType string) (*float64, str
 		assetType = "stock"
 	}
 
-	if cachedPrice, source, ok := pf.getCached(symbol, currency, assetType); ok {
+	symbolType := pf.symbolType(symbol, currency, assetType)
+	if cached, source, ok := pf.getCachedQuote(symbol, currency, assetType, symbolType); ok {
 		msg := fmt.Sprintf("价格获取成功 (缓存, 来源: %s)", source)
-		return &cachedPrice, msg, nil
+		return cached, msg, nil
 	}
 
-	symbolType := detectSymbolType(symbol, currency, assetType)
 	pf.logger.Info("fetching price", "symbol", symbol, "currency", currency, "assetType", assetType, "type", symbolType)
 
 	if symbolType == "bond" {
 		return nil, "债券价格暂不支持自动获取", ErrBondNotSupported
 	}
 	if symbolType == "cash" {
-		price := 1.0
-		return &price, "现金价格固定为 1.0", nil
+		return &priceQuote{price: 1.0}, "现金价格固定为 1.0", nil
 	}
 	if symbolType == "unknown" {
 		return nil, fmt.Sprintf("无法识别标的类型: %s", symbol), ErrUnknownSymbol
@@ -197,12 +280,12 @@ func (pf *priceFetcher) fetch(symbol, currency, assetType string) (*float64, str
 			errorsList = append(errorsList, fmt.Sprintf("%s: 熔断冷却中", service))
 			continue
 		}
-		price, err := attempt.fn()
-		if err == nil && price != nil {
+		quote, err := pf.runAttempt(attempt)
+		if err == nil && quote != nil {
 			pf.recordServiceSuccess(service)
-			pf.setCached(symbol, currency, assetType, *price, service)
+			pf.setCachedQuote(symbol, currency, assetType, *quote, service)
 			msg := fmt.Sprintf("价格获取成功 (来源: %s)", service)
-			return price, msg, nil
+			return quote, msg, nil
 		}
 		if err != nil {
 			errorsList = append(errorsList, fmt.Sprintf("%s: %v", service, err))
@@ -221,7 +304,7 @@ func (pf *priceFetcher) fetch(symbol, currency, assetType string) (*float64, str
 
 type fetchAttempt struct {
 	name string
-	fn   func() (*float64, error)
+	fn   func() (*priceQuote, error)
 }
 
 func (pf *priceFetcher) buildAttempts(symbolType, symbol, currency, assetType string) []fetchAttempt {
@@ -229,65 +312,65 @@ func (pf *priceFetcher) buildAttempts(symbolType, symbol, currency, assetType st
 	case "a_share":
 		if preferFundFirstForAShare(assetType) {
 			return []fetchAttempt{
-				{"Eastmoney Fund", func() (*float64, error) { return pf.eastmoneyFetchFund(symbol) }},
-				{"Eastmoney", func() (*float64, error) { return pf.eastmoneyFetchAShare(symbol) }},
-				{"Tencent Finance", func() (*float64, error) { return pf.tencentFetchAShare(symbol) }},
-				{"Sina Finance", func() (*float64, error) { return pf.sinaFetchAShare(symbol) }},
-				{"Yahoo Finance", func() (*float64, error) { return pf.yahooFetchStock(symbol, currency) }},
+				{"Eastmoney Fund", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchFund(symbol)) }},
+				{"Eastmoney", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchAShare(symbol)) }},
+				{"Tencent Finance", func() (*priceQuote, error) { return pf.tencentFetchAShareQuote(symbol) }},
+				{"Sina Finance", func() (*priceQuote, error) { return pf.sinaFetchAShareQuote(symbol) }},
+				{"Yahoo Finance", func() (*priceQuote, error) { return pf.yahooFetchStockQuote(symbol, currency) }},
 			}
 		}
 		return []fetchAttempt{
-			{"Eastmoney", func() (*float64, error) { return pf.eastmoneyFetchAShare(symbol) }},
-			{"Tencent Finance", func() (*float64, error) { return pf.tencentFetchAShare(symbol) }},
-			{"Sina Finance", func() (*float64, error) { return pf.sinaFetchAShare(symbol) }},
-			{"Eastmoney Fund", func() (*float64, error) { return pf.eastmoneyFetchFund(symbol) }},
-			{"Yahoo Finance", func() (*float64, error) { return pf.yahooFetchStock(symbol, currency) }},
+			{"Eastmoney", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchAShare(symbol)) }},
+			{"Tencent Finance", func() (*priceQuote, error) { return pf.tencentFetchAShareQuote(symbol) }},
+			{"Sina Finance", func() (*priceQuote, error) { return pf.sinaFetchAShareQuote(symbol) }},
+			{"Eastmoney Fund", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchFund(symbol)) }},
+			{"Yahoo Finance", func() (*priceQuote, error) { return pf.yahooFetchStockQuote(symbol, currency) }},
 		}
 	case "fund", "etf":
 		return []fetchAttempt{
-			{"Eastmoney Fund GZ", func() (*float64, error) { return pf.eastmoneyFetchFund(symbol) }},
-			{"Eastmoney Fund PZ", func() (*float64, error) { return pf.eastmoneyFetchFundPingzhong(symbol) }},
-			{"Eastmoney Fund LSJZ", func() (*float64, error) { return pf.eastmoneyFetchFundLsjz(symbol) }},
-			{"Eastmoney", func() (*float64, error) { return pf.eastmoneyFetchAShare(symbol) }},
+			{"Eastmoney Fund GZ", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchFund(symbol)) }},
+			{"Eastmoney Fund PZ", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchFundPingzhong(symbol)) }},
+			{"Eastmoney Fund LSJZ", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchFundLsjz(symbol)) }},
+			{"Eastmoney", func() (*priceQuote, error) { return priceOnly(pf.eastmoneyFetchAShare(symbol)) }},
 		}
 	case "hk_connect":
 		hkCode := hkConnectToHKCode(symbol)
 		return []fetchAttempt{
-			{"Eastmoney HK Connect", func() (*float64, error) {
-				return pf.convertHKDToCNY(func() (*float64, error) {
+			{"Eastmoney HK Connect", func() (*priceQuote, error) {
+				return priceOnly(pf.convertHKDToCNY(func() (*float64, error) {
 					return pf.eastmoneyFetchHKConnect(hkCode)
-				})
+				}))
 			}},
-			{"Yahoo Finance (HK Connect)", func() (*float64, error) {
-				return pf.convertHKDToCNY(func() (*float64, error) {
+			{"Yahoo Finance (HK Connect)", func() (*priceQuote, error) {
+				return priceOnly(pf.convertHKDToCNY(func() (*float64, error) {
 					return pf.yahooFetchStock(hkCode, "HKD")
-				})
+				}))
 			}},
-			{"Sina Finance (HK Connect)", func() (*float64, error) {
-				return pf.convertHKDToCNY(func() (*float64, error) {
+			{"Sina Finance (HK Connect)", func() (*priceQuote, error) {
+				return priceOnly(pf.convertHKDToCNY(func() (*float64, error) {
 					return pf.sinaFetchHKStock(hkCode)
-				})
+				}))
 			}},
-			{"Tencent Finance (HK Connect)", func() (*float64, error) {
-				return pf.convertHKDToCNY(func() (*float64, error) {
+			{"Tencent Finance (HK Connect)", func() (*priceQuote, error) {
+				return priceOnly(pf.convertHKDToCNY(func() (*float64, error) {
 					return pf.tencentFetchHKStock(hkCode)
-				})
+				}))
 			}},
 		}
 	case "hk_stock":
 		return []fetchAttempt{
-			{"Yahoo Finance", func() (*float64, error) { return pf.yahooFetchStock(symbol, currency) }},
-			{"Sina Finance", func() (*float64, error) { return pf.sinaFetchHKStock(symbol) }},
-			{"Tencent Finance", func() (*float64, error) { return pf.tencentFetchHKStock(symbol) }},
+			{"Yahoo Finance", func() (*priceQuote, error) { return pf.yahooFetchStockQuote(symbol, currency) }},
+			{"Sina Finance", func() (*priceQuote, error) { return pf.sinaFetchHKStockQuote(symbol) }},
+			{"Tencent Finance", func() (*priceQuote, error) { return pf.tencentFetchHKStockQuote(symbol) }},
 		}
 	case "us_stock":
 		return []fetchAttempt{
-			{"Yahoo Finance", func() (*float64, error) { return pf.yahooFetchStock(symbol, currency) }},
-			{"Sina Finance", func() (*float64, error) { return pf.sinaFetchUSStock(symbol) }},
-			{"Tencent Finance", func() (*float64, error) { return pf.tencentFetchUSStock(symbol) }},
+			{"Yahoo Finance", func() (*priceQuote, error) { return pf.yahooFetchStockQuote(symbol, currency) }},
+			{"Sina Finance", func() (*priceQuote, error) { return pf.sinaFetchUSStockQuote(symbol) }},
+			{"Tencent Finance", func() (*priceQuote, error) { return pf.tencentFetchUSStockQuote(symbol) }},
 		}
 	case "gold":
-		return []fetchAttempt{{"Yahoo Finance", pf.yahooFetchGold}}
+		return []fetchAttempt{{"Yahoo Finance", func() (*priceQuote, error) { return priceOnly(pf.yahooFetchGold()) }}}
 	default:
 		return nil
 	}
@@ -298,31 +381,72 @@ func preferFundFirstForAShare(assetType string) bool {
 	return assetType != "" && assetType != "stock"
 }
 
-func (pf *priceFetcher) getCached(symbol, currency, assetType string) (float64, string, bool) {
+// cacheTTLFor returns the cache TTL for a symbol type, falling back to the global default.
+func (pf *priceFetcher) cacheTTLFor(symbolType string) time.Duration {
+	if ttl, ok := pf.cacheTTLByType[symbolType]; ok {
+		return ttl
+	}
+	return pf.cacheTTL
+}
+
+func (pf *priceFetcher) getCached(symbol, currency, assetType, symbolType string) (float64, string, bool) {
+	quote, source, ok := pf.getCachedQuote(symbol, currency, assetType, symbolType)
+	if !ok {
+		return 0, "", false
+	}
+	return quote.price, source, true
+}
+
+func (pf *priceFetcher) getCachedQuote(symbol, currency, assetType, symbolType string) (*priceQuote, string, bool) {
 	key := cacheKey(symbol, currency, assetType)
 	pf.cacheMu.RLock()
 	defer pf.cacheMu.RUnlock()
 	entry, ok := pf.cache[key]
 	if !ok {
-		return 0, "", false
+		return nil, "", false
 	}
-	if time.Since(entry.ts) <= pf.cacheTTL {
-		return entry.price, entry.source, true
+	if time.Since(entry.ts) <= pf.cacheTTLFor(symbolType) {
+		return &priceQuote{price: entry.price, previousClose: entry.previousClose}, entry.source, true
 	}
-	return 0, "", false
+	return nil, "", false
 }
 
 func (pf *priceFetcher) setCached(symbol, currency, assetType string, price float64, source string) {
+	pf.setCachedQuote(symbol, currency, assetType, priceQuote{price: price}, source)
+}
+
+func (pf *priceFetcher) setCachedQuote(symbol, currency, assetType string, quote priceQuote, source string) {
 	key := cacheKey(symbol, currency, assetType)
 	pf.cacheMu.Lock()
 	defer pf.cacheMu.Unlock()
-	pf.cache[key] = cacheEntry{price: price, source: source, ts: time.Now()}
+	pf.cache[key] = cacheEntry{price: quote.price, previousClose: quote.previousClose, source: source, ts: time.Now()}
 }
 
 func cacheKey(symbol, currency, assetType string) string {
 	return fmt.Sprintf("%s|%s|%s", symbol, currency, assetType)
 }
 
+// configureCircuit replaces the circuit-breaker parameters at runtime. Failure
+// counts already recorded are kept and judged against the new values.
+func (pf *priceFetcher) configureCircuit(threshold int, window, cooldown time.Duration) error {
+	if threshold <= 0 || window <= 0 || cooldown <= 0 {
+		return fmt.Errorf("circuit threshold, window and cooldown must be positive")
+	}
+	pf.circuitMu.Lock()
+	defer pf.circuitMu.Unlock()
+	pf.failThreshold = threshold
+	pf.failWindow = window
+	pf.cooldown = cooldown
+	return nil
+}
+
+// circuitConfig returns the current circuit-breaker parameters.
+func (pf *priceFetcher) circuitConfig() (threshold int, window, cooldown time.Duration) {
+	pf.circuitMu.Lock()
+	defer pf.circuitMu.Unlock()
+	return pf.failThreshold, pf.failWindow, pf.cooldown
+}
+
 func (pf *priceFetcher) serviceAvailable(service string) bool {
 	pf.circuitMu.Lock()
 	defer pf.circuitMu.Unlock()
@@ -358,6 +482,17 @@ func (pf *priceFetcher) recordServiceSuccess(service string) {
 	delete(pf.serviceState, service)
 }
 
+// symbolType returns the user override for symbol when one is set, and the
+// heuristic classification otherwise.
+func (pf *priceFetcher) symbolType(symbol, currency, assetType string) string {
+	if pf.typeOverride != nil {
+		if override := pf.typeOverride(normalizeSymbol(symbol), normalizeCurrency(currency)); override != "" {
+			return override
+		}
+	}
+	return detectSymbolType(symbol, currency, assetType)
+}
+
 func detectSymbolType(symbol, currency, assetType string) string {
 	symbol = normalizeSymbol(symbol)
 	currency = normalizeCurrency(currency)
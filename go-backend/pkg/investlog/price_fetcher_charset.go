@@ -0,0 +1,58 @@
+package investlog
+
+import (
+	"bytes"
+	"mime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeResponseBody converts a quote response to UTF-8. Sina and Tencent
+// answer in GBK; GB18030 is a superset of GBK and GB2312, so it decodes all
+// three. Bodies without a declared charset are decoded the same way when
+// they are not valid UTF-8. A body that fails to decode is returned as is.
+func decodeResponseBody(contentType string, body []byte) []byte {
+	body = bytes.TrimPrefix(body, utf8BOM)
+	charset := ""
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		charset = strings.ToLower(strings.TrimSpace(params["charset"]))
+	}
+	switch charset {
+	case "gbk", "gb2312", "gb18030", "x-gbk":
+	case "":
+		if utf8.Valid(body) {
+			return body
+		}
+	default:
+		return body
+	}
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(body)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+// looksLikeHTML reports whether body is an HTML document rather than the
+// JSON, JS or CSV data price sources normally return. Only the start of the
+// body is checked: some data payloads legitimately embed HTML fragments
+// (e.g. the Eastmoney fund NAV table).
+func looksLikeHTML(body []byte) bool {
+	head := bytes.TrimSpace(bytes.TrimPrefix(body, utf8BOM))
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.ToLower(head)
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+// parseQuoteField parses a numeric field of a delimited quote string,
+// ignoring surrounding whitespace and quotes.
+func parseQuoteField(field string) (float64, error) {
+	return strconv.ParseFloat(strings.Trim(strings.TrimSpace(field), `"`), 64)
+}
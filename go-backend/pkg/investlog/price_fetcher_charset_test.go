@@ -0,0 +1,75 @@
+package investlog
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func gbkString(t *testing.T, s string) string {
+	t.Helper()
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(s)
+	if err != nil {
+		t.Fatalf("encode gbk: %v", err)
+	}
+	return encoded
+}
+
+func newFetcherWithResponse(body, contentType string) *priceFetcher {
+	return newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Second,
+		FailThreshold: 2,
+		FailWindow:    time.Second,
+		Cooldown:      time.Second,
+		HTTPTimeout:   time.Second,
+		HTTPClient:    &mockHTTPClient{status: http.StatusOK, body: body, contentType: contentType},
+	})
+}
+
+func TestDecodeResponseBody(t *testing.T) {
+	t.Parallel()
+
+	raw := gbkString(t, `var hq_str_sh600000="浦发银行,10.00";`)
+	for _, contentType := range []string{"application/javascript; charset=GBK", "text/html; charset=gb2312", ""} {
+		got := string(decodeResponseBody(contentType, []byte(raw)))
+		if !strings.Contains(got, "浦发银行") {
+			t.Errorf("content type %q: expected decoded name, got %q", contentType, got)
+		}
+	}
+
+	utf8Body := "\xEF\xBB\xBF" + `v_hk00700="100~腾讯控股~00700~320.40~"`
+	got := string(decodeResponseBody("text/plain; charset=utf-8", []byte(utf8Body)))
+	if got != utf8Body[3:] {
+		t.Fatalf("expected BOM stripped and UTF-8 kept, got %q", got)
+	}
+	if got := string(decodeResponseBody("", []byte("\xEF\xBB\xBF1.5"))); got != "1.5" {
+		t.Fatalf("expected BOM stripped without charset, got %q", got)
+	}
+}
+
+func TestSinaFetchAShareQuote_GBKBody(t *testing.T) {
+	body := gbkString(t, `var hq_str_sh600000="浦发银行,10.050,9.900,10.120,10.200,9.880";`)
+	pf := newFetcherWithResponse(body, "application/javascript; charset=GBK")
+	quote, err := pf.sinaFetchAShareQuote("600000")
+	if err != nil || quote == nil {
+		t.Fatalf("sinaFetchAShareQuote: %v %v", quote, err)
+	}
+	if quote.price != 10.12 || quote.previousClose == nil || *quote.previousClose != 9.9 {
+		t.Fatalf("unexpected quote: price %v previous close %v", quote.price, quote.previousClose)
+	}
+}
+
+func TestTencentFetchHKStockQuote_GBKBodyWithoutCharset(t *testing.T) {
+	body := gbkString(t, `v_hk00700="100~腾讯控股~00700~ 320.40 ~318.00~";`)
+	pf := newFetcherWithResponse(body, "")
+	quote, err := pf.tencentFetchHKStockQuote("00700")
+	if err != nil || quote == nil {
+		t.Fatalf("tencentFetchHKStockQuote: %v %v", quote, err)
+	}
+	if quote.price != 320.4 || quote.previousClose == nil || *quote.previousClose != 318 {
+		t.Fatalf("unexpected quote: price %v previous close %v", quote.price, quote.previousClose)
+	}
+}
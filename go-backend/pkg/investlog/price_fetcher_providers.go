@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -28,7 +27,7 @@ func (pf *priceFetcher) eastmoneyFetchAShare(symbol string) (*float64, error) {
 		return nil, nil
 	}
 	url := fmt.Sprintf("http://push2.eastmoney.com/api/qt/stock/get?secid=%d.%s&fields=f43&ut=fa5fd1943c7b386f172d6893dbfba10b", market, code)
-	body, err := pf.httpGet(context.Background(), url, map[string]string{"User-Agent": "Mozilla/5.0", "Referer": "http://quote.eastmoney.com/"})
+	body, err := pf.httpGet(context.Background(), url, map[string]string{"Referer": "http://quote.eastmoney.com/"})
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +56,7 @@ func (pf *priceFetcher) eastmoneyFetchFund(symbol string) (*float64, error) {
 		return nil, nil
 	}
 	url := fmt.Sprintf("http://fundgz.1234567.com.cn/js/%s.js", code)
-	body, err := pf.httpGet(context.Background(), url, map[string]string{"User-Agent": "Mozilla/5.0", "Referer": "http://fund.eastmoney.com/"})
+	body, err := pf.httpGet(context.Background(), url, map[string]string{"Referer": "http://fund.eastmoney.com/"})
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +88,7 @@ func (pf *priceFetcher) eastmoneyFetchFundPingzhong(symbol string) (*float64, er
 		return nil, nil
 	}
 	url := fmt.Sprintf("http://fund.eastmoney.com/pingzhongdata/%s.js", code)
-	body, err := pf.httpGet(context.Background(), url, map[string]string{"User-Agent": "Mozilla/5.0", "Referer": "http://fund.eastmoney.com/"})
+	body, err := pf.httpGet(context.Background(), url, map[string]string{"Referer": "http://fund.eastmoney.com/"})
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +142,7 @@ func (pf *priceFetcher) eastmoneyFetchFundLsjz(symbol string) (*float64, error)
 		return nil, nil
 	}
 	url := fmt.Sprintf("http://fund.eastmoney.com/f10/F10DataApi.aspx?type=lsjz&code=%s&page=1&per=1", code)
-	body, err := pf.httpGet(context.Background(), url, map[string]string{"User-Agent": "Mozilla/5.0", "Referer": "http://fund.eastmoney.com/"})
+	body, err := pf.httpGet(context.Background(), url, map[string]string{"Referer": "http://fund.eastmoney.com/"})
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +158,10 @@ func (pf *priceFetcher) eastmoneyFetchFundLsjz(symbol string) (*float64, error)
 }
 
 func (pf *priceFetcher) yahooFetchStock(symbol, currency string) (*float64, error) {
+	return quotePrice(pf.yahooFetchStockQuote(symbol, currency))
+}
+
+func (pf *priceFetcher) yahooFetchStockQuote(symbol, currency string) (*priceQuote, error) {
 	yahooSymbols := buildYahooSymbolCandidates(symbol, currency)
 	if len(yahooSymbols) == 0 {
 		return nil, nil
@@ -166,13 +169,13 @@ func (pf *priceFetcher) yahooFetchStock(symbol, currency string) (*float64, erro
 
 	var lastErr error
 	for _, yahooSymbol := range yahooSymbols {
-		price, err := pf.yahooFetchStockByYahooSymbol(yahooSymbol)
+		quote, err := pf.yahooFetchQuoteByYahooSymbol(yahooSymbol)
 		if err != nil {
 			lastErr = err
 			continue
 		}
-		if price != nil {
-			return price, nil
+		if quote != nil {
+			return quote, nil
 		}
 	}
 
@@ -184,8 +187,14 @@ func (pf *priceFetcher) yahooFetchStock(symbol, currency string) (*float64, erro
 }
 
 func (pf *priceFetcher) yahooFetchStockByYahooSymbol(yahooSymbol string) (*float64, error) {
+	return quotePrice(pf.yahooFetchQuoteByYahooSymbol(yahooSymbol))
+}
+
+// yahooFetchQuoteByYahooSymbol reads the chart endpoint; its meta carries
+// chartPreviousClose alongside the current price.
+func (pf *priceFetcher) yahooFetchQuoteByYahooSymbol(yahooSymbol string) (*priceQuote, error) {
 	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=1d", yahooSymbol)
-	body, err := pf.httpGet(context.Background(), url, map[string]string{"User-Agent": "Mozilla/5.0"})
+	body, err := pf.httpGet(context.Background(), url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -200,10 +209,11 @@ func (pf *priceFetcher) yahooFetchStockByYahooSymbol(yahooSymbol string) (*float
 	}
 	result, _ := results[0].(map[string]any)
 	meta, _ := result["meta"].(map[string]any)
+	previousClose := parsePreviousClose(meta["chartPreviousClose"])
 	if meta != nil {
 		if price, err := parseFloat(meta["regularMarketPrice"]); err == nil {
 			if price > 0 {
-				return &price, nil
+				return &priceQuote{price: price, previousClose: previousClose}, nil
 			}
 		}
 	}
@@ -224,7 +234,7 @@ func (pf *priceFetcher) yahooFetchStockByYahooSymbol(yahooSymbol string) (*float
 	if price <= 0 {
 		return nil, nil
 	}
-	return &price, nil
+	return &priceQuote{price: price, previousClose: previousClose}, nil
 }
 
 func buildYahooSymbolCandidates(symbol, currency string) []string {
@@ -299,14 +309,17 @@ func (pf *priceFetcher) yahooFetchGold() (*float64, error) {
 	if pricePerOz <= 0 {
 		return nil, nil
 	}
-	// Convert to CNY per gram using configured exchange rate.
-	converted := pricePerOz / ouncesToGrams * pf.usdToCNYRate
-	converted = math.Round(converted*100) / 100
+	converted := pf.convertGoldPrice(pricePerOz)
 	return &converted, nil
 }
 
 // Sina Finance APIs.
 func (pf *priceFetcher) sinaFetchAShare(symbol string) (*float64, error) {
+	return quotePrice(pf.sinaFetchAShareQuote(symbol))
+}
+
+// sinaFetchAShareQuote reads name, open, previous close, price, ...
+func (pf *priceFetcher) sinaFetchAShareQuote(symbol string) (*priceQuote, error) {
 	code := normalizeSymbol(symbol)
 	prefix := "sz"
 	if strings.HasPrefix(code, "SH") || strings.HasPrefix(code, "SZ") {
@@ -326,15 +339,21 @@ func (pf *priceFetcher) sinaFetchAShare(symbol string) (*float64, error) {
 	}
 	data := strings.Split(parts[1], ",")
 	if len(data) > 3 {
-		price, err := strconv.ParseFloat(data[3], 64)
+		price, err := parseQuoteField(data[3])
 		if err == nil {
-			return &price, nil
+			return &priceQuote{price: price, previousClose: parsePreviousClose(data[2])}, nil
 		}
 	}
 	return nil, nil
 }
 
 func (pf *priceFetcher) sinaFetchHKStock(symbol string) (*float64, error) {
+	return quotePrice(pf.sinaFetchHKStockQuote(symbol))
+}
+
+// sinaFetchHKStockQuote reads English name, name, open, previous close,
+// high, low, price, ...
+func (pf *priceFetcher) sinaFetchHKStockQuote(symbol string) (*priceQuote, error) {
 	code := normalizeSymbol(symbol)
 	if len(code) < 5 {
 		code = strings.Repeat("0", 5-len(code)) + code
@@ -350,15 +369,20 @@ func (pf *priceFetcher) sinaFetchHKStock(symbol string) (*float64, error) {
 	}
 	data := strings.Split(parts[1], ",")
 	if len(data) > 6 {
-		price, err := strconv.ParseFloat(data[6], 64)
+		price, err := parseQuoteField(data[6])
 		if err == nil {
-			return &price, nil
+			return &priceQuote{price: price, previousClose: parsePreviousClose(data[3])}, nil
 		}
 	}
 	return nil, nil
 }
 
 func (pf *priceFetcher) sinaFetchUSStock(symbol string) (*float64, error) {
+	return quotePrice(pf.sinaFetchUSStockQuote(symbol))
+}
+
+// sinaFetchUSStockQuote reads name, price, ...; field 26 is the previous close.
+func (pf *priceFetcher) sinaFetchUSStockQuote(symbol string) (*priceQuote, error) {
 	code := strings.ToLower(symbol)
 	url := fmt.Sprintf("http://hq.sinajs.cn/list=gb_%s", code)
 	body, err := pf.httpGet(context.Background(), url, map[string]string{"Referer": "http://finance.sina.com.cn"})
@@ -371,16 +395,25 @@ func (pf *priceFetcher) sinaFetchUSStock(symbol string) (*float64, error) {
 	}
 	data := strings.Split(parts[1], ",")
 	if len(data) > 1 {
-		price, err := strconv.ParseFloat(data[1], 64)
+		price, err := parseQuoteField(data[1])
 		if err == nil {
-			return &price, nil
+			quote := &priceQuote{price: price}
+			if len(data) > 26 {
+				quote.previousClose = parsePreviousClose(data[26])
+			}
+			return quote, nil
 		}
 	}
 	return nil, nil
 }
 
-// Tencent Finance APIs.
+// Tencent Finance APIs. Quote fields are market, name, code, price,
+// previous close, ...
 func (pf *priceFetcher) tencentFetchAShare(symbol string) (*float64, error) {
+	return quotePrice(pf.tencentFetchAShareQuote(symbol))
+}
+
+func (pf *priceFetcher) tencentFetchAShareQuote(symbol string) (*priceQuote, error) {
 	code := normalizeSymbol(symbol)
 	prefix := "sz"
 	if strings.HasPrefix(code, "SH") || strings.HasPrefix(code, "SZ") {
@@ -396,15 +429,19 @@ func (pf *priceFetcher) tencentFetchAShare(symbol string) (*float64, error) {
 	}
 	parts := strings.Split(string(body), "~")
 	if len(parts) > 3 {
-		price, err := strconv.ParseFloat(parts[3], 64)
+		price, err := parseQuoteField(parts[3])
 		if err == nil {
-			return &price, nil
+			return &priceQuote{price: price, previousClose: tencentPreviousClose(parts)}, nil
 		}
 	}
 	return nil, nil
 }
 
 func (pf *priceFetcher) tencentFetchHKStock(symbol string) (*float64, error) {
+	return quotePrice(pf.tencentFetchHKStockQuote(symbol))
+}
+
+func (pf *priceFetcher) tencentFetchHKStockQuote(symbol string) (*priceQuote, error) {
 	code := normalizeSymbol(symbol)
 	if len(code) < 5 {
 		code = strings.Repeat("0", 5-len(code)) + code
@@ -416,15 +453,19 @@ func (pf *priceFetcher) tencentFetchHKStock(symbol string) (*float64, error) {
 	}
 	parts := strings.Split(string(body), "~")
 	if len(parts) > 3 {
-		price, err := strconv.ParseFloat(parts[3], 64)
+		price, err := parseQuoteField(parts[3])
 		if err == nil {
-			return &price, nil
+			return &priceQuote{price: price, previousClose: tencentPreviousClose(parts)}, nil
 		}
 	}
 	return nil, nil
 }
 
 func (pf *priceFetcher) tencentFetchUSStock(symbol string) (*float64, error) {
+	return quotePrice(pf.tencentFetchUSStockQuote(symbol))
+}
+
+func (pf *priceFetcher) tencentFetchUSStockQuote(symbol string) (*priceQuote, error) {
 	code := normalizeSymbol(symbol)
 	url := fmt.Sprintf("http://qt.gtimg.cn/q=us%s", code)
 	body, err := pf.httpGet(context.Background(), url, nil)
@@ -433,14 +474,23 @@ func (pf *priceFetcher) tencentFetchUSStock(symbol string) (*float64, error) {
 	}
 	parts := strings.Split(string(body), "~")
 	if len(parts) > 3 {
-		price, err := strconv.ParseFloat(parts[3], 64)
+		price, err := parseQuoteField(parts[3])
 		if err == nil {
-			return &price, nil
+			return &priceQuote{price: price, previousClose: tencentPreviousClose(parts)}, nil
 		}
 	}
 	return nil, nil
 }
 
+// tencentPreviousClose reads the previous close that follows the price in
+// Tencent's "~"-separated quote.
+func tencentPreviousClose(parts []string) *float64 {
+	if len(parts) > 4 {
+		return parsePreviousClose(parts[4])
+	}
+	return nil
+}
+
 // hkConnectToHKCode strips the H prefix from a Stock Connect symbol to get the HK code.
 // e.g. "H00700" -> "00700"
 func hkConnectToHKCode(symbol string) string {
@@ -478,8 +528,7 @@ func (pf *priceFetcher) eastmoneyFetchHKConnect(hkCode string) (*float64, error)
 		hkCode,
 	)
 	body, err := pf.httpGet(context.Background(), url, map[string]string{
-		"User-Agent": "Mozilla/5.0",
-		"Referer":    "http://quote.eastmoney.com/",
+		"Referer": "http://quote.eastmoney.com/",
 	})
 	if err != nil {
 		return nil, err
@@ -514,6 +563,9 @@ func (pf *priceFetcher) httpGet(ctx context.Context, url string, headers map[str
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	if req.Header.Get("User-Agent") == "" && pf.userAgent != "" {
+		req.Header.Set("User-Agent", pf.userAgent)
+	}
 	resp, err := pf.client.Do(req)
 	if err != nil {
 		return nil, err
@@ -523,7 +575,16 @@ func (pf *priceFetcher) httpGet(ctx context.Context, url string, headers map[str
 		return nil, fmt.Errorf("http status %d", resp.StatusCode)
 	}
 	// Limit response size to prevent memory exhaustion from malicious/buggy external APIs
-	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	// A 200 HTML page is a block or captcha page; fail clearly instead of
+	// letting the parser report a confusing syntax error.
+	if looksLikeHTML(body) {
+		return nil, ErrHTMLResponse
+	}
+	return decodeResponseBody(resp.Header.Get("Content-Type"), body), nil
 }
 
 func parseFloat(value any) (float64, error) {
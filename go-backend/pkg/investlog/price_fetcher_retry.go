@@ -0,0 +1,59 @@
+package investlog
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+const defaultPriceTransientRetries = 1
+
+// priceTransientRetries applies Options.PriceTransientRetries: zero uses the
+// default and a negative value disables retries.
+func priceTransientRetries(v int) int {
+	switch {
+	case v < 0:
+		return 0
+	case v == 0:
+		return defaultPriceTransientRetries
+	default:
+		return v
+	}
+}
+
+// runAttempt calls attempt, retrying transient network errors so a momentary
+// hiccup is not recorded as a source failure. HTTP errors and empty results
+// are returned at once.
+func (pf *priceFetcher) runAttempt(attempt fetchAttempt) (*priceQuote, error) {
+	quote, err := attempt.fn()
+	for retry := 0; retry < pf.retryAttempts && isTransientFetchError(err); retry++ {
+		pf.logger.Info("retrying price source after transient error", "service", attempt.name, "err", err)
+		if pf.retryDelay > 0 {
+			time.Sleep(pf.retryDelay)
+		}
+		quote, err = attempt.fn()
+	}
+	return quote, err
+}
+
+// isTransientFetchError reports whether err is a network failure that is
+// likely to clear on its own: a reset or aborted connection, a connection
+// closed mid-response or a temporary DNS failure.
+func isTransientFetchError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	return false
+}
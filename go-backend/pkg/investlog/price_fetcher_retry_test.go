@@ -0,0 +1,128 @@
+package investlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyHTTPClient fails the first failures requests with err, then answers
+// with body.
+type flakyHTTPClient struct {
+	failures int
+	err      error
+	body     string
+	calls    int
+}
+
+func (m *flakyHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		return nil, m.err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(m.body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func newRetryTestFetcher(client HTTPDoer, retries int) *priceFetcher {
+	return newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Second,
+		FailThreshold: 1,
+		FailWindow:    time.Minute,
+		Cooldown:      time.Hour,
+		HTTPTimeout:   time.Second,
+		HTTPClient:    client,
+		RetryAttempts: retries,
+	})
+}
+
+func TestFetchQuote_RetriesTransientError(t *testing.T) {
+	client := &flakyHTTPClient{
+		failures: 1,
+		err:      &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+		body:     `{"data":{"f43":12345}}`,
+	}
+	pf := newRetryTestFetcher(client, 1)
+
+	price, msg, err := pf.fetch("600000", "CNY", "stock")
+	if err != nil || price == nil || *price != 123.45 {
+		t.Fatalf("expected price after retry, got %v %q %v", price, msg, err)
+	}
+	if !strings.Contains(msg, "Eastmoney") {
+		t.Fatalf("expected first source to succeed, got %q", msg)
+	}
+	if client.calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", client.calls)
+	}
+	if !pf.serviceAvailable("Eastmoney") {
+		t.Fatal("expected transient error not to trip the breaker")
+	}
+}
+
+func TestFetchQuote_DoesNotRetryDefinitiveError(t *testing.T) {
+	client := &flakyHTTPClient{failures: 1, err: errors.New("http status 404"), body: `{"data":{"f43":12345}}`}
+	pf := newRetryTestFetcher(client, 1)
+
+	quote, err := pf.runAttempt(fetchAttempt{"Eastmoney", func() (*priceQuote, error) {
+		return priceOnly(pf.eastmoneyFetchAShare("600000"))
+	}})
+	if err == nil || quote != nil || client.calls != 1 {
+		t.Fatalf("expected single failed call, got %v %v after %d calls", quote, err, client.calls)
+	}
+}
+
+func TestFetchQuote_RetriesDisabled(t *testing.T) {
+	client := &flakyHTTPClient{failures: 1, err: io.ErrUnexpectedEOF, body: `{"data":{"f43":12345}}`}
+	pf := newRetryTestFetcher(client, 0)
+
+	if _, err := pf.runAttempt(fetchAttempt{"Eastmoney", func() (*priceQuote, error) {
+		return priceOnly(pf.eastmoneyFetchAShare("600000"))
+	}}); err == nil || client.calls != 1 {
+		t.Fatalf("expected no retry, got %v after %d calls", err, client.calls)
+	}
+}
+
+func TestIsTransientFetchError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{fmt.Errorf("get: %w", syscall.ECONNRESET), true},
+		{io.ErrUnexpectedEOF, true},
+		{&net.DNSError{Err: "server misbehaving", Name: "hq.sinajs.cn", IsTemporary: true}, true},
+		{&net.DNSError{Err: "no such host", Name: "hq.sinajs.cn", IsNotFound: true}, false},
+		{errors.New("http status 403"), false},
+		{ErrNoData, false},
+	}
+	for _, tc := range cases {
+		if got := isTransientFetchError(tc.err); got != tc.want {
+			t.Errorf("isTransientFetchError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestPriceTransientRetries(t *testing.T) {
+	t.Parallel()
+
+	if got := priceTransientRetries(0); got != defaultPriceTransientRetries {
+		t.Fatalf("expected default retries, got %d", got)
+	}
+	if got := priceTransientRetries(-1); got != 0 {
+		t.Fatalf("expected retries disabled, got %d", got)
+	}
+	if got := priceTransientRetries(3); got != 3 {
+		t.Fatalf("expected 3 retries, got %d", got)
+	}
+}
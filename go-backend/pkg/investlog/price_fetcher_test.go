@@ -3,6 +3,7 @@ package investlog
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -14,15 +15,20 @@ import (
 
 // mockHTTPClient implements HTTPDoer for testing.
 type mockHTTPClient struct {
-	status int
-	body   string
+	status      int
+	body        string
+	contentType string
 }
 
 func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	header := make(http.Header)
+	if m.contentType != "" {
+		header.Set("Content-Type", m.contentType)
+	}
 	return &http.Response{
 		StatusCode: m.status,
 		Body:       io.NopCloser(strings.NewReader(m.body)),
-		Header:     make(http.Header),
+		Header:     header,
 	}, nil
 }
 
@@ -62,13 +68,13 @@ func TestPriceFetcherCacheAndServiceState(t *testing.T) {
 	})
 
 	pf.setCached("AAPL", "USD", "stock", 123.45, "Test")
-	if price, source, ok := pf.getCached("AAPL", "USD", "stock"); !ok || source != "Test" || price != 123.45 {
+	if price, source, ok := pf.getCached("AAPL", "USD", "stock", "us_stock"); !ok || source != "Test" || price != 123.45 {
 		t.Fatalf("expected cached price")
 	}
 
 	key := cacheKey("AAPL", "USD", "stock")
 	pf.cache[key] = cacheEntry{price: 1.23, source: "Test", ts: time.Now().Add(-2 * time.Second)}
-	if _, _, ok := pf.getCached("AAPL", "USD", "stock"); ok {
+	if _, _, ok := pf.getCached("AAPL", "USD", "stock", "us_stock"); ok {
 		t.Fatalf("expected cache miss after expiry")
 	}
 
@@ -90,6 +96,44 @@ func TestPriceFetcherCacheAndServiceState(t *testing.T) {
 	}
 }
 
+func TestPriceFetcherCacheTTLByType(t *testing.T) {
+	pf := newPriceFetcher(priceFetcherOptions{
+		CacheTTL: time.Second,
+		CacheTTLByType: map[string]time.Duration{
+			"etf":      time.Hour,
+			"us_stock": 500 * time.Millisecond,
+		},
+	})
+
+	if ttl := pf.cacheTTLFor("etf"); ttl != time.Hour {
+		t.Fatalf("expected fund TTL 1h, got %v", ttl)
+	}
+	if ttl := pf.cacheTTLFor("a_share"); ttl != time.Second {
+		t.Fatalf("expected unspecified type to use default TTL, got %v", ttl)
+	}
+
+	age := -2 * time.Second
+	pf.cache[cacheKey("110001", "CNY", "fund")] = cacheEntry{price: 1.5, source: "Test", ts: time.Now().Add(age)}
+	pf.cache[cacheKey("AAPL", "USD", "stock")] = cacheEntry{price: 200, source: "Test", ts: time.Now().Add(age)}
+	pf.cache[cacheKey("600000", "CNY", "stock")] = cacheEntry{price: 10, source: "Test", ts: time.Now().Add(age)}
+
+	if _, _, ok := pf.getCached("110001", "CNY", "fund", "etf"); !ok {
+		t.Fatalf("expected fund price to still be cached")
+	}
+	if _, _, ok := pf.getCached("AAPL", "USD", "stock", "us_stock"); ok {
+		t.Fatalf("expected US stock cache entry to expire")
+	}
+	if _, _, ok := pf.getCached("600000", "CNY", "stock", "a_share"); ok {
+		t.Fatalf("expected unspecified type to expire after default TTL")
+	}
+
+	// fetch resolves the symbol type before the cache lookup.
+	price, msg, err := pf.fetch("110001", "CNY", "fund")
+	if err != nil || price == nil || *price != 1.5 || !strings.Contains(msg, "缓存") {
+		t.Fatalf("expected cached fund price via fetch, got %v %q %v", price, msg, err)
+	}
+}
+
 func TestBuildAttemptsInvoke(t *testing.T) {
 	pf := newFetcherWithBody(http.StatusOK, "")
 	cases := []struct {
@@ -153,7 +197,7 @@ func TestPriceFetcherFetchSuccessAndCooldown(t *testing.T) {
 	if !strings.Contains(msg, "价格获取成功") {
 		t.Fatalf("expected success message")
 	}
-	if cached, _, ok := pf.getCached("AAPL", "USD", "stock"); !ok || cached != 123.45 {
+	if cached, _, ok := pf.getCached("AAPL", "USD", "stock", "us_stock"); !ok || cached != 123.45 {
 		t.Fatalf("expected cached price after success")
 	}
 
@@ -449,6 +493,47 @@ func TestTencentFetchers(t *testing.T) {
 	}
 }
 
+func TestQuoteFetchersReportPreviousClose(t *testing.T) {
+	pf := newFetcherWithBody(http.StatusOK, `{"chart":{"result":[{"meta":{"regularMarketPrice":105,"chartPreviousClose":100}}]}}`)
+	quote, err := pf.yahooFetchStockQuote("AAPL", "USD")
+	if err != nil || quote == nil || quote.price != 105 || quote.previousClose == nil || *quote.previousClose != 100 {
+		t.Fatalf("yahooFetchStockQuote: %+v %v", quote, err)
+	}
+
+	pf = newFetcherWithBody(http.StatusOK, `var hq_str_sh600000="name,10.1,10.00,10.50,d"`)
+	quote, err = pf.sinaFetchAShareQuote("600000")
+	if err != nil || quote == nil || quote.previousClose == nil || *quote.previousClose != 10 {
+		t.Fatalf("sinaFetchAShareQuote: %+v %v", quote, err)
+	}
+
+	pf = newFetcherWithBody(http.StatusOK, `1~name~600000~10.50~10.00~10.10`)
+	quote, err = pf.tencentFetchAShareQuote("600000")
+	if err != nil || quote == nil || quote.previousClose == nil || *quote.previousClose != 10 {
+		t.Fatalf("tencentFetchAShareQuote: %+v %v", quote, err)
+	}
+
+	// A missing previous close still yields the price.
+	pf = newFetcherWithBody(http.StatusOK, `{"chart":{"result":[{"meta":{"regularMarketPrice":105}}]}}`)
+	quote, err = pf.yahooFetchStockQuote("AAPL", "USD")
+	if err != nil || quote == nil || quote.price != 105 || quote.previousClose != nil {
+		t.Fatalf("yahooFetchStockQuote without previous close: %+v %v", quote, err)
+	}
+}
+
+func TestFetchQuoteCachesPreviousClose(t *testing.T) {
+	pf := newFetcherWithBody(http.StatusOK, `{"chart":{"result":[{"meta":{"regularMarketPrice":99,"chartPreviousClose":110}}]}}`)
+	quote, _, err := pf.fetchQuote("AAPL", "USD", "stock")
+	if err != nil || quote == nil || quote.previousClose == nil || *quote.previousClose != 110 {
+		t.Fatalf("fetchQuote: %+v %v", quote, err)
+	}
+
+	pf.client = &mockHTTPClient{status: http.StatusInternalServerError}
+	quote, msg, err := pf.fetchQuote("AAPL", "USD", "stock")
+	if err != nil || !strings.Contains(msg, "缓存") || quote.previousClose == nil || *quote.previousClose != 110 {
+		t.Fatalf("expected cached quote with previous close, got %+v %q %v", quote, msg, err)
+	}
+}
+
 func TestHTTPGetNon2xx(t *testing.T) {
 	pf := newFetcherWithBody(http.StatusInternalServerError, "")
 	if _, err := pf.httpGet(context.Background(), "http://example.com", nil); err == nil {
@@ -572,3 +657,156 @@ func TestParseFloatPriceFetcher(t *testing.T) {
 		}
 	}
 }
+
+func TestPriceFetcherTypeOverrideSelectsAttempts(t *testing.T) {
+	overrides := map[string]string{}
+	pf := newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Second,
+		FailThreshold: 5,
+		FailWindow:    time.Second,
+		Cooldown:      time.Second,
+		HTTPClient:    &routeHTTPClient{},
+		TypeOverride: func(symbol, currency string) string {
+			return overrides[symbol+"|"+currency]
+		},
+	})
+
+	_, msg, err := pf.fetch("600000", "CNY", "stock")
+	if err == nil {
+		t.Fatal("expected fetch to fail against empty routes")
+	}
+	if !strings.Contains(msg, "Tencent Finance") || strings.Contains(msg, "Eastmoney Fund GZ") {
+		t.Fatalf("expected a_share attempts without override, got %q", msg)
+	}
+
+	overrides["600000|CNY"] = "etf"
+	_, msg, _ = pf.fetch("600000", "CNY", "stock")
+	if !strings.Contains(msg, "Eastmoney Fund GZ") || strings.Contains(msg, "Tencent Finance") {
+		t.Fatalf("expected fund attempts with etf override, got %q", msg)
+	}
+
+	overrides["600000|CNY"] = "bond"
+	if _, _, err := pf.fetch("600000", "CNY", "stock"); !errors.Is(err, ErrBondNotSupported) {
+		t.Fatalf("expected bond override to skip fetching, got %v", err)
+	}
+}
+
+func TestPriceFetcherConfigureCircuit(t *testing.T) {
+	pf := newPriceFetcher(priceFetcherOptions{FailThreshold: 5, FailWindow: time.Minute, Cooldown: time.Minute})
+
+	pf.recordServiceFailure("Yahoo Finance")
+	pf.recordServiceFailure("Yahoo Finance")
+	if !pf.serviceAvailable("Yahoo Finance") {
+		t.Fatal("two failures should not trip a threshold of 5")
+	}
+
+	if err := pf.configureCircuit(3, time.Minute, time.Minute); err != nil {
+		t.Fatalf("configureCircuit: %v", err)
+	}
+	pf.recordServiceFailure("Yahoo Finance")
+	if pf.serviceAvailable("Yahoo Finance") {
+		t.Fatal("lowered threshold should trip on the next failure")
+	}
+
+	if threshold, window, cooldown := pf.circuitConfig(); threshold != 3 || window != time.Minute || cooldown != time.Minute {
+		t.Fatalf("unexpected circuit config: %d %s %s", threshold, window, cooldown)
+	}
+	for _, bad := range [][3]int{{0, 1, 1}, {1, 0, 1}, {1, 1, -1}} {
+		if err := pf.configureCircuit(bad[0], time.Duration(bad[1])*time.Second, time.Duration(bad[2])*time.Second); err == nil {
+			t.Fatalf("expected error for %v", bad)
+		}
+	}
+}
+
+type headerCaptureClient struct {
+	userAgents []string
+}
+
+func (c *headerCaptureClient) Do(req *http.Request) (*http.Response, error) {
+	c.userAgents = append(c.userAgents, req.Header.Get("User-Agent"))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestHTTPGetUserAgent(t *testing.T) {
+	client := &headerCaptureClient{}
+	pf := newPriceFetcher(priceFetcherOptions{
+		HTTPTimeout: time.Second,
+		HTTPClient:  client,
+		UserAgent:   "InvestLogTest/2.0",
+	})
+	ctx := context.Background()
+
+	_, err := pf.httpGet(ctx, "http://example.test/quote", map[string]string{"Referer": "http://example.test/"})
+	assertNoError(t, err, "get with configured user agent")
+	_, err = pf.httpGet(ctx, "http://example.test/quote", map[string]string{"User-Agent": "PerRequest/1.0"})
+	assertNoError(t, err, "get with per-request user agent")
+
+	defaultFetcher := newPriceFetcher(priceFetcherOptions{HTTPTimeout: time.Second, HTTPClient: client})
+	_, err = defaultFetcher.httpGet(ctx, "http://example.test/quote", nil)
+	assertNoError(t, err, "get with default user agent")
+
+	want := []string{"InvestLogTest/2.0", "PerRequest/1.0", defaultPriceUserAgent}
+	if len(client.userAgents) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(client.userAgents))
+	}
+	for i, ua := range want {
+		if client.userAgents[i] != ua {
+			t.Errorf("request %d: expected User-Agent %q, got %q", i, ua, client.userAgents[i])
+		}
+	}
+}
+
+const captchaPage = "\n<!DOCTYPE html>\n<html><head><title>验证码</title></head><body>Access denied</body></html>"
+
+func TestLooksLikeHTML(t *testing.T) {
+	cases := []struct {
+		body string
+		want bool
+	}{
+		{captchaPage, true},
+		{"<HTML><body>blocked</body></HTML>", true},
+		{`{"data":{"f43":12345}}`, false},
+		{`var apidata={ content:"<table><tr><td>2024-01-02</td><td>1.2345</td></tr></table>"};`, false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		if got := looksLikeHTML([]byte(tc.body)); got != tc.want {
+			t.Errorf("looksLikeHTML(%q) = %v, want %v", tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestPriceSourceHTMLResponse(t *testing.T) {
+	pf := newFetcherWithBody(http.StatusOK, captchaPage)
+	if _, err := pf.eastmoneyFetchAShare("600000"); !errors.Is(err, ErrHTMLResponse) {
+		t.Fatalf("eastmoney: expected ErrHTMLResponse, got %v", err)
+	}
+	if _, err := pf.yahooFetchQuoteByYahooSymbol("AAPL"); !errors.Is(err, ErrHTMLResponse) {
+		t.Fatalf("yahoo: expected ErrHTMLResponse, got %v", err)
+	}
+
+	pf = newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Second,
+		FailThreshold: 1,
+		FailWindow:    time.Minute,
+		Cooldown:      time.Hour,
+		HTTPTimeout:   time.Second,
+		HTTPClient:    &mockHTTPClient{status: http.StatusOK, body: captchaPage},
+	})
+	_, msg, err := pf.fetchQuote("AAPL", "USD", "stock")
+	if err == nil {
+		t.Fatal("expected fetch to fail on HTML responses")
+	}
+	if !strings.Contains(msg, "possible block/captcha") {
+		t.Errorf("expected descriptive HTML error, got %q", msg)
+	}
+	for _, attempt := range pf.buildAttempts("us_stock", "AAPL", "USD", "stock") {
+		if pf.serviceAvailable(attempt.name) {
+			t.Errorf("expected %s to be in cooldown after an HTML response", attempt.name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package investlog
+
+import (
+	"math"
+	"strings"
+)
+
+// Units gold prices can be quoted in.
+const (
+	GoldUnitGram  = "gram"
+	GoldUnitOunce = "ounce"
+)
+
+// normalizeGoldUnit maps the configured unit to GoldUnitGram or
+// GoldUnitOunce; anything unrecognized keeps the gram default.
+func normalizeGoldUnit(unit string) string {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "ounce", "oz", "troy_ounce":
+		return GoldUnitOunce
+	default:
+		return GoldUnitGram
+	}
+}
+
+// normalizeGoldCurrency returns the supported target currency, defaulting to CNY.
+func normalizeGoldCurrency(currency string) string {
+	currency = normalizeCurrency(currency)
+	if !contains(Currencies, currency) {
+		return "CNY"
+	}
+	return currency
+}
+
+// convertGoldPrice converts a USD per troy ounce quote to the configured
+// unit and currency, rounded to cents.
+func (pf *priceFetcher) convertGoldPrice(usdPerOunce float64) float64 {
+	price := usdPerOunce
+	if pf.goldUnit == GoldUnitGram {
+		price /= ouncesToGrams
+	}
+	if pf.goldCurrency != "USD" {
+		price *= pf.rateToCNY("USD") / pf.rateToCNY(pf.goldCurrency)
+	}
+	return math.Round(price*100) / 100
+}
+
+// rateToCNY resolves currency→CNY through rateResolver, falling back to the
+// built-in defaults when it is unset or fails.
+func (pf *priceFetcher) rateToCNY(currency string) float64 {
+	if currency == "CNY" {
+		return 1
+	}
+	if pf.rateResolver != nil {
+		if rate, err := pf.rateResolver(currency); err == nil && rate > 0 {
+			return rate
+		}
+	}
+	if currency == "HKD" {
+		return defaultHKDToCNYRate
+	}
+	return pf.usdToCNYRate
+}
@@ -0,0 +1,69 @@
+package investlog
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newGoldTestFetcher(unit, currency string, resolver func(string) (float64, error)) *priceFetcher {
+	return newPriceFetcher(priceFetcherOptions{
+		CacheTTL:     time.Second,
+		HTTPTimeout:  time.Second,
+		HTTPClient:   &mockHTTPClient{status: http.StatusOK, body: `{"chart":{"result":[{"meta":{"regularMarketPrice":2000}}]}}`},
+		RateResolver: resolver,
+		GoldUnit:     unit,
+		GoldCurrency: currency,
+	})
+}
+
+func TestYahooFetchGold_USDPerOuncePassthrough(t *testing.T) {
+	pf := newGoldTestFetcher("ounce", "USD", func(string) (float64, error) {
+		t.Fatal("USD per ounce should not need an exchange rate")
+		return 0, nil
+	})
+	price, err := pf.yahooFetchGold()
+	if err != nil || price == nil || *price != 2000 {
+		t.Fatalf("expected 2000 USD/oz, got %v %v", price, err)
+	}
+}
+
+func TestYahooFetchGold_CNYPerGramUsesRateResolver(t *testing.T) {
+	pf := newGoldTestFetcher("", "", func(currency string) (float64, error) {
+		if currency != "USD" {
+			t.Fatalf("unexpected rate lookup for %s", currency)
+		}
+		return 7.1, nil
+	})
+	price, err := pf.yahooFetchGold()
+	expected := math.Round(2000/ouncesToGrams*7.1*100) / 100
+	if err != nil || price == nil || *price != expected {
+		t.Fatalf("expected %.2f CNY/g, got %v %v", expected, price, err)
+	}
+}
+
+func TestConvertGoldPrice(t *testing.T) {
+	t.Parallel()
+
+	rates := map[string]float64{"USD": 7.0, "HKD": 0.9}
+	resolver := func(currency string) (float64, error) { return rates[currency], nil }
+
+	hkd := newGoldTestFetcher("ounce", "HKD", resolver)
+	if got, want := hkd.convertGoldPrice(2000), math.Round(2000*7.0/0.9*100)/100; got != want {
+		t.Fatalf("HKD/oz: expected %.2f, got %.2f", want, got)
+	}
+
+	failing := newGoldTestFetcher("gram", "CNY", func(string) (float64, error) { return 0, errors.New("no rate") })
+	if got, want := failing.convertGoldPrice(2000), math.Round(2000/ouncesToGrams*defaultUSDToCNYRate*100)/100; got != want {
+		t.Fatalf("resolver failure: expected fallback %.2f, got %.2f", want, got)
+	}
+
+	if unit := normalizeGoldUnit("OZ"); unit != GoldUnitOunce {
+		t.Fatalf("expected oz to map to ounce, got %q", unit)
+	}
+	if currency := normalizeGoldCurrency("eur"); currency != "CNY" {
+		t.Fatalf("expected unsupported currency to default to CNY, got %q", currency)
+	}
+}
@@ -0,0 +1,249 @@
+package investlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reFundLsjzRow matches one LSJZ row, capturing the NAV date and unit NAV.
+var reFundLsjzRow = regexp.MustCompile(`<td[^>]*>(\d{4}-\d{2}-\d{2})</td>\s*<td[^>]*>([\d.]+)</td>`)
+
+// HistoricalPrice is a symbol's closing price (or fund NAV) on one date.
+type HistoricalPrice struct {
+	Symbol    string `json:"symbol"`
+	Currency  string `json:"currency"`
+	AssetType string `json:"asset_type"`
+	Date      string `json:"date"`
+	Price     Amount `json:"price"`
+	Source    string `json:"source"`
+}
+
+// GetHistoricalPrice returns the close of symbol on date (YYYY-MM-DD). It
+// returns ErrNoData when the market did not trade that day. Prices are not
+// cached or stored, and HK Connect and gold prices are converted at the
+// current exchange rate.
+func (c *Core) GetHistoricalPrice(symbol, currency, assetType, date string) (*HistoricalPrice, error) {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	invalid := &ValidationError{}
+	if symbol == "" {
+		invalid.Add("symbol", "symbol is required")
+	}
+	if !isValidCurrency(currency) {
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+	day, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+	if err != nil {
+		invalid.Add("date", "date must be YYYY-MM-DD")
+	} else if day.Format("2006-01-02") > c.TodayISO() {
+		invalid.Add("date", "date must not be in the future")
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	price, source, err := c.price.fetchHistorical(symbol, currency, assetType, day)
+	if err != nil {
+		return nil, err
+	}
+	assetType = strings.ToLower(strings.TrimSpace(assetType))
+	if assetType == "" {
+		assetType = "stock"
+	}
+	return &HistoricalPrice{
+		Symbol:    symbol,
+		Currency:  currency,
+		AssetType: assetType,
+		Date:      day.Format("2006-01-02"),
+		Price:     NewAmount(*price),
+		Source:    source,
+	}, nil
+}
+
+// fetchHistorical returns the close of symbol on date and the source that
+// supplied it. Sources are tried in order without the cache or circuit
+// breaker; ErrNoData means every source answered but had no price that day.
+func (pf *priceFetcher) fetchHistorical(symbol, currency, assetType string, date time.Time) (*float64, string, error) {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	assetType = strings.ToLower(strings.TrimSpace(assetType))
+	if assetType == "" {
+		assetType = "stock"
+	}
+	day := date.Format("2006-01-02")
+
+	symbolType := pf.symbolType(symbol, currency, assetType)
+	switch symbolType {
+	case "bond":
+		return nil, "", ErrBondNotSupported
+	case "cash":
+		price := 1.0
+		return &price, "Cash", nil
+	case "unknown":
+		return nil, "", ErrUnknownSymbol
+	}
+
+	pf.logger.Info("fetching historical price", "symbol", symbol, "currency", currency, "date", day, "type", symbolType)
+
+	var errorsList []string
+	noData := false
+	for _, attempt := range pf.buildHistoricalAttempts(symbolType, symbol, currency, assetType, day) {
+		quote, err := pf.runAttempt(attempt)
+		if err == nil && quote != nil {
+			price := quote.price
+			return &price, attempt.name, nil
+		}
+		if err != nil {
+			errorsList = append(errorsList, fmt.Sprintf("%s: %v", attempt.name, err))
+			continue
+		}
+		noData = true
+	}
+	if noData || len(errorsList) == 0 {
+		return nil, "", ErrNoData
+	}
+	return nil, "", fmt.Errorf("historical price fetch failed: %s", strings.Join(errorsList, "; "))
+}
+
+func (pf *priceFetcher) buildHistoricalAttempts(symbolType, symbol, currency, assetType, day string) []fetchAttempt {
+	yahoo := fetchAttempt{"Yahoo Finance", func() (*priceQuote, error) {
+		return priceOnly(pf.yahooFetchHistorical(symbol, currency, day))
+	}}
+	lsjz := fetchAttempt{"Eastmoney Fund LSJZ", func() (*priceQuote, error) {
+		return priceOnly(pf.eastmoneyFetchFundLsjzOn(symbol, day))
+	}}
+	switch symbolType {
+	case "a_share":
+		if preferFundFirstForAShare(assetType) {
+			return []fetchAttempt{lsjz, yahoo}
+		}
+		return []fetchAttempt{yahoo}
+	case "fund", "etf":
+		return []fetchAttempt{lsjz, yahoo}
+	case "hk_connect":
+		hkCode := hkConnectToHKCode(symbol)
+		return []fetchAttempt{{"Yahoo Finance (HK Connect)", func() (*priceQuote, error) {
+			return priceOnly(pf.convertHKDToCNY(func() (*float64, error) {
+				return pf.yahooFetchHistorical(hkCode, "HKD", day)
+			}))
+		}}}
+	case "hk_stock", "us_stock":
+		return []fetchAttempt{yahoo}
+	case "gold":
+		return []fetchAttempt{{"Yahoo Finance", func() (*priceQuote, error) {
+			price, err := pf.yahooFetchHistorical("GC=F", "USD", day)
+			if err != nil || price == nil {
+				return nil, err
+			}
+			converted := pf.convertGoldPrice(*price)
+			return &priceQuote{price: converted}, nil
+		}}}
+	default:
+		return nil
+	}
+}
+
+// eastmoneyFetchFundLsjzOn returns the fund's unit NAV published for day.
+func (pf *priceFetcher) eastmoneyFetchFundLsjzOn(symbol, day string) (*float64, error) {
+	code := normalizeSymbol(symbol)
+	if !reSixDigit.MatchString(code) {
+		return nil, nil
+	}
+	url := fmt.Sprintf("http://fund.eastmoney.com/f10/F10DataApi.aspx?type=lsjz&code=%s&page=1&per=1&sdate=%s&edate=%s", code, day, day)
+	body, err := pf.httpGet(context.Background(), url, map[string]string{"Referer": "http://fund.eastmoney.com/"})
+	if err != nil {
+		return nil, err
+	}
+	matches := reFundLsjzRow.FindStringSubmatch(string(body))
+	if len(matches) < 3 || matches[1] != day {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				GMTOffset int64 `json:"gmtoffset"`
+			} `json:"meta"`
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Close []*float64 `json:"close"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+	} `json:"chart"`
+}
+
+func (pf *priceFetcher) yahooFetchHistorical(symbol, currency, day string) (*float64, error) {
+	var lastErr error
+	answered := false
+	for _, yahooSymbol := range buildYahooSymbolCandidates(symbol, currency) {
+		price, err := pf.yahooFetchHistoricalByYahooSymbol(yahooSymbol, day)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if price != nil {
+			return price, nil
+		}
+		answered = true
+	}
+	// A listing that answered without a bar for day means no trading, even
+	// if a fallback listing failed.
+	if answered {
+		return nil, nil
+	}
+	return nil, lastErr
+}
+
+// yahooFetchHistoricalByYahooSymbol requests daily bars around day and picks
+// the bar whose exchange-local date is day.
+func (pf *priceFetcher) yahooFetchHistoricalByYahooSymbol(yahooSymbol, day string) (*float64, error) {
+	start, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return nil, err
+	}
+	// Pad the range by a day either side so exchanges far from UTC are covered.
+	period1 := start.AddDate(0, 0, -1).Unix()
+	period2 := start.AddDate(0, 0, 2).Unix()
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&period1=%d&period2=%d", yahooSymbol, period1, period2)
+	body, err := pf.httpGet(context.Background(), url, nil)
+	if err != nil {
+		return nil, err
+	}
+	var payload yahooChartResponse
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if len(payload.Chart.Result) == 0 {
+		return nil, nil
+	}
+	result := payload.Chart.Result[0]
+	if len(result.Indicators.Quote) == 0 {
+		return nil, nil
+	}
+	closes := result.Indicators.Quote[0].Close
+	zone := time.FixedZone("exchange", int(result.Meta.GMTOffset))
+	for i, ts := range result.Timestamp {
+		if i >= len(closes) || closes[i] == nil || *closes[i] <= 0 {
+			continue
+		}
+		if time.Unix(ts, 0).In(zone).Format("2006-01-02") == day {
+			price := *closes[i]
+			return &price, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,101 @@
+package investlog
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const historicalChartBody = `{"chart":{"result":[{"meta":{"gmtoffset":-18000},
+	"timestamp":[1704205800,1704292200],
+	"indicators":{"quote":[{"close":[185.64,184.25]}]}}]}}`
+
+func newHistoricalFetcher(routes map[string]mockHTTPClient) *priceFetcher {
+	return newPriceFetcher(priceFetcherOptions{
+		CacheTTL:    time.Second,
+		HTTPTimeout: time.Second,
+		HTTPClient:  &routeHTTPClient{routes: routes},
+	})
+}
+
+func TestFetchHistoricalYahooPicksRequestedDate(t *testing.T) {
+	pf := newHistoricalFetcher(map[string]mockHTTPClient{
+		"https://query1.finance.yahoo.com/v8/finance/chart/AAPL?interval=1d&period1=1704067200&period2=1704326400": {status: http.StatusOK, body: historicalChartBody},
+	})
+
+	price, source, err := pf.fetchHistorical("AAPL", "USD", "stock", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("fetchHistorical: %v", err)
+	}
+	if price == nil || *price != 185.64 {
+		t.Fatalf("expected 185.64, got %v", price)
+	}
+	if source != "Yahoo Finance" {
+		t.Fatalf("expected Yahoo Finance source, got %q", source)
+	}
+}
+
+func TestFetchHistoricalNoTradingDay(t *testing.T) {
+	// 2024-01-01 was a holiday: the padded range only contains later bars.
+	pf := newHistoricalFetcher(map[string]mockHTTPClient{
+		"https://query1.finance.yahoo.com/v8/finance/chart/AAPL?interval=1d&period1=1703980800&period2=1704240000": {status: http.StatusOK, body: historicalChartBody},
+	})
+
+	_, _, err := pf.fetchHistorical("AAPL", "USD", "stock", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, ErrNoData) {
+		t.Fatalf("expected ErrNoData, got %v", err)
+	}
+}
+
+func TestFetchHistoricalFundLsjz(t *testing.T) {
+	pf := newHistoricalFetcher(map[string]mockHTTPClient{
+		"http://fund.eastmoney.com/f10/F10DataApi.aspx?type=lsjz&code=110001&page=1&per=1&sdate=2024-01-02&edate=2024-01-02": {
+			status: http.StatusOK,
+			body:   `var apidata={ content:"<table><tbody><tr><td>2024-01-02</td><td class='tor bold'>1.2340</td><td class='tor bold'>3.4560</td></tr></tbody></table>"};`,
+		},
+	})
+
+	price, source, err := pf.fetchHistorical("110001", "CNY", "fund", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("fetchHistorical: %v", err)
+	}
+	if price == nil || *price != 1.234 {
+		t.Fatalf("expected 1.234, got %v", price)
+	}
+	if source != "Eastmoney Fund LSJZ" {
+		t.Fatalf("expected LSJZ source, got %q", source)
+	}
+}
+
+func TestFetchHistoricalSourceErrors(t *testing.T) {
+	pf := newHistoricalFetcher(map[string]mockHTTPClient{})
+
+	_, _, err := pf.fetchHistorical("AAPL", "USD", "stock", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err == nil || errors.Is(err, ErrNoData) {
+		t.Fatalf("expected source error, got %v", err)
+	}
+}
+
+func TestGetHistoricalPriceValidation(t *testing.T) {
+	core := &Core{price: newHistoricalFetcher(map[string]mockHTTPClient{})}
+
+	_, err := core.GetHistoricalPrice("", "USD", "stock", "2024-13-01")
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != 2 {
+		t.Fatalf("expected symbol and date validation errors, got %v", err)
+	}
+
+	future := NowInShanghai().AddDate(0, 0, 2).Format("2006-01-02")
+	if _, err := core.GetHistoricalPrice("AAPL", "USD", "stock", future); !errors.As(err, &invalid) {
+		t.Fatalf("expected future date validation error, got %v", err)
+	}
+
+	result, err := core.GetHistoricalPrice("cash", "usd", "cash", "2024-01-02")
+	if err != nil {
+		t.Fatalf("GetHistoricalPrice cash: %v", err)
+	}
+	if result.Symbol != "CASH" || result.Currency != "USD" || result.Date != "2024-01-02" || result.Price.InexactFloat64() != 1 {
+		t.Fatalf("unexpected cash price: %+v", result)
+	}
+}
@@ -10,7 +10,7 @@ import (
 func (c *Core) UpdatePrice(symbol, currency, assetType string) (PriceResult, error) {
 	result, err := c.FetchPrice(symbol, currency, assetType)
 	if result.Price != nil {
-		_ = c.UpdateLatestPrice(symbol, currency, *result.Price)
+		_ = c.updateLatestQuote(symbol, currency, *result.Price, result.PreviousClose)
 		_, _ = c.AddOperationLog(OperationLog{
 			Operation:    "PRICE_UPDATE",
 			Symbol:       stringPtr(normalizeSymbol(symbol)),
@@ -44,15 +44,90 @@ func (c *Core) ManualUpdatePrice(symbol, currency string, price Amount) error {
 	return nil
 }
 
-// UpdateAllPrices updates all auto-update symbols within a currency.
+// ManualPrice is one entry of a bulk manual price update.
+type ManualPrice struct {
+	Symbol   string
+	Currency string
+	Price    Amount
+}
+
+// ManualUpdatePrices stores manual price overrides for a whole price sheet.
+// Invalid entries are skipped and reported in errors, one message per entry;
+// the valid ones are saved together. err is set only when saving fails.
+func (c *Core) ManualUpdatePrices(prices []ManualPrice) (int, []string, error) {
+	var errors []string
+	valid := make([]ManualPrice, 0, len(prices))
+	for i, p := range prices {
+		p.Symbol = normalizeSymbol(p.Symbol)
+		p.Currency = normalizeCurrency(p.Currency)
+		label := fmt.Sprintf("#%d", i+1)
+		if p.Symbol != "" {
+			label += " " + p.Symbol
+		}
+		switch {
+		case p.Symbol == "":
+			errors = append(errors, label+": symbol required")
+		case !isValidCurrency(p.Currency):
+			errors = append(errors, fmt.Sprintf("%s: invalid currency: %s", label, p.Currency))
+		case !p.Price.IsPositive():
+			errors = append(errors, label+": price must be positive")
+		default:
+			valid = append(valid, p)
+		}
+	}
+	if len(valid) == 0 {
+		return 0, errors, nil
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return 0, errors, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, p := range valid {
+		if _, err := tx.Exec(`
+			INSERT INTO latest_prices (symbol, currency, price, previous_close, updated_at)
+			VALUES (?, ?, ?, NULL, CURRENT_TIMESTAMP)
+			ON CONFLICT(symbol, currency) DO UPDATE SET
+				price = excluded.price,
+				previous_close = NULL,
+				updated_at = CURRENT_TIMESTAMP
+		`, p.Symbol, p.Currency, p.Price); err != nil {
+			return 0, errors, fmt.Errorf("save price of %s: %w", p.Symbol, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors, err
+	}
+	c.invalidateHoldingsCache()
+
+	for _, p := range valid {
+		_, _ = c.AddOperationLog(OperationLog{
+			Operation:    "MANUAL_PRICE_UPDATE",
+			Symbol:       stringPtr(p.Symbol),
+			Currency:     stringPtr(p.Currency),
+			Details:      stringPtr("Bulk manual price update"),
+			PriceFetched: amountPtr(p.Price),
+		})
+	}
+	return len(valid), errors, nil
+}
+
+// UpdateAllPrices updates all auto-update symbols within a currency,
+// including watchlist symbols that are not held. Inactive symbols are skipped.
+// Armed price alerts on the freshly fetched symbols are evaluated afterwards.
 func (c *Core) UpdateAllPrices(currency string) (int, []string, error) {
 	currency = normalizeCurrency(currency)
 	holdings, err := c.GetHoldingsBySymbol()
 	if err != nil {
 		return 0, nil, err
 	}
+	watchlist, err := c.watchlistForCurrency(currency)
+	if err != nil {
+		return 0, nil, err
+	}
 	currencyData, ok := holdings[currency]
-	if !ok {
+	if !ok && len(watchlist) == 0 {
 		return 0, nil, fmt.Errorf("currency not found")
 	}
 
@@ -63,7 +138,7 @@ func (c *Core) UpdateAllPrices(currency string) (int, []string, error) {
 	}
 	jobs := make([]symbolJob, 0, len(currencyData.Symbols))
 	for _, s := range currencyData.Symbols {
-		if s.AutoUpdate == 0 {
+		if s.AutoUpdate == 0 || s.Inactive {
 			continue
 		}
 		if recentlyUpdated(s.PriceUpdatedAt, recentThreshold) {
@@ -71,6 +146,20 @@ func (c *Core) UpdateAllPrices(currency string) (int, []string, error) {
 		}
 		jobs = append(jobs, symbolJob{symbol: s.Symbol, assetType: s.AssetType})
 	}
+	held := make(map[string]bool, len(currencyData.Symbols))
+	for _, s := range currencyData.Symbols {
+		held[s.Symbol] = true
+	}
+	inactiveSymbols, err := c.getInactiveSymbols()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, w := range watchlist {
+		if held[w.Symbol] || inactiveSymbols[w.Symbol] || recentlyUpdated(w.PriceUpdatedAt, recentThreshold) {
+			continue
+		}
+		jobs = append(jobs, symbolJob{symbol: w.Symbol, assetType: w.AssetType})
+	}
 	if len(jobs) == 0 {
 		return 0, nil, nil
 	}
@@ -89,6 +178,7 @@ func (c *Core) UpdateAllPrices(currency string) (int, []string, error) {
 				resultsCh <- updateResult{
 					symbol:  job.symbol,
 					message: result.Message,
+					price:   result.Price,
 					updated: result.Price != nil,
 					err:     err,
 				}
@@ -107,21 +197,25 @@ func (c *Core) UpdateAllPrices(currency string) (int, []string, error) {
 
 	updated := 0
 	var errors []string
+	fetched := make(map[string]Amount, len(jobs))
 	for res := range resultsCh {
 		if res.updated {
 			updated++
+			fetched[res.symbol] = *res.price
 			continue
 		}
 		if res.err != nil {
 			errors = append(errors, fmt.Sprintf("%s: %s", res.symbol, res.message))
 		}
 	}
+	c.evaluatePriceAlerts(currency, fetched)
 	return updated, errors, nil
 }
 
 type updateResult struct {
 	symbol  string
 	message string
+	price   *Amount
 	updated bool
 	err     error
 }
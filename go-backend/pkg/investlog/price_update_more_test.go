@@ -1,6 +1,9 @@
 package investlog
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+)
 
 func TestUpdatePriceAndUpdateAllPrices(t *testing.T) {
 	core, cleanup := setupTestDB(t)
@@ -52,3 +55,52 @@ func TestUpdatePriceAndUpdateAllPrices(t *testing.T) {
 		t.Fatalf("expected no errors, got %v", errors)
 	}
 }
+
+func TestUpdatePrice_StoresPreviousCloseForDayChange(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct", "Account")
+	testBuyTransaction(t, core, "AAPL", 10, 90, "USD", "acct")
+	core.price.client = &mockHTTPClient{
+		status: http.StatusOK,
+		body:   `{"chart":{"result":[{"meta":{"regularMarketPrice":105,"chartPreviousClose":100}}]}}`,
+	}
+
+	result, err := core.UpdatePrice("AAPL", "USD", "stock")
+	if err != nil {
+		t.Fatalf("UpdatePrice: %v", err)
+	}
+	if result.PreviousClose == nil || result.PreviousClose.InexactFloat64() != 100 {
+		t.Fatalf("expected previous close 100, got %v", result.PreviousClose)
+	}
+	latest, err := core.GetLatestPrice("AAPL", "USD")
+	if err != nil {
+		t.Fatalf("GetLatestPrice: %v", err)
+	}
+	if latest == nil || latest.PreviousClose == nil || latest.PreviousClose.InexactFloat64() != 100 {
+		t.Fatalf("expected stored previous close 100, got %+v", latest)
+	}
+
+	holdings, err := core.GetHoldingsBySymbol()
+	if err != nil {
+		t.Fatalf("GetHoldingsBySymbol: %v", err)
+	}
+	symbols := holdings["USD"].Symbols
+	if len(symbols) != 1 || symbols[0].DayChangePct == nil {
+		t.Fatalf("expected day change on AAPL, got %+v", symbols)
+	}
+	assertFloatEquals(t, *symbols[0].DayChangePct, 5, "day change pct")
+
+	// A manual price has no previous close, so the day change is omitted.
+	if err := core.ManualUpdatePrice("AAPL", "USD", NewAmountFromInt(120)); err != nil {
+		t.Fatalf("ManualUpdatePrice: %v", err)
+	}
+	holdings, err = core.GetHoldingsBySymbol()
+	if err != nil {
+		t.Fatalf("GetHoldingsBySymbol: %v", err)
+	}
+	if change := holdings["USD"].Symbols[0].DayChangePct; change != nil {
+		t.Fatalf("expected no day change after manual price, got %v", *change)
+	}
+}
@@ -4,15 +4,23 @@ import "database/sql"
 
 // UpdateLatestPrice inserts or updates a latest price.
 func (c *Core) UpdateLatestPrice(symbol, currency string, price Amount) error {
+	return c.updateLatestQuote(symbol, currency, price, nil)
+}
+
+// updateLatestQuote stores price with the previous close reported alongside
+// it. A nil previousClose clears any stored one, since it would no longer
+// match the new price.
+func (c *Core) updateLatestQuote(symbol, currency string, price Amount, previousClose *Amount) error {
 	symbol = normalizeSymbol(symbol)
 	currency = normalizeCurrency(currency)
 	_, err := c.db.Exec(`
-		INSERT INTO latest_prices (symbol, currency, price, updated_at)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		INSERT INTO latest_prices (symbol, currency, price, previous_close, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(symbol, currency) DO UPDATE SET
 			price = excluded.price,
+			previous_close = excluded.previous_close,
 			updated_at = CURRENT_TIMESTAMP
-	`, symbol, currency, price)
+	`, symbol, currency, price, previousClose)
 	if err != nil {
 		return err
 	}
@@ -24,9 +32,9 @@ func (c *Core) UpdateLatestPrice(symbol, currency string, price Amount) error {
 func (c *Core) GetLatestPrice(symbol, currency string) (*LatestPrice, error) {
 	symbol = normalizeSymbol(symbol)
 	currency = normalizeCurrency(currency)
-	row := c.db.QueryRow("SELECT symbol, currency, price, updated_at FROM latest_prices WHERE symbol = ? AND currency = ?", symbol, currency)
-	var p LatestPrice
-	if err := row.Scan(&p.Symbol, &p.Currency, &p.Price, &p.UpdatedAt); err != nil {
+	row := c.db.QueryRow("SELECT symbol, currency, price, previous_close, updated_at FROM latest_prices WHERE symbol = ? AND currency = ?", symbol, currency)
+	p, err := scanLatestPrice(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -37,7 +45,7 @@ func (c *Core) GetLatestPrice(symbol, currency string) (*LatestPrice, error) {
 
 // GetAllLatestPrices returns a map keyed by symbol+currency.
 func (c *Core) GetAllLatestPrices() (map[[2]string]LatestPrice, error) {
-	rows, err := c.db.Query("SELECT symbol, currency, price, updated_at FROM latest_prices")
+	rows, err := c.db.Query("SELECT symbol, currency, price, previous_close, updated_at FROM latest_prices")
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +53,8 @@ func (c *Core) GetAllLatestPrices() (map[[2]string]LatestPrice, error) {
 
 	result := map[[2]string]LatestPrice{}
 	for rows.Next() {
-		var p LatestPrice
-		if err := rows.Scan(&p.Symbol, &p.Currency, &p.Price, &p.UpdatedAt); err != nil {
+		p, err := scanLatestPrice(rows)
+		if err != nil {
 			return nil, err
 		}
 		key := [2]string{p.Symbol, p.Currency}
@@ -54,3 +62,25 @@ func (c *Core) GetAllLatestPrices() (map[[2]string]LatestPrice, error) {
 	}
 	return result, rows.Err()
 }
+
+func scanLatestPrice(scanner interface{ Scan(dest ...any) error }) (LatestPrice, error) {
+	var p LatestPrice
+	var previousClose sql.NullFloat64
+	if err := scanner.Scan(&p.Symbol, &p.Currency, &p.Price, &previousClose, &p.UpdatedAt); err != nil {
+		return LatestPrice{}, err
+	}
+	if previousClose.Valid && previousClose.Float64 > 0 {
+		p.PreviousClose = amountPtr(NewAmount(previousClose.Float64))
+	}
+	return p, nil
+}
+
+// dayChangePct is the percent change of price from previousClose, or nil
+// when there is no usable previous close.
+func dayChangePct(price Amount, previousClose *Amount) *float64 {
+	if previousClose == nil || !previousClose.IsPositive() {
+		return nil
+	}
+	pct := round2(price.Sub(previousClose.Decimal).Div(previousClose.Decimal).InexactFloat64() * 100)
+	return &pct
+}
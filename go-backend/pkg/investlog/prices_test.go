@@ -102,6 +102,38 @@ func TestManualUpdatePrice(t *testing.T) {
 	assertFloatEquals(t, price.Price, 155.00, "manual price")
 }
 
+func TestManualUpdatePrices_PartialSuccess(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	updated, errs, err := core.ManualUpdatePrices([]ManualPrice{
+		{Symbol: "aapl", Currency: "usd", Price: NewAmount(155)},
+		{Symbol: "", Currency: "USD", Price: NewAmount(1)},
+		{Symbol: "MSFT", Currency: "EUR", Price: NewAmount(300)},
+		{Symbol: "00700", Currency: "HKD", Price: NewAmount(0)},
+		{Symbol: "600000", Currency: "CNY", Price: NewAmount(10.5)},
+	})
+	assertNoError(t, err, "ManualUpdatePrices")
+	if updated != 2 {
+		t.Fatalf("expected 2 prices updated, got %d", updated)
+	}
+	if len(errs) != 3 || errs[0] != "#2: symbol required" || errs[1] != "#3 MSFT: invalid currency: EUR" || errs[2] != "#4 00700: price must be positive" {
+		t.Fatalf("unexpected per-entry errors: %q", errs)
+	}
+
+	price, err := core.GetLatestPrice("AAPL", "USD")
+	assertNoError(t, err, "GetLatestPrice AAPL")
+	if price == nil {
+		t.Fatal("expected AAPL price to exist")
+	}
+	assertFloatEquals(t, price.Price, 155, "AAPL price")
+	price, err = core.GetLatestPrice("MSFT", "EUR")
+	assertNoError(t, err, "GetLatestPrice MSFT")
+	if price != nil {
+		t.Fatalf("invalid entry should not be saved, got %+v", price)
+	}
+}
+
 func TestPriceSymbolNormalization(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -0,0 +1,69 @@
+package investlog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// fallbackBaseCurrency is the reporting currency until one is saved.
+const fallbackBaseCurrency = "CNY"
+
+// ReportingSettings holds app-wide reporting preferences.
+// DefaultBaseCurrency is used by net worth, annual performance and position
+// simulation when the request names no currency.
+type ReportingSettings struct {
+	DefaultBaseCurrency string `json:"default_base_currency"`
+}
+
+// GetReportingSettings returns the saved reporting settings, or the defaults
+// when none have been saved.
+func (c *Core) GetReportingSettings() (ReportingSettings, error) {
+	base, err := c.defaultBaseCurrency()
+	if err != nil {
+		return ReportingSettings{}, err
+	}
+	return ReportingSettings{DefaultBaseCurrency: base}, nil
+}
+
+// SetReportingSettings validates and persists settings.
+func (c *Core) SetReportingSettings(settings ReportingSettings) (ReportingSettings, error) {
+	base := normalizeCurrency(settings.DefaultBaseCurrency)
+	if !isValidCurrency(base) {
+		return ReportingSettings{}, NewValidationError("default_base_currency",
+			fmt.Sprintf("invalid currency: %s", settings.DefaultBaseCurrency))
+	}
+
+	_, err := c.db.Exec(`
+		INSERT INTO reporting_settings (id, default_base_currency, updated_at)
+		VALUES (1, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			default_base_currency = excluded.default_base_currency,
+			updated_at = CURRENT_TIMESTAMP
+	`, base)
+	if err != nil {
+		return ReportingSettings{}, fmt.Errorf("save reporting settings: %w", err)
+	}
+	return ReportingSettings{DefaultBaseCurrency: base}, nil
+}
+
+// resolveBaseCurrency normalizes currency, substituting the configured
+// default when it is empty. Validation is left to the caller.
+func (c *Core) resolveBaseCurrency(currency string) (string, error) {
+	if curr := normalizeCurrency(currency); curr != "" {
+		return curr, nil
+	}
+	return c.defaultBaseCurrency()
+}
+
+func (c *Core) defaultBaseCurrency() (string, error) {
+	var base string
+	err := c.db.QueryRow("SELECT default_base_currency FROM reporting_settings WHERE id = 1").Scan(&base)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fallbackBaseCurrency, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load reporting settings: %w", err)
+	}
+	return base, nil
+}
@@ -0,0 +1,53 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReportingSettingsDefaultBaseCurrency(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	setupNetWorthHoldings(t, core)
+
+	settings, err := core.GetReportingSettings()
+	assertNoError(t, err, "GetReportingSettings")
+	if settings.DefaultBaseCurrency != "CNY" {
+		t.Fatalf("expected CNY before any setting is saved, got %q", settings.DefaultBaseCurrency)
+	}
+
+	settings, err = core.SetReportingSettings(ReportingSettings{DefaultBaseCurrency: " usd "})
+	assertNoError(t, err, "SetReportingSettings")
+	if settings.DefaultBaseCurrency != "USD" {
+		t.Fatalf("expected normalized USD, got %q", settings.DefaultBaseCurrency)
+	}
+
+	netWorth, err := core.GetNetWorth("")
+	assertNoError(t, err, "GetNetWorth default")
+	if netWorth.BaseCurrency != "USD" || !floatEquals(netWorth.Total.InexactFloat64(), 21500.0/7, 0.01) {
+		t.Fatalf("expected net worth in USD, got %s %s", netWorth.BaseCurrency, netWorth.Total.String())
+	}
+	netWorth, err = core.GetNetWorth("HKD")
+	assertNoError(t, err, "GetNetWorth explicit")
+	if netWorth.BaseCurrency != "HKD" {
+		t.Fatalf("explicit base should win, got %s", netWorth.BaseCurrency)
+	}
+
+	performance, err := core.GetAnnualPerformance("")
+	assertNoError(t, err, "GetAnnualPerformance default")
+	if performance.Currency != "USD" {
+		t.Fatalf("expected annual performance in USD, got %s", performance.Currency)
+	}
+
+	simulation, err := core.SimulatePosition("AAPL", "", 1, 100)
+	assertNoError(t, err, "SimulatePosition default")
+	if simulation.Currency != "USD" {
+		t.Fatalf("expected simulation in USD, got %s", simulation.Currency)
+	}
+
+	_, err = core.SetReportingSettings(ReportingSettings{DefaultBaseCurrency: "EUR"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Fields["default_base_currency"] == "" {
+		t.Fatalf("expected default_base_currency validation error, got %v", err)
+	}
+}
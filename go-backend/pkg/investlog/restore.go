@@ -0,0 +1,129 @@
+package investlog
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// restoreRequiredTables are the tables a file must contain to be accepted as an
+// Invest Log database. Missing optional tables are created by migrations on open.
+var restoreRequiredTables = []string{"accounts", "symbols", "transactions"}
+
+// RestoreFromBackup replaces the database file with the SQLite backup read from r.
+// It stages the upload with StageBackup and then applies it with
+// RestoreFromStagedBackup.
+//
+// On success the Core is closed: callers must open a new Core on DBPath() to
+// serve the restored data. On error the Core is left open and unchanged.
+func (c *Core) RestoreFromBackup(r io.Reader) error {
+	staged, err := StageBackup(c.dbPath, r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(staged)
+	}()
+	return c.RestoreFromStagedBackup(staged)
+}
+
+// StageBackup writes the SQLite backup read from r to a temporary file next to
+// dbPath and validates it with PRAGMA integrity_check and a schema check. It
+// returns the file's path; the caller removes it once it is applied or
+// abandoned. Staging does not touch the database, so a server can receive and
+// check a large upload before it blocks other requests to apply it.
+func StageBackup(dbPath string, r io.Reader) (string, error) {
+	if r == nil {
+		return "", NewError(ErrCodeInvalidInput, "backup file is required")
+	}
+
+	dbPath = filepath.Clean(dbPath)
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), filepath.Base(dbPath)+".restore-*")
+	if err != nil {
+		return "", fmt.Errorf("create restore temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	staged := false
+	defer func() {
+		if !staged {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("write restore temp file: %w", err)
+	}
+	if written == 0 {
+		return "", NewError(ErrCodeInvalidInput, "backup file is empty")
+	}
+
+	if err := validateBackupDatabase(tmpPath); err != nil {
+		return "", err
+	}
+	staged = true
+	return tmpPath, nil
+}
+
+// RestoreFromStagedBackup replaces the database file with a backup returned by
+// StageBackup. The current database is copied next to the original as
+// "<db>.<timestamp>.bak" first.
+//
+// On success the Core is closed: callers must open a new Core on DBPath() to
+// serve the restored data. On error the Core is left open and unchanged.
+func (c *Core) RestoreFromStagedBackup(staged string) error {
+	backupPath := fmt.Sprintf("%s.%s.bak", c.dbPath, time.Now().Format("20060102-150405"))
+	if _, err := c.db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return fmt.Errorf("backup current database: %w", err)
+	}
+	if err := os.Rename(staged, c.dbPath); err != nil {
+		return fmt.Errorf("replace database file: %w", err)
+	}
+	c.Logger().Info("database restored from backup", "db_path", c.dbPath, "previous_backup", backupPath)
+
+	c.invalidateHoldingsCache()
+	if err := c.db.Close(); err != nil {
+		c.Logger().Warn("failed to close database after restore", "err", err)
+	}
+	return nil
+}
+
+func validateBackupDatabase(path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return WrapError(ErrCodeValidation, "backup is not a valid SQLite database", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return WrapError(ErrCodeValidation, "backup is not a valid SQLite database", err)
+	}
+	if result != "ok" {
+		return NewError(ErrCodeValidation, fmt.Sprintf("backup failed integrity check: %s", result))
+	}
+
+	var missing []string
+	for _, table := range restoreRequiredTables {
+		var name string
+		err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&name)
+		if err == sql.ErrNoRows {
+			missing = append(missing, table)
+			continue
+		}
+		if err != nil {
+			return WrapError(ErrCodeValidation, "read backup schema", err)
+		}
+	}
+	if len(missing) > 0 {
+		return NewError(ErrCodeValidation,
+			fmt.Sprintf("backup is not an Invest Log database: missing tables %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
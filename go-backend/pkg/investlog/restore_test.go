@@ -0,0 +1,97 @@
+package investlog
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestoreFromBackupRejectsInvalidFiles(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	foreignPath := filepath.Join(t.TempDir(), "foreign.db")
+	foreign, err := sql.Open("sqlite", foreignPath)
+	if err != nil {
+		t.Fatalf("open foreign db: %v", err)
+	}
+	if _, err := foreign.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)"); err != nil {
+		t.Fatalf("create foreign table: %v", err)
+	}
+	foreign.Close()
+	foreignBytes, err := os.ReadFile(foreignPath)
+	if err != nil {
+		t.Fatalf("read foreign db: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		code ErrorCode
+	}{
+		{name: "empty", data: nil, code: ErrCodeInvalidInput},
+		{name: "not sqlite", data: []byte(strings.Repeat("not a database ", 512)), code: ErrCodeValidation},
+		{name: "missing tables", data: foreignBytes, code: ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := core.RestoreFromBackup(bytes.NewReader(tt.data))
+			if !IsErrorCode(err, tt.code) {
+				t.Fatalf("expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+
+	// The live database must be untouched after rejected uploads.
+	if _, err := core.GetAccounts(); err != nil {
+		t.Fatalf("core unusable after rejected restore: %v", err)
+	}
+	matches, _ := filepath.Glob(core.DBPath() + ".*")
+	if len(matches) != 0 {
+		t.Fatalf("expected no backup or temp files, got %v", matches)
+	}
+}
+
+func TestRestoreFromBackup(t *testing.T) {
+	source, cleanupSource := setupTestDB(t)
+	defer cleanupSource()
+	testAccount(t, source, "restored", "Restored Account")
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.db")
+	if _, err := source.db.Exec("VACUUM INTO ?", snapshotPath); err != nil {
+		t.Fatalf("snapshot source db: %v", err)
+	}
+	snapshot, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	testAccount(t, core, "original", "Original Account")
+	dbPath := core.DBPath()
+
+	if err := core.RestoreFromBackup(bytes.NewReader(snapshot)); err != nil {
+		t.Fatalf("RestoreFromBackup: %v", err)
+	}
+
+	backups, _ := filepath.Glob(dbPath + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup of the previous db, got %v", backups)
+	}
+
+	reopened, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("reopen restored db: %v", err)
+	}
+	defer reopened.Close()
+	accounts, err := reopened.GetAccounts()
+	if err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].AccountID != "restored" {
+		t.Fatalf("expected restored account only, got %+v", accounts)
+	}
+}
@@ -35,12 +35,21 @@ func initDatabase(db *sql.DB) error {
 			asset_type TEXT NOT NULL DEFAULT 'stock',
 			sector TEXT,
 			exchange TEXT,
-			auto_update INTEGER DEFAULT 1
+			auto_update INTEGER DEFAULT 1,
+			inactive INTEGER NOT NULL DEFAULT 0
 		)
 	`); err != nil {
 		return err
 	}
 
+	if hasDefaultCommission, err := tableHasColumn(tx, "accounts", "default_commission"); err != nil {
+		return err
+	} else if !hasDefaultCommission {
+		if err := exec(tx, "ALTER TABLE accounts ADD COLUMN default_commission REAL"); err != nil {
+			return err
+		}
+	}
+
 	hasSymbolID, err := tableHasColumn(tx, "symbols", "id")
 	if err != nil {
 		return err
@@ -57,6 +66,13 @@ func initDatabase(db *sql.DB) error {
 			return err
 		}
 	}
+	if hasInactive, err := tableHasColumn(tx, "symbols", "inactive"); err != nil {
+		return err
+	} else if !hasInactive {
+		if err := exec(tx, "ALTER TABLE symbols ADD COLUMN inactive INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
 
 	if err := exec(tx, "DROP TRIGGER IF EXISTS trg_symbols_symbol_update"); err != nil {
 		return err
@@ -174,6 +190,7 @@ func initDatabase(db *sql.DB) error {
 			allow_new_symbols INTEGER NOT NULL DEFAULT 1 CHECK(allow_new_symbols IN (0, 1)),
 			strategy_prompt TEXT NOT NULL DEFAULT '',
 			api_key TEXT NOT NULL DEFAULT '',
+			theory_tags TEXT NOT NULL DEFAULT '[]',
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
 	`); err != nil {
@@ -191,6 +208,98 @@ func initDatabase(db *sql.DB) error {
 			return err
 		}
 	}
+	if hasTheoryTags, err := tableHasColumn(tx, "ai_settings", "theory_tags"); err != nil {
+		return err
+	} else if !hasTheoryTags {
+		if err := exec(tx, "ALTER TABLE ai_settings ADD COLUMN theory_tags TEXT NOT NULL DEFAULT '[]'"); err != nil {
+			return err
+		}
+	}
+
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS price_circuit_settings (
+			id INTEGER PRIMARY KEY CHECK(id = 1),
+			fail_threshold INTEGER NOT NULL,
+			fail_window_seconds INTEGER NOT NULL,
+			cooldown_seconds INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS reporting_settings (
+			id INTEGER PRIMARY KEY CHECK(id = 1),
+			default_base_currency TEXT NOT NULL,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+
+	// Symbols tracked without a position; symbol is free text so a watched
+	// symbol needs no row in symbols until it is first traded.
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS watchlist (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			symbol TEXT NOT NULL,
+			currency TEXT NOT NULL CHECK(currency IN ('CNY', 'USD', 'HKD')),
+			asset_type TEXT NOT NULL DEFAULT 'stock',
+			notes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(symbol, currency)
+		)
+	`); err != nil {
+		return err
+	}
+
+	// One alert per symbol, currency and direction; triggered_at is set when
+	// a bulk price update first reaches target_price.
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS price_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			symbol TEXT NOT NULL,
+			currency TEXT NOT NULL CHECK(currency IN ('CNY', 'USD', 'HKD')),
+			target_price REAL NOT NULL CHECK(target_price > 0),
+			direction TEXT NOT NULL CHECK(direction IN ('above', 'below')),
+			triggered_at DATETIME,
+			triggered_price REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(symbol, currency, direction)
+		)
+	`); err != nil {
+		return err
+	}
+
+	// Tombstones for deleted transactions. Rows keep their original id so a
+	// restore reinserts them unchanged; delete_group ties linked transfers
+	// deleted together.
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS deleted_transactions (
+			id INTEGER PRIMARY KEY,
+			transaction_date DATE NOT NULL,
+			transaction_time TIME,
+			symbol_id INTEGER NOT NULL,
+			transaction_type TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			price REAL NOT NULL,
+			total_amount REAL NOT NULL,
+			commission REAL DEFAULT 0,
+			currency TEXT,
+			account_id TEXT NOT NULL,
+			account_name TEXT,
+			notes TEXT,
+			tags TEXT,
+			linked_transaction_id INTEGER,
+			created_at DATETIME,
+			updated_at DATETIME,
+			delete_group INTEGER NOT NULL,
+			deleted_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
 
 	hasAssetTypeCheck, err := allocationSettingsHasAssetTypeCheck(tx)
 	if err != nil {
@@ -226,6 +335,22 @@ func initDatabase(db *sql.DB) error {
 		return err
 	}
 
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS ai_analysis_profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			language TEXT NOT NULL DEFAULT 'zh',
+			verbosity TEXT NOT NULL DEFAULT 'standard',
+			privacy_mode INTEGER NOT NULL DEFAULT 0,
+			theory_tags TEXT NOT NULL DEFAULT '[]',
+			fallback_text TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+
 	if err := exec(tx, `
 		CREATE TABLE IF NOT EXISTS ai_analysis_runs (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -290,6 +415,7 @@ func initDatabase(db *sql.DB) error {
 			symbol TEXT NOT NULL,
 			currency TEXT NOT NULL,
 			price REAL NOT NULL,
+			previous_close REAL,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			UNIQUE(symbol, currency)
 		)
@@ -297,6 +423,33 @@ func initDatabase(db *sql.DB) error {
 		return err
 	}
 
+	// One end-of-day holdings market value per currency and date, the
+	// valuation points time-weighted return is linked across.
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			currency TEXT NOT NULL CHECK(currency IN ('CNY', 'USD', 'HKD')),
+			snapshot_date TEXT NOT NULL,
+			market_value REAL NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(currency, snapshot_date)
+		)
+	`); err != nil {
+		return err
+	}
+
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS symbol_type_overrides (
+			symbol TEXT NOT NULL,
+			currency TEXT NOT NULL CHECK(currency IN ('CNY', 'USD', 'HKD')),
+			symbol_type TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY(symbol, currency)
+		)
+	`); err != nil {
+		return err
+	}
+
 	if err := exec(tx, `
 		CREATE TABLE IF NOT EXISTS symbol_analyses (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -318,6 +471,15 @@ func initDatabase(db *sql.DB) error {
 		return err
 	}
 
+	// Migrate: add previous_close column used for the day change of holdings.
+	if hasCol, err := tableHasColumn(tx, "latest_prices", "previous_close"); err != nil {
+		return err
+	} else if !hasCol {
+		if err := exec(tx, "ALTER TABLE latest_prices ADD COLUMN previous_close REAL"); err != nil {
+			return err
+		}
+	}
+
 	// Migrate: add external_data_summary column for AI symbol analysis.
 	if hasCol, err := tableHasColumn(tx, "symbol_analyses", "external_data_summary"); err != nil {
 		return err
@@ -327,6 +489,38 @@ func initDatabase(db *sql.DB) error {
 		}
 	}
 
+	// Migrate: add input_hash column used to reuse unchanged symbol analyses.
+	if hasCol, err := tableHasColumn(tx, "symbol_analyses", "input_hash"); err != nil {
+		return err
+	} else if !hasCol {
+		if err := exec(tx, "ALTER TABLE symbol_analyses ADD COLUMN input_hash TEXT"); err != nil {
+			return err
+		}
+	}
+
+	// symbol_analysis_ratings keeps the parsed rating of every framework output
+	// and of the synthesis ("overall"), so analyses can be queried by rating.
+	// Analyses saved before the table existed are filled in once.
+	hasRatings, err := tableExists(tx, "symbol_analysis_ratings")
+	if err != nil {
+		return err
+	}
+	if err := exec(tx, `
+		CREATE TABLE IF NOT EXISTS symbol_analysis_ratings (
+			analysis_id INTEGER NOT NULL REFERENCES symbol_analyses(id) ON DELETE CASCADE,
+			dimension TEXT NOT NULL,
+			rating TEXT NOT NULL,
+			PRIMARY KEY (analysis_id, dimension)
+		)
+	`); err != nil {
+		return err
+	}
+	if !hasRatings {
+		if err := backfillSymbolAnalysisRatings(tx); err != nil {
+			return err
+		}
+	}
+
 	if err := exec(tx, `
 		CREATE TABLE IF NOT EXISTS holdings_analyses (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -388,10 +582,13 @@ func initDatabase(db *sql.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_currency ON transactions(currency)",
 		"CREATE INDEX IF NOT EXISTS idx_symbols_asset_type ON symbols(asset_type)",
 		"CREATE INDEX IF NOT EXISTS idx_linked_txn ON transactions(linked_transaction_id)",
+		"CREATE INDEX IF NOT EXISTS idx_deleted_transactions_group ON deleted_transactions(delete_group)",
 		"CREATE INDEX IF NOT EXISTS idx_symbol_analyses_lookup ON symbol_analyses(symbol, currency, created_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_symbol_analysis_ratings_lookup ON symbol_analysis_ratings(dimension, rating)",
 		"CREATE INDEX IF NOT EXISTS idx_holdings_analyses_lookup ON holdings_analyses(currency, created_at DESC)",
 		"CREATE INDEX IF NOT EXISTS idx_ai_analysis_methods_name ON ai_analysis_methods(name)",
 		"CREATE INDEX IF NOT EXISTS idx_ai_analysis_runs_method_created ON ai_analysis_runs(method_id, created_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_operation_logs_type_created ON operation_logs(operation_type, created_at)",
 	}
 	for _, idx := range indexes {
 		if err := exec(tx, idx); err != nil {
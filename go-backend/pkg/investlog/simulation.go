@@ -0,0 +1,214 @@
+package investlog
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// ConcentrationMetrics summarizes how concentrated one currency's holdings are.
+// Percents are of the currency's total market value; HHI is the
+// Herfindahl-Hirschman index on a 0-10000 scale.
+type ConcentrationMetrics struct {
+	PositionCount      int     `json:"position_count"`
+	TopPositionPercent float64 `json:"top_position_percent"`
+	Top3Percent        float64 `json:"top3_percent"`
+	HHI                float64 `json:"hhi"`
+}
+
+// SimulatedPosition is one symbol's weight in the simulated portfolio.
+type SimulatedPosition struct {
+	Symbol      string  `json:"symbol"`
+	AssetType   string  `json:"asset_type"`
+	MarketValue Amount  `json:"market_value"`
+	Percent     float64 `json:"percent"`
+}
+
+// PositionSimulation compares a currency's allocation and concentration
+// before and after a hypothetical purchase.
+type PositionSimulation struct {
+	Symbol                string               `json:"symbol"`
+	Currency              string               `json:"currency"`
+	AssetType             string               `json:"asset_type"`
+	Quantity              Amount               `json:"quantity"`
+	Price                 Amount               `json:"price"`
+	Cost                  Amount               `json:"cost"`
+	PositionPercentBefore float64              `json:"position_percent_before"`
+	PositionPercent       float64              `json:"position_percent"`
+	Before                CurrencyAllocation   `json:"before"`
+	After                 CurrencyAllocation   `json:"after"`
+	ConcentrationBefore   ConcentrationMetrics `json:"concentration_before"`
+	ConcentrationAfter    ConcentrationMetrics `json:"concentration_after"`
+	Positions             []SimulatedPosition  `json:"positions"`
+}
+
+// SimulatePosition shows how buying quantity of symbol at price would change
+// the currency's holdings, allocation bands and concentration. Nothing is
+// written; the purchase price is used as the symbol's quote afterwards. An
+// empty currency means the configured default base currency.
+func (c *Core) SimulatePosition(symbol, currency string, quantity, price float64) (*PositionSimulation, error) {
+	symbol = normalizeSymbol(symbol)
+	currency, err := c.resolveBaseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	invalid := &ValidationError{}
+	if symbol == "" {
+		invalid.Add("symbol", "symbol is required")
+	}
+	if !isValidCurrency(currency) {
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+	if quantity <= 0 {
+		invalid.Add("quantity", "quantity must be positive")
+	}
+	if price <= 0 {
+		invalid.Add("price", "price must be positive")
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	holdings, err := c.GetHoldings("")
+	if err != nil {
+		return nil, err
+	}
+	latestPrices, err := c.GetAllLatestPrices()
+	if err != nil {
+		return nil, err
+	}
+	assetType, err := c.simulatedAssetType(symbol, currency, holdings)
+	if err != nil {
+		return nil, err
+	}
+
+	qty := NewAmount(quantity)
+	px := NewAmount(price)
+	cost := Amount{qty.Mul(px.Decimal)}
+
+	simHoldings := append(append([]Holding(nil), holdings...), Holding{
+		Symbol:      symbol,
+		Currency:    currency,
+		AssetType:   assetType,
+		TotalShares: qty,
+		TotalCost:   cost,
+		AvgCost:     px,
+	})
+	simPrices := make(map[[2]string]LatestPrice, len(latestPrices)+1)
+	for key, p := range latestPrices {
+		simPrices[key] = p
+	}
+	simPrices[[2]string{symbol, currency}] = LatestPrice{Symbol: symbol, Currency: currency, Price: px, UpdatedAt: c.NowRFC3339()}
+
+	before, err := c.buildHoldingsByCurrency(holdings, latestPrices)
+	if err != nil {
+		return nil, err
+	}
+	after, err := c.buildHoldingsByCurrency(simHoldings, simPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	beforePositions := currencyPositions(holdings, latestPrices, currency)
+	afterPositions := currencyPositions(simHoldings, simPrices, currency)
+	return &PositionSimulation{
+		Symbol:                symbol,
+		Currency:              currency,
+		AssetType:             assetType,
+		Quantity:              qty,
+		Price:                 px,
+		Cost:                  cost,
+		PositionPercentBefore: positionPercent(beforePositions, symbol),
+		PositionPercent:       positionPercent(afterPositions, symbol),
+		Before:                before[currency],
+		After:                 after[currency],
+		ConcentrationBefore:   concentrationOf(beforePositions),
+		ConcentrationAfter:    concentrationOf(afterPositions),
+		Positions:             afterPositions,
+	}, nil
+}
+
+// simulatedAssetType reuses the asset type of an existing holding or symbol,
+// defaulting to stock for symbols never traded.
+func (c *Core) simulatedAssetType(symbol, currency string, holdings []Holding) (string, error) {
+	for _, h := range holdings {
+		if h.Symbol == symbol && h.Currency == currency && h.AssetType != "" {
+			return h.AssetType, nil
+		}
+	}
+	meta, err := c.GetSymbolMetadata(symbol)
+	if err != nil {
+		return "", err
+	}
+	if meta != nil && meta.AssetType != "" {
+		return meta.AssetType, nil
+	}
+	return "stock", nil
+}
+
+// currencyPositions merges a currency's holdings across accounts into
+// per-symbol market values, largest first.
+func currencyPositions(holdings []Holding, latestPrices map[[2]string]LatestPrice, currency string) []SimulatedPosition {
+	bySymbol := map[string]*SimulatedPosition{}
+	var total Amount
+	for _, h := range holdings {
+		if h.Currency != currency {
+			continue
+		}
+		value := holdingMarketValue(h, latestPrices)
+		total = Amount{total.Add(value.Decimal)}
+		pos, ok := bySymbol[h.Symbol]
+		if !ok {
+			assetType := h.AssetType
+			if assetType == "" {
+				assetType = "stock"
+			}
+			pos = &SimulatedPosition{Symbol: h.Symbol, AssetType: assetType}
+			bySymbol[h.Symbol] = pos
+		}
+		pos.MarketValue = Amount{pos.MarketValue.Add(value.Decimal)}
+	}
+
+	positions := make([]SimulatedPosition, 0, len(bySymbol))
+	for _, pos := range bySymbol {
+		if total.IsPositive() {
+			pos.Percent = round2(pos.MarketValue.Div(total.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64())
+		}
+		positions = append(positions, *pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if !positions[i].MarketValue.Equal(positions[j].MarketValue.Decimal) {
+			return positions[i].MarketValue.GreaterThan(positions[j].MarketValue.Decimal)
+		}
+		return positions[i].Symbol < positions[j].Symbol
+	})
+	return positions
+}
+
+func positionPercent(positions []SimulatedPosition, symbol string) float64 {
+	for _, pos := range positions {
+		if pos.Symbol == symbol {
+			return pos.Percent
+		}
+	}
+	return 0
+}
+
+// concentrationOf expects positions sorted largest first.
+func concentrationOf(positions []SimulatedPosition) ConcentrationMetrics {
+	metrics := ConcentrationMetrics{PositionCount: len(positions)}
+	var hhi float64
+	for i, pos := range positions {
+		if i == 0 {
+			metrics.TopPositionPercent = pos.Percent
+		}
+		if i < 3 {
+			metrics.Top3Percent += pos.Percent
+		}
+		hhi += pos.Percent * pos.Percent
+	}
+	metrics.Top3Percent = round2(metrics.Top3Percent)
+	metrics.HHI = round2(hhi)
+	return metrics
+}
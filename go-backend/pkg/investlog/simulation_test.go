@@ -0,0 +1,98 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func allocationFor(t *testing.T, alloc CurrencyAllocation, assetType string) AllocationEntry {
+	t.Helper()
+	for _, entry := range alloc.Allocations {
+		if entry.AssetType == assetType {
+			return entry
+		}
+	}
+	t.Fatalf("asset type %s not found in %+v", assetType, alloc.Allocations)
+	return AllocationEntry{}
+}
+
+func TestSimulatePosition_PushesAssetTypeAboveBand(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-1")
+	_, err := core.AddTransaction(AddTransactionRequest{
+		Symbol:          "BND",
+		TransactionType: "BUY",
+		Quantity:        NewAmountFromInt(10),
+		Price:           NewAmountFromInt(100),
+		Currency:        "USD",
+		AccountID:       "acc-1",
+		AssetType:       "bond",
+	})
+	assertNoError(t, err, "buy bond")
+	_, err = core.SetAllocationSetting("USD", "stock", 0, 60)
+	assertNoError(t, err, "set allocation")
+
+	sim, err := core.SimulatePosition("aapl", "USD", 10, 100)
+	assertNoError(t, err, "SimulatePosition")
+
+	before := allocationFor(t, sim.Before, "stock")
+	if before.Percent != 50 || before.Warning != nil {
+		t.Fatalf("expected stock at 50%% within band before, got %+v", before)
+	}
+	after := allocationFor(t, sim.After, "stock")
+	if after.Percent != 66.67 || after.Warning == nil {
+		t.Fatalf("expected stock above band after, got %+v", after)
+	}
+	if sim.PositionPercentBefore != 50 || sim.PositionPercent != 66.67 {
+		t.Fatalf("unexpected position percent: before %v after %v", sim.PositionPercentBefore, sim.PositionPercent)
+	}
+	if sim.ConcentrationBefore.HHI != 5000 || sim.ConcentrationAfter.TopPositionPercent != 66.67 {
+		t.Fatalf("unexpected concentration: before %+v after %+v", sim.ConcentrationBefore, sim.ConcentrationAfter)
+	}
+
+	// Nothing is written.
+	holdings, err := core.GetHoldingsByCurrency()
+	assertNoError(t, err, "GetHoldingsByCurrency")
+	if got := allocationFor(t, holdings["USD"], "stock"); got.Percent != 50 {
+		t.Fatalf("simulation must not change holdings, got %+v", got)
+	}
+}
+
+func TestSimulatePosition_NewSymbolAndValidation(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	sim, err := core.SimulatePosition("MSFT", "USD", 2, 50)
+	assertNoError(t, err, "SimulatePosition")
+	if sim.AssetType != "stock" || sim.PositionPercent != 100 || sim.Cost.String() != "100" {
+		t.Fatalf("unexpected simulation for new symbol: %+v", sim)
+	}
+
+	_, err = core.SimulatePosition("", "EUR", 0, -1)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != 4 {
+		t.Fatalf("expected 4 field errors, got %v", err)
+	}
+}
+
+func TestCurrencyPositionsAndConcentration(t *testing.T) {
+	holdings := []Holding{
+		{Symbol: "AAA", Currency: "USD", AccountID: "a", TotalShares: NewAmountFromInt(1), TotalCost: NewAmountFromInt(100)},
+		{Symbol: "AAA", Currency: "USD", AccountID: "b", TotalShares: NewAmountFromInt(1), TotalCost: NewAmountFromInt(100)},
+		{Symbol: "BBB", Currency: "USD", AccountID: "a", TotalShares: NewAmountFromInt(2), TotalCost: NewAmountFromInt(50)},
+		{Symbol: "CCC", Currency: "CNY", AccountID: "a", TotalShares: NewAmountFromInt(1), TotalCost: NewAmountFromInt(999)},
+	}
+	prices := map[[2]string]LatestPrice{{"BBB", "USD"}: {Price: NewAmountFromInt(100)}}
+
+	positions := currencyPositions(holdings, prices, "USD")
+	if len(positions) != 2 || positions[0].Symbol != "AAA" || positions[1].MarketValue.String() != "200" {
+		t.Fatalf("unexpected positions: %+v", positions)
+	}
+	metrics := concentrationOf(positions)
+	if metrics.PositionCount != 2 || metrics.TopPositionPercent != 50 || metrics.Top3Percent != 100 || metrics.HHI != 5000 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+}
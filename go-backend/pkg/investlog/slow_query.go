@@ -0,0 +1,24 @@
+package investlog
+
+import "time"
+
+// trackQuery starts timing the named statement and returns the function
+// that stops it; call it once the rows have been consumed. Statements taking
+// at least Options.SlowQueryThreshold are logged at Warn.
+func (c *Core) trackQuery(name string) func() {
+	if c == nil || c.slowQueryThreshold <= 0 {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		if elapsed < c.slowQueryThreshold {
+			return
+		}
+		c.Logger().Warn("slow query",
+			"statement", name,
+			"elapsed_ms", elapsed.Milliseconds(),
+			"threshold_ms", c.slowQueryThreshold.Milliseconds(),
+		)
+	}
+}
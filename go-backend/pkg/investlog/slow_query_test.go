@@ -0,0 +1,62 @@
+package investlog
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackQuery_LogsSlowStatements(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Core{
+		logger:             slog.New(slog.NewTextHandler(&buf, nil)),
+		slowQueryThreshold: 5 * time.Millisecond,
+	}
+
+	c.trackQuery("fast.statement")()
+	if buf.Len() != 0 {
+		t.Fatalf("expected no log for fast statement, got %q", buf.String())
+	}
+
+	done := c.trackQuery("slow.statement")
+	time.Sleep(10 * time.Millisecond)
+	done()
+	logs := buf.String()
+	if !strings.Contains(logs, "level=WARN") || !strings.Contains(logs, "statement=slow.statement") {
+		t.Fatalf("expected slow query warning, got %q", logs)
+	}
+
+	buf.Reset()
+	c.slowQueryThreshold = 0
+	done = c.trackQuery("untracked.statement")
+	time.Sleep(time.Millisecond)
+	done()
+	if buf.Len() != 0 {
+		t.Fatalf("expected timing disabled without threshold, got %q", buf.String())
+	}
+}
+
+func TestGetHoldings_LogsSlowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	core, err := OpenWithOptions(Options{
+		DBPath:             filepath.Join(t.TempDir(), "test.db"),
+		Logger:             slog.New(slog.NewTextHandler(&buf, nil)),
+		SlowQueryThreshold: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("open core: %v", err)
+	}
+	defer core.Close()
+
+	testAccount(t, core, "acc-slow", "Main")
+	testBuyTransaction(t, core, "AAPL", 10, 100, "USD", "acc-slow")
+	if _, err := core.GetHoldings(""); err != nil {
+		t.Fatalf("GetHoldings failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "statement=holdings.aggregate") {
+		t.Fatalf("expected slow query log for holdings aggregation, got %q", buf.String())
+	}
+}
@@ -0,0 +1,51 @@
+package investlog
+
+import "strings"
+
+// SymbolClassification previews how a symbol would be priced.
+type SymbolClassification struct {
+	Symbol    string `json:"symbol"`
+	Currency  string `json:"currency"`
+	AssetType string `json:"asset_type"`
+	// SymbolType is the type prices are fetched for; Overridden reports that
+	// it comes from a user type override rather than detection.
+	SymbolType   string `json:"symbol_type"`
+	DetectedType string `json:"detected_type"`
+	Overridden   bool   `json:"overridden"`
+	// Sources are the price sources tried, in order. Empty for cash, bonds
+	// and unknown symbols.
+	Sources []string `json:"sources"`
+}
+
+// ClassifySymbol reports the symbol type and the price sources a fetch would
+// try, without contacting any of them.
+func (c *Core) ClassifySymbol(symbol, currency, assetType string) (*SymbolClassification, error) {
+	if normalizeSymbol(symbol) == "" {
+		return nil, NewValidationError("symbol", "symbol is required")
+	}
+	return c.price.classify(symbol, currency, assetType), nil
+}
+
+func (pf *priceFetcher) classify(symbol, currency, assetType string) *SymbolClassification {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	assetType = strings.ToLower(strings.TrimSpace(assetType))
+	if assetType == "" {
+		assetType = "stock"
+	}
+	detected := detectSymbolType(symbol, currency, assetType)
+	symbolType := pf.symbolType(symbol, currency, assetType)
+	result := &SymbolClassification{
+		Symbol:       symbol,
+		Currency:     currency,
+		AssetType:    assetType,
+		SymbolType:   symbolType,
+		DetectedType: detected,
+		Overridden:   symbolType != detected,
+		Sources:      []string{},
+	}
+	for _, attempt := range pf.buildAttempts(symbolType, symbol, currency, assetType) {
+		result.Sources = append(result.Sources, attempt.name)
+	}
+	return result
+}
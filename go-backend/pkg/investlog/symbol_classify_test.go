@@ -0,0 +1,53 @@
+package investlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPriceFetcherClassify(t *testing.T) {
+	t.Parallel()
+
+	pf := newPriceFetcher(priceFetcherOptions{})
+	cases := []struct {
+		symbol, currency, assetType string
+		wantType                    string
+		wantSources                 string
+	}{
+		{"600000", "CNY", "stock", "a_share", "Eastmoney,Tencent Finance,Sina Finance,Eastmoney Fund,Yahoo Finance"},
+		{"h00700", "CNY", "", "hk_connect", "Eastmoney HK Connect,Yahoo Finance (HK Connect),Sina Finance (HK Connect)"},
+		{"AAPL", "USD", "stock", "us_stock", "Yahoo Finance,Sina Finance,Tencent Finance"},
+		{"110001", "CNY", "fund", "etf", "Eastmoney Fund GZ,Eastmoney Fund PZ,Eastmoney Fund LSJZ,Eastmoney"},
+		{"???", "CNY", "", "unknown", ""},
+	}
+	for _, tc := range cases {
+		got := pf.classify(tc.symbol, tc.currency, tc.assetType)
+		if got.SymbolType != tc.wantType || got.Overridden {
+			t.Errorf("%s: expected type %s, got %+v", tc.symbol, tc.wantType, got)
+		}
+		sources := strings.Join(got.Sources, ",")
+		if !strings.HasPrefix(sources, tc.wantSources) || (tc.wantSources == "") != (sources == "") {
+			t.Errorf("%s: expected sources %q, got %q", tc.symbol, tc.wantSources, sources)
+		}
+	}
+}
+
+func TestPriceFetcherClassify_Override(t *testing.T) {
+	t.Parallel()
+
+	pf := newPriceFetcher(priceFetcherOptions{
+		TypeOverride: func(symbol, currency string) string {
+			if symbol == "510300" {
+				return "a_share"
+			}
+			return ""
+		},
+	})
+	got := pf.classify("510300", "CNY", "etf")
+	if got.SymbolType != "a_share" || got.DetectedType != "etf" || !got.Overridden {
+		t.Fatalf("expected override to a_share, got %+v", got)
+	}
+	if got.AssetType != "etf" || got.Sources[0] != "Eastmoney Fund" {
+		t.Fatalf("expected fund-first A-share sources, got %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+package investlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrMetadataUnavailable indicates every metadata source failed, as opposed to
+// the sources answering without data for the symbol.
+var ErrMetadataUnavailable = errors.New("symbol metadata sources unavailable")
+
+// fetchedSymbolMetadata holds descriptive fields returned by an external source.
+// Empty fields mean the source did not provide a value.
+type fetchedSymbolMetadata struct {
+	Name     string
+	Exchange string
+	Sector   string
+}
+
+func (m fetchedSymbolMetadata) empty() bool {
+	return m.Name == "" && m.Exchange == "" && m.Sector == ""
+}
+
+// merge fills empty fields from other without overwriting existing values.
+func (m *fetchedSymbolMetadata) merge(other fetchedSymbolMetadata) {
+	if m.Name == "" {
+		m.Name = other.Name
+	}
+	if m.Exchange == "" {
+		m.Exchange = other.Exchange
+	}
+	if m.Sector == "" {
+		m.Sector = other.Sector
+	}
+}
+
+// FetchSymbolMetadata queries an external source for a symbol's name, exchange,
+// and sector, then stores whatever was found in the symbols table. Fields the
+// source does not provide keep their existing values. When the sources fail
+// the error wraps ErrMetadataUnavailable; when they answer without data it is
+// a NOT_FOUND error.
+func (c *Core) FetchSymbolMetadata(symbol, currency string) (*Symbol, error) {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	if symbol == "" {
+		return nil, NewError(ErrCodeInvalidInput, "symbol is required")
+	}
+
+	meta, err := c.price.fetchSymbolMetadata(symbol, currency)
+	if meta.empty() {
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s: %w", ErrMetadataUnavailable, symbol, err)
+		}
+		return nil, WrapError(ErrCodeNotFound, fmt.Sprintf("no metadata found for %s", symbol), ErrNoData)
+	}
+	if err != nil {
+		c.Logger().Warn("symbol metadata partially fetched", "symbol", symbol, "err", err)
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	symbolID, _, _, err := c.ensureSymbol(tx, symbol, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE symbols
+		SET name = COALESCE(?, name),
+		    exchange = COALESCE(?, exchange),
+		    sector = COALESCE(?, sector)
+		WHERE id = ?
+	`, stringPtr(meta.Name), stringPtr(meta.Exchange), stringPtr(meta.Sector), symbolID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	c.invalidateHoldingsCache()
+	return c.GetSymbolMetadata(symbol)
+}
+
+// fetchSymbolMetadata tries Yahoo quoteSummary first (which carries sector),
+// then falls back to chart metadata for name and exchange.
+func (pf *priceFetcher) fetchSymbolMetadata(symbol, currency string) (fetchedSymbolMetadata, error) {
+	var meta fetchedSymbolMetadata
+	var errs []error
+	for _, yahooSymbol := range buildYahooSymbolCandidates(symbol, currency) {
+		summary, err := pf.yahooFetchQuoteSummaryMetadata(yahooSymbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("yahoo quoteSummary %s: %w", yahooSymbol, err))
+		}
+		meta.merge(summary)
+		if meta.Name != "" && meta.Exchange != "" && meta.Sector != "" {
+			return meta, nil
+		}
+
+		chart, err := pf.yahooFetchChartMetadata(yahooSymbol)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("yahoo chart %s: %w", yahooSymbol, err))
+		}
+		meta.merge(chart)
+		if !meta.empty() {
+			return meta, errors.Join(errs...)
+		}
+	}
+	return meta, errors.Join(errs...)
+}
+
+func (pf *priceFetcher) yahooFetchQuoteSummaryMetadata(yahooSymbol string) (fetchedSymbolMetadata, error) {
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v10/finance/quoteSummary/%s?modules=price,assetProfile", yahooSymbol)
+	body, err := pf.httpGet(context.Background(), url, nil)
+	if err != nil {
+		return fetchedSymbolMetadata{}, err
+	}
+	var payload struct {
+		QuoteSummary struct {
+			Result []struct {
+				Price struct {
+					LongName     string `json:"longName"`
+					ShortName    string `json:"shortName"`
+					ExchangeName string `json:"exchangeName"`
+				} `json:"price"`
+				AssetProfile struct {
+					Sector string `json:"sector"`
+				} `json:"assetProfile"`
+			} `json:"result"`
+		} `json:"quoteSummary"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fetchedSymbolMetadata{}, err
+	}
+	if len(payload.QuoteSummary.Result) == 0 {
+		return fetchedSymbolMetadata{}, nil
+	}
+	r := payload.QuoteSummary.Result[0]
+	return fetchedSymbolMetadata{
+		Name:     firstNonEmptyString(r.Price.LongName, r.Price.ShortName),
+		Exchange: firstNonEmptyString(r.Price.ExchangeName),
+		Sector:   firstNonEmptyString(r.AssetProfile.Sector),
+	}, nil
+}
+
+func (pf *priceFetcher) yahooFetchChartMetadata(yahooSymbol string) (fetchedSymbolMetadata, error) {
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=1d", yahooSymbol)
+	body, err := pf.httpGet(context.Background(), url, nil)
+	if err != nil {
+		return fetchedSymbolMetadata{}, err
+	}
+	var payload struct {
+		Chart struct {
+			Result []struct {
+				Meta struct {
+					LongName         string `json:"longName"`
+					ShortName        string `json:"shortName"`
+					FullExchangeName string `json:"fullExchangeName"`
+					ExchangeName     string `json:"exchangeName"`
+				} `json:"meta"`
+			} `json:"result"`
+		} `json:"chart"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fetchedSymbolMetadata{}, err
+	}
+	if len(payload.Chart.Result) == 0 {
+		return fetchedSymbolMetadata{}, nil
+	}
+	m := payload.Chart.Result[0].Meta
+	return fetchedSymbolMetadata{
+		Name:     firstNonEmptyString(m.LongName, m.ShortName),
+		Exchange: firstNonEmptyString(m.FullExchangeName, m.ExchangeName),
+	}, nil
+}
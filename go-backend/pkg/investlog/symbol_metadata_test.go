@@ -0,0 +1,109 @@
+package investlog
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFetchSymbolMetadata_PopulatesNameAndExchange(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newPriceFetcher(priceFetcherOptions{
+		CacheTTL:    time.Second,
+		HTTPTimeout: time.Second,
+		HTTPClient: &routeHTTPClient{routes: map[string]mockHTTPClient{
+			"https://query1.finance.yahoo.com/v10/finance/quoteSummary/AAPL?modules=price,assetProfile": {
+				status: http.StatusUnauthorized,
+				body:   `{"finance":{"error":{"code":"Unauthorized"}}}`,
+			},
+			"https://query1.finance.yahoo.com/v8/finance/chart/AAPL?interval=1d&range=1d": {
+				status: http.StatusOK,
+				body:   `{"chart":{"result":[{"meta":{"longName":"Apple Inc.","fullExchangeName":"NasdaqGS"}}]}}`,
+			},
+		}},
+	})
+
+	symbol, err := core.FetchSymbolMetadata("aapl", "USD")
+	assertNoError(t, err, "fetch symbol metadata")
+	if symbol == nil {
+		t.Fatalf("expected symbol to be stored")
+	}
+	if symbol.Name == nil || *symbol.Name != "Apple Inc." {
+		t.Fatalf("expected name Apple Inc., got %v", symbol.Name)
+	}
+	if symbol.Exchange == nil || *symbol.Exchange != "NasdaqGS" {
+		t.Fatalf("expected exchange NasdaqGS, got %v", symbol.Exchange)
+	}
+	if symbol.Sector != nil {
+		t.Fatalf("expected sector to stay empty, got %q", *symbol.Sector)
+	}
+}
+
+func TestFetchSymbolMetadata_KeepsExistingFieldsWhenSourceLacksThem(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc1", "Account 1")
+	testBuyTransaction(t, core, "MSFT", 10, 100, "USD", "acc1")
+	sector := "Technology"
+	if _, err := core.UpdateSymbolMetadata("MSFT", nil, nil, nil, &sector, nil); err != nil {
+		t.Fatalf("seed sector: %v", err)
+	}
+
+	core.price = newPriceFetcher(priceFetcherOptions{
+		CacheTTL:    time.Second,
+		HTTPTimeout: time.Second,
+		HTTPClient: &routeHTTPClient{routes: map[string]mockHTTPClient{
+			"https://query1.finance.yahoo.com/v10/finance/quoteSummary/MSFT?modules=price,assetProfile": {
+				status: http.StatusOK,
+				body:   `{"quoteSummary":{"result":[{"price":{"shortName":"Microsoft","exchangeName":"NMS"},"assetProfile":{}}]}}`,
+			},
+		}},
+	})
+
+	symbol, err := core.FetchSymbolMetadata("MSFT", "USD")
+	assertNoError(t, err, "fetch symbol metadata")
+	if symbol.Name == nil || *symbol.Name != "Microsoft" {
+		t.Fatalf("expected name Microsoft, got %v", symbol.Name)
+	}
+	if symbol.Exchange == nil || *symbol.Exchange != "NMS" {
+		t.Fatalf("expected exchange NMS, got %v", symbol.Exchange)
+	}
+	if symbol.Sector == nil || *symbol.Sector != "Technology" {
+		t.Fatalf("expected existing sector to be kept, got %v", symbol.Sector)
+	}
+}
+
+func TestFetchSymbolMetadata_NoData(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newFetcherWithBody(http.StatusOK, `{}`)
+
+	if _, err := core.FetchSymbolMetadata("ZZZZ", "USD"); !IsErrorCode(err, ErrCodeNotFound) {
+		t.Fatalf("expected NOT_FOUND error, got %v", err)
+	}
+	if _, err := core.FetchSymbolMetadata(" ", "USD"); !IsErrorCode(err, ErrCodeInvalidInput) {
+		t.Fatalf("expected INVALID_INPUT error, got %v", err)
+	}
+	symbol, err := core.GetSymbolMetadata("ZZZZ")
+	assertNoError(t, err, "get symbol metadata")
+	if symbol != nil {
+		t.Fatalf("expected no symbol row to be created")
+	}
+}
+
+func TestFetchSymbolMetadata_SourceFailure(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newFetcherWithBody(http.StatusServiceUnavailable, "")
+
+	_, err := core.FetchSymbolMetadata("AAPL", "USD")
+	if !errors.Is(err, ErrMetadataUnavailable) {
+		t.Fatalf("expected ErrMetadataUnavailable, got %v", err)
+	}
+}
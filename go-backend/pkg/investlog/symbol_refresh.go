@@ -0,0 +1,117 @@
+package investlog
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// symbolRefreshExternalTimeout bounds the external data step of
+// RefreshSymbolData; the scrapers behind it can be slow.
+const symbolRefreshExternalTimeout = 90 * time.Second
+
+// Overall outcomes of RefreshSymbolData.
+const (
+	SymbolRefreshOK      = "ok"
+	SymbolRefreshPartial = "partial"
+	SymbolRefreshFailed  = "failed"
+)
+
+// SymbolRefreshStep reports the outcome of one RefreshSymbolData step.
+type SymbolRefreshStep struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SymbolRefreshResult combines the outcomes of RefreshSymbolData. Status is
+// "ok" when every step succeeded, "failed" when none did and "partial"
+// otherwise.
+type SymbolRefreshResult struct {
+	Symbol       string            `json:"symbol"`
+	Currency     string            `json:"currency"`
+	Status       string            `json:"status"`
+	Price        SymbolRefreshStep `json:"price"`
+	Metadata     SymbolRefreshStep `json:"metadata"`
+	ExternalData SymbolRefreshStep `json:"external_data"`
+	// LatestPrice is the price stored by the price step.
+	LatestPrice *Amount `json:"latest_price,omitempty"`
+	// SymbolInfo is the symbol as stored after the metadata step.
+	SymbolInfo *Symbol `json:"symbol_info,omitempty"`
+}
+
+// RefreshSymbolData fetches a symbol's latest price, updates its metadata and
+// warms the external data cache used by symbol analysis. Each step runs even
+// when an earlier one fails and reports its own outcome; err is set only for
+// invalid input. The external data step stops when ctx is done.
+func (c *Core) RefreshSymbolData(ctx context.Context, symbol, currency string) (*SymbolRefreshResult, error) {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	if symbol == "" {
+		return nil, NewValidationError("symbol", "symbol is required")
+	}
+	if !isValidCurrency(currency) {
+		return nil, NewValidationError("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+
+	result := &SymbolRefreshResult{Symbol: symbol, Currency: currency}
+
+	assetType := "stock"
+	if existing, err := c.GetSymbolMetadata(symbol); err == nil && existing != nil && existing.AssetType != "" {
+		assetType = existing.AssetType
+	}
+	priceResult, err := c.UpdatePrice(symbol, currency, assetType)
+	switch {
+	case priceResult.Price != nil:
+		result.Price = SymbolRefreshStep{OK: true, Message: priceResult.Message}
+		result.LatestPrice = priceResult.Price
+	case err != nil:
+		result.Price = SymbolRefreshStep{Message: priceResult.Message, Error: err.Error()}
+	default:
+		result.Price = SymbolRefreshStep{Message: priceResult.Message, Error: ErrNoData.Error()}
+	}
+
+	if info, err := c.FetchSymbolMetadata(symbol, currency); err != nil {
+		result.Metadata = SymbolRefreshStep{Error: err.Error()}
+	} else {
+		result.Metadata = SymbolRefreshStep{OK: true, Message: "metadata updated"}
+		result.SymbolInfo = info
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, symbolRefreshExternalTimeout)
+	defer cancel()
+	symbolContext := ""
+	if contextData, err := c.buildSymbolContext(symbol, currency, ""); err == nil {
+		symbolContext, _ = contextData.aiJSON()
+	}
+	data, err := c.loadExternalData(ctx, symbol, currency, symbolContext)
+	switch {
+	case err != nil:
+		result.ExternalData = SymbolRefreshStep{Error: err.Error()}
+	case data == nil:
+		result.ExternalData = SymbolRefreshStep{Error: ErrNoData.Error()}
+	default:
+		result.ExternalData = SymbolRefreshStep{OK: true, Message: fmt.Sprintf("%d sections cached", len(data.RawSections))}
+	}
+
+	result.Status = symbolRefreshStatus(result.Price, result.Metadata, result.ExternalData)
+	c.Logger().Info("symbol data refreshed", "symbol", symbol, "currency", currency, "status", result.Status)
+	return result, nil
+}
+
+func symbolRefreshStatus(steps ...SymbolRefreshStep) string {
+	succeeded := 0
+	for _, step := range steps {
+		if step.OK {
+			succeeded++
+		}
+	}
+	switch succeeded {
+	case len(steps):
+		return SymbolRefreshOK
+	case 0:
+		return SymbolRefreshFailed
+	default:
+		return SymbolRefreshPartial
+	}
+}
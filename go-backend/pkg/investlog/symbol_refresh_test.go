@@ -0,0 +1,179 @@
+package investlog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRefreshSymbolData_MixedOutcome(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newPriceFetcher(priceFetcherOptions{
+		CacheTTL:      time.Second,
+		FailThreshold: 3,
+		FailWindow:    time.Second,
+		Cooldown:      time.Second,
+		HTTPTimeout:   time.Second,
+		HTTPClient: &routeHTTPClient{routes: map[string]mockHTTPClient{
+			"https://query1.finance.yahoo.com/v8/finance/chart/AAPL?interval=1d&range=1d": {
+				status: http.StatusOK,
+				body:   `{"chart":{"result":[{"meta":{"regularMarketPrice":190.5,"longName":"Apple Inc.","fullExchangeName":"NasdaqGS"}}]}}`,
+			},
+		}},
+	})
+	core.externalData = ExternalDataProviderFunc(func(context.Context, ExternalDataRequest) (*ExternalData, error) {
+		return nil, errors.New("news site unavailable")
+	})
+
+	result, err := core.RefreshSymbolData(context.Background(), "aapl", "usd")
+	assertNoError(t, err, "refresh symbol data")
+	if result.Symbol != "AAPL" || result.Currency != "USD" {
+		t.Fatalf("expected normalized AAPL/USD, got %s/%s", result.Symbol, result.Currency)
+	}
+	if result.Status != SymbolRefreshPartial {
+		t.Fatalf("expected partial status, got %q", result.Status)
+	}
+	if !result.Price.OK || result.LatestPrice == nil {
+		t.Fatalf("expected price step to succeed, got %+v", result.Price)
+	}
+	assertFloatEquals(t, *result.LatestPrice, 190.5, "latest price")
+	if !result.Metadata.OK || result.SymbolInfo == nil || result.SymbolInfo.Name == nil || *result.SymbolInfo.Name != "Apple Inc." {
+		t.Fatalf("expected metadata step to store the name, got %+v %+v", result.Metadata, result.SymbolInfo)
+	}
+	if result.ExternalData.OK || result.ExternalData.Error != "news site unavailable" {
+		t.Fatalf("expected external data step to report the provider error, got %+v", result.ExternalData)
+	}
+}
+
+func TestRefreshSymbolData_WarmsExternalDataCache(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newFetcherWithBody(http.StatusServiceUnavailable, "")
+	calls := 0
+	core.externalData = ExternalDataProviderFunc(func(_ context.Context, req ExternalDataRequest) (*ExternalData, error) {
+		calls++
+		return &ExternalData{Sections: []ExternalDataSection{{Source: "test", Type: "news", Content: req.Symbol + " news"}}}, nil
+	})
+
+	result, err := core.RefreshSymbolData(context.Background(), "AAPL", "USD")
+	assertNoError(t, err, "refresh symbol data")
+	if result.Status != SymbolRefreshPartial {
+		t.Fatalf("expected partial status, got %q", result.Status)
+	}
+	if result.Price.OK || result.Price.Error == "" {
+		t.Fatalf("expected price step to fail, got %+v", result.Price)
+	}
+	if result.Metadata.OK || result.Metadata.Error == "" {
+		t.Fatalf("expected metadata step to fail, got %+v", result.Metadata)
+	}
+	if !result.ExternalData.OK {
+		t.Fatalf("expected external data step to succeed, got %+v", result.ExternalData)
+	}
+
+	data := core.fetchExternalData(context.Background(), SymbolAnalysisRequest{Symbol: "AAPL", Currency: "USD"}, "")
+	if data == nil || len(data.RawSections) != 1 || data.RawSections[0].Content != "AAPL news" {
+		t.Fatalf("expected cached external data, got %+v", data)
+	}
+	if calls != 1 {
+		t.Fatalf("expected analysis to reuse the warmed cache, provider called %d times", calls)
+	}
+}
+
+func TestRefreshSymbolData_AllStepsFail(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newFetcherWithBody(http.StatusServiceUnavailable, "")
+	core.externalData = ExternalDataProviderFunc(func(context.Context, ExternalDataRequest) (*ExternalData, error) {
+		return nil, nil
+	})
+
+	result, err := core.RefreshSymbolData(context.Background(), "AAPL", "USD")
+	assertNoError(t, err, "refresh symbol data")
+	if result.Status != SymbolRefreshFailed {
+		t.Fatalf("expected failed status, got %q", result.Status)
+	}
+	if result.ExternalData.Error != ErrNoData.Error() {
+		t.Fatalf("expected no-data external step, got %+v", result.ExternalData)
+	}
+
+	if _, err := core.RefreshSymbolData(context.Background(), " ", "USD"); err == nil {
+		t.Fatal("expected error for empty symbol")
+	}
+	if _, err := core.RefreshSymbolData(context.Background(), "AAPL", "XYZ"); err == nil {
+		t.Fatal("expected error for invalid currency")
+	}
+}
+
+func TestSymbolRefreshStatus(t *testing.T) {
+	ok, failed := SymbolRefreshStep{OK: true}, SymbolRefreshStep{Error: "boom"}
+	cases := []struct {
+		steps []SymbolRefreshStep
+		want  string
+	}{
+		{[]SymbolRefreshStep{ok, ok, ok}, SymbolRefreshOK},
+		{[]SymbolRefreshStep{ok, failed, ok}, SymbolRefreshPartial},
+		{[]SymbolRefreshStep{failed, failed, failed}, SymbolRefreshFailed},
+	}
+	for _, tc := range cases {
+		if got := symbolRefreshStatus(tc.steps...); got != tc.want {
+			t.Errorf("symbolRefreshStatus(%+v) = %q, want %q", tc.steps, got, tc.want)
+		}
+	}
+}
+
+func TestExternalDataCache(t *testing.T) {
+	cache := newExternalDataCache(time.Minute)
+	cache.put("AAPL", "USD", &symbolExternalData{Symbol: "AAPL", FetchedAt: time.Now()})
+
+	got, ok := cache.get("AAPL", "USD")
+	if !ok {
+		t.Fatal("expected cached entry")
+	}
+	got.Summary = "model summary"
+	if again, _ := cache.get("AAPL", "USD"); again.Summary != "" {
+		t.Fatalf("expected callers to get a copy, cached summary is %q", again.Summary)
+	}
+	if _, ok := cache.get("AAPL", "HKD"); ok {
+		t.Fatal("expected miss for another currency")
+	}
+
+	cache.put("MSFT", "USD", &symbolExternalData{Symbol: "MSFT", FetchedAt: time.Now().Add(-2 * time.Minute)})
+	if _, ok := cache.get("MSFT", "USD"); ok {
+		t.Fatal("expected expired entry to miss")
+	}
+
+	var disabled *externalDataCache
+	disabled.put("AAPL", "USD", &symbolExternalData{})
+	if _, ok := disabled.get("AAPL", "USD"); ok {
+		t.Fatal("expected nil cache to store nothing")
+	}
+}
+
+func TestRefreshSymbolData_ExternalStepFollowsCallerContext(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	core.price = newFetcherWithBody(http.StatusServiceUnavailable, "")
+	core.externalData = ExternalDataProviderFunc(func(ctx context.Context, _ ExternalDataRequest) (*ExternalData, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result, err := core.RefreshSymbolData(ctx, "AAPL", "USD")
+	assertNoError(t, err, "refresh symbol data")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the external step to stop with the caller context, took %s", elapsed)
+	}
+	if result.ExternalData.OK || result.ExternalData.Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected the external step to report the deadline, got %+v", result.ExternalData)
+	}
+}
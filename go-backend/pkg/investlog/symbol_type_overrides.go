@@ -0,0 +1,75 @@
+package investlog
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SymbolTypes lists the classifications the price fetcher understands and
+// that SetSymbolTypeOverride accepts.
+var SymbolTypes = []string{"a_share", "etf", "fund", "hk_connect", "hk_stock", "us_stock", "gold", "cash", "bond"}
+
+// SetSymbolTypeOverride forces the price-source classification of symbol in
+// currency, for symbols the prefix heuristics get wrong (e.g. a 6-digit CNY
+// bond). An empty symbolType removes the override.
+func (c *Core) SetSymbolTypeOverride(symbol, currency, symbolType string) error {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	symbolType = strings.ToLower(strings.TrimSpace(symbolType))
+
+	invalid := &ValidationError{}
+	if symbol == "" {
+		invalid.Add("symbol", "symbol is required")
+	}
+	if !isValidCurrency(currency) {
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+	if symbolType != "" && !contains(SymbolTypes, symbolType) {
+		invalid.Add("symbol_type", fmt.Sprintf("invalid symbol_type: %s", symbolType))
+	}
+	if err := invalid.Err(); err != nil {
+		return err
+	}
+
+	if symbolType == "" {
+		if _, err := c.db.Exec(`DELETE FROM symbol_type_overrides WHERE symbol = ? AND currency = ?`, symbol, currency); err != nil {
+			return fmt.Errorf("delete symbol type override: %w", err)
+		}
+		return nil
+	}
+	_, err := c.db.Exec(`
+		INSERT INTO symbol_type_overrides (symbol, currency, symbol_type, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(symbol, currency) DO UPDATE SET symbol_type = excluded.symbol_type, updated_at = CURRENT_TIMESTAMP
+	`, symbol, currency, symbolType)
+	if err != nil {
+		return fmt.Errorf("save symbol type override: %w", err)
+	}
+	return nil
+}
+
+// GetSymbolTypeOverride returns the forced type for symbol, or "" when none is set.
+func (c *Core) GetSymbolTypeOverride(symbol, currency string) (string, error) {
+	var symbolType string
+	err := c.db.QueryRow(`SELECT symbol_type FROM symbol_type_overrides WHERE symbol = ? AND currency = ?`,
+		normalizeSymbol(symbol), normalizeCurrency(currency)).Scan(&symbolType)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("query symbol type override: %w", err)
+	}
+	return symbolType, nil
+}
+
+// symbolTypeOverride adapts GetSymbolTypeOverride for the price fetcher, which
+// falls back to detection when the lookup fails.
+func (c *Core) symbolTypeOverride(symbol, currency string) string {
+	symbolType, err := c.GetSymbolTypeOverride(symbol, currency)
+	if err != nil {
+		c.Logger().Warn("symbol type override lookup failed", "symbol", symbol, "currency", currency, "err", err)
+		return ""
+	}
+	return symbolType
+}
@@ -0,0 +1,44 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetSymbolTypeOverride(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	assertNoError(t, core.SetSymbolTypeOverride("600000", "cny", "bond"), "set override")
+	got, err := core.GetSymbolTypeOverride("600000", "CNY")
+	assertNoError(t, err, "get override")
+	if got != "bond" {
+		t.Fatalf("expected bond override, got %q", got)
+	}
+	if _, err := core.FetchPrice("600000", "CNY", "stock"); !errors.Is(err, ErrBondNotSupported) {
+		t.Fatalf("expected override to route to bond handling, got %v", err)
+	}
+
+	assertNoError(t, core.SetSymbolTypeOverride("600000", "CNY", ""), "clear override")
+	got, err = core.GetSymbolTypeOverride("600000", "CNY")
+	assertNoError(t, err, "get cleared override")
+	if got != "" {
+		t.Fatalf("expected override cleared, got %q", got)
+	}
+}
+
+func TestSetSymbolTypeOverride_Validation(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	err := core.SetSymbolTypeOverride("", "EUR", "crypto")
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	for _, field := range []string{"symbol", "currency", "symbol_type"} {
+		if invalid.Fields[field] == "" {
+			t.Errorf("expected error for %q, got %v", field, invalid.Fields)
+		}
+	}
+}
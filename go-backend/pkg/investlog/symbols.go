@@ -9,7 +9,7 @@ import (
 // GetSymbols returns all symbols.
 func (c *Core) GetSymbols() ([]Symbol, error) {
 	rows, err := c.db.Query(`
-		SELECT id, symbol, name, asset_type, sector, exchange, auto_update
+		SELECT id, symbol, name, asset_type, sector, exchange, auto_update, inactive
 		FROM symbols
 		ORDER BY symbol
 	`)
@@ -22,7 +22,7 @@ func (c *Core) GetSymbols() ([]Symbol, error) {
 	for rows.Next() {
 		var s Symbol
 		var name, sector, exchange sql.NullString
-		if err := rows.Scan(&s.ID, &s.Symbol, &name, &s.AssetType, &sector, &exchange, &s.AutoUpdate); err != nil {
+		if err := rows.Scan(&s.ID, &s.Symbol, &name, &s.AssetType, &sector, &exchange, &s.AutoUpdate, &s.Inactive); err != nil {
 			return nil, err
 		}
 		if name.Valid {
@@ -42,10 +42,10 @@ func (c *Core) GetSymbols() ([]Symbol, error) {
 // GetSymbolMetadata fetches a symbol by code.
 func (c *Core) GetSymbolMetadata(symbol string) (*Symbol, error) {
 	symbol = normalizeSymbol(symbol)
-	row := c.db.QueryRow("SELECT id, symbol, name, asset_type, sector, exchange, auto_update FROM symbols WHERE symbol = ?", symbol)
+	row := c.db.QueryRow("SELECT id, symbol, name, asset_type, sector, exchange, auto_update, inactive FROM symbols WHERE symbol = ?", symbol)
 	var s Symbol
 	var name, sector, exchange sql.NullString
-	if err := row.Scan(&s.ID, &s.Symbol, &name, &s.AssetType, &sector, &exchange, &s.AutoUpdate); err != nil {
+	if err := row.Scan(&s.ID, &s.Symbol, &name, &s.AssetType, &sector, &exchange, &s.AutoUpdate, &s.Inactive); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -193,3 +193,55 @@ func (c *Core) UpdateSymbolAutoUpdate(symbol string, autoUpdate int) (bool, erro
 	c.invalidateHoldingsCache()
 	return true, nil
 }
+
+// SetSymbolInactive marks a symbol as delisted, or active again. Inactive
+// symbols are skipped by bulk and on-demand price fetches and left out of
+// holdings analyses; their transactions and holdings are unchanged. The
+// flag is kept per symbol code, and currency must name a currency the
+// symbol is held or traded in. It reports whether such a symbol exists.
+func (c *Core) SetSymbolInactive(symbol, currency string, inactive bool) (bool, error) {
+	symbol = normalizeSymbol(symbol)
+	currency = normalizeCurrency(currency)
+	if !isValidCurrency(currency) {
+		return false, NewValidationError("currency", fmt.Sprintf("invalid currency: %s", currency))
+	}
+	flag := 0
+	if inactive {
+		flag = 1
+	}
+	result, err := c.db.Exec(
+		`UPDATE symbols SET inactive = ?
+		 WHERE symbol = ?
+		   AND EXISTS (SELECT 1 FROM transactions t WHERE t.symbol_id = symbols.id AND t.currency = ?)`,
+		flag, symbol, currency,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		c.invalidateHoldingsCache()
+	}
+	return rows > 0, nil
+}
+
+func (c *Core) getInactiveSymbols() (map[string]bool, error) {
+	rows, err := c.db.Query("SELECT symbol FROM symbols WHERE inactive = 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string]bool{}
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, err
+		}
+		result[symbol] = true
+	}
+	return result, rows.Err()
+}
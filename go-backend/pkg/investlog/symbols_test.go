@@ -1,6 +1,7 @@
 package investlog
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -243,3 +244,61 @@ func TestSymbolNormalization(t *testing.T) {
 		t.Errorf("expected normalized symbol 'AAPL', got '%s'", symbols[0].Symbol)
 	}
 }
+
+func TestSetSymbolInactive_SkipsBulkPriceUpdate(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "test-account", "Test Account")
+	testBuyTransaction(t, core, "AAPL", 10, 150, "USD", "test-account")
+	testBuyTransaction(t, core, "DLST", 10, 20, "USD", "test-account")
+	core.price.setCached("AAPL", "USD", "stock", 160, "test")
+	core.price.setCached("DLST", "USD", "stock", 1, "test")
+
+	found, err := core.SetSymbolInactive("dlst", "usd", true)
+	assertNoError(t, err, "set inactive")
+	if !found {
+		t.Fatal("expected DLST to exist")
+	}
+	if found, err := core.SetSymbolInactive("MISSING", "USD", true); err != nil || found {
+		t.Fatalf("expected unknown symbol to report not found, got %v, %v", found, err)
+	}
+	if found, err := core.SetSymbolInactive("DLST", "HKD", true); err != nil || found {
+		t.Fatalf("expected symbol not traded in HKD to report not found, got %v, %v", found, err)
+	}
+	var invalid *ValidationError
+	if _, err := core.SetSymbolInactive("DLST", "EUR", true); !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error for invalid currency, got %v", err)
+	}
+	meta, err := core.GetSymbolMetadata("DLST")
+	assertNoError(t, err, "get metadata")
+	if meta == nil || !meta.Inactive {
+		t.Fatalf("expected DLST inactive, got %+v", meta)
+	}
+
+	_, _, err = core.UpdateAllPrices("USD")
+	assertNoError(t, err, "update all prices")
+	if latest, err := core.GetLatestPrice("AAPL", "USD"); err != nil || latest == nil {
+		t.Fatalf("expected AAPL price updated, got %v, %v", latest, err)
+	}
+	if latest, err := core.GetLatestPrice("DLST", "USD"); err != nil || latest != nil {
+		t.Fatalf("expected inactive DLST skipped, got %v, %v", latest, err)
+	}
+
+	txs, err := core.GetTransactions(TransactionFilter{Symbol: "DLST"})
+	assertNoError(t, err, "get transactions")
+	if len(txs) != 1 {
+		t.Fatalf("expected DLST transaction kept, got %d", len(txs))
+	}
+	bySymbol, err := core.GetHoldingsBySymbol()
+	assertNoError(t, err, "holdings by symbol")
+	inactiveHeld := false
+	for _, h := range bySymbol["USD"].Symbols {
+		if h.Symbol == "DLST" {
+			inactiveHeld = h.Inactive
+		}
+	}
+	if !inactiveHeld {
+		t.Fatal("expected DLST holding kept and flagged inactive")
+	}
+}
@@ -0,0 +1,72 @@
+package investlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagCount is a transaction tag and how many transactions carry it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// GetAllTags returns every distinct transaction tag, sorted by name, with the
+// number of transactions tagged with it.
+func (c *Core) GetAllTags() ([]TagCount, error) {
+	rows, err := c.db.Query("SELECT tags FROM transactions WHERE tags IS NOT NULL AND TRIM(tags) != ''")
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan tags: %w", err)
+		}
+		for _, tag := range parseTags(raw) {
+			counts[tag]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tags: %w", err)
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Tag < result[j].Tag })
+	return result, nil
+}
+
+// parseTags splits a stored tags value, which is either a JSON array of
+// strings or a list delimited by commas or semicolons (ASCII or full-width).
+// Tags are trimmed and each appears at most once.
+func parseTags(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	var parts []string
+	if strings.HasPrefix(raw, "[") {
+		if err := json.Unmarshal([]byte(raw), &parts); err != nil {
+			parts = nil
+		}
+	}
+	if parts == nil {
+		parts = strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == '，' || r == ';' || r == '；'
+		})
+	}
+
+	tags := make([]string, 0, len(parts))
+	for _, part := range parts {
+		tag := strings.TrimSpace(part)
+		if tag != "" && !contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,53 @@
+package investlog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want []string
+	}{
+		{`["long", " dividend ", "long", ""]`, []string{"long", "dividend"}},
+		{"long, dividend;tech", []string{"long", "dividend", "tech"}},
+		{"长线，分红；科技", []string{"长线", "分红", "科技"}},
+		{"swing trade", []string{"swing trade"}},
+		{"[unclosed, long", []string{"[unclosed", "long"}},
+		{"  ", []string{}},
+	}
+	for _, tc := range cases {
+		if got := parseTags(tc.raw); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("parseTags(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestGetAllTags(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	for _, tags := range []string{`["long","dividend"]`, "long, tech", "tech；long；tech", ""} {
+		tags := tags
+		_, err := core.AddTransaction(AddTransactionRequest{
+			Symbol:          "AAPL",
+			TransactionType: "BUY",
+			Quantity:        NewAmount(1),
+			Price:           NewAmount(100),
+			Currency:        "USD",
+			AccountID:       "acc-1",
+			AssetType:       "stock",
+			Tags:            stringPtr(tags),
+		})
+		assertNoError(t, err, "AddTransaction")
+	}
+
+	got, err := core.GetAllTags()
+	assertNoError(t, err, "GetAllTags")
+	want := []TagCount{{Tag: "dividend", Count: 1}, {Tag: "long", Count: 3}, {Tag: "tech", Count: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetAllTags = %+v, want %+v", got, want)
+	}
+}
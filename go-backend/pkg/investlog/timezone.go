@@ -1,6 +1,10 @@
 package investlog
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 const shanghaiTimeZoneName = "Asia/Shanghai"
 
@@ -14,6 +18,19 @@ func loadShanghaiLocation() *time.Location {
 	return location
 }
 
+// loadTimeZone resolves an IANA time zone name; empty means Asia/Shanghai.
+func loadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == shanghaiTimeZoneName {
+		return shanghaiLocation, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return location, nil
+}
+
 // NowInShanghai returns current time in Asia/Shanghai.
 func NowInShanghai() time.Time {
 	return time.Now().In(shanghaiLocation)
@@ -28,3 +45,32 @@ func TodayISOInShanghai() string {
 func NowRFC3339InShanghai() string {
 	return NowInShanghai().Format(time.RFC3339)
 }
+
+// Location returns the configured time zone used for generated dates and
+// timestamps, falling back to Asia/Shanghai.
+func (c *Core) Location() *time.Location {
+	if c == nil || c.location == nil {
+		return shanghaiLocation
+	}
+	return c.location
+}
+
+// TimeZone returns the configured IANA time zone name.
+func (c *Core) TimeZone() string {
+	return c.Location().String()
+}
+
+// Now returns the current time in the configured time zone.
+func (c *Core) Now() time.Time {
+	return time.Now().In(c.Location())
+}
+
+// TodayISO returns the current date as YYYY-MM-DD in the configured time zone.
+func (c *Core) TodayISO() string {
+	return c.Now().Format("2006-01-02")
+}
+
+// NowRFC3339 returns the current RFC3339 timestamp in the configured time zone.
+func (c *Core) NowRFC3339() string {
+	return c.Now().Format(time.RFC3339)
+}
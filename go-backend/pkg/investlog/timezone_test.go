@@ -0,0 +1,66 @@
+package investlog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenWithOptions_RejectsUnknownTimeZone(t *testing.T) {
+	_, err := OpenWithOptions(Options{DBPath: filepath.Join(t.TempDir(), "tz.db"), TimeZone: "Mars/Olympus_Mons"})
+	if err == nil || !strings.Contains(err.Error(), "Mars/Olympus_Mons") {
+		t.Fatalf("expected invalid time zone error, got %v", err)
+	}
+}
+
+func TestCoreTimestampsUseConfiguredTimeZone(t *testing.T) {
+	if got := (*Core)(nil).TimeZone(); got != shanghaiTimeZoneName {
+		t.Fatalf("expected default %s, got %s", shanghaiTimeZoneName, got)
+	}
+
+	loc, err := loadTimeZone("Pacific/Kiritimati")
+	if err != nil {
+		t.Skipf("zoneinfo unavailable: %v", err)
+	}
+	core := &Core{location: loc}
+	if core.TimeZone() != "Pacific/Kiritimati" {
+		t.Fatalf("unexpected time zone %s", core.TimeZone())
+	}
+	if stamp := core.NowRFC3339(); !strings.HasSuffix(stamp, "+14:00") {
+		t.Fatalf("expected +14:00 offset, got %s", stamp)
+	}
+	before := time.Now().In(loc).Format("2006-01-02")
+	today := core.TodayISO()
+	after := time.Now().In(loc).Format("2006-01-02")
+	if today != before && today != after {
+		t.Fatalf("expected today in Kiritimati (%s), got %s", before, today)
+	}
+
+	// 11:00 UTC on New Year's Eve is already the next year at UTC+14.
+	at, err := parseTransactionDate("2023-12-31T11:00:00Z", core.Location())
+	assertNoError(t, err, "parseTransactionDate")
+	if at.Year() != 2024 {
+		t.Fatalf("expected 2024 in Kiritimati, got %d", at.Year())
+	}
+}
+
+func TestAddTransaction_DefaultDateUsesConfiguredTimeZone(t *testing.T) {
+	if _, err := loadTimeZone("Pacific/Pago_Pago"); err != nil {
+		t.Skipf("zoneinfo unavailable: %v", err)
+	}
+	core, err := OpenWithOptions(Options{DBPath: filepath.Join(t.TempDir(), "tz.db"), TimeZone: "Pacific/Pago_Pago"})
+	assertNoError(t, err, "OpenWithOptions")
+	defer core.Close()
+
+	testAccount(t, core, "acc-1", "Main")
+	before := core.TodayISO()
+	id := testBuyTransaction(t, core, "AAPL", 1, 100, "USD", "acc-1")
+	after := core.TodayISO()
+
+	txn, err := core.GetTransaction(id)
+	assertNoError(t, err, "GetTransaction")
+	if got := txn.TransactionDate[:10]; got != before && got != after {
+		t.Fatalf("expected transaction date in Pago Pago (%s), got %s", before, txn.TransactionDate)
+	}
+}
@@ -0,0 +1,136 @@
+package investlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransactionTypeSummary counts one transaction type in one currency.
+type TransactionTypeSummary struct {
+	TransactionType string `json:"transaction_type"`
+	Currency        string `json:"currency"`
+	Count           int    `json:"count"`
+	TotalAmount     Amount `json:"total_amount"`
+}
+
+// TransactionMonthSummary groups a calendar month's transactions by type.
+type TransactionMonthSummary struct {
+	Month  string                   `json:"month"`
+	Count  int                      `json:"count"`
+	ByType []TransactionTypeSummary `json:"by_type"`
+}
+
+// TransactionSummary totals the transactions matched by a filter. Amounts are
+// never summed across currencies, so each type appears once per currency.
+type TransactionSummary struct {
+	Count   int                       `json:"count"`
+	ByType  []TransactionTypeSummary  `json:"by_type"`
+	ByMonth []TransactionMonthSummary `json:"by_month"`
+}
+
+// GetTransactionSummary counts and totals the transactions matching filter by
+// type and by calendar month (YYYY-MM) in the configured time zone. Limit and
+// Offset are ignored; months are oldest first.
+func (c *Core) GetTransactionSummary(filter TransactionFilter) (*TransactionSummary, error) {
+	query := strings.Builder{}
+	// CAST keeps the driver from turning DATE values into UTC timestamps.
+	query.WriteString(`
+		SELECT t.id, CAST(t.transaction_date AS TEXT), t.transaction_type, t.currency, t.total_amount
+		FROM transactions t
+		JOIN symbols s ON s.id = t.symbol_id
+		WHERE 1=1
+	`)
+	where, params := transactionFilterSQL(filter)
+	query.WriteString(where)
+
+	defer c.trackQuery("transactions.summary")()
+	rows, err := c.db.Query(query.String(), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type typeKey struct{ txnType, currency string }
+	type bucket struct {
+		count int
+		total decimal.Decimal
+	}
+	byType := map[typeKey]*bucket{}
+	byMonth := map[string]map[typeKey]*bucket{}
+	count := 0
+	add := func(groups map[typeKey]*bucket, key typeKey, amount decimal.Decimal) {
+		b, ok := groups[key]
+		if !ok {
+			b = &bucket{}
+			groups[key] = b
+		}
+		b.count++
+		b.total = b.total.Add(amount)
+	}
+	for rows.Next() {
+		var id int64
+		var date string
+		var key typeKey
+		var total Amount
+		if err := rows.Scan(&id, &date, &key.txnType, &key.currency, &total); err != nil {
+			return nil, err
+		}
+		at, err := parseTransactionDate(date, c.Location())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", id, err)
+		}
+		month := at.Format("2006-01")
+		if byMonth[month] == nil {
+			byMonth[month] = map[typeKey]*bucket{}
+		}
+		add(byType, key, total.Decimal)
+		add(byMonth[month], key, total.Decimal)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	flatten := func(groups map[typeKey]*bucket) []TransactionTypeSummary {
+		out := make([]TransactionTypeSummary, 0, len(groups))
+		for key, b := range groups {
+			out = append(out, TransactionTypeSummary{
+				TransactionType: key.txnType,
+				Currency:        key.currency,
+				Count:           b.count,
+				TotalAmount:     Amount{b.total},
+			})
+		}
+		sort.Slice(out, func(i, j int) bool {
+			if out[i].TransactionType != out[j].TransactionType {
+				return out[i].TransactionType < out[j].TransactionType
+			}
+			return out[i].Currency < out[j].Currency
+		})
+		return out
+	}
+
+	months := make([]string, 0, len(byMonth))
+	for month := range byMonth {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+
+	summary := &TransactionSummary{
+		Count:   count,
+		ByType:  flatten(byType),
+		ByMonth: make([]TransactionMonthSummary, 0, len(months)),
+	}
+	for _, month := range months {
+		types := flatten(byMonth[month])
+		monthCount := 0
+		for _, t := range types {
+			monthCount += t.Count
+		}
+		summary.ByMonth = append(summary.ByMonth, TransactionMonthSummary{Month: month, Count: monthCount, ByType: types})
+	}
+	return summary, nil
+}
@@ -0,0 +1,90 @@
+package investlog
+
+import "testing"
+
+func addSummaryTransaction(t *testing.T, core *Core, date, symbol, txnType string, qty, price int64, currency string) int64 {
+	t.Helper()
+	id, err := core.AddTransaction(AddTransactionRequest{
+		TransactionDate: date,
+		Symbol:          symbol,
+		TransactionType: txnType,
+		Quantity:        NewAmountFromInt(qty),
+		Price:           NewAmountFromInt(price),
+		Currency:        currency,
+		AccountID:       "acct",
+		AssetType:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("AddTransaction %s %s on %s: %v", txnType, symbol, date, err)
+	}
+	return id
+}
+
+func findTypeSummary(items []TransactionTypeSummary, txnType, currency string) *TransactionTypeSummary {
+	for i := range items {
+		if items[i].TransactionType == txnType && items[i].Currency == currency {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func TestGetTransactionSummary_GroupsByTypeAndMonth(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct", "Account")
+	addSummaryTransaction(t, core, "2024-01-05", "AAPL", "BUY", 10, 100, "USD")
+	addSummaryTransaction(t, core, "2024-01-20", "MSFT", "BUY", 5, 200, "USD")
+	addSummaryTransaction(t, core, "2024-02-10", "AAPL", "SELL", 4, 120, "USD")
+	addSummaryTransaction(t, core, "2024-02-15", "AAPL", "DIVIDEND", 1, 30, "USD")
+	addSummaryTransaction(t, core, "2024-03-01", "600519", "BUY", 1, 1500, "CNY")
+	// 20:00 UTC on Jan 31 is already Feb 1 in the default Asia/Shanghai zone.
+	late := addSummaryTransaction(t, core, "2024-01-31", "MSFT", "SELL", 1, 210, "USD")
+	_, err := core.db.Exec("UPDATE transactions SET transaction_date = ? WHERE id = ?", "2024-01-31T20:00:00Z", late)
+	assertNoError(t, err, "set UTC timestamp")
+
+	summary, err := core.GetTransactionSummary(TransactionFilter{})
+	assertNoError(t, err, "GetTransactionSummary")
+	if summary.Count != 6 {
+		t.Fatalf("expected 6 transactions, got %d", summary.Count)
+	}
+	if len(summary.ByType) != 4 {
+		t.Fatalf("expected BUY/USD, BUY/CNY, SELL/USD, DIVIDEND/USD, got %+v", summary.ByType)
+	}
+	if buy := findTypeSummary(summary.ByType, "BUY", "USD"); buy == nil || buy.Count != 2 || buy.TotalAmount.InexactFloat64() != 2000 {
+		t.Fatalf("unexpected USD buys: %+v", buy)
+	}
+	if buy := findTypeSummary(summary.ByType, "BUY", "CNY"); buy == nil || buy.Count != 1 || buy.TotalAmount.InexactFloat64() != 1500 {
+		t.Fatalf("unexpected CNY buys: %+v", buy)
+	}
+	if sell := findTypeSummary(summary.ByType, "SELL", "USD"); sell == nil || sell.Count != 2 || sell.TotalAmount.InexactFloat64() != 690 {
+		t.Fatalf("unexpected USD sells: %+v", sell)
+	}
+	if div := findTypeSummary(summary.ByType, "DIVIDEND", "USD"); div == nil || div.Count != 1 || div.TotalAmount.InexactFloat64() != 30 {
+		t.Fatalf("unexpected USD dividends: %+v", div)
+	}
+
+	if len(summary.ByMonth) != 3 {
+		t.Fatalf("expected 3 months, got %+v", summary.ByMonth)
+	}
+	jan, feb, mar := summary.ByMonth[0], summary.ByMonth[1], summary.ByMonth[2]
+	if jan.Month != "2024-01" || jan.Count != 2 || len(jan.ByType) != 1 {
+		t.Fatalf("unexpected January: %+v", jan)
+	}
+	if feb.Month != "2024-02" || feb.Count != 3 {
+		t.Fatalf("unexpected February: %+v", feb)
+	}
+	if sell := findTypeSummary(feb.ByType, "SELL", "USD"); sell == nil || sell.Count != 2 || sell.TotalAmount.InexactFloat64() != 690 {
+		t.Fatalf("expected the Jan 31 UTC sell in February, got %+v", feb.ByType)
+	}
+	if mar.Month != "2024-03" || mar.Count != 1 {
+		t.Fatalf("unexpected March: %+v", mar)
+	}
+
+	filtered, err := core.GetTransactionSummary(TransactionFilter{Currency: "usd", TransactionType: "BUY"})
+	assertNoError(t, err, "GetTransactionSummary filtered")
+	if filtered.Count != 2 || len(filtered.ByType) != 1 || len(filtered.ByMonth) != 1 {
+		t.Fatalf("expected only January USD buys, got %+v", filtered)
+	}
+}
@@ -2,9 +2,10 @@ package investlog
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
 // TransactionFilter controls transaction queries.
@@ -22,23 +23,119 @@ type TransactionFilter struct {
 
 // AddTransaction inserts a new transaction and returns its ID.
 func (c *Core) AddTransaction(req AddTransactionRequest) (int64, error) {
-	if req.TransactionType == "" {
-		return 0, errors.New("transaction_type required")
+	if err := c.validateAddTransaction(&req); err != nil {
+		return 0, err
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	id, err := c.addTransactionTx(tx, req)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	c.invalidateHoldingsCache()
+
+	return id, nil
+}
+
+// addTransactionTx runs the checks that depend on stored data and inserts an
+// already validated request within tx. The caller commits and invalidates the
+// holdings cache.
+func (c *Core) addTransactionTx(tx *sql.Tx, req AddTransactionRequest) (int64, error) {
+	// Validate SELL/TRANSFER_OUT won't result in negative holdings
+	if req.TransactionType == "SELL" || req.TransactionType == "TRANSFER_OUT" {
+		currentShares, err := currentShares(tx, req.Symbol, req.Currency, req.AccountID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check current holdings: %w", err)
+		}
+		if req.Quantity.GreaterThan(currentShares.Decimal) {
+			return 0, fmt.Errorf("insufficient shares: trying to %s %s but only have %s",
+				req.TransactionType, req.Quantity.Round(4).String(), currentShares.Round(4).String())
+		}
+	}
+
+	if req.CheckDuplicate {
+		existingID, err := findDuplicateTransaction(tx, req)
+		if err != nil {
+			return 0, err
+		}
+		if existingID != 0 {
+			return 0, &DuplicateTransactionError{ExistingID: existingID}
+		}
+	}
+
+	totalAmount := Amount{req.Quantity.Mul(req.Price.Decimal)}
+	if req.TotalAmount != nil {
+		totalAmount = *req.TotalAmount
+	}
+
+	if err := ensureAccountTx(tx, req.AccountID, req.AccountName); err != nil {
+		return 0, err
+	}
+
+	if req.UseDefaultCommission && req.Commission.IsZero() && (req.TransactionType == "BUY" || req.TransactionType == "SELL") {
+		commission, err := accountDefaultCommissionTx(tx, req.AccountID)
+		if err != nil {
+			return 0, err
+		}
+		if commission != nil {
+			req.Commission = *commission
+		}
+	}
+
+	if req.CheckTotalAmount {
+		if err := reconcileTotalAmount(req); err != nil {
+			return 0, err
+		}
+	}
+
+	symbolID, symbol, _, err := c.ensureSymbol(tx, req.Symbol, &req.AssetType)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := c.insertTransactionTx(tx, req, symbolID, totalAmount)
+	if err != nil {
+		return 0, err
+	}
+
+	if req.LinkCash && symbol != "CASH" {
+		if err := c.insertLinkedCashTx(tx, req, symbol, id, totalAmount); err != nil {
+			return 0, err
+		}
 	}
-	if !isValidTransactionType(req.TransactionType) {
-		return 0, fmt.Errorf("invalid transaction_type: %s", req.TransactionType)
+	return id, nil
+}
+
+// validateAddTransaction fills request defaults and checks the fields that
+// do not depend on stored data.
+func (c *Core) validateAddTransaction(req *AddTransactionRequest) error {
+	invalid := &ValidationError{}
+	if req.TransactionType == "" {
+		invalid.Add("transaction_type", "transaction_type required")
+	} else if !isValidTransactionType(req.TransactionType) {
+		invalid.Add("transaction_type", fmt.Sprintf("invalid transaction_type: %s", req.TransactionType))
 	}
 	if req.AccountID == "" {
-		return 0, errors.New("account_id required")
+		invalid.Add("account_id", "account_id required")
 	}
 	if req.Currency == "" {
 		req.Currency = "CNY"
 	}
 	if !isValidCurrency(req.Currency) {
-		return 0, fmt.Errorf("invalid currency: %s", req.Currency)
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", req.Currency))
 	}
 	if req.TransactionDate == "" {
-		req.TransactionDate = todayISO()
+		req.TransactionDate = c.TodayISO()
 	}
 	if req.AssetType == "" {
 		req.AssetType = "stock"
@@ -49,18 +146,18 @@ func (c *Core) AddTransaction(req AddTransactionRequest) (int64, error) {
 		req.Price = NewAmountFromInt(1)
 	}
 	if req.Symbol == "" {
-		return 0, errors.New("symbol required")
+		invalid.Add("symbol", "symbol required")
 	}
 
 	// Validate quantity based on transaction type
 	switch req.TransactionType {
 	case "BUY", "TRANSFER_IN", "INCOME":
 		if !req.Quantity.IsPositive() {
-			return 0, errors.New("quantity must be positive for BUY/TRANSFER_IN/INCOME")
+			invalid.Add("quantity", "quantity must be positive for BUY/TRANSFER_IN/INCOME")
 		}
 	case "SELL", "TRANSFER_OUT":
 		if !req.Quantity.IsPositive() {
-			return 0, errors.New("quantity must be positive for SELL/TRANSFER_OUT")
+			invalid.Add("quantity", "quantity must be positive for SELL/TRANSFER_OUT")
 		}
 	case "DIVIDEND":
 		// Dividend amount can be in total_amount, quantity validation optional
@@ -74,86 +171,79 @@ func (c *Core) AddTransaction(req AddTransactionRequest) (int64, error) {
 
 	// Validate price is not negative
 	if req.Price.IsNegative() {
-		return 0, errors.New("price cannot be negative")
+		invalid.Add("price", "price cannot be negative")
 	}
+	return invalid.Err()
+}
 
-	// Validate SELL/TRANSFER_OUT won't result in negative holdings
-	if req.TransactionType == "SELL" || req.TransactionType == "TRANSFER_OUT" {
-		currentShares, err := c.getCurrentShares(req.Symbol, req.Currency, req.AccountID)
-		if err != nil {
-			return 0, fmt.Errorf("failed to check current holdings: %w", err)
-		}
-		if req.Quantity.GreaterThan(currentShares.Decimal) {
-			return 0, fmt.Errorf("insufficient shares: trying to %s %s but only have %s",
-				req.TransactionType, req.Quantity.Round(4).String(), currentShares.Round(4).String())
-		}
+// reconcileTotalAmount checks an explicit TotalAmount of a BUY or SELL
+// against Quantity*Price, accepting either the gross value or the
+// commission-inclusive one. The tolerance is one cent or 0.05% of the gross
+// value, whichever is larger, to absorb broker rounding of the price.
+func reconcileTotalAmount(req AddTransactionRequest) error {
+	if req.TotalAmount == nil || (req.TransactionType != "BUY" && req.TransactionType != "SELL") {
+		return nil
 	}
-
-	totalAmount := Amount{req.Quantity.Mul(req.Price.Decimal)}
-	if req.TotalAmount != nil {
-		totalAmount = *req.TotalAmount
+	if !req.Quantity.IsPositive() || !req.Price.IsPositive() {
+		return nil
 	}
-
-	tx, err := c.db.Begin()
-	if err != nil {
-		return 0, err
+	gross := req.Quantity.Mul(req.Price.Decimal)
+	withCommission := gross.Add(req.Commission.Decimal)
+	if req.TransactionType == "SELL" {
+		withCommission = gross.Sub(req.Commission.Decimal)
 	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	if err := ensureAccountTx(tx, req.AccountID, req.AccountName); err != nil {
-		return 0, err
+	tolerance := decimal.Max(decimal.NewFromFloat(0.01), gross.Mul(decimal.NewFromFloat(0.0005)))
+	total := req.TotalAmount.Decimal
+	if total.Sub(gross).Abs().LessThanOrEqual(tolerance) || total.Sub(withCommission).Abs().LessThanOrEqual(tolerance) {
+		return nil
 	}
-
-	symbolID, symbol, _, err := c.ensureSymbol(tx, req.Symbol, &req.AssetType)
-	if err != nil {
-		return 0, err
+	message := fmt.Sprintf("total_amount %s does not match quantity*price %s", total.Round(2).String(), gross.Round(2).String())
+	if !req.Commission.IsZero() {
+		message += fmt.Sprintf(" (%s including commission)", withCommission.Round(2).String())
 	}
+	return NewValidationError("total_amount", message)
+}
 
-	id, err := c.insertTransactionTx(tx, req, symbolID, totalAmount)
+// insertLinkedCashTx records the cash leg of a LinkCash transaction in the
+// same account and currency: a BUY spends cash, while SELL proceeds and
+// dividends arrive as a TRANSFER_IN. The cash row points at the parent via
+// linked_transaction_id so deleting either removes both.
+func (c *Core) insertLinkedCashTx(tx *sql.Tx, req AddTransactionRequest, symbol string, parentID int64, totalAmount Amount) error {
+	var cashType string
+	var cashAmount Amount
+	switch req.TransactionType {
+	case "BUY":
+		cashType = "SELL"
+		cashAmount = Amount{totalAmount.Add(req.Commission.Decimal)}
+	case "SELL", "DIVIDEND":
+		cashType = "TRANSFER_IN"
+		cashAmount = Amount{totalAmount.Sub(req.Commission.Decimal)}
+	default:
+		return nil
+	}
+	if !cashAmount.IsPositive() {
+		return nil
+	}
+	cashReq := AddTransactionRequest{
+		TransactionDate: req.TransactionDate,
+		TransactionTime: req.TransactionTime,
+		Symbol:          "CASH",
+		TransactionType: cashType,
+		Quantity:        cashAmount,
+		Price:           NewAmountFromInt(1),
+		AccountID:       req.AccountID,
+		AssetType:       "cash",
+		Commission:      NewAmountFromInt(0),
+		Currency:        req.Currency,
+		AccountName:     req.AccountName,
+		Notes:           stringPtr(fmt.Sprintf("Linked to %s %s", req.TransactionType, symbol)),
+	}
+	cashSymbolID, _, _, err := c.ensureSymbol(tx, cashReq.Symbol, &cashReq.AssetType)
 	if err != nil {
-		return 0, err
-	}
-
-	if req.LinkCash && (req.TransactionType == "BUY" || req.TransactionType == "SELL") && symbol != "CASH" {
-		cashType := "SELL"
-		if req.TransactionType == "SELL" {
-			cashType = "BUY"
-		}
-		cashAmount := Amount{totalAmount.Add(req.Commission.Decimal)}
-		if req.TransactionType == "SELL" {
-			cashAmount = Amount{totalAmount.Sub(req.Commission.Decimal)}
-		}
-		cashReq := AddTransactionRequest{
-			TransactionDate: req.TransactionDate,
-			TransactionTime: req.TransactionTime,
-			Symbol:          "CASH",
-			TransactionType: cashType,
-			Quantity:        cashAmount,
-			Price:           NewAmountFromInt(1),
-			AccountID:       req.AccountID,
-			AssetType:       "cash",
-			Commission:      NewAmountFromInt(0),
-			Currency:        req.Currency,
-			AccountName:     req.AccountName,
-			Notes:           stringPtr(fmt.Sprintf("Linked to %s %s", req.TransactionType, symbol)),
-		}
-		cashSymbolID, _, _, err := c.ensureSymbol(tx, cashReq.Symbol, &cashReq.AssetType)
-		if err != nil {
-			return 0, err
-		}
-		if _, err := c.insertTransactionTx(tx, cashReq, cashSymbolID, cashAmount); err != nil {
-			return 0, err
-		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, err
+		return err
 	}
-	c.invalidateHoldingsCache()
-
-	return id, nil
+	_, err = c.insertTransactionWithLinkTx(tx, cashReq, cashSymbolID, cashAmount, &parentID)
+	return err
 }
 
 func (c *Core) insertTransactionTx(tx *sql.Tx, req AddTransactionRequest, symbolID int64, totalAmount Amount) (int64, error) {
@@ -268,87 +358,108 @@ func (c *Core) GetTransactions(filter TransactionFilter) ([]Transaction, error)
 		JOIN symbols s ON s.id = t.symbol_id
 		WHERE 1=1
 	`)
-	params := []any{}
+	where, params := transactionFilterSQL(filter)
+	query.WriteString(where)
+
+	query.WriteString(" ORDER BY t.transaction_date DESC, t.id DESC LIMIT ? OFFSET ?")
+	params = append(params, limit, offset)
+
+	defer c.trackQuery("transactions.list")()
+	rows, err := c.db.Query(query.String(), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, t)
+	}
+	return results, rows.Err()
+}
 
+// transactionFilterSQL returns the AND clauses and parameters selecting the
+// transactions matched by filter, for a query over transactions t joined to
+// symbols s. Limit and Offset are left to the caller.
+func transactionFilterSQL(filter TransactionFilter) (string, []any) {
+	var where strings.Builder
+	params := []any{}
 	if filter.Symbol != "" {
-		query.WriteString(" AND s.symbol = ?")
+		where.WriteString(" AND s.symbol = ?")
 		params = append(params, normalizeSymbol(filter.Symbol))
 	}
 	if filter.AccountID != "" {
-		query.WriteString(" AND t.account_id = ?")
+		where.WriteString(" AND t.account_id = ?")
 		params = append(params, filter.AccountID)
 	}
 	if filter.TransactionType != "" {
-		query.WriteString(" AND t.transaction_type = ?")
+		where.WriteString(" AND t.transaction_type = ?")
 		params = append(params, filter.TransactionType)
 	}
 	if filter.Currency != "" {
-		query.WriteString(" AND t.currency = ?")
+		where.WriteString(" AND t.currency = ?")
 		params = append(params, normalizeCurrency(filter.Currency))
 	}
 	if filter.Year > 0 {
-		query.WriteString(" AND strftime('%Y', t.transaction_date) = ?")
+		where.WriteString(" AND strftime('%Y', t.transaction_date) = ?")
 		params = append(params, fmt.Sprintf("%04d", filter.Year))
 	}
 	if filter.StartDate != "" {
-		query.WriteString(" AND t.transaction_date >= ?")
+		where.WriteString(" AND t.transaction_date >= ?")
 		params = append(params, filter.StartDate)
 	}
 	if filter.EndDate != "" {
-		query.WriteString(" AND t.transaction_date <= ?")
+		where.WriteString(" AND t.transaction_date <= ?")
 		params = append(params, filter.EndDate)
 	}
+	return where.String(), params
+}
 
-	query.WriteString(" ORDER BY t.transaction_date DESC, t.id DESC LIMIT ? OFFSET ?")
-	params = append(params, limit, offset)
-
-	rows, err := c.db.Query(query.String(), params...)
-	if err != nil {
-		return nil, err
+// scanTransaction scans a row of transaction columns as selected by
+// GetTransactions, followed by any extra destinations.
+func scanTransaction(rows *sql.Rows, extra ...any) (Transaction, error) {
+	var t Transaction
+	var transactionTime, accountName, notes, tags, createdAt, updatedAt, name sql.NullString
+	var linkedTxnID sql.NullInt64
+	dest := []any{
+		&t.ID, &t.TransactionDate, &transactionTime, &t.SymbolID, &t.TransactionType,
+		&t.Quantity, &t.Price, &t.TotalAmount, &t.Commission, &t.Currency,
+		&t.AccountID, &accountName, &notes, &tags,
+		&linkedTxnID, &createdAt, &updatedAt,
+		&t.Symbol, &name, &t.AssetType,
 	}
-	defer rows.Close()
-
-	var results []Transaction
-	for rows.Next() {
-		var t Transaction
-		var transactionTime, accountName, notes, tags, createdAt, updatedAt, name sql.NullString
-		var linkedTxnID sql.NullInt64
-		if err := rows.Scan(
-			&t.ID, &t.TransactionDate, &transactionTime, &t.SymbolID, &t.TransactionType,
-			&t.Quantity, &t.Price, &t.TotalAmount, &t.Commission, &t.Currency,
-			&t.AccountID, &accountName, &notes, &tags,
-			&linkedTxnID, &createdAt, &updatedAt,
-			&t.Symbol, &name, &t.AssetType,
-		); err != nil {
-			return nil, err
-		}
-		if transactionTime.Valid {
-			t.TransactionTime = &transactionTime.String
-		}
-		if accountName.Valid {
-			t.AccountName = &accountName.String
-		}
-		if notes.Valid {
-			t.Notes = &notes.String
-		}
-		if tags.Valid {
-			t.Tags = &tags.String
-		}
-		if linkedTxnID.Valid {
-			t.LinkedTransactionID = &linkedTxnID.Int64
-		}
-		if createdAt.Valid {
-			t.CreatedAt = &createdAt.String
-		}
-		if updatedAt.Valid {
-			t.UpdatedAt = &updatedAt.String
-		}
-		if name.Valid {
-			t.Name = &name.String
-		}
-		results = append(results, t)
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return Transaction{}, err
 	}
-	return results, rows.Err()
+	if transactionTime.Valid {
+		t.TransactionTime = &transactionTime.String
+	}
+	if accountName.Valid {
+		t.AccountName = &accountName.String
+	}
+	if notes.Valid {
+		t.Notes = &notes.String
+	}
+	if tags.Valid {
+		t.Tags = &tags.String
+	}
+	if linkedTxnID.Valid {
+		t.LinkedTransactionID = &linkedTxnID.Int64
+	}
+	if createdAt.Valid {
+		t.CreatedAt = &createdAt.String
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = &updatedAt.String
+	}
+	if name.Valid {
+		t.Name = &name.String
+	}
+	return t, nil
 }
 
 // GetTransactionCount returns count of transactions matching the filter.
@@ -360,29 +471,10 @@ func (c *Core) GetTransactionCount(filter TransactionFilter) (int, error) {
 		JOIN symbols s ON s.id = t.symbol_id
 		WHERE 1=1
 	`)
-	params := []any{}
-
-	if filter.Symbol != "" {
-		query.WriteString(" AND s.symbol = ?")
-		params = append(params, normalizeSymbol(filter.Symbol))
-	}
-	if filter.AccountID != "" {
-		query.WriteString(" AND t.account_id = ?")
-		params = append(params, filter.AccountID)
-	}
-	if filter.TransactionType != "" {
-		query.WriteString(" AND t.transaction_type = ?")
-		params = append(params, filter.TransactionType)
-	}
-	if filter.Currency != "" {
-		query.WriteString(" AND t.currency = ?")
-		params = append(params, normalizeCurrency(filter.Currency))
-	}
-	if filter.Year > 0 {
-		query.WriteString(" AND strftime('%Y', t.transaction_date) = ?")
-		params = append(params, fmt.Sprintf("%04d", filter.Year))
-	}
+	where, params := transactionFilterSQL(filter)
+	query.WriteString(where)
 
+	defer c.trackQuery("transactions.count")()
 	var count int
 	if err := c.db.QueryRow(query.String(), params...).Scan(&count); err != nil {
 		return 0, err
@@ -391,7 +483,8 @@ func (c *Core) GetTransactionCount(filter TransactionFilter) (int, error) {
 }
 
 // DeleteTransaction deletes a transaction by ID.
-// If the transaction has linked records, they are also deleted.
+// If the transaction has linked records, they are also deleted. Deleted rows
+// are kept as tombstones so RestoreTransaction can bring them back.
 func (c *Core) DeleteTransaction(id int64) (bool, error) {
 	tx, err := c.db.Begin()
 	if err != nil {
@@ -432,6 +525,9 @@ func (c *Core) DeleteTransaction(id int64) (bool, error) {
 		return false, err
 	}
 
+	if err := archiveDeletedTransactions(tx, id, idsToDelete); err != nil {
+		return false, err
+	}
 	if _, err := tx.Exec("DELETE FROM transactions WHERE id IN ("+placeholders(len(idsToDelete))+")", int64SliceToAny(idsToDelete)...); err != nil {
 		return false, err
 	}
@@ -470,6 +566,17 @@ func nullString(value *string) sql.NullString {
 
 // getCurrentShares returns the current share count for a symbol in a specific account and currency.
 func (c *Core) getCurrentShares(symbol, currency, accountID string) (Amount, error) {
+	return currentShares(c.db, symbol, currency, accountID)
+}
+
+// rowQuerier is the QueryRow method shared by *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// currentShares is getCurrentShares against q, so callers holding a
+// transaction see their own uncommitted rows.
+func currentShares(q rowQuerier, symbol, currency, accountID string) (Amount, error) {
 	query := `
 		SELECT COALESCE(SUM(CASE
 			WHEN t.transaction_type IN ('BUY', 'TRANSFER_IN', 'INCOME') THEN t.quantity
@@ -482,7 +589,7 @@ func (c *Core) getCurrentShares(symbol, currency, accountID string) (Amount, err
 		WHERE s.symbol = ? AND t.currency = ? AND t.account_id = ?
 	`
 	var shares Amount
-	err := c.db.QueryRow(query, normalizeSymbol(symbol), normalizeCurrency(currency), accountID).Scan(&shares)
+	err := q.QueryRow(query, normalizeSymbol(symbol), normalizeCurrency(currency), accountID).Scan(&shares)
 	if err != nil {
 		return Amount{}, err
 	}
@@ -67,3 +67,25 @@ func TestGetTransactionCountFilters(t *testing.T) {
 		t.Fatalf("expected 2 transactions in 2024, got %d", count)
 	}
 }
+
+func TestGetTransactionCount_DateRange(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct", "Account")
+	testBuyTransaction(t, core, "AAA", 1, 10, "USD", "acct")
+	_, err := core.db.Exec("UPDATE transactions SET transaction_date = '2024-01-01'")
+	assertNoError(t, err, "backdate transaction")
+	testBuyTransaction(t, core, "BBB", 1, 10, "USD", "acct")
+
+	count, err := core.GetTransactionCount(TransactionFilter{StartDate: "2024-06-01"})
+	assertNoError(t, err, "GetTransactionCount start")
+	if count != 1 {
+		t.Fatalf("expected 1 transaction after start date, got %d", count)
+	}
+	count, err = core.GetTransactionCount(TransactionFilter{EndDate: "2024-06-01"})
+	assertNoError(t, err, "GetTransactionCount end")
+	if count != 1 {
+		t.Fatalf("expected 1 transaction before end date, got %d", count)
+	}
+}
@@ -0,0 +1,51 @@
+package investlog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// duplicateTransactionWindowDays is how many days either side of a new
+// transaction's date an identical one counts as a likely re-entry.
+const duplicateTransactionWindowDays = 3
+
+// DuplicateTransactionError reports that AddTransaction found an identical
+// transaction (same symbol, type, quantity, price, currency and account)
+// within a few days of the new one and did not insert it.
+type DuplicateTransactionError struct {
+	ExistingID int64
+}
+
+// Error implements the error interface.
+func (e *DuplicateTransactionError) Error() string {
+	return fmt.Sprintf("possible duplicate of transaction %d", e.ExistingID)
+}
+
+// findDuplicateTransaction returns the ID of the identical transaction
+// closest in date to req, or 0 when there is none.
+func findDuplicateTransaction(q rowQuerier, req AddTransactionRequest) (int64, error) {
+	var id int64
+	err := q.QueryRow(`
+		SELECT t.id
+		FROM transactions t
+		JOIN symbols s ON s.id = t.symbol_id
+		WHERE s.symbol = ? AND t.transaction_type = ? AND t.account_id = ? AND t.currency = ?
+			AND ABS(t.quantity - ?) < 0.00005 AND ABS(t.price - ?) < 0.00005
+			AND ABS(julianday(t.transaction_date) - julianday(?)) <= ?
+		ORDER BY ABS(julianday(t.transaction_date) - julianday(?)), t.id DESC
+		LIMIT 1
+	`,
+		normalizeSymbol(req.Symbol), req.TransactionType, req.AccountID, req.Currency,
+		req.Quantity, req.Price,
+		req.TransactionDate, duplicateTransactionWindowDays,
+		req.TransactionDate,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("check duplicate transaction: %w", err)
+	}
+	return id, nil
+}
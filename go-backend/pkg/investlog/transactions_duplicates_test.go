@@ -0,0 +1,52 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddTransaction_CheckDuplicate(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc-1", "Main")
+	buy := func(date string, qty float64, check bool) (int64, error) {
+		return core.AddTransaction(AddTransactionRequest{
+			TransactionDate: date,
+			Symbol:          "AAPL",
+			TransactionType: "BUY",
+			Quantity:        NewAmount(qty),
+			Price:           NewAmount(150.25),
+			Currency:        "USD",
+			AccountID:       "acc-1",
+			AssetType:       "stock",
+			CheckDuplicate:  check,
+		})
+	}
+	firstID, err := buy("2024-03-10", 10, true)
+	assertNoError(t, err, "first buy")
+
+	_, err = buy("2024-03-12", 10, true)
+	var duplicate *DuplicateTransactionError
+	if !errors.As(err, &duplicate) || duplicate.ExistingID != firstID {
+		t.Fatalf("expected duplicate of %d, got %v", firstID, err)
+	}
+
+	// A different quantity or a date outside the window is a new trade.
+	_, err = buy("2024-03-12", 11, true)
+	assertNoError(t, err, "buy with different quantity")
+	_, err = buy("2024-03-20", 10, true)
+	assertNoError(t, err, "buy outside window")
+
+	// Without the check the identical trade is recorded anyway.
+	forcedID, err := buy("2024-03-12", 10, false)
+	assertNoError(t, err, "forced buy")
+	if forcedID == firstID {
+		t.Fatal("expected a new transaction ID")
+	}
+	count, err := core.GetTransactionCount(TransactionFilter{Symbol: "AAPL"})
+	assertNoError(t, err, "GetTransactionCount")
+	if count != 4 {
+		t.Fatalf("expected 4 transactions, got %d", count)
+	}
+}
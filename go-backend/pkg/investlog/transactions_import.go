@@ -0,0 +1,325 @@
+package investlog
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// defaultImportMaxRows caps the data rows of one CSV import.
+	defaultImportMaxRows = 10000
+	// importBatchSize is how many imported rows are written per database
+	// transaction.
+	importBatchSize = 500
+)
+
+// importRequiredColumns must appear in the CSV header.
+var importRequiredColumns = []string{"symbol", "transaction_type", "quantity", "price", "account_id"}
+
+// TransactionImportResult summarizes ImportTransactionsCSV.
+type TransactionImportResult struct {
+	Imported int                    `json:"imported"`
+	Failed   int                    `json:"failed"`
+	Batches  int                    `json:"batches"`
+	Rows     []TransactionImportRow `json:"rows"`
+}
+
+// TransactionImportRow is the outcome of one CSV data row: the new
+// transaction ID, or why the row was skipped.
+type TransactionImportRow struct {
+	Line       int    `json:"line"`
+	ID         int64  `json:"id,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	ExistingID int64  `json:"existing_id,omitempty"`
+}
+
+// ImportTransactionsCSV imports transactions from CSV. The header names the
+// columns using the transaction JSON field names; symbol, transaction_type,
+// quantity, price and account_id are required, while transaction_date,
+// transaction_time, currency, asset_type, commission, total_amount,
+// account_name, notes, tags, link_cash and force are optional.
+//
+// The upload is spooled to a temporary file and counted first, so a file with
+// more rows than the configured maximum is rejected before anything is
+// written. Rows are then read one at a time and each goes through the same
+// checks as AddTransaction: a blank commission uses the account default,
+// duplicates and mismatched totals are rejected unless force is set, and
+// link_cash records the cash leg. A rejected row is reported and skipped
+// without affecting the others. Rows are written in batches of
+// importBatchSize, each in its own database transaction; when a batch cannot
+// be committed, the earlier batches stay imported and the partial result is
+// returned with the error.
+func (c *Core) ImportTransactionsCSV(r io.Reader) (*TransactionImportResult, error) {
+	spool, err := os.CreateTemp("", "investlog-import-*.csv")
+	if err != nil {
+		return nil, fmt.Errorf("spool csv: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	if _, err := io.Copy(spool, r); err != nil {
+		return nil, fmt.Errorf("spool csv: %w", err)
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader, columns, err := newImportReader(spool)
+	if err != nil {
+		return nil, err
+	}
+	maxRows := defaultInt(c.importMaxRows, defaultImportMaxRows)
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, NewValidationError("file", fmt.Sprintf("invalid csv: %v", err))
+		}
+		if isBlankCSVRecord(record) {
+			continue
+		}
+		if rows++; rows > maxRows {
+			return nil, NewValidationError("file", fmt.Sprintf("import exceeds the limit of %d rows; split the file", maxRows))
+		}
+	}
+	if rows == 0 {
+		return nil, NewValidationError("file", "csv has no transactions")
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader, columns, err = newImportReader(spool)
+	if err != nil {
+		return nil, err
+	}
+	result := &TransactionImportResult{Rows: make([]TransactionImportRow, 0, rows)}
+	err = c.importTransactionRows(reader, columns, result)
+	if result.Imported > 0 {
+		c.invalidateHoldingsCache()
+	}
+	if err != nil {
+		return result, fmt.Errorf("import stopped after %d rows were imported: %w", result.Imported, err)
+	}
+	c.Logger().Info("transactions imported", "rows", result.Imported, "failed", result.Failed, "batches", result.Batches)
+	return result, nil
+}
+
+// newImportReader reads the header of r and checks the required columns.
+func newImportReader(r io.Reader) (*csv.Reader, map[string]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, NewValidationError("file", "csv is empty")
+	}
+	if err != nil {
+		return nil, nil, NewValidationError("file", fmt.Sprintf("invalid csv: %v", err))
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, NewValidationError("file", fmt.Sprintf("missing column: %s", name))
+		}
+	}
+	return reader, columns, nil
+}
+
+// importTransactionRows writes the data rows of reader in batches, recording
+// each row's outcome in result.
+func (c *Core) importTransactionRows(reader *csv.Reader, columns map[string]int, result *TransactionImportResult) error {
+	var (
+		tx      *sql.Tx
+		pending []TransactionImportRow
+	)
+	commit := func() error {
+		if tx == nil {
+			return nil
+		}
+		err := tx.Commit()
+		tx = nil
+		if err != nil {
+			return err
+		}
+		for _, row := range pending {
+			if row.Error == "" {
+				result.Imported++
+			} else {
+				result.Failed++
+			}
+		}
+		result.Rows = append(result.Rows, pending...)
+		result.Batches++
+		pending = pending[:0]
+		return nil
+	}
+	defer func() {
+		if tx != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return NewValidationError("file", fmt.Sprintf("invalid csv: %v", err))
+		}
+		if isBlankCSVRecord(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if tx == nil {
+			if tx, err = c.db.Begin(); err != nil {
+				return err
+			}
+		}
+		row := TransactionImportRow{Line: line}
+		id, err := c.importTransactionRow(tx, record, columns)
+		if err != nil {
+			row.Error = err.Error()
+			var duplicate *DuplicateTransactionError
+			var invalid *ValidationError
+			var coded *Error
+			switch {
+			case errors.As(err, &duplicate):
+				row.ErrorCode = string(ErrCodeDuplicate)
+				row.ExistingID = duplicate.ExistingID
+			case errors.As(err, &invalid):
+				row.ErrorCode = string(ErrCodeValidation)
+			case errors.As(err, &coded):
+				row.ErrorCode = string(coded.Code)
+			}
+		}
+		row.ID = id
+		pending = append(pending, row)
+		if len(pending) == importBatchSize {
+			if err := commit(); err != nil {
+				return err
+			}
+		}
+	}
+	return commit()
+}
+
+// importTransactionRow adds one CSV record within tx. A savepoint keeps a
+// rejected row from leaving partial writes in the batch.
+func (c *Core) importTransactionRow(tx *sql.Tx, record []string, columns map[string]int) (int64, error) {
+	req, err := parseImportRecord(record, columns)
+	if err == nil {
+		err = c.validateAddTransaction(&req)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+		return 0, err
+	}
+	id, err := c.addTransactionTx(tx, req)
+	if err != nil {
+		if _, rollbackErr := tx.Exec(`ROLLBACK TO import_row`); rollbackErr != nil {
+			return 0, errors.Join(err, rollbackErr)
+		}
+	}
+	if _, releaseErr := tx.Exec(`RELEASE import_row`); releaseErr != nil {
+		return 0, errors.Join(err, releaseErr)
+	}
+	return id, err
+}
+
+// parseImportRecord maps one CSV record onto an AddTransactionRequest.
+func parseImportRecord(record []string, columns map[string]int) (AddTransactionRequest, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	optional := func(name string) *string {
+		if value := field(name); value != "" {
+			return &value
+		}
+		return nil
+	}
+	invalid := &ValidationError{}
+	amount := func(name string) Amount {
+		value := field(name)
+		if value == "" {
+			return Amount{}
+		}
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			invalid.Add(name, fmt.Sprintf("invalid %s: %s", name, value))
+			return Amount{}
+		}
+		return Amount{d}
+	}
+	flag := func(name string) bool {
+		value := field(name)
+		if value == "" {
+			return false
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			invalid.Add(name, fmt.Sprintf("invalid %s: %s", name, value))
+		}
+		return b
+	}
+
+	force := flag("force")
+	req := AddTransactionRequest{
+		TransactionDate:      field("transaction_date"),
+		TransactionTime:      optional("transaction_time"),
+		Symbol:               field("symbol"),
+		TransactionType:      strings.ToUpper(field("transaction_type")),
+		Quantity:             amount("quantity"),
+		Price:                amount("price"),
+		AccountID:            field("account_id"),
+		AssetType:            field("asset_type"),
+		Commission:           amount("commission"),
+		UseDefaultCommission: field("commission") == "",
+		Currency:             strings.ToUpper(field("currency")),
+		AccountName:          optional("account_name"),
+		Notes:                optional("notes"),
+		Tags:                 optional("tags"),
+		LinkCash:             flag("link_cash"),
+		CheckDuplicate:       !force,
+		CheckTotalAmount:     !force,
+	}
+	if field("total_amount") != "" {
+		total := amount("total_amount")
+		req.TotalAmount = &total
+	}
+	return req, invalid.Err()
+}
+
+func isBlankCSVRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package investlog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestImportTransactionsCSV_BatchesBelowLimit(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var b strings.Builder
+	b.WriteString("transaction_date,symbol,transaction_type,quantity,price,currency,account_id,commission\n")
+	rows := importBatchSize*2 + 1
+	for i := 0; i < rows-1; i++ {
+		fmt.Fprintf(&b, "2024-01-%02d,AAPL,buy,1,%d,usd,acc-import,0.5\n", i%28+1, 100+i)
+	}
+	b.WriteString("\n2024-02-01,AAPL,SELL,10,120,USD,acc-import,\n")
+
+	result, err := core.ImportTransactionsCSV(strings.NewReader(b.String()))
+	assertNoError(t, err, "import csv")
+	if result.Imported != rows || result.Failed != 0 || result.Batches != 3 || len(result.Rows) != rows {
+		t.Fatalf("expected %d rows in 3 batches, got imported=%d failed=%d batches=%d", rows, result.Imported, result.Failed, result.Batches)
+	}
+	if last := result.Rows[rows-1]; last.Line != rows+2 || last.ID == 0 {
+		t.Fatalf("expected the SELL on line %d to be imported, got %+v", rows+2, last)
+	}
+
+	count, err := core.GetTransactionCount(TransactionFilter{AccountID: "acc-import"})
+	assertNoError(t, err, "count imported")
+	if count != rows {
+		t.Fatalf("expected %d stored transactions, got %d", rows, count)
+	}
+	shares, err := core.getCurrentShares("AAPL", "USD", "acc-import")
+	assertNoError(t, err, "current shares")
+	assertFloatEquals(t, shares, float64(rows-1-10), "shares after import")
+}
+
+func TestImportTransactionsCSV_RejectsAboveLimit(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+	core.importMaxRows = 3
+
+	csv := "symbol,transaction_type,quantity,price,currency,account_id\n" +
+		strings.Repeat("AAPL,BUY,1,100,USD,acc-import\n", 4)
+	_, err := core.ImportTransactionsCSV(strings.NewReader(csv))
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || !strings.Contains(err.Error(), "limit of 3 rows") {
+		t.Fatalf("expected row limit validation error, got %v", err)
+	}
+
+	count, err := core.GetTransactionCount(TransactionFilter{})
+	assertNoError(t, err, "count")
+	if count != 0 {
+		t.Fatalf("expected nothing imported, got %d transactions", count)
+	}
+}
+
+func TestImportTransactionsCSV_InvalidFiles(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cases := map[string]string{
+		"":                                   "csv is empty",
+		"symbol,quantity,price,account_id\n": "missing column: transaction_type",
+		"symbol,transaction_type,quantity,price,account_id\n": "no transactions",
+	}
+	for input, want := range cases {
+		if _, err := core.ImportTransactionsCSV(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("import %q: expected error containing %q, got %v", input, want, err)
+		}
+	}
+}
+
+func TestImportTransactionsCSV_AppliesAddTransactionRules(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acc", "Main")
+	_, err := core.SetAccountDefaultCommission("acc", amountPtr(NewAmount(5)))
+	assertNoError(t, err, "set default commission")
+	existingID := testBuyTransaction(t, core, "MSFT", 2, 300, "USD", "acc")
+
+	csv := "transaction_date,symbol,transaction_type,quantity,price,currency,account_id,commission,total_amount,link_cash,force\n" +
+		"2024-03-01,AAPL,BUY,10,100,USD,acc,,,true,\n" + // line 2: default commission and linked cash
+		"2024-03-01,AAPL,BUY,abc,100,USD,acc,,,,\n" + // line 3: unparsable quantity
+		"2024-03-01,AAPL,SELL,50,100,USD,acc,,,,\n" + // line 4: oversell
+		"2024-03-01,AAPL,BUY,1,100,USD,acc,0,150,,\n" + // line 5: total_amount mismatch
+		fmt.Sprintf("%s,MSFT,BUY,2,300,USD,acc,0,,,\n", core.TodayISO()) + // line 6: duplicate
+		fmt.Sprintf("%s,MSFT,BUY,2,300,USD,acc,0,,,true\n", core.TodayISO()) // line 7: forced duplicate
+
+	result, err := core.ImportTransactionsCSV(strings.NewReader(csv))
+	assertNoError(t, err, "import csv")
+	if result.Imported != 2 || result.Failed != 4 || len(result.Rows) != 6 {
+		t.Fatalf("expected 2 imported and 4 failed rows, got %+v", result)
+	}
+	byLine := map[int]TransactionImportRow{}
+	for _, row := range result.Rows {
+		byLine[row.Line] = row
+	}
+	if byLine[2].ID == 0 || byLine[7].ID == 0 {
+		t.Fatalf("expected lines 2 and 7 to be imported, got %+v and %+v", byLine[2], byLine[7])
+	}
+	if row := byLine[3]; row.ErrorCode != string(ErrCodeValidation) || !strings.Contains(row.Error, "invalid quantity") {
+		t.Fatalf("expected line 3 quantity error, got %+v", row)
+	}
+	if row := byLine[4]; !strings.Contains(row.Error, "insufficient shares") {
+		t.Fatalf("expected line 4 oversell error, got %+v", row)
+	}
+	if row := byLine[5]; row.ErrorCode != string(ErrCodeValidation) || !strings.Contains(row.Error, "total_amount") {
+		t.Fatalf("expected line 5 total_amount error, got %+v", row)
+	}
+	if row := byLine[6]; row.ErrorCode != string(ErrCodeDuplicate) || row.ExistingID != existingID {
+		t.Fatalf("expected line 6 duplicate of %d, got %+v", existingID, row)
+	}
+
+	txns, err := core.GetTransactions(TransactionFilter{Symbol: "AAPL"})
+	assertNoError(t, err, "AAPL transactions")
+	if len(txns) != 1 {
+		t.Fatalf("expected only the valid AAPL row to be stored, got %d", len(txns))
+	}
+	assertFloatEquals(t, txns[0].Commission, 5, "default commission")
+	cash, err := core.getCurrentShares("CASH", "USD", "acc")
+	assertNoError(t, err, "cash shares")
+	assertFloatEquals(t, cash, -1005, "linked cash leg")
+}
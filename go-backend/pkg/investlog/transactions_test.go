@@ -1,6 +1,7 @@
 package investlog
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -163,6 +164,29 @@ func TestAddTransaction_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestAddTransaction_ValidationFields(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := core.AddTransaction(AddTransactionRequest{
+		Quantity: NewAmountFromInt(100),
+		Price:    NewAmount(-1),
+		Currency: "EUR",
+	})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	for _, field := range []string{"transaction_type", "account_id", "currency", "symbol", "price"} {
+		if invalid.Fields[field] == "" {
+			t.Errorf("expected error for field %q, got %v", field, invalid.Fields)
+		}
+	}
+	if len(invalid.Fields) != 5 {
+		t.Errorf("expected 5 field errors, got %v", invalid.Fields)
+	}
+}
+
 func TestAddTransaction_SellValidation(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -392,7 +416,7 @@ func TestAddTransaction_CashLinking(t *testing.T) {
 	})
 	assertNoError(t, err, "SELL with cash linking")
 
-	// Check that CASH was increased (SELL creates a BUY on CASH)
+	// Check that CASH was increased (SELL creates a TRANSFER_IN on CASH)
 	cashShares, err = core.getCurrentShares("CASH", "CNY", "test-account")
 	assertNoError(t, err, "get CASH shares after SELL")
 
@@ -400,6 +424,82 @@ func TestAddTransaction_CashLinking(t *testing.T) {
 	assertFloatEquals(t, cashShares, 99590, "CASH after SELL")
 }
 
+func TestAddTransaction_LinkedCashFollowsDelete(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "test-account", "Test Account")
+	_, err := core.AddTransaction(AddTransactionRequest{
+		Symbol:          "600000",
+		TransactionType: "BUY",
+		Quantity:        NewAmountFromInt(100),
+		Price:           NewAmountFromInt(10),
+		Currency:        "CNY",
+		AccountID:       "test-account",
+		AssetType:       "stock",
+	})
+	assertNoError(t, err, "BUY without cash linking")
+
+	sellID, err := core.AddTransaction(AddTransactionRequest{
+		Symbol:          "600000",
+		TransactionType: "SELL",
+		Quantity:        NewAmountFromInt(40),
+		Price:           NewAmountFromInt(12),
+		Currency:        "CNY",
+		AccountID:       "test-account",
+		AssetType:       "stock",
+		Commission:      NewAmountFromInt(5),
+		LinkCash:        true,
+	})
+	assertNoError(t, err, "SELL with cash linking")
+
+	dividendID, err := core.AddTransaction(AddTransactionRequest{
+		Symbol:          "600000",
+		TransactionType: "DIVIDEND",
+		Quantity:        NewAmountFromInt(60),
+		Price:           NewAmount(0.5),
+		Currency:        "CNY",
+		AccountID:       "test-account",
+		AssetType:       "stock",
+		LinkCash:        true,
+	})
+	assertNoError(t, err, "DIVIDEND with cash linking")
+
+	cashShares, err := core.getCurrentShares("CASH", "CNY", "test-account")
+	assertNoError(t, err, "get CASH shares")
+	// 40*12 - 5 = 475 from the sale, plus 60*0.5 = 30 of dividends.
+	assertFloatEquals(t, cashShares, 505, "CASH after SELL and DIVIDEND")
+
+	cashTxns, err := core.GetTransactions(TransactionFilter{Symbol: "CASH"})
+	assertNoError(t, err, "get CASH transactions")
+	if len(cashTxns) != 2 {
+		t.Fatalf("expected 2 linked cash transactions, got %d", len(cashTxns))
+	}
+	for _, txn := range cashTxns {
+		if txn.TransactionType != "TRANSFER_IN" {
+			t.Errorf("expected TRANSFER_IN cash entry, got %s", txn.TransactionType)
+		}
+		if txn.LinkedTransactionID == nil || (*txn.LinkedTransactionID != sellID && *txn.LinkedTransactionID != dividendID) {
+			t.Errorf("cash entry %d not linked to its parent: %v", txn.ID, txn.LinkedTransactionID)
+		}
+	}
+
+	deleted, err := core.DeleteTransaction(sellID)
+	assertNoError(t, err, "delete SELL")
+	if !deleted {
+		t.Fatal("expected SELL to be deleted")
+	}
+	cashShares, err = core.getCurrentShares("CASH", "CNY", "test-account")
+	assertNoError(t, err, "get CASH shares after delete")
+	assertFloatEquals(t, cashShares, 30, "CASH after deleting SELL")
+
+	_, err = core.DeleteTransaction(dividendID)
+	assertNoError(t, err, "delete DIVIDEND")
+	cashShares, err = core.getCurrentShares("CASH", "CNY", "test-account")
+	assertNoError(t, err, "get CASH shares after deleting DIVIDEND")
+	assertFloatEquals(t, cashShares, 0, "CASH after deleting DIVIDEND")
+}
+
 func TestAddTransaction_SymbolNormalization(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -451,6 +551,63 @@ func TestAddTransaction_TotalAmountOverride(t *testing.T) {
 	assertFloatEquals(t, tx.TotalAmount, 1600, "overridden total amount")
 }
 
+func TestAddTransaction_CheckTotalAmount(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "test-account", "Test Account")
+	add := func(txType string, total, commission float64) error {
+		ta := NewAmount(total)
+		_, err := core.AddTransaction(AddTransactionRequest{
+			Symbol:           "AAPL",
+			TransactionType:  txType,
+			Quantity:         NewAmountFromInt(10),
+			Price:            NewAmount(15.5),
+			Commission:       NewAmount(commission),
+			Currency:         "USD",
+			AccountID:        "test-account",
+			AssetType:        "stock",
+			TotalAmount:      &ta,
+			CheckTotalAmount: true,
+		})
+		return err
+	}
+
+	assertNoError(t, add("BUY", 155, 0), "matching total")
+	assertNoError(t, add("BUY", 160, 5), "commission-inclusive BUY total")
+	assertNoError(t, add("SELL", 150, 5), "commission-inclusive SELL total")
+
+	err := add("BUY", 165, 5)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Fields["total_amount"] == "" {
+		t.Fatalf("expected total_amount validation error, got %v", err)
+	}
+	if err := add("SELL", 160, 5); err == nil {
+		t.Fatal("expected SELL total with commission added to be rejected")
+	}
+}
+
+func TestReconcileTotalAmount_Tolerance(t *testing.T) {
+	total := NewAmount(10000.4)
+	req := AddTransactionRequest{
+		TransactionType: "BUY",
+		Quantity:        NewAmountFromInt(1000),
+		Price:           NewAmountFromInt(10),
+		TotalAmount:     &total,
+	}
+	if err := reconcileTotalAmount(req); err != nil {
+		t.Fatalf("expected rounding difference within tolerance, got %v", err)
+	}
+	total = NewAmount(10010)
+	if err := reconcileTotalAmount(req); err == nil {
+		t.Fatal("expected mismatch beyond tolerance to be rejected")
+	}
+	req.TransactionType = "DIVIDEND"
+	if err := reconcileTotalAmount(req); err != nil {
+		t.Fatalf("expected non-trade types to be skipped, got %v", err)
+	}
+}
+
 func TestAddTransaction_DefaultCurrency(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
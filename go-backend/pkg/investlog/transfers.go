@@ -25,6 +25,8 @@ func (c *Core) Transfer(req TransferRequest) (*TransferResult, error) {
 	if req.FromAccountID == req.ToAccountID {
 		return nil, errors.New("from_account_id and to_account_id must be different")
 	}
+	req.FromCurrency = normalizeCurrency(req.FromCurrency)
+	req.ToCurrency = normalizeCurrency(req.ToCurrency)
 	if req.FromCurrency == "" {
 		req.FromCurrency = "CNY"
 	}
@@ -38,7 +40,7 @@ func (c *Core) Transfer(req TransferRequest) (*TransferResult, error) {
 		return nil, fmt.Errorf("invalid to_currency: %s", req.ToCurrency)
 	}
 	if req.TransactionDate == "" {
-		req.TransactionDate = todayISO()
+		req.TransactionDate = c.TodayISO()
 	}
 	if req.AssetType == "" {
 		req.AssetType = "stock"
@@ -65,11 +67,13 @@ func (c *Core) Transfer(req TransferRequest) (*TransferResult, error) {
 
 	// --- 3. Exchange rate ---
 	exchangeRate := 1.0
-	crossCurrency := normalizeCurrency(req.FromCurrency) != normalizeCurrency(req.ToCurrency)
+	crossCurrency := req.FromCurrency != req.ToCurrency
 	if crossCurrency {
 		exchangeRate, err = c.GetExchangeRate(req.FromCurrency, req.ToCurrency)
 		if err != nil {
-			return nil, fmt.Errorf("get exchange rate: %w", err)
+			return nil, NewValidationError("to_currency", fmt.Sprintf(
+				"cannot convert %s to %s: %v; set the exchange rate before transferring across currencies",
+				req.FromCurrency, req.ToCurrency, err))
 		}
 	}
 
@@ -166,8 +170,11 @@ func (c *Core) Transfer(req TransferRequest) (*TransferResult, error) {
 	c.invalidateHoldingsCache()
 
 	result := &TransferResult{
-		TransferOutID: outID,
-		TransferInID:  inID,
+		TransferOutID:  outID,
+		TransferInID:   inID,
+		OutTotalAmount: outTotalAmount,
+		InQuantity:     inQuantity,
+		InTotalAmount:  inTotalAmount,
 	}
 	if crossCurrency {
 		result.ExchangeRate = NewAmount(exchangeRate)
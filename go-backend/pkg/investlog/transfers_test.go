@@ -1,6 +1,7 @@
 package investlog
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -206,6 +207,109 @@ func TestTransfer_CrossCurrency_Securities(t *testing.T) {
 	}
 }
 
+func TestTransfer_SameCurrency_NoConversion(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct-a", "Account A")
+	testAccount(t, core, "acct-b", "Account B")
+	testBuyTransaction(t, core, "600519", 10, 1500, "CNY", "acct-a")
+
+	result, err := core.Transfer(TransferRequest{
+		Symbol:        "600519",
+		Quantity:      NewAmountFromInt(4),
+		FromAccountID: "acct-a",
+		ToAccountID:   "acct-b",
+		FromCurrency:  "CNY",
+		ToCurrency:    "cny",
+	})
+	assertNoError(t, err, "Transfer same currency")
+
+	if !result.ExchangeRate.IsZero() {
+		t.Errorf("same currency transfer should not report an exchange_rate, got %v", result.ExchangeRate)
+	}
+	assertFloatEquals(t, result.OutTotalAmount, 6000, "out total amount")
+	assertFloatEquals(t, result.InQuantity, 4, "in quantity")
+	assertFloatEquals(t, result.InTotalAmount, 6000, "in total amount")
+}
+
+func TestTransfer_CrossCurrency_USDToCNY(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct-a", "Account A")
+	testAccount(t, core, "acct-b", "Account B")
+	_, err := core.SetExchangeRate("USD", "CNY", 7.2, "manual")
+	assertNoError(t, err, "set USD rate")
+	testBuyTransaction(t, core, "AAPL", 10, 200, "USD", "acct-a")
+
+	result, err := core.Transfer(TransferRequest{
+		Symbol:        "AAPL",
+		Quantity:      NewAmountFromInt(5),
+		FromAccountID: "acct-a",
+		ToAccountID:   "acct-b",
+		FromCurrency:  "USD",
+		ToCurrency:    "CNY",
+	})
+	assertNoError(t, err, "Transfer USD to CNY")
+
+	assertFloatEquals(t, result.ExchangeRate, 7.2, "exchange rate")
+	assertFloatEquals(t, result.OutTotalAmount, 1000, "out total amount in USD")
+	assertFloatEquals(t, result.InQuantity, 5, "in quantity")
+	assertFloatEquals(t, result.InTotalAmount, 7200, "in total amount in CNY")
+
+	holdings, err := core.GetHoldings("")
+	assertNoError(t, err, "GetHoldings")
+	var found bool
+	for _, h := range holdings {
+		if h.Symbol == "AAPL" && h.AccountID == "acct-b" {
+			found = true
+			if h.Currency != "CNY" {
+				t.Fatalf("expected destination recorded in CNY, got %s", h.Currency)
+			}
+			assertFloatEquals(t, h.TotalCost, 7200, "dest cost in CNY")
+			assertFloatEquals(t, h.AvgCost, 1440, "dest avg cost in CNY")
+		}
+	}
+	if !found {
+		t.Fatal("destination CNY holding not found")
+	}
+}
+
+func TestTransfer_CrossCurrency_MissingRate(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testAccount(t, core, "acct-a", "Account A")
+	testAccount(t, core, "acct-b", "Account B")
+	testBuyTransaction(t, core, "AAPL", 10, 200, "USD", "acct-a")
+	if _, err := core.db.Exec("DELETE FROM exchange_rates WHERE from_currency = 'USD'"); err != nil {
+		t.Fatalf("delete USD rate: %v", err)
+	}
+
+	_, err := core.Transfer(TransferRequest{
+		Symbol:        "AAPL",
+		Quantity:      NewAmountFromInt(5),
+		FromAccountID: "acct-a",
+		ToAccountID:   "acct-b",
+		FromCurrency:  "USD",
+		ToCurrency:    "CNY",
+	})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Fields["to_currency"] == "" {
+		t.Fatalf("expected to_currency validation error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "USD/CNY") {
+		t.Fatalf("expected error to name the missing USD/CNY rate, got %v", err)
+	}
+
+	holdings, err := core.GetHoldings("acct-b")
+	assertNoError(t, err, "GetHoldings")
+	if len(holdings) != 0 {
+		t.Fatalf("expected no destination records after failed transfer, got %+v", holdings)
+	}
+}
+
 func TestTransfer_WithCommission(t *testing.T) {
 	core, cleanup := setupTestDB(t)
 	defer cleanup()
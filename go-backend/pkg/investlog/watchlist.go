@@ -0,0 +1,126 @@
+package investlog
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// WatchlistItem is a symbol tracked without holding it. LatestPrice and
+// PriceUpdatedAt come from latest_prices and are nil until a price is known.
+type WatchlistItem struct {
+	ID             int64   `json:"id"`
+	Symbol         string  `json:"symbol"`
+	Currency       string  `json:"currency"`
+	AssetType      string  `json:"asset_type"`
+	Notes          *string `json:"notes"`
+	LatestPrice    *Amount `json:"latest_price"`
+	PriceUpdatedAt *string `json:"price_updated_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// AddToWatchlist starts tracking item.Symbol in item.Currency. Adding a
+// symbol that is already watched updates its asset type and notes.
+func (c *Core) AddToWatchlist(item WatchlistItem) (*WatchlistItem, error) {
+	symbol := normalizeSymbol(item.Symbol)
+	currency := normalizeCurrency(item.Currency)
+	assetType := strings.ToLower(strings.TrimSpace(item.AssetType))
+	if assetType == "" {
+		assetType = "stock"
+	}
+	invalid := &ValidationError{}
+	if symbol == "" {
+		invalid.Add("symbol", "symbol is required")
+	}
+	if !isValidCurrency(currency) {
+		invalid.Add("currency", fmt.Sprintf("invalid currency: %s", item.Currency))
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err := c.db.Exec(`
+		INSERT INTO watchlist (symbol, currency, asset_type, notes)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(symbol, currency) DO UPDATE SET
+			asset_type = excluded.asset_type,
+			notes = excluded.notes
+	`, symbol, currency, assetType, nullString(item.Notes))
+	if err != nil {
+		return nil, fmt.Errorf("add to watchlist: %w", err)
+	}
+	return c.getWatchlistItem(symbol, currency)
+}
+
+// RemoveFromWatchlist stops tracking the watchlist entry id. It reports
+// false when there is no such entry.
+func (c *Core) RemoveFromWatchlist(id int64) (bool, error) {
+	result, err := c.db.Exec("DELETE FROM watchlist WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetWatchlist returns every watched symbol with its latest known price,
+// ordered by currency and symbol.
+func (c *Core) GetWatchlist() ([]WatchlistItem, error) {
+	return c.queryWatchlist("", nil)
+}
+
+// watchlistForCurrency returns the watched symbols in currency.
+func (c *Core) watchlistForCurrency(currency string) ([]WatchlistItem, error) {
+	return c.queryWatchlist("WHERE w.currency = ?", []any{currency})
+}
+
+func (c *Core) getWatchlistItem(symbol, currency string) (*WatchlistItem, error) {
+	items, err := c.queryWatchlist("WHERE w.symbol = ? AND w.currency = ?", []any{symbol, currency})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, NewError(ErrCodeNotFound, fmt.Sprintf("%s (%s) is not on the watchlist", symbol, currency))
+	}
+	return &items[0], nil
+}
+
+func (c *Core) queryWatchlist(where string, params []any) ([]WatchlistItem, error) {
+	rows, err := c.db.Query(`
+		SELECT w.id, w.symbol, w.currency, w.asset_type, w.notes,
+			lp.price, CAST(lp.updated_at AS TEXT), CAST(w.created_at AS TEXT)
+		FROM watchlist w
+		LEFT JOIN latest_prices lp ON lp.symbol = w.symbol AND lp.currency = w.currency
+		`+where+`
+		ORDER BY w.currency, w.symbol
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []WatchlistItem{}
+	for rows.Next() {
+		var item WatchlistItem
+		var notes, updatedAt, createdAt sql.NullString
+		var price sql.NullFloat64
+		if err := rows.Scan(&item.ID, &item.Symbol, &item.Currency, &item.AssetType, &notes, &price, &updatedAt, &createdAt); err != nil {
+			return nil, err
+		}
+		if notes.Valid {
+			item.Notes = &notes.String
+		}
+		if price.Valid {
+			item.LatestPrice = amountPtr(NewAmount(price.Float64))
+		}
+		if updatedAt.Valid {
+			item.PriceUpdatedAt = &updatedAt.String
+		}
+		item.CreatedAt = createdAt.String
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
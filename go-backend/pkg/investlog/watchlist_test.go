@@ -0,0 +1,88 @@
+package investlog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWatchlistAddRemoveList(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	item, err := core.AddToWatchlist(WatchlistItem{Symbol: " nvda ", Currency: "usd"})
+	assertNoError(t, err, "AddToWatchlist NVDA")
+	if item.Symbol != "NVDA" || item.Currency != "USD" || item.AssetType != "stock" {
+		t.Fatalf("unexpected watchlist item: %+v", item)
+	}
+	_, err = core.AddToWatchlist(WatchlistItem{Symbol: "00700", Currency: "HKD"})
+	assertNoError(t, err, "AddToWatchlist 00700")
+
+	notes := "wait for pullback"
+	again, err := core.AddToWatchlist(WatchlistItem{Symbol: "NVDA", Currency: "USD", AssetType: "Stock", Notes: &notes})
+	assertNoError(t, err, "re-add NVDA")
+	if again.ID != item.ID || again.Notes == nil || *again.Notes != notes {
+		t.Fatalf("re-adding should update the existing entry, got %+v", again)
+	}
+
+	assertNoError(t, core.UpdateLatestPrice("NVDA", "USD", NewAmount(120.5)), "UpdateLatestPrice")
+	items, err := core.GetWatchlist()
+	assertNoError(t, err, "GetWatchlist")
+	if len(items) != 2 || items[0].Symbol != "00700" || items[1].Symbol != "NVDA" {
+		t.Fatalf("unexpected watchlist: %+v", items)
+	}
+	if items[1].LatestPrice == nil || items[0].LatestPrice != nil {
+		t.Fatalf("expected a latest price only for NVDA: %+v", items)
+	}
+	assertFloatEquals(t, *items[1].LatestPrice, 120.5, "NVDA latest price")
+
+	removed, err := core.RemoveFromWatchlist(item.ID)
+	assertNoError(t, err, "RemoveFromWatchlist")
+	if !removed {
+		t.Fatal("expected NVDA to be removed")
+	}
+	removed, err = core.RemoveFromWatchlist(item.ID)
+	assertNoError(t, err, "RemoveFromWatchlist again")
+	if removed {
+		t.Fatal("removing twice should report false")
+	}
+	items, err = core.GetWatchlist()
+	assertNoError(t, err, "GetWatchlist after remove")
+	if len(items) != 1 || items[0].Symbol != "00700" {
+		t.Fatalf("unexpected watchlist after remove: %+v", items)
+	}
+
+	_, err = core.AddToWatchlist(WatchlistItem{Currency: "EUR"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != 2 {
+		t.Fatalf("expected symbol and currency errors, got %v", err)
+	}
+}
+
+func TestUpdateAllPrices_IncludesWatchlist(t *testing.T) {
+	core, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// No holdings in USD, so only the watched symbol is refreshed. The
+	// fetcher cache stands in for the remote price source.
+	_, err := core.AddToWatchlist(WatchlistItem{Symbol: "MSFT", Currency: "USD"})
+	assertNoError(t, err, "AddToWatchlist")
+	core.price.setCached("MSFT", "USD", "stock", 410.25, "test")
+
+	updated, errs, err := core.UpdateAllPrices("USD")
+	assertNoError(t, err, "UpdateAllPrices")
+	if updated != 1 || len(errs) != 0 {
+		t.Fatalf("expected 1 watchlist price updated, got %d (%v)", updated, errs)
+	}
+	price, err := core.GetLatestPrice("MSFT", "USD")
+	assertNoError(t, err, "GetLatestPrice")
+	if price == nil {
+		t.Fatal("expected MSFT latest price")
+	}
+	assertFloatEquals(t, price.Price, 410.25, "MSFT price")
+
+	ctx, err := core.buildSymbolContext("MSFT", "USD", "")
+	assertNoError(t, err, "buildSymbolContext for watched symbol")
+	if ctx.TotalShares != 0 || ctx.LatestPrice != 410.25 || ctx.AssetType != "stock" {
+		t.Fatalf("unexpected context for watched symbol: %+v", ctx)
+	}
+}
@@ -10,7 +10,8 @@ function resolveApiBase() {
     return trimTrailingSlash(stored);
   }
   if (window.location.protocol === 'http:' || window.location.protocol === 'https:') {
-    return '';
+    const meta = document.querySelector('meta[name="investlog-base-path"]');
+    return meta ? trimTrailingSlash(meta.getAttribute('content') || '') : '';
   }
   return '';
 }
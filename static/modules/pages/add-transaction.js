@@ -346,11 +346,20 @@ async function renderAddTransaction() {
         payload.price = 1;
       }
 
+      const save = () => fetchJSON('/api/transactions', {
+        method: 'POST',
+        body: JSON.stringify(payload),
+      });
       try {
-        await fetchJSON('/api/transactions', {
-          method: 'POST',
-          body: JSON.stringify(payload),
-        });
+        try {
+          await save();
+        } catch (err) {
+          const existingID = duplicateTransactionID(err);
+          if (!existingID) throw err;
+          if (!await showConfirmModal(`An identical transaction (#${existingID}) was recorded within 3 days. Save anyway?`)) return;
+          payload.force = true;
+          await save();
+        }
         showToast('Transaction saved');
         window.location.hash = '#/transactions';
       } catch (err) {
@@ -362,3 +371,14 @@ async function renderAddTransaction() {
   }
 }
 
+
+// duplicateTransactionID returns the existing transaction ID from a 409
+// duplicate response, or 0 for any other error.
+function duplicateTransactionID(err) {
+  try {
+    const body = JSON.parse(err.message);
+    return body.error_code === 'DUPLICATE' ? Number(body.existing_id) || 0 : 0;
+  } catch (_) {
+    return 0;
+  }
+}
@@ -38,6 +38,12 @@ async function renderSymbolAnalysis() {
           streamState.stage = payload && payload.message
             ? String(payload.message)
             : 'Analyzing...';
+          // Dimension agents plus synthesis make up the steps.
+          const total = payload && Number(payload.total);
+          if (total > 0) {
+            const step = payload.stage === 'synthesis' ? total + 1 : Number(payload.completed) || 0;
+            streamState.stage += ` (${step}/${total + 1})`;
+          }
           if (contentEl) {
             contentEl.innerHTML = renderSymbolAnalysisStreamingCard(streamState);
           }